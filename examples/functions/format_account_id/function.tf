@@ -0,0 +1,11 @@
+locals {
+  # Spreadsheet exports often turn account IDs into numbers and drop leading zeros
+  accounts = csvdecode(file("${path.module}/accounts.csv"))
+}
+
+resource "prism_permission_set_assignment" "developers" {
+  permission_set_id = prism_permission_set.developer.id
+  principal_type    = "GROUP"
+  principal_id      = "developers"
+  account_ids       = [for row in local.accounts : provider::prism::format_account_id(row.account_id)]
+}
@@ -0,0 +1,8 @@
+variable "account_ids" {
+  type = list(string)
+
+  validation {
+    condition     = alltrue([for id in var.account_ids : provider::prism::is_account_id(id)])
+    error_message = "Each account ID must be exactly 12 digits."
+  }
+}
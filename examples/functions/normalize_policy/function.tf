@@ -0,0 +1,10 @@
+resource "prism_permission_set" "billing" {
+  name             = "BillingAccess"
+  session_duration = "PT1H"
+
+  inline_policies = {
+    # Policy files written by hand or exported from AWS often differ only in
+    # key order and whitespace; normalizing keeps plans stable.
+    billing = provider::prism::normalize_policy(file("${path.module}/policies/billing.json"))
+  }
+}
@@ -15,7 +15,7 @@ provider "prism" {
   prism_subdomain = var.prism_subdomain
 
   # Base URL for your Prism instance (without port)
-  # The port 8090 is automatically appended
+  # The port (8090 unless `port` is set) is automatically appended
   base_url = var.prism_base_url
 
   # API token for authentication
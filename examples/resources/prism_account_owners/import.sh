@@ -0,0 +1,2 @@
+# Account owners can be imported using the AWS account ID
+terraform import prism_account_owners.production "123456789012"
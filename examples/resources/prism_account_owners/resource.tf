@@ -0,0 +1,15 @@
+resource "prism_aws_account" "production" {
+  account_id   = "123456789012"
+  account_name = "Production"
+  region       = "us-east-1"
+  # owner_emails is left unset so prism_account_owners can manage owners
+}
+
+resource "prism_account_owners" "production" {
+  account_id = prism_aws_account.production.account_id
+
+  owner_emails = [
+    "platform-lead@example.com",
+    "security-oncall@example.com",
+  ]
+}
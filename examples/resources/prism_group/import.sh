@@ -1,2 +1,2 @@
-# Groups can be imported using the group ID
-terraform import prism_group.example "group-1234567890abcdef"
+# Groups can be imported using the group name
+terraform import prism_group.example "Developers"
@@ -1,4 +1,6 @@
-# Identity providers can be imported using the type and alias
-# Format: type:alias (though alias is auto-generated from type)
-terraform import prism_identity_provider.google "google:google"
+# Identity providers can be imported using the type, optionally followed by the alias
+# Format: type or type:alias (the alias is auto-generated from type)
+# A : or % in the alias is written as %3A or %25
+# config is sensitive and cannot be read back, so set config or config_wo after importing
+terraform import prism_identity_provider.google "google"
 terraform import prism_identity_provider.microsoft "microsoft:microsoft"
@@ -17,14 +17,17 @@ resource "prism_identity_provider" "google" {
 # For keycloak: alias = "keycloak"
 
 # Microsoft Azure AD Identity Provider
+# With Terraform 1.11 or later, config_wo keeps the client secret out of state.
+# Increment config_wo_version whenever the secret changes.
 resource "prism_identity_provider" "microsoft" {
   type         = "microsoft"
   display_name = "Sign in with Microsoft"
   enabled      = true
 
-  config = jsonencode({
+  config_wo = jsonencode({
     clientId     = "your-azure-client-id"
-    clientSecret = "your-azure-client-secret"
+    clientSecret = var.azure_client_secret
     tenantId     = "your-azure-tenant-id"
   })
+  config_wo_version = 1
 }
@@ -1,3 +1,11 @@
 # Permission set assignments can be imported using comma-separated assignment IDs
 # Format: assignment_id_1,assignment_id_2,assignment_id_3
 terraform import prism_permission_set_assignment.example "asgn-abc123,asgn-def456,asgn-ghi789"
+
+# Or by describing the assignment, which is resolved to the assignment IDs
+# Format: permission_set_id:principal_type:principal_id:account_id_1,account_id_2
+# A : or % in the principal ID is written as %3A or %25, e.g. CN=jane%3Adoe
+terraform import prism_permission_set_assignment.example "ps-abc123:GROUP:Developers:123456789012,210987654321"
+
+# Either way, the imported account_ids are sorted, so list them sorted in the
+# configuration for the first plan to show no changes
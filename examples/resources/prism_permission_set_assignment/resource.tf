@@ -4,3 +4,13 @@ resource "prism_permission_set_assignment" "dev_team" {
   principal_id      = prism_group.developers.name
   account_ids       = [prism_aws_account.production.account_id]
 }
+
+# A user can be named by email address. It must belong to exactly one user,
+# and is resolved to that user's username on every refresh, so the
+# assignment follows the user through a rename.
+resource "prism_permission_set_assignment" "on_call" {
+  permission_set_id = prism_permission_set.developer.id
+  principal_type    = "USER"
+  principal_id      = "alice@example.com"
+  account_ids       = [prism_aws_account.production.account_id]
+}
@@ -0,0 +1,2 @@
+# Inline policies can be imported using the permission set ID and policy name, separated by "/"
+terraform import prism_permission_set_inline_policy.s3_write "ps-abc123/s3-write"
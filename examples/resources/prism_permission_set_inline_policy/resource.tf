@@ -0,0 +1,18 @@
+resource "prism_permission_set" "shared" {
+  name             = "SharedAccess"
+  managed_policies = ["arn:aws:iam::aws:policy/ReadOnlyAccess"]
+  # inline_policies is left unset so each team can add its own policies
+}
+
+resource "prism_permission_set_inline_policy" "s3_write" {
+  permission_set_id = prism_permission_set.shared.id
+  policy_name       = "s3-write"
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Allow"
+      Action   = ["s3:PutObject"]
+      Resource = "arn:aws:s3:::team-uploads/*"
+    }]
+  })
+}
@@ -0,0 +1,2 @@
+# Managed policy attachments can be imported using the permission set ID and policy ARN, separated by "/"
+terraform import prism_permission_set_managed_policy_attachment.security_audit "ps-abc123/arn:aws:iam::aws:policy/SecurityAudit"
@@ -0,0 +1,20 @@
+resource "prism_permission_set" "shared" {
+  name = "SharedAccess"
+  inline_policies = {
+    "base" = jsonencode({
+      Version   = "2012-10-17"
+      Statement = []
+    })
+  }
+  # managed_policies is left unset so each team can attach its own policies
+}
+
+resource "prism_permission_set_managed_policy_attachment" "security_audit" {
+  permission_set_id = prism_permission_set.shared.id
+  policy_arn        = "arn:aws:iam::aws:policy/SecurityAudit"
+}
+
+resource "prism_permission_set_managed_policy_attachment" "view_only" {
+  permission_set_id = prism_permission_set.shared.id
+  policy_arn        = "arn:aws:iam::aws:policy/job-function/ViewOnlyAccess"
+}
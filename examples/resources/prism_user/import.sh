@@ -1,2 +1,2 @@
-# Users can be imported using the user ID
-terraform import prism_user.example "user-1234567890abcdef"
+# Users can be imported using the username
+terraform import prism_user.example "john.doe"
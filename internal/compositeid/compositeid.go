@@ -0,0 +1,97 @@
+// Package compositeid builds and parses IDs made of several values joined
+// by a separator, like an identity provider's type:alias import ID or a
+// group member's group_name:username.
+//
+// Encode escapes the separator and '%' within each value as %XX, so that
+// values holding the separator, like LDAP usernames with colons, survive
+// the round trip. Values without either are written unchanged, so IDs
+// built before escaping existed still decode to the same values.
+package compositeid
+
+import (
+	"fmt"
+	"strings"
+)
+
+const hexDigits = "0123456789ABCDEF"
+
+// Encode joins parts with sep, escaping sep and '%' within each part. sep
+// must not be '%' or a hex digit.
+func Encode(sep byte, parts ...string) string {
+	checkSeparator(sep)
+
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteByte(sep)
+		}
+		for j := 0; j < len(part); j++ {
+			if c := part[j]; c == sep || c == '%' {
+				b.WriteByte('%')
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xF])
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Decode splits id on sep and unescapes each part, reversing Encode. It
+// fails on a '%' that isn't followed by two hex digits.
+func Decode(id string, sep byte) ([]string, error) {
+	checkSeparator(sep)
+
+	parts := strings.Split(id, string([]byte{sep}))
+	for i, part := range parts {
+		unescaped, err := unescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("part %d of %q: %w", i+1, id, err)
+		}
+		parts[i] = unescaped
+	}
+	return parts, nil
+}
+
+func unescape(part string) (string, error) {
+	if !strings.Contains(part, "%") {
+		return part, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(part); i++ {
+		if part[i] != '%' {
+			b.WriteByte(part[i])
+			continue
+		}
+		if i+2 >= len(part) {
+			return "", fmt.Errorf("incomplete escape %q", part[i:])
+		}
+		hi, lo := unhex(part[i+1]), unhex(part[i+2])
+		if hi < 0 || lo < 0 {
+			return "", fmt.Errorf("invalid escape %q", part[i:i+3])
+		}
+		b.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+func checkSeparator(sep byte) {
+	if sep == '%' || unhex(sep) >= 0 {
+		panic(fmt.Sprintf("compositeid: invalid separator %q", sep))
+	}
+}
@@ -0,0 +1,90 @@
+package compositeid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hostileParts are values that a naive join would mangle: separators,
+// escapes, look-alike escapes and bytes that aren't valid UTF-8.
+var hostileParts = [][]string{
+	{"admins", "jane"},
+	{"admins", "CN=jane:doe,OU=people"},
+	{"a:b", ":", ""},
+	{"", ""},
+	{"%", "%%", "%3A", "%3a", "%2"},
+	{"100%:off", "/slash/", "ab%ZZ"},
+	{"\x00", "\xff\xfe", "üñí:cödé"},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, sep := range []byte{':', '/', ','} {
+		for _, parts := range hostileParts {
+			id := Encode(sep, parts...)
+			got, err := Decode(id, sep)
+			if err != nil {
+				t.Errorf("%q with %q: decode %q: %v", parts, sep, id, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, parts) {
+				t.Errorf("%q with %q: encoded as %q, decoded as %q", parts, sep, id, got)
+			}
+			if n := strings.Count(id, string([]byte{sep})); n != len(parts)-1 {
+				t.Errorf("%q with %q: expected %d separators in %q, got %d", parts, sep, len(parts)-1, id, n)
+			}
+		}
+	}
+}
+
+func TestEncode_LeavesPlainValues(t *testing.T) {
+	// IDs built before escaping existed must decode the same
+	if got, want := Encode(':', "google", "google-sso"), "google:google-sso"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := Encode(':', "ldap", "CN=a:b"), "ldap:CN=a%3Ab"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDecode_InvalidEscapes(t *testing.T) {
+	for _, id := range []string{"a:%", "a:%4", "%ZZ:b", "a:b%G1"} {
+		if _, err := Decode(id, ':'); err == nil {
+			t.Errorf("%q: expected an error", id)
+		}
+	}
+}
+
+func TestInvalidSeparator(t *testing.T) {
+	for _, sep := range []byte{'%', '0', 'a', 'F'} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: expected a panic", sep)
+				}
+			}()
+			Encode(sep, "a", "b")
+		}()
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, parts := range hostileParts {
+		f.Add(parts[0], parts[len(parts)-1], ":")
+	}
+	f.Add("a%", "b", "/")
+	f.Add("", "", "\xe8")
+	f.Fuzz(func(t *testing.T, a, b, sep string) {
+		if len(sep) != 1 || sep[0] == '%' || unhex(sep[0]) >= 0 {
+			t.Skip()
+		}
+		id := Encode(sep[0], a, b)
+		got, err := Decode(id, sep[0])
+		if err != nil {
+			t.Fatalf("decode %q: %v", id, err)
+		}
+		if want := []string{a, b}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("encoded %q as %q, decoded as %q", want, id, got)
+		}
+	})
+}
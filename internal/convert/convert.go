@@ -0,0 +1,86 @@
+// Package convert builds the list, set and map values the provider writes to
+// state from API values, so that every resource and data source treats
+// missing and empty values the same way.
+//
+// The OrNull helpers write null when the API returns no elements, for
+// optional and computed attributes that are unset when empty. The OrEmpty
+// helpers write an empty collection instead, for attributes that always
+// hold a value, like a group's members. KeepEmptyList and KeepEmptyMap keep
+// a configured empty value that an OrNull helper would turn into null.
+package convert
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// StringListOrNull returns values as a list, or null if there are none.
+func StringListOrNull(values []string) types.List {
+	if len(values) == 0 {
+		return types.ListNull(types.StringType)
+	}
+	return StringListOrEmpty(values)
+}
+
+// StringListOrEmpty returns values as a list, which is empty if there are
+// none.
+func StringListOrEmpty(values []string) types.List {
+	return types.ListValueMust(types.StringType, stringValues(values))
+}
+
+// StringSetOrEmpty returns values as a set, which is empty if there are
+// none.
+func StringSetOrEmpty(values []string) types.Set {
+	return types.SetValueMust(types.StringType, stringValues(values))
+}
+
+// StringMapOrNull returns values as a map, or null if there are none.
+func StringMapOrNull(values map[string]string) types.Map {
+	if len(values) == 0 {
+		return types.MapNull(types.StringType)
+	}
+	elements := make(map[string]attr.Value, len(values))
+	for k, v := range values {
+		elements[k] = types.StringValue(v)
+	}
+	return types.MapValueMust(types.StringType, elements)
+}
+
+// StringMapFromMultiValue returns the first value of each key in values as
+// a map, the way the API's multi-valued attributes are shown in Terraform.
+// Keys without a value are left out, and the map is null if none remain.
+func StringMapFromMultiValue(values map[string][]string) types.Map {
+	first := make(map[string]string, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			first[k] = v[0]
+		}
+	}
+	return StringMapOrNull(first)
+}
+
+// KeepEmptyList returns prior instead of a null value when prior is a known,
+// empty list, so that an attribute configured as [] doesn't show a change
+// when the API returns no elements.
+func KeepEmptyList(prior, value types.List) types.List {
+	if value.IsNull() && !prior.IsNull() && !prior.IsUnknown() && len(prior.Elements()) == 0 {
+		return prior
+	}
+	return value
+}
+
+// KeepEmptyMap is KeepEmptyList for maps.
+func KeepEmptyMap(prior, value types.Map) types.Map {
+	if value.IsNull() && !prior.IsNull() && !prior.IsUnknown() && len(prior.Elements()) == 0 {
+		return prior
+	}
+	return value
+}
+
+func stringValues(values []string) []attr.Value {
+	elements := make([]attr.Value, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+	return elements
+}
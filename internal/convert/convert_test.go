@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestStringListOrNull(t *testing.T) {
+	if got := StringListOrNull(nil); !got.IsNull() {
+		t.Errorf("nil: expected null, got %s", got)
+	}
+	if got := StringListOrNull([]string{}); !got.IsNull() {
+		t.Errorf("empty: expected null, got %s", got)
+	}
+	want := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("b"), types.StringValue("a")})
+	if got := StringListOrNull([]string{"b", "a"}); !got.Equal(want) {
+		t.Errorf("expected %s in order, got %s", want, got)
+	}
+}
+
+func TestStringListOrEmpty(t *testing.T) {
+	for name, values := range map[string][]string{"nil": nil, "empty": {}} {
+		got := StringListOrEmpty(values)
+		if got.IsNull() || len(got.Elements()) != 0 {
+			t.Errorf("%s: expected an empty list, got %s", name, got)
+		}
+	}
+	if got := StringListOrEmpty([]string{"a"}); len(got.Elements()) != 1 {
+		t.Errorf("expected one element, got %s", got)
+	}
+}
+
+func TestStringSetOrEmpty(t *testing.T) {
+	if got := StringSetOrEmpty(nil); got.IsNull() || len(got.Elements()) != 0 {
+		t.Errorf("nil: expected an empty set, got %s", got)
+	}
+	want := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")})
+	if got := StringSetOrEmpty([]string{"b", "a"}); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestStringMapOrNull(t *testing.T) {
+	if got := StringMapOrNull(nil); !got.IsNull() {
+		t.Errorf("nil: expected null, got %s", got)
+	}
+	if got := StringMapOrNull(map[string]string{}); !got.IsNull() {
+		t.Errorf("empty: expected null, got %s", got)
+	}
+	want := types.MapValueMust(types.StringType, map[string]attr.Value{"k": types.StringValue("v")})
+	if got := StringMapOrNull(map[string]string{"k": "v"}); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestStringMapFromMultiValue(t *testing.T) {
+	got := StringMapFromMultiValue(map[string][]string{
+		"department": {"Engineering", "Platform"},
+		"location":   {"Berlin"},
+		"cost":       {},
+	})
+	want := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"department": types.StringValue("Engineering"),
+		"location":   types.StringValue("Berlin"),
+	})
+	if !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	if got := StringMapFromMultiValue(map[string][]string{"cost": nil}); !got.IsNull() {
+		t.Errorf("expected null when no key has a value, got %s", got)
+	}
+}
+
+func TestKeepEmptyList(t *testing.T) {
+	empty := types.ListValueMust(types.StringType, []attr.Value{})
+	null := types.ListNull(types.StringType)
+	full := StringListOrNull([]string{"a"})
+
+	tests := map[string]struct {
+		prior, value, want types.List
+	}{
+		"configured empty":      {prior: empty, value: null, want: empty},
+		"unset":                 {prior: null, value: null, want: null},
+		"unknown":               {prior: types.ListUnknown(types.StringType), value: null, want: null},
+		"elements removed":      {prior: full, value: null, want: null},
+		"elements from the API": {prior: empty, value: full, want: full},
+	}
+	for name, tt := range tests {
+		if got := KeepEmptyList(tt.prior, tt.value); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s, got %s", name, tt.want, got)
+		}
+	}
+}
+
+func TestKeepEmptyMap(t *testing.T) {
+	empty := types.MapValueMust(types.StringType, map[string]attr.Value{})
+	null := types.MapNull(types.StringType)
+
+	if got := KeepEmptyMap(empty, null); !got.Equal(empty) {
+		t.Errorf("configured empty: expected an empty map, got %s", got)
+	}
+	if got := KeepEmptyMap(StringMapOrNull(map[string]string{"k": "v"}), null); !got.IsNull() {
+		t.Errorf("elements removed: expected null, got %s", got)
+	}
+}
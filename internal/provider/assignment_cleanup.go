@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Maximum number of assignment deletions in flight while cleaning up
+// assignments before deleting a permission set, account, user, or group
+const assignmentCleanupConcurrency = 10
+
+// How long to wait for a user's or group's assignments to disappear after
+// cleanup before deleting the principal
+const principalAssignmentCleanupTimeout = 5 * time.Minute
+
+// Most deleted assignments listed in the Automatic Assignment Cleanup
+// warning; the rest are only logged
+const maxListedCleanupAssignments = 20
+
+// Backoff bounds for re-listing assignments after cleanup
+const (
+	assignmentCleanupInitialBackoff = 500 * time.Millisecond
+	assignmentCleanupMaxBackoff     = 10 * time.Second
+)
+
+// deleteAssignments deletes the given assignments concurrently, with at most
+// assignmentCleanupConcurrency requests in flight. It returns the IDs that
+// were deleted and a description of each failure.
+func deleteAssignments(client *Client, assignmentIDs []string) ([]string, []string) {
+	var (
+		mu           sync.Mutex
+		wg           sync.WaitGroup
+		deletedIDs   []string
+		deleteErrors []string
+	)
+
+	sem := make(chan struct{}, assignmentCleanupConcurrency)
+	for _, assignmentID := range assignmentIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(assignmentID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := client.DeletePermissionSetAssignment(assignmentID)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// Collect errors but continue trying to delete other assignments
+				deleteErrors = append(deleteErrors, fmt.Sprintf("assignment %s: %s", assignmentID, err.Error()))
+				return
+			}
+			deletedIDs = append(deletedIDs, assignmentID)
+		}(assignmentID)
+	}
+	wg.Wait()
+
+	return deletedIDs, deleteErrors
+}
+
+// waitForAssignmentsDeleted re-lists assignments with exponential backoff
+// until none of assignmentIDs remain. It reports false if they are still
+// present when timeout elapses, and returns ctx's error if ctx is done first.
+func waitForAssignmentsDeleted(ctx context.Context, client *Client, assignmentIDs []string, timeout time.Duration) (bool, error) {
+	pending := make(map[string]bool, len(assignmentIDs))
+	for _, id := range assignmentIDs {
+		pending[id] = true
+	}
+
+	deadline := time.Now().Add(timeout)
+	backoff := assignmentCleanupInitialBackoff
+
+	for {
+		assignments, err := client.ListPermissionSetAssignments()
+		if err != nil {
+			return false, err
+		}
+
+		stillExists := false
+		for _, assignment := range assignments {
+			if pending[assignment.ID] {
+				stillExists = true
+				break
+			}
+		}
+		if !stillExists {
+			return true, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false, nil
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > assignmentCleanupMaxBackoff {
+			backoff = assignmentCleanupMaxBackoff
+		}
+	}
+}
+
+// addDeleteCancelledError reports that deleting owner (e.g. `User "alice"`)
+// stopped because ctx was done while its assignments were being deleted.
+func addDeleteCancelledError(ctx context.Context, diags *diag.Diagnostics, owner string) {
+	diags.AddError(
+		"Delete Cancelled",
+		fmt.Sprintf("Stopped waiting for the permission set assignments of %s to be deleted: %s. %s was not deleted; run the destroy again to finish.",
+			owner, ctx.Err(), owner),
+	)
+}
+
+// activeAssignmentsDetail explains why owner (e.g. `User "alice"`) cannot be
+// deleted while cleanup is disabled, listing the blocking assignments.
+// managedBy describes how the prism_permission_set_assignment resources that
+// likely manage them reference owner (e.g. `principal_id = "alice"`).
+func activeAssignmentsDetail(owner, managedBy string, assignments []PermissionSetAssignment) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s still has %d permission set assignment(s), and cleanup_assignments_on_delete is disabled in the provider configuration:\n\n",
+		owner, len(assignments)))
+
+	for _, assignment := range assignments {
+		sb.WriteString("  - " + describeAssignment(assignment) + "\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nThese are likely managed by prism_permission_set_assignment resources with %s. "+
+		"Remove those resources first, or set cleanup_assignments_on_delete = true to delete the assignments automatically.", managedBy))
+
+	return sb.String()
+}
+
+// describeAssignment returns e.g. "asgn-1 (permission set ps-1, USER alice,
+// account 111111111111)".
+func describeAssignment(assignment PermissionSetAssignment) string {
+	principal := assignment.Username
+	if assignment.PrincipalType == "GROUP" {
+		principal = assignment.GroupName
+	}
+	return fmt.Sprintf("%s (permission set %s, %s %s, account %s)",
+		assignment.ID, assignment.PermissionSetID, assignment.PrincipalType, principal, assignment.AccountID)
+}
+
+// addCleanupWarning warns that the assignments of owner (e.g. `User
+// "alice"`) with deletedIDs were deleted automatically, and logs them at
+// debug level.
+func addCleanupWarning(ctx context.Context, diags *diag.Diagnostics, owner string, assignments []PermissionSetAssignment, deletedIDs []string) {
+	deleted := make(map[string]bool, len(deletedIDs))
+	for _, id := range deletedIDs {
+		deleted[id] = true
+	}
+	var descriptions []string
+	for _, assignment := range assignments {
+		if deleted[assignment.ID] {
+			descriptions = append(descriptions, describeAssignment(assignment))
+		}
+	}
+	sort.Strings(descriptions)
+
+	diags.AddWarning("Automatic Assignment Cleanup", cleanupWarningDetail(owner, descriptions))
+	tflog.Debug(ctx, "Automatically deleted permission set assignments", map[string]interface{}{
+		"owner":       owner,
+		"assignments": descriptions,
+	})
+}
+
+// cleanupWarningDetail lists the deleted assignments of owner, at most
+// maxListedCleanupAssignments of them.
+func cleanupWarningDetail(owner string, descriptions []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s had %d permission set assignment(s), which were deleted automatically before deleting it:\n\n",
+		owner, len(descriptions)))
+
+	for i, description := range descriptions {
+		if i == maxListedCleanupAssignments {
+			sb.WriteString(fmt.Sprintf("  - +%d more\n", len(descriptions)-i))
+			break
+		}
+		sb.WriteString("  - " + description + "\n")
+	}
+
+	sb.WriteString("\nThis may affect other Terraform resources if they manage these assignments; " +
+		"their next plan will show them to be created again.")
+
+	return sb.String()
+}
+
+// cleanupPrincipalAssignments prepares a user or group for deletion. When
+// the client's CleanupAssignmentsOnDelete is set it deletes the principal's
+// permission set assignments; otherwise it reports an error listing them.
+// It returns false if the principal must not be deleted.
+func cleanupPrincipalAssignments(ctx context.Context, client *Client, principalType, principalID string, diags *diag.Diagnostics) bool {
+	owner := fmt.Sprintf("User %q", principalID)
+	if principalType == "GROUP" {
+		owner = fmt.Sprintf("Group %q", principalID)
+	}
+
+	assignments, err := client.ListPermissionSetAssignmentsFiltered("", principalType, principalID)
+	if err != nil {
+		// Log warning but continue - if we can't list assignments, try to delete anyway
+		diags.AddWarning(
+			"Unable to List Assignments",
+			fmt.Sprintf("Could not list permission set assignments before deleting %s. If assignments exist, deletion may fail: %s", owner, err),
+		)
+		return true
+	}
+	if len(assignments) == 0 {
+		return true
+	}
+
+	if !client.CleanupAssignmentsOnDelete {
+		diags.AddError(
+			"Active Permission Set Assignments",
+			activeAssignmentsDetail(owner, fmt.Sprintf("principal_type = %q and principal_id = %q", principalType, principalID), assignments),
+		)
+		return false
+	}
+
+	var assignmentIDs []string
+	for _, assignment := range assignments {
+		assignmentIDs = append(assignmentIDs, assignment.ID)
+	}
+
+	deletedIDs, deleteErrors := deleteAssignments(client, assignmentIDs)
+
+	if len(deleteErrors) > 0 {
+		diags.AddWarning(
+			"Failed to Delete Some Assignments",
+			fmt.Sprintf("Could not delete all permission set assignments for %s. Deletion may fail. Errors: %s",
+				owner, strings.Join(deleteErrors, "; ")),
+		)
+	}
+
+	if len(deletedIDs) > 0 {
+		addCleanupWarning(ctx, diags, owner, assignments, deletedIDs)
+
+		// Wait for assignments to be fully deleted (backend processes asynchronously)
+		gone, err := waitForAssignmentsDeleted(ctx, client, deletedIDs, principalAssignmentCleanupTimeout)
+		if ctx.Err() != nil {
+			addDeleteCancelledError(ctx, diags, owner)
+			return false
+		}
+		if err != nil {
+			diags.AddWarning(
+				"Error Checking Assignment Status",
+				fmt.Sprintf("Could not verify assignments were deleted: %s", err),
+			)
+		} else if !gone {
+			diags.AddWarning(
+				"Assignment Deletion Timeout",
+				fmt.Sprintf("Waited %v for assignments to be deleted but they may still be processing. Deletion of %s may fail.", principalAssignmentCleanupTimeout, owner),
+			)
+		}
+	}
+
+	return true
+}
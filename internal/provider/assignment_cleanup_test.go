@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDescribeAssignment(t *testing.T) {
+	tests := map[string]struct {
+		assignment PermissionSetAssignment
+		want       string
+	}{
+		"user": {
+			assignment: PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+			want:       "asgn-1 (permission set ps-1, USER alice, account 111111111111)",
+		},
+		"group": {
+			assignment: PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"},
+			want:       "asgn-2 (permission set ps-1, GROUP developers, account 222222222222)",
+		},
+	}
+	for name, tt := range tests {
+		if got := describeAssignment(tt.assignment); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", name, tt.want, got)
+		}
+	}
+}
+
+func TestCleanupWarningDetail(t *testing.T) {
+	got := cleanupWarningDetail(`User "alice"`, []string{
+		"asgn-1 (permission set ps-1, USER alice, account 111111111111)",
+		"asgn-2 (permission set ps-2, USER alice, account 222222222222)",
+	})
+	want := `User "alice" had 2 permission set assignment(s), which were deleted automatically before deleting it:
+
+  - asgn-1 (permission set ps-1, USER alice, account 111111111111)
+  - asgn-2 (permission set ps-2, USER alice, account 222222222222)
+
+This may affect other Terraform resources if they manage these assignments; their next plan will show them to be created again.`
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestCleanupWarningDetail_CapsList(t *testing.T) {
+	var descriptions []string
+	for i := 0; i < maxListedCleanupAssignments+5; i++ {
+		descriptions = append(descriptions, fmt.Sprintf("asgn-%02d", i))
+	}
+
+	got := cleanupWarningDetail(`Permission set "ps-1"`, descriptions)
+	if !strings.Contains(got, "had 25 permission set assignment(s)") {
+		t.Errorf("expected the full count, got:\n%s", got)
+	}
+	if want := fmt.Sprintf("  - asgn-%02d\n  - +5 more\n", maxListedCleanupAssignments-1); !strings.Contains(got, want) {
+		t.Errorf("expected the list to end with %q, got:\n%s", want, got)
+	}
+	if strings.Contains(got, fmt.Sprintf("asgn-%02d", maxListedCleanupAssignments)) {
+		t.Errorf("expected at most %d assignments to be listed, got:\n%s", maxListedCleanupAssignments, got)
+	}
+}
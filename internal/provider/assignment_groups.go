@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"sort"
+	"strings"
+)
+
+// PermissionSetAssignmentGroup is the assignments of one permission set to
+// one principal across AWS accounts, which one
+// prism_permission_set_assignment manages.
+type PermissionSetAssignmentGroup struct {
+	PermissionSetID string
+	PrincipalType   string   // USER or GROUP
+	PrincipalID     string   // username or group name, as in principal_id
+	AccountIDs      []string // sorted and distinct
+	AssignmentIDs   []string // backend assignment IDs, in AccountIDs order
+}
+
+// ID returns the resource ID of the group: its assignment IDs, comma
+// separated. Importing this ID gives account_ids in AccountIDs order.
+func (g PermissionSetAssignmentGroup) ID() string {
+	return strings.Join(g.AssignmentIDs, ",")
+}
+
+// AssignmentPrincipalID returns the principal_id of assignment: the
+// username of a USER assignment and the group name of a GROUP assignment.
+func AssignmentPrincipalID(assignment PermissionSetAssignment) string {
+	if assignment.PrincipalType == "USER" {
+		return assignment.Username
+	}
+	return assignment.GroupName
+}
+
+// GroupPermissionSetAssignments groups single-account assignments by
+// permission set and principal, in the order each group first appears.
+// Within a group, account IDs are sorted, so the same assignments always
+// give the same account_ids and ID whatever order the API lists them in.
+// Duplicate assignments of an account all keep their assignment ID, so
+// deleting the group deletes them too, but the account is listed once.
+func GroupPermissionSetAssignments(assignments []PermissionSetAssignment) []PermissionSetAssignmentGroup {
+	type groupKey struct {
+		PermissionSetID string
+		PrincipalType   string
+		PrincipalID     string
+	}
+
+	index := make(map[groupKey]int)
+	var members [][]PermissionSetAssignment
+	var groups []PermissionSetAssignmentGroup
+	for _, assignment := range assignments {
+		key := groupKey{
+			PermissionSetID: assignment.PermissionSetID,
+			PrincipalType:   assignment.PrincipalType,
+			PrincipalID:     AssignmentPrincipalID(assignment),
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, PermissionSetAssignmentGroup{
+				PermissionSetID: key.PermissionSetID,
+				PrincipalType:   key.PrincipalType,
+				PrincipalID:     key.PrincipalID,
+			})
+			members = append(members, nil)
+		}
+		members[i] = append(members[i], assignment)
+	}
+
+	for i := range groups {
+		sort.SliceStable(members[i], func(a, b int) bool {
+			return members[i][a].AccountID < members[i][b].AccountID
+		})
+		for j, assignment := range members[i] {
+			if j == 0 || assignment.AccountID != members[i][j-1].AccountID {
+				groups[i].AccountIDs = append(groups[i].AccountIDs, assignment.AccountID)
+			}
+			groups[i].AssignmentIDs = append(groups[i].AssignmentIDs, assignment.ID)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupPermissionSetAssignments(t *testing.T) {
+	assignments := []PermissionSetAssignment{
+		{ID: "a-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "333333333333"},
+		{ID: "a-2", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "222222222222"},
+		{ID: "a-3", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"},
+		{ID: "a-4", PermissionSetID: "ps-2", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"},
+		// A user and a group with the same name are different principals
+		{ID: "a-5", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "developers", AccountID: "111111111111"},
+	}
+
+	want := []PermissionSetAssignmentGroup{
+		{PermissionSetID: "ps-1", PrincipalType: "GROUP", PrincipalID: "developers", AccountIDs: []string{"111111111111", "333333333333"}, AssignmentIDs: []string{"a-3", "a-1"}},
+		{PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "alice", AccountIDs: []string{"222222222222"}, AssignmentIDs: []string{"a-2"}},
+		{PermissionSetID: "ps-2", PrincipalType: "GROUP", PrincipalID: "developers", AccountIDs: []string{"111111111111"}, AssignmentIDs: []string{"a-4"}},
+		{PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "developers", AccountIDs: []string{"111111111111"}, AssignmentIDs: []string{"a-5"}},
+	}
+	got := GroupPermissionSetAssignments(assignments)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected groups\n%+v\ngot\n%+v", want, got)
+	}
+	if id := got[0].ID(); id != "a-3,a-1" {
+		t.Errorf("expected ID a-3,a-1, got %q", id)
+	}
+}
+
+func TestGroupPermissionSetAssignments_DuplicateAccounts(t *testing.T) {
+	assignments := []PermissionSetAssignment{
+		{ID: "a-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"},
+		{ID: "a-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"},
+		{ID: "a-3", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"},
+	}
+
+	got := GroupPermissionSetAssignments(assignments)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 group, got %+v", got)
+	}
+	if want := []string{"111111111111", "222222222222"}; !reflect.DeepEqual(got[0].AccountIDs, want) {
+		t.Errorf("expected account IDs %v, got %v", want, got[0].AccountIDs)
+	}
+	if want := []string{"a-2", "a-1", "a-3"}; !reflect.DeepEqual(got[0].AssignmentIDs, want) {
+		t.Errorf("expected every assignment ID %v, got %v", want, got[0].AssignmentIDs)
+	}
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported values of the provider's auth.method
+const (
+	AuthMethodAPIToken                = "api_token"
+	AuthMethodBearer                  = "bearer"
+	AuthMethodOAuth2ClientCredentials = "oauth2_client_credentials"
+)
+
+// Authenticator adds credentials to API requests. Implementations must be
+// safe for concurrent use.
+type Authenticator interface {
+	// Authenticate sets the credentials on req.
+	Authenticate(req *http.Request) error
+
+	// Invalidate is called after the API rejected the credentials with 401.
+	// It drops any cached credentials and reports whether retrying the
+	// request with fresh ones may succeed.
+	Invalidate() bool
+}
+
+// APITokenAuth sends a Prism API token in the X-API-Token header.
+type APITokenAuth struct {
+	Token string
+}
+
+func (a APITokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("X-API-Token", a.Token)
+	return nil
+}
+
+func (a APITokenAuth) Invalidate() bool { return false }
+
+// BearerAuth sends a token in an "Authorization: Bearer" header, for
+// gateways in front of the Prism API that expect one.
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+func (a BearerAuth) Invalidate() bool { return false }
+
+// How long before its expiry a cached OAuth2 access token is refreshed
+const oauth2TokenExpiryMargin = 30 * time.Second
+
+// OAuth2ClientCredentialsAuth gets access tokens from TokenURL with the
+// OAuth2 client credentials grant and sends them as bearer tokens. A token
+// is cached until shortly before it expires, or until the API rejects it.
+type OAuth2ClientCredentialsAuth struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// HTTPClient requests tokens; nil means http.DefaultClient.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (a *OAuth2ClientCredentialsAuth) Authenticate(req *http.Request) error {
+	token, err := a.accessToken()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *OAuth2ClientCredentialsAuth) Invalidate() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+	return true
+}
+
+// accessToken returns the cached access token, requesting a new one when
+// there is none or it's about to expire.
+func (a *OAuth2ClientCredentialsAuth) accessToken() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expiry.IsZero() || time.Until(a.expiry) > oauth2TokenExpiryMargin) {
+		return a.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+	req, err := http.NewRequest("POST", a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("OAuth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OAuth2 token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("OAuth2 token request: failed to read response body: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		// The body can echo the client credentials, so leave it out
+		return "", fmt.Errorf("OAuth2 token request: %s returned status %d", a.TokenURL, resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("OAuth2 token request: failed to unmarshal response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("OAuth2 token request: %s returned no access_token", a.TokenURL)
+	}
+
+	a.token = result.AccessToken
+	a.expiry = time.Time{}
+	if result.ExpiresIn > 0 {
+		a.expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return a.token, nil
+}
@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// recordAuthHeaders answers every request with an empty group list and
+// records the credentials each one carried.
+func recordAuthHeaders(apiTokens, authorizations *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*apiTokens = append(*apiTokens, r.Header.Get("X-API-Token"))
+		*authorizations = append(*authorizations, r.Header.Get("Authorization"))
+		writeAPIData(w, []Group{})
+	})
+}
+
+func TestClient_APITokenAuth(t *testing.T) {
+	var apiTokens, authorizations []string
+	client := newTestClient(t, recordAuthHeaders(&apiTokens, &authorizations))
+
+	if _, err := client.ListGroups(); err != nil {
+		t.Fatal(err)
+	}
+	if apiTokens[0] != "test-token" || authorizations[0] != "" {
+		t.Errorf("expected only X-API-Token, got X-API-Token %q and Authorization %q", apiTokens[0], authorizations[0])
+	}
+}
+
+func TestClient_BearerAuth(t *testing.T) {
+	var apiTokens, authorizations []string
+	client := newTestClient(t, recordAuthHeaders(&apiTokens, &authorizations))
+	client.Auth = BearerAuth{Token: "gateway-token"}
+
+	if _, err := client.ListGroups(); err != nil {
+		t.Fatal(err)
+	}
+	if apiTokens[0] != "" || authorizations[0] != "Bearer gateway-token" {
+		t.Errorf("expected only a bearer token, got X-API-Token %q and Authorization %q", apiTokens[0], authorizations[0])
+	}
+}
+
+// tokenServer issues access tokens "token-1", "token-2", ... that expire
+// after expiresIn seconds, checking the client credentials request.
+func tokenServer(t *testing.T, expiresIn int, requests *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" || id != "prism-client" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.Form.Get("scope"); got != "prism.read prism.write" {
+			t.Errorf("expected both scopes to be requested, got %q", got)
+		}
+		n := atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newOAuth2Client(t *testing.T, api http.Handler, tokens *httptest.Server) *Client {
+	t.Helper()
+	client := newTestClient(t, api)
+	client.Auth = &OAuth2ClientCredentialsAuth{
+		TokenURL:     tokens.URL + "/oauth2/token",
+		ClientID:     "prism-client",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"prism.read", "prism.write"},
+		HTTPClient:   tokens.Client(),
+	}
+	return client
+}
+
+func TestClient_OAuth2ClientCredentials(t *testing.T) {
+	var tokenRequests int32
+	tokens := tokenServer(t, 3600, &tokenRequests)
+	var apiTokens, authorizations []string
+	client := newOAuth2Client(t, recordAuthHeaders(&apiTokens, &authorizations), tokens)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ListGroups(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("expected the access token to be cached, got %d token requests", tokenRequests)
+	}
+	for i, authorization := range authorizations {
+		if authorization != "Bearer token-1" || apiTokens[i] != "" {
+			t.Errorf("request %d: expected the access token, got Authorization %q and X-API-Token %q", i, authorization, apiTokens[i])
+		}
+	}
+}
+
+func TestClient_OAuth2RefreshesBeforeExpiry(t *testing.T) {
+	var tokenRequests int32
+	// Tokens expire within oauth2TokenExpiryMargin, so each request refreshes
+	tokens := tokenServer(t, 10, &tokenRequests)
+	var apiTokens, authorizations []string
+	client := newOAuth2Client(t, recordAuthHeaders(&apiTokens, &authorizations), tokens)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListGroups(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokenRequests != 2 || authorizations[1] != "Bearer token-2" {
+		t.Errorf("expected a fresh token for each request, got %d token requests and %v", tokenRequests, authorizations)
+	}
+}
+
+func TestClient_OAuth2RefreshesOn401(t *testing.T) {
+	var tokenRequests int32
+	tokens := tokenServer(t, 3600, &tokenRequests)
+
+	// The gateway revoked token-1
+	var authorizations []string
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			writeAPIError(w, http.StatusUnauthorized, "token revoked")
+			return
+		}
+		writeAPIData(w, []Group{})
+	})
+	client := newOAuth2Client(t, api, tokens)
+
+	if _, err := client.ListGroups(); err != nil {
+		t.Fatalf("expected the request to succeed with a refreshed token, got %s", err)
+	}
+	if tokenRequests != 2 || len(authorizations) != 2 {
+		t.Errorf("expected one retry with a new token, got %d token requests and %v", tokenRequests, authorizations)
+	}
+}
+
+func TestClient_401IsRetriedOnce(t *testing.T) {
+	var tokenRequests int32
+	tokens := tokenServer(t, 3600, &tokenRequests)
+	var calls int32
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeAPIError(w, http.StatusUnauthorized, "forbidden client")
+	})
+
+	_, err := newOAuth2Client(t, api, tokens).ListGroups()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the 401 to be returned, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a single retry, got %d calls", calls)
+	}
+
+	// Static credentials can't be refreshed, so they aren't retried
+	calls = 0
+	client := newTestClient(t, api)
+	if _, err := client.ListGroups(); err == nil {
+		t.Error("expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry with an API token, got %d calls", calls)
+	}
+}
+
+func TestClient_OAuth2TokenErrors(t *testing.T) {
+	var tokenRequests int32
+	tokens := tokenServer(t, 3600, &tokenRequests)
+	client := newOAuth2Client(t, http.NotFoundHandler(), tokens)
+	client.Auth.(*OAuth2ClientCredentialsAuth).ClientSecret = "wrong"
+
+	_, err := client.ListGroups()
+	if err == nil {
+		t.Fatal("expected an error for rejected client credentials")
+	}
+	if got := err.Error(); !strings.Contains(got, "OAuth2 token request") || !strings.Contains(got, "status 401") || strings.Contains(got, "wrong") {
+		t.Errorf("expected a token request error without the secret, got %q", got)
+	}
+}
+
+func TestProviderAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	nullAuth := ProviderAuthModel{
+		Method:       types.StringNull(),
+		Token:        types.StringNull(),
+		TokenURL:     types.StringNull(),
+		ClientID:     types.StringNull(),
+		ClientSecret: types.StringNull(),
+		Scopes:       types.ListNull(types.StringType),
+	}
+
+	var diags diag.Diagnostics
+	if auth := providerAuthenticator(ctx, AuthMethodAPIToken, nil, "api-token", &diags); auth != (APITokenAuth{Token: "api-token"}) || diags.HasError() {
+		t.Errorf("expected API token auth without a block, got %#v (%v)", auth, diags)
+	}
+
+	// bearer falls back to api_token
+	if auth := providerAuthenticator(ctx, AuthMethodBearer, &nullAuth, "api-token", &diags); auth != (BearerAuth{Token: "api-token"}) || diags.HasError() {
+		t.Errorf("expected a bearer token from api_token, got %#v (%v)", auth, diags)
+	}
+	withToken := nullAuth
+	withToken.Token = types.StringValue("gateway-token")
+	if auth := providerAuthenticator(ctx, AuthMethodBearer, &withToken, "api-token", &diags); auth != (BearerAuth{Token: "gateway-token"}) || diags.HasError() {
+		t.Errorf("expected auth.token to win, got %#v (%v)", auth, diags)
+	}
+	providerAuthenticator(ctx, AuthMethodBearer, &nullAuth, "", &diags)
+	if !hasDiagnostic(diags, "Missing Authentication Setting") {
+		t.Errorf("expected a missing token error, got %v", diags)
+	}
+
+	oauth2 := nullAuth
+	oauth2.TokenURL = types.StringValue("https://auth.example.com/token")
+	oauth2.ClientID = types.StringValue("prism-client")
+	oauth2.ClientSecret = types.StringUnknown()
+	oauth2.Scopes = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("prism.read")})
+	diags = nil
+	auth := providerAuthenticator(ctx, AuthMethodOAuth2ClientCredentials, &oauth2, "", &diags)
+	if !hasDiagnostic(diags, "Unknown Authentication Setting") || diags.ErrorsCount() != 1 {
+		t.Errorf("expected only an unknown client_secret error, got %v", diags)
+	}
+	if got := auth.(*OAuth2ClientCredentialsAuth); got.ClientID != "prism-client" || len(got.Scopes) != 1 {
+		t.Errorf("expected the OAuth2 settings to be read, got %#v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// backendFeature is an attribute that older Prism backends don't support:
+// they answer 404 for it or silently ignore it.
+type backendFeature struct {
+	Attribute  string
+	MinVersion backendVersion
+}
+
+// Attributes that need a minimum backend version
+var (
+	featureOwnerEmails = backendFeature{Attribute: "owner_emails", MinVersion: backendVersion{2, 3, 0}}
+)
+
+// backendFeatures lists every backendFeature, for the capabilities of the
+// prism_version data source.
+var backendFeatures = []backendFeature{
+	featureOwnerEmails,
+}
+
+// backendVersion is a Prism backend's major.minor.patch version.
+type backendVersion [3]int
+
+// parseBackendVersion parses versions such as "2.4", "2.4.1" and
+// "v2.4.1-rc.1+abc". A pre-release or build suffix is ignored.
+func parseBackendVersion(s string) (backendVersion, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return backendVersion{}, false
+	}
+
+	var v backendVersion
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return backendVersion{}, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// atLeast reports whether v is minimum or later.
+func (v backendVersion) atLeast(minimum backendVersion) bool {
+	for i := range v {
+		if v[i] != minimum[i] {
+			return v[i] > minimum[i]
+		}
+	}
+	return true
+}
+
+// String returns v as "2.4" when its patch version is zero, or "2.4.1".
+func (v backendVersion) String() string {
+	if v[2] == 0 {
+		return fmt.Sprintf("%d.%d", v[0], v[1])
+	}
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// versionProbe caches the backend version reported by GET /api/v1/version.
+// The probe runs on whichever request comes first, while plans of other
+// resources may be reading the result, so the version is stored atomically.
+type versionProbe struct {
+	once    sync.Once
+	version atomic.Pointer[backendVersion]
+}
+
+// ProbeBackendVersion asks the backend for its version, once per client.
+// Backends without the version endpoint, or that can't be reached, leave
+// the version unknown, which turns feature warnings off rather than
+// failing: the requests that follow report any real connection problem.
+// The request goes through exchange, as doRequestWithOptions would probe
+// again.
+func (c *Client) ProbeBackendVersion(ctx context.Context) {
+	c.version.once.Do(func() {
+		body, err := c.exchange(ctx, "GET", "/api/v1/version", nil, requestOptions{unscoped: true})
+		var result struct {
+			Version string `json:"version"`
+		}
+		if err == nil {
+			err = json.Unmarshal(body, &result)
+		}
+		if err != nil {
+			tflog.Debug(ctx, "Prism backend version unavailable", map[string]interface{}{"error": err.Error()})
+			return
+		}
+
+		version, ok := parseBackendVersion(result.Version)
+		if !ok {
+			tflog.Debug(ctx, "Unrecognized Prism backend version", map[string]interface{}{"version": result.Version})
+			return
+		}
+		c.version.version.Store(&version)
+		tflog.Debug(ctx, "Prism backend version", map[string]interface{}{"version": version.String()})
+	})
+}
+
+// reportedVersion returns the version ProbeBackendVersion found, if any.
+func (c *Client) reportedVersion() (backendVersion, bool) {
+	if version := c.version.version.Load(); version != nil {
+		return *version, true
+	}
+	return backendVersion{}, false
+}
+
+// warnUnsupportedFeature adds a warning at attributePath when the backend
+// reports a version older than feature needs. Nothing is reported when the
+// version is unknown, which includes before the client's first request:
+// plans don't probe the backend themselves.
+func (c *Client) warnUnsupportedFeature(diags *diag.Diagnostics, attributePath path.Path, feature backendFeature) {
+	version, known := c.reportedVersion()
+	if !known || version.atLeast(feature.MinVersion) {
+		return
+	}
+	diags.AddAttributeWarning(
+		attributePath,
+		"Attribute Not Supported by Prism Backend",
+		fmt.Sprintf("%s requires Prism >= %s; the configured backend reports %s and the attribute will be ignored. "+
+			"Upgrade the Prism backend, or remove %s from the configuration to silence this warning.",
+			feature.Attribute, feature.MinVersion, version, feature.Attribute),
+	)
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseBackendVersion(t *testing.T) {
+	tests := map[string]struct {
+		want backendVersion
+		ok   bool
+	}{
+		"2.4":          {want: backendVersion{2, 4, 0}, ok: true},
+		"2.4.1":        {want: backendVersion{2, 4, 1}, ok: true},
+		"v2.4.1-rc.1":  {want: backendVersion{2, 4, 1}, ok: true},
+		"2.10.0+build": {want: backendVersion{2, 10, 0}, ok: true},
+		"2":            {},
+		"2.x":          {},
+		"2.4.1.7":      {},
+		"":             {},
+	}
+	for input, tt := range tests {
+		got, ok := parseBackendVersion(input)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseBackendVersion(%q) = %v, %t; expected %v, %t", input, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if !(backendVersion{2, 10, 0}).atLeast(backendVersion{2, 3, 0}) {
+		t.Error("expected 2.10 to be at least 2.3")
+	}
+	if (backendVersion{2, 2, 9}).atLeast(backendVersion{2, 3, 0}) {
+		t.Error("expected 2.2.9 to be older than 2.3")
+	}
+}
+
+// versionServer answers GET /api/v1/version with version, or with status
+// when version is empty, and passes other requests to next.
+func versionServer(version string, status int, next http.Handler, calls *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/version" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if calls != nil {
+			atomic.AddInt32(calls, 1)
+		}
+		if version == "" {
+			writeAPIError(w, status, "not found")
+			return
+		}
+		writeAPIData(w, map[string]string{"version": version})
+	})
+}
+
+func TestClient_ProbeBackendVersion(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, versionServer("2.4.1", 0, newFakePrism(), &calls))
+
+	client.ProbeBackendVersion(context.Background())
+	client.ProbeBackendVersion(context.Background())
+	version, known := client.reportedVersion()
+	if !known || version != (backendVersion{2, 4, 1}) {
+		t.Errorf("expected version 2.4.1, got %v (known %t)", version, known)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("expected the version to be probed once, got %d calls", calls)
+	}
+
+	client = newTestClient(t, versionServer("", http.StatusNotFound, newFakePrism(), nil))
+	client.ProbeBackendVersion(context.Background())
+	if _, known := client.reportedVersion(); known {
+		t.Error("expected an unknown version without the version endpoint")
+	}
+}
+
+func TestAWSAccountResource_OwnerEmailsFeatureGate(t *testing.T) {
+	withOwners := testAWSAccountModel("123456789012", "Production")
+	withOwners.OwnerEmails = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("alice@example.com")})
+
+	tests := map[string]struct {
+		version     string
+		plan        *AWSAccountResourceModel
+		wantWarning bool
+	}{
+		"older backend":                {version: "2.2", plan: withOwners, wantWarning: true},
+		"supported backend":            {version: "2.3.0", plan: withOwners},
+		"newer backend":                {version: "3.0", plan: withOwners},
+		"unknown version":              {plan: withOwners},
+		"older backend without owners": {version: "2.2", plan: testAWSAccountModel("123456789012", "Production")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, versionServer(tt.version, http.StatusNotFound, newFakePrism(), nil))
+			client.ProbeBackendVersion(context.Background())
+			h := newResourceHarness(t, NewAWSAccountResource(), client)
+
+			diags := h.modifyPlan(tt.plan)
+			if diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags)
+			}
+			detail := ""
+			for _, d := range diags.Warnings() {
+				if d.Summary() == "Attribute Not Supported by Prism Backend" {
+					detail = d.Detail()
+				}
+			}
+			if tt.wantWarning != (detail != "") {
+				t.Fatalf("expected a warning: %t, got %v", tt.wantWarning, diags)
+			}
+			if tt.wantWarning && !strings.Contains(detail, "owner_emails requires Prism >= 2.3; the configured backend reports 2.2") {
+				t.Errorf("expected the warning to name the versions, got %q", detail)
+			}
+		})
+	}
+}
+
+func TestAWSAccountResource_OwnerEmailsFeatureGateDuringFirstRequest(t *testing.T) {
+	// Terraform plans one resource while it reads another, so ModifyPlan can
+	// check the version while the first request's probe stores it. Run with
+	// -race, as CI does, to catch an unsynchronized read.
+	client := newTestClient(t, versionServer("2.2", 0, newFakePrism(), nil))
+	client.ProbeVersion = true
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+	plan := testAWSAccountModel("123456789012", "Production")
+	plan.OwnerEmails = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("alice@example.com")})
+
+	// ModifyPlan is called directly rather than through the harness, as t's
+	// locks would order it after the probe and hide a race
+	raw := h.state(plan).Raw
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: h.schema, Raw: raw},
+		Plan:   tfsdk.Plan{Schema: h.schema, Raw: raw},
+		State:  h.nullState(),
+	}
+	modifier := h.r.(resource.ResourceWithModifyPlan)
+
+	start, listed := make(chan struct{}), make(chan error, 1)
+	go func() {
+		<-start
+		_, err := client.ListAWSAccounts()
+		listed <- err
+	}()
+	close(start)
+
+	var err error
+planning:
+	for {
+		select {
+		case err = <-listed:
+			break planning
+		default:
+			resp := resource.ModifyPlanResponse{Plan: req.Plan}
+			modifier.ModifyPlan(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+		}
+	}
+	if err != nil {
+		t.Fatalf("list accounts: %s", err)
+	}
+
+	// Once the probe is done, plans see the version
+	if diags := h.modifyPlan(plan); !hasDiagnostic(diags, "Attribute Not Supported by Prism Backend") {
+		t.Errorf("expected a warning after the first request, got %v", diags)
+	}
+}
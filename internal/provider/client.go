@@ -4,10 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,31 +25,85 @@ import (
 var apiCallCounter int64
 var apiStartTime = time.Now()
 
-// First request serialization mechanism
-// Ensures the first API request completes before allowing parallel requests,
-// giving the backend time to cache token details.
-var (
-	firstRequestOnce sync.Once
-	firstRequestDone = make(chan struct{})
-)
+// firstRequestGate ensures a client's first API request completes before
+// allowing parallel requests, giving the backend time to cache token details.
+// The zero value is ready to use.
+type firstRequestGate struct {
+	mu   sync.Mutex
+	done atomic.Bool
+}
 
-// signalFirstRequestComplete signals that the first request has completed.
-// Safe to call multiple times (only first call has effect).
-func signalFirstRequestComplete() {
-	select {
-	case <-firstRequestDone:
-		// Already closed
-	default:
-		close(firstRequestDone)
+// enter blocks until the first request has completed, unless the caller is
+// making the first request. The returned function must be called when the
+// request finishes.
+func (g *firstRequestGate) enter() func() {
+	if g.done.Load() {
+		return func() {}
+	}
+
+	g.mu.Lock()
+	if g.done.Load() {
+		// Another request completed first while we waited
+		g.mu.Unlock()
+		return func() {}
+	}
+	return func() {
+		g.done.Store(true)
+		g.mu.Unlock()
 	}
 }
 
-// Client is the CloudKeeper API client
+// Client is the CloudKeeper API client.
+//
+// A Client is shared by every resource and data source and is safe for
+// concurrent use by multiple goroutines. Its exported fields must not be
+// changed once the client is in use; unexported mutable state is guarded by
+// its own locks.
 type Client struct {
 	BaseURL        string
 	PrismSubdomain string
 	HTTPClient     *http.Client
 	Token          string
+
+	// CleanupAssignmentsOnDelete controls whether deleting a permission set,
+	// AWS account, user, or group first deletes its permission set
+	// assignments. When false, such deletes fail and list the assignments.
+	CleanupAssignmentsOnDelete bool
+
+	// ValidatePolicyARNs controls whether plans check that managed policy
+	// ARNs look like IAM policy ARNs in a standard partition.
+	ValidatePolicyARNs bool
+
+	// ProviderVersion is the version of the provider using this client, as
+	// reported by the prism_version data source.
+	ProviderVersion string
+
+	// CacheReads turns on the read cache, which serves repeated GETs of AWS
+	// accounts and permission sets from memory until a write changes them.
+	// See readCache.
+	CacheReads bool
+
+	// ProbeVersion makes the first API request ask the backend for its
+	// version first; see ProbeBackendVersion. Configure sets it instead of
+	// probing, so that operations that send no requests, such as a plan
+	// with -refresh=false, never touch the network.
+	ProbeVersion bool
+
+	// Auth adds credentials to each request. Nil means Token is sent in the
+	// X-API-Token header.
+	Auth Authenticator
+
+	// TimingLog receives a line for each API call with its duration. Nil
+	// means os.Stderr; io.Discard turns the lines off.
+	TimingLog io.Writer
+
+	firstRequest    firstRequestGate
+	assignmentCache assignmentListCache
+	memberRemoval   memberRemovalProbe
+	version         versionProbe
+	reads           readCache
+
+	permissionSetWrites keyedLocks
 }
 
 // NewClient creates a new CloudKeeper API client
@@ -53,128 +114,270 @@ func NewClient(baseURL, prismSubdomain, token string) *Client {
 		HTTPClient: &http.Client{
 			Timeout: 120 * time.Second,
 		},
-		Token: token,
+		Token:                      token,
+		CleanupAssignmentsOnDelete: true,
+		ValidatePolicyARNs:         true,
 	}
 }
 
-// doRequestRaw performs an HTTP request without customer path prefix
-func (c *Client) doRequestRaw(method, path string, body interface{}) ([]byte, error) {
-	// First request serialization - ensure first request completes before others proceed
-	isFirst := false
-	firstRequestOnce.Do(func() {
-		isFirst = true
-	})
-	if !isFirst {
-		// Wait for first request to complete
-		<-firstRequestDone
+// logTiming writes the timing line of an API call to c.TimingLog.
+func (c *Client) logTiming(callNum int64, sinceStart time.Duration, method, reqURL string, elapsed time.Duration) {
+	w := c.TimingLog
+	if w == nil {
+		w = os.Stderr
 	}
-	// Signal completion when done (only effective for first request)
-	defer signalFirstRequestComplete()
+	fmt.Fprintf(w, "[API TIMING] #%d @%.2fs | %s %s | Response: %v\n", callNum, sinceStart.Seconds(), method, reqURL, elapsed)
+}
 
-	var reqBody io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonBody)
+// send sends the request newRequest builds, with c's credentials. When the
+// API rejects the credentials with 401 and the authenticator may get fresh
+// ones, the request is built and sent once more. Each attempt is logged to
+// c.TimingLog.
+func (c *Client) send(httpClient *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	auth := c.Auth
+	if auth == nil {
+		auth = APITokenAuth{Token: c.Token}
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL+path, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if err := auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Token", c.Token)
+		callNum := atomic.AddInt64(&apiCallCounter, 1)
+		sinceStart := time.Since(apiStartTime)
+		startTime := time.Now()
+		resp, err := httpClient.Do(req)
+		elapsed := time.Since(startTime)
+		c.logTiming(callNum, sinceStart, req.Method, req.URL.String(), elapsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
 
-	callNum := atomic.AddInt64(&apiCallCounter, 1)
-	sinceStart := time.Since(apiStartTime)
-	startTime := time.Now()
-	resp, err := c.HTTPClient.Do(req)
-	elapsed := time.Since(startTime)
-	fmt.Fprintf(os.Stderr, "[API TIMING] #%d @%.2fs | %s %s | Response: %v\n", callNum, sinceStart.Seconds(), method, c.BaseURL+path, elapsed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 1 || !auth.Invalidate() {
+			return resp, nil
+		}
+		resp.Body.Close()
 	}
-	defer resp.Body.Close()
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+// bodyReader returns a reader for a JSON request body, or nil for none.
+func bodyReader(jsonBody []byte) io.Reader {
+	if jsonBody == nil {
+		return nil
 	}
+	return bytes.NewReader(jsonBody)
+}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
-	}
+// requestOptions select the steps of the request pipeline a call skips.
+// The zero value is the usual customer-scoped, unwrapped request.
+type requestOptions struct {
+	// unscoped sends path as is, without the /api/v1/customers/<subdomain>
+	// prefix, for endpoints above the customer such as the backend version
+	// and the customer admin routes.
+	unscoped bool
 
-	return respBody, nil
+	// raw returns the response body as is instead of unwrapping the data
+	// field of the standard API response.
+	raw bool
+
+	// uncached skips the read cache, for polls that wait for a change.
+	uncached bool
 }
 
 // doRequest performs an HTTP request with customer path prefix and unwraps the API response
 func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error) {
+	return c.doRequestContext(context.Background(), method, path, body)
+}
+
+// doRequestContext is doRequest bound to ctx.
+func (c *Client) doRequestContext(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	return c.doRequestWithOptions(ctx, method, path, body, requestOptions{})
+}
+
+// doRequestWithOptions is the request pipeline every API call goes through:
+// the read cache, first-request serialization, authentication, timing logs,
+// typed APIErrors with redacted bodies, and unwrapping, with the steps opts
+// turns off. When ctx carries a deadline it replaces the HTTP client's fixed
+// timeout, so long-running calls such as account onboarding can be tuned
+// through resource timeouts.
+func (c *Client) doRequestWithOptions(ctx context.Context, method, path string, body interface{}, opts requestOptions) ([]byte, error) {
+	if c.ProbeVersion {
+		c.ProbeBackendVersion(ctx)
+	}
+
+	// The read cache holds unwrapped customer-scoped responses only
+	if c.CacheReads && !opts.unscoped && !opts.raw {
+		if method != "GET" {
+			defer c.reads.invalidate(path)
+		} else if _, ok := cachedReadPrefix(path); ok && !opts.uncached {
+			return c.reads.get(path, func() ([]byte, error) {
+				return c.exchange(ctx, method, path, body, opts)
+			})
+		}
+	}
+	return c.exchange(ctx, method, path, body, opts)
+}
+
+// exchange sends one request for doRequestWithOptions and reads its response.
+func (c *Client) exchange(ctx context.Context, method, path string, body interface{}, opts requestOptions) ([]byte, error) {
 	// First request serialization - ensure first request completes before others proceed
-	isFirst := false
-	firstRequestOnce.Do(func() {
-		isFirst = true
-	})
-	if !isFirst {
-		// Wait for first request to complete
-		<-firstRequestDone
+	defer c.firstRequest.enter()()
+
+	// path arrives with its parameters already escaped (see escapePath)
+	fullPath := path
+	if !opts.unscoped {
+		fullPath = fmt.Sprintf("/api/v1/customers/%s%s", url.PathEscape(c.PrismSubdomain), path)
 	}
-	// Signal completion when done (only effective for first request)
-	defer signalFirstRequestComplete()
 
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("%s %s: failed to marshal request body: %w", method, fullPath, err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	if !strings.HasPrefix(c.BaseURL, "https://") {
-		c.BaseURL = "https://" + c.BaseURL
-	}
-	url := fmt.Sprintf("%s/api/v1/customers/%s%s", c.BaseURL, c.PrismSubdomain, path)
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Don't write the normalized URL back to c.BaseURL: requests run concurrently
+	baseURL := c.BaseURL
+	if !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "https://" + baseURL
 	}
+	reqURL := baseURL + fullPath
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Token", c.Token)
+	httpClient := c.HTTPClient
+	if _, ok := ctx.Deadline(); ok {
+		withoutTimeout := *c.HTTPClient
+		withoutTimeout.Timeout = 0
+		httpClient = &withoutTimeout
+	}
 
-	callNum := atomic.AddInt64(&apiCallCounter, 1)
-	sinceStart := time.Since(apiStartTime)
-	startTime := time.Now()
-	resp, err := c.HTTPClient.Do(req)
-	elapsed := time.Since(startTime)
-	fmt.Fprintf(os.Stderr, "[API TIMING] #%d @%.2fs | %s %s | Response: %v\n", callNum, sinceStart.Seconds(), method, url, elapsed)
+	resp, err := c.send(httpClient, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, method, reqURL, bodyReader(jsonBody))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("%s %s: %w", method, fullPath, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%s %s: failed to read response body: %w", method, fullPath, err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{Method: method, Path: fullPath, StatusCode: resp.StatusCode, Body: c.redactSecrets(string(respBody)), RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	if opts.raw {
+		return respBody, nil
 	}
 
 	// Unwrap the API response to extract the data field
 	data, err := unwrapAPIResponse(respBody)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s %s: %w", method, fullPath, err)
 	}
 
 	return data, nil
 }
 
+// secretFieldPattern matches JSON string fields whose names mark them as
+// secrets, e.g. "clientSecret": "..." in an identity provider config that an
+// error response echoes back.
+var secretFieldPattern = regexp.MustCompile(`"([A-Za-z_]*(?i:secret|password|token)[A-Za-z_]*)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+
+// redactSecrets removes the API token and the values of secret fields from an
+// error response body, which ends up in diagnostics and logs.
+func (c *Client) redactSecrets(body string) string {
+	if c.Token != "" {
+		body = strings.ReplaceAll(body, c.Token, "[REDACTED]")
+	}
+	return secretFieldPattern.ReplaceAllString(body, `"$1":"[REDACTED]"`)
+}
+
+// APIError is returned for API responses with an HTTP error status. Path is
+// the escaped request path, including the customer prefix for scoped calls.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+
+	// RetryAfter is how long the response's Retry-After header asks callers
+	// to wait before retrying, or zero without one.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: API error (%d): %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a
+// number of seconds or an HTTP date. It returns zero for an empty, invalid or
+// past value.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
+// isNotFoundError reports whether err is an API error with status 404.
+// Match on the status rather than the message text, which includes the
+// request path and so may contain "404" anywhere.
+func isNotFoundError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// isNotImplementedError reports whether err is an API error with status
+// 501, which some deployments return for endpoints they don't support.
+func isNotImplementedError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotImplemented
+}
+
+// isConflictError reports whether err is an API error with status 409.
+func isConflictError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// escapePath builds an API path from format, escaping each parameter as a
+// single path segment so that names containing "/", "?", "#", spaces, or
+// non-ASCII characters address the intended resource.
+func escapePath(format string, params ...string) string {
+	escaped := make([]interface{}, len(params))
+	for i, param := range params {
+		escaped[i] = escapePathSegment(param)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// escapePathSegment escapes s for use as one path segment. url.PathEscape
+// leaves "." and ".." as is, and proxies resolve those as relative segments,
+// so they are percent-encoded too. It also leaves ":", which the backend
+// routes as a custom action ("members:remove"), so colons are encoded too.
+func escapePathSegment(s string) string {
+	if s == "." || s == ".." {
+		return strings.ReplaceAll(s, ".", "%2E")
+	}
+	return strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+}
+
 // APIResponse represents the standard API response wrapper
 type APIResponse struct {
 	Success bool            `json:"success"`
@@ -209,7 +412,32 @@ type AWSAccount struct {
 	OwnerEmails []string `json:"owner_emails,omitempty"`
 }
 
-func (c *Client) CreateAWSAccount(account *AWSAccount) (*AWSAccount, error) {
+// UnmarshalJSON reads the account name and owner emails from either field
+// the backend has used for them: "name" and "owner_emails", or
+// "accountName" and "ownerEmails" in newer versions of GET /aws-accounts and
+// GET /aws-accounts/{id}. Accounts are still written with the snake_case
+// fields.
+func (a *AWSAccount) UnmarshalJSON(data []byte) error {
+	type plain AWSAccount
+	var account struct {
+		plain
+		CamelAccountName string   `json:"accountName"`
+		CamelOwnerEmails []string `json:"ownerEmails"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return err
+	}
+	*a = AWSAccount(account.plain)
+	if a.AccountName == "" {
+		a.AccountName = account.CamelAccountName
+	}
+	if len(a.OwnerEmails) == 0 {
+		a.OwnerEmails = account.CamelOwnerEmails
+	}
+	return nil
+}
+
+func (c *Client) CreateAWSAccount(ctx context.Context, account *AWSAccount) (*AWSAccount, error) {
 	// Use the onboard endpoint which does full account setup (IdP/OIDC)
 	requestBody := map[string]interface{}{
 		"accountId":   account.AccountID,
@@ -221,43 +449,32 @@ func (c *Client) CreateAWSAccount(account *AWSAccount) (*AWSAccount, error) {
 		requestBody["ownerEmails"] = account.OwnerEmails
 	}
 
-	body, err := c.doRequest("POST", "/accounts/onboard", requestBody)
+	body, err := retryOnboard(ctx, account.AccountID, func() ([]byte, error) {
+		return c.doRequestContext(ctx, "POST", "/accounts/onboard", requestBody)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	// The onboard endpoint returns a complex structure with the account nested
 	var response struct {
-		Account struct {
-			ID          string   `json:"id"`
-			AccountID   string   `json:"account_id"`
-			Name        string   `json:"name"`
-			Status      string   `json:"status"`
-			Region      string   `json:"region,omitempty"`
-			RoleArn     string   `json:"role_arn,omitempty"`
-			OwnerEmails []string `json:"owner_emails,omitempty"`
-		} `json:"account"`
+		Account AWSAccount `json:"account"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Convert to AWSAccount
-	result := &AWSAccount{
-		ID:          response.Account.ID,
-		AccountID:   response.Account.AccountID,
-		AccountName: response.Account.Name,
-		Region:      response.Account.Region,
-		RoleArn:     response.Account.RoleArn,
-		OwnerEmails: response.Account.OwnerEmails,
-	}
-
-	return result, nil
+	return &response.Account, nil
 }
 
 func (c *Client) GetAWSAccount(accountID string) (*AWSAccount, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/aws-accounts/%s", accountID), nil)
+	return c.getAWSAccount(accountID, requestOptions{})
+}
+
+// getAWSAccount is GetAWSAccount with opts, e.g. to bypass the read cache.
+func (c *Client) getAWSAccount(accountID string, opts requestOptions) (*AWSAccount, error) {
+	body, err := c.doRequestWithOptions(context.Background(), "GET", escapePath("/aws-accounts/%s", accountID), nil, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +488,7 @@ func (c *Client) GetAWSAccount(accountID string) (*AWSAccount, error) {
 }
 
 func (c *Client) UpdateAWSAccount(accountID string, account *AWSAccount) (*AWSAccount, error) {
-	body, err := c.doRequest("PUT", fmt.Sprintf("/aws-accounts/%s", accountID), account)
+	body, err := c.doRequest("PUT", escapePath("/aws-accounts/%s", accountID), account)
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +502,7 @@ func (c *Client) UpdateAWSAccount(accountID string, account *AWSAccount) (*AWSAc
 }
 
 func (c *Client) DeleteAWSAccount(accountID string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/aws-accounts/%s/deboard", accountID), nil)
+	_, err := c.doRequest("DELETE", escapePath("/aws-accounts/%s/deboard", accountID), nil)
 	return err
 }
 
@@ -303,6 +520,63 @@ func (c *Client) ListAWSAccounts() ([]AWSAccount, error) {
 	return result, nil
 }
 
+// CheckAccess makes the cheapest authenticated request there is, listing at
+// most one AWS account, so that a wrong subdomain or token shows up before
+// any real work. The error is the request's, e.g. an *APIError with status
+// 401 for a rejected token.
+func (c *Client) CheckAccess() error {
+	_, err := c.doRequest("GET", "/aws-accounts?max=1", nil)
+	return err
+}
+
+// accountOwners is the body of the AWS account owners sub-resource.
+type accountOwners struct {
+	AccountID   string   `json:"account_id,omitempty"`
+	OwnerEmails []string `json:"owner_emails"`
+}
+
+// GetAWSAccountOwners returns the owner emails used to route JIT approvals
+// for an AWS account.
+func (c *Client) GetAWSAccountOwners(accountID string) ([]string, error) {
+	return c.getAWSAccountOwners(accountID, requestOptions{})
+}
+
+// getAWSAccountOwners is GetAWSAccountOwners with opts, e.g. to bypass the
+// read cache.
+func (c *Client) getAWSAccountOwners(accountID string, opts requestOptions) ([]string, error) {
+	body, err := c.doRequestWithOptions(context.Background(), "GET", escapePath("/aws-accounts/%s/owners", accountID), nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result accountOwners
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return result.OwnerEmails, nil
+}
+
+// SetAWSAccountOwners replaces the owner emails of an AWS account without
+// touching its other settings. An empty list removes all owners.
+func (c *Client) SetAWSAccountOwners(accountID string, ownerEmails []string) ([]string, error) {
+	if ownerEmails == nil {
+		ownerEmails = []string{}
+	}
+
+	body, err := c.doRequest("PUT", escapePath("/aws-accounts/%s/owners", accountID), accountOwners{OwnerEmails: ownerEmails})
+	if err != nil {
+		return nil, err
+	}
+
+	var result accountOwners
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return result.OwnerEmails, nil
+}
+
 // ========== Permission Set Operations ==========
 
 type PermissionSet struct {
@@ -312,6 +586,11 @@ type PermissionSet struct {
 	SessionDuration string            `json:"session_duration,omitempty"`
 	ManagedPolicies []string          `json:"managed_policies,omitempty"`
 	InlinePolicies  map[string]string `json:"inline_policies,omitempty"`
+
+	// Set by the backend, and missing from older versions of it
+	CreatedAt          *time.Time `json:"created_at,omitempty"`
+	UpdatedAt          *time.Time `json:"updated_at,omitempty"`
+	ProvisioningStatus string     `json:"provisioning_status,omitempty"`
 }
 
 func (c *Client) CreatePermissionSet(permSet *PermissionSet) (*PermissionSet, error) {
@@ -329,7 +608,7 @@ func (c *Client) CreatePermissionSet(permSet *PermissionSet) (*PermissionSet, er
 }
 
 func (c *Client) GetPermissionSet(permSetID string) (*PermissionSet, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/permission-sets/%s", permSetID), nil)
+	body, err := c.doRequest("GET", escapePath("/permission-sets/%s", permSetID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -343,7 +622,7 @@ func (c *Client) GetPermissionSet(permSetID string) (*PermissionSet, error) {
 }
 
 func (c *Client) UpdatePermissionSet(permSetID string, permSet *PermissionSet) (*PermissionSet, error) {
-	body, err := c.doRequest("PUT", fmt.Sprintf("/permission-sets/%s", permSetID), permSet)
+	body, err := c.doRequest("PUT", escapePath("/permission-sets/%s", permSetID), permSet)
 	if err != nil {
 		return nil, err
 	}
@@ -357,7 +636,7 @@ func (c *Client) UpdatePermissionSet(permSetID string, permSet *PermissionSet) (
 }
 
 func (c *Client) DeletePermissionSet(permSetID string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/permission-sets/%s", permSetID), nil)
+	_, err := c.doRequest("DELETE", escapePath("/permission-sets/%s", permSetID), nil)
 	return err
 }
 
@@ -375,6 +654,94 @@ func (c *Client) ListPermissionSets() ([]PermissionSet, error) {
 	return result, nil
 }
 
+// getPermissionSetUncached is GetPermissionSet without the read cache, for
+// reads that a write depends on.
+func (c *Client) getPermissionSetUncached(ctx context.Context, permSetID string) (*PermissionSet, error) {
+	body, err := c.doRequestWithOptions(ctx, "GET", escapePath("/permission-sets/%s", permSetID), nil, requestOptions{uncached: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var result PermissionSet
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// keyedLocks holds a mutex per key, created on first use.
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks the mutex of key and returns the function that unlocks it.
+func (k *keyedLocks) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*sync.Mutex{}
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// UpdatePermissionSetManagedPolicies replaces the managed policies of a
+// permission set with those modify returns for a copy of them; see
+// updatePermissionSetPolicies.
+func (c *Client) UpdatePermissionSetManagedPolicies(ctx context.Context, permSetID string, modify func(policies []string) []string) (*PermissionSet, error) {
+	return c.updatePermissionSetPolicies(ctx, permSetID, func(managed []string, inline map[string]string) ([]string, map[string]string) {
+		return modify(managed), inline
+	})
+}
+
+// UpdatePermissionSetInlinePolicies replaces the inline policies of a
+// permission set with those modify returns for a copy of them; see
+// updatePermissionSetPolicies.
+func (c *Client) UpdatePermissionSetInlinePolicies(ctx context.Context, permSetID string, modify func(policies map[string]string) map[string]string) (*PermissionSet, error) {
+	return c.updatePermissionSetPolicies(ctx, permSetID, func(managed []string, inline map[string]string) ([]string, map[string]string) {
+		return managed, modify(inline)
+	})
+}
+
+// updatePermissionSetPolicies reads a permission set, passes copies of its
+// managed and inline policies to modify, and writes the permission set back
+// with the policies modify returns, unless they are unchanged. The backend
+// has no endpoint for a single policy, so this is how one is attached or
+// detached. The read bypasses the read cache, and the read-modify-writes of
+// one permission set are serialized within the client, so that attachments
+// applied in parallel don't overwrite each other. Writes from elsewhere in
+// between are reported by the backend as a 409 Conflict.
+func (c *Client) updatePermissionSetPolicies(ctx context.Context, permSetID string, modify func(managed []string, inline map[string]string) ([]string, map[string]string)) (*PermissionSet, error) {
+	defer c.permissionSetWrites.lock(permSetID)()
+
+	current, err := c.getPermissionSetUncached(ctx, permSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	managed, inline := modify(slices.Clone(current.ManagedPolicies), maps.Clone(current.InlinePolicies))
+	if slices.Equal(managed, current.ManagedPolicies) && maps.Equal(inline, current.InlinePolicies) {
+		return current, nil
+	}
+
+	// Send back only what the resource sets, as prism_permission_set does
+	return c.UpdatePermissionSet(permSetID, &PermissionSet{
+		Name:            current.Name,
+		Description:     current.Description,
+		SessionDuration: current.SessionDuration,
+		ManagedPolicies: managed,
+		InlinePolicies:  inline,
+	})
+}
+
 // ========== Permission Set Assignment Operations ==========
 
 type PermissionSetAssignment struct {
@@ -391,6 +758,7 @@ type PermissionSetAssignment struct {
 
 func (c *Client) CreatePermissionSetAssignment(assignment *PermissionSetAssignment) (*PermissionSetAssignment, error) {
 	body, err := c.doRequest("POST", "/permission-set-assignments", assignment)
+	c.assignmentCache.invalidate()
 	if err != nil {
 		return nil, err
 	}
@@ -403,8 +771,15 @@ func (c *Client) CreatePermissionSetAssignment(assignment *PermissionSetAssignme
 	return &result, nil
 }
 
+// errEmptyAssignmentID is returned for an empty assignment ID, which would
+// otherwise address the assignment collection.
+var errEmptyAssignmentID = errors.New("permission set assignment ID must not be empty")
+
 func (c *Client) GetPermissionSetAssignment(assignmentID string) (*PermissionSetAssignment, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/permission-set-assignments/%s", assignmentID), nil)
+	if assignmentID == "" {
+		return nil, errEmptyAssignmentID
+	}
+	body, err := c.doRequest("GET", escapePath("/permission-set-assignments/%s", assignmentID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -418,7 +793,11 @@ func (c *Client) GetPermissionSetAssignment(assignmentID string) (*PermissionSet
 }
 
 func (c *Client) DeletePermissionSetAssignment(assignmentID string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/permission-set-assignments/%s", assignmentID), nil)
+	if assignmentID == "" {
+		return errEmptyAssignmentID
+	}
+	_, err := c.doRequest("DELETE", escapePath("/permission-set-assignments/%s", assignmentID), nil)
+	c.assignmentCache.invalidate()
 	return err
 }
 
@@ -440,17 +819,116 @@ func (c *Client) ListPermissionSetAssignments() ([]PermissionSetAssignment, erro
 	return result.Assignments, nil
 }
 
+// How long a cached assignment list is reused before being fetched again
+const assignmentListCacheTTL = 30 * time.Second
+
+// assignmentListCache holds the most recent list of all assignments so that
+// resources refreshed in the same Terraform operation share one list call.
+type assignmentListCache struct {
+	mu          sync.Mutex
+	assignments []PermissionSetAssignment
+	fetchedAt   time.Time
+}
+
+func (a *assignmentListCache) invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.assignments = nil
+	a.fetchedAt = time.Time{}
+}
+
+// ListPermissionSetAssignmentsFiltered returns the assignments for the given
+// permission set and principal. Empty arguments match any value.
+//
+// The backend has no filter parameters for this endpoint, so the full list is
+// fetched once and shared by all callers until it expires or this client
+// creates or deletes an assignment. Concurrent callers wait for a single fetch.
+func (c *Client) ListPermissionSetAssignmentsFiltered(permissionSetID, principalType, principalID string) ([]PermissionSetAssignment, error) {
+	c.assignmentCache.mu.Lock()
+	if c.assignmentCache.fetchedAt.IsZero() || time.Since(c.assignmentCache.fetchedAt) > assignmentListCacheTTL {
+		assignments, err := c.ListPermissionSetAssignments()
+		if err != nil {
+			c.assignmentCache.mu.Unlock()
+			return nil, err
+		}
+		c.assignmentCache.assignments = assignments
+		c.assignmentCache.fetchedAt = time.Now()
+	}
+	all := c.assignmentCache.assignments
+	c.assignmentCache.mu.Unlock()
+
+	var result []PermissionSetAssignment
+	for _, assignment := range all {
+		if permissionSetID != "" && assignment.PermissionSetID != permissionSetID {
+			continue
+		}
+		if principalType != "" && assignment.PrincipalType != principalType {
+			continue
+		}
+		if principalID != "" && assignment.PrincipalID != principalID &&
+			assignment.Username != principalID && assignment.GroupName != principalID {
+			continue
+		}
+		result = append(result, assignment)
+	}
+
+	return result, nil
+}
+
 // ========== User Operations ==========
 
 type User struct {
 	ID         string              `json:"id,omitempty"`
-	CustomerID string              `json:"customerId"`
+	CustomerID string              `json:"customerId,omitempty"`
 	Username   string              `json:"username"`
 	Email      string              `json:"email"`
 	FirstName  string              `json:"firstName,omitempty"`
 	LastName   string              `json:"lastName,omitempty"`
 	Enabled    bool                `json:"enabled"`
 	Attributes map[string][]string `json:"attributes,omitempty"`
+
+	// Read from the backend's epoch-millisecond createdTimestamp and
+	// lastLoginTimestamp, and never sent. Nil when the backend doesn't report
+	// them; LastLoginAt is also nil for a user who has never logged in.
+	CreatedAt   *time.Time `json:"-"`
+	LastLoginAt *time.Time `json:"-"`
+}
+
+// UnmarshalJSON reads the first and last names in either key style: GET
+// /users/{id} returns firstName and lastName, but GET /users returns
+// first_name and last_name. Users are still written in camelCase.
+func (u *User) UnmarshalJSON(data []byte) error {
+	type plain User
+	var user struct {
+		plain
+		SnakeFirstName     string `json:"first_name"`
+		SnakeLastName      string `json:"last_name"`
+		CreatedTimestamp   int64  `json:"createdTimestamp"`
+		LastLoginTimestamp int64  `json:"lastLoginTimestamp"`
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return err
+	}
+	*u = User(user.plain)
+	if u.FirstName == "" {
+		u.FirstName = user.SnakeFirstName
+	}
+	if u.LastName == "" {
+		u.LastName = user.SnakeLastName
+	}
+	u.CreatedAt = epochMillis(user.CreatedTimestamp)
+	u.LastLoginAt = epochMillis(user.LastLoginTimestamp)
+	return nil
+}
+
+// epochMillis returns the UTC time ms milliseconds after the Unix epoch, or
+// nil for zero, which the backend sends for a missing timestamp.
+func epochMillis(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := time.UnixMilli(ms).UTC()
+	return &t
 }
 
 func (c *Client) CreateUser(user *User) (*User, error) {
@@ -468,7 +946,7 @@ func (c *Client) CreateUser(user *User) (*User, error) {
 }
 
 func (c *Client) GetUser(userID string) (*User, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/users/%s", userID), nil)
+	body, err := c.doRequest("GET", escapePath("/users/%s", userID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +960,7 @@ func (c *Client) GetUser(userID string) (*User, error) {
 }
 
 func (c *Client) UpdateUser(userID string, user *User) (*User, error) {
-	body, err := c.doRequest("PUT", fmt.Sprintf("/users/%s", userID), user)
+	body, err := c.doRequest("PUT", escapePath("/users/%s", userID), user)
 	if err != nil {
 		return nil, err
 	}
@@ -496,7 +974,7 @@ func (c *Client) UpdateUser(userID string, user *User) (*User, error) {
 }
 
 func (c *Client) DeleteUser(userID string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/users/%s", userID), nil)
+	_, err := c.doRequest("DELETE", escapePath("/users/%s", userID), nil)
 	return err
 }
 
@@ -518,7 +996,7 @@ func (c *Client) ListUsers() ([]User, error) {
 
 type Group struct {
 	ID          string   `json:"id,omitempty"`
-	CustomerID  string   `json:"customerId"`
+	CustomerID  string   `json:"customerId,omitempty"`
 	Name        string   `json:"name"`
 	Description string   `json:"description,omitempty"`
 	Path        string   `json:"path,omitempty"`
@@ -540,7 +1018,7 @@ func (c *Client) CreateGroup(group *Group) (*Group, error) {
 }
 
 func (c *Client) GetGroup(groupName string) (*Group, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/groups/%s", groupName), nil)
+	body, err := c.doRequest("GET", escapePath("/groups/%s", groupName), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -554,7 +1032,7 @@ func (c *Client) GetGroup(groupName string) (*Group, error) {
 }
 
 func (c *Client) UpdateGroup(groupName string, group *Group) (*Group, error) {
-	body, err := c.doRequest("PUT", fmt.Sprintf("/groups/%s", groupName), group)
+	body, err := c.doRequest("PUT", escapePath("/groups/%s", groupName), group)
 	if err != nil {
 		return nil, err
 	}
@@ -568,7 +1046,29 @@ func (c *Client) UpdateGroup(groupName string, group *Group) (*Group, error) {
 }
 
 func (c *Client) DeleteGroup(groupName string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/groups/%s", groupName), nil)
+	_, err := c.doRequest("DELETE", escapePath("/groups/%s", groupName), nil)
+	return err
+}
+
+// UpdateGroupByID updates the group with ID groupID. Unlike UpdateGroup,
+// the group is found by its ID, so group may rename it.
+func (c *Client) UpdateGroupByID(groupID string, group *Group) (*Group, error) {
+	body, err := c.doRequest("PUT", escapePath("/groups/by-id/%s", groupID), group)
+	if err != nil {
+		return nil, err
+	}
+
+	var result Group
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteGroupByID deletes the group with ID groupID.
+func (c *Client) DeleteGroupByID(groupID string) error {
+	_, err := c.doRequest("DELETE", escapePath("/groups/by-id/%s", groupID), nil)
 	return err
 }
 
@@ -586,6 +1086,37 @@ func (c *Client) ListGroups() ([]Group, error) {
 	return result, nil
 }
 
+// groupRoleMappings is the body of the group role-mappings sub-resource.
+// Only realm roles are read; client roles are left out.
+type groupRoleMappings struct {
+	RealmMappings []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"realmMappings"`
+}
+
+// GetGroupRoleMappings returns the names of the realm roles mapped to the
+// group with the given ID, sorted.
+func (c *Client) GetGroupRoleMappings(groupID string) ([]string, error) {
+	body, err := c.doRequest("GET", escapePath("/groups/by-id/%s/role-mappings", groupID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result groupRoleMappings
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	roles := make([]string, 0, len(result.RealmMappings))
+	for _, role := range result.RealmMappings {
+		roles = append(roles, role.Name)
+	}
+	sort.Strings(roles)
+
+	return roles, nil
+}
+
 // ========== Group Membership Operations ==========
 
 type GroupMembership struct {
@@ -597,20 +1128,129 @@ func (c *Client) AddGroupMembers(groupName string, usernames []string) error {
 	membership := GroupMembership{
 		Usernames: usernames,
 	}
-	_, err := c.doRequest("POST", fmt.Sprintf("/groups/%s/members", groupName), membership)
+	_, err := c.doRequest("POST", escapePath("/groups/%s/members", groupName), membership)
 	return err
 }
 
+// memberRemovalStyle is the request form used to remove group members.
+type memberRemovalStyle int
+
+const (
+	// memberRemovalBody sends DELETE /groups/{g}/members with a JSON body.
+	memberRemovalBody memberRemovalStyle = iota
+	// memberRemovalAction sends POST /groups/{g}/members:remove with a JSON body.
+	memberRemovalAction
+	// memberRemovalPerUser sends DELETE /groups/{g}/members/{username} per user.
+	memberRemovalPerUser
+)
+
+// memberRemovalProbe remembers which member removal form works. Some proxies
+// strip or reject DELETE request bodies, so when the default form is rejected
+// the alternatives are probed once and the result is kept for the client.
+type memberRemovalProbe struct {
+	mu    sync.Mutex
+	style memberRemovalStyle
+}
+
 func (c *Client) RemoveGroupMembers(groupName string, usernames []string) error {
+	c.memberRemoval.mu.Lock()
+	style := c.memberRemoval.style
+	c.memberRemoval.mu.Unlock()
+
+	err := c.removeGroupMembers(style, groupName, usernames)
+	if style != memberRemovalBody || !isBodyRejectedError(err) {
+		return err
+	}
+	return c.probeMemberRemoval(groupName, usernames, err)
+}
+
+// probeMemberRemoval is called after a DELETE with a body was rejected. It
+// tries the members:remove action, then per-user DELETEs, and caches the
+// first form that works. Concurrent callers wait for a single probe.
+func (c *Client) probeMemberRemoval(groupName string, usernames []string, bodyErr error) error {
+	c.memberRemoval.mu.Lock()
+	defer c.memberRemoval.mu.Unlock()
+
+	if c.memberRemoval.style != memberRemovalBody {
+		// Another caller finished probing while we waited
+		return c.removeGroupMembers(c.memberRemoval.style, groupName, usernames)
+	}
+
+	err := c.removeGroupMembers(memberRemovalAction, groupName, usernames)
+	if err == nil {
+		c.memberRemoval.style = memberRemovalAction
+		return nil
+	}
+	if !isRouteMissingError(err) {
+		return err
+	}
+
+	// While probing, a 404 means the per-user route is missing rather than
+	// that the user is not a member
+	err = c.removeGroupMembersPerUser(groupName, usernames, false)
+	if err == nil {
+		c.memberRemoval.style = memberRemovalPerUser
+		return nil
+	}
+	if isRouteMissingError(err) {
+		return fmt.Errorf("no supported way to remove group members: %w", bodyErr)
+	}
+	return err
+}
+
+func (c *Client) removeGroupMembers(style memberRemovalStyle, groupName string, usernames []string) error {
 	membership := GroupMembership{
 		Usernames: usernames,
 	}
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/groups/%s/members", groupName), membership)
-	return err
+
+	switch style {
+	case memberRemovalAction:
+		_, err := c.doRequest("POST", escapePath("/groups/%s/members:remove", groupName), membership)
+		return err
+	case memberRemovalPerUser:
+		return c.removeGroupMembersPerUser(groupName, usernames, true)
+	default:
+		_, err := c.doRequest("DELETE", escapePath("/groups/%s/members", groupName), membership)
+		return err
+	}
+}
+
+// removeGroupMembersPerUser removes each user with its own DELETE. When
+// ignoreNotFound is set, a user that is not a member counts as removed.
+func (c *Client) removeGroupMembersPerUser(groupName string, usernames []string, ignoreNotFound bool) error {
+	for _, username := range usernames {
+		_, err := c.doRequest("DELETE", escapePath("/groups/%s/members/%s", groupName, username), nil)
+		if err != nil && !(ignoreNotFound && isNotFoundError(err)) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBodyRejectedError reports whether err is a response that a proxy or
+// backend gives when it rejects a DELETE request body.
+func isBodyRejectedError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType:
+		return true
+	}
+	return false
+}
+
+// isRouteMissingError reports whether err indicates that the backend does not
+// serve the requested route or method.
+func isRouteMissingError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed)
 }
 
 func (c *Client) GetGroupMembers(groupName string) ([]string, error) {
-	body, err := c.doRequest("GET", fmt.Sprintf("/groups/%s/members", groupName), nil)
+	body, err := c.doRequest("GET", escapePath("/groups/%s/members", groupName), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -636,6 +1276,56 @@ func (c *Client) GetGroupMembers(groupName string) ([]string, error) {
 	return usernames, nil
 }
 
+// GetUserGroups returns the names of the groups username is a member of.
+// Backends without GET /users/{username}/groups are served by listing the
+// members of every group instead.
+func (c *Client) GetUserGroups(username string) ([]string, error) {
+	body, err := c.doRequest("GET", escapePath("/users/%s/groups", username), nil)
+	if isRouteMissingError(err) {
+		return c.findUserGroups(username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Groups []Group `json:"groups"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	names := make([]string, len(result.Groups))
+	for i, group := range result.Groups {
+		names[i] = group.Name
+	}
+	return names, nil
+}
+
+// findUserGroups is GetUserGroups for backends without the user groups
+// route. It costs a request per group.
+func (c *Client) findUserGroups(username string) ([]string, error) {
+	groups, err := c.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, group := range groups {
+		members, err := c.GetGroupMembers(group.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member == username {
+				names = append(names, group.Name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
 // ========== Identity Provider Operations ==========
 
 type IdentityProvider struct {
@@ -648,6 +1338,37 @@ type IdentityProvider struct {
 	Config      map[string]interface{} `json:"config"`
 }
 
+// identityProviderPayload is an identity provider as the backend returns it.
+type identityProviderPayload struct {
+	Type        string            `json:"type"`
+	Alias       string            `json:"alias"`
+	DisplayName string            `json:"displayName"`
+	ProviderId  string            `json:"providerId"`
+	Enabled     bool              `json:"enabled"`
+	TrustEmail  bool              `json:"trustEmail"`
+	StoreToken  bool              `json:"storeToken"`
+	Config      map[string]string `json:"config"`
+}
+
+// toIdentityProvider converts the payload of the identity provider of type
+// idpType.
+func (p identityProviderPayload) toIdentityProvider(idpType string) *IdentityProvider {
+	result := &IdentityProvider{
+		Type:        idpType,
+		Alias:       p.Alias,
+		DisplayName: p.DisplayName,
+		Enabled:     p.Enabled,
+		Config:      make(map[string]interface{}),
+	}
+
+	// Convert config map from string to interface{}
+	for k, v := range p.Config {
+		result.Config[k] = v
+	}
+
+	return result
+}
+
 func (c *Client) CreateIdentityProvider(idpType string, idp *IdentityProvider) (*IdentityProvider, error) {
 	// Build request body based on IdP type - backend expects fields at top level, not nested in config
 	requestBody := make(map[string]interface{})
@@ -738,84 +1459,40 @@ func (c *Client) CreateIdentityProvider(idpType string, idp *IdentityProvider) (
 		}
 	}
 
-	body, err := c.doRequest("POST", fmt.Sprintf("/identity-providers/%s", idpType), requestBody)
+	body, err := c.doRequest("POST", escapePath("/identity-providers/%s", idpType), requestBody)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse response - backend returns nested in "identityProvider" field
 	var response struct {
-		IdentityProvider struct {
-			Alias       string            `json:"alias"`
-			DisplayName string            `json:"displayName"`
-			ProviderId  string            `json:"providerId"`
-			Enabled     bool              `json:"enabled"`
-			TrustEmail  bool              `json:"trustEmail"`
-			StoreToken  bool              `json:"storeToken"`
-			Config      map[string]string `json:"config"`
-		} `json:"identityProvider"`
+		IdentityProvider identityProviderPayload `json:"identityProvider"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Convert response to IdentityProvider
-	result := &IdentityProvider{
-		Type:        idpType,
-		Alias:       response.IdentityProvider.Alias,
-		DisplayName: response.IdentityProvider.DisplayName,
-		Enabled:     response.IdentityProvider.Enabled,
-		Config:      make(map[string]interface{}),
-	}
-
-	// Convert config map from string to interface{}
-	for k, v := range response.IdentityProvider.Config {
-		result.Config[k] = v
-	}
-
-	return result, nil
+	return response.IdentityProvider.toIdentityProvider(idpType), nil
 }
 
 func (c *Client) GetIdentityProvider(idpType, alias string) (*IdentityProvider, error) {
 	// Backend endpoint is just /identity-providers/{type}, not with alias
-	body, err := c.doRequest("GET", fmt.Sprintf("/identity-providers/%s", idpType), nil)
+	body, err := c.doRequest("GET", escapePath("/identity-providers/%s", idpType), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse response - backend returns nested in "identityProvider" field
 	var response struct {
-		IdentityProvider struct {
-			Alias       string            `json:"alias"`
-			DisplayName string            `json:"displayName"`
-			ProviderId  string            `json:"providerId"`
-			Enabled     bool              `json:"enabled"`
-			TrustEmail  bool              `json:"trustEmail"`
-			StoreToken  bool              `json:"storeToken"`
-			Config      map[string]string `json:"config"`
-		} `json:"identityProvider"`
+		IdentityProvider identityProviderPayload `json:"identityProvider"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Convert response to IdentityProvider
-	result := &IdentityProvider{
-		Type:        idpType,
-		Alias:       response.IdentityProvider.Alias,
-		DisplayName: response.IdentityProvider.DisplayName,
-		Enabled:     response.IdentityProvider.Enabled,
-		Config:      make(map[string]interface{}),
-	}
-
-	// Convert config map from string to interface{}
-	for k, v := range response.IdentityProvider.Config {
-		result.Config[k] = v
-	}
-
-	return result, nil
+	return response.IdentityProvider.toIdentityProvider(idpType), nil
 }
 
 func (c *Client) UpdateIdentityProvider(idpType, alias string, idp *IdentityProvider) (*IdentityProvider, error) {
@@ -904,48 +1581,26 @@ func (c *Client) UpdateIdentityProvider(idpType, alias string, idp *IdentityProv
 	}
 
 	// Backend endpoint is just /identity-providers/{type}, not with alias
-	body, err := c.doRequest("PUT", fmt.Sprintf("/identity-providers/%s", idpType), requestBody)
+	body, err := c.doRequest("PUT", escapePath("/identity-providers/%s", idpType), requestBody)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse response - backend returns nested in "identityProvider" field
 	var response struct {
-		IdentityProvider struct {
-			Alias       string            `json:"alias"`
-			DisplayName string            `json:"displayName"`
-			ProviderId  string            `json:"providerId"`
-			Enabled     bool              `json:"enabled"`
-			TrustEmail  bool              `json:"trustEmail"`
-			StoreToken  bool              `json:"storeToken"`
-			Config      map[string]string `json:"config"`
-		} `json:"identityProvider"`
+		IdentityProvider identityProviderPayload `json:"identityProvider"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Convert response to IdentityProvider
-	result := &IdentityProvider{
-		Type:        idpType,
-		Alias:       response.IdentityProvider.Alias,
-		DisplayName: response.IdentityProvider.DisplayName,
-		Enabled:     response.IdentityProvider.Enabled,
-		Config:      make(map[string]interface{}),
-	}
-
-	// Convert config map from string to interface{}
-	for k, v := range response.IdentityProvider.Config {
-		result.Config[k] = v
-	}
-
-	return result, nil
+	return response.IdentityProvider.toIdentityProvider(idpType), nil
 }
 
 func (c *Client) DeleteIdentityProvider(idpType, alias string) error {
 	// Backend endpoint is just /identity-providers/{type}, not with alias
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/identity-providers/%s", idpType), nil)
+	_, err := c.doRequest("DELETE", escapePath("/identity-providers/%s", idpType), nil)
 	return err
 }
 
@@ -955,11 +1610,31 @@ func (c *Client) ListIdentityProviders() ([]IdentityProvider, error) {
 		return nil, err
 	}
 
-	var result []IdentityProvider
-	if err := json.Unmarshal(body, &result); err != nil {
+	// Backend returns { "identityProviders": [...], "count": N }; older
+	// versions returned the bare list
+	var response struct {
+		IdentityProviders []identityProviderPayload `json:"identityProviders"`
+		Count             int                       `json:"count"`
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &response.IdentityProviders)
+	} else {
+		err = json.Unmarshal(body, &response)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	result := make([]IdentityProvider, 0, len(response.IdentityProviders))
+	for _, payload := range response.IdentityProviders {
+		// Each type has one identity provider, whose alias is its type
+		idpType := payload.Type
+		if idpType == "" {
+			idpType = payload.Alias
+		}
+		result = append(result, *payload.toIdentityProvider(idpType))
+	}
+
 	return result, nil
 }
 
@@ -970,45 +1645,90 @@ func isDependencyNotFoundError(err error) bool {
 	if err == nil {
 		return false
 	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		// Don't match against the path in the message
+		return apiErr.StatusCode == http.StatusNotFound || strings.Contains(strings.ToLower(apiErr.Body), "not found")
+	}
 	msg := err.Error()
 	return strings.Contains(msg, "404") || strings.Contains(strings.ToLower(msg), "not found")
 }
 
-// waitForDependency polls checkFunc every 2s for up to 60s until the dependency exists.
+// defaultDependencyWait bounds waitForDependency and waitForDeletion when ctx
+// has no deadline.
+const defaultDependencyWait = 60 * time.Second
+
+// errDependencyTimeout is wrapped by the errors of waitForDependency and
+// waitForDeletion when the resource didn't reach the wanted state in time.
+var errDependencyTimeout = errors.New("timed out")
+
+// waitForDependency polls checkFunc every 2s until the dependency exists, for up to
+// ctx's deadline (typically the resource's create timeout) or 60s if ctx has none.
 // Returns nil immediately if the dependency is found on the first check.
 // Returns immediately on non-404 errors. Times out with a descriptive error.
 func waitForDependency(ctx context.Context, resourceType, resourceID string, checkFunc func() error) error {
-	const maxWait = 60 * time.Second
+	return pollDependency(ctx, resourceType, resourceID, checkFunc, true)
+}
+
+// waitForDeletion is waitForDependency inverted: it polls checkFunc until
+// the resource is gone, i.e. until checkFunc returns a not-found error, for
+// resources the backend deletes asynchronously.
+func waitForDeletion(ctx context.Context, resourceType, resourceID string, checkFunc func() error) error {
+	return pollDependency(ctx, resourceType, resourceID, checkFunc, false)
+}
+
+// pollDependency polls checkFunc until the resource exists, or until it's
+// gone when wantExists is false.
+func pollDependency(ctx context.Context, resourceType, resourceID string, checkFunc func() error, wantExists bool) error {
 	const pollInterval = 2 * time.Second
 
-	err := checkFunc()
-	if err == nil {
-		return nil
+	label, pending, done, goal := "DEPENDENCY WAIT", "not found", "is now available", "become available"
+	if !wantExists {
+		label, pending, done, goal = "DELETION WAIT", "still exists", "is now deleted", "be deleted"
+	}
+
+	// check reports whether the resource has reached the wanted state
+	check := func() (bool, error) {
+		err := checkFunc()
+		if err != nil && !isDependencyNotFoundError(err) {
+			return false, fmt.Errorf("error checking %s %q: %w", resourceType, resourceID, err)
+		}
+		return (err == nil) == wantExists, nil
 	}
-	if !isDependencyNotFoundError(err) {
-		return fmt.Errorf("error checking %s %q: %w", resourceType, resourceID, err)
+
+	maxWait := defaultDependencyWait
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = time.Until(deadline).Round(time.Second)
 	}
 
-	fmt.Fprintf(os.Stderr, "[DEPENDENCY WAIT] %s %q not found, polling for up to %s...\n", resourceType, resourceID, maxWait)
+	reached, err := check()
+	if reached || err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "[%s] %s %q %s, polling for up to %s...\n", label, resourceType, resourceID, pending, maxWait)
 
 	deadline := time.Now().Add(maxWait)
 	for time.Now().Before(deadline) {
 		select {
 		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w after %s waiting for %s %q to %s", errDependencyTimeout, maxWait, resourceType, resourceID, goal)
+			}
 			return fmt.Errorf("context cancelled while waiting for %s %q", resourceType, resourceID)
 		case <-time.After(pollInterval):
 		}
 
-		err = checkFunc()
-		if err == nil {
-			fmt.Fprintf(os.Stderr, "[DEPENDENCY WAIT] %s %q is now available\n", resourceType, resourceID)
+		reached, err = check()
+		if reached {
+			fmt.Fprintf(os.Stderr, "[%s] %s %q %s\n", label, resourceType, resourceID, done)
 			return nil
 		}
-		if !isDependencyNotFoundError(err) {
-			return fmt.Errorf("error checking %s %q: %w", resourceType, resourceID, err)
+		if err != nil {
+			return err
 		}
-		fmt.Fprintf(os.Stderr, "[DEPENDENCY WAIT] %s %q still not found, retrying...\n", resourceType, resourceID)
+		fmt.Fprintf(os.Stderr, "[%s] %s %q %s, retrying...\n", label, resourceType, resourceID, pending)
 	}
 
-	return fmt.Errorf("timed out after %s waiting for %s %q to become available", maxWait, resourceType, resourceID)
+	return fmt.Errorf("%w after %s waiting for %s %q to %s", errDependencyTimeout, maxWait, resourceType, resourceID, goal)
 }
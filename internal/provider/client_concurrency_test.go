@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestClient_ConcurrentUse drives one Client from many goroutines mixing
+// reads and writes, as Terraform does under -parallelism. Run with -race to
+// catch unsynchronized access to client state.
+func TestClient_ConcurrentUse(t *testing.T) {
+	const (
+		workers    = 20
+		iterations = 5
+	)
+
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111", AccountName: "Shared"}
+	fake.groups["shared"] = &Group{ID: "group-1", Name: "shared"}
+	client := newTestClient(t, fake)
+	// Exercise scheme normalization, which must not write to the shared client
+	client.BaseURL = strings.TrimPrefix(client.BaseURL, "https://")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if err := concurrentClientStep(client, w, i); err != nil {
+					errs <- fmt.Errorf("worker %d iteration %d: %w", w, i, err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if len(fake.users) != workers*iterations {
+		t.Errorf("expected %d users, got %d", workers*iterations, len(fake.users))
+	}
+	if len(fake.assignments) != 0 {
+		t.Errorf("expected every assignment to be deleted, got %d", len(fake.assignments))
+	}
+}
+
+// concurrentClientStep performs one round of mixed client calls for a worker.
+func concurrentClientStep(client *Client, worker, iteration int) error {
+	username := fmt.Sprintf("user-%d-%d", worker, iteration)
+
+	if _, err := client.CreateUser(&User{Username: username, Email: username + "@example.com", Enabled: true}); err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+	if _, err := client.UpdateUser(username, &User{Username: username, Email: username + "@example.org", Enabled: true}); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if _, err := client.GetUser(username); err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if _, err := client.ListUsers(); err != nil {
+		return fmt.Errorf("list users: %w", err)
+	}
+
+	if err := client.AddGroupMembers("shared", []string{username}); err != nil {
+		return fmt.Errorf("add group member: %w", err)
+	}
+	if _, err := client.GetGroupMembers("shared"); err != nil {
+		return fmt.Errorf("get group members: %w", err)
+	}
+
+	created, err := client.CreatePermissionSetAssignment(&PermissionSetAssignment{
+		PermissionSetID: "ps-1",
+		PrincipalType:   "USER",
+		PrincipalID:     username,
+		AccountIDs:      []string{"111111111111"},
+	})
+	if err != nil {
+		return fmt.Errorf("create assignment: %w", err)
+	}
+	if _, err := client.ListPermissionSetAssignmentsFiltered("ps-1", "USER", username); err != nil {
+		return fmt.Errorf("list assignments: %w", err)
+	}
+	if err := client.DeletePermissionSetAssignment(created.ID); err != nil {
+		return fmt.Errorf("delete assignment: %w", err)
+	}
+
+	if _, err := client.SetAWSAccountOwners("111111111111", []string{username + "@example.com"}); err != nil {
+		return fmt.Errorf("set account owners: %w", err)
+	}
+	if _, err := client.GetAWSAccountOwners("111111111111"); err != nil {
+		return fmt.Errorf("get account owners: %w", err)
+	}
+
+	return nil
+}
+
+func TestClient_FirstRequestCompletesBeforeOthers(t *testing.T) {
+	var inFlight, maxInFlight, calls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			if n != 1 {
+				t.Errorf("expected the first request to run alone, got %d in flight", n)
+			}
+			// Hold the first request so that others would overlap it
+			time.Sleep(50 * time.Millisecond)
+		} else {
+			for {
+				prev := atomic.LoadInt32(&maxInFlight)
+				if n <= prev || atomic.CompareAndSwapInt32(&maxInFlight, prev, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		writeAPIData(w, []User{})
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ListUsers(); err != nil {
+				t.Errorf("list users: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&maxInFlight); got < 2 {
+		t.Errorf("expected requests after the first to run in parallel, got at most %d in flight", got)
+	}
+}
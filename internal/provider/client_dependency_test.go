@@ -191,6 +191,51 @@ func TestWaitForDependency_FullTimeout(t *testing.T) {
 	}
 }
 
+// ========== waitForDeletion tests ==========
+
+func TestWaitForDeletion_GoneAfterPolling(t *testing.T) {
+	var calls int64
+
+	err := waitForDeletion(context.Background(), "test_resource", "test-id", func() error {
+		if atomic.AddInt64(&calls, 1) < 2 {
+			return nil
+		}
+		return fmt.Errorf("API error (404): not found")
+	})
+
+	if err != nil {
+		t.Fatalf("expected nil error once deleted, got: %v", err)
+	}
+	if finalCalls := atomic.LoadInt64(&calls); finalCalls != 2 {
+		t.Errorf("expected 2 calls, got %d", finalCalls)
+	}
+}
+
+func TestWaitForDeletion_NonRetryableError(t *testing.T) {
+	err := waitForDeletion(context.Background(), "test_resource", "test-id", func() error {
+		return fmt.Errorf("API error (500): internal server error")
+	})
+
+	expected := `error checking test_resource "test-id"`
+	if err == nil || !containsSubstring(err.Error(), expected) {
+		t.Errorf("expected error to contain %q, got: %v", expected, err)
+	}
+}
+
+func TestWaitForDeletion_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := waitForDeletion(ctx, "test_resource", "test-id", func() error {
+		return nil
+	})
+
+	expected := `waiting for test_resource "test-id" to be deleted`
+	if err == nil || !containsSubstring(err.Error(), expected) {
+		t.Errorf("expected error to contain %q, got: %v", expected, err)
+	}
+}
+
 // helper
 func containsSubstring(s, substr string) bool {
 	return len(s) >= len(substr) && searchSubstring(s, substr)
@@ -0,0 +1,904 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// recordRequestURIs wraps handler, recording the raw request URI of each
+// request as sent on the wire.
+func recordRequestURIs(handler http.Handler) (http.Handler, func() []string) {
+	var (
+		mu   sync.Mutex
+		uris []string
+	)
+	wrapped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		uris = append(uris, r.Method+" "+r.RequestURI)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+	return wrapped, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), uris...)
+	}
+}
+
+func TestClient_EscapesGroupNameWithSlash(t *testing.T) {
+	fake := newFakePrism()
+	fake.users["alice"] = &User{ID: "user-1", Username: "alice"}
+	handler, requests := recordRequestURIs(fake)
+	client := newTestClient(t, handler)
+
+	if _, err := client.CreateGroup(&Group{Name: "platform/eng"}); err != nil {
+		t.Fatalf("create group: %s", err)
+	}
+	group, err := client.GetGroup("platform/eng")
+	if err != nil {
+		t.Fatalf("get group: %s", err)
+	}
+	if group.Name != "platform/eng" {
+		t.Errorf("expected group platform/eng, got %q", group.Name)
+	}
+	if err := client.AddGroupMembers("platform/eng", []string{"alice"}); err != nil {
+		t.Fatalf("add group members: %s", err)
+	}
+	members, err := client.GetGroupMembers("platform/eng")
+	if err != nil {
+		t.Fatalf("get group members: %s", err)
+	}
+	if len(members) != 1 || members[0] != "alice" {
+		t.Errorf("expected member alice, got %v", members)
+	}
+	if err := client.DeleteGroup("platform/eng"); err != nil {
+		t.Fatalf("delete group: %s", err)
+	}
+
+	want := []string{
+		"POST /api/v1/customers/test/groups",
+		"GET /api/v1/customers/test/groups/platform%2Feng",
+		"POST /api/v1/customers/test/groups/platform%2Feng/members",
+		"GET /api/v1/customers/test/groups/platform%2Feng/members",
+		"DELETE /api/v1/customers/test/groups/platform%2Feng",
+	}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestClient_ErrorsIncludeMethodAndPath(t *testing.T) {
+	client := newTestClient(t, newFakePrism())
+
+	_, err := client.GetGroup("platform/eng")
+	if err == nil {
+		t.Fatal("expected an error for a missing group")
+	}
+	want := "GET /api/v1/customers/test/groups/platform%2Feng: API error (404): "
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("expected error to start with %q, got %q", want, err.Error())
+	}
+	if !isNotFoundError(err) {
+		t.Error("expected isNotFoundError to be true")
+	}
+}
+
+func TestClient_TimingLog(t *testing.T) {
+	client := newTestClient(t, newFakePrism())
+	var log strings.Builder
+	client.TimingLog = &log
+
+	if _, err := client.ListUsers(); err != nil {
+		t.Fatalf("list users: %s", err)
+	}
+	_, _ = client.GetGroup("missing")
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a timing line per call, got:\n%s", log.String())
+	}
+	for i, want := range []string{"| GET " + client.BaseURL + "/api/v1/customers/test/users | Response: ", "/groups/missing | Response: "} {
+		if !strings.HasPrefix(lines[i], "[API TIMING] #") || !strings.Contains(lines[i], want) {
+			t.Errorf("expected line %d to be a timing line containing %q, got %q", i, want, lines[i])
+		}
+	}
+}
+
+func TestClient_ErrorsIncludeMethodAndPathForUnwrapFailures(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusOK, "quota exceeded")
+	}))
+
+	err := client.DeleteUser("alice")
+	want := "DELETE /api/v1/customers/test/users/alice: API request failed: quota exceeded"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+}
+
+func TestClient_APIErrorRetryAfter(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		writeAPIError(w, http.StatusTooManyRequests, "slow down")
+	}))
+
+	_, err := client.ListUsers()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("expected a 429 with Retry-After 7s, got %d with %s", apiErr.StatusCode, apiErr.RetryAfter)
+	}
+}
+
+func TestClient_APIErrorRedactsSecrets(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"success":false,"error":"invalid config for token test-token",` +
+			`"config":{"clientId":"app-1","clientSecret":"s3cr3t","storeToken":false,"password": "hunter2"}}`))
+	}))
+
+	_, err := client.ListUsers()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, secret := range []string{"test-token", "s3cr3t", "hunter2"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("expected %q to be redacted, got %s", secret, err)
+		}
+	}
+	for _, kept := range []string{`"clientId":"app-1"`, `"storeToken":false`, `"clientSecret":"[REDACTED]"`} {
+		if !strings.Contains(err.Error(), kept) {
+			t.Errorf("expected %s in the error, got %s", kept, err)
+		}
+	}
+}
+
+func TestClient_RequestOptions(t *testing.T) {
+	handler, requests := recordRequestURIs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Token") != "test-token" {
+			writeAPIError(w, http.StatusUnauthorized, "missing token")
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.Header().Set("Retry-After", "3")
+			writeAPIError(w, http.StatusNotFound, "no such thing for test-token")
+			return
+		}
+		writeAPIData(w, map[string]string{"name": "acme"})
+	}))
+	client := newTestClient(t, handler)
+	var log strings.Builder
+	client.TimingLog = &log
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		path string
+		opts requestOptions
+		want string
+	}{
+		{name: "scoped", path: "/settings", want: `{"name":"acme"}`},
+		{name: "unscoped", path: "/api/v1/admin/customers/acme", opts: requestOptions{unscoped: true}, want: `{"name":"acme"}`},
+		{name: "raw", path: "/settings", opts: requestOptions{raw: true}, want: `{"data":{"name":"acme"},"success":true}`},
+		{name: "unscoped raw", path: "/api/v1/admin/customers/acme", opts: requestOptions{unscoped: true, raw: true}, want: `{"data":{"name":"acme"},"success":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := client.doRequestWithOptions(ctx, "GET", tt.path, nil, tt.opts)
+			if err != nil {
+				t.Fatalf("request: %s", err)
+			}
+			if got := strings.TrimSpace(string(body)); got != tt.want {
+				t.Errorf("expected body %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	want := []string{
+		"GET /api/v1/customers/test/settings",
+		"GET /api/v1/admin/customers/acme",
+		"GET /api/v1/customers/test/settings",
+		"GET /api/v1/admin/customers/acme",
+	}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if lines := strings.Count(log.String(), "[API TIMING]"); lines != len(want) {
+		t.Errorf("expected %d timing lines, got:\n%s", len(want), log.String())
+	}
+
+	// Unscoped calls get the same typed, redacted errors as scoped ones
+	_, err := client.doRequestWithOptions(ctx, "GET", "/api/v1/admin/customers/missing", nil, requestOptions{unscoped: true, raw: true})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiErr.Path != "/api/v1/admin/customers/missing" || apiErr.RetryAfter != 3*time.Second || !isNotFoundError(err) {
+		t.Errorf("expected a 404 for the unscoped path with Retry-After 3s, got %+v", apiErr)
+	}
+	if strings.Contains(err.Error(), "test-token") {
+		t.Errorf("expected the token to be redacted, got %s", err)
+	}
+}
+
+func TestClient_CheckAccess(t *testing.T) {
+	handler, requests := recordRequestURIs(newFakePrism())
+	client := newTestClient(t, handler)
+
+	if err := client.CheckAccess(); err != nil {
+		t.Fatalf("check access: %s", err)
+	}
+	want := []string{"GET /api/v1/customers/test/aws-accounts?max=1"}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests %v, got %v", want, got)
+	}
+
+	client = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusUnauthorized, "invalid token")
+	}))
+	var apiErr *APIError
+	if err := client.CheckAccess(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 APIError, got %v", err)
+	}
+}
+
+func TestClient_GetAWSAccountNameShapes(t *testing.T) {
+	tests := map[string]string{
+		"name":        `{"id": "acct-42", "account_id": "123456789012", "name": "Production"}`,
+		"accountName": `{"id": "acct-42", "account_id": "123456789012", "accountName": "Production"}`,
+		"both":        `{"id": "acct-42", "account_id": "123456789012", "name": "Production", "accountName": "Old"}`,
+	}
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAPIData(w, json.RawMessage(payload))
+			}))
+			account, err := client.GetAWSAccount("123456789012")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if account.AccountName != "Production" || account.ID != "acct-42" || account.AccountID != "123456789012" {
+				t.Errorf("expected account acct-42 named Production, got %+v", account)
+			}
+		})
+	}
+
+	// Updates still send the name as "name"
+	body, err := json.Marshal(AWSAccount{AccountID: "123456789012", AccountName: "Production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"name":"Production"`) || strings.Contains(string(body), "accountName") {
+		t.Errorf("expected the name to be sent as name, got %s", body)
+	}
+}
+
+func TestClient_AWSAccountOwnerEmails(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, fake)
+	if _, err := client.CreateAWSAccount(context.Background(), &AWSAccount{AccountID: "123456789012", AccountName: "Production", OwnerEmails: []string{"alice@example.com", "bob@example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := client.GetAWSAccount("123456789012")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := client.ListAWSAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 {
+		t.Fatalf("expected 1 account, got %d", len(accounts))
+	}
+	for name, got := range map[string]AWSAccount{"get": *account, "list": accounts[0]} {
+		if strings.Join(got.OwnerEmails, ",") != "alice@example.com,bob@example.com" {
+			t.Errorf("%s: expected both owners, got %v", name, got.OwnerEmails)
+		}
+	}
+
+	// Newer backends send the owners in camelCase
+	client = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/customers/test/aws-accounts" {
+			writeAPIData(w, json.RawMessage(`[{"account_id": "123456789012", "accountName": "Production", "ownerEmails": ["alice@example.com"]}]`))
+			return
+		}
+		writeAPIData(w, json.RawMessage(`{"account_id": "123456789012", "accountName": "Production", "ownerEmails": ["alice@example.com"]}`))
+	}))
+	account, err = client.GetAWSAccount("123456789012")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err = client.ListAWSAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]AWSAccount{"get": *account, "list": accounts[0]} {
+		if strings.Join(got.OwnerEmails, ",") != "alice@example.com" {
+			t.Errorf("%s: expected alice as owner, got %v", name, got.OwnerEmails)
+		}
+	}
+}
+
+func TestClient_GetGroupRoleMappings(t *testing.T) {
+	tests := map[string]struct {
+		payload string
+		want    string
+	}{
+		"realm roles": {
+			payload: `{"realmMappings": [{"id": "r-2", "name": "prism-viewer"}, {"id": "r-1", "name": "prism-admin"}]}`,
+			want:    "prism-admin,prism-viewer",
+		},
+		"client roles only": {
+			payload: `{"clientMappings": {"prism-ui": {"mappings": [{"id": "c-1", "name": "view"}]}}}`,
+			want:    "",
+		},
+		"no mappings": {payload: `{}`, want: ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler, requests := recordRequestURIs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAPIData(w, json.RawMessage(tt.payload))
+			}))
+			client := newTestClient(t, handler)
+
+			roles, err := client.GetGroupRoleMappings("group/1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if roles == nil || strings.Join(roles, ",") != tt.want {
+				t.Errorf("expected roles %q, got %#v", tt.want, roles)
+			}
+			if got := requests(); len(got) != 1 || got[0] != "GET /api/v1/customers/test/groups/by-id/group%2F1/role-mappings" {
+				t.Errorf("expected one escaped role-mappings request, got %v", got)
+			}
+		})
+	}
+}
+
+func TestClient_UserNameShapes(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/customers/test/users":
+			writeAPIData(w, json.RawMessage(`[{"id": "u-1", "username": "alice", "email": "alice@example.com", "first_name": "Alice", "last_name": "Smith", "enabled": true}]`))
+		case "/api/v1/customers/test/users/alice":
+			writeAPIData(w, json.RawMessage(`{"id": "u-1", "username": "alice", "email": "alice@example.com", "firstName": "Alice", "lastName": "Smith", "enabled": true}`))
+		default:
+			writeAPIError(w, http.StatusNotFound, "not found")
+		}
+	}))
+
+	users, err := client.ListUsers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := client.GetUser("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]User{"list": users[0], "get": *user} {
+		if got.FirstName != "Alice" || got.LastName != "Smith" || got.Username != "alice" || !got.Enabled {
+			t.Errorf("%s: expected alice named Alice Smith, got %+v", name, got)
+		}
+	}
+
+	// Users are still sent in camelCase
+	body, err := json.Marshal(User{Username: "alice", FirstName: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"firstName":"Alice"`) || strings.Contains(string(body), "first_name") {
+		t.Errorf("expected the first name to be sent as firstName, got %s", body)
+	}
+}
+
+func TestClient_ListIdentityProvidersShapes(t *testing.T) {
+	google := `{"alias": "google", "displayName": "Google", "providerId": "google", "enabled": true, "config": {"clientId": "client-1", "hostedDomain": "example.com"}}`
+	tests := map[string]string{
+		"wrapped": `{"identityProviders": [` + google + `], "count": 1}`,
+		"bare":    `[` + google + `]`,
+	}
+	for name, payload := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAPIData(w, json.RawMessage(payload))
+			}))
+			idps, err := client.ListIdentityProviders()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []IdentityProvider{{
+				Type:        "google",
+				Alias:       "google",
+				DisplayName: "Google",
+				Enabled:     true,
+				Config:      map[string]interface{}{"clientId": "client-1", "hostedDomain": "example.com"},
+			}}
+			if !reflect.DeepEqual(idps, want) {
+				t.Errorf("expected %+v, got %+v", want, idps)
+			}
+		})
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, json.RawMessage(`{"identityProviders": [], "count": 0}`))
+	}))
+	if idps, err := client.ListIdentityProviders(); err != nil || len(idps) != 0 {
+		t.Errorf("expected no identity providers, got %v, %v", idps, err)
+	}
+}
+
+func TestClient_UserAndGroupPayloadsOmitCustomerID(t *testing.T) {
+	fake := newFakePrism()
+	var mu sync.Mutex
+	var bodies []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("reading request body: %s", err)
+			}
+			mu.Lock()
+			bodies = append(bodies, r.Method+" "+r.URL.Path+" "+string(body))
+			mu.Unlock()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		fake.ServeHTTP(w, r)
+	}))
+
+	user := &User{Username: "alice", Email: "alice@example.com", Enabled: true}
+	if _, err := client.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateUser("alice", user); err != nil {
+		t.Fatal(err)
+	}
+	group := &Group{Name: "Admins"}
+	if _, err := client.CreateGroup(group); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateGroup("Admins", group); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 4 {
+		t.Fatalf("expected 4 requests with a body, got %d: %v", len(bodies), bodies)
+	}
+	for _, body := range bodies {
+		if strings.Contains(body, "customerId") {
+			t.Errorf("expected no customerId, the path names the customer: %s", body)
+		}
+	}
+}
+
+func TestClient_GroupRoutes(t *testing.T) {
+	fake := newFakePrism()
+	handler, requests := recordRequestURIs(fake)
+	client := newTestClient(t, handler)
+
+	created, err := client.CreateGroup(&Group{Name: "platform/eng"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateGroup("platform/eng", &Group{Name: "platform/eng", Description: "by name"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := client.UpdateGroupByID(created.ID, &Group{Name: "platform/sre", Description: "by ID"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != created.ID || updated.Name != "platform/sre" {
+		t.Errorf("expected %s renamed to platform/sre, got %+v", created.ID, updated)
+	}
+	if err := client.DeleteGroupByID(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteGroup("platform/sre"); !isNotFoundError(err) {
+		t.Errorf("expected the group to be gone, got %v", err)
+	}
+
+	want := []string{
+		"POST /api/v1/customers/test/groups",
+		"PUT /api/v1/customers/test/groups/platform%2Feng",
+		"PUT /api/v1/customers/test/groups/by-id/" + created.ID,
+		"DELETE /api/v1/customers/test/groups/by-id/" + created.ID,
+		"DELETE /api/v1/customers/test/groups/platform%2Fsre",
+	}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestClient_AssignmentIDsAreEscaped(t *testing.T) {
+	fake := newFakePrism()
+	handler, requests := recordRequestURIs(fake)
+	client := newTestClient(t, handler)
+
+	for _, id := range []string{"ps-1:123456789012:alice", "ps-1/123456789012"} {
+		fake.assignments[id] = &PermissionSetAssignment{ID: id, PermissionSetID: "ps-1", AccountID: "123456789012"}
+		if assignment, err := client.GetPermissionSetAssignment(id); err != nil || assignment.ID != id {
+			t.Errorf("get %s: got %+v, %v", id, assignment, err)
+		}
+		if err := client.DeletePermissionSetAssignment(id); err != nil {
+			t.Errorf("delete %s: %v", id, err)
+		}
+		if _, ok := fake.assignments[id]; ok {
+			t.Errorf("expected %s to be deleted", id)
+		}
+	}
+
+	want := []string{
+		"GET /api/v1/customers/test/permission-set-assignments/ps-1%3A123456789012%3Aalice",
+		"DELETE /api/v1/customers/test/permission-set-assignments/ps-1%3A123456789012%3Aalice",
+		"GET /api/v1/customers/test/permission-set-assignments/ps-1%2F123456789012",
+		"DELETE /api/v1/customers/test/permission-set-assignments/ps-1%2F123456789012",
+	}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestClient_EmptyAssignmentIDSendsNoRequest(t *testing.T) {
+	handler, requests := recordRequestURIs(newFakePrism())
+	client := newTestClient(t, handler)
+
+	if _, err := client.GetPermissionSetAssignment(""); !errors.Is(err, errEmptyAssignmentID) {
+		t.Errorf("expected errEmptyAssignmentID from get, got %v", err)
+	}
+	if err := client.DeletePermissionSetAssignment(""); !errors.Is(err, errEmptyAssignmentID) {
+		t.Errorf("expected errEmptyAssignmentID from delete, got %v", err)
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("expected no requests, got %v", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":                              0,
+		"0":                             0,
+		"120":                           2 * time.Minute,
+		"-5":                            0,
+		"soon":                          0,
+		"1.5":                           0,
+		"Mon, 02 Jan 2006 15:04:05 GMT": 0, // in the past
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("%q: expected %s, got %s", value, want, got)
+		}
+	}
+
+	// A date in the future is the time until then
+	got := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got <= 55*time.Second || got > time.Minute {
+		t.Errorf("expected about a minute for a date a minute away, got %s", got)
+	}
+}
+
+func TestIsNotFoundError_IgnoresPath(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusInternalServerError, "internal error")
+	}))
+
+	// The path contains "404", which must not be mistaken for the status
+	_, err := client.GetGroup("team-404")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if isNotFoundError(err) {
+		t.Errorf("expected a 500 to not be treated as not found: %s", err)
+	}
+	if isDependencyNotFoundError(err) {
+		t.Errorf("expected a 500 to not be treated as a missing dependency: %s", err)
+	}
+}
+
+func TestGroupResource_ReadErrorNamesEndpoint(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusBadRequest, "invalid group name")
+	}))
+	h := newResourceHarness(t, NewGroupResource(), client)
+
+	_, diags := h.read(h.state(&GroupResourceModel{
+		ID:          types.StringValue("group-1"),
+		Name:        types.StringValue("platform/eng"),
+		Description: types.StringValue(""),
+		Path:        types.StringValue(""),
+		RealmRoles:  types.ListNull(types.StringType),
+		Timeouts:    nullTimeouts("delete"),
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	detail := diags.Errors()[0].Detail()
+	if !strings.Contains(detail, "GET /api/v1/customers/test/groups/platform%2Feng: API error (400)") {
+		t.Errorf("expected the diagnostic to name the method and path, got %q", detail)
+	}
+}
+
+// hostileNames are names that break a path when interpolated unescaped.
+var hostileNames = []string{
+	"SRE / Platform",
+	"platform/eng",
+	"on call",
+	"team#1",
+	"what?now",
+	"100%",
+	"a+b&c=d",
+	"plateforme-équipe",
+	"開発チーム",
+	"..",
+	".",
+}
+
+func TestClient_HostileNames(t *testing.T) {
+	for _, name := range hostileNames {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakePrism()
+			fake.permSets[name] = &PermissionSet{ID: name, Name: "ReadOnly"}
+			fake.accounts[name] = &AWSAccount{ID: "acct-1", AccountID: name, AccountName: "Shared"}
+			fake.assignments[name] = &PermissionSetAssignment{ID: name, PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "alice"}
+			handler, requests := recordRequestURIs(fake)
+			client := newTestClient(t, handler)
+			escaped := escapePathSegment(name)
+
+			// Users
+			if _, err := client.CreateUser(&User{Username: name, Email: "user@example.com", Enabled: true}); err != nil {
+				t.Fatalf("create user: %s", err)
+			}
+			if user, err := client.GetUser(name); err != nil || user.Username != name {
+				t.Fatalf("get user: %v, %v", user, err)
+			}
+			if _, err := client.UpdateUser(name, &User{Username: name, Email: "user@example.org", Enabled: true}); err != nil {
+				t.Fatalf("update user: %s", err)
+			}
+
+			// Groups and members
+			if _, err := client.CreateGroup(&Group{Name: name}); err != nil {
+				t.Fatalf("create group: %s", err)
+			}
+			if group, err := client.GetGroup(name); err != nil || group.Name != name {
+				t.Fatalf("get group: %v, %v", group, err)
+			}
+			if _, err := client.UpdateGroup(name, &Group{Name: name, Description: "updated"}); err != nil {
+				t.Fatalf("update group: %s", err)
+			}
+			if err := client.AddGroupMembers(name, []string{name}); err != nil {
+				t.Fatalf("add group members: %s", err)
+			}
+			if members, err := client.GetGroupMembers(name); err != nil || len(members) != 1 || members[0] != name {
+				t.Fatalf("get group members: %v, %v", members, err)
+			}
+			if err := client.RemoveGroupMembers(name, []string{name}); err != nil {
+				t.Fatalf("remove group members: %s", err)
+			}
+			if err := client.DeleteGroup(name); err != nil {
+				t.Fatalf("delete group: %s", err)
+			}
+			if err := client.DeleteUser(name); err != nil {
+				t.Fatalf("delete user: %s", err)
+			}
+
+			// Permission sets and assignments by ID
+			if _, err := client.GetPermissionSet(name); err != nil {
+				t.Fatalf("get permission set: %s", err)
+			}
+			if _, err := client.UpdatePermissionSet(name, &PermissionSet{Name: "ReadOnly", Description: "updated"}); err != nil {
+				t.Fatalf("update permission set: %s", err)
+			}
+			if _, err := client.GetPermissionSetAssignment(name); err != nil {
+				t.Fatalf("get assignment: %s", err)
+			}
+			if err := client.DeletePermissionSetAssignment(name); err != nil {
+				t.Fatalf("delete assignment: %s", err)
+			}
+			if err := client.DeletePermissionSet(name); err != nil {
+				t.Fatalf("delete permission set: %s", err)
+			}
+
+			// AWS accounts
+			if _, err := client.GetAWSAccount(name); err != nil {
+				t.Fatalf("get account: %s", err)
+			}
+			if _, err := client.SetAWSAccountOwners(name, []string{"owner@example.com"}); err != nil {
+				t.Fatalf("set account owners: %s", err)
+			}
+			if _, err := client.GetAWSAccountOwners(name); err != nil {
+				t.Fatalf("get account owners: %s", err)
+			}
+			if _, err := client.UpdateAWSAccount(name, &AWSAccount{AccountID: name, AccountName: "Renamed"}); err != nil {
+				t.Fatalf("update account: %s", err)
+			}
+			if err := client.DeleteAWSAccount(name); err != nil {
+				t.Fatalf("delete account: %s", err)
+			}
+
+			if len(fake.users) != 0 || len(fake.groups) != 0 || len(fake.permSets) != 0 ||
+				len(fake.assignments) != 0 || len(fake.accounts) != 0 {
+				t.Errorf("expected every object to be deleted, got %d users, %d groups, %d permission sets, %d assignments, %d accounts",
+					len(fake.users), len(fake.groups), len(fake.permSets), len(fake.assignments), len(fake.accounts))
+			}
+			found := false
+			for _, req := range requests() {
+				uri := strings.SplitN(req, " ", 2)[1]
+				if strings.ContainsAny(uri, " ?#") {
+					t.Errorf("expected %q to be escaped in the request URI, got %q", name, uri)
+				}
+				found = found || strings.Contains(uri, "/"+escaped+"/") || strings.HasSuffix(uri, "/"+escaped)
+			}
+			if !found {
+				t.Errorf("expected a request URI containing %q, got %v", escaped, requests())
+			}
+		})
+	}
+}
+
+func TestEscapePathSegment(t *testing.T) {
+	tests := map[string]string{
+		"alice":          "alice",
+		"SRE / Platform": "SRE%20%2F%20Platform",
+		"team#1":         "team%231",
+		"what?now":       "what%3Fnow",
+		"100%":           "100%25",
+		"開発":             "%E9%96%8B%E7%99%BA",
+		"..":             "%2E%2E",
+		".":              "%2E",
+		"a.b":            "a.b",
+		"assign:42":      "assign%3A42",
+	}
+	for in, want := range tests {
+		if got := escapePathSegment(in); got != want {
+			t.Errorf("escapePathSegment(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// rejectDeleteBodies wraps handler as a proxy that answers DELETE requests
+// carrying a body with status, and hides the routes in missing.
+func rejectDeleteBodies(handler http.Handler, status int, missing ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.ContentLength != 0 {
+			writeAPIError(w, status, "request body not allowed")
+			return
+		}
+		for _, suffix := range missing {
+			if strings.HasSuffix(r.URL.EscapedPath(), suffix) {
+				writeAPIError(w, http.StatusNotFound, "not found")
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newMembershipFake returns a fake with group "eng" holding alice, bob, and carol.
+func newMembershipFake() *fakePrism {
+	fake := newFakePrism()
+	fake.groups["eng"] = &Group{ID: "group-1", Name: "eng"}
+	fake.members["eng"] = []string{"alice", "bob", "carol"}
+	return fake
+}
+
+func TestClient_RemoveGroupMembers_DeleteBody(t *testing.T) {
+	fake := newMembershipFake()
+	handler, requests := recordRequestURIs(fake)
+	client := newTestClient(t, handler)
+
+	if err := client.RemoveGroupMembers("eng", []string{"alice", "bob"}); err != nil {
+		t.Fatalf("remove group members: %s", err)
+	}
+
+	want := []string{"DELETE /api/v1/customers/test/groups/eng/members"}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if got := fake.members["eng"]; len(got) != 1 || got[0] != "carol" {
+		t.Errorf("expected only carol to remain, got %v", got)
+	}
+}
+
+func TestClient_RemoveGroupMembers_FallsBackToRemoveAction(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			fake := newMembershipFake()
+			handler, requests := recordRequestURIs(rejectDeleteBodies(fake, status))
+			client := newTestClient(t, handler)
+
+			if err := client.RemoveGroupMembers("eng", []string{"alice"}); err != nil {
+				t.Fatalf("remove group members: %s", err)
+			}
+			if err := client.RemoveGroupMembers("eng", []string{"bob"}); err != nil {
+				t.Fatalf("remove group members: %s", err)
+			}
+
+			// The DELETE with a body is tried once; later calls use the cached form
+			want := []string{
+				"DELETE /api/v1/customers/test/groups/eng/members",
+				"POST /api/v1/customers/test/groups/eng/members:remove",
+				"POST /api/v1/customers/test/groups/eng/members:remove",
+			}
+			if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+			}
+			if got := fake.members["eng"]; len(got) != 1 || got[0] != "carol" {
+				t.Errorf("expected only carol to remain, got %v", got)
+			}
+		})
+	}
+}
+
+func TestClient_RemoveGroupMembers_FallsBackToPerUserDelete(t *testing.T) {
+	fake := newMembershipFake()
+	handler, requests := recordRequestURIs(rejectDeleteBodies(fake, http.StatusBadRequest, "/members:remove"))
+	client := newTestClient(t, handler)
+
+	if err := client.RemoveGroupMembers("eng", []string{"alice", "bob"}); err != nil {
+		t.Fatalf("remove group members: %s", err)
+	}
+	// dave is not a member; once the form is known, that counts as removed
+	if err := client.RemoveGroupMembers("eng", []string{"carol", "dave"}); err != nil {
+		t.Fatalf("remove group members: %s", err)
+	}
+
+	want := []string{
+		"DELETE /api/v1/customers/test/groups/eng/members",
+		"POST /api/v1/customers/test/groups/eng/members:remove",
+		"DELETE /api/v1/customers/test/groups/eng/members/alice",
+		"DELETE /api/v1/customers/test/groups/eng/members/bob",
+		"DELETE /api/v1/customers/test/groups/eng/members/carol",
+		"DELETE /api/v1/customers/test/groups/eng/members/dave",
+	}
+	if got := requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected requests:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if got := fake.members["eng"]; len(got) != 0 {
+		t.Errorf("expected no members to remain, got %v", got)
+	}
+}
+
+func TestClient_RemoveGroupMembers_NoSupportedForm(t *testing.T) {
+	fake := newMembershipFake()
+	client := newTestClient(t, rejectDeleteBodies(fake, http.StatusBadRequest, "/members:remove", "/members/alice"))
+
+	err := client.RemoveGroupMembers("eng", []string{"alice"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "no supported way to remove group members") ||
+		!strings.Contains(err.Error(), "DELETE /api/v1/customers/test/groups/eng/members: API error (400)") {
+		t.Errorf("expected the error to report the rejected DELETE, got %q", err)
+	}
+	if client.memberRemoval.style != memberRemovalBody {
+		t.Errorf("expected a failed probe not to be cached, got style %d", client.memberRemoval.style)
+	}
+}
+
+func TestClient_RemoveGroupMembers_OtherErrorsAreNotProbed(t *testing.T) {
+	fake := newMembershipFake()
+	handler, requests := recordRequestURIs(fake)
+	client := newTestClient(t, handler)
+
+	err := client.RemoveGroupMembers("missing", []string{"alice"})
+	if !isNotFoundError(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if got := requests(); len(got) != 1 {
+		t.Errorf("expected a single request, got %v", got)
+	}
+}
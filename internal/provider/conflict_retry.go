@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Backoff bounds for retrying a request the backend refuses with 409 Conflict
+const (
+	conflictInitialBackoff = 1 * time.Second
+	conflictMaxBackoff     = 10 * time.Second
+)
+
+// retryOnConflict calls do until it succeeds or fails with anything but a
+// 409 Conflict, backing off exponentially between attempts. The backend
+// refuses to delete a user or group while membership changes on it are
+// still being processed, which happens when its membership resource was
+// removed just before it in the same apply, and refuses a permission set
+// update that races another. After timeout it returns the last conflict,
+// and it returns ctx's error if ctx is done first. A Retry-After header on
+// the conflict is honoured when it asks for longer.
+func retryOnConflict(ctx context.Context, timeout time.Duration, description string, do func() error) error {
+	deadline := time.Now().Add(timeout)
+	backoff := conflictInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := do()
+		if err == nil || !isConflictError(err) {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("still conflicting after retrying for %s: %w", timeout, err)
+		}
+
+		wait := backoff
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		if wait > remaining {
+			wait = remaining
+		}
+		tflog.Debug(ctx, "Request conflicted, retrying", map[string]interface{}{
+			"resource": description,
+			"attempt":  attempt,
+			"wait":     wait.String(),
+			"error":    err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped retrying: %w (last error: %s)", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > conflictMaxBackoff {
+			backoff = conflictMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// conflictingDeletes answers the first conflicts DELETE requests with 409
+// Conflict, as the backend does while membership changes are pending, and
+// passes everything else to next. A negative conflicts never stops.
+type conflictingDeletes struct {
+	next      http.Handler
+	conflicts int
+	status    int
+
+	mu       sync.Mutex
+	attempts int
+}
+
+func (c *conflictingDeletes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete && !strings.Contains(r.URL.Path, "/permission-set-assignments") {
+		c.mu.Lock()
+		c.attempts++
+		conflict := c.conflicts < 0 || c.attempts <= c.conflicts
+		c.mu.Unlock()
+		if conflict {
+			status := c.status
+			if status == 0 {
+				status = http.StatusConflict
+			}
+			writeAPIError(w, status, "group has pending membership operations")
+			return
+		}
+	}
+	c.next.ServeHTTP(w, r)
+}
+
+func TestGroupDelete_RetriesConflict(t *testing.T) {
+	fake := newFakePrism()
+	fake.groups["developers"] = &Group{ID: "group-1", Name: "developers"}
+	handler := &conflictingDeletes{next: fake, conflicts: 1}
+	h := newResourceHarness(t, NewGroupResource(), newTestClient(t, handler))
+
+	state := testGroupModel("developers", "")
+	state.ID = types.StringValue("group-1")
+	if diags := h.delete(h.state(state)); diags.HasError() {
+		t.Fatalf("expected the delete to succeed after a conflict, got %v", diags)
+	}
+	if handler.attempts != 2 {
+		t.Errorf("expected 2 delete attempts, got %d", handler.attempts)
+	}
+	if _, ok := fake.groups["developers"]; ok {
+		t.Error("expected the group to be deleted")
+	}
+}
+
+func TestUserDelete_RetriesConflict(t *testing.T) {
+	fake := newFakePrism()
+	fake.users["alice"] = &User{ID: "user-1", Username: "alice", Email: "alice@example.com"}
+	handler := &conflictingDeletes{next: fake, conflicts: 2}
+	h := newResourceHarness(t, NewUserResource(), newTestClient(t, handler))
+
+	if diags := h.delete(h.state(testUserModel("alice", "alice@example.com"))); diags.HasError() {
+		t.Fatalf("expected the delete to succeed after conflicts, got %v", diags)
+	}
+	if handler.attempts != 3 {
+		t.Errorf("expected 3 delete attempts, got %d", handler.attempts)
+	}
+	if _, ok := fake.users["alice"]; ok {
+		t.Error("expected the user to be deleted")
+	}
+}
+
+func TestGroupDelete_PersistentConflictTimesOut(t *testing.T) {
+	fake := newFakePrism()
+	fake.groups["developers"] = &Group{ID: "group-1", Name: "developers"}
+	handler := &conflictingDeletes{next: fake, conflicts: -1}
+	h := newResourceHarness(t, NewGroupResource(), newTestClient(t, handler))
+
+	state := testGroupModel("developers", "")
+	state.ID = types.StringValue("group-1")
+	state.Timeouts = testTimeouts(map[string]string{"delete": "2s"})
+	start := time.Now()
+	diags := h.delete(h.state(state))
+	elapsed := time.Since(start)
+
+	if detail := diagnosticDetail(diags, "Client Error"); !strings.Contains(detail, "still conflicting after retrying for 2s") {
+		t.Errorf("expected a conflict error after the timeout, got %v", diags)
+	}
+	if handler.attempts < 2 {
+		t.Errorf("expected the delete to be retried, got %d attempts", handler.attempts)
+	}
+	if elapsed > 6*time.Second {
+		t.Errorf("expected the retries to stop near the 2s timeout, took %v", elapsed)
+	}
+	if _, ok := fake.groups["developers"]; !ok {
+		t.Error("expected the group to remain")
+	}
+}
+
+func TestUserDelete_OtherErrorsAreNotRetried(t *testing.T) {
+	fake := newFakePrism()
+	fake.users["alice"] = &User{ID: "user-1", Username: "alice", Email: "alice@example.com"}
+	handler := &conflictingDeletes{next: fake, conflicts: 1, status: http.StatusInternalServerError}
+	h := newResourceHarness(t, NewUserResource(), newTestClient(t, handler))
+
+	diags := h.delete(h.state(testUserModel("alice", "alice@example.com")))
+	if !hasDiagnostic(diags, "Client Error") {
+		t.Errorf("expected a Client Error, got %v", diags)
+	}
+	if handler.attempts != 1 {
+		t.Errorf("expected a single delete attempt, got %d", handler.attempts)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ datasource.DataSource = &AWSAccountDataSource{}
@@ -93,7 +95,7 @@ func (d *AWSAccountDataSource) Read(ctx context.Context, req datasource.ReadRequ
 
 	account, err := d.client.GetAWSAccount(data.AccountID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read AWS account, got error: %s", err))
+		addLookupError(&resp.Diagnostics, "AWS account", "account_id", data.AccountID.ValueString(), err)
 		return
 	}
 
@@ -106,17 +108,24 @@ func (d *AWSAccountDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		data.RoleArn = types.StringValue(account.RoleArn)
 	}
 
-	// Set owner_emails from API response
-	if len(account.OwnerEmails) > 0 {
-		ownerEmailsList, diags := types.ListValueFrom(ctx, types.StringType, account.OwnerEmails)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
+	// Older backends leave owner_emails out of the account payload, so fall
+	// back to the owners sub-resource. Owners are best-effort: a backend
+	// without that endpoint leaves owner_emails null.
+	ownerEmails := account.OwnerEmails
+	if len(ownerEmails) == 0 {
+		ownerEmails, err = d.client.GetAWSAccountOwners(data.AccountID.ValueString())
+		if err != nil {
+			if !isNotFoundError(err) && !isNotImplementedError(err) {
+				addReadError(&resp.Diagnostics, "read AWS account owners", err)
+				return
+			}
+			tflog.Debug(ctx, "AWS account owners are unavailable, leaving owner_emails unset", map[string]interface{}{
+				"account_id": data.AccountID.ValueString(),
+				"error":      err.Error(),
+			})
 		}
-		data.OwnerEmails = ownerEmailsList
-	} else {
-		data.OwnerEmails = types.ListNull(types.StringType)
 	}
+	data.OwnerEmails = convert.StringListOrNull(ownerEmails)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
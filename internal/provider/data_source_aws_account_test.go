@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// oldBackendAccountPayload is an account as older backends return it,
+// without owner_emails.
+const oldBackendAccountPayload = `{"success":true,"data":{"id":"acct-1","account_id":"111111111111","name":"Production","region":"us-east-1"}}`
+
+// accountHandler serves account as the account payload and responds to the
+// owners sub-resource with ownersStatus and ownersBody.
+func accountHandler(account string, ownersStatus int, ownersBody string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/owners") {
+			w.WriteHeader(ownersStatus)
+			_, _ = w.Write([]byte(ownersBody))
+			return
+		}
+		_, _ = w.Write([]byte(account))
+	}
+}
+
+func readAccountDataSource(t *testing.T, handler http.Handler) (AWSAccountDataSourceModel, bool, string) {
+	t.Helper()
+
+	state, diags := readDataSource(t, NewAWSAccountDataSource(), newTestClient(t, handler), &AWSAccountDataSourceModel{
+		AccountID:   types.StringValue("111111111111"),
+		OwnerEmails: types.ListNull(types.StringType),
+	})
+	var got AWSAccountDataSourceModel
+	if diags.HasError() {
+		return got, false, diags.Errors()[0].Summary()
+	}
+	state.Get(context.Background(), &got)
+	return got, true, ""
+}
+
+func TestAWSAccountDataSource_OwnersUnavailable(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+	}{
+		"not implemented": {status: http.StatusNotImplemented, body: `{"success":false,"error":"not implemented"}`},
+		"not found":       {status: http.StatusNotFound, body: `{"success":false,"error":"not found"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok, summary := readAccountDataSource(t, accountHandler(oldBackendAccountPayload, tt.status, tt.body))
+			if !ok {
+				t.Fatalf("expected the read to succeed, got %q", summary)
+			}
+			if got.AccountName.ValueString() != "Production" {
+				t.Errorf("expected account_name Production, got %s", got.AccountName)
+			}
+			if !got.OwnerEmails.IsNull() {
+				t.Errorf("expected null owner_emails, got %s", got.OwnerEmails)
+			}
+		})
+	}
+}
+
+func TestAWSAccountDataSource_OwnersFromSubResource(t *testing.T) {
+	got, ok, summary := readAccountDataSource(t, accountHandler(oldBackendAccountPayload, http.StatusOK,
+		`{"success":true,"data":{"account_id":"111111111111","owner_emails":["ops@example.com"]}}`))
+	if !ok {
+		t.Fatalf("expected the read to succeed, got %q", summary)
+	}
+	if got.OwnerEmails.String() != `["ops@example.com"]` {
+		t.Errorf("expected owner_emails from the owners endpoint, got %s", got.OwnerEmails)
+	}
+}
+
+func TestAWSAccountDataSource_OwnersError(t *testing.T) {
+	_, ok, summary := readAccountDataSource(t, accountHandler(oldBackendAccountPayload, http.StatusInternalServerError,
+		`{"success":false,"error":"internal error"}`))
+	if ok || summary != "Client Error" {
+		t.Errorf("expected a Client Error for a failing owners endpoint, got %q", summary)
+	}
+}
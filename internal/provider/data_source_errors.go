@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// addReadError adds the error for a data source call that failed with err,
+// where action says what was attempted, e.g. "list permission sets". The
+// detail tells a problem with the provider's credentials and a Prism outage
+// apart from other failures.
+func addReadError(diags *diag.Diagnostics, action string, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s, got error: %s", action, err))
+		return
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s: the Prism API rejected the provider's credentials (HTTP %d). "+
+			"Check api_token (or PRISM_API_TOKEN), the auth block and prism_subdomain in the provider configuration.\n\nError: %s",
+			action, apiErr.StatusCode, err))
+	case apiErr.StatusCode == http.StatusForbidden:
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s: the provider's credentials are not allowed to do this (HTTP %d). "+
+			"Check that the credentials in the provider configuration (api_token or the auth block) belong to prism_subdomain and have the needed permissions.\n\nError: %s",
+			action, apiErr.StatusCode, err))
+	case apiErr.StatusCode >= 500:
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s: the Prism API returned a server error (HTTP %d), which is usually temporary. "+
+			"Retry the plan or apply, and check the state of the Prism service if the error persists.\n\nError: %s",
+			action, apiErr.StatusCode, err))
+	default:
+		diags.AddError("Client Error", fmt.Sprintf("Unable to %s, got error: %s", action, err))
+	}
+}
+
+// addLookupError is addReadError for reading the object, e.g. "AWS account",
+// whose attribute is value. A 404 is reported on the attribute as the object
+// not being found.
+func addLookupError(diags *diag.Diagnostics, object, attribute, value string, err error) {
+	if !isNotFoundError(err) {
+		addReadError(diags, "read "+object, err)
+		return
+	}
+	diags.AddAttributeError(
+		path.Root(attribute),
+		titleCase(object)+" Not Found",
+		fmt.Sprintf("%s with %s %q was not found. Check the %s and that the %s exists in this Prism subdomain.",
+			upperFirst(object), attribute, value, attribute, object),
+	)
+}
+
+// titleCase upper-cases the first letter of each word in s.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = upperFirst(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAddLookupError(t *testing.T) {
+	apiError := func(status int) error {
+		return &APIError{Method: "GET", Path: "/api/v1/customers/test/aws-accounts/123456789012", StatusCode: status, Body: "boom"}
+	}
+	tests := map[string]struct {
+		err         error
+		wantSummary string
+		wantDetail  string
+	}{
+		"not found":    {err: apiError(http.StatusNotFound), wantSummary: "AWS Account Not Found", wantDetail: `AWS account with account_id "123456789012" was not found`},
+		"unauthorized": {err: apiError(http.StatusUnauthorized), wantSummary: "Client Error", wantDetail: "rejected the provider's credentials (HTTP 401). Check api_token"},
+		"forbidden":    {err: apiError(http.StatusForbidden), wantSummary: "Client Error", wantDetail: "not allowed to do this (HTTP 403)"},
+		"server error": {err: apiError(http.StatusBadGateway), wantSummary: "Client Error", wantDetail: "server error (HTTP 502), which is usually temporary. Retry"},
+		"bad request":  {err: apiError(http.StatusBadRequest), wantSummary: "Client Error", wantDetail: "Unable to read AWS account, got error: GET"},
+		"network":      {err: errors.New("connection refused"), wantSummary: "Client Error", wantDetail: "Unable to read AWS account, got error: connection refused"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			addLookupError(&diags, "AWS account", "account_id", "123456789012", tt.err)
+			if len(diags) != 1 || diags[0].Summary() != tt.wantSummary {
+				t.Fatalf("expected one %q error, got %v", tt.wantSummary, diags)
+			}
+			if !strings.Contains(diags[0].Detail(), tt.wantDetail) {
+				t.Errorf("expected the detail to contain %q, got %q", tt.wantDetail, diags[0].Detail())
+			}
+		})
+	}
+
+	var diags diag.Diagnostics
+	addLookupError(&diags, "AWS account", "account_id", "123456789012", apiError(http.StatusNotFound))
+	if withPath, ok := diags[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("account_id")) {
+		t.Errorf("expected the not found error on account_id, got %v", diags[0])
+	}
+}
+
+func TestUserDataSource_ReadErrors(t *testing.T) {
+	tests := map[int]struct {
+		wantSummary string
+		wantDetail  string
+	}{
+		http.StatusNotFound:           {wantSummary: "User Not Found", wantDetail: `User with id "user-1" was not found`},
+		http.StatusUnauthorized:       {wantSummary: "Client Error", wantDetail: "rejected the provider's credentials"},
+		http.StatusServiceUnavailable: {wantSummary: "Client Error", wantDetail: "usually temporary"},
+	}
+	for status, tt := range tests {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAPIError(w, status, "unavailable")
+			}))
+			_, diags := readDataSource(t, NewUserDataSource(), client, &UserDataSourceModel{
+				ID:         types.StringValue("user-1"),
+				Attributes: types.MapNull(types.StringType),
+			})
+			if !hasDiagnostic(diags, tt.wantSummary) {
+				t.Fatalf("expected %q, got %v", tt.wantSummary, diags)
+			}
+			if detail := diagnosticDetail(diags, tt.wantSummary); !strings.Contains(detail, tt.wantDetail) {
+				t.Errorf("expected the detail to contain %q, got %q", tt.wantDetail, detail)
+			}
+		})
+	}
+}
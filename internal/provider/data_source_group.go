@@ -3,9 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -24,6 +28,9 @@ type GroupDataSourceModel struct {
 	Name        types.String `tfsdk:"name"`
 	Description types.String `tfsdk:"description"`
 	Path        types.String `tfsdk:"path"`
+	ParentPath  types.String `tfsdk:"parent_path"`
+	ParentID    types.String `tfsdk:"parent_id"`
+	RealmRoles  types.List   `tfsdk:"realm_roles"`
 }
 
 func (d *GroupDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -32,15 +39,21 @@ func (d *GroupDataSource) Metadata(ctx context.Context, req datasource.MetadataR
 
 func (d *GroupDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Fetches information about a CloudKeeper group.",
+		MarkdownDescription: "Fetches information about a CloudKeeper group, looked up by its name, its full path, or its ID. " +
+			"Use `path` when groups in different parts of the hierarchy share a name.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "The unique identifier for the group",
+				MarkdownDescription: "The unique identifier for the group. Exactly one of `id`, `name` and `path` must be set.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("name"), path.MatchRoot("path")),
+				},
 			},
 			"name": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
+				Computed:            true,
 				MarkdownDescription: "The name of the group",
 			},
 			"description": schema.StringAttribute{
@@ -48,8 +61,22 @@ func (d *GroupDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 				MarkdownDescription: "A description of the group",
 			},
 			"path": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The full path of the group in the group hierarchy, e.g. `/engineering/platform`. A trailing slash is ignored when looking the group up.",
+			},
+			"parent_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The full path of the group's parent, e.g. `/engineering`. Null for a top-level group.",
+			},
+			"parent_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The unique identifier of the group's parent. Null for a top-level group, or if the parent isn't visible to the provider.",
+			},
+			"realm_roles": schema.ListAttribute{
 				Computed:            true,
-				MarkdownDescription: "The path of the group (for hierarchical groups)",
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the realm roles mapped to the group, sorted. Null if the Prism backend doesn't report role mappings.",
 			},
 		},
 	}
@@ -80,17 +107,134 @@ func (d *GroupDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
-	group, err := d.client.GetGroup(data.Name.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read group, got error: %s", err))
-		return
+	// Groups are only listed when looking up by path or ID, or to find the
+	// parent of a nested group
+	var groups []Group
+	listGroups := func() bool {
+		if groups != nil {
+			return true
+		}
+		var err error
+		groups, err = d.client.ListGroups()
+		if err != nil {
+			addReadError(&resp.Diagnostics, "list groups", err)
+			return false
+		}
+		return true
+	}
+
+	var group *Group
+	if !data.Name.IsNull() {
+		groupName := data.Name.ValueString()
+		var err error
+		group, err = d.client.GetGroup(groupName)
+		if err != nil {
+			addLookupError(&resp.Diagnostics, "group", "name", groupName, err)
+			return
+		}
+		if group.Name == "" {
+			group.Name = groupName
+		}
+	} else {
+		if !listGroups() {
+			return
+		}
+		group = d.findGroup(data, groups, resp)
+		if group == nil {
+			return
+		}
 	}
 
 	data.ID = types.StringValue(group.ID)
+	data.Name = types.StringValue(group.Name)
 	data.Description = types.StringValue(group.Description)
+	data.Path = types.StringNull()
+	data.ParentPath = types.StringNull()
+	data.ParentID = types.StringNull()
 	if group.Path != "" {
 		data.Path = types.StringValue(group.Path)
 	}
 
+	if parentPath := parentGroupPath(group.Path); parentPath != "" {
+		data.ParentPath = types.StringValue(parentPath)
+		if !listGroups() {
+			return
+		}
+		for _, candidate := range groups {
+			if normalizeGroupPath(candidate.Path) == parentPath {
+				data.ParentID = types.StringValue(candidate.ID)
+				break
+			}
+		}
+	}
+
+	data.RealmRoles = groupRealmRoles(ctx, d.client, group.ID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// findGroup returns the group in groups with the configured path or id, or
+// nil after adding an error if there's no single such group.
+func (d *GroupDataSource) findGroup(data GroupDataSourceModel, groups []Group, resp *datasource.ReadResponse) *Group {
+	attribute, want := "id", data.ID.ValueString()
+	matches := func(group Group) bool { return group.ID == want }
+	if !data.Path.IsNull() {
+		attribute, want = "path", normalizeGroupPath(data.Path.ValueString())
+		matches = func(group Group) bool { return normalizeGroupPath(group.Path) == want }
+	}
+
+	var found []Group
+	for _, group := range groups {
+		if matches(group) {
+			found = append(found, group)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attribute),
+			"Group Not Found",
+			fmt.Sprintf("No group has %s %q. Check the %s and that the group exists in this Prism subdomain.", attribute, want, attribute),
+		)
+		return nil
+	case 1:
+		return &found[0]
+	default:
+		var ids []string
+		for _, group := range found {
+			ids = append(ids, group.ID)
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root(attribute),
+			"Multiple Groups Found",
+			fmt.Sprintf("%d groups have %s %q (%s). Use id to choose one.", len(found), attribute, want, strings.Join(ids, ", ")),
+		)
+		return nil
+	}
+}
+
+// normalizeGroupPath returns groupPath with a leading slash and without a
+// trailing one, e.g. "/engineering/platform" for "engineering/platform/".
+// It returns "" for an empty path.
+func normalizeGroupPath(groupPath string) string {
+	groupPath = strings.Trim(groupPath, "/")
+	if groupPath == "" {
+		return ""
+	}
+	return "/" + groupPath
+}
+
+// parentGroupPath returns the normalized path of the parent of the group at
+// groupPath, or "" for a top-level group.
+func parentGroupPath(groupPath string) string {
+	groupPath = normalizeGroupPath(groupPath)
+	i := strings.LastIndex(groupPath, "/")
+	if i <= 0 {
+		return ""
+	}
+	return groupPath[:i]
+}
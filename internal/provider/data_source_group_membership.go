@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &GroupMembershipDataSource{}
+
+func NewGroupMembershipDataSource() datasource.DataSource {
+	return &GroupMembershipDataSource{}
+}
+
+type GroupMembershipDataSource struct {
+	client *Client
+}
+
+type GroupMembershipDataSourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	GroupName   types.String `tfsdk:"group_name"`
+	Usernames   types.List   `tfsdk:"usernames"`
+	MemberCount types.Int64  `tfsdk:"member_count"`
+}
+
+func (d *GroupMembershipDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_membership"
+}
+
+func (d *GroupMembershipDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches the members of a CloudKeeper group without managing them.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The identifier for this group membership (group_name)",
+			},
+			"group_name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The name of the group",
+			},
+			"usernames": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Usernames of the group members, sorted alphabetically",
+			},
+			"member_count": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The number of members in the group",
+			},
+		},
+	}
+}
+
+func (d *GroupMembershipDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *GroupMembershipDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data GroupMembershipDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	groupName := data.GroupName.ValueString()
+	members, err := d.client.GetGroupMembers(groupName)
+	if err != nil {
+		addLookupError(&resp.Diagnostics, "group", "group_name", groupName, err)
+		return
+	}
+
+	// Sort members alphabetically to ensure consistent ordering
+	sort.Strings(members)
+
+	data.ID = types.StringValue(groupName)
+	data.Usernames = convert.StringListOrEmpty(members)
+	data.MemberCount = types.Int64Value(int64(len(members)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func groupMembersHandler(members map[string][]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		groupName := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/customers/test/groups/"), "/members")
+		usernames, ok := members[groupName]
+		if !ok {
+			http.Error(w, `{"success":false,"error":"group not found"}`, http.StatusNotFound)
+			return
+		}
+
+		type member struct {
+			Username string `json:"username"`
+		}
+		result := []member{}
+		for _, u := range usernames {
+			result = append(result, member{Username: u})
+		}
+		writeAPIData(w, map[string]interface{}{
+			"group":   groupName,
+			"members": result,
+			"count":   len(result),
+		})
+	}
+}
+
+func TestGroupMembershipDataSource_Populated(t *testing.T) {
+	client := newTestClient(t, groupMembersHandler(map[string][]string{
+		"oncall": {"carol", "alice", "bob"},
+	}))
+
+	state, diags := readDataSource(t, NewGroupMembershipDataSource(), client, &GroupMembershipDataSourceModel{
+		GroupName: types.StringValue("oncall"),
+		Usernames: types.ListNull(types.StringType),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got GroupMembershipDataSourceModel
+	state.Get(context.Background(), &got)
+
+	var usernames []string
+	got.Usernames.ElementsAs(context.Background(), &usernames, false)
+	if strings.Join(usernames, ",") != "alice,bob,carol" {
+		t.Errorf("expected sorted usernames, got %v", usernames)
+	}
+	if got.MemberCount.ValueInt64() != 3 {
+		t.Errorf("expected member_count 3, got %d", got.MemberCount.ValueInt64())
+	}
+}
+
+func TestGroupMembershipDataSource_Empty(t *testing.T) {
+	client := newTestClient(t, groupMembersHandler(map[string][]string{
+		"oncall": {},
+	}))
+
+	state, diags := readDataSource(t, NewGroupMembershipDataSource(), client, &GroupMembershipDataSourceModel{
+		GroupName: types.StringValue("oncall"),
+		Usernames: types.ListNull(types.StringType),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got GroupMembershipDataSourceModel
+	state.Get(context.Background(), &got)
+	if got.Usernames.IsNull() || len(got.Usernames.Elements()) != 0 {
+		t.Errorf("expected an empty (non-null) usernames list, got %v", got.Usernames)
+	}
+	if got.MemberCount.ValueInt64() != 0 {
+		t.Errorf("expected member_count 0, got %d", got.MemberCount.ValueInt64())
+	}
+}
+
+func TestGroupMembershipDataSource_MissingGroup(t *testing.T) {
+	client := newTestClient(t, groupMembersHandler(map[string][]string{}))
+
+	_, diags := readDataSource(t, NewGroupMembershipDataSource(), client, &GroupMembershipDataSourceModel{
+		GroupName: types.StringValue("ghosts"),
+		Usernames: types.ListNull(types.StringType),
+	})
+	if !diags.HasError() {
+		t.Fatal("expected an error for a missing group")
+	}
+	if summary := diags.Errors()[0].Summary(); summary != "Group Not Found" {
+		t.Errorf("expected a Group Not Found diagnostic, got %q", summary)
+	}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// groupConfig returns config with its computed lists typed, as readDataSource
+// needs.
+func groupConfig(config GroupDataSourceModel) *GroupDataSourceModel {
+	config.RealmRoles = types.ListNull(types.StringType)
+	return &config
+}
+
+func TestGroupDataSource_Read(t *testing.T) {
+	fake := newFakePrism()
+	fake.groups["developers"] = &Group{ID: "group-1", Name: "developers", Description: "Platform developers"}
+	client := newTestClient(t, fake)
+
+	state, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{
+		Name: types.StringValue("developers"),
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got GroupDataSourceModel
+	state.Get(context.Background(), &got)
+	if got.ID.ValueString() != "group-1" {
+		t.Errorf("expected id group-1, got %s", got.ID)
+	}
+	if got.Name.ValueString() != "developers" {
+		t.Errorf("expected name developers, got %s", got.Name)
+	}
+	if got.Description.ValueString() != "Platform developers" {
+		t.Errorf("expected the description to be read, got %s", got.Description)
+	}
+	if !got.Path.IsNull() || !got.ParentPath.IsNull() || !got.ParentID.IsNull() {
+		t.Errorf("expected null path and parent, got %s, %s and %s", got.Path, got.ParentPath, got.ParentID)
+	}
+}
+
+func TestGroupDataSource_MissingGroup(t *testing.T) {
+	client := newTestClient(t, newFakePrism())
+
+	_, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{
+		Name: types.StringValue("ghosts"),
+	}))
+	if !diags.HasError() {
+		t.Fatal("expected an error for a missing group")
+	}
+	if summary := diags.Errors()[0].Summary(); summary != "Group Not Found" {
+		t.Errorf("expected a Group Not Found diagnostic, got %q", summary)
+	}
+}
+
+// newNestedGroupsFake returns a backend with two "platform" groups under
+// different parents. Groups are keyed by path, since the names repeat.
+func newNestedGroupsFake() *fakePrism {
+	fake := newFakePrism()
+	for _, group := range []*Group{
+		{ID: "group-1", Name: "engineering", Path: "/engineering"},
+		{ID: "group-2", Name: "platform", Path: "/engineering/platform"},
+		{ID: "group-3", Name: "data", Path: "/data"},
+		{ID: "group-4", Name: "platform", Path: "/data/platform/"},
+	} {
+		fake.groups[group.Path] = group
+	}
+	return fake
+}
+
+func TestGroupDataSource_ReadByPath(t *testing.T) {
+	tests := map[string]struct {
+		config                               *GroupDataSourceModel
+		wantID, wantParentID, wantParentPath string
+	}{
+		"path":                   {config: groupConfig(GroupDataSourceModel{Path: types.StringValue("/engineering/platform")}), wantID: "group-2", wantParentID: "group-1", wantParentPath: "/engineering"},
+		"path with trailing '/'": {config: groupConfig(GroupDataSourceModel{Path: types.StringValue("/data/platform/")}), wantID: "group-4", wantParentID: "group-3", wantParentPath: "/data"},
+		"path without '/' ends":  {config: groupConfig(GroupDataSourceModel{Path: types.StringValue("data/platform")}), wantID: "group-4", wantParentID: "group-3", wantParentPath: "/data"},
+		"id":                     {config: groupConfig(GroupDataSourceModel{ID: types.StringValue("group-2")}), wantID: "group-2", wantParentID: "group-1", wantParentPath: "/engineering"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newNestedGroupsFake())
+			state, diags := readDataSource(t, NewGroupDataSource(), client, tt.config)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			var got GroupDataSourceModel
+			state.Get(context.Background(), &got)
+			if got.ID.ValueString() != tt.wantID || got.Name.ValueString() != "platform" {
+				t.Errorf("expected platform group %s, got %s named %s", tt.wantID, got.ID, got.Name)
+			}
+			if got.ParentPath.ValueString() != tt.wantParentPath || got.ParentID.ValueString() != tt.wantParentID {
+				t.Errorf("expected parent %s at %s, got %s at %s", tt.wantParentID, tt.wantParentPath, got.ParentID, got.ParentPath)
+			}
+		})
+	}
+}
+
+func TestGroupDataSource_TopLevelGroupHasNoParent(t *testing.T) {
+	client := newTestClient(t, newNestedGroupsFake())
+	state, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{Path: types.StringValue("/engineering")}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got GroupDataSourceModel
+	state.Get(context.Background(), &got)
+	if got.ID.ValueString() != "group-1" || !got.ParentPath.IsNull() || !got.ParentID.IsNull() {
+		t.Errorf("expected group-1 without a parent, got %s with parent %s at %s", got.ID, got.ParentID, got.ParentPath)
+	}
+}
+
+func TestGroupDataSource_PathNotFound(t *testing.T) {
+	client := newTestClient(t, newNestedGroupsFake())
+	for name, config := range map[string]*GroupDataSourceModel{
+		"path": groupConfig(GroupDataSourceModel{Path: types.StringValue("/sales/platform")}),
+		"id":   groupConfig(GroupDataSourceModel{ID: types.StringValue("group-9")}),
+	} {
+		_, diags := readDataSource(t, NewGroupDataSource(), client, config)
+		if !hasDiagnostic(diags, "Group Not Found") {
+			t.Errorf("%s: expected a Group Not Found diagnostic, got %v", name, diags)
+		}
+	}
+}
+
+func TestGroupDataSource_RealmRoles(t *testing.T) {
+	fake := newNestedGroupsFake()
+	fake.realmRoles["group-2"] = []string{"prism-viewer", "prism-admin"}
+	client := newTestClient(t, fake)
+
+	state, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{Path: types.StringValue("/engineering/platform")}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	var got GroupDataSourceModel
+	state.Get(context.Background(), &got)
+	if got.RealmRoles.String() != `["prism-admin","prism-viewer"]` {
+		t.Errorf("expected sorted realm roles, got %s", got.RealmRoles)
+	}
+
+	// A group without mappings has an empty list
+	state, diags = readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{ID: types.StringValue("group-1")}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	state.Get(context.Background(), &got)
+	if got.RealmRoles.IsNull() || len(got.RealmRoles.Elements()) != 0 {
+		t.Errorf("expected an empty realm_roles list, got %s", got.RealmRoles)
+	}
+}
+
+func TestGroupDataSource_RealmRolesUnavailable(t *testing.T) {
+	fake := newNestedGroupsFake()
+	unavailable := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/role-mappings") {
+				writeAPIError(w, status, "role mappings unavailable")
+				return
+			}
+			fake.ServeHTTP(w, r)
+		})
+	}
+
+	for _, status := range []int{http.StatusNotFound, http.StatusNotImplemented} {
+		client := newTestClient(t, unavailable(status))
+		state, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{ID: types.StringValue("group-1")}))
+		if diags.HasError() {
+			t.Fatalf("%d: unexpected diagnostics: %v", status, diags)
+		}
+		var got GroupDataSourceModel
+		state.Get(context.Background(), &got)
+		if !got.RealmRoles.IsNull() {
+			t.Errorf("%d: expected null realm_roles, got %s", status, got.RealmRoles)
+		}
+	}
+
+	client := newTestClient(t, unavailable(http.StatusInternalServerError))
+	_, diags := readDataSource(t, NewGroupDataSource(), client, groupConfig(GroupDataSourceModel{ID: types.StringValue("group-1")}))
+	if !hasDiagnostic(diags, "Client Error") {
+		t.Errorf("expected a Client Error for a failing role-mappings endpoint, got %v", diags)
+	}
+}
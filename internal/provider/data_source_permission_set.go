@@ -3,7 +3,9 @@ package provider
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -26,6 +28,22 @@ type PermissionSetDataSourceModel struct {
 	SessionDuration types.String `tfsdk:"session_duration"`
 	ManagedPolicies types.List   `tfsdk:"managed_policies"`
 	InlinePolicies  types.Map    `tfsdk:"inline_policies"`
+
+	CreatedAt          types.String `tfsdk:"created_at"`
+	UpdatedAt          types.String `tfsdk:"updated_at"`
+	ProvisioningStatus types.String `tfsdk:"provisioning_status"`
+
+	IncludeAssignments types.Bool                            `tfsdk:"include_assignments"`
+	Assignments        []PermissionSetAssignmentSummaryModel `tfsdk:"assignments"`
+	AssignmentCount    types.Int64                           `tfsdk:"assignment_count"`
+}
+
+// PermissionSetAssignmentSummaryModel is one principal and account a
+// permission set is assigned to.
+type PermissionSetAssignmentSummaryModel struct {
+	PrincipalType types.String `tfsdk:"principal_type"`
+	Principal     types.String `tfsdk:"principal"`
+	AccountID     types.String `tfsdk:"account_id"`
 }
 
 func (d *PermissionSetDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -63,6 +81,46 @@ func (d *PermissionSetDataSource) Schema(ctx context.Context, req datasource.Sch
 				Computed:            true,
 				MarkdownDescription: "Map of inline IAM policy documents in JSON format. The key is the policy name, and the value is the policy document.",
 			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the permission set was created, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+			},
+			"updated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the permission set last changed, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+			},
+			"provisioning_status": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the permission set's policies are fully provisioned to its accounts, as reported by the Prism backend. Null if the backend doesn't report it.",
+			},
+			"include_assignments": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to read the permission set's assignments into `assignments` and `assignment_count`. This lists every assignment, so it is off by default.",
+			},
+			"assignments": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The principals and accounts the permission set is assigned to, one entry per account. Null unless `include_assignments` is true.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"principal_type": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The type of principal: USER or GROUP",
+						},
+						"principal": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The username or group name",
+						},
+						"account_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The AWS account ID",
+						},
+					},
+				},
+			},
+			"assignment_count": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The number of entries in `assignments`. Null unless `include_assignments` is true.",
+			},
 		},
 	}
 }
@@ -94,7 +152,7 @@ func (d *PermissionSetDataSource) Read(ctx context.Context, req datasource.ReadR
 
 	permSet, err := d.client.GetPermissionSet(data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set, got error: %s", err))
+		addLookupError(&resp.Diagnostics, "permission set", "id", data.ID.ValueString(), err)
 		return
 	}
 
@@ -104,23 +162,72 @@ func (d *PermissionSetDataSource) Read(ctx context.Context, req datasource.ReadR
 		data.SessionDuration = types.StringValue(permSet.SessionDuration)
 	}
 
-	if len(permSet.ManagedPolicies) > 0 {
-		managedPoliciesList, diags := types.ListValueFrom(ctx, types.StringType, permSet.ManagedPolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
+	data.ManagedPolicies = convert.StringListOrNull(permSet.ManagedPolicies)
+	data.InlinePolicies = convert.StringMapOrNull(permSet.InlinePolicies)
+
+	data.CreatedAt, data.UpdatedAt, data.ProvisioningStatus = permissionSetStatus(permSet)
+
+	data.Assignments = nil
+	data.AssignmentCount = types.Int64Null()
+	if data.IncludeAssignments.ValueBool() {
+		assignments, err := d.client.ListPermissionSetAssignmentsFiltered(permSet.ID, "", "")
+		if err != nil {
+			addReadError(&resp.Diagnostics, "list permission set assignments", err)
 			return
 		}
-		data.ManagedPolicies = managedPoliciesList
+		data.Assignments = summarizeAssignments(assignments)
+		data.AssignmentCount = types.Int64Value(int64(len(data.Assignments)))
 	}
 
-	if len(permSet.InlinePolicies) > 0 {
-		inlinePoliciesMap, diags := types.MapValueFrom(ctx, types.StringType, permSet.InlinePolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// summarizeAssignments returns one summary per principal and account in
+// assignments, sorted. Principals are named by username or group name when
+// the backend reports them.
+func summarizeAssignments(assignments []PermissionSetAssignment) []PermissionSetAssignmentSummaryModel {
+	type entry struct{ principalType, principal, accountID string }
+	seen := make(map[entry]bool)
+	var entries []entry
+	for _, assignment := range assignments {
+		principal := assignment.PrincipalID
+		if assignment.PrincipalType == "USER" && assignment.Username != "" {
+			principal = assignment.Username
+		} else if assignment.PrincipalType == "GROUP" && assignment.GroupName != "" {
+			principal = assignment.GroupName
+		}
+
+		accountIDs := assignment.AccountIDs
+		if len(accountIDs) == 0 {
+			accountIDs = []string{assignment.AccountID}
+		}
+		for _, accountID := range accountIDs {
+			e := entry{assignment.PrincipalType, principal, accountID}
+			if !seen[e] {
+				seen[e] = true
+				entries = append(entries, e)
+			}
 		}
-		data.InlinePolicies = inlinePoliciesMap
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.principalType != b.principalType {
+			return a.principalType < b.principalType
+		}
+		if a.principal != b.principal {
+			return a.principal < b.principal
+		}
+		return a.accountID < b.accountID
+	})
+
+	summaries := make([]PermissionSetAssignmentSummaryModel, len(entries))
+	for i, e := range entries {
+		summaries[i] = PermissionSetAssignmentSummaryModel{
+			PrincipalType: types.StringValue(e.principalType),
+			Principal:     types.StringValue(e.principal),
+			AccountID:     types.StringValue(e.accountID),
+		}
+	}
+	return summaries
 }
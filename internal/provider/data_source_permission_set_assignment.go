@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &PermissionSetAssignmentDataSource{}
+
+func NewPermissionSetAssignmentDataSource() datasource.DataSource {
+	return &PermissionSetAssignmentDataSource{}
+}
+
+type PermissionSetAssignmentDataSource struct {
+	client *Client
+}
+
+type PermissionSetAssignmentDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	PermissionSetID   types.String `tfsdk:"permission_set_id"`
+	PermissionSetName types.String `tfsdk:"permission_set_name"`
+	PrincipalType     types.String `tfsdk:"principal_type"`
+	PrincipalID       types.String `tfsdk:"principal_id"`
+	AccountID         types.String `tfsdk:"account_id"`
+}
+
+func (d *PermissionSetAssignmentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_set_assignment"
+}
+
+func (d *PermissionSetAssignmentDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches an existing CloudKeeper permission set assignment, which grants one permission set to one user or group in one AWS account, without managing it.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The backend ID of the assignment",
+			},
+			"permission_set_id": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The ID of the assigned permission set. Exactly one of `permission_set_id` and `permission_set_name` must be set.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("permission_set_name")),
+				},
+			},
+			"permission_set_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The name of the assigned permission set",
+			},
+			"principal_type": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The type of principal (USER or GROUP)",
+				Validators: []validator.String{
+					stringvalidator.OneOf("USER", "GROUP"),
+				},
+			},
+			"principal_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The username of the user or the name of the group",
+			},
+			"account_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The AWS account ID the permission set is assigned in",
+			},
+		},
+	}
+}
+
+func (d *PermissionSetAssignmentDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *PermissionSetAssignmentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PermissionSetAssignmentDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSet := d.findPermissionSet(data, resp)
+	if permSet == nil {
+		return
+	}
+
+	principalType := data.PrincipalType.ValueString()
+	principalID := data.PrincipalID.ValueString()
+	accountID := data.AccountID.ValueString()
+
+	assignments, err := d.client.ListPermissionSetAssignmentsFiltered(permSet.ID, principalType, principalID)
+	if err != nil {
+		addReadError(&resp.Diagnostics, "list permission set assignments", err)
+		return
+	}
+
+	var matches []PermissionSetAssignment
+	for _, assignment := range assignments {
+		if assignment.AccountID == accountID {
+			matches = append(matches, assignment)
+		}
+	}
+
+	grant := fmt.Sprintf("permission set %q to %s %q in account %s", permSet.Name, principalType, principalID, accountID)
+	switch len(matches) {
+	case 0:
+		resp.Diagnostics.AddError(
+			"Permission Set Assignment Not Found",
+			fmt.Sprintf("No assignment grants %s. Check the principal and account, and that the assignment exists in this Prism subdomain.", grant),
+		)
+		return
+	case 1:
+	default:
+		var lines []string
+		for _, assignment := range matches {
+			lines = append(lines, "  - "+describeAssignment(assignment))
+		}
+		resp.Diagnostics.AddError(
+			"Multiple Permission Set Assignments Found",
+			fmt.Sprintf("%d assignments grant %s:\n\n%s\n\nRemove the duplicates in Prism so that the grant is held by one assignment.",
+				len(matches), grant, strings.Join(lines, "\n")),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(matches[0].ID)
+	data.PermissionSetID = types.StringValue(permSet.ID)
+	data.PermissionSetName = types.StringValue(permSet.Name)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// findPermissionSet returns the permission set named by permission_set_id
+// or permission_set_name, or nil after adding an error if there's no single
+// such permission set.
+func (d *PermissionSetAssignmentDataSource) findPermissionSet(data PermissionSetAssignmentDataSourceModel, resp *datasource.ReadResponse) *PermissionSet {
+	if !data.PermissionSetID.IsNull() {
+		permSetID := data.PermissionSetID.ValueString()
+		permSet, err := d.client.GetPermissionSet(permSetID)
+		if err != nil {
+			addLookupError(&resp.Diagnostics, "permission set", "permission_set_id", permSetID, err)
+			return nil
+		}
+		return permSet
+	}
+
+	name := data.PermissionSetName.ValueString()
+	permSets, err := d.client.ListPermissionSets()
+	if err != nil {
+		addReadError(&resp.Diagnostics, "list permission sets", err)
+		return nil
+	}
+
+	var matches []PermissionSet
+	for _, permSet := range permSets {
+		if permSet.Name == name {
+			matches = append(matches, permSet)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("permission_set_name"),
+			"Permission Set Not Found",
+			fmt.Sprintf("No permission set is named %q. Check the name and that the permission set exists in this Prism subdomain.", name),
+		)
+		return nil
+	case 1:
+		return &matches[0]
+	default:
+		var ids []string
+		for _, permSet := range matches {
+			ids = append(ids, permSet.ID)
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root("permission_set_name"),
+			"Multiple Permission Sets Found",
+			fmt.Sprintf("%d permission sets are named %q (%s). Use permission_set_id to choose one.", len(matches), name, strings.Join(ids, ", ")),
+		)
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testAssignmentDataSourceConfig(permSetID, permSetName, principalType, principalID, accountID string) *PermissionSetAssignmentDataSourceModel {
+	config := &PermissionSetAssignmentDataSourceModel{
+		ID:                types.StringNull(),
+		PermissionSetID:   types.StringNull(),
+		PermissionSetName: types.StringNull(),
+		PrincipalType:     types.StringValue(principalType),
+		PrincipalID:       types.StringValue(principalID),
+		AccountID:         types.StringValue(accountID),
+	}
+	if permSetID != "" {
+		config.PermissionSetID = types.StringValue(permSetID)
+	}
+	if permSetName != "" {
+		config.PermissionSetName = types.StringValue(permSetName)
+	}
+	return config
+}
+
+func newAssignmentDataSourceFake() *fakePrism {
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	fake.permSets["ps-2"] = &PermissionSet{ID: "ps-2", Name: "Admin"}
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "alice", Username: "alice", AccountID: "111111111111"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "alice", Username: "alice", AccountID: "222222222222"}
+	fake.assignments["asgn-3"] = &PermissionSetAssignment{ID: "asgn-3", PermissionSetID: "ps-2", PrincipalType: "GROUP", PrincipalID: "developers", GroupName: "developers", AccountID: "111111111111"}
+	return fake
+}
+
+func TestPermissionSetAssignmentDataSource_Read(t *testing.T) {
+	tests := map[string]struct {
+		config *PermissionSetAssignmentDataSourceModel
+		wantID string
+	}{
+		"by permission set id":   {config: testAssignmentDataSourceConfig("ps-1", "", "USER", "alice", "222222222222"), wantID: "asgn-2"},
+		"by permission set name": {config: testAssignmentDataSourceConfig("", "Admin", "GROUP", "developers", "111111111111"), wantID: "asgn-3"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newAssignmentDataSourceFake())
+			state, diags := readDataSource(t, NewPermissionSetAssignmentDataSource(), client, tt.config)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			var got PermissionSetAssignmentDataSourceModel
+			state.Get(context.Background(), &got)
+			if got.ID.ValueString() != tt.wantID {
+				t.Errorf("expected id %s, got %s", tt.wantID, got.ID)
+			}
+			if got.PermissionSetID.IsNull() || got.PermissionSetName.IsNull() {
+				t.Errorf("expected both permission set attributes to be set, got id %s and name %s", got.PermissionSetID, got.PermissionSetName)
+			}
+		})
+	}
+}
+
+func TestPermissionSetAssignmentDataSource_NotFound(t *testing.T) {
+	tests := map[string]struct {
+		config  *PermissionSetAssignmentDataSourceModel
+		summary string
+	}{
+		"assignment":           {config: testAssignmentDataSourceConfig("ps-1", "", "USER", "alice", "333333333333"), summary: "Permission Set Assignment Not Found"},
+		"other principal type": {config: testAssignmentDataSourceConfig("ps-1", "", "GROUP", "alice", "111111111111"), summary: "Permission Set Assignment Not Found"},
+		"permission set id":    {config: testAssignmentDataSourceConfig("ps-9", "", "USER", "alice", "111111111111"), summary: "Permission Set Not Found"},
+		"permission set name":  {config: testAssignmentDataSourceConfig("", "PowerUser", "USER", "alice", "111111111111"), summary: "Permission Set Not Found"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, newAssignmentDataSourceFake())
+			_, diags := readDataSource(t, NewPermissionSetAssignmentDataSource(), client, tt.config)
+			if !hasDiagnostic(diags, tt.summary) {
+				t.Fatalf("expected %q, got %v", tt.summary, diags)
+			}
+		})
+	}
+}
+
+func TestPermissionSetAssignmentDataSource_Ambiguous(t *testing.T) {
+	fake := newAssignmentDataSourceFake()
+	fake.assignments["asgn-4"] = &PermissionSetAssignment{ID: "asgn-4", PermissionSetID: "ps-1", PrincipalType: "USER", PrincipalID: "alice", Username: "alice", AccountID: "111111111111"}
+	fake.permSets["ps-3"] = &PermissionSet{ID: "ps-3", Name: "Admin"}
+
+	client := newTestClient(t, fake)
+	_, diags := readDataSource(t, NewPermissionSetAssignmentDataSource(), client, testAssignmentDataSourceConfig("ps-1", "", "USER", "alice", "111111111111"))
+	if !hasDiagnostic(diags, "Multiple Permission Set Assignments Found") {
+		t.Fatalf("expected a multiple assignments error, got %v", diags)
+	}
+	if detail := diagnosticDetail(diags, "Multiple Permission Set Assignments Found"); !strings.Contains(detail, "asgn-1") || !strings.Contains(detail, "asgn-4") {
+		t.Errorf("expected both assignments to be listed, got %q", detail)
+	}
+
+	_, diags = readDataSource(t, NewPermissionSetAssignmentDataSource(), client, testAssignmentDataSourceConfig("", "Admin", "GROUP", "developers", "111111111111"))
+	if detail := diagnosticDetail(diags, "Multiple Permission Sets Found"); !strings.Contains(detail, "ps-2, ps-3") {
+		t.Errorf("expected both permission sets to be listed, got %v", diags)
+	}
+}
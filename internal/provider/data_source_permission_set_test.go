@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testPermissionSetDataSourceConfig(id string, includeAssignments types.Bool) *PermissionSetDataSourceModel {
+	return &PermissionSetDataSourceModel{
+		ID:                 types.StringValue(id),
+		Name:               types.StringNull(),
+		Description:        types.StringNull(),
+		SessionDuration:    types.StringNull(),
+		ManagedPolicies:    types.ListNull(types.StringType),
+		InlinePolicies:     types.MapNull(types.StringType),
+		CreatedAt:          types.StringNull(),
+		UpdatedAt:          types.StringNull(),
+		ProvisioningStatus: types.StringNull(),
+		IncludeAssignments: includeAssignments,
+		AssignmentCount:    types.Int64Null(),
+	}
+}
+
+func TestPermissionSetDataSource_IncludeAssignments(t *testing.T) {
+	fake := newAssignmentDataSourceFake()
+	fake.assignments["asgn-4"] = &PermissionSetAssignment{ID: "asgn-4", PermissionSetID: "ps-1", PrincipalType: "GROUP", PrincipalID: "group-1", GroupName: "auditors", AccountID: "111111111111"}
+	handler, count := countRequests(fake)
+	client := newTestClient(t, handler)
+
+	state, diags := readDataSource(t, NewPermissionSetDataSource(), client, testPermissionSetDataSourceConfig("ps-1", types.BoolValue(true)))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got PermissionSetDataSourceModel
+	state.Get(context.Background(), &got)
+	want := []PermissionSetAssignmentSummaryModel{
+		{PrincipalType: types.StringValue("GROUP"), Principal: types.StringValue("auditors"), AccountID: types.StringValue("111111111111")},
+		{PrincipalType: types.StringValue("USER"), Principal: types.StringValue("alice"), AccountID: types.StringValue("111111111111")},
+		{PrincipalType: types.StringValue("USER"), Principal: types.StringValue("alice"), AccountID: types.StringValue("222222222222")},
+	}
+	if len(got.Assignments) != len(want) {
+		t.Fatalf("expected %d assignments, got %v", len(want), got.Assignments)
+	}
+	for i := range want {
+		if got.Assignments[i] != want[i] {
+			t.Errorf("assignments[%d]: expected %v, got %v", i, want[i], got.Assignments[i])
+		}
+	}
+	if got.AssignmentCount.ValueInt64() != 3 {
+		t.Errorf("expected assignment_count 3, got %s", got.AssignmentCount)
+	}
+	if got := count("GET /permission-set-assignments"); got != 1 {
+		t.Errorf("expected 1 assignment list call, got %d", got)
+	}
+}
+
+func TestPermissionSetDataSource_AssignmentsOffByDefault(t *testing.T) {
+	for name, include := range map[string]types.Bool{"unset": types.BoolNull(), "false": types.BoolValue(false)} {
+		t.Run(name, func(t *testing.T) {
+			handler, count := countRequests(newAssignmentDataSourceFake())
+			client := newTestClient(t, handler)
+
+			state, diags := readDataSource(t, NewPermissionSetDataSource(), client, testPermissionSetDataSourceConfig("ps-1", include))
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			var got PermissionSetDataSourceModel
+			state.Get(context.Background(), &got)
+			if got.Assignments != nil || !got.AssignmentCount.IsNull() {
+				t.Errorf("expected no assignments, got %v (count %s)", got.Assignments, got.AssignmentCount)
+			}
+			if got.Name.ValueString() != "ReadOnly" {
+				t.Errorf("expected name ReadOnly, got %s", got.Name)
+			}
+			if got := count("GET /permission-set-assignments"); got != 0 {
+				t.Errorf("expected no assignment list call, got %d", got)
+			}
+		})
+	}
+}
+
+func TestSummarizeAssignments(t *testing.T) {
+	got := summarizeAssignments([]PermissionSetAssignment{
+		{PrincipalType: "USER", PrincipalID: "user-1", AccountIDs: []string{"222222222222", "111111111111"}},
+		{PrincipalType: "USER", PrincipalID: "user-1", AccountID: "111111111111"},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected duplicates to collapse into 2 entries, got %v", got)
+	}
+	if got[0].Principal.ValueString() != "user-1" || got[0].AccountID.ValueString() != "111111111111" {
+		t.Errorf("expected the principal ID when no username is reported, got %v", got[0])
+	}
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &PermissionSetsDataSource{}
+
+func NewPermissionSetsDataSource() datasource.DataSource {
+	return &PermissionSetsDataSource{}
+}
+
+type PermissionSetsDataSource struct {
+	client *Client
+}
+
+type PermissionSetsDataSourceModel struct {
+	NameRegex      types.String                           `tfsdk:"name_regex"`
+	PermissionSets []PermissionSetsDataItemModel          `tfsdk:"permission_sets"`
+	ByName         map[string]PermissionSetsDataItemModel `tfsdk:"by_name"`
+}
+
+type PermissionSetsDataItemModel struct {
+	ID              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Description     types.String `tfsdk:"description"`
+	SessionDuration types.String `tfsdk:"session_duration"`
+	ManagedPolicies types.List   `tfsdk:"managed_policies"`
+}
+
+func (d *PermissionSetsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_sets"
+}
+
+func (d *PermissionSetsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetches all CloudKeeper permission sets, optionally filtered by name.",
+
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "A regular expression matched against permission set names. Only matching permission sets are returned. The filter is applied client-side.",
+			},
+			"permission_sets": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The permission sets matching the filter",
+				NestedObject:        permissionSetsDataItemSchema(),
+			},
+			"by_name": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The permission sets matching the filter keyed by name, e.g. for `for_each`. Names shared by several permission sets are left out, with a warning.",
+				NestedObject:        permissionSetsDataItemSchema(),
+			},
+		},
+	}
+}
+
+// permissionSetsDataItemSchema is the schema of one permission set in
+// permission_sets and by_name.
+func permissionSetsDataItemSchema() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The unique identifier for the permission set",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The name of the permission set",
+			},
+			"description": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "A description of the permission set",
+			},
+			"session_duration": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The session duration in ISO 8601 format",
+			},
+			"managed_policies": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "List of AWS managed policy ARNs",
+			},
+		},
+	}
+}
+
+func (d *PermissionSetsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *PermissionSetsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PermissionSetsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Compile the filter before calling the API so a bad pattern fails fast
+	var nameRegex *regexp.Regexp
+	if !data.NameRegex.IsNull() && data.NameRegex.ValueString() != "" {
+		re, err := regexp.Compile(data.NameRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name_regex"),
+				"Invalid Name Regex",
+				fmt.Sprintf("Unable to compile name_regex %q: %s", data.NameRegex.ValueString(), err),
+			)
+			return
+		}
+		nameRegex = re
+	}
+
+	permSets, err := d.client.ListPermissionSets()
+	if err != nil {
+		addReadError(&resp.Diagnostics, "list permission sets", err)
+		return
+	}
+
+	// Always write a (possibly empty) list and map so that length() works on
+	// no matches
+	data.PermissionSets = []PermissionSetsDataItemModel{}
+	data.ByName = map[string]PermissionSetsDataItemModel{}
+	nameCounts := map[string]int{}
+	for _, permSet := range filterPermissionSetsByName(permSets, nameRegex) {
+		item := PermissionSetsDataItemModel{
+			ID:              types.StringValue(permSet.ID),
+			Name:            types.StringValue(permSet.Name),
+			Description:     types.StringValue(permSet.Description),
+			SessionDuration: types.StringValue(permSet.SessionDuration),
+			ManagedPolicies: convert.StringListOrNull(permSet.ManagedPolicies),
+		}
+		data.PermissionSets = append(data.PermissionSets, item)
+		data.ByName[permSet.Name] = item
+		nameCounts[permSet.Name]++
+	}
+
+	// Picking one of several permission sets with the same name would make
+	// by_name change with the API's list order
+	var duplicated []string
+	for name, count := range nameCounts {
+		if count > 1 {
+			duplicated = append(duplicated, fmt.Sprintf("%q", name))
+			delete(data.ByName, name)
+		}
+	}
+	if len(duplicated) > 0 {
+		sort.Strings(duplicated)
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("by_name"),
+			"Duplicate Permission Set Names",
+			fmt.Sprintf("Several permission sets are named %s, so by_name leaves them out. Find them in permission_sets by ID instead.", strings.Join(duplicated, ", ")),
+		)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// filterPermissionSetsByName returns the permission sets whose name matches re.
+// A nil re matches everything.
+func filterPermissionSetsByName(permSets []PermissionSet, re *regexp.Regexp) []PermissionSet {
+	if re == nil {
+		return permSets
+	}
+
+	var filtered []PermissionSet
+	for _, permSet := range permSets {
+		if re.MatchString(permSet.Name) {
+			filtered = append(filtered, permSet)
+		}
+	}
+	return filtered
+}
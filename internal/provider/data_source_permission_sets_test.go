@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFilterPermissionSetsByName(t *testing.T) {
+	permSets := []PermissionSet{
+		{ID: "ps-1", Name: "billing-readonly"},
+		{ID: "ps-2", Name: "billing-admin"},
+		{ID: "ps-3", Name: "network-readonly"},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "suffix", pattern: "-readonly$", want: []string{"ps-1", "ps-3"}},
+		{name: "prefix", pattern: "^billing-", want: []string{"ps-1", "ps-2"}},
+		{name: "no match", pattern: "^security-", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterPermissionSetsByName(permSets, regexp.MustCompile(tt.pattern))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d permission sets, got %d", len(tt.want), len(got))
+			}
+			for i, ps := range got {
+				if ps.ID != tt.want[i] {
+					t.Errorf("result[%d]: expected %s, got %s", i, tt.want[i], ps.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterPermissionSetsByName_NilRegexReturnsAll(t *testing.T) {
+	permSets := []PermissionSet{{ID: "ps-1"}, {ID: "ps-2"}}
+	if got := filterPermissionSetsByName(permSets, nil); len(got) != 2 {
+		t.Errorf("expected all permission sets, got %d", len(got))
+	}
+}
+
+func TestPermissionSetsDataSource_Read(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, []PermissionSet{
+			{ID: "ps-1", Name: "billing-readonly", ManagedPolicies: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"}},
+			{ID: "ps-2", Name: "billing-admin"},
+		})
+	}))
+
+	state, diags := readDataSource(t, NewPermissionSetsDataSource(), client, &PermissionSetsDataSourceModel{
+		NameRegex: types.StringValue("-readonly$"),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got PermissionSetsDataSourceModel
+	state.Get(context.Background(), &got)
+	if len(got.PermissionSets) != 1 || got.PermissionSets[0].ID.ValueString() != "ps-1" {
+		t.Fatalf("expected only ps-1, got %+v", got.PermissionSets)
+	}
+	if got.PermissionSets[0].ManagedPolicies.IsNull() {
+		t.Error("expected managed_policies to be populated")
+	}
+}
+
+func TestPermissionSetsDataSource_ReadEmpty(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, []PermissionSet{})
+	}))
+
+	state, diags := readDataSource(t, NewPermissionSetsDataSource(), client, &PermissionSetsDataSourceModel{
+		NameRegex: types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got PermissionSetsDataSourceModel
+	state.Get(context.Background(), &got)
+	if got.PermissionSets == nil || len(got.PermissionSets) != 0 {
+		t.Errorf("expected an empty (non-null) list, got %+v", got.PermissionSets)
+	}
+	if got.ByName == nil || len(got.ByName) != 0 {
+		t.Errorf("expected an empty (non-null) by_name, got %+v", got.ByName)
+	}
+}
+
+func TestPermissionSetsDataSource_InvalidRegex(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no API call expected for an invalid regex")
+	}))
+
+	_, diags := readDataSource(t, NewPermissionSetsDataSource(), client, &PermissionSetsDataSourceModel{
+		NameRegex: types.StringValue("(unclosed"),
+	})
+	if !diags.HasError() {
+		t.Fatal("expected an error for an invalid regex")
+	}
+}
+
+func TestPermissionSetsDataSource_ReadByName(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, []PermissionSet{
+			{ID: "ps-1", Name: "billing-readonly"},
+			{ID: "ps-2", Name: "billing-admin"},
+			{ID: "ps-3", Name: "network-readonly"},
+			{ID: "ps-4", Name: "network-readonly"},
+		})
+	}))
+
+	state, diags := readDataSource(t, NewPermissionSetsDataSource(), client, &PermissionSetsDataSourceModel{
+		NameRegex: types.StringNull(),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if detail := diagnosticDetail(diags, "Duplicate Permission Set Names"); !strings.Contains(detail, `"network-readonly"`) {
+		t.Errorf("expected a warning naming network-readonly, got %q", detail)
+	}
+
+	var got PermissionSetsDataSourceModel
+	state.Get(context.Background(), &got)
+	if len(got.PermissionSets) != 4 {
+		t.Errorf("expected every permission set in permission_sets, got %d", len(got.PermissionSets))
+	}
+	var keys []string
+	for name, item := range got.ByName {
+		keys = append(keys, name)
+		if item.Name.ValueString() != name {
+			t.Errorf("expected by_name[%q] to be that permission set, got %q", name, item.Name.ValueString())
+		}
+	}
+	sort.Strings(keys)
+	if want := []string{"billing-admin", "billing-readonly"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected by_name keys %v, got %v", want, keys)
+	}
+	if id := got.ByName["billing-admin"].ID.ValueString(); id != "ps-2" {
+		t.Errorf("expected billing-admin to be ps-2, got %q", id)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -27,6 +28,9 @@ type UserDataSourceModel struct {
 	LastName   types.String `tfsdk:"last_name"`
 	Enabled    types.Bool   `tfsdk:"enabled"`
 	Attributes types.Map    `tfsdk:"attributes"`
+
+	CreatedAt   types.String `tfsdk:"created_at"`
+	LastLoginAt types.String `tfsdk:"last_login_at"`
 }
 
 func (d *UserDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -67,6 +71,14 @@ func (d *UserDataSource) Schema(ctx context.Context, req datasource.SchemaReques
 				Computed:            true,
 				MarkdownDescription: "Custom attributes for the user",
 			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the user was created, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+			},
+			"last_login_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the user last logged in, in RFC 3339 format. Null if the user has never logged in or the Prism backend doesn't report it.",
+			},
 		},
 	}
 }
@@ -98,7 +110,7 @@ func (d *UserDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 
 	user, err := d.client.GetUser(data.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read user, got error: %s", err))
+		addLookupError(&resp.Diagnostics, "user", "id", data.ID.ValueString(), err)
 		return
 	}
 
@@ -112,21 +124,8 @@ func (d *UserDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	}
 	data.Enabled = types.BoolValue(user.Enabled)
 
-	if len(user.Attributes) > 0 {
-		// Convert map[string][]string from API to map[string]string for Terraform
-		tfAttributesMap := make(map[string]string)
-		for k, v := range user.Attributes {
-			if len(v) > 0 {
-				tfAttributesMap[k] = v[0] // Take first value
-			}
-		}
-		attributesMap, diags := types.MapValueFrom(ctx, types.StringType, tfAttributesMap)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.Attributes = attributesMap
-	}
+	data.Attributes = convert.StringMapFromMultiValue(user.Attributes)
+	data.CreatedAt, data.LastLoginAt = userTimestamps(user)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// userFixture serves GET /users/{id} with user, a raw API payload.
+func userFixture(user map[string]interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, user)
+	})
+}
+
+func TestUserDataSource_Timestamps(t *testing.T) {
+	tests := map[string]struct {
+		user                         map[string]interface{}
+		wantCreatedAt, wantLastLogin string
+	}{
+		"present": {
+			user:          map[string]interface{}{"id": "user-1", "username": "alice", "email": "alice@example.com", "createdTimestamp": 1700000000000, "lastLoginTimestamp": 1760000000123},
+			wantCreatedAt: "2023-11-14T22:13:20Z",
+			wantLastLogin: "2025-10-09T08:53:20Z",
+		},
+		"never logged in": {
+			user:          map[string]interface{}{"id": "user-1", "username": "alice", "email": "alice@example.com", "createdTimestamp": 1700000000000},
+			wantCreatedAt: "2023-11-14T22:13:20Z",
+		},
+		"missing": {
+			user: map[string]interface{}{"id": "user-1", "username": "alice", "email": "alice@example.com"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, userFixture(tt.user))
+			state, diags := readDataSource(t, NewUserDataSource(), client, &UserDataSourceModel{
+				ID:         types.StringValue("user-1"),
+				Attributes: types.MapNull(types.StringType),
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			var got UserDataSourceModel
+			state.Get(context.Background(), &got)
+			if got.CreatedAt.ValueString() != tt.wantCreatedAt || got.CreatedAt.IsNull() != (tt.wantCreatedAt == "") {
+				t.Errorf("expected created_at %q, got %s", tt.wantCreatedAt, got.CreatedAt)
+			}
+			if got.LastLoginAt.ValueString() != tt.wantLastLogin || got.LastLoginAt.IsNull() != (tt.wantLastLogin == "") {
+				t.Errorf("expected last_login_at %q, got %s", tt.wantLastLogin, got.LastLoginAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &VersionDataSource{}
+
+func NewVersionDataSource() datasource.DataSource {
+	return &VersionDataSource{}
+}
+
+type VersionDataSource struct {
+	client *Client
+}
+
+type VersionDataSourceModel struct {
+	ProviderVersion types.String `tfsdk:"provider_version"`
+	BackendVersion  types.String `tfsdk:"backend_version"`
+	Capabilities    types.Map    `tfsdk:"capabilities"`
+}
+
+func (d *VersionDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_version"
+}
+
+func (d *VersionDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reports the provider version and the Prism backend version, so that modules can depend on what the backend supports.",
+
+		Attributes: map[string]schema.Attribute{
+			"provider_version": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The version of this provider",
+			},
+			"backend_version": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The version the Prism backend reports, e.g. `2.4` or `2.4.1`. Null if the backend doesn't report a version.",
+			},
+			"capabilities": schema.MapAttribute{
+				ElementType:         types.BoolType,
+				Computed:            true,
+				MarkdownDescription: "Whether the backend supports each attribute that needs a minimum backend version, keyed by attribute name, e.g. `owner_emails`. Null if the backend doesn't report a version.",
+			},
+		},
+	}
+}
+
+func (d *VersionDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *VersionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data VersionDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The client probes the version before its first request; this probes
+	// it if no request has been made yet, and reuses the result otherwise
+	d.client.ProbeBackendVersion(ctx)
+
+	data.ProviderVersion = types.StringValue(d.client.ProviderVersion)
+	data.BackendVersion = types.StringNull()
+	data.Capabilities = types.MapNull(types.BoolType)
+	if version, known := d.client.reportedVersion(); known {
+		capabilities := make(map[string]bool, len(backendFeatures))
+		for _, feature := range backendFeatures {
+			capabilities[feature.Attribute] = version.atLeast(feature.MinVersion)
+		}
+
+		var diags diag.Diagnostics
+		data.BackendVersion = types.StringValue(version.String())
+		data.Capabilities, diags = types.MapValueFrom(ctx, types.BoolType, capabilities)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestVersionDataSource_Read(t *testing.T) {
+	tests := map[string]struct {
+		version          string
+		wantVersion      string
+		wantOwnerEmails  bool
+		wantCapabilities bool
+	}{
+		"older backend":   {version: "2.2", wantVersion: "2.2", wantCapabilities: true},
+		"current backend": {version: "v2.4.1-rc.1", wantVersion: "2.4.1", wantCapabilities: true, wantOwnerEmails: true},
+		"no version":      {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			client := newTestClient(t, versionServer(tt.version, http.StatusNotFound, newFakePrism(), &calls))
+			client.ProviderVersion = "1.2.3"
+			client.ProbeBackendVersion(context.Background())
+
+			state, diags := readDataSource(t, NewVersionDataSource(), client, &VersionDataSourceModel{
+				ProviderVersion: types.StringNull(),
+				BackendVersion:  types.StringNull(),
+				Capabilities:    types.MapNull(types.BoolType),
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			var got VersionDataSourceModel
+			state.Get(context.Background(), &got)
+			if got.ProviderVersion.ValueString() != "1.2.3" {
+				t.Errorf("expected provider_version 1.2.3, got %s", got.ProviderVersion)
+			}
+			if tt.wantVersion == "" && !got.BackendVersion.IsNull() {
+				t.Errorf("expected a null backend_version, got %s", got.BackendVersion)
+			} else if got.BackendVersion.ValueString() != tt.wantVersion {
+				t.Errorf("expected backend_version %s, got %s", tt.wantVersion, got.BackendVersion)
+			}
+			if got.Capabilities.IsNull() == tt.wantCapabilities {
+				t.Fatalf("expected capabilities: %t, got %s", tt.wantCapabilities, got.Capabilities)
+			}
+			if tt.wantCapabilities {
+				ownerEmails, ok := got.Capabilities.Elements()["owner_emails"].(types.Bool)
+				if !ok || ownerEmails.ValueBool() != tt.wantOwnerEmails {
+					t.Errorf("expected owner_emails capability %t, got %s", tt.wantOwnerEmails, got.Capabilities)
+				}
+			}
+
+			// The data source reuses the version probed before it read
+			if calls := atomic.LoadInt32(&calls); calls != 1 {
+				t.Errorf("expected the version to be probed once, got %d calls", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// emailsValidator checks each element of a list or set of strings, such as
+// owner or approver emails, is a bare email address, and that no address is
+// given twice. Addresses are compared lowercased, since the backend treats
+// "Alice@example.com" and "alice@example.com" as the same owner.
+type emailsValidator struct{}
+
+var (
+	_ validator.List = emailsValidator{}
+	_ validator.Set  = emailsValidator{}
+)
+
+func (v emailsValidator) Description(ctx context.Context) string {
+	return "each value must be a distinct email address such as \"alice@example.com\""
+}
+
+func (v emailsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v emailsValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	elements := req.ConfigValue.Elements()
+	paths := make([]path.Path, len(elements))
+	for i := range elements {
+		paths[i] = req.Path.AtListIndex(i)
+	}
+	resp.Diagnostics.Append(validateEmails(elements, paths, true)...)
+}
+
+func (v emailsValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	elements := req.ConfigValue.Elements()
+	paths := make([]path.Path, len(elements))
+	for i, element := range elements {
+		paths[i] = req.Path.AtSetValue(element)
+	}
+	resp.Diagnostics.Append(validateEmails(elements, paths, false)...)
+}
+
+// validateEmails reports an error at paths[i] for each element that isn't
+// an email address or repeats an earlier one. Unknown elements are skipped.
+// Messages name list elements by index; set elements have no order the user
+// wrote, and their path already names the value.
+func validateEmails(elements []attr.Value, paths []path.Path, indexed bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	seen := make(map[string]int, len(elements))
+	for i, element := range elements {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		email := value.ValueString()
+
+		subject := fmt.Sprintf("%q", email)
+		if indexed {
+			subject = fmt.Sprintf("Element %d, %q,", i, email)
+		}
+
+		if !isEmailAddress(email) {
+			diags.AddAttributeError(
+				paths[i],
+				"Invalid Email Address",
+				fmt.Sprintf("%s is not an email address. Use a bare address such as \"alice@example.com\", without a display name or surrounding spaces.", subject),
+			)
+			continue
+		}
+
+		normalized := normalizeEmail(email)
+		if first, ok := seen[normalized]; ok {
+			repeated := fmt.Sprintf("%q", elements[first].(types.String).ValueString())
+			if indexed {
+				repeated = fmt.Sprintf("element %d", first)
+			}
+			diags.AddAttributeError(
+				paths[i],
+				"Duplicate Email Address",
+				fmt.Sprintf("%s repeats %s. Email addresses are compared case-insensitively; remove one of them.", subject, repeated),
+			)
+			continue
+		}
+		seen[normalized] = i
+	}
+
+	return diags
+}
+
+// isEmailAddress reports whether s is a bare email address, e.g.
+// "alice@example.com" but not "Alice <alice@example.com>".
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// normalizeEmail returns the form of email used to compare addresses.
+func normalizeEmail(email string) string {
+	return strings.ToLower(email)
+}
+
+// normalizeEmails returns emails as normalizeEmail does each.
+func normalizeEmails(emails []string) []string {
+	normalized := make([]string, len(emails))
+	for i, email := range emails {
+		normalized[i] = normalizeEmail(email)
+	}
+	return normalized
+}
+
+// ownerEmailsList returns the owner_emails list to store for the backend's
+// emails, keeping current when it holds the same addresses in the same
+// order, differing only in case, so that a configured "Alice@example.com"
+// doesn't show a diff when the backend stores it lowercased.
+func ownerEmailsList(current types.List, remote []string) types.List {
+	if !current.IsNull() && !current.IsUnknown() && sameEmails(current.Elements(), remote, true) {
+		return current
+	}
+	return convert.KeepEmptyList(current, convert.StringListOrNull(remote))
+}
+
+// ownerEmailsSet is ownerEmailsList for a set, which ignores order.
+func ownerEmailsSet(current types.Set, remote []string) types.Set {
+	if !current.IsNull() && !current.IsUnknown() && sameEmails(current.Elements(), remote, false) {
+		return current
+	}
+	return convert.StringSetOrEmpty(remote)
+}
+
+// sameEmails reports whether elements and emails hold the same addresses,
+// compared as normalizeEmail does, and in the same order if ordered is set.
+func sameEmails(elements []attr.Value, emails []string, ordered bool) bool {
+	if len(elements) != len(emails) {
+		return false
+	}
+
+	a := make([]string, len(elements))
+	for i, element := range elements {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			return false
+		}
+		a[i] = normalizeEmail(value.ValueString())
+	}
+	b := normalizeEmails(emails)
+	if !ordered {
+		slices.Sort(a)
+		slices.Sort(b)
+	}
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEmailsValidator_List(t *testing.T) {
+	tests := []struct {
+		name   string
+		emails []attr.Value
+		// wantErrors maps the list index of each expected error to its summary
+		wantErrors map[int]string
+	}{
+		{
+			name:   "valid",
+			emails: []attr.Value{types.StringValue("alice@example.com"), types.StringValue("bob.smith+jit@ops.example.co.uk")},
+		},
+		{
+			name:       "missing domain",
+			emails:     []attr.Value{types.StringValue("alice@example.com"), types.StringValue("bob")},
+			wantErrors: map[int]string{1: "Invalid Email Address"},
+		},
+		{
+			name:       "display name",
+			emails:     []attr.Value{types.StringValue("Alice <alice@example.com>")},
+			wantErrors: map[int]string{0: "Invalid Email Address"},
+		},
+		{
+			name:       "surrounding spaces",
+			emails:     []attr.Value{types.StringValue(" alice@example.com")},
+			wantErrors: map[int]string{0: "Invalid Email Address"},
+		},
+		{
+			name:       "empty",
+			emails:     []attr.Value{types.StringValue("")},
+			wantErrors: map[int]string{0: "Invalid Email Address"},
+		},
+		{
+			name:       "duplicate",
+			emails:     []attr.Value{types.StringValue("alice@example.com"), types.StringValue("bob@example.com"), types.StringValue("alice@example.com")},
+			wantErrors: map[int]string{2: "Duplicate Email Address"},
+		},
+		{
+			name:       "duplicate in another case",
+			emails:     []attr.Value{types.StringValue("alice@example.com"), types.StringValue("Alice@Example.com")},
+			wantErrors: map[int]string{1: "Duplicate Email Address"},
+		},
+		{
+			name:   "unknown element",
+			emails: []attr.Value{types.StringValue("alice@example.com"), types.StringUnknown()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.ListRequest{
+				Path:        path.Root("owner_emails"),
+				ConfigValue: types.ListValueMust(types.StringType, tt.emails),
+			}
+			var resp validator.ListResponse
+			emailsValidator{}.ValidateList(context.Background(), req, &resp)
+
+			if got := resp.Diagnostics.ErrorsCount(); got != len(tt.wantErrors) {
+				t.Fatalf("expected %d errors, got %v", len(tt.wantErrors), resp.Diagnostics)
+			}
+			for index, summary := range tt.wantErrors {
+				want := path.Root("owner_emails").AtListIndex(index)
+				found := false
+				for _, d := range resp.Diagnostics.Errors() {
+					if withPath, ok := d.(interface{ Path() path.Path }); ok && withPath.Path().Equal(want) && d.Summary() == summary {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected %q at %s, got %v", summary, want, resp.Diagnostics)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailsValidator_Set(t *testing.T) {
+	duplicate := types.StringValue("ALICE@example.com")
+	req := validator.SetRequest{
+		Path:        path.Root("owner_emails"),
+		ConfigValue: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("alice@example.com"), duplicate}),
+	}
+	var resp validator.SetResponse
+	emailsValidator{}.ValidateSet(context.Background(), req, &resp)
+
+	if !hasDiagnostic(resp.Diagnostics, "Duplicate Email Address") || resp.Diagnostics.ErrorsCount() != 1 {
+		t.Fatalf("expected one duplicate error, got %v", resp.Diagnostics)
+	}
+	withPath, ok := resp.Diagnostics.Errors()[0].(interface{ Path() path.Path })
+	if !ok || !withPath.Path().Equal(path.Root("owner_emails").AtSetValue(duplicate)) {
+		t.Errorf("expected the error at the duplicate element, got %v", resp.Diagnostics)
+	}
+	// Set elements have no index the user wrote
+	if detail := resp.Diagnostics.Errors()[0].Detail(); strings.Contains(detail, "lement") {
+		t.Errorf("expected no element index in a set diagnostic, got %q", detail)
+	}
+}
+
+func TestOwnerEmailsList(t *testing.T) {
+	configured := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("Alice@example.com"), types.StringValue("bob@example.com")})
+
+	tests := []struct {
+		name   string
+		remote []string
+		want   types.List
+	}{
+		{"lowercased", []string{"alice@example.com", "bob@example.com"}, configured},
+		{"reordered", []string{"bob@example.com", "alice@example.com"}, types.ListValueMust(types.StringType, []attr.Value{types.StringValue("bob@example.com"), types.StringValue("alice@example.com")})},
+		{"changed", []string{"alice@example.com"}, types.ListValueMust(types.StringType, []attr.Value{types.StringValue("alice@example.com")})},
+		{"removed", nil, types.ListNull(types.StringType)},
+	}
+	for _, tt := range tests {
+		if got := ownerEmailsList(configured, tt.remote); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOwnerEmailsSet(t *testing.T) {
+	configured := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("Alice@example.com"), types.StringValue("bob@example.com")})
+
+	if got := ownerEmailsSet(configured, []string{"bob@example.com", "alice@example.com"}); !got.Equal(configured) {
+		t.Errorf("expected the configured set to be kept, got %s", got)
+	}
+	want := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("carol@example.com")})
+	if got := ownerEmailsSet(configured, []string{"carol@example.com"}); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestEmailsValidator_NullAndUnknown(t *testing.T) {
+	for name, value := range map[string]types.List{
+		"null":    types.ListNull(types.StringType),
+		"unknown": types.ListUnknown(types.StringType),
+	} {
+		var resp validator.ListResponse
+		emailsValidator{}.ValidateList(context.Background(), validator.ListRequest{Path: path.Root("owner_emails"), ConfigValue: value}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Errorf("%s: expected no errors, got %v", name, resp.Diagnostics)
+		}
+	}
+}
@@ -0,0 +1,587 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakePrism is an in-memory implementation of the Prism API endpoints used by
+// Client. Tests can inspect and mutate its state directly to simulate changes
+// made outside Terraform.
+type fakePrism struct {
+	mu sync.Mutex
+
+	nextID      int
+	users       map[string]*User // by username
+	groups      map[string]*Group
+	members     map[string][]string // group name -> usernames
+	permSets    map[string]*PermissionSet
+	assignments map[string]*PermissionSetAssignment
+	accounts    map[string]*AWSAccount // by account ID
+	realmRoles  map[string][]string    // group ID -> realm role names
+
+	// memberDeleteConflicts makes deleting a user who is still a group
+	// member fail with 409, as some backend versions do.
+	memberDeleteConflicts bool
+
+	// titleCaseAccountNames makes onboarding and updating an account store
+	// its name trimmed and title-cased, as the backend does.
+	titleCaseAccountNames bool
+}
+
+func newFakePrism() *fakePrism {
+	return &fakePrism{
+		users:       map[string]*User{},
+		groups:      map[string]*Group{},
+		members:     map[string][]string{},
+		permSets:    map[string]*PermissionSet{},
+		assignments: map[string]*PermissionSetAssignment{},
+		accounts:    map[string]*AWSAccount{},
+		realmRoles:  map[string][]string{},
+	}
+}
+
+func (f *fakePrism) newID(prefix string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%d", prefix, f.nextID)
+}
+
+// hasAssignments reports whether any assignment references the principal.
+func (f *fakePrism) hasAssignments(principalType, name string) bool {
+	for _, a := range f.assignments {
+		if a.PrincipalType == principalType && (a.Username == name || a.GroupName == name) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
+
+func (f *fakePrism) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Split the escaped path so that escaped "/" in names stays within a
+	// segment, then strip /api/v1/customers/{subdomain}
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		parts[i] = unescaped
+	}
+	if len(parts) < 5 || parts[0] != "api" || parts[1] != "v1" || parts[2] != "customers" {
+		writeAPIError(w, http.StatusNotFound, "not found")
+		return
+	}
+	parts = parts[4:]
+
+	switch parts[0] {
+	case "users":
+		f.serveUsers(w, r, parts[1:])
+	case "groups":
+		f.serveGroups(w, r, parts[1:])
+	case "permission-sets":
+		f.servePermissionSets(w, r, parts[1:])
+	case "permission-set-assignments":
+		f.serveAssignments(w, r, parts[1:])
+	case "accounts", "aws-accounts":
+		f.serveAccounts(w, r, parts)
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *fakePrism) serveUsers(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		users := []User{}
+		for _, u := range f.users {
+			users = append(users, *u)
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+		writeAPIData(w, users)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var user User
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := f.users[user.Username]; ok {
+			writeAPIError(w, http.StatusConflict, "user already exists")
+			return
+		}
+		user.ID = f.newID("user")
+		f.users[user.Username] = &user
+		writeAPIData(w, user)
+	case len(parts) == 1:
+		user, ok := f.users[parts[0]]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeAPIData(w, user)
+		case http.MethodPut:
+			var updated User
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			updated.ID = user.ID
+			f.users[parts[0]] = &updated
+			writeAPIData(w, updated)
+		case http.MethodDelete:
+			if f.hasAssignments("USER", parts[0]) {
+				writeAPIError(w, http.StatusConflict, "user has active assignments")
+				return
+			}
+			if f.memberDeleteConflicts && len(f.userGroups(parts[0])) > 0 {
+				writeAPIError(w, http.StatusConflict, "user is a member of groups")
+				return
+			}
+			delete(f.users, parts[0])
+			writeAPIData(w, nil)
+		}
+	case len(parts) == 2 && parts[1] == "groups" && r.Method == http.MethodGet:
+		if _, ok := f.users[parts[0]]; !ok {
+			writeAPIError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		groups := []Group{}
+		for _, name := range f.userGroups(parts[0]) {
+			groups = append(groups, *f.groups[name])
+		}
+		writeAPIData(w, map[string]interface{}{
+			"user":   parts[0],
+			"groups": groups,
+			"count":  len(groups),
+		})
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// userGroups returns the sorted names of the groups username is a member of.
+func (f *fakePrism) userGroups(username string) []string {
+	var names []string
+	for name, members := range f.members {
+		for _, member := range members {
+			if member == username {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakePrism) serveGroups(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		groups := []Group{}
+		for _, g := range f.groups {
+			groups = append(groups, *g)
+		}
+		sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+		writeAPIData(w, groups)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var group Group
+		if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := f.groups[group.Name]; ok {
+			writeAPIError(w, http.StatusConflict, "group already exists")
+			return
+		}
+		group.ID = f.newID("group")
+		f.groups[group.Name] = &group
+		writeAPIData(w, group)
+	case len(parts) == 2 && parts[0] == "by-id":
+		f.serveGroupByID(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "by-id" && parts[2] == "role-mappings" && r.Method == http.MethodGet:
+		f.serveGroupRoleMappings(w, parts[1])
+	case len(parts) == 1:
+		group, ok := f.groups[parts[0]]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeAPIData(w, group)
+		case http.MethodPut:
+			var updated Group
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			updated.ID = group.ID
+			f.groups[parts[0]] = &updated
+			writeAPIData(w, updated)
+		case http.MethodDelete:
+			if f.hasAssignments("GROUP", parts[0]) {
+				writeAPIError(w, http.StatusConflict, "group has active assignments")
+				return
+			}
+			delete(f.groups, parts[0])
+			delete(f.members, parts[0])
+			writeAPIData(w, nil)
+		}
+	case len(parts) == 2 && parts[1] == "members":
+		f.serveGroupMembers(w, r, parts[0], r.Method == http.MethodDelete)
+	case len(parts) == 2 && parts[1] == "members:remove" && r.Method == http.MethodPost:
+		f.serveGroupMembers(w, r, parts[0], true)
+	case len(parts) == 3 && parts[1] == "members" && r.Method == http.MethodDelete:
+		f.removeGroupMember(w, parts[0], parts[2])
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveGroupRoleMappings lists the realm roles of the group with the given
+// ID, in the backend's role-mappings shape.
+func (f *fakePrism) serveGroupRoleMappings(w http.ResponseWriter, id string) {
+	found := false
+	for _, g := range f.groups {
+		if g.ID == id {
+			found = true
+		}
+	}
+	if !found {
+		writeAPIError(w, http.StatusNotFound, "group not found")
+		return
+	}
+
+	type role struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	mappings := []role{}
+	for _, name := range f.realmRoles[id] {
+		mappings = append(mappings, role{ID: "role-" + name, Name: name})
+	}
+	writeAPIData(w, map[string]interface{}{"realmMappings": mappings})
+}
+
+// serveGroupByID updates, possibly renaming, or deletes the group with the
+// given ID.
+func (f *fakePrism) serveGroupByID(w http.ResponseWriter, r *http.Request, id string) {
+	var name string
+	for _, g := range f.groups {
+		if g.ID == id {
+			name = g.Name
+		}
+	}
+	if name == "" {
+		writeAPIError(w, http.StatusNotFound, "group not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var updated Group
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := f.groups[updated.Name]; ok && updated.Name != name {
+			writeAPIError(w, http.StatusConflict, "group already exists")
+			return
+		}
+		updated.ID = id
+		members := f.members[name]
+		delete(f.groups, name)
+		delete(f.members, name)
+		f.groups[updated.Name] = &updated
+		if members != nil {
+			f.members[updated.Name] = members
+		}
+		writeAPIData(w, updated)
+	case http.MethodDelete:
+		if f.hasAssignments("GROUP", name) {
+			writeAPIError(w, http.StatusConflict, "group has active assignments")
+			return
+		}
+		delete(f.groups, name)
+		delete(f.members, name)
+		writeAPIData(w, nil)
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveGroupMembers lists members on GET and otherwise adds or, when remove
+// is set, removes the users in the request body.
+func (f *fakePrism) serveGroupMembers(w http.ResponseWriter, r *http.Request, groupName string, remove bool) {
+	if _, ok := f.groups[groupName]; !ok {
+		writeAPIError(w, http.StatusNotFound, "group not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		type member struct {
+			Username string `json:"username"`
+		}
+		members := []member{}
+		for _, username := range f.members[groupName] {
+			members = append(members, member{Username: username})
+		}
+		writeAPIData(w, map[string]interface{}{
+			"group":   groupName,
+			"members": members,
+			"count":   len(members),
+		})
+	case http.MethodPost, http.MethodDelete:
+		var membership GroupMembership
+		if err := json.NewDecoder(r.Body).Decode(&membership); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		current := map[string]bool{}
+		for _, username := range f.members[groupName] {
+			current[username] = true
+		}
+		for _, username := range membership.Usernames {
+			if !remove {
+				if _, ok := f.users[username]; !ok {
+					writeAPIError(w, http.StatusNotFound, fmt.Sprintf("user %s not found", username))
+					return
+				}
+				current[username] = true
+			} else {
+				delete(current, username)
+			}
+		}
+		usernames := []string{}
+		for username := range current {
+			usernames = append(usernames, username)
+		}
+		sort.Strings(usernames)
+		f.members[groupName] = usernames
+		writeAPIData(w, nil)
+	}
+}
+
+func (f *fakePrism) removeGroupMember(w http.ResponseWriter, groupName, username string) {
+	if _, ok := f.groups[groupName]; !ok {
+		writeAPIError(w, http.StatusNotFound, "group not found")
+		return
+	}
+	for i, member := range f.members[groupName] {
+		if member == username {
+			f.members[groupName] = append(f.members[groupName][:i:i], f.members[groupName][i+1:]...)
+			writeAPIData(w, nil)
+			return
+		}
+	}
+	writeAPIError(w, http.StatusNotFound, fmt.Sprintf("user %s is not a member", username))
+}
+
+func (f *fakePrism) servePermissionSets(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		permSets := []PermissionSet{}
+		for _, ps := range f.permSets {
+			permSets = append(permSets, *ps)
+		}
+		sort.Slice(permSets, func(i, j int) bool { return permSets[i].ID < permSets[j].ID })
+		writeAPIData(w, permSets)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var permSet PermissionSet
+		if err := json.NewDecoder(r.Body).Decode(&permSet); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		permSet.ID = f.newID("ps")
+		f.permSets[permSet.ID] = &permSet
+		writeAPIData(w, permSet)
+	case len(parts) == 1:
+		permSet, ok := f.permSets[parts[0]]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "permission set not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeAPIData(w, permSet)
+		case http.MethodPut:
+			var updated PermissionSet
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			updated.ID = permSet.ID
+			f.permSets[parts[0]] = &updated
+			writeAPIData(w, updated)
+		case http.MethodDelete:
+			for _, a := range f.assignments {
+				if a.PermissionSetID == parts[0] {
+					writeAPIError(w, http.StatusConflict, "permission set has active assignments")
+					return
+				}
+			}
+			delete(f.permSets, parts[0])
+			writeAPIData(w, nil)
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *fakePrism) serveAssignments(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		assignments := []PermissionSetAssignment{}
+		for _, a := range f.assignments {
+			assignments = append(assignments, *a)
+		}
+		sort.Slice(assignments, func(i, j int) bool { return assignments[i].ID < assignments[j].ID })
+		writeAPIData(w, map[string]interface{}{
+			"assignments": assignments,
+			"count":       len(assignments),
+		})
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		var req PermissionSetAssignment
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if _, ok := f.permSets[req.PermissionSetID]; !ok {
+			writeAPIError(w, http.StatusNotFound, "permission set not found")
+			return
+		}
+		// One assignment per account; only the first is returned
+		var first *PermissionSetAssignment
+		for _, acctID := range req.AccountIDs {
+			a := &PermissionSetAssignment{
+				ID:              f.newID("asgn"),
+				PermissionSetID: req.PermissionSetID,
+				PrincipalType:   req.PrincipalType,
+				AccountID:       acctID,
+				Username:        req.Username,
+				GroupName:       req.GroupName,
+			}
+			f.assignments[a.ID] = a
+			if first == nil {
+				first = a
+			}
+		}
+		writeAPIData(w, first)
+	case len(parts) == 1:
+		a, ok := f.assignments[parts[0]]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "assignment not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeAPIData(w, a)
+		case http.MethodDelete:
+			delete(f.assignments, parts[0])
+			writeAPIData(w, nil)
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// accountName returns name as the fake stores it.
+func (f *fakePrism) accountName(name string) string {
+	if !f.titleCaseAccountNames {
+		return name
+	}
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+	}
+	return strings.Join(words, " ")
+}
+
+func (f *fakePrism) serveAccounts(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case parts[0] == "accounts" && len(parts) == 2 && parts[1] == "onboard" && r.Method == http.MethodPost:
+		var req struct {
+			AccountID   string   `json:"accountId"`
+			AccountName string   `json:"accountName"`
+			OwnerEmails []string `json:"ownerEmails"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		account := &AWSAccount{
+			ID:          f.newID("acct"),
+			AccountID:   req.AccountID,
+			AccountName: f.accountName(req.AccountName),
+			OwnerEmails: req.OwnerEmails,
+		}
+		f.accounts[account.AccountID] = account
+		writeAPIData(w, map[string]interface{}{"account": account})
+	case parts[0] == "aws-accounts" && len(parts) == 1 && r.Method == http.MethodGet:
+		accounts := []AWSAccount{}
+		for _, a := range f.accounts {
+			accounts = append(accounts, *a)
+		}
+		sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+		writeAPIData(w, accounts)
+	case parts[0] == "aws-accounts" && (len(parts) == 2 || len(parts) == 3 && (parts[2] == "deboard" || parts[2] == "owners")):
+		account, ok := f.accounts[parts[1]]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "account not found")
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 2:
+			writeAPIData(w, account)
+		case r.Method == http.MethodPut && len(parts) == 2:
+			var updated AWSAccount
+			if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			updated.ID = account.ID
+			updated.AccountName = f.accountName(updated.AccountName)
+			f.accounts[parts[1]] = &updated
+			writeAPIData(w, updated)
+		case r.Method == http.MethodDelete && len(parts) == 3 && parts[2] == "deboard":
+			delete(f.accounts, parts[1])
+			writeAPIData(w, nil)
+		case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "owners":
+			writeAPIData(w, map[string]interface{}{"account_id": account.AccountID, "owner_emails": account.OwnerEmails})
+		case r.Method == http.MethodPut && len(parts) == 3 && parts[2] == "owners":
+			var req struct {
+				OwnerEmails []string `json:"owner_emails"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			account.OwnerEmails = req.OwnerEmails
+			writeAPIData(w, map[string]interface{}{"account_id": account.AccountID, "owner_emails": account.OwnerEmails})
+		default:
+			writeAPIError(w, http.StatusNotFound, "not found")
+		}
+	default:
+		writeAPIError(w, http.StatusNotFound, "not found")
+	}
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// fieldMismatches collects attributes whose value read back from the API
+// after a create or update differs from the value that was sent. Several
+// resources keep planned values when the API returns empty ones, which would
+// otherwise hide a backend that silently ignores a field.
+type fieldMismatches []string
+
+func (m *fieldMismatches) compareString(attribute, sent, got string) {
+	if sent != got {
+		*m = append(*m, fmt.Sprintf("%s: sent %q, got %q", attribute, sent, got))
+	}
+}
+
+func (m *fieldMismatches) compareBool(attribute string, sent, got bool) {
+	if sent != got {
+		*m = append(*m, fmt.Sprintf("%s: sent %t, got %t", attribute, sent, got))
+	}
+}
+
+// compareStrings compares two lists ignoring order.
+func (m *fieldMismatches) compareStrings(attribute string, sent, got []string) {
+	sortedSent := append([]string(nil), sent...)
+	sortedGot := append([]string(nil), got...)
+	sort.Strings(sortedSent)
+	sort.Strings(sortedGot)
+	if strings.Join(sortedSent, "\n") != strings.Join(sortedGot, "\n") {
+		*m = append(*m, fmt.Sprintf("%s: sent %q, got %q", attribute, sortedSent, sortedGot))
+	}
+}
+
+// addWarning reports the mismatches for owner, e.g. `User "alice"`, as a
+// single warning. It does nothing when every field was applied.
+func (m fieldMismatches) addWarning(diags *diag.Diagnostics, owner string) {
+	if len(m) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s was saved, but reading it back returned values that differ from what was sent:\n\n", owner))
+	for _, mismatch := range m {
+		sb.WriteString(fmt.Sprintf("  - %s\n", mismatch))
+	}
+	sb.WriteString("\nThe backend may have ignored or rejected these fields. Check that they are supported, " +
+		"as a later plan may show them changing.")
+
+	diags.AddWarning("Fields Not Applied", sb.String())
+}
+
+// addVerifyError reports that the read-back after a create or update failed.
+// The change itself succeeded, so this is a warning.
+func addVerifyError(diags *diag.Diagnostics, owner string, err error) {
+	diags.AddWarning(
+		"Unable to Verify Fields",
+		fmt.Sprintf("%s was saved, but reading it back to check that every field was applied failed: %s", owner, err),
+	)
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &FormatAccountIDFunction{}
+
+func NewFormatAccountIDFunction() function.Function {
+	return &FormatAccountIDFunction{}
+}
+
+type FormatAccountIDFunction struct{}
+
+func (f *FormatAccountIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_account_id"
+}
+
+func (f *FormatAccountIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Format a value as a 12-digit AWS account ID",
+		MarkdownDescription: "Converts a number or string to a 12-digit AWS account ID, left-padding with zeros. " +
+			"Useful when account IDs come from CSV files or spreadsheets that drop leading zeros. " +
+			"Surrounding whitespace in strings is ignored. Fails on negative or fractional numbers, " +
+			"non-digit strings, and values longer than 12 digits.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "account_id",
+				MarkdownDescription: "The account ID as a number or string",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *FormatAccountIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value types.Dynamic
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &value))
+	if resp.Error != nil {
+		return
+	}
+
+	var (
+		formatted string
+		err       error
+	)
+	switch v := value.UnderlyingValue().(type) {
+	case types.String:
+		formatted, err = formatAccountIDString(v.ValueString())
+	case types.Number:
+		formatted, err = formatAccountIDNumber(v.ValueBigFloat())
+	default:
+		err = fmt.Errorf("expected a number or string, got %s", value.UnderlyingValue().Type(ctx))
+	}
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, err.Error()))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, formatted))
+}
+
+// formatAccountIDString left-pads a string of up to 12 digits with zeros.
+func formatAccountIDString(s string) (string, error) {
+	digits := strings.TrimSpace(s)
+	if digits == "" {
+		return "", fmt.Errorf("account ID is empty")
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("account ID %q must contain only digits", s)
+		}
+	}
+	if len(digits) > 12 {
+		return "", fmt.Errorf("account ID %q has more than 12 digits", s)
+	}
+	return strings.Repeat("0", 12-len(digits)) + digits, nil
+}
+
+// formatAccountIDNumber formats a non-negative integer below 10^12 as a
+// zero-padded 12-digit account ID.
+func formatAccountIDNumber(n *big.Float) (string, error) {
+	if n == nil || n.IsInf() {
+		return "", fmt.Errorf("account ID must be a finite number")
+	}
+	if !n.IsInt() {
+		return "", fmt.Errorf("account ID %s must be a whole number", n.Text('g', -1))
+	}
+	if n.Sign() < 0 {
+		return "", fmt.Errorf("account ID %s must not be negative", n.Text('g', -1))
+	}
+
+	i, _ := n.Int(nil)
+	return formatAccountIDString(i.String())
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func runFormatAccountID(value attr.Value) (string, *function.FuncError) {
+	req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.DynamicValue(value)})}
+	resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+	NewFormatAccountIDFunction().Run(context.Background(), req, &resp)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	return resp.Result.Value().(types.String).ValueString(), nil
+}
+
+func TestFormatAccountIDFunction(t *testing.T) {
+	tests := []struct {
+		name  string
+		value attr.Value
+		want  string
+	}{
+		{name: "string already formatted", value: types.StringValue("123456789012"), want: "123456789012"},
+		{name: "string missing leading zeros", value: types.StringValue("12345678901"), want: "012345678901"},
+		{name: "string with whitespace", value: types.StringValue("  42\n"), want: "000000000042"},
+		{name: "number", value: types.NumberValue(big.NewFloat(123456789012)), want: "123456789012"},
+		{name: "number missing leading zeros", value: types.NumberValue(big.NewFloat(1234567)), want: "000001234567"},
+		{name: "zero", value: types.NumberValue(big.NewFloat(0)), want: "000000000000"},
+		{name: "largest account ID", value: types.NumberValue(big.NewFloat(999999999999)), want: "999999999999"},
+		{name: "whole number written as float", value: types.NumberValue(big.NewFloat(42.0)), want: "000000000042"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runFormatAccountID(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			if !isAccountID(got) {
+				t.Errorf("expected %s to be a valid account ID", got)
+			}
+		})
+	}
+}
+
+func TestFormatAccountIDFunction_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value attr.Value
+	}{
+		{name: "empty string", value: types.StringValue("")},
+		{name: "blank string", value: types.StringValue("   ")},
+		{name: "letters", value: types.StringValue("12345abc")},
+		{name: "dashes", value: types.StringValue("1234-5678-9012")},
+		{name: "sign", value: types.StringValue("-42")},
+		{name: "too many digits string", value: types.StringValue("1234567890123")},
+		{name: "too many digits number", value: types.NumberValue(big.NewFloat(1e12))},
+		{name: "negative", value: types.NumberValue(big.NewFloat(-1))},
+		{name: "fractional", value: types.NumberValue(big.NewFloat(12.5))},
+		{name: "infinite", value: types.NumberValue(big.NewFloat(math.Inf(1)))},
+		{name: "bool", value: types.BoolValue(true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runFormatAccountID(tt.value)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.FunctionArgument == nil || *err.FunctionArgument != 0 {
+				t.Errorf("expected the error to point at the account_id argument, got %v", err.FunctionArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &IsAccountIDFunction{}
+
+func NewIsAccountIDFunction() function.Function {
+	return &IsAccountIDFunction{}
+}
+
+type IsAccountIDFunction struct{}
+
+func (f *IsAccountIDFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_account_id"
+}
+
+func (f *IsAccountIDFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check whether a string is an AWS account ID",
+		MarkdownDescription: "Returns `true` if the given string is a valid AWS account ID: exactly 12 decimal digits, with no surrounding whitespace.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "account_id",
+				MarkdownDescription: "The value to check",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *IsAccountIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var accountID string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &accountID))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, isAccountID(accountID)))
+}
+
+// isAccountID reports whether s is exactly 12 decimal digits.
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
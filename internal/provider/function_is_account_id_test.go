@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIsAccountIDFunction(t *testing.T) {
+	tests := []struct {
+		name      string
+		accountID string
+		want      bool
+	}{
+		{name: "valid", accountID: "123456789012", want: true},
+		{name: "leading zeros", accountID: "000000000001", want: true},
+		{name: "too short", accountID: "12345678901", want: false},
+		{name: "too long", accountID: "1234567890123", want: false},
+		{name: "empty", accountID: "", want: false},
+		{name: "letters", accountID: "12345678901a", want: false},
+		{name: "dashes", accountID: "1234-5678-9012", want: false},
+		{name: "surrounding whitespace", accountID: " 123456789012", want: false},
+		{name: "non-ASCII digits", accountID: "١٢٣٤٥٦٧٨٩٠١٢", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(tt.accountID)})}
+			resp := function.RunResponse{Result: function.NewResultData(types.BoolUnknown())}
+			NewIsAccountIDFunction().Run(context.Background(), req, &resp)
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+
+			got, ok := resp.Result.Value().(types.Bool)
+			if !ok {
+				t.Fatalf("expected a bool result, got %T", resp.Result.Value())
+			}
+			if got.ValueBool() != tt.want {
+				t.Errorf("expected %t for %q, got %t", tt.want, tt.accountID, got.ValueBool())
+			}
+		})
+	}
+}
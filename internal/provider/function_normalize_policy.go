@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &NormalizePolicyFunction{}
+
+func NewNormalizePolicyFunction() function.Function {
+	return &NormalizePolicyFunction{}
+}
+
+type NormalizePolicyFunction struct{}
+
+func (f *NormalizePolicyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize_policy"
+}
+
+func (f *NormalizePolicyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Normalize an IAM policy document",
+		MarkdownDescription: "Returns the given JSON document in canonical form: object keys sorted and insignificant whitespace removed. " +
+			"Use it for `inline_policies` values so that equivalent policies always produce the same string.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "policy",
+				MarkdownDescription: "The policy document as a JSON string",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NormalizePolicyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var policy string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &policy))
+	if resp.Error != nil {
+		return
+	}
+
+	normalized, err := normalizePolicyJSON(policy)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("Invalid policy JSON: %s", err)))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, normalized))
+}
+
+// normalizePolicyJSON re-encodes a JSON document with sorted object keys and
+// no insignificant whitespace. Numbers keep their original representation.
+func normalizePolicyJSON(policy string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(policy)))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+	// More() is false before a stray "}" or "]", so look for the end of
+	// the input instead
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	// encoding/json sorts map keys; disable HTML escaping so characters such
+	// as "<" and "&" in conditions are kept verbatim
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func runNormalizePolicy(t *testing.T, policy string) (string, *function.FuncError) {
+	t.Helper()
+
+	req := function.RunRequest{Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(policy)})}
+	resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+	NewNormalizePolicyFunction().Run(context.Background(), req, &resp)
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	result, ok := resp.Result.Value().(types.String)
+	if !ok {
+		t.Fatalf("expected a string result, got %T", resp.Result.Value())
+	}
+	return result.ValueString(), nil
+}
+
+func TestNormalizePolicyFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{
+			name: "sorts keys and strips whitespace",
+			policy: `{
+				"Version": "2012-10-17",
+				"Statement": [{"Resource": "*", "Effect": "Allow", "Action": ["s3:GetObject"]}]
+			}`,
+			want: `{"Statement":[{"Action":["s3:GetObject"],"Effect":"Allow","Resource":"*"}],"Version":"2012-10-17"}`,
+		},
+		{
+			name:   "keeps array order and number formatting",
+			policy: `{"b": [3, 1, 2], "a": 1.50}`,
+			want:   `{"a":1.50,"b":[3,1,2]}`,
+		},
+		{
+			name:   "does not escape HTML characters",
+			policy: `{"Condition": {"StringLike": {"aws:PrincipalTag/team": "a&b<c>"}}}`,
+			want:   `{"Condition":{"StringLike":{"aws:PrincipalTag/team":"a&b<c>"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runNormalizePolicy(t, tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizePolicyFunction_Idempotent(t *testing.T) {
+	once, err := runNormalizePolicy(t, `{"Version": "2012-10-17", "Statement": []}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	twice, err := runNormalizePolicy(t, once)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if once != twice {
+		t.Errorf("expected normalizing twice to be stable, got %s then %s", once, twice)
+	}
+}
+
+func TestNormalizePolicyFunction_InvalidJSON(t *testing.T) {
+	for _, policy := range []string{`{"Version": `, `not json`, `{} {}`, ``, `{"a":1} }`, `{"a":1} ]`, `{"a":1} x`} {
+		if _, err := runNormalizePolicy(t, policy); err == nil {
+			t.Errorf("expected an error for %q", policy)
+		} else if err.FunctionArgument == nil || *err.FunctionArgument != 0 {
+			t.Errorf("expected the error to point at the policy argument for %q", policy)
+		}
+	}
+}
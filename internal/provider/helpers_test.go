@@ -0,0 +1,359 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// newTestClient starts a TLS test server with handler and returns a Client
+// pointed at it. The server is closed when the test finishes.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	client := NewClient(srv.URL, "test", "test-token")
+	client.HTTPClient = srv.Client()
+	return client
+}
+
+// writeAPIData writes data wrapped in the standard API response envelope.
+func writeAPIData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// readDataSource runs ds.Read with config (a data source model struct) and
+// returns the resulting state.
+func readDataSource(t *testing.T, ds datasource.DataSource, client *Client, config interface{}) (tfsdk.State, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	ds.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("schema diagnostics: %v", schemaResp.Diagnostics)
+	}
+	s := schemaResp.Schema
+
+	if c, ok := ds.(datasource.DataSourceWithConfigure); ok {
+		var configureResp datasource.ConfigureResponse
+		c.Configure(ctx, datasource.ConfigureRequest{ProviderData: client}, &configureResp)
+		if configureResp.Diagnostics.HasError() {
+			t.Fatalf("configure diagnostics: %v", configureResp.Diagnostics)
+		}
+	}
+
+	// Build the config value by round-tripping the model through a State
+	configState := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}
+	if diags := configState.Set(ctx, config); diags.HasError() {
+		t.Fatalf("building config: %v", diags)
+	}
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Schema: s, Raw: configState.Raw}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)}}
+	ds.Read(ctx, req, &resp)
+
+	return resp.State, resp.Diagnostics
+}
+
+// newAccTestClient returns the client used by resource lifecycle tests.
+//
+// With TF_ACC set, it targets the real Prism backend configured through
+// PRISM_BASE_URL, PRISM_SUBDOMAIN and PRISM_API_TOKEN (skipping the test if
+// any is missing) and the returned fakePrism is nil. Otherwise it returns a
+// client backed by an in-process fake Prism server.
+func newAccTestClient(t *testing.T) (*Client, *fakePrism) {
+	t.Helper()
+
+	if os.Getenv("TF_ACC") == "" {
+		fake := newFakePrism()
+		return newTestClient(t, fake), fake
+	}
+
+	baseURL := os.Getenv("PRISM_BASE_URL")
+	subdomain := os.Getenv("PRISM_SUBDOMAIN")
+	token := os.Getenv("PRISM_API_TOKEN")
+	if baseURL == "" || subdomain == "" || token == "" {
+		t.Skip("PRISM_BASE_URL, PRISM_SUBDOMAIN and PRISM_API_TOKEN must be set for acceptance tests")
+	}
+
+	// Mirror the provider's Configure, which serves the API on port 8090
+	return NewClient(strings.TrimSuffix(baseURL, "/")+":8090", subdomain, token), nil
+}
+
+// requireFake skips tests that manipulate backend state directly when
+// running against a real Prism backend.
+func requireFake(t *testing.T, fake *fakePrism) {
+	t.Helper()
+	if fake == nil {
+		t.Skip("test requires the fake Prism server")
+	}
+}
+
+// accName returns a unique name for objects created by lifecycle tests.
+func accName(prefix string) string {
+	return fmt.Sprintf("tf-acc-%s-%d", prefix, time.Now().UnixNano()%1000000)
+}
+
+// resourceHarness drives a resource's CRUD and import methods the way
+// Terraform core would, for lifecycle tests.
+type resourceHarness struct {
+	t      *testing.T
+	r      resource.Resource
+	schema schema.Schema
+}
+
+func newResourceHarness(t *testing.T, r resource.Resource, client *Client) *resourceHarness {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("schema diagnostics: %v", schemaResp.Diagnostics)
+	}
+
+	if c, ok := r.(resource.ResourceWithConfigure); ok {
+		var configureResp resource.ConfigureResponse
+		c.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &configureResp)
+		if configureResp.Diagnostics.HasError() {
+			t.Fatalf("configure diagnostics: %v", configureResp.Diagnostics)
+		}
+	}
+
+	return &resourceHarness{t: t, r: r, schema: schemaResp.Schema}
+}
+
+func (h *resourceHarness) nullState() tfsdk.State {
+	return tfsdk.State{Schema: h.schema, Raw: tftypes.NewValue(h.schema.Type().TerraformType(context.Background()), nil)}
+}
+
+// state builds a State from a resource model struct.
+func (h *resourceHarness) state(model interface{}) tfsdk.State {
+	h.t.Helper()
+
+	state := h.nullState()
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		h.t.Fatalf("building state: %v", diags)
+	}
+	return state
+}
+
+// get decodes state into a resource model struct.
+func (h *resourceHarness) get(state tfsdk.State, target interface{}) {
+	h.t.Helper()
+
+	if diags := state.Get(context.Background(), target); diags.HasError() {
+		h.t.Fatalf("reading state: %v", diags)
+	}
+}
+
+func (h *resourceHarness) create(plan interface{}) (tfsdk.State, diag.Diagnostics) {
+	h.t.Helper()
+
+	planState := h.state(plan)
+	req := resource.CreateRequest{
+		Config: tfsdk.Config{Schema: h.schema, Raw: planState.Raw},
+		Plan:   tfsdk.Plan{Schema: h.schema, Raw: planState.Raw},
+	}
+	resp := resource.CreateResponse{State: h.nullState()}
+	h.r.Create(context.Background(), req, &resp)
+
+	return resp.State, resp.Diagnostics
+}
+
+// validateConfig runs the resource's ValidateConfig, if it has one, with
+// config (a resource model struct).
+func (h *resourceHarness) validateConfig(config interface{}) diag.Diagnostics {
+	h.t.Helper()
+
+	v, ok := h.r.(resource.ResourceWithValidateConfig)
+	if !ok {
+		return nil
+	}
+	req := resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: h.schema, Raw: h.state(config).Raw}}
+	var resp resource.ValidateConfigResponse
+	v.ValidateConfig(context.Background(), req, &resp)
+
+	return resp.Diagnostics
+}
+
+// modifyPlan runs the resource's ModifyPlan, if it has one, for creating a
+// resource with plan (a resource model struct).
+func (h *resourceHarness) modifyPlan(plan interface{}) diag.Diagnostics {
+	h.t.Helper()
+
+	m, ok := h.r.(resource.ResourceWithModifyPlan)
+	if !ok {
+		return nil
+	}
+	raw := h.state(plan).Raw
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: h.schema, Raw: raw},
+		Plan:   tfsdk.Plan{Schema: h.schema, Raw: raw},
+		State:  h.nullState(),
+	}
+	resp := resource.ModifyPlanResponse{Plan: req.Plan}
+	m.ModifyPlan(context.Background(), req, &resp)
+
+	return resp.Diagnostics
+}
+
+// read refreshes state. A null Raw value in the result means the resource
+// was removed from state.
+func (h *resourceHarness) read(state tfsdk.State) (tfsdk.State, diag.Diagnostics) {
+	h.t.Helper()
+
+	req := resource.ReadRequest{State: state}
+	resp := resource.ReadResponse{State: state}
+	h.r.Read(context.Background(), req, &resp)
+
+	return resp.State, resp.Diagnostics
+}
+
+func (h *resourceHarness) update(state tfsdk.State, plan interface{}) (tfsdk.State, diag.Diagnostics) {
+	h.t.Helper()
+
+	planState := h.state(plan)
+	req := resource.UpdateRequest{
+		Config: tfsdk.Config{Schema: h.schema, Raw: planState.Raw},
+		Plan:   tfsdk.Plan{Schema: h.schema, Raw: planState.Raw},
+		State:  state,
+	}
+	resp := resource.UpdateResponse{State: state}
+	h.r.Update(context.Background(), req, &resp)
+
+	return resp.State, resp.Diagnostics
+}
+
+func (h *resourceHarness) delete(state tfsdk.State) diag.Diagnostics {
+	h.t.Helper()
+
+	req := resource.DeleteRequest{State: state}
+	resp := resource.DeleteResponse{State: state}
+	h.r.Delete(context.Background(), req, &resp)
+
+	return resp.Diagnostics
+}
+
+// importState runs ImportState followed by Read, as `terraform import` does.
+func (h *resourceHarness) importState(id string) (tfsdk.State, diag.Diagnostics) {
+	h.t.Helper()
+
+	importer, ok := h.r.(resource.ResourceWithImportState)
+	if !ok {
+		h.t.Fatal("resource does not support import")
+	}
+
+	resp := resource.ImportStateResponse{State: h.nullState()}
+	importer.ImportState(context.Background(), resource.ImportStateRequest{ID: id}, &resp)
+	if resp.Diagnostics.HasError() {
+		return resp.State, resp.Diagnostics
+	}
+
+	return h.read(resp.State)
+}
+
+// attr returns the string value of a top-level attribute in state.
+func (h *resourceHarness) attr(state tfsdk.State, name string) string {
+	h.t.Helper()
+
+	var value types.String
+	if diags := state.GetAttribute(context.Background(), path.Root(name), &value); diags.HasError() {
+		h.t.Fatalf("reading %s: %v", name, diags)
+	}
+	return value.ValueString()
+}
+
+// assertImportComplete checks that state produced by importState holds the
+// id and every required, non-sensitive attribute, so that
+// `terraform plan -generate-config-out` can write a usable configuration.
+func (h *resourceHarness) assertImportComplete(state tfsdk.State) {
+	h.t.Helper()
+
+	if state.Raw.IsNull() {
+		h.t.Fatal("expected import to produce state, but the resource was removed")
+	}
+
+	for name, attribute := range h.schema.Attributes {
+		if name != "id" && (!attribute.IsRequired() || attribute.IsSensitive()) {
+			continue
+		}
+
+		var value attr.Value
+		if diags := state.GetAttribute(context.Background(), path.Root(name), &value); diags.HasError() {
+			h.t.Fatalf("reading %s: %v", name, diags)
+		}
+		if value.IsNull() || value.IsUnknown() {
+			h.t.Errorf("expected %s to be populated after import", name)
+		}
+	}
+}
+
+// hasDiagnostic reports whether diags contains a diagnostic with the given summary.
+func hasDiagnostic(diags diag.Diagnostics, summary string) bool {
+	for _, d := range diags {
+		if d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
+
+// dropRequestFields wraps handler as a backend that silently ignores the
+// given top-level fields in POST and PUT request bodies.
+func dropRequestFields(t *testing.T, handler http.Handler, fields ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body: %s", err)
+			}
+			for _, field := range fields {
+				delete(body, field)
+			}
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				t.Errorf("encoding request body: %s", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(encoded))
+			r.ContentLength = int64(len(encoded))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// diagnosticDetail returns the detail of the first diagnostic with the given
+// summary, or "" if there is none.
+func diagnosticDetail(diags diag.Diagnostics, summary string) string {
+	for _, d := range diags {
+		if d.Summary() == summary {
+			return d.Detail()
+		}
+	}
+	return ""
+}
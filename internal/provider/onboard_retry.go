@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// onboardRetryableErrors are the error messages of onboarding failures that
+// are known to be transient, matched case-insensitively against the response
+// body. Onboarding isn't idempotent, so no other failure is retried.
+var onboardRetryableErrors = []string{
+	"IdP creation in progress",
+	"realm lock timeout",
+}
+
+// Retry bounds for onboarding an account
+const onboardMaxAttempts = 4
+
+// Backoff between onboarding attempts. Variables so that tests can shorten
+// them.
+var (
+	onboardInitialBackoff = 500 * time.Millisecond
+	onboardMaxBackoff     = 5 * time.Second
+)
+
+// isOnboardRetryable reports whether err is one of onboardRetryableErrors.
+func isOnboardRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	body := strings.ToLower(apiErr.Body)
+	for _, message := range onboardRetryableErrors {
+		if strings.Contains(body, strings.ToLower(message)) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryOnboard calls onboard until it succeeds, fails with an error that
+// isn't in onboardRetryableErrors, or has been tried onboardMaxAttempts
+// times, backing off exponentially between attempts. A Retry-After header
+// is honoured when it asks for longer. Errors after more than one attempt
+// say how many were made.
+func retryOnboard(ctx context.Context, accountID string, onboard func() ([]byte, error)) ([]byte, error) {
+	backoff := onboardInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		body, err := onboard()
+		if err == nil {
+			return body, nil
+		}
+		if !isOnboardRetryable(err) || attempt == onboardMaxAttempts {
+			if attempt > 1 {
+				return nil, fmt.Errorf("onboarding failed after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		wait := backoff
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		tflog.Debug(ctx, "Onboarding failed with a transient error, retrying", map[string]interface{}{
+			"account_id": accountID,
+			"attempt":    attempt,
+			"wait":       wait.String(),
+			"error":      err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped retrying onboarding after %d attempts: %w (last error: %s)", attempt, ctx.Err(), err)
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > onboardMaxBackoff {
+			backoff = onboardMaxBackoff
+		}
+	}
+}
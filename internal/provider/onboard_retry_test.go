@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingOnboards answers the first failures onboard requests with status
+// and message, and passes everything else to next. A negative failures
+// never stops.
+type failingOnboards struct {
+	next     http.Handler
+	failures int
+	status   int
+	message  string
+
+	mu       sync.Mutex
+	attempts int
+}
+
+func (f *failingOnboards) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/accounts/onboard") {
+		f.mu.Lock()
+		f.attempts++
+		fail := f.failures < 0 || f.attempts <= f.failures
+		f.mu.Unlock()
+		if fail {
+			writeAPIError(w, f.status, f.message)
+			return
+		}
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+// shortOnboardBackoff shortens the backoff between onboarding attempts for
+// the rest of the test.
+func shortOnboardBackoff(t *testing.T) {
+	initial, max := onboardInitialBackoff, onboardMaxBackoff
+	onboardInitialBackoff, onboardMaxBackoff = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { onboardInitialBackoff, onboardMaxBackoff = initial, max })
+}
+
+func TestCreateAWSAccount_RetriesTransientOnboardFailures(t *testing.T) {
+	shortOnboardBackoff(t)
+	for _, message := range []string{"IdP creation in progress", "Realm lock timeout while creating client"} {
+		t.Run(message, func(t *testing.T) {
+			fake := newFakePrism()
+			handler := &failingOnboards{next: fake, failures: 2, status: http.StatusInternalServerError, message: message}
+			client := newTestClient(t, handler)
+
+			account, err := client.CreateAWSAccount(context.Background(), &AWSAccount{AccountID: "123456789012", AccountName: "prod"})
+			if err != nil {
+				t.Fatalf("expected onboarding to succeed after transient failures, got %s", err)
+			}
+			if account.AccountID != "123456789012" {
+				t.Errorf("expected the onboarded account, got %+v", account)
+			}
+			if handler.attempts != 3 {
+				t.Errorf("expected 3 onboard attempts, got %d", handler.attempts)
+			}
+		})
+	}
+}
+
+func TestCreateAWSAccount_DoesNotRetryOtherOnboardFailures(t *testing.T) {
+	fake := newFakePrism()
+	handler := &failingOnboards{next: fake, failures: -1, status: http.StatusInternalServerError, message: "failed to create OIDC provider"}
+	client := newTestClient(t, handler)
+
+	_, err := client.CreateAWSAccount(context.Background(), &AWSAccount{AccountID: "123456789012", AccountName: "prod"})
+	if err == nil || !strings.Contains(err.Error(), "failed to create OIDC provider") {
+		t.Fatalf("expected the backend error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "attempts") {
+		t.Errorf("expected no attempt count for a single attempt, got %s", err)
+	}
+	if handler.attempts != 1 {
+		t.Errorf("expected 1 onboard attempt, got %d", handler.attempts)
+	}
+}
+
+func TestCreateAWSAccount_GivesUpOnPersistentTransientFailures(t *testing.T) {
+	shortOnboardBackoff(t)
+	fake := newFakePrism()
+	handler := &failingOnboards{next: fake, failures: -1, status: http.StatusServiceUnavailable, message: "realm lock timeout"}
+	client := newTestClient(t, handler)
+
+	_, err := client.CreateAWSAccount(context.Background(), &AWSAccount{AccountID: "123456789012", AccountName: "prod"})
+	if err == nil || !strings.Contains(err.Error(), "onboarding failed after 4 attempts") || !strings.Contains(err.Error(), "realm lock timeout") {
+		t.Fatalf("expected an error with the attempt count and the last failure, got %v", err)
+	}
+	if handler.attempts != onboardMaxAttempts {
+		t.Errorf("expected %d onboard attempts, got %d", onboardMaxAttempts, handler.attempts)
+	}
+	if len(fake.accounts) != 0 {
+		t.Errorf("expected no account, got %v", fake.accounts)
+	}
+}
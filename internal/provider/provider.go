@@ -2,19 +2,27 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure CloudKeeperProvider satisfies various provider interfaces.
 var _ provider.Provider = &CloudKeeperProvider{}
+var _ provider.ProviderWithFunctions = &CloudKeeperProvider{}
 
 // CloudKeeperProvider defines the provider implementation.
 type CloudKeeperProvider struct {
@@ -26,6 +34,22 @@ type CloudKeeperProviderModel struct {
 	PrismSubdomain types.String `tfsdk:"prism_subdomain"`
 	APIToken       types.String `tfsdk:"api_token"`
 	BaseURL        types.String `tfsdk:"base_url"`
+	Port           types.Int64  `tfsdk:"port"`
+
+	CleanupAssignmentsOnDelete types.Bool `tfsdk:"cleanup_assignments_on_delete"`
+	ValidatePolicyARNs         types.Bool `tfsdk:"validate_policy_arns"`
+
+	Auth *ProviderAuthModel `tfsdk:"auth"`
+}
+
+// ProviderAuthModel describes the provider's auth block.
+type ProviderAuthModel struct {
+	Method       types.String `tfsdk:"method"`
+	Token        types.String `tfsdk:"token"`
+	TokenURL     types.String `tfsdk:"token_url"`
+	ClientID     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	Scopes       types.List   `tfsdk:"scopes"`
 }
 
 // New creates a new provider instance
@@ -59,9 +83,67 @@ func (p *CloudKeeperProvider) Schema(ctx context.Context, req provider.SchemaReq
 				Sensitive:           true,
 			},
 			"base_url": schema.StringAttribute{
-				MarkdownDescription: "The base URL for the Prism API endpoint (e.g., `https://prism.cloudkeeper.com`). The port 8090 is automatically appended. Can also be set via the `PRISM_BASE_URL` environment variable.",
+				MarkdownDescription: "The base URL for the Prism API endpoint (e.g., `https://prism.cloudkeeper.com`). The `port` is automatically appended. Can also be set via the `PRISM_BASE_URL` environment variable.",
 				Optional:            true,
 			},
+			"port": schema.Int64Attribute{
+				MarkdownDescription: "The port of the Prism API endpoint. Defaults to `8090`. Can also be set via the `PRISM_PORT` environment variable.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
+			},
+			"cleanup_assignments_on_delete": schema.BoolAttribute{
+				MarkdownDescription: "Whether deleting a permission set, AWS account, user, or group first deletes the permission set assignments that reference it. " +
+					"When `false`, such deletes fail with an error listing the blocking assignments. Defaults to `true`. " +
+					"Can also be set via the `PRISM_CLEANUP_ASSIGNMENTS_ON_DELETE` environment variable.",
+				Optional: true,
+			},
+			"validate_policy_arns": schema.BoolAttribute{
+				MarkdownDescription: "Whether plans check that the `managed_policies` of permission sets are IAM policy ARNs such as `arn:aws:iam::aws:policy/ReadOnlyAccess`. " +
+					"Set to `false` for partitions the check doesn't recognize. Defaults to `true`. " +
+					"Can also be set via the `PRISM_VALIDATE_POLICY_ARNS` environment variable.",
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"auth": schema.SingleNestedBlock{
+				MarkdownDescription: "How requests to the Prism API are authenticated. Without this block, `api_token` is sent in the `X-API-Token` header.",
+				Attributes: map[string]schema.Attribute{
+					"method": schema.StringAttribute{
+						MarkdownDescription: "The authentication method: `api_token` sends `api_token` in the `X-API-Token` header, " +
+							"`bearer` sends `token` in an `Authorization: Bearer` header, and `oauth2_client_credentials` gets bearer tokens from `token_url` " +
+							"with the OAuth2 client credentials grant, refreshing them before they expire. Defaults to `api_token`.",
+						Optional: true,
+						Validators: []validator.String{
+							stringvalidator.OneOf(AuthMethodAPIToken, AuthMethodBearer, AuthMethodOAuth2ClientCredentials),
+						},
+					},
+					"token": schema.StringAttribute{
+						MarkdownDescription: "The bearer token for the `bearer` method. Defaults to `api_token`.",
+						Optional:            true,
+						Sensitive:           true,
+					},
+					"token_url": schema.StringAttribute{
+						MarkdownDescription: "The OAuth2 token endpoint for the `oauth2_client_credentials` method.",
+						Optional:            true,
+					},
+					"client_id": schema.StringAttribute{
+						MarkdownDescription: "The OAuth2 client ID for the `oauth2_client_credentials` method.",
+						Optional:            true,
+					},
+					"client_secret": schema.StringAttribute{
+						MarkdownDescription: "The OAuth2 client secret for the `oauth2_client_credentials` method.",
+						Optional:            true,
+						Sensitive:           true,
+					},
+					"scopes": schema.ListAttribute{
+						MarkdownDescription: "The OAuth2 scopes to request for the `oauth2_client_credentials` method.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -122,6 +204,57 @@ func (p *CloudKeeperProvider) Configure(ctx context.Context, req provider.Config
 		baseURL = data.BaseURL.ValueString()
 	}
 
+	port := int64(DefaultPort)
+	if v := os.Getenv("PRISM_PORT"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 1 || parsed > 65535 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("port"),
+				"Invalid PRISM_PORT Value",
+				fmt.Sprintf("The PRISM_PORT environment variable must be a port number between 1 and 65535, got %q.", v),
+			)
+		}
+		port = parsed
+	}
+
+	if !data.Port.IsNull() && !data.Port.IsUnknown() {
+		port = data.Port.ValueInt64()
+	}
+
+	cleanupAssignmentsOnDelete := true
+	if v := os.Getenv("PRISM_CLEANUP_ASSIGNMENTS_ON_DELETE"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cleanup_assignments_on_delete"),
+				"Invalid PRISM_CLEANUP_ASSIGNMENTS_ON_DELETE Value",
+				fmt.Sprintf("The PRISM_CLEANUP_ASSIGNMENTS_ON_DELETE environment variable must be a boolean, got %q.", v),
+			)
+		}
+		cleanupAssignmentsOnDelete = parsed
+	}
+
+	if !data.CleanupAssignmentsOnDelete.IsNull() && !data.CleanupAssignmentsOnDelete.IsUnknown() {
+		cleanupAssignmentsOnDelete = data.CleanupAssignmentsOnDelete.ValueBool()
+	}
+
+	validatePolicyARNs := true
+	if v := os.Getenv("PRISM_VALIDATE_POLICY_ARNS"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("validate_policy_arns"),
+				"Invalid PRISM_VALIDATE_POLICY_ARNS Value",
+				fmt.Sprintf("The PRISM_VALIDATE_POLICY_ARNS environment variable must be a boolean, got %q.", v),
+			)
+		}
+		validatePolicyARNs = parsed
+	}
+
+	if !data.ValidatePolicyARNs.IsNull() && !data.ValidatePolicyARNs.IsUnknown() {
+		validatePolicyARNs = data.ValidatePolicyARNs.ValueBool()
+	}
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
@@ -135,7 +268,20 @@ func (p *CloudKeeperProvider) Configure(ctx context.Context, req provider.Config
 		)
 	}
 
-	if apiToken == "" {
+	authMethod := AuthMethodAPIToken
+	if data.Auth != nil && data.Auth.Method.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("auth").AtName("method"),
+			"Unknown Authentication Method",
+			"The provider cannot create the CloudKeeper API client as auth.method is unknown. Set the value statically in the configuration, or target apply its source first.",
+		)
+		return
+	}
+	if data.Auth != nil && !data.Auth.Method.IsNull() {
+		authMethod = data.Auth.Method.ValueString()
+	}
+
+	if apiToken == "" && authMethod == AuthMethodAPIToken {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("api_token"),
 			"Missing CloudKeeper API Token",
@@ -155,16 +301,27 @@ func (p *CloudKeeperProvider) Configure(ctx context.Context, req provider.Config
 		)
 	}
 
+	auth := providerAuthenticator(ctx, authMethod, data.Auth, apiToken, &resp.Diagnostics)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Ensure base URL doesn't have trailing slash and append port
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	finalBaseURL := baseURL + ":8090"
-
 	// Create a new CloudKeeper client using the configuration values
-	client := NewClient(finalBaseURL, prismSubdomain, apiToken)
+	client := NewClient(APIBaseURL(baseURL, port), prismSubdomain, apiToken)
+	client.CleanupAssignmentsOnDelete = cleanupAssignmentsOnDelete
+	client.ValidatePolicyARNs = validatePolicyARNs
+	client.ProviderVersion = p.version
+	// The provider is configured anew for each Terraform operation, so
+	// cached reads last one operation
+	client.CacheReads = true
+	if oauth2, ok := auth.(*OAuth2ClientCredentialsAuth); ok {
+		oauth2.HTTPClient = client.HTTPClient
+	}
+	client.Auth = auth
+	// Configure sends no requests, so plans that refresh nothing work
+	// without a route to Prism. The version is probed by the first request
+	client.ProbeVersion = true
 
 	// Make the CloudKeeper client available during DataSource and Resource
 	// type Configure methods.
@@ -172,12 +329,24 @@ func (p *CloudKeeperProvider) Configure(ctx context.Context, req provider.Config
 	resp.ResourceData = client
 }
 
+// DefaultPort is the port of the Prism API when none is configured.
+const DefaultPort = 8090
+
+// APIBaseURL joins the configured base URL and port into the URL that the
+// client sends requests to, e.g. https://prism.cloudkeeper.com:8090.
+func APIBaseURL(baseURL string, port int64) string {
+	return fmt.Sprintf("%s:%d", strings.TrimSuffix(baseURL, "/"), port)
+}
+
 // Resources defines the resources implemented in the provider.
 func (p *CloudKeeperProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewAWSAccountResource,
+		NewAccountOwnersResource,
 		NewPermissionSetResource,
 		NewPermissionSetAssignmentResource,
+		NewPermissionSetManagedPolicyAttachmentResource,
+		NewPermissionSetInlinePolicyResource,
 		NewUserResource,
 		NewGroupResource,
 		NewGroupMembershipResource,
@@ -190,7 +359,67 @@ func (p *CloudKeeperProvider) DataSources(ctx context.Context) []func() datasour
 	return []func() datasource.DataSource{
 		NewAWSAccountDataSource,
 		NewPermissionSetDataSource,
+		NewPermissionSetsDataSource,
+		NewPermissionSetAssignmentDataSource,
 		NewUserDataSource,
 		NewGroupDataSource,
+		NewGroupMembershipDataSource,
+		NewVersionDataSource,
+	}
+}
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *CloudKeeperProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewNormalizePolicyFunction,
+		NewIsAccountIDFunction,
+		NewFormatAccountIDFunction,
+	}
+}
+
+// providerAuthenticator returns the Authenticator for the auth block, adding
+// an error for each setting that method needs but is missing or unknown.
+// apiToken is the resolved api_token, which bearer uses without a token.
+func providerAuthenticator(ctx context.Context, method string, auth *ProviderAuthModel, apiToken string, diags *diag.Diagnostics) Authenticator {
+	if auth == nil {
+		auth = &ProviderAuthModel{}
+	}
+	authPath := path.Root("auth")
+
+	// required returns the value of a setting method needs
+	required := func(name string, value types.String) string {
+		switch {
+		case value.IsUnknown():
+			diags.AddAttributeError(authPath.AtName(name), "Unknown Authentication Setting",
+				fmt.Sprintf("The provider cannot create the CloudKeeper API client as auth.%s is unknown. Set the value statically in the configuration, or target apply its source first.", name))
+		case value.ValueString() == "":
+			diags.AddAttributeError(authPath.AtName(name), "Missing Authentication Setting",
+				fmt.Sprintf("The %s authentication method needs auth.%s to be set to a non-empty value.", method, name))
+		}
+		return value.ValueString()
+	}
+
+	switch method {
+	case AuthMethodBearer:
+		token := auth.Token
+		if token.IsNull() && apiToken != "" {
+			token = types.StringValue(apiToken)
+		}
+		return BearerAuth{Token: required("token", token)}
+	case AuthMethodOAuth2ClientCredentials:
+		oauth2 := &OAuth2ClientCredentialsAuth{
+			TokenURL:     required("token_url", auth.TokenURL),
+			ClientID:     required("client_id", auth.ClientID),
+			ClientSecret: required("client_secret", auth.ClientSecret),
+		}
+		if auth.Scopes.IsUnknown() {
+			diags.AddAttributeError(authPath.AtName("scopes"), "Unknown Authentication Setting",
+				"The provider cannot create the CloudKeeper API client as auth.scopes is unknown. Set the value statically in the configuration, or target apply its source first.")
+		} else if !auth.Scopes.IsNull() {
+			diags.Append(auth.Scopes.ElementsAs(ctx, &oauth2.Scopes, false)...)
+		}
+		return oauth2
+	default:
+		return APITokenAuth{Token: apiToken}
 	}
 }
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// protocol6Schemas starts the provider as a protocol 6 server in-process, as
+// main does, and returns its schemas.
+func protocol6Schemas(t *testing.T) *tfprotov6.GetProviderSchemaResponse {
+	t.Helper()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("starting provider server: %v", err)
+	}
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema: %v", err)
+	}
+	return resp
+}
+
+// TestProviderServer_Protocol6Schemas starts the provider as a protocol 6
+// server in-process, as main does, and checks that every resource and data
+// source schema loads without errors.
+func TestProviderServer_Protocol6Schemas(t *testing.T) {
+	ctx := context.Background()
+
+	resp := protocol6Schemas(t)
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Errorf("schema error: %s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	p := New("test")()
+	for _, newResource := range p.(*CloudKeeperProvider).Resources(ctx) {
+		var metadata resource.MetadataResponse
+		newResource().Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "prism"}, &metadata)
+		name := metadata.TypeName
+		if _, ok := resp.ResourceSchemas[name]; !ok {
+			t.Errorf("expected a schema for resource %s", name)
+		}
+	}
+	for _, newDataSource := range p.(*CloudKeeperProvider).DataSources(ctx) {
+		var metadata datasource.MetadataResponse
+		newDataSource().Metadata(ctx, datasource.MetadataRequest{ProviderTypeName: "prism"}, &metadata)
+		name := metadata.TypeName
+		if _, ok := resp.DataSourceSchemas[name]; !ok {
+			t.Errorf("expected a schema for data source %s", name)
+		}
+	}
+	if len(resp.Functions) == 0 {
+		t.Error("expected the provider functions to be listed")
+	}
+}
+
+// secretAttributeName matches attribute names that suggest a secret value.
+var secretAttributeName = regexp.MustCompile(`(?i)(password|secret|token|private_key|api_key|credential)`)
+
+// knownSecretAttributes are attributes that hold secrets although their
+// names don't say so.
+var knownSecretAttributes = []string{
+	"prism_identity_provider.config",
+	"prism_identity_provider.config_wo",
+}
+
+// notSecretAttributes are attributes whose names look like they hold a
+// secret but don't.
+var notSecretAttributes = map[string]bool{
+	"provider.auth.token_url": true,
+}
+
+// walkSchemaAttributes calls fn with the dotted path of every attribute in
+// block, including those of nested attributes and nested blocks.
+func walkSchemaAttributes(prefix string, block *tfprotov6.SchemaBlock, fn func(path string, attribute *tfprotov6.SchemaAttribute)) {
+	if block == nil {
+		return
+	}
+	for _, attribute := range block.Attributes {
+		walkSchemaAttribute(prefix+"."+attribute.Name, attribute, fn)
+	}
+	for _, nested := range block.BlockTypes {
+		walkSchemaAttributes(prefix+"."+nested.TypeName, nested.Block, fn)
+	}
+}
+
+func walkSchemaAttribute(path string, attribute *tfprotov6.SchemaAttribute, fn func(path string, attribute *tfprotov6.SchemaAttribute)) {
+	fn(path, attribute)
+	if attribute.NestedType == nil {
+		return
+	}
+	for _, nested := range attribute.NestedType.Attributes {
+		walkSchemaAttribute(path+"."+nested.Name, nested, fn)
+	}
+}
+
+// TestProviderSchemas_SecretsAreSensitive checks that every attribute of the
+// provider, its resources and its data sources that looks like it holds a
+// secret is marked sensitive, so that it's redacted from plan output.
+func TestProviderSchemas_SecretsAreSensitive(t *testing.T) {
+	resp := protocol6Schemas(t)
+
+	sensitive := map[string]bool{}
+	check := func(path string, attribute *tfprotov6.SchemaAttribute) {
+		sensitive[path] = attribute.Sensitive
+		if secretAttributeName.MatchString(attribute.Name) && !attribute.Sensitive && !notSecretAttributes[path] {
+			t.Errorf("expected %s to be sensitive", path)
+		}
+	}
+
+	walkSchemaAttributes("provider", resp.Provider.Block, check)
+	for name, s := range resp.ResourceSchemas {
+		walkSchemaAttributes(name, s.Block, check)
+	}
+	for name, s := range resp.DataSourceSchemas {
+		walkSchemaAttributes("data."+name, s.Block, check)
+	}
+
+	if !sensitive["provider.api_token"] {
+		t.Error("expected provider.api_token to be sensitive")
+	}
+	for _, path := range knownSecretAttributes {
+		if !sensitive[path] {
+			t.Errorf("expected %s to be sensitive", path)
+		}
+	}
+}
+
+// schemaObject returns an object of typ with the given attribute values and
+// every other attribute null.
+func schemaObject(typ tftypes.Type, values map[string]tftypes.Value) tftypes.Value {
+	object := typ.(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(object.AttributeTypes))
+	for name, attributeType := range object.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		}
+	}
+	return tftypes.NewValue(object, attributes)
+}
+
+func dynamicValue(t *testing.T, typ tftypes.Type, value tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+	dv, err := tfprotov6.NewDynamicValue(typ, value)
+	if err != nil {
+		t.Fatalf("encoding %s: %v", typ, err)
+	}
+	return &dv
+}
+
+func errorDiagnostics(t *testing.T, step string, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Errorf("%s: %s: %s", step, d.Summary, d.Detail)
+		}
+	}
+}
+
+// countingListener listens on a local port that drops every connection,
+// and returns the port and the number of connections made to it.
+func countingListener(t *testing.T) (int, *int32) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var connections int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&connections, 1)
+			conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, &connections
+}
+
+// TestProviderServer_PlanWithoutRefreshIsOffline configures the provider with
+// a base_url that can't serve requests, as on CI agents without a route to
+// Prism, and plans as terraform plan -refresh=false does: the planned
+// resources are never read. Neither step may connect to base_url.
+func TestProviderServer_PlanWithoutRefreshIsOffline(t *testing.T) {
+	port, connections := countingListener(t)
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("starting provider server: %v", err)
+	}
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema: %v", err)
+	}
+
+	providerType := schemas.Provider.ValueType()
+	configured, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.9.0",
+		Config: dynamicValue(t, providerType, schemaObject(providerType, map[string]tftypes.Value{
+			"prism_subdomain": tftypes.NewValue(tftypes.String, "acme"),
+			"api_token":       tftypes.NewValue(tftypes.String, "token"),
+			"base_url":        tftypes.NewValue(tftypes.String, "https://127.0.0.1"),
+			"port":            tftypes.NewValue(tftypes.Number, port),
+		})),
+	})
+	if err != nil {
+		t.Fatalf("ConfigureProvider: %v", err)
+	}
+	errorDiagnostics(t, "configure", configured.Diagnostics)
+
+	accountType := schemas.ResourceSchemas["prism_aws_account"].ValueType()
+	configValues := map[string]tftypes.Value{
+		"account_id":   tftypes.NewValue(tftypes.String, "123456789012"),
+		"account_name": tftypes.NewValue(tftypes.String, "Production"),
+		"region":       tftypes.NewValue(tftypes.String, "us-east-1"),
+		"owner_emails": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+			tftypes.NewValue(tftypes.String, "alice@example.com"),
+		}),
+	}
+	config := dynamicValue(t, accountType, schemaObject(accountType, configValues))
+
+	// A resource to create, and one already in state that isn't refreshed
+	stateValues := map[string]tftypes.Value{"id": tftypes.NewValue(tftypes.String, "123456789012")}
+	for name, value := range configValues {
+		stateValues[name] = value
+	}
+	prior := dynamicValue(t, accountType, schemaObject(accountType, stateValues))
+	plans := map[string]*tfprotov6.PlanResourceChangeRequest{
+		"create": {
+			TypeName:         "prism_aws_account",
+			PriorState:       dynamicValue(t, accountType, tftypes.NewValue(accountType, nil)),
+			ProposedNewState: config,
+			Config:           config,
+		},
+		"existing": {
+			TypeName:         "prism_aws_account",
+			PriorState:       prior,
+			ProposedNewState: prior,
+			Config:           config,
+		},
+	}
+	for name, req := range plans {
+		planned, err := server.PlanResourceChange(ctx, req)
+		if err != nil {
+			t.Fatalf("PlanResourceChange (%s): %v", name, err)
+		}
+		errorDiagnostics(t, "plan "+name, planned.Diagnostics)
+	}
+
+	if n := atomic.LoadInt32(connections); n != 0 {
+		t.Errorf("expected configure and plan to stay offline, got %d connections to base_url", n)
+	}
+}
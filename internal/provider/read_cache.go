@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+// cachedReadPrefixes are the paths whose GET responses the read cache keeps,
+// each with the path prefixes of the writes that change them. Onboarding an
+// account posts to /accounts/onboard rather than /aws-accounts.
+var cachedReadPrefixes = map[string][]string{
+	"/aws-accounts":    {"/aws-accounts", "/accounts"},
+	"/permission-sets": {"/permission-sets"},
+}
+
+// readCache keeps the responses of GET requests on AWS accounts and
+// permission sets for the lifetime of the client, which the provider
+// configures once per Terraform operation. A refresh of many assignments
+// that reference the same accounts then costs one request per account.
+// Any write under the same prefix drops the cached responses of that prefix.
+// Errors aren't cached, so waits for an object to appear keep polling.
+type readCache struct {
+	mu      sync.Mutex
+	entries map[string]*readCacheEntry
+}
+
+// readCacheEntry is a cached response, or one being fetched while done is
+// open. Concurrent readers of the same path wait for a single fetch.
+type readCacheEntry struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// cachedReadPrefix returns the prefix in cachedReadPrefixes that path's GETs
+// are cached under, if any.
+func cachedReadPrefix(path string) (string, bool) {
+	for prefix := range cachedReadPrefixes {
+		if hasPathPrefix(path, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// hasPathPrefix reports whether path is prefix or lies below it.
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
+
+// get returns the cached response for path, calling fetch for it when there
+// is none. The returned bytes are shared and must not be modified.
+func (rc *readCache) get(path string, fetch func() ([]byte, error)) ([]byte, error) {
+	rc.mu.Lock()
+	if entry, ok := rc.entries[path]; ok {
+		rc.mu.Unlock()
+		<-entry.done
+		return entry.data, entry.err
+	}
+	if rc.entries == nil {
+		rc.entries = make(map[string]*readCacheEntry)
+	}
+	entry := &readCacheEntry{done: make(chan struct{})}
+	rc.entries[path] = entry
+	rc.mu.Unlock()
+
+	entry.data, entry.err = fetch()
+
+	if entry.err != nil {
+		rc.mu.Lock()
+		// Unless a write has already dropped it
+		if rc.entries[path] == entry {
+			delete(rc.entries, path)
+		}
+		rc.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.data, entry.err
+}
+
+// invalidate drops the cached responses that a write to path may change.
+// Readers already waiting on a dropped fetch still get its response.
+func (rc *readCache) invalidate(path string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for readPrefix, writePrefixes := range cachedReadPrefixes {
+		for _, writePrefix := range writePrefixes {
+			if !hasPathPrefix(path, writePrefix) {
+				continue
+			}
+			for cached := range rc.entries {
+				if hasPathPrefix(cached, readPrefix) {
+					delete(rc.entries, cached)
+				}
+			}
+			break
+		}
+	}
+}
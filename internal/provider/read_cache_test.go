@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// countRequests wraps handler, counting requests by method and path.
+func countRequests(handler http.Handler) (http.Handler, func(request string) int) {
+	var (
+		mu     sync.Mutex
+		counts = map[string]int{}
+	)
+	wrapped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v1/customers/test")]++
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+	return wrapped, func(request string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[request]
+	}
+}
+
+// readCacheFake returns a fake with two accounts and a permission set, and
+// a client with the read cache on.
+func readCacheFake(t *testing.T) (*fakePrism, *Client, func(request string) int) {
+	t.Helper()
+
+	fake := newFakePrism()
+	fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111", AccountName: "prod"}
+	fake.accounts["222222222222"] = &AWSAccount{ID: "acct-2", AccountID: "222222222222", AccountName: "dev"}
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	handler, count := countRequests(fake)
+	client := newTestClient(t, handler)
+	client.CacheReads = true
+	return fake, client, count
+}
+
+func TestReadCache_CollapsesReads(t *testing.T) {
+	_, client, count := readCacheFake(t)
+
+	// Refreshing many resources reads the same objects over and over, partly
+	// in parallel
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, accountID := range []string{"111111111111", "222222222222"} {
+				if _, err := client.GetAWSAccount(accountID); err != nil {
+					t.Errorf("get account %s: %s", accountID, err)
+				}
+			}
+			if _, err := client.GetPermissionSet("ps-1"); err != nil {
+				t.Errorf("get permission set: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, request := range []string{"GET /aws-accounts/111111111111", "GET /aws-accounts/222222222222", "GET /permission-sets/ps-1"} {
+		if got := count(request); got != 1 {
+			t.Errorf("expected 1 %s, got %d", request, got)
+		}
+	}
+}
+
+func TestReadCache_WritesInvalidate(t *testing.T) {
+	_, client, count := readCacheFake(t)
+
+	if _, err := client.GetAWSAccount("111111111111"); err != nil {
+		t.Fatalf("get account: %s", err)
+	}
+	if _, err := client.GetPermissionSet("ps-1"); err != nil {
+		t.Fatalf("get permission set: %s", err)
+	}
+	if _, err := client.UpdateAWSAccount("111111111111", &AWSAccount{AccountID: "111111111111", AccountName: "production"}); err != nil {
+		t.Fatalf("update account: %s", err)
+	}
+
+	account, err := client.GetAWSAccount("111111111111")
+	if err != nil {
+		t.Fatalf("get account: %s", err)
+	}
+	if account.AccountName != "production" {
+		t.Errorf("expected the updated name, got %q", account.AccountName)
+	}
+	if got := count("GET /aws-accounts/111111111111"); got != 2 {
+		t.Errorf("expected the account to be fetched again after the update, got %d fetches", got)
+	}
+
+	// A write to accounts leaves cached permission sets alone
+	if _, err := client.GetPermissionSet("ps-1"); err != nil {
+		t.Fatalf("get permission set: %s", err)
+	}
+	if got := count("GET /permission-sets/ps-1"); got != 1 {
+		t.Errorf("expected the permission set to stay cached, got %d fetches", got)
+	}
+}
+
+func TestReadCache_ErrorsAreNotCached(t *testing.T) {
+	fake, client, count := readCacheFake(t)
+
+	if _, err := client.GetAWSAccount("333333333333"); !isNotFoundError(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	fake.accounts["333333333333"] = &AWSAccount{ID: "acct-3", AccountID: "333333333333"}
+	if _, err := client.GetAWSAccount("333333333333"); err != nil {
+		t.Fatalf("expected the account to be found once it exists, got %s", err)
+	}
+	if got := count("GET /aws-accounts/333333333333"); got != 2 {
+		t.Errorf("expected 2 fetches, got %d", got)
+	}
+}
+
+func TestReadCache_Off(t *testing.T) {
+	_, client, count := readCacheFake(t)
+	client.CacheReads = false
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetAWSAccount("111111111111"); err != nil {
+			t.Fatalf("get account: %s", err)
+		}
+	}
+	if got := count("GET /aws-accounts/111111111111"); got != 3 {
+		t.Errorf("expected every read to reach the backend, got %d", got)
+	}
+}
+
+func TestReadCache_AccountDeletionWaitBypassesCache(t *testing.T) {
+	fake, client, count := readCacheFake(t)
+
+	if _, err := client.GetAWSAccount("111111111111"); err != nil {
+		t.Fatalf("get account: %s", err)
+	}
+	if _, err := client.getAWSAccount("111111111111", requestOptions{uncached: true}); err != nil {
+		t.Fatalf("get account: %s", err)
+	}
+	delete(fake.accounts, "111111111111")
+	if _, err := client.getAWSAccount("111111111111", requestOptions{uncached: true}); !isNotFoundError(err) {
+		t.Errorf("expected an uncached read to see the account gone, got %v", err)
+	}
+	if got := count("GET /aws-accounts/111111111111"); got != 3 {
+		t.Errorf("expected 3 fetches, got %d", got)
+	}
+}
+
+func TestCachedReadPrefix(t *testing.T) {
+	tests := map[string]bool{
+		"/aws-accounts":                    true,
+		"/aws-accounts/111111111111":       true,
+		"/aws-accounts?max=1":              true,
+		"/aws-accounts-archive":            false,
+		"/permission-set-assignments/as-1": false,
+	}
+	for path, want := range tests {
+		_, got := cachedReadPrefix(path)
+		if got != want {
+			t.Errorf("cachedReadPrefix(%q): expected %t, got %t", path, want, got)
+		}
+	}
+}
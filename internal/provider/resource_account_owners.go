@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &AccountOwnersResource{}
+var _ resource.ResourceWithImportState = &AccountOwnersResource{}
+
+func NewAccountOwnersResource() resource.Resource {
+	return &AccountOwnersResource{}
+}
+
+type AccountOwnersResource struct {
+	client *Client
+}
+
+type AccountOwnersResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	AccountID   types.String `tfsdk:"account_id"`
+	OwnerEmails types.Set    `tfsdk:"owner_emails"`
+}
+
+func (r *AccountOwnersResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_owners"
+}
+
+func (r *AccountOwnersResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the owners of an onboarded AWS account. Owners approve JIT (Just-In-Time) access requests for the account. " +
+			"Use this resource when owners are managed separately from account onboarding, and leave `owner_emails` unset on the matching `prism_aws_account`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The AWS account ID",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The AWS account ID (12-digit number) of an onboarded account",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"owner_emails": schema.SetAttribute{
+				Required:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Email addresses of the account owners who approve JIT access requests",
+				Validators:          []validator.Set{emailsValidator{}},
+			},
+		},
+	}
+}
+
+func (r *AccountOwnersResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *AccountOwnersResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AccountOwnersResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ownerEmails []string
+	resp.Diagnostics.Append(data.OwnerEmails.ElementsAs(ctx, &ownerEmails, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sort.Strings(ownerEmails)
+
+	_, err := r.client.SetAWSAccountOwners(data.AccountID.ValueString(), ownerEmails)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to set account owners, got error: %s", err))
+		return
+	}
+
+	// Keep the planned owner_emails; the set is order-insensitive so the API
+	// response adds nothing
+	data.ID = types.StringValue(data.AccountID.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountOwnersResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AccountOwnersResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ownerEmails, err := r.client.GetAWSAccountOwners(data.AccountID.ValueString())
+	if err != nil {
+		// If the account is no longer onboarded (404), remove it from state
+		if isNotFoundError(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read account owners, got error: %s", err))
+		return
+	}
+
+	data.OwnerEmails = ownerEmailsSet(data.OwnerEmails, ownerEmails)
+	data.ID = types.StringValue(data.AccountID.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountOwnersResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AccountOwnersResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var ownerEmails []string
+	resp.Diagnostics.Append(data.OwnerEmails.ElementsAs(ctx, &ownerEmails, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sort.Strings(ownerEmails)
+
+	_, err := r.client.SetAWSAccountOwners(data.AccountID.ValueString(), ownerEmails)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update account owners, got error: %s", err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccountOwnersResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AccountOwnersResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.client.SetAWSAccountOwners(data.AccountID.ValueString(), nil)
+	if err != nil {
+		// The account was deboarded, so its owners are already gone
+		if isNotFoundError(err) {
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to remove account owners, got error: %s", err))
+		return
+	}
+}
+
+func (r *AccountOwnersResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import using account_id since that's what Read() uses to fetch the owners
+	resource.ImportStatePassthroughID(ctx, path.Root("account_id"), req, resp)
+}
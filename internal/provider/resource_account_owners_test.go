@@ -0,0 +1,219 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testAccountOwnersModel(t *testing.T, accountID string, ownerEmails ...string) *AccountOwnersResourceModel {
+	t.Helper()
+
+	set, diags := types.SetValueFrom(context.Background(), types.StringType, ownerEmails)
+	if diags.HasError() {
+		t.Fatalf("building owner_emails: %v", diags)
+	}
+	return &AccountOwnersResourceModel{
+		ID:          types.StringUnknown(),
+		AccountID:   types.StringValue(accountID),
+		OwnerEmails: set,
+	}
+}
+
+// ownerEmails returns the owner emails in state, sorted and comma-joined.
+func ownerEmails(h *resourceHarness, state tfsdk.State) string {
+	var data AccountOwnersResourceModel
+	h.get(state, &data)
+
+	var emails []string
+	data.OwnerEmails.ElementsAs(context.Background(), &emails, false)
+	sort.Strings(emails)
+	return strings.Join(emails, ",")
+}
+
+// ownersTestAccount returns an onboarded account to manage owners for: one
+// seeded into the fake, or the first of PRISM_ACC_AWS_ACCOUNT_IDS.
+func ownersTestAccount(t *testing.T, fake *fakePrism) string {
+	t.Helper()
+
+	if fake != nil {
+		fake.accounts["123456789012"] = &AWSAccount{ID: "acct-1", AccountID: "123456789012", AccountName: "Production"}
+		return "123456789012"
+	}
+	if os.Getenv("PRISM_ACC_AWS_ACCOUNT_IDS") == "" {
+		t.Skip("PRISM_ACC_AWS_ACCOUNT_IDS must be set for account owners acceptance tests")
+	}
+	return strings.Split(os.Getenv("PRISM_ACC_AWS_ACCOUNT_IDS"), ",")[0]
+}
+
+func TestAccAccountOwnersResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	accountID := ownersTestAccount(t, fake)
+	h := newResourceHarness(t, NewAccountOwnersResource(), client)
+
+	// Create
+	state, diags := h.create(testAccountOwnersModel(t, accountID, "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if got := h.attr(state, "id"); got != accountID {
+		t.Errorf("expected id %q, got %q", accountID, got)
+	}
+
+	// Update
+	plan := testAccountOwnersModel(t, accountID, "bob@example.com", "alice@example.com")
+	plan.ID = types.StringValue(accountID)
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read after update: %v", diags)
+	}
+	if got := ownerEmails(h, state); got != "alice@example.com,bob@example.com" {
+		t.Errorf("expected both owners after update, got %s", got)
+	}
+
+	// Import
+	imported, diags := h.importState(accountID)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(imported)
+	if got := ownerEmails(h, imported); got != "alice@example.com,bob@example.com" {
+		t.Errorf("expected imported owners to match, got %s", got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil {
+		if owners := fake.accounts[accountID].OwnerEmails; len(owners) != 0 {
+			t.Errorf("expected owners to be removed, got %v", owners)
+		}
+		if _, ok := fake.accounts[accountID]; !ok {
+			t.Error("expected the account itself to be kept")
+		}
+	}
+}
+
+func TestAccAccountOwnersResource_ChangedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	accountID := ownersTestAccount(t, fake)
+	h := newResourceHarness(t, NewAccountOwnersResource(), client)
+
+	state, diags := h.create(testAccountOwnersModel(t, accountID, "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.accounts[accountID].OwnerEmails = []string{"mallory@example.com"}
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := ownerEmails(h, state); got != "mallory@example.com" {
+		t.Errorf("expected read to pick up the out-of-band owner, got %s", got)
+	}
+}
+
+func TestAccountOwnersResource_BackendLowercasesEmails(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	accountID := ownersTestAccount(t, fake)
+	h := newResourceHarness(t, NewAccountOwnersResource(), client)
+
+	state, diags := h.create(testAccountOwnersModel(t, accountID, "Alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.accounts[accountID].OwnerEmails = []string{"alice@example.com"}
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := ownerEmails(h, state); got != "Alice@example.com" {
+		t.Errorf("expected the configured case to be kept, got %s", got)
+	}
+}
+
+func TestAccAccountOwnersResource_AccountDeboarded(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	accountID := ownersTestAccount(t, fake)
+	h := newResourceHarness(t, NewAccountOwnersResource(), client)
+
+	state, diags := h.create(testAccountOwnersModel(t, accountID, "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	delete(fake.accounts, accountID)
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !state.Raw.IsNull() {
+		t.Error("expected the resource to be removed from state")
+	}
+}
+
+func TestAccountOwnersResource_CoexistsWithAWSAccount(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+
+	accounts := newResourceHarness(t, NewAWSAccountResource(), client)
+	accountState, diags := accounts.create(&AWSAccountResourceModel{
+		ID:          types.StringUnknown(),
+		AccountID:   types.StringValue("123456789012"),
+		AccountName: types.StringValue("Production"),
+		Region:      types.StringNull(),
+		RoleArn:     types.StringNull(),
+		OwnerEmails: types.ListNull(types.StringType),
+		Timeouts:    nullTimeouts("create", "delete"),
+	})
+	if diags.HasError() {
+		t.Fatalf("create account: %v", diags)
+	}
+
+	owners := newResourceHarness(t, NewAccountOwnersResource(), client)
+	if _, diags := owners.create(testAccountOwnersModel(t, "123456789012", "alice@example.com")); diags.HasError() {
+		t.Fatalf("create owners: %v", diags)
+	}
+
+	// prism_aws_account leaves owners it doesn't manage alone, so neither
+	// resource sees drift from the other
+	accountState, diags = accounts.read(accountState)
+	if diags.HasError() {
+		t.Fatalf("read account: %v", diags)
+	}
+	var account AWSAccountResourceModel
+	accounts.get(accountState, &account)
+	if !account.OwnerEmails.IsNull() {
+		t.Errorf("expected prism_aws_account owner_emails to stay unset, got %s", account.OwnerEmails)
+	}
+	if got := fake.accounts["123456789012"].OwnerEmails; len(got) != 1 || got[0] != "alice@example.com" {
+		t.Errorf("expected owners to be kept, got %v", got)
+	}
+
+	// Updating the account, whose PUT replaces it, keeps the owners too
+	account.AccountName = types.StringValue("Production EU")
+	if _, diags := accounts.update(accountState, &account); diags.HasError() {
+		t.Fatalf("update account: %v", diags)
+	}
+	if got := fake.accounts["123456789012"]; got.AccountName != "Production EU" || len(got.OwnerEmails) != 1 || got.OwnerEmails[0] != "alice@example.com" {
+		t.Errorf("expected the rename to keep owners [alice@example.com], got %q with %v", got.AccountName, got.OwnerEmails)
+	}
+}
@@ -6,16 +6,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &AWSAccountResource{}
 var _ resource.ResourceWithImportState = &AWSAccountResource{}
+var _ resource.ResourceWithModifyPlan = &AWSAccountResource{}
 
 func NewAWSAccountResource() resource.Resource {
 	return &AWSAccountResource{}
@@ -25,6 +29,12 @@ type AWSAccountResource struct {
 	client *Client
 }
 
+// Default timeouts for onboarding and deboarding an account
+const (
+	awsAccountCreateTimeout = 10 * time.Minute
+	awsAccountDeleteTimeout = 10 * time.Minute
+)
+
 type AWSAccountResourceModel struct {
 	ID          types.String `tfsdk:"id"`
 	AccountID   types.String `tfsdk:"account_id"`
@@ -32,6 +42,7 @@ type AWSAccountResourceModel struct {
 	Region      types.String `tfsdk:"region"`
 	RoleArn     types.String `tfsdk:"role_arn"`
 	OwnerEmails types.List   `tfsdk:"owner_emails"`
+	Timeouts    types.Object `tfsdk:"timeouts"`
 }
 
 func (r *AWSAccountResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -59,7 +70,7 @@ func (r *AWSAccountResource) Schema(ctx context.Context, req resource.SchemaRequ
 			},
 			"account_name": schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "A friendly name for the AWS account",
+				MarkdownDescription: "A friendly name for the AWS account. The backend trims and title-cases names, so a name that differs from the stored one only in case or whitespace is kept as configured",
 			},
 			"region": schema.StringAttribute{
 				Optional:            true,
@@ -73,9 +84,17 @@ func (r *AWSAccountResource) Schema(ctx context.Context, req resource.SchemaRequ
 			"owner_emails": schema.ListAttribute{
 				Optional:            true,
 				ElementType:         types.StringType,
-				MarkdownDescription: "List of owner email addresses for JIT (Just-In-Time) access approvals",
+				MarkdownDescription: "List of owner email addresses for JIT (Just-In-Time) access approvals. Leave unset to manage owners with `prism_account_owners` instead. Requires Prism 2.3 or later; older backends ignore it, and plans warn about that once the provider has made a request in the run. A plan that makes none, like one that only creates resources or runs with `-refresh=false`, can't check the version and doesn't warn.",
+				Validators:          []validator.List{emailsValidator{}},
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"create": awsAccountCreateTimeout,
+				"delete": awsAccountDeleteTimeout,
+			}),
+		},
 	}
 }
 
@@ -96,6 +115,21 @@ func (r *AWSAccountResource) Configure(ctx context.Context, req resource.Configu
 	r.client = client
 }
 
+// ModifyPlan warns when owner_emails is set but the backend reports a
+// version that ignores it.
+func (r *AWSAccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var ownerEmails types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("owner_emails"), &ownerEmails)...)
+	if resp.Diagnostics.HasError() || ownerEmails.IsNull() {
+		return
+	}
+	r.client.warnUnsupportedFeature(&resp.Diagnostics, path.Root("owner_emails"), featureOwnerEmails)
+}
+
 func (r *AWSAccountResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data AWSAccountResourceModel
 
@@ -116,17 +150,31 @@ func (r *AWSAccountResource) Create(ctx context.Context, req resource.CreateRequ
 
 	account := &AWSAccount{
 		AccountID:   data.AccountID.ValueString(),
-		AccountName: data.AccountName.ValueString(),
+		AccountName: normalizeAccountName(data.AccountName.ValueString()),
 		Region:      data.Region.ValueString(),
 		RoleArn:     data.RoleArn.ValueString(),
 		OwnerEmails: ownerEmails,
 	}
 
-	created, err := r.client.CreateAWSAccount(account)
+	createTimeout, diags := resolveTimeout(data.Timeouts, "create", awsAccountCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	created, err := r.client.CreateAWSAccount(ctx, account)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create AWS account, got error: %s", err))
 		return
 	}
+	// Onboarding only sends the account ID, name, and owners
+	r.verifyApplied(&AWSAccount{
+		AccountID:   account.AccountID,
+		AccountName: account.AccountName,
+		OwnerEmails: account.OwnerEmails,
+	}, !data.OwnerEmails.IsNull(), &resp.Diagnostics)
 
 	// Set ID from API response
 	data.ID = types.StringValue(created.ID)
@@ -138,7 +186,7 @@ func (r *AWSAccountResource) Create(ctx context.Context, req resource.CreateRequ
 
 	// Only update account_name if API returned a non-empty value, otherwise preserve plan value
 	if created.AccountName != "" {
-		data.AccountName = types.StringValue(created.AccountName)
+		data.AccountName = accountNameValue(data.AccountName, created.AccountName)
 	}
 
 	// Only update region if API returned a non-empty value
@@ -155,16 +203,10 @@ func (r *AWSAccountResource) Create(ctx context.Context, req resource.CreateRequ
 		data.RoleArn = types.StringValue(defaultRoleArn)
 	}
 
-	// Set owner_emails from API response
-	if len(created.OwnerEmails) > 0 {
-		ownerEmailsList, diags := types.ListValueFrom(ctx, types.StringType, created.OwnerEmails)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.OwnerEmails = ownerEmailsList
-	} else {
-		data.OwnerEmails = types.ListNull(types.StringType)
+	// Set owner_emails from API response, unless they are left unset here
+	// because a prism_account_owners resource manages them
+	if !data.OwnerEmails.IsNull() {
+		data.OwnerEmails = ownerEmailsList(data.OwnerEmails, created.OwnerEmails)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -184,11 +226,12 @@ func (r *AWSAccountResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Only update account_name if API returned a non-empty value, otherwise preserve state value
-	if account.AccountName != "" {
-		data.AccountName = types.StringValue(account.AccountName)
+	if account.ID != "" {
+		data.ID = types.StringValue(account.ID)
 	}
 
+	data.AccountName = accountNameValue(data.AccountName, account.AccountName)
+
 	// Only update region if API returned a non-empty value
 	if account.Region != "" {
 		data.Region = types.StringValue(account.Region)
@@ -203,16 +246,10 @@ func (r *AWSAccountResource) Read(ctx context.Context, req resource.ReadRequest,
 		data.RoleArn = types.StringValue(defaultRoleArn)
 	}
 
-	// Set owner_emails from API response
-	if len(account.OwnerEmails) > 0 {
-		ownerEmailsList, diags := types.ListValueFrom(ctx, types.StringType, account.OwnerEmails)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.OwnerEmails = ownerEmailsList
-	} else {
-		data.OwnerEmails = types.ListNull(types.StringType)
+	// Set owner_emails from API response, unless they are left unset here
+	// because a prism_account_owners resource manages them
+	if !data.OwnerEmails.IsNull() {
+		data.OwnerEmails = ownerEmailsList(data.OwnerEmails, account.OwnerEmails)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -236,9 +273,27 @@ func (r *AWSAccountResource) Update(ctx context.Context, req resource.UpdateRequ
 		}
 	}
 
+	// The update replaces the whole account, so send back the current
+	// owners when a prism_account_owners resource manages them. A backend
+	// without the owners endpoint has none to keep
+	if data.OwnerEmails.IsNull() {
+		current, err := r.client.getAWSAccountOwners(data.AccountID.ValueString(), requestOptions{uncached: true})
+		if err != nil {
+			if !isNotFoundError(err) && !isNotImplementedError(err) {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read AWS account owners, got error: %s", err))
+				return
+			}
+			tflog.Debug(ctx, "AWS account owners are unavailable, updating the account without them", map[string]interface{}{
+				"account_id": data.AccountID.ValueString(),
+				"error":      err.Error(),
+			})
+		}
+		ownerEmails = current
+	}
+
 	account := &AWSAccount{
 		AccountID:   data.AccountID.ValueString(),
-		AccountName: data.AccountName.ValueString(),
+		AccountName: normalizeAccountName(data.AccountName.ValueString()),
 		Region:      data.Region.ValueString(),
 		RoleArn:     data.RoleArn.ValueString(),
 		OwnerEmails: ownerEmails,
@@ -249,10 +304,11 @@ func (r *AWSAccountResource) Update(ctx context.Context, req resource.UpdateRequ
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update AWS account, got error: %s", err))
 		return
 	}
+	r.verifyApplied(account, !data.OwnerEmails.IsNull(), &resp.Diagnostics)
 
 	// Only update account_name if API returned a non-empty value, otherwise preserve plan value
 	if updated.AccountName != "" {
-		data.AccountName = types.StringValue(updated.AccountName)
+		data.AccountName = accountNameValue(data.AccountName, updated.AccountName)
 	}
 
 	// Only update region if API returned a non-empty value
@@ -269,21 +325,65 @@ func (r *AWSAccountResource) Update(ctx context.Context, req resource.UpdateRequ
 		data.RoleArn = types.StringValue(defaultRoleArn)
 	}
 
-	// Set owner_emails from API response
-	if len(updated.OwnerEmails) > 0 {
-		ownerEmailsList, diags := types.ListValueFrom(ctx, types.StringType, updated.OwnerEmails)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.OwnerEmails = ownerEmailsList
-	} else {
-		data.OwnerEmails = types.ListNull(types.StringType)
+	// Set owner_emails from API response, unless they are left unset here
+	// because a prism_account_owners resource manages them
+	if !data.OwnerEmails.IsNull() {
+		data.OwnerEmails = ownerEmailsList(data.OwnerEmails, updated.OwnerEmails)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// verifyApplied reads the account back and warns about any field whose value
+// differs from what was sent. Owners are only checked when this resource
+// manages them.
+func (r *AWSAccountResource) verifyApplied(sent *AWSAccount, checkOwners bool, diags *diag.Diagnostics) {
+	owner := fmt.Sprintf("AWS account %q", sent.AccountID)
+	remote, err := r.client.GetAWSAccount(sent.AccountID)
+	if err != nil {
+		addVerifyError(diags, owner, err)
+		return
+	}
+
+	var mismatches fieldMismatches
+	if !accountNamesEqual(sent.AccountName, remote.AccountName) {
+		mismatches.compareString("account_name", sent.AccountName, remote.AccountName)
+	}
+	if sent.Region != "" {
+		mismatches.compareString("region", sent.Region, remote.Region)
+	}
+	if sent.RoleArn != "" {
+		mismatches.compareString("role_arn", sent.RoleArn, remote.RoleArn)
+	}
+	if checkOwners {
+		mismatches.compareStrings("owner_emails", normalizeEmails(sent.OwnerEmails), normalizeEmails(remote.OwnerEmails))
+	}
+
+	mismatches.addWarning(diags, owner)
+}
+
+// normalizeAccountName trims name and collapses runs of whitespace inside it,
+// as the backend does when it stores a name.
+func normalizeAccountName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// accountNamesEqual reports whether two account names are the same once
+// normalized, ignoring case.
+func accountNamesEqual(a, b string) bool {
+	return strings.EqualFold(normalizeAccountName(a), normalizeAccountName(b))
+}
+
+// accountNameValue returns the account_name to store for the backend's name,
+// keeping current when it is the same name in other case or spacing so the
+// configured value doesn't show a diff.
+func accountNameValue(current types.String, remote string) types.String {
+	if !current.IsNull() && !current.IsUnknown() && accountNamesEqual(current.ValueString(), remote) {
+		return current
+	}
+	return types.StringValue(remote)
+}
+
 func (r *AWSAccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data AWSAccountResourceModel
 
@@ -294,10 +394,16 @@ func (r *AWSAccountResource) Delete(ctx context.Context, req resource.DeleteRequ
 
 	accountID := data.AccountID.ValueString()
 
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", awsAccountDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Before deleting the account, we need to delete all permission set assignments
 	// that reference this account. This handles cases where Terraform's dependency
 	// graph doesn't capture the relationship (e.g., hardcoded account IDs)
-	assignments, err := r.client.ListPermissionSetAssignments()
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered("", "", "")
 	if err != nil {
 		// Log warning but continue - if we can't list assignments, try to delete anyway
 		resp.Diagnostics.AddWarning(
@@ -305,22 +411,27 @@ func (r *AWSAccountResource) Delete(ctx context.Context, req resource.DeleteRequ
 			fmt.Sprintf("Could not list permission set assignments before deleting account. If assignments exist, account deletion may fail: %s", err),
 		)
 	} else {
-		// Find and delete all assignments for this account
-		var deleteErrors []string
-		var deletedIDs []string
-
+		// Find all assignments for this account
+		var accountAssignments []PermissionSetAssignment
+		var assignmentIDs []string
 		for _, assignment := range assignments {
 			if assignment.AccountID == accountID {
-				err := r.client.DeletePermissionSetAssignment(assignment.ID)
-				if err != nil {
-					// Collect errors but continue trying to delete other assignments
-					deleteErrors = append(deleteErrors, fmt.Sprintf("assignment %s: %s", assignment.ID, err.Error()))
-				} else {
-					deletedIDs = append(deletedIDs, assignment.ID)
-				}
+				accountAssignments = append(accountAssignments, assignment)
+				assignmentIDs = append(assignmentIDs, assignment.ID)
 			}
 		}
 
+		if len(accountAssignments) > 0 && !r.client.CleanupAssignmentsOnDelete {
+			resp.Diagnostics.AddError(
+				"Active Permission Set Assignments",
+				activeAssignmentsDetail(fmt.Sprintf("AWS account %s", accountID),
+					fmt.Sprintf("%q in account_ids", accountID), accountAssignments),
+			)
+			return
+		}
+
+		deletedIDs, deleteErrors := deleteAssignments(r.client, assignmentIDs)
+
 		if len(deleteErrors) > 0 {
 			resp.Diagnostics.AddWarning(
 				"Failed to Delete Some Assignments",
@@ -330,52 +441,23 @@ func (r *AWSAccountResource) Delete(ctx context.Context, req resource.DeleteRequ
 		}
 
 		if len(deletedIDs) > 0 {
-			resp.Diagnostics.AddWarning(
-				"Automatic Assignment Cleanup",
-				fmt.Sprintf("Automatically deleted %d permission set assignment(s) for account %s before deleting the account. This may affect other Terraform resources if they manage these assignments.",
-					len(deletedIDs), accountID),
-			)
+			addCleanupWarning(ctx, &resp.Diagnostics, fmt.Sprintf("AWS account %s", accountID), accountAssignments, deletedIDs)
 
 			// Wait for assignments to be fully deleted (backend processes asynchronously)
-			// Poll for up to 30 seconds to verify assignments are gone
-			maxWaitTime := 30 * time.Second
-			pollInterval := 2 * time.Second
-			startTime := time.Now()
-
-			for time.Since(startTime) < maxWaitTime {
-				// Check if assignments still exist
-				stillExists := false
-				for _, deletedID := range deletedIDs {
-					_, err := r.client.GetPermissionSetAssignment(deletedID)
-					if err == nil {
-						// Assignment still exists
-						stillExists = true
-						break
-					}
-					// 404 means it's gone, which is what we want
-					if !strings.Contains(err.Error(), "404") && !strings.Contains(err.Error(), "not found") {
-						// Some other error - log it but continue
-						resp.Diagnostics.AddWarning(
-							"Error Checking Assignment Status",
-							fmt.Sprintf("Could not verify assignment %s was deleted: %s", deletedID, err),
-						)
-					}
-				}
-
-				if !stillExists {
-					// All assignments are deleted
-					break
-				}
-
-				// Wait before next poll
-				time.Sleep(pollInterval)
+			gone, err := waitForAssignmentsDeleted(ctx, r.client, deletedIDs, deleteTimeout)
+			if ctx.Err() != nil {
+				addDeleteCancelledError(ctx, &resp.Diagnostics, fmt.Sprintf("AWS account %s", accountID))
+				return
 			}
-
-			// Final check - if assignments still exist after waiting, warn the user
-			if time.Since(startTime) >= maxWaitTime {
+			if err != nil {
+				resp.Diagnostics.AddWarning(
+					"Error Checking Assignment Status",
+					fmt.Sprintf("Could not verify assignments were deleted: %s", err),
+				)
+			} else if !gone {
 				resp.Diagnostics.AddWarning(
 					"Assignment Deletion Timeout",
-					fmt.Sprintf("Waited %v for assignments to be deleted but they may still be processing. Account deletion may fail.", maxWaitTime),
+					fmt.Sprintf("Waited %v for assignments to be deleted but they may still be processing. Account deletion may fail.", deleteTimeout),
 				)
 			}
 		}
@@ -387,6 +469,20 @@ func (r *AWSAccountResource) Delete(ctx context.Context, req resource.DeleteRequ
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete AWS account, got error: %s", err))
 		return
 	}
+
+	// Deboarding finishes in the background, and onboarding the same account
+	// ID fails until it has, so wait for the account to be gone
+	waitCtx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if err := waitForDeletion(waitCtx, "aws_account", accountID, func() error {
+		_, err := r.client.getAWSAccount(accountID, requestOptions{uncached: true})
+		return err
+	}); err != nil {
+		resp.Diagnostics.AddWarning(
+			"Account Deboarding Not Finished",
+			fmt.Sprintf("AWS account %s was deboarded, but could not be confirmed deleted: %s. Onboarding it again may fail until deboarding finishes.", accountID, err),
+		)
+	}
 }
 
 func (r *AWSAccountResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
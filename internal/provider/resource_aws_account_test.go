@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAWSAccountResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.accounts["123456789012"] = &AWSAccount{ID: "acct-42", AccountID: "123456789012", AccountName: "Production", Region: "us-east-1"}
+
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+	state, diags := h.importState("123456789012")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+
+	if got := h.attr(state, "id"); got != "acct-42" {
+		t.Errorf("expected id acct-42, got %q", got)
+	}
+	if got := h.attr(state, "account_name"); got != "Production" {
+		t.Errorf("expected account_name Production, got %q", got)
+	}
+}
+
+func testAWSAccountModel(accountID, name string) *AWSAccountResourceModel {
+	return &AWSAccountResourceModel{
+		ID:          types.StringUnknown(),
+		AccountID:   types.StringValue(accountID),
+		AccountName: types.StringValue(name),
+		Region:      types.StringNull(),
+		RoleArn:     types.StringNull(),
+		OwnerEmails: types.ListNull(types.StringType),
+		Timeouts:    nullTimeouts("create", "delete"),
+	}
+}
+
+func TestAWSAccountResource_WarnsWhenFieldsAreNotApplied(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, dropRequestFields(t, fake, "ownerEmails", "region"))
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+
+	// Create: onboarding ignores the owners
+	plan := testAWSAccountModel("123456789012", "Production")
+	plan.OwnerEmails = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("owner@example.com")})
+	state, diags := h.create(plan)
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	detail := diagnosticDetail(diags, "Fields Not Applied")
+	if !strings.Contains(detail, `AWS account "123456789012"`) ||
+		!strings.Contains(detail, `owner_emails: sent ["owner@example.com"], got []`) {
+		t.Errorf("expected a warning naming owner_emails, got %q", detail)
+	}
+
+	// Update: the account name is applied but the region is not
+	plan = testAWSAccountModel("123456789012", "Prod")
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.Region = types.StringValue("eu-west-1")
+	plan.RoleArn = types.StringValue(h.attr(state, "role_arn"))
+	_, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	detail = diagnosticDetail(diags, "Fields Not Applied")
+	if !strings.Contains(detail, `region: sent "eu-west-1", got ""`) {
+		t.Errorf("expected a warning naming region, got %q", detail)
+	}
+	if strings.Contains(detail, "account_name") || strings.Contains(detail, "owner_emails") {
+		t.Errorf("expected only region in the warning, got %q", detail)
+	}
+}
+
+func TestAWSAccountResource_OwnersManagedElsewhereAreNotVerified(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, fake)
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+
+	state, diags := h.create(testAWSAccountModel("123456789012", "Production"))
+	if diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected create without warnings, got %v", diags)
+	}
+
+	// A prism_account_owners resource sets the owners
+	fake.accounts["123456789012"].OwnerEmails = []string{"owner@example.com"}
+
+	plan := testAWSAccountModel("123456789012", "Prod")
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.RoleArn = types.StringValue(h.attr(state, "role_arn"))
+	if _, diags := h.update(state, plan); diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected update without warnings, got %v", diags)
+	}
+}
+
+func TestAWSAccountResource_UpdateWithoutOwnersEndpoint(t *testing.T) {
+	for name, status := range map[string]int{"not found": http.StatusNotFound, "not implemented": http.StatusNotImplemented} {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakePrism()
+			ownersRequests := 0
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// A backend from before the owners sub-resource
+				if strings.HasSuffix(r.URL.Path, "/owners") {
+					ownersRequests++
+					writeAPIError(w, status, "not found")
+					return
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			h := newResourceHarness(t, NewAWSAccountResource(), client)
+
+			state, diags := h.create(testAWSAccountModel("123456789012", "Production"))
+			if diags.HasError() {
+				t.Fatalf("create: %v", diags)
+			}
+
+			plan := testAWSAccountModel("123456789012", "Prod")
+			plan.ID = types.StringValue(h.attr(state, "id"))
+			plan.RoleArn = types.StringValue(h.attr(state, "role_arn"))
+			if _, diags := h.update(state, plan); diags.HasError() {
+				t.Fatalf("expected the update to succeed without the owners endpoint, got %v", diags)
+			}
+			if ownersRequests == 0 {
+				t.Error("expected the update to ask for the owners")
+			}
+			if got := fake.accounts["123456789012"].AccountName; got != "Prod" {
+				t.Errorf("expected account_name Prod, got %q", got)
+			}
+		})
+	}
+}
+
+func TestAWSAccountResource_ReadDetectsRenamedAccount(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, fake)
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+
+	state, diags := h.create(testAWSAccountModel("123456789012", "Production"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.accounts["123456789012"].AccountName = "Renamed"
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "account_name"); got != "Renamed" {
+		t.Errorf("expected account_name Renamed, got %q", got)
+	}
+
+	// An empty name is drift too, not a reason to keep the old one
+	fake.accounts["123456789012"].AccountName = ""
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "account_name"); got != "" {
+		t.Errorf("expected an empty account_name, got %q", got)
+	}
+}
+
+func TestAWSAccountResource_NameNormalizedByBackend(t *testing.T) {
+	fake := newFakePrism()
+	fake.titleCaseAccountNames = true
+	h := newResourceHarness(t, NewAWSAccountResource(), newTestClient(t, fake))
+
+	// The backend stores "Prod Payments", which is the same name
+	state, diags := h.create(testAWSAccountModel("123456789012", "prod  payments "))
+	if diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected create without warnings, got %v", diags)
+	}
+	if got := fake.accounts["123456789012"].AccountName; got != "Prod Payments" {
+		t.Fatalf("expected the backend to store Prod Payments, got %q", got)
+	}
+	if got := h.attr(state, "account_name"); got != "prod  payments " {
+		t.Errorf("expected the configured account_name to be kept, got %q", got)
+	}
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "account_name"); got != "prod  payments " {
+		t.Errorf("expected the configured account_name to survive a refresh, got %q", got)
+	}
+
+	// A case-only change to the configuration is kept as configured too
+	plan := testAWSAccountModel("123456789012", "PROD PAYMENTS")
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.RoleArn = types.StringValue(h.attr(state, "role_arn"))
+	state, diags = h.update(state, plan)
+	if diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected update without warnings, got %v", diags)
+	}
+	if got := h.attr(state, "account_name"); got != "PROD PAYMENTS" {
+		t.Errorf("expected account_name PROD PAYMENTS, got %q", got)
+	}
+
+	// A real rename is still drift
+	fake.accounts["123456789012"].AccountName = "Prod Billing"
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "account_name"); got != "Prod Billing" {
+		t.Errorf("expected account_name Prod Billing, got %q", got)
+	}
+}
+
+func TestAccountNamesEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"prod payments", "Prod Payments", true},
+		{"prod payments ", "Prod Payments", true},
+		{"  prod \t payments", "prod payments", true},
+		{"prod payments", "prod-payments", false},
+		{"prod", "production", false},
+	}
+	for _, tt := range tests {
+		if got := accountNamesEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("accountNamesEqual(%q, %q): expected %t, got %t", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestAWSAccountResource_DeleteWaitsForDeboard(t *testing.T) {
+	tests := map[string]struct {
+		lingeringGets int
+		wantGets      int
+	}{
+		"immediate": {lingeringGets: 0, wantGets: 1},
+		"slow":      {lingeringGets: 1, wantGets: 2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakePrism()
+			fake.accounts["123456789012"] = &AWSAccount{ID: "acct-42", AccountID: "123456789012", AccountName: "Production"}
+
+			// The account stays visible for lingeringGets after the deboard
+			lingering := 0
+			handler, requests := recordRequestURIs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && lingering > 0 {
+					lingering--
+					writeAPIData(w, AWSAccount{ID: "acct-42", AccountID: "123456789012", AccountName: "Production"})
+					return
+				}
+				if strings.HasSuffix(r.URL.Path, "/deboard") {
+					lingering = tt.lingeringGets
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			h := newResourceHarness(t, NewAWSAccountResource(), newTestClient(t, handler))
+
+			state := h.state(&AWSAccountResourceModel{
+				ID:          types.StringValue("acct-42"),
+				AccountID:   types.StringValue("123456789012"),
+				AccountName: types.StringValue("Production"),
+				Region:      types.StringNull(),
+				RoleArn:     types.StringNull(),
+				OwnerEmails: types.ListNull(types.StringType),
+				Timeouts:    nullTimeouts("create", "delete"),
+			})
+			if diags := h.delete(state); diags.HasError() || diags.WarningsCount() > 0 {
+				t.Fatalf("expected delete without warnings, got %v", diags)
+			}
+
+			gets := 0
+			for _, request := range requests() {
+				if request == "GET /api/v1/customers/test/aws-accounts/123456789012" {
+					gets++
+				}
+			}
+			if gets != tt.wantGets {
+				t.Errorf("expected %d GETs of the account, got %d: %v", tt.wantGets, gets, requests())
+			}
+		})
+	}
+}
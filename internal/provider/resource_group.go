@@ -3,13 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &GroupResource{}
@@ -28,8 +33,14 @@ type GroupResourceModel struct {
 	Name        types.String `tfsdk:"name"`
 	Description types.String `tfsdk:"description"`
 	Path        types.String `tfsdk:"path"`
+	RealmRoles  types.List   `tfsdk:"realm_roles"`
+	Timeouts    types.Object `tfsdk:"timeouts"`
 }
 
+// Default timeout for retrying a group delete that conflicts with membership
+// changes still being processed
+const groupDeleteTimeout = 2 * time.Minute
+
 func (r *GroupResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_group"
 }
@@ -60,6 +71,19 @@ func (r *GroupResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Computed:            true,
 				MarkdownDescription: "The path of the group (for hierarchical groups)",
 			},
+			"realm_roles": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of the realm roles mapped to the group, sorted. These drive the group's permissions in the Prism UI. Null if the Prism backend doesn't report role mappings.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"delete": groupDeleteTimeout,
+			}),
 		},
 	}
 }
@@ -105,6 +129,7 @@ func (r *GroupResource) Create(ctx context.Context, req resource.CreateRequest,
 	data.Name = types.StringValue(created.Name)
 	data.Description = types.StringValue(created.Description)
 	data.Path = types.StringValue(created.Path)
+	data.RealmRoles = groupRealmRoles(ctx, r.client, created.ID, &resp.Diagnostics)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -123,17 +148,22 @@ func (r *GroupResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	if group.ID != "" {
+		data.ID = types.StringValue(group.ID)
+	}
 	data.Name = types.StringValue(group.Name)
 	data.Description = types.StringValue(group.Description)
 	data.Path = types.StringValue(group.Path)
+	data.RealmRoles = groupRealmRoles(ctx, r.client, data.ID.ValueString(), &resp.Diagnostics)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data GroupResourceModel
+	var data, state GroupResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -144,7 +174,15 @@ func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest,
 		Path:        data.Path.ValueString(),
 	}
 
-	updated, err := r.client.UpdateGroup(data.Name.ValueString(), group)
+	// The ID route finds the group whatever its name, so a rename and other
+	// changes land together; the name route needs the group's current name
+	var updated *Group
+	var err error
+	if id := state.ID.ValueString(); id != "" {
+		updated, err = r.client.UpdateGroupByID(id, group)
+	} else {
+		updated, err = r.client.UpdateGroup(state.Name.ValueString(), group)
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update group, got error: %s", err))
 		return
@@ -153,6 +191,7 @@ func (r *GroupResource) Update(ctx context.Context, req resource.UpdateRequest,
 	data.Name = types.StringValue(updated.Name)
 	data.Description = types.StringValue(updated.Description)
 	data.Path = types.StringValue(updated.Path)
+	data.RealmRoles = groupRealmRoles(ctx, r.client, state.ID.ValueString(), &resp.Diagnostics)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -165,7 +204,24 @@ func (r *GroupResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	err := r.client.DeleteGroup(data.Name.ValueString())
+	// Remove or report the principal's permission set assignments first, since
+	// the backend refuses to delete principals that still have assignments
+	if !cleanupPrincipalAssignments(ctx, r.client, "GROUP", data.Name.ValueString(), &resp.Diagnostics) {
+		return
+	}
+
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", groupDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := retryOnConflict(ctx, deleteTimeout, fmt.Sprintf("group %q", data.Name.ValueString()), func() error {
+		if id := data.ID.ValueString(); id != "" {
+			return r.client.DeleteGroupByID(id)
+		}
+		return r.client.DeleteGroup(data.Name.ValueString())
+	})
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete group, got error: %s", err))
 		return
@@ -176,3 +232,27 @@ func (r *GroupResource) ImportState(ctx context.Context, req resource.ImportStat
 	// Import using name since that's what Read() uses to fetch the group
 	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
 }
+
+// groupRealmRoles returns the realm roles of the group with groupID for
+// state. Role mappings are informational, so a backend without the
+// role-mappings endpoint leaves them null instead of failing.
+func groupRealmRoles(ctx context.Context, client *Client, groupID string, diags *diag.Diagnostics) types.List {
+	if groupID == "" {
+		return types.ListNull(types.StringType)
+	}
+
+	roles, err := client.GetGroupRoleMappings(groupID)
+	if err != nil {
+		if isNotFoundError(err) || isNotImplementedError(err) {
+			tflog.Debug(ctx, "Group role mappings are unavailable, leaving realm_roles unset", map[string]interface{}{
+				"group_id": groupID,
+				"error":    err.Error(),
+			})
+		} else {
+			addReadError(diags, fmt.Sprintf("read the role mappings of group %s", groupID), err)
+		}
+		return types.ListNull(types.StringType)
+	}
+
+	return convert.StringListOrEmpty(roles)
+}
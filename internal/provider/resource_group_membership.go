@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -25,9 +29,11 @@ type GroupMembershipResource struct {
 }
 
 type GroupMembershipResourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	GroupName types.String `tfsdk:"group_name"`
-	Usernames types.List   `tfsdk:"usernames"`
+	ID                types.String `tfsdk:"id"`
+	GroupName         types.String `tfsdk:"group_name"`
+	Usernames         types.List   `tfsdk:"usernames"`
+	MaxMembersTracked types.Int64  `tfsdk:"max_members_tracked"`
+	MembersTruncated  types.Bool   `tfsdk:"members_truncated"`
 }
 
 func (r *GroupMembershipResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -58,6 +64,17 @@ func (r *GroupMembershipResource) Schema(ctx context.Context, req resource.Schem
 				Required:            true,
 				MarkdownDescription: "List of usernames to add to the group",
 			},
+			"max_members_tracked": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "The most members to record in state. When the group has more, `usernames` only tracks the configured users that are still members, `members_truncated` is set and a warning is shown, and members added outside Terraform are no longer detected. Unset tracks every member",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"members_truncated": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the last read found more members than `max_members_tracked`. Updates are refused while this is set unless `max_members_tracked` is raised above the group's size or removed",
+			},
 		},
 	}
 }
@@ -108,7 +125,7 @@ func (r *GroupMembershipResource) Create(ctx context.Context, req resource.Creat
 			_, err := r.client.GetUser(username)
 			return err
 		}); err != nil {
-			resp.Diagnostics.AddError("Dependency Error", fmt.Sprintf("User dependency not satisfied: %s", err))
+			resp.Diagnostics.AddError("Dependency Error", userDependencyDetail(username, err))
 			return
 		}
 	}
@@ -120,6 +137,7 @@ func (r *GroupMembershipResource) Create(ctx context.Context, req resource.Creat
 	}
 
 	data.ID = types.StringValue(data.GroupName.ValueString())
+	data.MembersTruncated = types.BoolValue(false)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -141,12 +159,26 @@ func (r *GroupMembershipResource) Read(ctx context.Context, req resource.ReadReq
 	// Sort members alphabetically to ensure consistent ordering
 	sort.Strings(members)
 
-	usernamesList, diags := types.ListValueFrom(ctx, types.StringType, members)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	data.MembersTruncated = types.BoolValue(membersExceedCap(members, data.MaxMembersTracked))
+	if data.MembersTruncated.ValueBool() {
+		// Keep only the tracked users that are still members, so a removed
+		// one shows as drift without writing the whole group into state
+		var tracked []string
+		resp.Diagnostics.Append(data.Usernames.ElementsAs(ctx, &tracked, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		members = stillMembers(tracked, members)
+		resp.Diagnostics.AddWarning(
+			"Group Membership Truncated",
+			fmt.Sprintf("Group %q has more than %d members (max_members_tracked), so only the %d tracked users that are still members were recorded. "+
+				"Members added outside Terraform are not detected, and updates to this resource are refused until max_members_tracked is raised above the group's size or removed.",
+				data.GroupName.ValueString(), data.MaxMembersTracked.ValueInt64(), len(members)),
+		)
 	}
-	data.Usernames = usernamesList
+
+	data.Usernames = convert.StringListOrEmpty(members)
+	data.ID = types.StringValue(data.GroupName.ValueString())
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -167,6 +199,16 @@ func (r *GroupMembershipResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	// A truncated read doesn't list every member, so diffing against it
+	// would leave members added outside Terraform in place. Read the whole
+	// group again and diff against that, as long as the plan's cap allows.
+	if state.MembersTruncated.ValueBool() {
+		stateUsernames = r.fullMembers(plan, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Find users to add (in plan but not in state)
 	toAdd := []string{}
 	for _, planUsername := range planUsernames {
@@ -203,7 +245,7 @@ func (r *GroupMembershipResource) Update(ctx context.Context, req resource.Updat
 			_, err := r.client.GetUser(username)
 			return err
 		}); err != nil {
-			resp.Diagnostics.AddError("Dependency Error", fmt.Sprintf("User dependency not satisfied: %s", err))
+			resp.Diagnostics.AddError("Dependency Error", userDependencyDetail(username, err))
 			return
 		}
 	}
@@ -226,9 +268,53 @@ func (r *GroupMembershipResource) Update(ctx context.Context, req resource.Updat
 		}
 	}
 
+	plan.MembersTruncated = types.BoolValue(false)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
+// fullMembers returns every member of plan's group, or an error diagnostic
+// when there are still more than plan's max_members_tracked.
+func (r *GroupMembershipResource) fullMembers(plan GroupMembershipResourceModel, diags *diag.Diagnostics) []string {
+	members, err := r.client.GetGroupMembers(plan.GroupName.ValueString())
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read group members, got error: %s", err))
+		return nil
+	}
+	if membersExceedCap(members, plan.MaxMembersTracked) {
+		diags.AddError(
+			"Group Membership Truncated",
+			fmt.Sprintf("Group %q has %d members, more than max_members_tracked (%d), so its membership can't be managed authoritatively. "+
+				"Raise max_members_tracked above the group's size or remove it to update this resource.",
+				plan.GroupName.ValueString(), len(members), plan.MaxMembersTracked.ValueInt64()),
+		)
+		return nil
+	}
+	return members
+}
+
+// membersExceedCap reports whether members is longer than max, when max is set.
+func membersExceedCap(members []string, max types.Int64) bool {
+	return !max.IsNull() && !max.IsUnknown() && int64(len(members)) > max.ValueInt64()
+}
+
+// stillMembers returns the usernames in tracked that are in members, sorted.
+func stillMembers(tracked, members []string) []string {
+	isMember := make(map[string]bool, len(members))
+	for _, member := range members {
+		isMember[member] = true
+	}
+
+	kept := []string{}
+	for _, username := range tracked {
+		if isMember[username] {
+			kept = append(kept, username)
+		}
+	}
+	sort.Strings(kept)
+	return kept
+}
+
 func (r *GroupMembershipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data GroupMembershipResourceModel
 
@@ -0,0 +1,325 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testGroupMembershipModel(t *testing.T, groupName string, usernames ...string) *GroupMembershipResourceModel {
+	t.Helper()
+
+	list, diags := types.ListValueFrom(context.Background(), types.StringType, usernames)
+	if diags.HasError() {
+		t.Fatalf("building usernames: %v", diags)
+	}
+	return &GroupMembershipResourceModel{
+		ID:                types.StringUnknown(),
+		GroupName:         types.StringValue(groupName),
+		Usernames:         list,
+		MaxMembersTracked: types.Int64Null(),
+		MembersTruncated:  types.BoolUnknown(),
+	}
+}
+
+// membershipUsernames returns the usernames in state, comma-joined.
+func membershipUsernames(h *resourceHarness, state tfsdk.State) string {
+	var data GroupMembershipResourceModel
+	h.get(state, &data)
+
+	var usernames []string
+	data.Usernames.ElementsAs(context.Background(), &usernames, false)
+	return strings.Join(usernames, ",")
+}
+
+func sortedJoin(values ...string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// createMembershipDependencies creates a group and users, removing them when
+// the test finishes.
+func createMembershipDependencies(t *testing.T, client *Client, groupName string, usernames ...string) {
+	t.Helper()
+
+	users := newResourceHarness(t, NewUserResource(), client)
+	for _, username := range usernames {
+		userState, diags := users.create(testUserModel(username, username+"@example.com"))
+		if diags.HasError() {
+			t.Fatalf("create user %s: %v", username, diags)
+		}
+		t.Cleanup(func() { users.delete(userState) })
+	}
+
+	groups := newResourceHarness(t, NewGroupResource(), client)
+	groupState, diags := groups.create(testGroupModel(groupName, ""))
+	if diags.HasError() {
+		t.Fatalf("create group: %v", diags)
+	}
+	t.Cleanup(func() { groups.delete(groupState) })
+}
+
+func TestAccGroupMembershipResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	groupName := accName("group")
+	alice, bob, carol := accName("alice"), accName("bob"), accName("carol")
+	createMembershipDependencies(t, client, groupName, alice, bob, carol)
+
+	h := newResourceHarness(t, NewGroupMembershipResource(), client)
+
+	// Create
+	state, diags := h.create(testGroupMembershipModel(t, groupName, alice, bob))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if got := h.attr(state, "id"); got != groupName {
+		t.Errorf("expected id %q, got %q", groupName, got)
+	}
+
+	// Update
+	plan := testGroupMembershipModel(t, groupName, bob, carol)
+	plan.ID = types.StringValue(groupName)
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read after update: %v", diags)
+	}
+	if got, want := membershipUsernames(h, state), sortedJoin(bob, carol); got != want {
+		t.Errorf("expected members %s after update, got %s", want, got)
+	}
+
+	// Import
+	imported, diags := h.importState(groupName)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got, want := membershipUsernames(h, imported), sortedJoin(bob, carol); got != want {
+		t.Errorf("expected imported members %s, got %s", want, got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil && len(fake.members[groupName]) != 0 {
+		t.Errorf("expected no members after destroy, got %v", fake.members[groupName])
+	}
+}
+
+func TestAccGroupMembershipResource_ChangedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	createMembershipDependencies(t, client, "developers", "alice", "bob")
+
+	h := newResourceHarness(t, NewGroupMembershipResource(), client)
+	state, diags := h.create(testGroupMembershipModel(t, "developers", "alice"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.members["developers"] = []string{"bob", "alice"}
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := membershipUsernames(h, state); got != "alice,bob" {
+		t.Errorf("expected read to pick up the out-of-band member, got %s", got)
+	}
+
+	// An emptied group reads back as an empty list, not an unset one
+	delete(fake.members, "developers")
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	var data GroupMembershipResourceModel
+	h.get(state, &data)
+	if data.Usernames.IsNull() || len(data.Usernames.Elements()) != 0 {
+		t.Errorf("expected an empty usernames list, got %s", data.Usernames)
+	}
+}
+
+func TestGroupMembershipResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.groups["developers"] = &Group{ID: "group-42", Name: "developers"}
+	fake.members["developers"] = []string{"bob", "alice"}
+
+	h := newResourceHarness(t, NewGroupMembershipResource(), client)
+	state, diags := h.importState("developers")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+
+	if got := membershipUsernames(h, state); got != "alice,bob" {
+		t.Errorf("expected imported members alice,bob, got %s", got)
+	}
+}
+
+// delayUser wraps handler as a backend where username isn't visible for
+// its first misses lookups, like a user that was just created, and returns
+// a func counting the lookups.
+func delayUser(handler http.Handler, username string, misses int) (http.Handler, func() int) {
+	var lookups int
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/users/"+username) {
+			lookups++
+			if lookups <= misses {
+				writeAPIError(w, http.StatusNotFound, "user not found")
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}), func() int { return lookups }
+}
+
+func TestGroupMembershipResource_WaitsForNewUsers(t *testing.T) {
+	fake := newFakePrism()
+	fake.groups["developers"] = &Group{ID: "group-1", Name: "developers"}
+	for _, username := range []string{"alice", "bob", "carol"} {
+		fake.users[username] = &User{ID: "user-" + username, Username: username}
+	}
+
+	// bob and carol appear on the third poll
+	handler, bobLookups := delayUser(fake, "bob", 2)
+	handler, carolLookups := delayUser(handler, "carol", 2)
+	h := newResourceHarness(t, NewGroupMembershipResource(), newTestClient(t, handler))
+
+	state, diags := h.create(testGroupMembershipModel(t, "developers", "alice", "bob"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if got := bobLookups(); got != 3 {
+		t.Errorf("expected bob to be looked up 3 times, got %d", got)
+	}
+	if got := sortedJoin(fake.members["developers"]...); got != "alice,bob" {
+		t.Errorf("expected members alice,bob, got %s", got)
+	}
+
+	plan := testGroupMembershipModel(t, "developers", "alice", "bob", "carol")
+	plan.ID = types.StringValue("developers")
+	if _, diags := h.update(state, plan); diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	if got := carolLookups(); got != 3 {
+		t.Errorf("expected carol to be looked up 3 times, got %d", got)
+	}
+	if got := sortedJoin(fake.members["developers"]...); got != "alice,bob,carol" {
+		t.Errorf("expected members alice,bob,carol, got %s", got)
+	}
+}
+
+// cappedMembershipState creates a membership of developers tracking alice
+// with max_members_tracked 3, then fills the group to members users.
+func cappedMembershipState(t *testing.T, members int) (*fakePrism, *resourceHarness, tfsdk.State) {
+	t.Helper()
+
+	fake := newFakePrism()
+	fake.groups["developers"] = &Group{ID: "group-1", Name: "developers"}
+	fake.users["alice"] = &User{ID: "user-alice", Username: "alice"}
+	h := newResourceHarness(t, NewGroupMembershipResource(), newTestClient(t, fake))
+
+	plan := testGroupMembershipModel(t, "developers", "alice")
+	plan.MaxMembersTracked = types.Int64Value(3)
+	state, diags := h.create(plan)
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	for i := len(fake.members["developers"]); i < members; i++ {
+		fake.members["developers"] = append(fake.members["developers"], fmt.Sprintf("user%02d", i))
+	}
+	return fake, h, state
+}
+
+func TestGroupMembershipResource_MaxMembersTracked(t *testing.T) {
+	tests := map[string]struct {
+		members       int
+		wantTruncated bool
+		wantUsernames string
+	}{
+		"below the cap": {members: 2, wantUsernames: "alice,user01"},
+		"at the cap":    {members: 3, wantUsernames: "alice,user01,user02"},
+		"over the cap":  {members: 4, wantTruncated: true, wantUsernames: "alice"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, h, state := cappedMembershipState(t, tt.members)
+
+			state, diags := h.read(state)
+			if diags.HasError() {
+				t.Fatalf("read: %v", diags)
+			}
+			if got := hasDiagnostic(diags, "Group Membership Truncated"); got != tt.wantTruncated {
+				t.Errorf("expected truncation warning %t, got %t: %v", tt.wantTruncated, got, diags)
+			}
+			var data GroupMembershipResourceModel
+			h.get(state, &data)
+			if got := data.MembersTruncated.ValueBool(); got != tt.wantTruncated {
+				t.Errorf("expected members_truncated %t, got %t", tt.wantTruncated, got)
+			}
+			if got := membershipUsernames(h, state); got != tt.wantUsernames {
+				t.Errorf("expected usernames %s, got %s", tt.wantUsernames, got)
+			}
+		})
+	}
+}
+
+func TestGroupMembershipResource_TruncatedReadDropsRemovedUsers(t *testing.T) {
+	fake, h, state := cappedMembershipState(t, 5)
+	fake.members["developers"] = fake.members["developers"][1:]
+
+	state, diags := h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := membershipUsernames(h, state); got != "" {
+		t.Errorf("expected alice to drop out of state once removed, got %s", got)
+	}
+}
+
+func TestGroupMembershipResource_UpdateRefusesTruncatedMembership(t *testing.T) {
+	fake, h, state := cappedMembershipState(t, 5)
+	fake.users["bob"] = &User{ID: "user-bob", Username: "bob"}
+	state, diags := h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+
+	plan := testGroupMembershipModel(t, "developers", "alice", "bob")
+	plan.ID = types.StringValue("developers")
+	plan.MaxMembersTracked = types.Int64Value(3)
+	_, diags = h.update(state, plan)
+	if !hasDiagnostic(diags, "Group Membership Truncated") {
+		t.Fatalf("expected the update to be refused, got %v", diags)
+	}
+	if got := len(fake.members["developers"]); got != 5 {
+		t.Errorf("expected the group to be left alone, got %d members", got)
+	}
+
+	// Raising the cap above the group's size manages it authoritatively again
+	plan.MaxMembersTracked = types.Int64Value(10)
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	if got := sortedJoin(fake.members["developers"]...); got != "alice,bob" {
+		t.Errorf("expected only alice and bob to remain, got %s", got)
+	}
+	var data GroupMembershipResourceModel
+	h.get(state, &data)
+	if data.MembersTruncated.ValueBool() {
+		t.Error("expected members_truncated to be cleared")
+	}
+}
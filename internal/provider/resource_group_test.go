@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testGroupModel(name, description string) *GroupResourceModel {
+	return &GroupResourceModel{
+		ID:          types.StringUnknown(),
+		Name:        types.StringValue(name),
+		Description: types.StringValue(description),
+		Path:        types.StringValue(""),
+		RealmRoles:  types.ListUnknown(types.StringType),
+		Timeouts:    nullTimeouts("delete"),
+	}
+}
+
+func TestAccGroupResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	h := newResourceHarness(t, NewGroupResource(), client)
+	name := accName("group")
+
+	// Create
+	state, diags := h.create(testGroupModel(name, "Initial"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if h.attr(state, "id") == "" {
+		t.Error("expected id to be set after create")
+	}
+
+	// Update
+	plan := testGroupModel(name, "Updated")
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+
+	// Import
+	imported, diags := h.importState(name)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got := h.attr(imported, "description"); got != "Updated" {
+		t.Errorf("expected imported description %q, got %q", "Updated", got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil {
+		if _, ok := fake.groups[name]; ok {
+			t.Error("expected group to be deleted")
+		}
+	}
+}
+
+func TestAccGroupResource_ChangedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewGroupResource(), client)
+
+	state, diags := h.create(testGroupModel("developers", "Initial"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.groups["developers"].Description = "Edited in the console"
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "description"); got != "Edited in the console" {
+		t.Errorf("expected read to pick up the out-of-band change, got %q", got)
+	}
+
+	// Realm roles mapped in the console show up on the next read
+	fake.realmRoles[fake.groups["developers"].ID] = []string{"prism-viewer"}
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	var data GroupResourceModel
+	h.get(state, &data)
+	if data.RealmRoles.String() != `["prism-viewer"]` {
+		t.Errorf("expected read to pick up the realm role, got %s", data.RealmRoles)
+	}
+}
+
+func TestGroupDelete_CleansUpAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewGroupResource(), client)
+
+	state, diags := h.create(testGroupModel("developers", ""))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if len(fake.assignments) != 0 {
+		t.Errorf("expected assignments to be deleted, got %d", len(fake.assignments))
+	}
+	if _, ok := fake.groups["developers"]; ok {
+		t.Error("expected group to be deleted")
+	}
+}
+
+func TestGroupDelete_ProtectsAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	client.CleanupAssignmentsOnDelete = false
+	h := newResourceHarness(t, NewGroupResource(), client)
+
+	state, diags := h.create(testGroupModel("developers", ""))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if !hasDiagnostic(diags, "Active Permission Set Assignments") {
+		t.Fatalf("expected an Active Permission Set Assignments error, got %v", diags)
+	}
+	if _, ok := fake.groups["developers"]; !ok {
+		t.Error("expected group to be kept")
+	}
+}
+
+func TestGroupResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.groups["developers"] = &Group{ID: "group-42", Name: "developers", Description: "Developers", Path: "/developers"}
+
+	h := newResourceHarness(t, NewGroupResource(), client)
+	state, diags := h.importState("developers")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+
+	if got := h.attr(state, "id"); got != "group-42" {
+		t.Errorf("expected id group-42, got %q", got)
+	}
+	if got := h.attr(state, "description"); got != "Developers" {
+		t.Errorf("expected description Developers, got %q", got)
+	}
+}
+
+func TestGroupResource_UpdateAfterRename(t *testing.T) {
+	fake := newFakePrism()
+	handler, requests := recordRequestURIs(fake)
+	h := newResourceHarness(t, NewGroupResource(), newTestClient(t, handler))
+
+	state, diags := h.create(testGroupModel("developers", "Initial"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := h.attr(state, "id")
+
+	// The rename and the description change land in one update
+	plan := testGroupModel("engineers", "Renamed")
+	plan.ID = types.StringValue(id)
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	if _, ok := fake.groups["developers"]; ok {
+		t.Error("expected the old name to be gone")
+	}
+	if group := fake.groups["engineers"]; group == nil || group.ID != id || group.Description != "Renamed" {
+		t.Errorf("expected %s renamed to engineers with description Renamed, got %+v", id, group)
+	}
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read after rename: %v", diags)
+	}
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if len(fake.groups) != 0 {
+		t.Errorf("expected the group to be deleted, got %v", fake.groups)
+	}
+
+	var writes []string
+	for _, request := range requests() {
+		if strings.HasPrefix(request, "PUT ") || strings.HasPrefix(request, "DELETE ") {
+			writes = append(writes, request)
+		}
+	}
+	want := []string{
+		"PUT /api/v1/customers/test/groups/by-id/" + id,
+		"DELETE /api/v1/customers/test/groups/by-id/" + id,
+	}
+	if strings.Join(writes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected writes by ID %v, got %v", want, writes)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/compositeid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -13,6 +16,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -28,12 +32,14 @@ type IdentityProviderResource struct {
 }
 
 type IdentityProviderResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	Type        types.String `tfsdk:"type"`
-	Alias       types.String `tfsdk:"alias"`
-	DisplayName types.String `tfsdk:"display_name"`
-	Enabled     types.Bool   `tfsdk:"enabled"`
-	Config      types.String `tfsdk:"config"`
+	ID              types.String `tfsdk:"id"`
+	Type            types.String `tfsdk:"type"`
+	Alias           types.String `tfsdk:"alias"`
+	DisplayName     types.String `tfsdk:"display_name"`
+	Enabled         types.Bool   `tfsdk:"enabled"`
+	Config          types.String `tfsdk:"config"`
+	ConfigWO        types.String `tfsdk:"config_wo"`
+	ConfigWOVersion types.Int64  `tfsdk:"config_wo_version"`
 }
 
 func (r *IdentityProviderResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -80,9 +86,28 @@ func (r *IdentityProviderResource) Schema(ctx context.Context, req resource.Sche
 				MarkdownDescription: "Whether the identity provider is enabled",
 			},
 			"config": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "JSON configuration for the identity provider (includes client ID, client secret, etc.)",
+				MarkdownDescription: "JSON configuration for the identity provider (includes client ID, client secret, etc.). Stored in state; on Terraform 1.11 and later prefer `config_wo`. Exactly one of `config` or `config_wo` must be set.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("config_wo")),
+				},
+			},
+			"config_wo": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Write-only JSON configuration for the identity provider. Sent to the API but never stored in state. Requires Terraform 1.11 or later. Change `config_wo_version` to apply a new value.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("config_wo_version")),
+				},
+			},
+			"config_wo_version": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Version of `config_wo`. Terraform cannot detect changes to write-only values, so increment this to send an updated `config_wo` to the API.",
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("config_wo")),
+				},
 			},
 		},
 	}
@@ -113,10 +138,9 @@ func (r *IdentityProviderResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
-	// Parse config JSON
-	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(data.Config.ValueString()), &config); err != nil {
-		resp.Diagnostics.AddError("Invalid Configuration", fmt.Sprintf("Unable to parse config JSON: %s", err))
+	config, diags := identityProviderConfig(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -133,8 +157,13 @@ func (r *IdentityProviderResource) Create(ctx context.Context, req resource.Crea
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create identity provider, got error: %s", err))
 		return
 	}
+	r.verifyApplied(idp, created.Alias, &resp.Diagnostics)
 
 	data.ID = types.StringValue(created.ID)
+	if created.ID == "" {
+		// The API doesn't return an ID, so identify the provider by its alias
+		data.ID = types.StringValue(created.Alias)
+	}
 
 	// Always use the alias returned by the API - it's auto-generated based on type
 	// Backend hardcodes: google -> "google", microsoft -> "microsoft", etc.
@@ -153,6 +182,9 @@ func (r *IdentityProviderResource) Create(ctx context.Context, req resource.Crea
 	// Keep the original planned config value to avoid drift on sensitive fields
 	// data.Config already contains the planned value from earlier in this function
 
+	// Write-only values must never be persisted
+	data.ConfigWO = types.StringNull()
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -170,12 +202,23 @@ func (r *IdentityProviderResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
+	if idp.Alias != "" {
+		data.Alias = types.StringValue(idp.Alias)
+	}
+	// The API doesn't return an ID, so identify the provider by its alias
+	if data.ID.ValueString() == "" {
+		data.ID = data.Alias
+	}
+
 	if idp.DisplayName != "" {
 		data.DisplayName = types.StringValue(idp.DisplayName)
 	}
 
-	// Preserve enabled from state - API may not properly return this field
-	// Keep the existing state value in data.Enabled
+	// Preserve enabled from state - API may not properly return this field.
+	// On import there is no state value to preserve, so take the API value.
+	if data.Enabled.IsNull() {
+		data.Enabled = types.BoolValue(idp.Enabled)
+	}
 
 	// API doesn't return sensitive config fields (clientId, clientSecret, etc.)
 	// Keep the existing state config value to avoid drift on sensitive fields
@@ -192,9 +235,9 @@ func (r *IdentityProviderResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal([]byte(data.Config.ValueString()), &config); err != nil {
-		resp.Diagnostics.AddError("Invalid Configuration", fmt.Sprintf("Unable to parse config JSON: %s", err))
+	config, diags := identityProviderConfig(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -210,6 +253,8 @@ func (r *IdentityProviderResource) Update(ctx context.Context, req resource.Upda
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update identity provider, got error: %s", err))
 		return
 	}
+	idp.Type = data.Type.ValueString()
+	r.verifyApplied(idp, data.Alias.ValueString(), &resp.Diagnostics)
 
 	if updated.DisplayName != "" {
 		data.DisplayName = types.StringValue(updated.DisplayName)
@@ -222,9 +267,33 @@ func (r *IdentityProviderResource) Update(ctx context.Context, req resource.Upda
 	// Keep the planned config value to avoid drift on sensitive fields
 	// data.Config already contains the planned value from earlier in this function
 
+	// Write-only values must never be persisted
+	data.ConfigWO = types.StringNull()
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// verifyApplied reads the identity provider back and warns about any field
+// whose value differs from what was sent, since Create and Update keep the
+// planned enabled value. Config is not checked because the API never
+// returns secrets.
+func (r *IdentityProviderResource) verifyApplied(sent *IdentityProvider, alias string, diags *diag.Diagnostics) {
+	owner := fmt.Sprintf("Identity provider %q", alias)
+	remote, err := r.client.GetIdentityProvider(sent.Type, alias)
+	if err != nil {
+		addVerifyError(diags, owner, err)
+		return
+	}
+
+	var mismatches fieldMismatches
+	if sent.DisplayName != "" {
+		mismatches.compareString("display_name", sent.DisplayName, remote.DisplayName)
+	}
+	mismatches.compareBool("enabled", sent.Enabled, remote.Enabled)
+
+	mismatches.addWarning(diags, owner)
+}
+
 func (r *IdentityProviderResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data IdentityProviderResourceModel
 
@@ -241,5 +310,43 @@ func (r *IdentityProviderResource) Delete(ctx context.Context, req resource.Dele
 }
 
 func (r *IdentityProviderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// Import using type or type:alias since Read() fetches the provider by type
+	parts, err := compositeid.Decode(req.ID, ':')
+	if err != nil || len(parts) > 2 || parts[0] == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form type or type:alias (e.g. google or google:google), with any %% or : in the alias written as %%25 or %%3A, got: %q", req.ID),
+		)
+		return
+	}
+	idpType, alias := parts[0], parts[0]
+	if len(parts) == 2 && parts[1] != "" {
+		alias = parts[1]
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("type"), idpType)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("alias"), alias)...)
+}
+
+// identityProviderConfig parses the identity provider configuration from
+// either config or, since write-only values are always null in the plan,
+// config_wo read from the raw configuration.
+func identityProviderConfig(ctx context.Context, cfg tfsdk.Config, data IdentityProviderResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	raw := data.Config
+	if raw.IsNull() {
+		diags.Append(cfg.GetAttribute(ctx, path.Root("config_wo"), &raw)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(raw.ValueString()), &config); err != nil {
+		diags.AddError("Invalid Configuration", fmt.Sprintf("Unable to parse config JSON: %s", err))
+		return nil, diags
+	}
+
+	return config, diags
 }
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// identityProviderHandler serves the identity provider endpoints for a single
+// Google provider and records each request body sent to create or update it.
+func identityProviderHandler(t *testing.T, bodies *[]map[string]interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/identity-providers/google") {
+			writeAPIError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body: %s", err)
+			}
+			*bodies = append(*bodies, body)
+		}
+
+		// Like the real backend, never echo the client secret back
+		writeAPIData(w, map[string]interface{}{
+			"identityProvider": map[string]interface{}{
+				"alias":       "google",
+				"displayName": "Sign in with Google",
+				"enabled":     true,
+				"config":      map[string]string{"clientId": "client"},
+			},
+		})
+	})
+}
+
+func testIdentityProviderModel(config, configWO string, version int64) *IdentityProviderResourceModel {
+	data := &IdentityProviderResourceModel{
+		ID:              types.StringUnknown(),
+		Type:            types.StringValue("google"),
+		Alias:           types.StringUnknown(),
+		DisplayName:     types.StringValue("Sign in with Google"),
+		Enabled:         types.BoolValue(true),
+		Config:          types.StringNull(),
+		ConfigWO:        types.StringNull(),
+		ConfigWOVersion: types.Int64Null(),
+	}
+	if config != "" {
+		data.Config = types.StringValue(config)
+	}
+	if configWO != "" {
+		data.ConfigWO = types.StringValue(configWO)
+		data.ConfigWOVersion = types.Int64Value(version)
+	}
+	return data
+}
+
+func TestIdentityProviderResource_WriteOnlyConfig(t *testing.T) {
+	var bodies []map[string]interface{}
+	client := newTestClient(t, identityProviderHandler(t, &bodies))
+	h := newResourceHarness(t, NewIdentityProviderResource(), client)
+
+	// Create
+	state, diags := h.create(testIdentityProviderModel("", `{"clientId": "client", "clientSecret": "first"}`, 1))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if len(bodies) != 1 || bodies[0]["clientSecret"] != "first" {
+		t.Fatalf("expected the write-only secret to reach the API on create, got %v", bodies)
+	}
+	assertIdentityProviderSecretsAbsent(t, state)
+
+	// Update with a bumped version
+	plan := testIdentityProviderModel("", `{"clientId": "client", "clientSecret": "second"}`, 2)
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.Alias = types.StringValue(h.attr(state, "alias"))
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	if len(bodies) != 2 || bodies[1]["clientSecret"] != "second" {
+		t.Fatalf("expected the new write-only secret to reach the API on update, got %v", bodies)
+	}
+	assertIdentityProviderSecretsAbsent(t, state)
+
+	var version types.Int64
+	state.GetAttribute(context.Background(), path.Root("config_wo_version"), &version)
+	if version.ValueInt64() != 2 {
+		t.Errorf("expected config_wo_version 2 in state, got %s", version)
+	}
+
+	// Refresh
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	assertIdentityProviderSecretsAbsent(t, state)
+}
+
+func TestIdentityProviderResource_Config(t *testing.T) {
+	var bodies []map[string]interface{}
+	client := newTestClient(t, identityProviderHandler(t, &bodies))
+	h := newResourceHarness(t, NewIdentityProviderResource(), client)
+
+	config := `{"clientId": "client", "clientSecret": "secret"}`
+	state, diags := h.create(testIdentityProviderModel(config, "", 0))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if len(bodies) != 1 || bodies[0]["clientSecret"] != "secret" {
+		t.Fatalf("expected the secret to reach the API, got %v", bodies)
+	}
+	// Terraform versions without write-only support keep using config, which
+	// is stored in state as before
+	if got := h.attr(state, "config"); got != config {
+		t.Errorf("expected config to be kept in state, got %q", got)
+	}
+}
+
+// assertIdentityProviderSecretsAbsent checks that neither config attribute
+// holds a value and that no client secret appears anywhere in state.
+func assertIdentityProviderSecretsAbsent(t *testing.T, state tfsdk.State) {
+	t.Helper()
+
+	for _, name := range []string{"config", "config_wo"} {
+		var value types.String
+		if diags := state.GetAttribute(context.Background(), path.Root(name), &value); diags.HasError() {
+			t.Fatalf("reading %s: %v", name, diags)
+		}
+		if !value.IsNull() {
+			t.Errorf("expected %s to be null in state, got %q", name, value.ValueString())
+		}
+	}
+	if raw := state.Raw.String(); strings.Contains(raw, "clientSecret") {
+		t.Errorf("expected no client secret in state, got %s", raw)
+	}
+}
+
+func TestIdentityProviderResource_Import(t *testing.T) {
+	var bodies []map[string]interface{}
+	client := newTestClient(t, identityProviderHandler(t, &bodies))
+
+	for _, importID := range []string{"google", "google:google"} {
+		t.Run(importID, func(t *testing.T) {
+			h := newResourceHarness(t, NewIdentityProviderResource(), client)
+			state, diags := h.importState(importID)
+			if diags.HasError() {
+				t.Fatalf("import: %v", diags)
+			}
+			h.assertImportComplete(state)
+
+			var data IdentityProviderResourceModel
+			h.get(state, &data)
+			if data.ID.ValueString() != "google" || data.Alias.ValueString() != "google" {
+				t.Errorf("expected id and alias google, got %s and %s", data.ID, data.Alias)
+			}
+			if data.DisplayName.ValueString() != "Sign in with Google" || !data.Enabled.ValueBool() {
+				t.Errorf("expected display_name and enabled from the API, got %s and %s", data.DisplayName, data.Enabled)
+			}
+		})
+	}
+}
+
+func TestIdentityProviderResource_ImportInvalidID(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+	h := newResourceHarness(t, NewIdentityProviderResource(), client)
+
+	for _, id := range []string{":google", "google:a:b", "google:a%3"} {
+		if _, diags := h.importState(id); !hasDiagnostic(diags, "Invalid Import ID") {
+			t.Errorf("%q: expected an Invalid Import ID error, got %v", id, diags)
+		}
+	}
+}
+
+func TestIdentityProviderResource_WarnsWhenFieldsAreNotApplied(t *testing.T) {
+	var bodies []map[string]interface{}
+	// The handler always reports the provider as enabled
+	client := newTestClient(t, identityProviderHandler(t, &bodies))
+	h := newResourceHarness(t, NewIdentityProviderResource(), client)
+
+	plan := testIdentityProviderModel(`{"clientId": "client", "clientSecret": "secret"}`, "", 0)
+	plan.Enabled = types.BoolValue(false)
+	state, diags := h.create(plan)
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	detail := diagnosticDetail(diags, "Fields Not Applied")
+	if !strings.Contains(detail, `Identity provider "google"`) || !strings.Contains(detail, "enabled: sent false, got true") {
+		t.Errorf("expected a warning naming enabled, got %q", detail)
+	}
+	if strings.Contains(detail, "secret") {
+		t.Errorf("expected the warning not to mention config, got %q", detail)
+	}
+
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.Alias = types.StringValue(h.attr(state, "alias"))
+	plan.Enabled = types.BoolValue(true)
+	if _, diags := h.update(state, plan); diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected update without warnings, got %v", diags)
+	}
+}
@@ -3,9 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -16,6 +20,8 @@ import (
 
 var _ resource.Resource = &PermissionSetResource{}
 var _ resource.ResourceWithImportState = &PermissionSetResource{}
+var _ resource.ResourceWithValidateConfig = &PermissionSetResource{}
+var _ resource.ResourceWithModifyPlan = &PermissionSetResource{}
 
 func NewPermissionSetResource() resource.Resource {
 	return &PermissionSetResource{}
@@ -25,6 +31,17 @@ type PermissionSetResource struct {
 	client *Client
 }
 
+// Default timeout for deleting a permission set, including the wait for its
+// assignments to be removed
+const permissionSetDeleteTimeout = 5 * time.Minute
+
+// Most managed policies the backend allows on one permission set
+const maxManagedPolicies = 10
+
+// policyARNPattern matches AWS managed and customer managed IAM policy ARNs
+// in the standard, GovCloud and China partitions.
+var policyARNPattern = regexp.MustCompile(`^arn:aws[\-a-z]*:iam::(aws|\d{12}):policy/.+$`)
+
 type PermissionSetResourceModel struct {
 	ID              types.String `tfsdk:"id"`
 	Name            types.String `tfsdk:"name"`
@@ -32,6 +49,11 @@ type PermissionSetResourceModel struct {
 	SessionDuration types.String `tfsdk:"session_duration"`
 	ManagedPolicies types.List   `tfsdk:"managed_policies"`
 	InlinePolicies  types.Map    `tfsdk:"inline_policies"`
+	Timeouts        types.Object `tfsdk:"timeouts"`
+
+	CreatedAt          types.String `tfsdk:"created_at"`
+	UpdatedAt          types.String `tfsdk:"updated_at"`
+	ProvisioningStatus types.String `tfsdk:"provisioning_status"`
 }
 
 func (r *PermissionSetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -63,16 +85,123 @@ func (r *PermissionSetResource) Schema(ctx context.Context, req resource.SchemaR
 				MarkdownDescription: "The session duration in ISO 8601 format (e.g., PT4H for 4 hours)",
 			},
 			"managed_policies": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				MarkdownDescription: "List of AWS managed policy ARNs to attach",
+				ElementType: types.StringType,
+				Optional:    true,
+				MarkdownDescription: "List of AWS managed policy ARNs to attach, at most 10. A permission set needs at least one managed or inline policy. " +
+					"Leave unset to attach policies with `prism_permission_set_managed_policy_attachment` instead; the permission set then leaves its managed policies as they are.",
 			},
 			"inline_policies": schema.MapAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				MarkdownDescription: "Map of inline IAM policy documents in JSON format. The key is the policy name, and the value is the policy document.",
+				ElementType: types.StringType,
+				Optional:    true,
+				MarkdownDescription: "Map of inline IAM policy documents in JSON format. The key is the policy name, and the value is the policy document. " +
+					"Leave unset to manage inline policies with `prism_permission_set_inline_policy` instead; the permission set then leaves its inline policies as they are.",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the permission set was created, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the permission set last changed, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+			},
+			"provisioning_status": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the permission set's policies are fully provisioned to its accounts, as reported by the Prism backend. Null if the backend doesn't report it.",
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"delete": permissionSetDeleteTimeout,
+			}),
+		},
+	}
+}
+
+// ValidateConfig catches policy combinations the backend rejects at plan
+// time: a permission set without any policy, more than maxManagedPolicies
+// managed policies, or the same managed policy twice. Unknown values skip the
+// checks that depend on them.
+func (r *PermissionSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data PermissionSetResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.ManagedPolicies.IsUnknown() && !data.InlinePolicies.IsUnknown() &&
+		len(data.ManagedPolicies.Elements()) == 0 && len(data.InlinePolicies.Elements()) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("managed_policies"),
+			"Missing Policies",
+			"A permission set needs at least one policy. Set managed_policies, inline_policies, or both.",
+		)
+	}
+
+	if data.ManagedPolicies.IsNull() || data.ManagedPolicies.IsUnknown() {
+		return
+	}
+
+	policies := data.ManagedPolicies.Elements()
+	if len(policies) > maxManagedPolicies {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("managed_policies"),
+			"Too Many Managed Policies",
+			fmt.Sprintf("A permission set can have at most %d managed policies, got %d. Combine the extra permissions into an inline policy.",
+				maxManagedPolicies, len(policies)),
+		)
+	}
+
+	seen := make(map[string]int, len(policies))
+	for i, element := range policies {
+		policy, ok := element.(types.String)
+		if !ok || policy.IsNull() || policy.IsUnknown() {
+			continue
+		}
+		if first, ok := seen[policy.ValueString()]; ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("managed_policies").AtListIndex(i),
+				"Duplicate Managed Policy",
+				fmt.Sprintf("Element %d, %q, repeats element %d. Remove one of them.", i, policy.ValueString(), first),
+			)
+			continue
+		}
+		seen[policy.ValueString()] = i
+	}
+}
+
+// ModifyPlan checks that the planned managed policies look like IAM policy
+// ARNs, unless the provider's validate_policy_arns is false. It runs here
+// rather than as a schema validator because validators run before the
+// provider is configured.
+func (r *PermissionSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil || !r.client.ValidatePolicyARNs {
+		return
+	}
+
+	var managedPolicies types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("managed_policies"), &managedPolicies)...)
+	if resp.Diagnostics.HasError() || managedPolicies.IsNull() || managedPolicies.IsUnknown() {
+		return
+	}
+
+	for i, element := range managedPolicies.Elements() {
+		policy, ok := element.(types.String)
+		if !ok || policy.IsNull() || policy.IsUnknown() {
+			continue
+		}
+		if !policyARNPattern.MatchString(policy.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("managed_policies").AtListIndex(i),
+				"Invalid Managed Policy ARN",
+				fmt.Sprintf("Element %d, %q, is not an IAM policy ARN such as \"arn:aws:iam::aws:policy/ReadOnlyAccess\" or \"arn:aws:iam::123456789012:policy/MyPolicy\". "+
+					"If it's valid in a partition this check doesn't know, set validate_policy_arns = false in the provider configuration.", i, policy.ValueString()),
+			)
+		}
 	}
 }
 
@@ -140,26 +269,16 @@ func (r *PermissionSetResource) Create(ctx context.Context, req resource.CreateR
 		data.SessionDuration = types.StringValue(created.SessionDuration)
 	}
 
-	// Convert managed policies back to list
-	if len(created.ManagedPolicies) > 0 {
-		managedPoliciesList, diags := types.ListValueFrom(ctx, types.StringType, created.ManagedPolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.ManagedPolicies = managedPoliciesList
+	// Keep the planned policies when the API doesn't return them
+	if len(created.ManagedPolicies) > 0 && !data.ManagedPolicies.IsNull() {
+		data.ManagedPolicies = convert.StringListOrNull(created.ManagedPolicies)
 	}
-
-	// Convert inline policies back to map
-	if len(created.InlinePolicies) > 0 {
-		inlinePoliciesMap, diags := types.MapValueFrom(ctx, types.StringType, created.InlinePolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.InlinePolicies = inlinePoliciesMap
+	if len(created.InlinePolicies) > 0 && !data.InlinePolicies.IsNull() {
+		data.InlinePolicies = convert.StringMapOrNull(created.InlinePolicies)
 	}
 
+	data.CreatedAt, data.UpdatedAt, data.ProvisioningStatus = permissionSetStatus(created)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -176,6 +295,7 @@ func (r *PermissionSetResource) Read(ctx context.Context, req resource.ReadReque
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set, got error: %s", err))
 		return
 	}
+	importing := data.Name.IsNull()
 
 	data.Name = types.StringValue(permSet.Name)
 	data.Description = types.StringValue(permSet.Description)
@@ -183,27 +303,49 @@ func (r *PermissionSetResource) Read(ctx context.Context, req resource.ReadReque
 		data.SessionDuration = types.StringValue(permSet.SessionDuration)
 	}
 
-	if len(permSet.ManagedPolicies) > 0 {
-		managedPoliciesList, diags := types.ListValueFrom(ctx, types.StringType, permSet.ManagedPolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.ManagedPolicies = managedPoliciesList
+	// Without managed_policies or inline_policies those policies are left to
+	// prism_permission_set_managed_policy_attachment and
+	// prism_permission_set_inline_policy resources. A resource being
+	// imported has no name yet, and takes the policies it has
+	if !data.ManagedPolicies.IsNull() || importing {
+		data.ManagedPolicies = convert.KeepEmptyList(data.ManagedPolicies, convert.StringListOrNull(permSet.ManagedPolicies))
 	}
-
-	if len(permSet.InlinePolicies) > 0 {
-		inlinePoliciesMap, diags := types.MapValueFrom(ctx, types.StringType, permSet.InlinePolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.InlinePolicies = inlinePoliciesMap
+	if !data.InlinePolicies.IsNull() && !importing {
+		warnUnlistedInlinePolicies(data, permSet, &resp.Diagnostics)
+	}
+	if !data.InlinePolicies.IsNull() || importing {
+		data.InlinePolicies = convert.KeepEmptyMap(data.InlinePolicies, convert.StringMapOrNull(permSet.InlinePolicies))
 	}
 
+	data.CreatedAt, data.UpdatedAt, data.ProvisioningStatus = permissionSetStatus(permSet)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// warnUnlistedInlinePolicies warns about inline policies of permSet that
+// the inline_policies in state doesn't hold, such as ones added by a
+// prism_permission_set_inline_policy, which the next apply removes.
+func warnUnlistedInlinePolicies(data PermissionSetResourceModel, permSet *PermissionSet, diags *diag.Diagnostics) {
+	listed := data.InlinePolicies.Elements()
+	var unlisted []string
+	for name := range permSet.InlinePolicies {
+		if _, ok := listed[name]; !ok {
+			unlisted = append(unlisted, name)
+		}
+	}
+	if len(unlisted) == 0 {
+		return
+	}
+	sort.Strings(unlisted)
+
+	diags.AddAttributeWarning(
+		path.Root("inline_policies"),
+		"Inline Policies Not in Configuration",
+		fmt.Sprintf("Permission set %q has inline policies that inline_policies doesn't list: %s. The next apply removes them. %s",
+			permSet.Name, strings.Join(unlisted, ", "), inlinePolicyOwnershipHint),
+	)
+}
+
 func (r *PermissionSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data PermissionSetResourceModel
 
@@ -212,12 +354,28 @@ func (r *PermissionSetResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	// Policies left unset are managed by attachment resources: write back
+	// what the permission set has, holding off those resources until the
+	// update is done
+	var current *PermissionSet
+	if data.ManagedPolicies.IsNull() || data.InlinePolicies.IsNull() {
+		defer r.client.permissionSetWrites.lock(data.ID.ValueString())()
+		var err error
+		current, err = r.client.getPermissionSetUncached(ctx, data.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set before update, got error: %s", err))
+			return
+		}
+	}
+
 	var managedPolicies []string
 	if !data.ManagedPolicies.IsNull() {
 		resp.Diagnostics.Append(data.ManagedPolicies.ElementsAs(ctx, &managedPolicies, false)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
+	} else {
+		managedPolicies = current.ManagedPolicies
 	}
 
 	// Convert inline policies map
@@ -227,6 +385,8 @@ func (r *PermissionSetResource) Update(ctx context.Context, req resource.UpdateR
 		if resp.Diagnostics.HasError() {
 			return
 		}
+	} else {
+		inlinePolicies = current.InlinePolicies
 	}
 
 	permSet := &PermissionSet{
@@ -249,24 +409,16 @@ func (r *PermissionSetResource) Update(ctx context.Context, req resource.UpdateR
 		data.SessionDuration = types.StringValue(updated.SessionDuration)
 	}
 
-	if len(updated.ManagedPolicies) > 0 {
-		managedPoliciesList, diags := types.ListValueFrom(ctx, types.StringType, updated.ManagedPolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.ManagedPolicies = managedPoliciesList
+	// Keep the planned policies when the API doesn't return them
+	if len(updated.ManagedPolicies) > 0 && !data.ManagedPolicies.IsNull() {
+		data.ManagedPolicies = convert.StringListOrNull(updated.ManagedPolicies)
 	}
-
-	if len(updated.InlinePolicies) > 0 {
-		inlinePoliciesMap, diags := types.MapValueFrom(ctx, types.StringType, updated.InlinePolicies)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.InlinePolicies = inlinePoliciesMap
+	if len(updated.InlinePolicies) > 0 && !data.InlinePolicies.IsNull() {
+		data.InlinePolicies = convert.StringMapOrNull(updated.InlinePolicies)
 	}
 
+	data.CreatedAt, data.UpdatedAt, data.ProvisioningStatus = permissionSetStatus(updated)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -280,32 +432,37 @@ func (r *PermissionSetResource) Delete(ctx context.Context, req resource.DeleteR
 
 	permissionSetID := data.ID.ValueString()
 
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", permissionSetDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Before deleting the permission set, delete all assignments that use it
 	// This prevents the "permission set has active assignments" error
-	assignments, err := r.client.ListPermissionSetAssignments()
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered(permissionSetID, "", "")
 	if err != nil {
 		// Log warning but continue - if we can't list assignments, try to delete anyway
 		resp.Diagnostics.AddWarning(
 			"Unable to List Assignments",
 			fmt.Sprintf("Could not list permission set assignments before deleting permission set. If assignments exist, deletion may fail: %s", err),
 		)
+	} else if len(assignments) > 0 && !r.client.CleanupAssignmentsOnDelete {
+		resp.Diagnostics.AddError(
+			"Active Permission Set Assignments",
+			activeAssignmentsDetail(fmt.Sprintf("Permission set %q", permissionSetID),
+				fmt.Sprintf("permission_set_id = %q", permissionSetID), assignments),
+		)
+		return
 	} else {
-		// Find and delete all assignments for this permission set
-		var deleteErrors []string
-		var deletedIDs []string
-
+		// Delete all assignments for this permission set
+		var assignmentIDs []string
 		for _, assignment := range assignments {
-			if assignment.PermissionSetID == permissionSetID {
-				err := r.client.DeletePermissionSetAssignment(assignment.ID)
-				if err != nil {
-					// Collect errors but continue trying to delete other assignments
-					deleteErrors = append(deleteErrors, fmt.Sprintf("assignment %s: %s", assignment.ID, err.Error()))
-				} else {
-					deletedIDs = append(deletedIDs, assignment.ID)
-				}
-			}
+			assignmentIDs = append(assignmentIDs, assignment.ID)
 		}
 
+		deletedIDs, deleteErrors := deleteAssignments(r.client, assignmentIDs)
+
 		if len(deleteErrors) > 0 {
 			resp.Diagnostics.AddWarning(
 				"Failed to Delete Some Assignments",
@@ -315,52 +472,23 @@ func (r *PermissionSetResource) Delete(ctx context.Context, req resource.DeleteR
 		}
 
 		if len(deletedIDs) > 0 {
-			resp.Diagnostics.AddWarning(
-				"Automatic Assignment Cleanup",
-				fmt.Sprintf("Automatically deleted %d permission set assignment(s) before deleting the permission set. This may affect other Terraform resources if they manage these assignments.",
-					len(deletedIDs)),
-			)
+			addCleanupWarning(ctx, &resp.Diagnostics, fmt.Sprintf("Permission set %q", permissionSetID), assignments, deletedIDs)
 
 			// Wait for assignments to be fully deleted (backend processes asynchronously)
-			// Poll for up to 30 seconds to verify assignments are gone
-			maxWaitTime := 30 * time.Second
-			pollInterval := 2 * time.Second
-			startTime := time.Now()
-
-			for time.Since(startTime) < maxWaitTime {
-				// Check if assignments still exist
-				stillExists := false
-				for _, deletedID := range deletedIDs {
-					_, err := r.client.GetPermissionSetAssignment(deletedID)
-					if err == nil {
-						// Assignment still exists
-						stillExists = true
-						break
-					}
-					// 404 means it's gone, which is what we want
-					if !strings.Contains(err.Error(), "404") && !strings.Contains(err.Error(), "not found") {
-						// Some other error - log it but continue
-						resp.Diagnostics.AddWarning(
-							"Error Checking Assignment Status",
-							fmt.Sprintf("Could not verify assignment %s was deleted: %s", deletedID, err),
-						)
-					}
-				}
-
-				if !stillExists {
-					// All assignments are deleted
-					break
-				}
-
-				// Wait before next poll
-				time.Sleep(pollInterval)
+			gone, err := waitForAssignmentsDeleted(ctx, r.client, deletedIDs, deleteTimeout)
+			if ctx.Err() != nil {
+				addDeleteCancelledError(ctx, &resp.Diagnostics, fmt.Sprintf("Permission set %q", permissionSetID))
+				return
 			}
-
-			// Final check - if assignments still exist after waiting, warn the user
-			if time.Since(startTime) >= maxWaitTime {
+			if err != nil {
+				resp.Diagnostics.AddWarning(
+					"Error Checking Assignment Status",
+					fmt.Sprintf("Could not verify assignments were deleted: %s", err),
+				)
+			} else if !gone {
 				resp.Diagnostics.AddWarning(
 					"Assignment Deletion Timeout",
-					fmt.Sprintf("Waited %v for assignments to be deleted but they may still be processing. Permission set deletion may fail.", maxWaitTime),
+					fmt.Sprintf("Waited %v for assignments to be deleted but they may still be processing. Permission set deletion may fail.", deleteTimeout),
 				)
 			}
 		}
@@ -377,3 +505,26 @@ func (r *PermissionSetResource) Delete(ctx context.Context, req resource.DeleteR
 func (r *PermissionSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
+
+// permissionSetStatus returns the created_at, updated_at and
+// provisioning_status values of permSet, which are null where the backend
+// doesn't send them.
+func permissionSetStatus(permSet *PermissionSet) (createdAt, updatedAt, provisioningStatus types.String) {
+	return timestampValue(permSet.CreatedAt), timestampValue(permSet.UpdatedAt), optionalStringValue(permSet.ProvisioningStatus)
+}
+
+// timestampValue returns t in RFC 3339 format, or null if it's nil.
+func timestampValue(t *time.Time) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(t.Format(time.RFC3339))
+}
+
+// optionalStringValue returns s, or null if it's empty.
+func optionalStringValue(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
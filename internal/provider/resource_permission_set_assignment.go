@@ -3,20 +3,30 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/compositeid"
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &PermissionSetAssignmentResource{}
 var _ resource.ResourceWithImportState = &PermissionSetAssignmentResource{}
+var _ resource.ResourceWithUpgradeState = &PermissionSetAssignmentResource{}
 
 func NewPermissionSetAssignmentResource() resource.Resource {
 	return &PermissionSetAssignmentResource{}
@@ -26,12 +36,19 @@ type PermissionSetAssignmentResource struct {
 	client *Client
 }
 
+// Default timeout for creating an assignment, including waiting for the
+// permission set, accounts, and principal to become available
+const permissionSetAssignmentCreateTimeout = 5 * time.Minute
+
 type PermissionSetAssignmentResourceModel struct {
-	ID              types.String `tfsdk:"id"`
-	PermissionSetID types.String `tfsdk:"permission_set_id"`
-	PrincipalType   types.String `tfsdk:"principal_type"`
-	PrincipalID     types.String `tfsdk:"principal_id"`
-	AccountIDs      types.List   `tfsdk:"account_ids"`
+	ID                types.String `tfsdk:"id"`
+	PermissionSetID   types.String `tfsdk:"permission_set_id"`
+	PrincipalType     types.String `tfsdk:"principal_type"`
+	PrincipalID       types.String `tfsdk:"principal_id"`
+	ResolvedPrincipal types.String `tfsdk:"resolved_principal"`
+	AccountIDs        types.List   `tfsdk:"account_ids"`
+	AssignmentIDs     types.Map    `tfsdk:"assignment_ids"`
+	Timeouts          types.Object `tfsdk:"timeouts"`
 }
 
 func (r *PermissionSetAssignmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -39,7 +56,13 @@ func (r *PermissionSetAssignmentResource) Metadata(ctx context.Context, req reso
 }
 
 func (r *PermissionSetAssignmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = schema.Schema{
+	resp.Schema = permissionSetAssignmentSchema()
+	// Version 1: id holds comma-separated backend assignment IDs
+	resp.Schema.Version = 1
+}
+
+func permissionSetAssignmentSchema() schema.Schema {
+	return schema.Schema{
 		MarkdownDescription: "Assigns a permission set to a user or group for a specific AWS account.",
 
 		Attributes: map[string]schema.Attribute{
@@ -52,7 +75,7 @@ func (r *PermissionSetAssignmentResource) Schema(ctx context.Context, req resour
 			},
 			"permission_set_id": schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "The ID of the permission set to assign",
+				MarkdownDescription: "The ID of the permission set to assign, e.g. `prism_permission_set.example.id`. A permission set name is rejected",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -69,18 +92,102 @@ func (r *PermissionSetAssignmentResource) Schema(ctx context.Context, req resour
 			},
 			"principal_id": schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "The ID or email of the user/group",
+				MarkdownDescription: "The username or group name of the principal. When `principal_type` is `USER`, this may also be the email address of the user, which is resolved to their username",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"resolved_principal": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The username or group name `principal_id` resolves to. It differs from `principal_id` only when that is a user's email address",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"account_ids": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Required:            true,
-				MarkdownDescription: "List of AWS account IDs to grant access to",
+				MarkdownDescription: "List of AWS account IDs to grant access to. Each account may only be listed once",
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"assignment_ids": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "The backend assignment ID of each account in `account_ids`, keyed by account ID",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"create": permissionSetAssignmentCreateTimeout,
+			}),
+		},
+	}
+}
+
+func (r *PermissionSetAssignmentResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	priorSchema := permissionSetAssignmentSchema()
+
+	return map[int64]resource.StateUpgrader{
+		// Version 0 states may hold the legacy descriptive ID
+		// (permission_set_id:principal_type:principal_id:account1,account2)
+		// written by older provider releases. Resolve it into backend assignment IDs.
+		0: {
+			PriorSchema:   &priorSchema,
+			StateUpgrader: r.upgradeStateV0,
+		},
+	}
+}
+
+func (r *PermissionSetAssignmentResource) upgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var data PermissionSetAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, principalType, principalID, accountIDs, ok := parseLegacyAssignmentID(data.ID.ValueString())
+	if !ok {
+		// Already in the current format
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
 	}
+
+	if r.client == nil {
+		resp.Diagnostics.AddError(
+			"Unconfigured Client",
+			"Unable to upgrade permission set assignment state: the provider client is not configured.",
+		)
+		return
+	}
+
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered(permSetID, principalType, principalID)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list permission set assignments while upgrading state, got error: %s", err))
+		return
+	}
+
+	assignmentIDs, missingAccountIDs := matchAssignmentIDs(assignments, permSetID, principalType, principalID, accountIDs)
+	if len(missingAccountIDs) > 0 {
+		resp.Diagnostics.AddWarning(
+			"Assignment Not Found",
+			fmt.Sprintf("Could not find assignments for accounts %s while upgrading state for %q. They may have been deleted outside Terraform.",
+				strings.Join(missingAccountIDs, ", "), data.ID.ValueString()),
+		)
+	}
+
+	if len(assignmentIDs) > 0 {
+		data.ID = types.StringValue(strings.Join(assignmentIDs, ","))
+	}
+	// Otherwise keep the legacy ID; the next Read finds nothing and removes the resource
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *PermissionSetAssignmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -121,15 +228,21 @@ func (r *PermissionSetAssignmentResource) Create(ctx context.Context, req resour
 		AccountIDs:      accountIDs,
 	}
 
-	// Set principal name based on type
-	if data.PrincipalType.ValueString() == "USER" {
-		assignment.Username = data.PrincipalID.ValueString()
-	} else if data.PrincipalType.ValueString() == "GROUP" {
-		assignment.GroupName = data.PrincipalID.ValueString()
+	createTimeout, diags := resolveTimeout(data.Timeouts, "create", permissionSetAssignmentCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
-	// Wait for dependencies to become available before creating
 	permSetID := data.PermissionSetID.ValueString()
+	r.checkPermissionSetID(ctx, permSetID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Wait for dependencies to become available before creating
 	if err := waitForDependency(ctx, "permission_set", permSetID, func() error {
 		_, err := r.client.GetPermissionSet(permSetID)
 		return err
@@ -150,12 +263,17 @@ func (r *PermissionSetAssignmentResource) Create(ctx context.Context, req resour
 
 	principalID := data.PrincipalID.ValueString()
 	principalType := data.PrincipalType.ValueString()
+	resolvedPrincipal := principalID
 	if principalType == "USER" {
 		if err := waitForDependency(ctx, "user", principalID, func() error {
-			_, err := r.client.GetUser(principalID)
+			var err error
+			if resolvedPrincipal, err = r.resolvePrincipal(principalType, principalID); err != nil {
+				return err
+			}
+			_, err = r.client.GetUser(resolvedPrincipal)
 			return err
 		}); err != nil {
-			resp.Diagnostics.AddError("Dependency Error", fmt.Sprintf("User dependency not satisfied: %s", err))
+			resp.Diagnostics.AddError("Dependency Error", userDependencyDetail(principalID, err))
 			return
 		}
 	} else if principalType == "GROUP" {
@@ -168,6 +286,13 @@ func (r *PermissionSetAssignmentResource) Create(ctx context.Context, req resour
 		}
 	}
 
+	// Set principal name based on type
+	if principalType == "USER" {
+		assignment.Username = resolvedPrincipal
+	} else if principalType == "GROUP" {
+		assignment.GroupName = resolvedPrincipal
+	}
+
 	_, err := r.client.CreatePermissionSetAssignment(assignment)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create permission set assignment, got error: %s", err))
@@ -176,50 +301,20 @@ func (r *PermissionSetAssignmentResource) Create(ctx context.Context, req resour
 
 	// After creating, we need to find the actual assignment IDs that were created
 	// The backend creates one assignment per account, but only returns the first one
-	// So we need to list all assignments and find the ones we just created
-	assignments, err := r.client.ListPermissionSetAssignments()
+	// So we need to list the principal's assignments and find the ones we just created
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered(permSetID, principalType, resolvedPrincipal)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list permission set assignments after create, got error: %s", err))
 		return
 	}
 
 	// Find the assignments we just created by matching all criteria
-
-	var createdAssignmentIDs []string
-	for _, acctID := range accountIDs {
-		found := false
-		for _, apiAssignment := range assignments {
-			if apiAssignment.PermissionSetID != permSetID {
-				continue
-			}
-			if apiAssignment.PrincipalType != principalType {
-				continue
-			}
-			if apiAssignment.AccountID != acctID {
-				continue
-			}
-
-			// Check principal ID matches
-			principalMatches := false
-			if principalType == "USER" && apiAssignment.Username == principalID {
-				principalMatches = true
-			} else if principalType == "GROUP" && apiAssignment.GroupName == principalID {
-				principalMatches = true
-			}
-
-			if principalMatches {
-				createdAssignmentIDs = append(createdAssignmentIDs, apiAssignment.ID)
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			resp.Diagnostics.AddWarning(
-				"Assignment Not Found",
-				fmt.Sprintf("Could not find assignment for account %s after creation. It may have been created but not immediately visible.", acctID),
-			)
-		}
+	createdAssignmentIDs, missingAccountIDs := matchAssignmentIDs(assignments, permSetID, principalType, resolvedPrincipal, accountIDs)
+	for _, acctID := range missingAccountIDs {
+		resp.Diagnostics.AddWarning(
+			"Assignment Not Found",
+			fmt.Sprintf("Could not find assignment for account %s after creation. It may have been created but not immediately visible.", acctID),
+		)
 	}
 
 	if len(createdAssignmentIDs) == 0 {
@@ -234,6 +329,8 @@ func (r *PermissionSetAssignmentResource) Create(ctx context.Context, req resour
 	// Format: assignmentId1,assignmentId2,assignmentId3,...
 	compositeID := strings.Join(createdAssignmentIDs, ",")
 	data.ID = types.StringValue(compositeID)
+	data.ResolvedPrincipal = types.StringValue(resolvedPrincipal)
+	data.AssignmentIDs = assignmentIDsByAccount(assignments, createdAssignmentIDs)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -247,7 +344,7 @@ func (r *PermissionSetAssignmentResource) Read(ctx context.Context, req resource
 	}
 
 	// Parse the composite ID to get the actual assignment IDs
-	assignmentIDs := strings.Split(data.ID.ValueString(), ",")
+	assignmentIDs := splitAssignmentIDs(data.ID.ValueString())
 	if len(assignmentIDs) == 0 {
 		resp.Diagnostics.AddError(
 			"Invalid State",
@@ -256,26 +353,55 @@ func (r *PermissionSetAssignmentResource) Read(ctx context.Context, req resource
 		return
 	}
 
+	// States written before resolved_principal existed have principal_id only
+	principalID := data.PrincipalID.ValueString()
+	resolvedPrincipal := data.ResolvedPrincipal.ValueString()
+	if resolvedPrincipal == "" {
+		resolvedPrincipal = principalID
+	}
+	// Resolve an email again, so the assignments of a renamed user are still found
+	if data.PrincipalType.ValueString() == "USER" && isEmailAddress(principalID) {
+		username, err := r.resolvePrincipal("USER", principalID)
+		switch {
+		case err == nil:
+			resolvedPrincipal = username
+		case isDependencyNotFoundError(err):
+			// The user is gone; look its assignments up by the last username
+		default:
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to resolve user %q, got error: %s", principalID, err))
+			return
+		}
+	}
+
+	// Look the assignments up in the shared assignment list rather than
+	// fetching each one, so a refresh costs one list call in total
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered(
+		data.PermissionSetID.ValueString(), data.PrincipalType.ValueString(), resolvedPrincipal)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set assignments, got error: %s", err))
+		return
+	}
+	assignmentsByID := make(map[string]PermissionSetAssignment, len(assignments))
+	for _, assignment := range assignments {
+		assignmentsByID[assignment.ID] = assignment
+	}
+
 	// Track existing assignments and collect account IDs
-	var existingAssignments []*PermissionSetAssignment
+	var existingAssignments []PermissionSetAssignment
 	var accountIDs []string
 
+	// An account the backend assigned more than once is listed once
+	seenAccounts := make(map[string]bool, len(assignmentIDs))
 	for _, assignmentID := range assignmentIDs {
-		assignment, err := r.client.GetPermissionSetAssignment(assignmentID)
-		if err != nil {
-			// If 404 or not found, skip it
-			if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
-				continue
-			}
-			// Other errors should be reported
-			resp.Diagnostics.AddWarning(
-				"API Error",
-				fmt.Sprintf("Unable to read assignment %s: %s", assignmentID, err),
-			)
+		assignment, ok := assignmentsByID[assignmentID]
+		if !ok {
 			continue
 		}
 		existingAssignments = append(existingAssignments, assignment)
-		accountIDs = append(accountIDs, assignment.AccountID)
+		if !seenAccounts[assignment.AccountID] {
+			seenAccounts[assignment.AccountID] = true
+			accountIDs = append(accountIDs, assignment.AccountID)
+		}
 	}
 
 	// If none of the assignments exist, remove from state
@@ -292,25 +418,39 @@ func (r *PermissionSetAssignmentResource) Read(ctx context.Context, req resource
 		)
 	}
 
+	// A resource being imported has no account_ids yet. It gets them sorted,
+	// with the ID in the same order, as GroupPermissionSetAssignments groups
+	// them and the import tool generates them; otherwise account_ids keep the
+	// order of the configuration
+	if data.AccountIDs.IsNull() {
+		groups := GroupPermissionSetAssignments(existingAssignments)
+		if len(groups) > 1 {
+			resp.Diagnostics.AddError(
+				"Invalid Import ID",
+				fmt.Sprintf("The assignments in %q belong to %d different permission sets or principals. Import the assignments of each permission set and principal separately.",
+					data.ID.ValueString(), len(groups)),
+			)
+			return
+		}
+		accountIDs = groups[0].AccountIDs
+		data.ID = types.StringValue(groups[0].ID())
+	}
+
 	// Populate state from the first existing assignment (they should all have same permission_set, principal)
 	firstAssignment := existingAssignments[0]
 	data.PermissionSetID = types.StringValue(firstAssignment.PermissionSetID)
 	data.PrincipalType = types.StringValue(firstAssignment.PrincipalType)
-
-	// Set principal_id based on type
-	if firstAssignment.PrincipalType == "USER" {
-		data.PrincipalID = types.StringValue(firstAssignment.Username)
-	} else {
-		data.PrincipalID = types.StringValue(firstAssignment.GroupName)
+	// principal_id keeps a configured email for as long as it resolves to
+	// the assignments' user
+	principal := AssignmentPrincipalID(firstAssignment)
+	if principal != resolvedPrincipal {
+		data.PrincipalID = types.StringValue(principal)
 	}
+	data.ResolvedPrincipal = types.StringValue(principal)
 
 	// Set account_ids from all existing assignments
-	accountIDsValues, diags := types.ListValueFrom(ctx, types.StringType, accountIDs)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-	data.AccountIDs = accountIDsValues
+	data.AccountIDs = convert.StringListOrEmpty(accountIDs)
+	data.AssignmentIDs = assignmentIDsByAccount(existingAssignments, splitAssignmentIDs(data.ID.ValueString()))
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -332,7 +472,7 @@ func (r *PermissionSetAssignmentResource) Delete(ctx context.Context, req resour
 	}
 
 	// Parse the composite ID to get the actual assignment IDs we created
-	assignmentIDs := strings.Split(data.ID.ValueString(), ",")
+	assignmentIDs := splitAssignmentIDs(data.ID.ValueString())
 	if len(assignmentIDs) == 0 {
 		// Nothing to delete
 		return
@@ -345,7 +485,7 @@ func (r *PermissionSetAssignmentResource) Delete(ctx context.Context, req resour
 		err := r.client.DeletePermissionSetAssignment(assignmentID)
 		if err != nil {
 			// If already deleted (404), that's OK
-			if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
+			if isNotFoundError(err) || strings.Contains(err.Error(), "not found") {
 				continue
 			}
 			deleteErrors = append(deleteErrors, fmt.Sprintf("assignment %s: %s", assignmentID, err.Error()))
@@ -358,6 +498,198 @@ func (r *PermissionSetAssignmentResource) Delete(ctx context.Context, req resour
 	}
 }
 
+// splitAssignmentIDs splits a resource ID into its backend assignment IDs,
+// leaving out empty ones so that an empty ID gives none.
+func splitAssignmentIDs(id string) []string {
+	var assignmentIDs []string
+	for _, assignmentID := range strings.Split(id, ",") {
+		if assignmentID = strings.TrimSpace(assignmentID); assignmentID != "" {
+			assignmentIDs = append(assignmentIDs, assignmentID)
+		}
+	}
+	return assignmentIDs
+}
+
+// assignmentIDsByAccount maps the account of each of assignmentIDs found in
+// assignments to its ID. An account the backend assigned more than once maps
+// to the first of its IDs; the id attribute still holds them all.
+func assignmentIDsByAccount(assignments []PermissionSetAssignment, assignmentIDs []string) types.Map {
+	assignmentsByID := make(map[string]PermissionSetAssignment, len(assignments))
+	for _, assignment := range assignments {
+		assignmentsByID[assignment.ID] = assignment
+	}
+
+	byAccount := make(map[string]string, len(assignmentIDs))
+	for _, assignmentID := range assignmentIDs {
+		assignment, ok := assignmentsByID[assignmentID]
+		if !ok {
+			continue
+		}
+		if _, seen := byAccount[assignment.AccountID]; !seen {
+			byAccount[assignment.AccountID] = assignmentID
+		}
+	}
+	return convert.StringMapOrNull(byAccount)
+}
+
 func (r *PermissionSetAssignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	permSetID, principalType, principalID, accountIDs, ok := parseLegacyAssignmentID(req.ID)
+	if !ok {
+		// Comma-separated backend assignment IDs, the same format as the resource ID
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		return
+	}
+
+	// Descriptive permission_set_id:TYPE:principal:account1,account2 form,
+	// resolved to backend assignment IDs
+	principal, err := r.resolvePrincipal(principalType, principalID)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to resolve user %q, got error: %s", principalID, err))
+		return
+	}
+	assignments, err := r.client.ListPermissionSetAssignmentsFiltered(permSetID, principalType, principal)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list permission set assignments, got error: %s", err))
+		return
+	}
+
+	matched, missing := matchAssignmentIDs(assignments, permSetID, principalType, principal, accountIDs)
+	if len(missing) > 0 {
+		resp.Diagnostics.AddError(
+			"Assignment Not Found",
+			fmt.Sprintf("No assignment of permission set %s to %s %s exists for account(s): %s",
+				permSetID, principalType, principalID, strings.Join(missing, ", ")),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strings.Join(matched, ","))...)
+}
+
+// permissionSetIDPattern matches the UUIDs the backend gives permission sets.
+var permissionSetIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+
+// checkPermissionSetID adds an error when permSetID is the name of a
+// permission set rather than its ID. The backend may accept a name on
+// create, but it lists assignments by permission set ID, so Read would lose
+// track of them at once. Values that look like IDs aren't checked, and a
+// failure to list permission sets is left to the requests that follow.
+func (r *PermissionSetAssignmentResource) checkPermissionSetID(ctx context.Context, permSetID string, diags *diag.Diagnostics) {
+	if permissionSetIDPattern.MatchString(permSetID) {
+		return
+	}
+	permSets, err := r.client.ListPermissionSets()
+	if err != nil {
+		tflog.Debug(ctx, "Unable to check permission_set_id against permission set names", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	var named *PermissionSet
+	for i, permSet := range permSets {
+		if permSet.ID == permSetID {
+			return
+		}
+		if permSet.Name == permSetID && named == nil {
+			named = &permSets[i]
+		}
+	}
+	if named != nil {
+		diags.AddAttributeError(
+			path.Root("permission_set_id"),
+			"Permission Set Name Used as ID",
+			fmt.Sprintf("%q is the name of permission set %s, not its ID. Assignments made by name can't be read back. "+
+				"Reference the permission set's ID instead, e.g. prism_permission_set.example.id, or look the ID up by name with the prism_permission_sets data source.",
+				permSetID, named.ID),
+		)
+	}
+}
+
+// resolvePrincipal returns the username or group name principalID names.
+// A USER principal_id may be an email address, which is resolved with
+// usernameForEmail; anything else already is the name.
+func (r *PermissionSetAssignmentResource) resolvePrincipal(principalType, principalID string) (string, error) {
+	if principalType != "USER" || !isEmailAddress(principalID) {
+		return principalID, nil
+	}
+	users, err := r.client.ListUsers()
+	if err != nil {
+		return "", err
+	}
+	return usernameForEmail(users, principalID)
+}
+
+// usernameForEmail returns the username of the user whose username is
+// email, or else of the only user with that email address, compared
+// case-insensitively. Checking usernames first keeps usernames that are
+// themselves email addresses working as before. It fails when no user or
+// several users have the address.
+func usernameForEmail(users []User, email string) (string, error) {
+	var matches []string
+	for _, user := range users {
+		if user.Username == email {
+			return user.Username, nil
+		}
+		if normalizeEmail(user.Email) == normalizeEmail(email) {
+			matches = append(matches, user.Username)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("user with email %q not found", email)
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("email %q belongs to %d users (%s); set principal_id to the username of the one to assign", email, len(matches), strings.Join(matches, ", "))
+}
+
+// matchAssignmentIDs returns the backend IDs of the assignments matching the
+// permission set and principal for each account, in account order, along with
+// the accounts that had no matching assignment. Every matching assignment of
+// an account is returned, so that Delete also removes duplicates the backend
+// created for it.
+func matchAssignmentIDs(assignments []PermissionSetAssignment, permSetID, principalType, principalID string, accountIDs []string) ([]string, []string) {
+	var matched, missing []string
+	for _, acctID := range accountIDs {
+		found := false
+		for _, apiAssignment := range assignments {
+			if apiAssignment.PermissionSetID != permSetID {
+				continue
+			}
+			if apiAssignment.PrincipalType != principalType {
+				continue
+			}
+			if apiAssignment.AccountID != acctID {
+				continue
+			}
+
+			// Check principal ID matches
+			if (principalType == "USER" && apiAssignment.Username == principalID) ||
+				(principalType == "GROUP" && apiAssignment.GroupName == principalID) {
+				matched = append(matched, apiAssignment.ID)
+				found = true
+			}
+		}
+
+		if !found {
+			missing = append(missing, acctID)
+		}
+	}
+	return matched, missing
+}
+
+// parseLegacyAssignmentID splits an ID in the legacy
+// permission_set_id:principal_type:principal_id:account1,account2 format,
+// with colons in the principal escaped as by compositeid.Encode. ok is false
+// for IDs in the current comma-separated assignment ID format.
+func parseLegacyAssignmentID(id string) (permSetID, principalType, principalID string, accountIDs []string, ok bool) {
+	parts, err := compositeid.Decode(id, ':')
+	if err != nil || len(parts) != 4 || parts[0] == "" || parts[2] == "" || parts[3] == "" {
+		return "", "", "", nil, false
+	}
+	if parts[1] != "USER" && parts[1] != "GROUP" {
+		return "", "", "", nil, false
+	}
+	return parts[0], parts[1], parts[2], strings.Split(parts[3], ","), true
 }
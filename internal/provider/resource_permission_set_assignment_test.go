@@ -0,0 +1,867 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestParseLegacyAssignmentID(t *testing.T) {
+	permSetID, principalType, principalID, accountIDs, ok := parseLegacyAssignmentID("ps-1:GROUP:developers:111111111111,222222222222")
+	if !ok {
+		t.Fatal("expected a legacy ID to parse")
+	}
+	if permSetID != "ps-1" || principalType != "GROUP" || principalID != "developers" {
+		t.Errorf("unexpected parts: %q %q %q", permSetID, principalType, principalID)
+	}
+	if len(accountIDs) != 2 || accountIDs[0] != "111111111111" || accountIDs[1] != "222222222222" {
+		t.Errorf("unexpected account IDs: %v", accountIDs)
+	}
+
+	// An LDAP username holding colons, escaped as compositeid.Encode does
+	_, _, principalID, accountIDs, ok = parseLegacyAssignmentID("ps-1:USER:CN=jane%3Adoe%25:111111111111")
+	if !ok || principalID != "CN=jane:doe%" || len(accountIDs) != 1 {
+		t.Errorf("expected an escaped principal to parse, got %q %v %v", principalID, accountIDs, ok)
+	}
+
+	for _, id := range []string{"asgn-1", "asgn-1,asgn-2", "ps-1:ROLE:x:111111111111", "ps-1:USER::111111111111", "ps-1:USER:jane:doe:111111111111", "ps-1:USER:jane%:111111111111"} {
+		if _, _, _, _, ok := parseLegacyAssignmentID(id); ok {
+			t.Errorf("expected %q not to parse as a legacy ID", id)
+		}
+	}
+}
+
+// upgradeAssignmentState runs the version 0 upgrader on a state holding id.
+func upgradeAssignmentState(t *testing.T, client *Client, id string) (PermissionSetAssignmentResourceModel, resource.UpgradeStateResponse) {
+	t.Helper()
+	ctx := context.Background()
+
+	r := NewPermissionSetAssignmentResource()
+	h := newResourceHarness(t, r, client)
+	if h.schema.Version != 1 {
+		t.Fatalf("expected schema version 1, got %d", h.schema.Version)
+	}
+
+	upgrader, ok := r.(resource.ResourceWithUpgradeState).UpgradeState(ctx)[0]
+	if !ok {
+		t.Fatal("expected a version 0 state upgrader")
+	}
+
+	accountIDs, _ := types.ListValueFrom(ctx, types.StringType, []string{"111111111111", "222222222222"})
+	priorState := tfsdk.State{Schema: *upgrader.PriorSchema, Raw: tftypes.NewValue(upgrader.PriorSchema.Type().TerraformType(ctx), nil)}
+	if diags := priorState.Set(ctx, &PermissionSetAssignmentResourceModel{
+		ID:              types.StringValue(id),
+		PermissionSetID: types.StringValue("ps-1"),
+		PrincipalType:   types.StringValue("GROUP"),
+		PrincipalID:     types.StringValue("developers"),
+		AccountIDs:      accountIDs,
+		AssignmentIDs:   types.MapNull(types.StringType),
+		Timeouts:        types.ObjectNull(map[string]attr.Type{"create": types.StringType}),
+	}); diags.HasError() {
+		t.Fatalf("building prior state: %v", diags)
+	}
+
+	resp := resource.UpgradeStateResponse{State: h.nullState()}
+	upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{State: &priorState}, &resp)
+
+	var got PermissionSetAssignmentResourceModel
+	if !resp.Diagnostics.HasError() {
+		h.get(resp.State, &got)
+	}
+	return got, resp
+}
+
+func assignmentsHandler(assignments []PermissionSetAssignment) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeAPIData(w, map[string]interface{}{
+			"assignments": assignments,
+			"count":       len(assignments),
+		})
+	}
+}
+
+func TestSplitAssignmentIDs(t *testing.T) {
+	tests := map[string][]string{
+		"":           nil,
+		",":          nil,
+		"a-1":        {"a-1"},
+		"a:1,a/2":    {"a:1", "a/2"},
+		"a-1, ,a-2,": {"a-1", "a-2"},
+	}
+	for id, want := range tests {
+		if got := splitAssignmentIDs(id); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitAssignmentIDs(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestPermissionSetAssignmentUpgradeState_LegacyID(t *testing.T) {
+	client := newTestClient(t, assignmentsHandler([]PermissionSetAssignment{
+		{ID: "asgn-other", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "admins", AccountID: "111111111111"},
+		{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"},
+		{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"},
+	}))
+
+	got, resp := upgradeAssignmentState(t, client, "ps-1:GROUP:developers:111111111111,222222222222")
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if got.ID.ValueString() != "asgn-1,asgn-2" {
+		t.Errorf("expected id asgn-1,asgn-2, got %q", got.ID.ValueString())
+	}
+	if got.PrincipalID.ValueString() != "developers" {
+		t.Errorf("expected other attributes to carry over, got principal_id %q", got.PrincipalID.ValueString())
+	}
+}
+
+func TestPermissionSetAssignmentUpgradeState_LegacyIDPartiallyMissing(t *testing.T) {
+	client := newTestClient(t, assignmentsHandler([]PermissionSetAssignment{
+		{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"},
+	}))
+
+	got, resp := upgradeAssignmentState(t, client, "ps-1:GROUP:developers:111111111111,222222222222")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected one warning for the missing account, got %v", resp.Diagnostics)
+	}
+	if got.ID.ValueString() != "asgn-1" {
+		t.Errorf("expected id asgn-1, got %q", got.ID.ValueString())
+	}
+}
+
+func TestPermissionSetAssignmentUpgradeState_CurrentID(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no API call expected for a state already in the current format")
+	}))
+
+	got, resp := upgradeAssignmentState(t, client, "asgn-1,asgn-2")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if got.ID.ValueString() != "asgn-1,asgn-2" {
+		t.Errorf("expected id to be unchanged, got %q", got.ID.ValueString())
+	}
+}
+
+// createAssignmentDependencies creates a permission set and group and returns
+// their identifiers along with the AWS account IDs to assign. Against a real
+// backend the accounts come from PRISM_ACC_AWS_ACCOUNT_IDS (comma-separated),
+// since onboarding requires real AWS accounts.
+func createAssignmentDependencies(t *testing.T, client *Client, fake *fakePrism) (permSetID, groupName string, accountIDs []string) {
+	t.Helper()
+
+	if fake != nil {
+		accounts := newResourceHarness(t, NewAWSAccountResource(), client)
+		for _, acctID := range []string{"111111111111", "222222222222"} {
+			_, diags := accounts.create(&AWSAccountResourceModel{
+				ID:          types.StringUnknown(),
+				AccountID:   types.StringValue(acctID),
+				AccountName: types.StringValue("acct-" + acctID),
+				Region:      types.StringNull(),
+				RoleArn:     types.StringNull(),
+				OwnerEmails: types.ListNull(types.StringType),
+				Timeouts:    nullTimeouts("create", "delete"),
+			})
+			if diags.HasError() {
+				t.Fatalf("create account %s: %v", acctID, diags)
+			}
+			accountIDs = append(accountIDs, acctID)
+		}
+	} else {
+		if os.Getenv("PRISM_ACC_AWS_ACCOUNT_IDS") == "" {
+			t.Skip("PRISM_ACC_AWS_ACCOUNT_IDS must be set for assignment acceptance tests")
+		}
+		accountIDs = strings.Split(os.Getenv("PRISM_ACC_AWS_ACCOUNT_IDS"), ",")
+	}
+
+	permSets := newResourceHarness(t, NewPermissionSetResource(), client)
+	permSetState, diags := permSets.create(testPermissionSetModel(t, accName("readonly"), ""))
+	if diags.HasError() {
+		t.Fatalf("create permission set: %v", diags)
+	}
+	t.Cleanup(func() { permSets.delete(permSetState) })
+
+	groupName = accName("group")
+	createMembershipDependencies(t, client, groupName)
+
+	return permSets.attr(permSetState, "id"), groupName, accountIDs
+}
+
+func testAssignmentModel(t *testing.T, permSetID, groupName string, accountIDs []string) *PermissionSetAssignmentResourceModel {
+	t.Helper()
+
+	accounts, diags := types.ListValueFrom(context.Background(), types.StringType, accountIDs)
+	if diags.HasError() {
+		t.Fatalf("building account_ids: %v", diags)
+	}
+	return &PermissionSetAssignmentResourceModel{
+		ID:                types.StringUnknown(),
+		PermissionSetID:   types.StringValue(permSetID),
+		PrincipalType:     types.StringValue("GROUP"),
+		PrincipalID:       types.StringValue(groupName),
+		ResolvedPrincipal: types.StringUnknown(),
+		AccountIDs:        accounts,
+		AssignmentIDs:     types.MapUnknown(types.StringType),
+		Timeouts:          nullTimeouts("create"),
+	}
+}
+
+func TestAccPermissionSetAssignmentResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	permSetID, groupName, accountIDs := createAssignmentDependencies(t, client, fake)
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+	// Create
+	state, diags := h.create(testAssignmentModel(t, permSetID, groupName, accountIDs))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := h.attr(state, "id")
+	if got := len(strings.Split(id, ",")); got != len(accountIDs) {
+		t.Errorf("expected %d assignment IDs, got %q", len(accountIDs), id)
+	}
+
+	// Import
+	imported, diags := h.importState(id)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got := h.attr(imported, "principal_id"); got != groupName {
+		t.Errorf("expected imported principal_id %q, got %q", groupName, got)
+	}
+	if got := h.attr(imported, "permission_set_id"); got != permSetID {
+		t.Errorf("expected imported permission_set_id %q, got %q", permSetID, got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil && len(fake.assignments) != 0 {
+		t.Errorf("expected assignments to be deleted, got %d", len(fake.assignments))
+	}
+}
+
+func TestAccPermissionSetAssignmentResource_PartiallyDeletedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID, groupName, accountIDs := createAssignmentDependencies(t, client, fake)
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+	state, diags := h.create(testAssignmentModel(t, permSetID, groupName, accountIDs))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	first := strings.Split(h.attr(state, "id"), ",")[0]
+	delete(fake.assignments, first)
+	// The next refresh runs in a new Terraform operation with a fresh client
+	client.assignmentCache.invalidate()
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Partial Assignment Drift") {
+		t.Errorf("expected a Partial Assignment Drift warning, got %v", diags)
+	}
+	var data PermissionSetAssignmentResourceModel
+	h.get(state, &data)
+	if got := len(data.AccountIDs.Elements()); got != len(accountIDs)-1 {
+		t.Errorf("expected %d account_ids after drift, got %d", len(accountIDs)-1, got)
+	}
+
+	// With every assignment gone the resource leaves state
+	for id := range fake.assignments {
+		delete(fake.assignments, id)
+	}
+	client.assignmentCache.invalidate()
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !state.Raw.IsNull() {
+		t.Error("expected the assignment to be removed from state")
+	}
+}
+
+func assignmentIDsOf(t *testing.T, h *resourceHarness, state tfsdk.State) map[string]string {
+	t.Helper()
+	var data PermissionSetAssignmentResourceModel
+	h.get(state, &data)
+	var got map[string]string
+	if diags := data.AssignmentIDs.ElementsAs(context.Background(), &got, false); diags.HasError() {
+		t.Fatalf("reading assignment_ids: %v", diags)
+	}
+	return got
+}
+
+func TestPermissionSetAssignmentResource_AssignmentIDs(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID, groupName, accountIDs := createAssignmentDependencies(t, client, fake)
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+	state, diags := h.create(testAssignmentModel(t, permSetID, groupName, accountIDs))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	ids := assignmentIDsOf(t, h, state)
+	if len(ids) != len(accountIDs) {
+		t.Fatalf("expected an assignment ID for each of %v, got %v", accountIDs, ids)
+	}
+	for _, acctID := range accountIDs {
+		assignment, ok := fake.assignments[ids[acctID]]
+		if !ok || assignment.AccountID != acctID {
+			t.Errorf("expected assignment_ids[%q] to be an assignment of that account, got %q", acctID, ids[acctID])
+		}
+	}
+
+	// An assignment deleted outside Terraform leaves the map on refresh
+	removed := accountIDs[0]
+	delete(fake.assignments, ids[removed])
+	client.assignmentCache.invalidate()
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	refreshed := assignmentIDsOf(t, h, state)
+	if _, ok := refreshed[removed]; ok {
+		t.Errorf("expected %s to leave assignment_ids, got %v", removed, refreshed)
+	}
+	if len(refreshed) != len(accountIDs)-1 {
+		t.Errorf("expected %d assignment IDs after drift, got %v", len(accountIDs)-1, refreshed)
+	}
+
+	// Importing fills the map as well
+	imported, diags := h.importState(h.attr(state, "id"))
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got := assignmentIDsOf(t, h, imported); !reflect.DeepEqual(got, refreshed) {
+		t.Errorf("expected imported assignment_ids %v, got %v", refreshed, got)
+	}
+}
+
+func TestPermissionSetAssignmentRead_SharesOneListCall(t *testing.T) {
+	fake := newFakePrism()
+	var listCalls, getCalls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if strings.HasSuffix(r.URL.Path, "/permission-set-assignments") {
+				atomic.AddInt32(&listCalls, 1)
+			} else if strings.Contains(r.URL.Path, "/permission-set-assignments/") {
+				atomic.AddInt32(&getCalls, 1)
+			}
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+	// Five assignment resources, each spanning two accounts
+	var states []tfsdk.State
+	for i := 0; i < 5; i++ {
+		groupName := fmt.Sprintf("group-%d", i)
+		ids := []string{}
+		for _, acctID := range []string{"111111111111", "222222222222"} {
+			id := fmt.Sprintf("asgn-%d-%s", i, acctID)
+			fake.assignments[id] = &PermissionSetAssignment{ID: id, PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: groupName, AccountID: acctID}
+			ids = append(ids, id)
+		}
+		model := testAssignmentModel(t, "ps-1", groupName, []string{"111111111111", "222222222222"})
+		model.ID = types.StringValue(strings.Join(ids, ","))
+		states = append(states, h.state(model))
+	}
+
+	var wg sync.WaitGroup
+	for _, state := range states {
+		wg.Add(1)
+		go func(state tfsdk.State) {
+			defer wg.Done()
+			refreshed, diags := h.read(state)
+			if diags.HasError() || refreshed.Raw.IsNull() {
+				t.Errorf("read: %v", diags)
+			}
+		}(state)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&listCalls); got != 1 {
+		t.Errorf("expected 1 list call for the whole refresh, got %d", got)
+	}
+	if got := atomic.LoadInt32(&getCalls); got != 0 {
+		t.Errorf("expected no per-assignment GET calls, got %d", got)
+	}
+}
+
+func TestListPermissionSetAssignmentsFiltered_InvalidatedByWrites(t *testing.T) {
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "readonly"}
+	client := newTestClient(t, fake)
+
+	got, err := client.ListPermissionSetAssignmentsFiltered("ps-1", "", "")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no assignments, got %v (%v)", got, err)
+	}
+
+	if _, err := client.CreatePermissionSetAssignment(&PermissionSetAssignment{
+		PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountIDs: []string{"111111111111"},
+	}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err = client.ListPermissionSetAssignmentsFiltered("ps-1", "USER", "alice")
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected the new assignment after create, got %v (%v)", got, err)
+	}
+	if other, _ := client.ListPermissionSetAssignmentsFiltered("ps-1", "USER", "bob"); len(other) != 0 {
+		t.Errorf("expected the principal filter to exclude alice's assignment, got %v", other)
+	}
+
+	if err := client.DeletePermissionSetAssignment(got[0].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got, _ := client.ListPermissionSetAssignmentsFiltered("ps-1", "", ""); len(got) != 0 {
+		t.Errorf("expected no assignments after delete, got %v", got)
+	}
+}
+
+func TestPermissionSetAssignmentResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"}
+	fake.assignments["asgn-3"] = &PermissionSetAssignment{ID: "asgn-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+
+	tests := []struct {
+		name     string
+		importID string
+	}{
+		{name: "assignment IDs", importID: "asgn-1,asgn-2"},
+		{name: "descriptive", importID: "ps-1:GROUP:developers:111111111111,222222222222"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+			state, diags := h.importState(tt.importID)
+			if diags.HasError() {
+				t.Fatalf("import: %v", diags)
+			}
+			h.assertImportComplete(state)
+
+			var data PermissionSetAssignmentResourceModel
+			h.get(state, &data)
+			if got := data.ID.ValueString(); got != "asgn-1,asgn-2" {
+				t.Errorf("expected id asgn-1,asgn-2, got %q", got)
+			}
+			if data.PermissionSetID.ValueString() != "ps-1" || data.PrincipalType.ValueString() != "GROUP" || data.PrincipalID.ValueString() != "developers" {
+				t.Errorf("expected ps-1 assigned to GROUP developers, got %s %s %s", data.PermissionSetID, data.PrincipalType, data.PrincipalID)
+			}
+			if got := len(data.AccountIDs.Elements()); got != 2 {
+				t.Errorf("expected 2 account_ids, got %d", got)
+			}
+		})
+	}
+}
+
+func TestPermissionSetAssignmentResource_ImportDescriptiveMissingAccount(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+	_, diags := h.importState("ps-1:GROUP:developers:111111111111,333333333333")
+	if !hasDiagnostic(diags, "Assignment Not Found") {
+		t.Fatalf("expected an Assignment Not Found error, got %v", diags)
+	}
+	if !strings.Contains(diags.Errors()[0].Detail(), "333333333333") {
+		t.Errorf("expected the missing account to be named, got %s", diags.Errors()[0].Detail())
+	}
+}
+
+func TestPermissionSetAssignmentResource_ImportSortsAccounts(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+	state, diags := h.importState("asgn-1,asgn-2")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+
+	var data PermissionSetAssignmentResourceModel
+	h.get(state, &data)
+	var accountIDs []string
+	data.AccountIDs.ElementsAs(context.Background(), &accountIDs, false)
+	if want := []string{"111111111111", "222222222222"}; !reflect.DeepEqual(accountIDs, want) {
+		t.Errorf("expected account_ids %v, got %v", want, accountIDs)
+	}
+	if got := data.ID.ValueString(); got != "asgn-2,asgn-1" {
+		t.Errorf("expected the id in account order, got %q", got)
+	}
+
+	// A refresh keeps the order of the configuration
+	model := testAssignmentModel(t, "ps-1", "developers", []string{"222222222222", "111111111111"})
+	model.ID = types.StringValue("asgn-1,asgn-2")
+	refreshed, diags := h.read(h.state(model))
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	h.get(refreshed, &data)
+	accountIDs = nil
+	data.AccountIDs.ElementsAs(context.Background(), &accountIDs, false)
+	if want := []string{"222222222222", "111111111111"}; !reflect.DeepEqual(accountIDs, want) {
+		t.Errorf("expected account_ids %v after a refresh, got %v", want, accountIDs)
+	}
+}
+
+func TestPermissionSetAssignmentResource_ImportMixedPrincipals(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+	_, diags := h.importState("asgn-1,asgn-2")
+	if !hasDiagnostic(diags, "Invalid Import ID") {
+		t.Errorf("expected an Invalid Import ID error, got %v", diags)
+	}
+}
+
+func TestPermissionSetAssignmentResource_WaitsForNewUser(t *testing.T) {
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111"}
+	fake.users["bob"] = &User{ID: "user-bob", Username: "bob"}
+
+	// bob appears on the third poll
+	handler, lookups := delayUser(fake, "bob", 2)
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), newTestClient(t, handler))
+
+	plan := testAssignmentModel(t, "ps-1", "bob", []string{"111111111111"})
+	plan.PrincipalType = types.StringValue("USER")
+	if _, diags := h.create(plan); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if got := lookups(); got != 3 {
+		t.Errorf("expected bob to be looked up 3 times, got %d", got)
+	}
+	if len(fake.assignments) != 1 {
+		t.Errorf("expected 1 assignment, got %d", len(fake.assignments))
+	}
+}
+
+func TestPermissionSetAssignmentResource_UserNeverAppears(t *testing.T) {
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111"}
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), newTestClient(t, fake))
+
+	plan := testAssignmentModel(t, "ps-1", "bob", []string{"111111111111"})
+	plan.PrincipalType = types.StringValue("USER")
+	plan.Timeouts = testTimeouts(map[string]string{"create": "3s"})
+	_, diags := h.create(plan)
+
+	detail := diagnosticDetail(diags, "Dependency Error")
+	for _, want := range []string{`timed out after 3s waiting for user "bob"`, `If user "bob" is created in the same apply`} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("expected the error to contain %q, got %q", want, detail)
+		}
+	}
+	if len(fake.assignments) != 0 {
+		t.Errorf("expected no assignment, got %d", len(fake.assignments))
+	}
+}
+
+func TestUsernameForEmail(t *testing.T) {
+	users := []User{
+		{Username: "alice", Email: "Alice@example.com"},
+		{Username: "bob", Email: "bob@example.com"},
+		{Username: "bob2", Email: "BOB@example.com"},
+		{Username: "carol@example.com", Email: "carol.smith@example.com"},
+		{Username: "dave", Email: "carol@example.com"},
+	}
+
+	tests := []struct {
+		email   string
+		want    string
+		wantErr string
+	}{
+		{email: "alice@example.com", want: "alice"},
+		{email: "ALICE@EXAMPLE.COM", want: "alice"},
+		{email: "carol@example.com", want: "carol@example.com"},
+		{email: "nobody@example.com", wantErr: `user with email "nobody@example.com" not found`},
+		{email: "bob@example.com", wantErr: `email "bob@example.com" belongs to 2 users (bob, bob2)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := usernameForEmail(users, tt.email)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected an error containing %q, got %q, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expected %q, got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+// emailAssignmentFake returns a fake with a permission set, an account, and
+// the users alice and bob, and a USER assignment model for principalID.
+func emailAssignmentFake(t *testing.T, principalID string) (*fakePrism, *PermissionSetAssignmentResourceModel) {
+	t.Helper()
+
+	fake := newFakePrism()
+	fake.permSets["ps-1"] = &PermissionSet{ID: "ps-1", Name: "ReadOnly"}
+	fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111"}
+	fake.users["alice"] = &User{ID: "user-alice", Username: "alice", Email: "Alice@example.com"}
+	fake.users["bob"] = &User{ID: "user-bob", Username: "bob", Email: "bob@example.com"}
+
+	plan := testAssignmentModel(t, "ps-1", principalID, []string{"111111111111"})
+	plan.PrincipalType = types.StringValue("USER")
+	return fake, plan
+}
+
+func TestPermissionSetAssignmentResource_PrincipalEmailOrUsername(t *testing.T) {
+	for _, principalID := range []string{"alice@example.com", "alice"} {
+		t.Run(principalID, func(t *testing.T) {
+			fake, plan := emailAssignmentFake(t, principalID)
+			client := newTestClient(t, fake)
+			h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+			state, diags := h.create(plan)
+			if diags.HasError() {
+				t.Fatalf("create: %v", diags)
+			}
+			if len(fake.assignments) != 1 {
+				t.Fatalf("expected 1 assignment, got %d", len(fake.assignments))
+			}
+			for _, assignment := range fake.assignments {
+				if assignment.Username != "alice" {
+					t.Errorf("expected the assignment to name alice, got %q", assignment.Username)
+				}
+			}
+			if got := h.attr(state, "principal_id"); got != principalID {
+				t.Errorf("expected principal_id %q, got %q", principalID, got)
+			}
+			if got := h.attr(state, "resolved_principal"); got != "alice" {
+				t.Errorf("expected resolved_principal alice, got %q", got)
+			}
+
+			// A refresh keeps principal_id as configured
+			client.assignmentCache.invalidate()
+			state, diags = h.read(state)
+			if diags.HasError() {
+				t.Fatalf("read: %v", diags)
+			}
+			if got := h.attr(state, "principal_id"); got != principalID {
+				t.Errorf("expected principal_id %q after a refresh, got %q", principalID, got)
+			}
+			if got := h.attr(state, "resolved_principal"); got != "alice" {
+				t.Errorf("expected resolved_principal alice after a refresh, got %q", got)
+			}
+		})
+	}
+}
+
+func TestPermissionSetAssignmentResource_PrincipalEmailSurvivesRename(t *testing.T) {
+	fake, plan := emailAssignmentFake(t, "alice@example.com")
+	client := newTestClient(t, fake)
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+	state, diags := h.create(plan)
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	// alice is renamed outside Terraform, and the backend follows the rename
+	// in her assignments
+	fake.users["alice.smith"] = fake.users["alice"]
+	fake.users["alice.smith"].Username = "alice.smith"
+	delete(fake.users, "alice")
+	for _, assignment := range fake.assignments {
+		assignment.Username = "alice.smith"
+	}
+	client.assignmentCache.invalidate()
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if state.Raw.IsNull() {
+		t.Fatal("expected the assignment to stay in state")
+	}
+	if got := h.attr(state, "principal_id"); got != "alice@example.com" {
+		t.Errorf("expected principal_id to keep the email, got %q", got)
+	}
+	if got := h.attr(state, "resolved_principal"); got != "alice.smith" {
+		t.Errorf("expected resolved_principal alice.smith, got %q", got)
+	}
+}
+
+func TestPermissionSetAssignmentResource_PrincipalEmailErrors(t *testing.T) {
+	t.Run("no user", func(t *testing.T) {
+		fake, plan := emailAssignmentFake(t, "nobody@example.com")
+		plan.Timeouts = testTimeouts(map[string]string{"create": "1s"})
+		h := newResourceHarness(t, NewPermissionSetAssignmentResource(), newTestClient(t, fake))
+
+		_, diags := h.create(plan)
+		detail := diagnosticDetail(diags, "Dependency Error")
+		for _, want := range []string{`waiting for user "nobody@example.com"`, "check that a user has this email address"} {
+			if !strings.Contains(detail, want) {
+				t.Errorf("expected the error to contain %q, got %q", want, detail)
+			}
+		}
+		if len(fake.assignments) != 0 {
+			t.Errorf("expected no assignment, got %d", len(fake.assignments))
+		}
+	})
+
+	t.Run("several users", func(t *testing.T) {
+		fake, plan := emailAssignmentFake(t, "bob@example.com")
+		fake.users["bob2"] = &User{ID: "user-bob2", Username: "bob2", Email: "Bob@Example.com"}
+		h := newResourceHarness(t, NewPermissionSetAssignmentResource(), newTestClient(t, fake))
+
+		_, diags := h.create(plan)
+		detail := diagnosticDetail(diags, "Dependency Error")
+		if !strings.Contains(detail, `email "bob@example.com" belongs to 2 users (bob, bob2)`) {
+			t.Errorf("expected the error to name both users, got %q", detail)
+		}
+		if len(fake.assignments) != 0 {
+			t.Errorf("expected no assignment, got %d", len(fake.assignments))
+		}
+	})
+}
+
+func TestPermissionSetAssignmentResource_RejectsDuplicateAccounts(t *testing.T) {
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), nil)
+	attribute, ok := h.schema.Attributes["account_ids"].(schema.ListAttribute)
+	if !ok {
+		t.Fatal("expected account_ids to be a list attribute")
+	}
+
+	for name, accountIDs := range map[string][]string{
+		"distinct":   {"111111111111", "222222222222"},
+		"duplicated": {"111111111111", "222222222222", "111111111111"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			value, _ := types.ListValueFrom(context.Background(), types.StringType, accountIDs)
+			req := validator.ListRequest{Path: path.Root("account_ids"), ConfigValue: value}
+			var resp validator.ListResponse
+			for _, v := range attribute.Validators {
+				v.ValidateList(context.Background(), req, &resp)
+			}
+			if got, want := resp.Diagnostics.HasError(), name == "duplicated"; got != want {
+				t.Errorf("expected an error: %t, got %v", want, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPermissionSetAssignmentResource_DuplicateBackendAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	// The backend assigned 111111111111 twice, as it does for a duplicated account_ids element
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "222222222222"}
+	fake.assignments["asgn-3"] = &PermissionSetAssignment{ID: "asgn-3", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "developers", AccountID: "111111111111"}
+
+	h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+	state, diags := h.importState("ps-1:GROUP:developers:111111111111,222222222222")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+
+	var data PermissionSetAssignmentResourceModel
+	h.get(state, &data)
+	var accountIDs []string
+	data.AccountIDs.ElementsAs(context.Background(), &accountIDs, false)
+	if want := []string{"111111111111", "222222222222"}; !reflect.DeepEqual(accountIDs, want) {
+		t.Errorf("expected each account once in account_ids %v, got %v", want, accountIDs)
+	}
+	if got := len(splitAssignmentIDs(data.ID.ValueString())); got != 3 {
+		t.Errorf("expected the id to hold all 3 assignments, got %q", data.ID.ValueString())
+	}
+	if got, want := assignmentIDsOf(t, h, state), map[string]string{"111111111111": "asgn-1", "222222222222": "asgn-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected assignment_ids %v, got %v", want, got)
+	}
+
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if len(fake.assignments) != 0 {
+		t.Errorf("expected every assignment to be deleted, got %v", fake.assignments)
+	}
+}
+
+func TestPermissionSetAssignmentResource_PermissionSetNameAsID(t *testing.T) {
+	const uuid = "0b6f3d2e-8c1a-4f57-9e0d-2a7c5b9d41e3"
+	tests := []struct {
+		name      string
+		permSetID string
+		wantError bool
+		wantLists int32
+	}{
+		{name: "uuid", permSetID: uuid, wantLists: 0},
+		{name: "name that exists", permSetID: "ReadOnly", wantError: true, wantLists: 1},
+		{name: "name that doesn't exist", permSetID: "ps-legacy", wantLists: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakePrism()
+			fake.permSets[uuid] = &PermissionSet{ID: uuid, Name: "ReadOnly"}
+			fake.permSets["ps-legacy"] = &PermissionSet{ID: "ps-legacy", Name: "Legacy"}
+			fake.accounts["111111111111"] = &AWSAccount{ID: "acct-1", AccountID: "111111111111"}
+			fake.groups["developers"] = &Group{ID: "group-1", Name: "developers"}
+
+			var lists int32
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-sets") {
+					atomic.AddInt32(&lists, 1)
+				}
+				fake.ServeHTTP(w, r)
+			}))
+			h := newResourceHarness(t, NewPermissionSetAssignmentResource(), client)
+
+			_, diags := h.create(testAssignmentModel(t, tt.permSetID, "developers", []string{"111111111111"}))
+			if tt.wantError {
+				detail := diagnosticDetail(diags, "Permission Set Name Used as ID")
+				if !strings.Contains(detail, uuid) || !strings.Contains(detail, "prism_permission_set.example.id") {
+					t.Errorf("expected the error to give the ID and how to reference it, got %q", detail)
+				}
+				if len(fake.assignments) != 0 {
+					t.Errorf("expected no assignment, got %d", len(fake.assignments))
+				}
+			} else if diags.HasError() {
+				t.Fatalf("create: %v", diags)
+			}
+			if got := atomic.LoadInt32(&lists); got != tt.wantLists {
+				t.Errorf("expected %d permission set list calls, got %d", tt.wantLists, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &PermissionSetInlinePolicyResource{}
+var _ resource.ResourceWithImportState = &PermissionSetInlinePolicyResource{}
+
+func NewPermissionSetInlinePolicyResource() resource.Resource {
+	return &PermissionSetInlinePolicyResource{}
+}
+
+type PermissionSetInlinePolicyResource struct {
+	client *Client
+}
+
+// Default timeout for writing or removing an inline policy, including
+// retries of updates that conflict with other changes to the permission set
+const inlinePolicyTimeout = 2 * time.Minute
+
+type PermissionSetInlinePolicyResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	PermissionSetID types.String `tfsdk:"permission_set_id"`
+	PolicyName      types.String `tfsdk:"policy_name"`
+	Policy          types.String `tfsdk:"policy"`
+	Timeouts        types.Object `tfsdk:"timeouts"`
+}
+
+func (r *PermissionSetInlinePolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_set_inline_policy"
+}
+
+func (r *PermissionSetInlinePolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages one inline policy of a permission set, leaving its other inline policies alone. " +
+			"Use this resource when different teams own the inline policies of a shared permission set, and leave `inline_policies` unset on the matching `prism_permission_set`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The permission set ID and policy name, separated by `/`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"permission_set_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The ID of the permission set, e.g. `prism_permission_set.example.id`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy_name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The name of the inline policy, unique within the permission set",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy": schema.StringAttribute{
+				Required: true,
+				MarkdownDescription: "The IAM policy document in JSON format. Documents that differ only in whitespace or key order " +
+					"are the same policy, so reformatting one shows no changes",
+				Validators: []validator.String{policyJSONValidator{}},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"create": inlinePolicyTimeout,
+				"update": inlinePolicyTimeout,
+				"delete": inlinePolicyTimeout,
+			}),
+		},
+	}
+}
+
+func (r *PermissionSetInlinePolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *PermissionSetInlinePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PermissionSetInlinePolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resolveTimeout(data.Timeouts, "create", inlinePolicyTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, name, policy := data.PermissionSetID.ValueString(), data.PolicyName.ValueString(), data.Policy.ValueString()
+
+	// A policy of the same name with another document belongs to someone
+	// else, such as the permission set's inline_policies; don't overwrite it
+	var conflicting bool
+	err := retryOnConflict(ctx, createTimeout, fmt.Sprintf("permission set %q", permSetID), func() error {
+		_, err := r.client.UpdatePermissionSetInlinePolicies(ctx, permSetID, func(policies map[string]string) map[string]string {
+			existing, ok := policies[name]
+			conflicting = ok && !policyDocumentsEqual(existing, policy)
+			if ok {
+				return policies
+			}
+			if policies == nil {
+				policies = map[string]string{}
+			}
+			policies[name] = policy
+			return policies
+		})
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to add inline policy %q, got error: %s", name, err))
+		return
+	}
+	if conflicting {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("policy_name"),
+			"Inline Policy Already Exists",
+			fmt.Sprintf("Permission set %s already has an inline policy named %q with a different document. "+
+				"It may be set by the permission set's inline_policies or by another prism_permission_set_inline_policy. "+
+				"Choose another policy_name, or import the existing policy with ID %q to manage it here.",
+				permSetID, name, inlinePolicyID(permSetID, name)),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(inlinePolicyID(permSetID, name))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetInlinePolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PermissionSetInlinePolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSet, err := r.client.GetPermissionSet(data.PermissionSetID.ValueString())
+	if err != nil {
+		// If the permission set was deleted (404), its inline policies went with it
+		if isNotFoundError(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set, got error: %s", err))
+		return
+	}
+
+	// A policy imported moments ago has no document in state yet
+	name, imported := data.PolicyName.ValueString(), data.Policy.IsNull()
+	policy, ok := permSet.InlinePolicies[name]
+	if !ok {
+		// The policy was removed outside this resource
+		if !imported {
+			resp.Diagnostics.AddWarning(
+				"Inline Policy Removed",
+				fmt.Sprintf("Inline policy %q is no longer on permission set %s, so the next apply creates it again. %s",
+					name, data.PermissionSetID.ValueString(), inlinePolicyOwnershipHint),
+			)
+		}
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Keep the configured formatting of an unchanged document
+	if !policyDocumentsEqual(data.Policy.ValueString(), policy) {
+		if !imported {
+			resp.Diagnostics.AddWarning(
+				"Inline Policy Changed",
+				fmt.Sprintf("Inline policy %q of permission set %s was changed outside this resource, and the next apply writes it back. %s",
+					name, data.PermissionSetID.ValueString(), inlinePolicyOwnershipHint),
+			)
+		}
+		data.Policy = types.StringValue(policy)
+	}
+	data.ID = types.StringValue(inlinePolicyID(data.PermissionSetID.ValueString(), data.PolicyName.ValueString()))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetInlinePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data PermissionSetInlinePolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := resolveTimeout(data.Timeouts, "update", inlinePolicyTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, name, policy := data.PermissionSetID.ValueString(), data.PolicyName.ValueString(), data.Policy.ValueString()
+	err := retryOnConflict(ctx, updateTimeout, fmt.Sprintf("permission set %q", permSetID), func() error {
+		_, err := r.client.UpdatePermissionSetInlinePolicies(ctx, permSetID, func(policies map[string]string) map[string]string {
+			if existing, ok := policies[name]; ok && policyDocumentsEqual(existing, policy) {
+				return policies
+			}
+			if policies == nil {
+				policies = map[string]string{}
+			}
+			policies[name] = policy
+			return policies
+		})
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update inline policy %q, got error: %s", name, err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetInlinePolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PermissionSetInlinePolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", inlinePolicyTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, name := data.PermissionSetID.ValueString(), data.PolicyName.ValueString()
+	err := retryOnConflict(ctx, deleteTimeout, fmt.Sprintf("permission set %q", permSetID), func() error {
+		_, err := r.client.UpdatePermissionSetInlinePolicies(ctx, permSetID, func(policies map[string]string) map[string]string {
+			delete(policies, name)
+			return policies
+		})
+		return err
+	})
+	if err != nil {
+		// The permission set was deleted, and the policy with it
+		if isNotFoundError(err) {
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to remove inline policy %q, got error: %s", name, err))
+		return
+	}
+}
+
+func (r *PermissionSetInlinePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	permSetID, name, ok := strings.Cut(req.ID, "/")
+	if !ok || permSetID == "" || name == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form permission_set_id/policy_name, e.g. ps-123/s3-read, got %q.", req.ID),
+		)
+		return
+	}
+
+	// Read fills in policy from the permission set
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission_set_id"), permSetID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("policy_name"), name)...)
+}
+
+// inlinePolicyOwnershipHint explains the likely cause of a policy changed
+// behind a prism_permission_set_inline_policy's back, and of the opposite
+// case in prism_permission_set.
+const inlinePolicyOwnershipHint = "A prism_permission_set that sets inline_policies owns every inline policy of the permission set, " +
+	"and removes or rewrites any it doesn't list, so that it and prism_permission_set_inline_policy keep undoing each other. " +
+	"Either leave inline_policies unset on the permission set, or move the policy into inline_policies and remove the prism_permission_set_inline_policy."
+
+// inlinePolicyID returns the ID of the inline policy name of the permission
+// set with permSetID. Permission set IDs hold no "/", so the first one
+// separates the two.
+func inlinePolicyID(permSetID, name string) string {
+	return permSetID + "/" + name
+}
+
+// policyDocumentsEqual reports whether two policy documents are the same
+// once normalized with normalizePolicyJSON. Documents that aren't JSON are
+// compared as they are.
+func policyDocumentsEqual(a, b string) bool {
+	normalizedA, errA := normalizePolicyJSON(a)
+	normalizedB, errB := normalizePolicyJSON(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return normalizedA == normalizedB
+}
+
+// policyJSONValidator checks that a string is a single JSON document.
+type policyJSONValidator struct{}
+
+var _ validator.String = policyJSONValidator{}
+
+func (v policyJSONValidator) Description(ctx context.Context) string {
+	return "value must be a JSON policy document"
+}
+
+func (v policyJSONValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v policyJSONValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := normalizePolicyJSON(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Policy JSON",
+			fmt.Sprintf("The policy must be a JSON document, e.g. written with jsonencode(): %s", err),
+		)
+	}
+}
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	s3ReadPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	// s3ReadPolicyIndented is s3ReadPolicy as a heredoc would write it
+	s3ReadPolicyIndented = `{
+  "Statement": [
+    {
+      "Resource": "*",
+      "Action": "s3:GetObject",
+      "Effect": "Allow"
+    }
+  ],
+  "Version": "2012-10-17"
+}`
+	s3WritePolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:PutObject","Resource":"*"}]}`
+)
+
+func testInlinePolicyModel(permSetID, name, policy string) *PermissionSetInlinePolicyResourceModel {
+	return &PermissionSetInlinePolicyResourceModel{
+		ID:              types.StringUnknown(),
+		PermissionSetID: types.StringValue(permSetID),
+		PolicyName:      types.StringValue(name),
+		Policy:          types.StringValue(policy),
+		Timeouts:        nullTimeouts("create", "update", "delete"),
+	}
+}
+
+func TestPermissionSetInlinePolicy_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID := sharedPermissionSet(fake)
+	h := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+
+	state, diags := h.create(testInlinePolicyModel(permSetID, "s3", s3ReadPolicyIndented))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if got, want := h.attr(state, "id"), "ps-1/s3"; got != want {
+		t.Errorf("expected id %q, got %q", want, got)
+	}
+	if got := fake.permSets[permSetID].InlinePolicies["base"]; got != emptyInlinePolicy {
+		t.Errorf("expected the unmanaged policy to be kept, got %q", got)
+	}
+
+	// The backend's compact form of the document isn't drift
+	fake.permSets[permSetID].InlinePolicies["s3"] = s3ReadPolicy
+	state, diags = h.read(state)
+	if diags.HasError() || diags.WarningsCount() != 0 {
+		t.Fatalf("read: expected no diagnostics, got %v", diags)
+	}
+	if got := h.attr(state, "policy"); got != s3ReadPolicyIndented {
+		t.Errorf("expected the configured document to be kept, got %q", got)
+	}
+
+	// A document changed outside Terraform is
+	fake.permSets[permSetID].InlinePolicies["s3"] = s3WritePolicy
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Inline Policy Changed") {
+		t.Errorf("expected an Inline Policy Changed warning, got %v", diags)
+	}
+	if got := h.attr(state, "policy"); got != s3WritePolicy {
+		t.Errorf("expected the changed document %q, got %q", s3WritePolicy, got)
+	}
+
+	var data PermissionSetInlinePolicyResourceModel
+	h.get(state, &data)
+	data.Policy = types.StringValue(s3ReadPolicy)
+	state, diags = h.update(state, &data)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	if got := fake.permSets[permSetID].InlinePolicies["s3"]; got != s3ReadPolicy {
+		t.Errorf("expected the update to write %q, got %q", s3ReadPolicy, got)
+	}
+
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	want := map[string]string{"base": emptyInlinePolicy}
+	if got := fake.permSets[permSetID].InlinePolicies; len(got) != 1 || got["base"] != want["base"] {
+		t.Errorf("expected inline policies %v after delete, got %v", want, got)
+	}
+
+	// A policy removed outside Terraform leaves state
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !state.Raw.IsNull() {
+		t.Error("expected a removed policy to be removed from state")
+	}
+}
+
+func TestPermissionSetInlinePolicy_TwoOnOneSet(t *testing.T) {
+	fake := newFakePrism()
+	permSetID := sharedPermissionSet(fake)
+	client := newTestClient(t, slowPermissionSetWrites(fake))
+	h := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+
+	names := []string{"s3-read", "s3-write"}
+	policies := []string{s3ReadPolicy, s3WritePolicy}
+	states := make([]struct {
+		state tfsdk.State
+		diags diag.Diagnostics
+	}, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states[i].state, states[i].diags = h.create(testInlinePolicyModel(permSetID, names[i], policies[i]))
+		}()
+	}
+	wg.Wait()
+	for i, s := range states {
+		if s.diags.HasError() {
+			t.Fatalf("create %s: %v", names[i], s.diags)
+		}
+	}
+
+	got := fake.permSets[permSetID].InlinePolicies
+	if len(got) != 3 || got["s3-read"] != s3ReadPolicy || got["s3-write"] != s3WritePolicy {
+		t.Fatalf("expected both policies next to the unmanaged one, got %v", got)
+	}
+
+	if diags := h.delete(states[0].state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if _, ok := fake.permSets[permSetID].InlinePolicies["s3-write"]; !ok {
+		t.Error("expected removing one policy to keep the other")
+	}
+}
+
+func TestPermissionSetInlinePolicy_ExistingName(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID := sharedPermissionSet(fake)
+	h := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+
+	// Another document under the same name belongs to someone else
+	_, diags := h.create(testInlinePolicyModel(permSetID, "base", s3ReadPolicy))
+	if !hasDiagnostic(diags, "Inline Policy Already Exists") {
+		t.Fatalf("expected an Inline Policy Already Exists error, got %v", diags)
+	}
+	if got := fake.permSets[permSetID].InlinePolicies["base"]; got != emptyInlinePolicy {
+		t.Errorf("expected the existing policy to be left alone, got %q", got)
+	}
+
+	// The same document is adopted
+	if _, diags := h.create(testInlinePolicyModel(permSetID, "base", `{"Statement": [], "Version": "2012-10-17"}`)); diags.HasError() {
+		t.Errorf("expected an equal document to be adopted, got %v", diags)
+	}
+}
+
+func TestPermissionSetInlinePolicy_ParentWithoutInlinePolicies(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+
+	permSets := newResourceHarness(t, NewPermissionSetResource(), client)
+	parent := testPermissionSetModel(t, "Shared", "shared by several teams")
+	parent.ManagedPolicies = types.ListValueMust(types.StringType, []attr.Value{types.StringValue(readOnlyAccessARN)})
+	parent.InlinePolicies = types.MapNull(types.StringType)
+	parentState, diags := permSets.create(parent)
+	if diags.HasError() {
+		t.Fatalf("create permission set: %v", diags)
+	}
+	permSetID := permSets.attr(parentState, "id")
+
+	policies := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+	if _, diags := policies.create(testInlinePolicyModel(permSetID, "s3", s3ReadPolicy)); diags.HasError() {
+		t.Fatalf("create inline policy: %v", diags)
+	}
+
+	// The permission set doesn't pick up the policy as drift
+	parentState, diags = permSets.read(parentState)
+	if diags.HasError() {
+		t.Fatalf("read permission set: %v", diags)
+	}
+	var data PermissionSetResourceModel
+	permSets.get(parentState, &data)
+	if !data.InlinePolicies.IsNull() {
+		t.Errorf("expected inline_policies to stay null, got %s", data.InlinePolicies)
+	}
+
+	// and updating it keeps the policy
+	data.Description = types.StringValue("updated")
+	if _, diags := permSets.update(parentState, &data); diags.HasError() {
+		t.Fatalf("update permission set: %v", diags)
+	}
+	if got := fake.permSets[permSetID].InlinePolicies["s3"]; got != s3ReadPolicy {
+		t.Errorf("expected the update to keep the inline policy, got %q", got)
+	}
+	if got := fake.permSets[permSetID].Description; got != "updated" {
+		t.Errorf("expected description to be updated, got %q", got)
+	}
+}
+
+func TestPermissionSetInlinePolicy_ParentWithInlinePolicies(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+
+	permSets := newResourceHarness(t, NewPermissionSetResource(), client)
+	parent := testPermissionSetModel(t, "Shared", "shared by several teams")
+	parent.ManagedPolicies = types.ListNull(types.StringType)
+	parent.InlinePolicies = types.MapValueMust(types.StringType, map[string]attr.Value{"base": types.StringValue(emptyInlinePolicy)})
+	parentState, diags := permSets.create(parent)
+	if diags.HasError() {
+		t.Fatalf("create permission set: %v", diags)
+	}
+	permSetID := permSets.attr(parentState, "id")
+
+	policies := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+	policyState, diags := policies.create(testInlinePolicyModel(permSetID, "s3", s3ReadPolicy))
+	if diags.HasError() {
+		t.Fatalf("create inline policy: %v", diags)
+	}
+
+	// The permission set owns every inline policy, and says so about the
+	// one it doesn't list
+	parentState, diags = permSets.read(parentState)
+	if diags.HasError() {
+		t.Fatalf("read permission set: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Inline Policies Not in Configuration") {
+		t.Errorf("expected an Inline Policies Not in Configuration warning, got %v", diags)
+	}
+
+	// Applying its configuration removes the policy, and the inline policy
+	// resource names the likely cause
+	var data PermissionSetResourceModel
+	permSets.get(parentState, &data)
+	data.InlinePolicies = parent.InlinePolicies
+	if _, diags := permSets.update(parentState, &data); diags.HasError() {
+		t.Fatalf("update permission set: %v", diags)
+	}
+	policyState, diags = policies.read(policyState)
+	if diags.HasError() {
+		t.Fatalf("read inline policy: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Inline Policy Removed") {
+		t.Errorf("expected an Inline Policy Removed warning, got %v", diags)
+	}
+	if !policyState.Raw.IsNull() {
+		t.Error("expected the removed policy to leave state")
+	}
+}
+
+func TestPermissionSetInlinePolicy_Import(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID := sharedPermissionSet(fake)
+	h := newResourceHarness(t, NewPermissionSetInlinePolicyResource(), client)
+
+	state, diags := h.importState(permSetID + "/base")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+	if diags.WarningsCount() != 0 {
+		t.Errorf("expected no warnings on import, got %v", diags)
+	}
+	if got := h.attr(state, "policy"); got != emptyInlinePolicy {
+		t.Errorf("expected policy %q, got %q", emptyInlinePolicy, got)
+	}
+
+	for _, id := range []string{"base", permSetID + "/", "/base"} {
+		if _, diags := h.importState(id); !hasDiagnostic(diags, "Invalid Import ID") {
+			t.Errorf("import %q: expected an Invalid Import ID error, got %v", id, diags)
+		}
+	}
+}
+
+func TestPolicyJSONValidator(t *testing.T) {
+	for value, wantErr := range map[string]bool{
+		s3ReadPolicyIndented: false,
+		"not json":           true,
+		`{"Version":`:        true,
+		s3ReadPolicy + " }":  true,
+		s3ReadPolicy + "\n":  false,
+	} {
+		req := validator.StringRequest{Path: path.Root("policy"), ConfigValue: types.StringValue(value)}
+		var resp validator.StringResponse
+		policyJSONValidator{}.ValidateString(context.Background(), req, &resp)
+		if got := resp.Diagnostics.HasError(); got != wantErr {
+			t.Errorf("%q: expected error %v, got %v", value, wantErr, resp.Diagnostics)
+		}
+	}
+}
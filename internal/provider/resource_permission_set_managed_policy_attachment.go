@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &PermissionSetManagedPolicyAttachmentResource{}
+var _ resource.ResourceWithImportState = &PermissionSetManagedPolicyAttachmentResource{}
+var _ resource.ResourceWithModifyPlan = &PermissionSetManagedPolicyAttachmentResource{}
+
+func NewPermissionSetManagedPolicyAttachmentResource() resource.Resource {
+	return &PermissionSetManagedPolicyAttachmentResource{}
+}
+
+type PermissionSetManagedPolicyAttachmentResource struct {
+	client *Client
+}
+
+// Default timeout for attaching or detaching a policy, including retries of
+// updates that conflict with other changes to the permission set
+const managedPolicyAttachmentTimeout = 2 * time.Minute
+
+type PermissionSetManagedPolicyAttachmentResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	PermissionSetID types.String `tfsdk:"permission_set_id"`
+	PolicyARN       types.String `tfsdk:"policy_arn"`
+	Timeouts        types.Object `tfsdk:"timeouts"`
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_set_managed_policy_attachment"
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Attaches one AWS managed policy to a permission set, leaving its other managed policies alone. " +
+			"Use this resource when different teams manage the policies of a shared permission set, and leave `managed_policies` unset on the matching `prism_permission_set`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The permission set ID and policy ARN, separated by `/`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"permission_set_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The ID of the permission set, e.g. `prism_permission_set.example.id`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy_arn": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The ARN of the managed policy to attach, e.g. `arn:aws:iam::aws:policy/ReadOnlyAccess`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"create": managedPolicyAttachmentTimeout,
+				"delete": managedPolicyAttachmentTimeout,
+			}),
+		},
+	}
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan checks that policy_arn looks like an IAM policy ARN, as
+// prism_permission_set does for managed_policies, unless the provider's
+// validate_policy_arns is false.
+func (r *PermissionSetManagedPolicyAttachmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil || !r.client.ValidatePolicyARNs {
+		return
+	}
+
+	var policyARN types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("policy_arn"), &policyARN)...)
+	if resp.Diagnostics.HasError() || policyARN.IsNull() || policyARN.IsUnknown() {
+		return
+	}
+
+	if !policyARNPattern.MatchString(policyARN.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("policy_arn"),
+			"Invalid Managed Policy ARN",
+			fmt.Sprintf("%q is not an IAM policy ARN such as \"arn:aws:iam::aws:policy/ReadOnlyAccess\" or \"arn:aws:iam::123456789012:policy/MyPolicy\". "+
+				"If it's valid in a partition this check doesn't know, set validate_policy_arns = false in the provider configuration.", policyARN.ValueString()),
+		)
+	}
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PermissionSetManagedPolicyAttachmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resolveTimeout(data.Timeouts, "create", managedPolicyAttachmentTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, policyARN := data.PermissionSetID.ValueString(), data.PolicyARN.ValueString()
+	err := retryOnConflict(ctx, createTimeout, fmt.Sprintf("permission set %q", permSetID), func() error {
+		_, err := r.client.UpdatePermissionSetManagedPolicies(ctx, permSetID, func(policies []string) []string {
+			if slices.Contains(policies, policyARN) {
+				return policies
+			}
+			return append(policies, policyARN)
+		})
+		return err
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to attach managed policy %s, got error: %s", policyARN, err))
+		return
+	}
+
+	data.ID = types.StringValue(managedPolicyAttachmentID(permSetID, policyARN))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PermissionSetManagedPolicyAttachmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSet, err := r.client.GetPermissionSet(data.PermissionSetID.ValueString())
+	if err != nil {
+		// If the permission set was deleted (404), the attachment went with it
+		if isNotFoundError(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read permission set, got error: %s", err))
+		return
+	}
+
+	// The policy was detached outside Terraform
+	if !slices.Contains(permSet.ManagedPolicies, data.PolicyARN.ValueString()) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.ID = types.StringValue(managedPolicyAttachmentID(data.PermissionSetID.ValueString(), data.PolicyARN.ValueString()))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Every other attribute requires replacement, so only timeouts change here
+	var data PermissionSetManagedPolicyAttachmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PermissionSetManagedPolicyAttachmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", managedPolicyAttachmentTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permSetID, policyARN := data.PermissionSetID.ValueString(), data.PolicyARN.ValueString()
+	err := retryOnConflict(ctx, deleteTimeout, fmt.Sprintf("permission set %q", permSetID), func() error {
+		_, err := r.client.UpdatePermissionSetManagedPolicies(ctx, permSetID, func(policies []string) []string {
+			return slices.DeleteFunc(policies, func(policy string) bool { return policy == policyARN })
+		})
+		return err
+	})
+	if err != nil {
+		// The permission set was deleted, and the attachment with it
+		if isNotFoundError(err) {
+			return
+		}
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to detach managed policy %s, got error: %s", policyARN, err))
+		return
+	}
+}
+
+func (r *PermissionSetManagedPolicyAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	permSetID, policyARN, ok := strings.Cut(req.ID, "/")
+	if !ok || permSetID == "" || !strings.HasPrefix(policyARN, "arn:") {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			fmt.Sprintf("Expected an import ID of the form permission_set_id/policy_arn, e.g. ps-123/arn:aws:iam::aws:policy/ReadOnlyAccess, got %q.", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission_set_id"), permSetID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("policy_arn"), policyARN)...)
+}
+
+// managedPolicyAttachmentID returns the ID of the attachment of policyARN to
+// the permission set with permSetID. Permission set IDs hold no "/", so the
+// first one separates the two.
+func managedPolicyAttachmentID(permSetID, policyARN string) string {
+	return permSetID + "/" + policyARN
+}
@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	readOnlyAccessARN = "arn:aws:iam::aws:policy/ReadOnlyAccess"
+	viewOnlyAccessARN = "arn:aws:iam::aws:policy/job-function/ViewOnlyAccess"
+	securityAuditARN  = "arn:aws:iam::aws:policy/SecurityAudit"
+	emptyInlinePolicy = `{"Version":"2012-10-17","Statement":[]}`
+)
+
+func testManagedPolicyAttachmentModel(permSetID, policyARN string) *PermissionSetManagedPolicyAttachmentResourceModel {
+	return &PermissionSetManagedPolicyAttachmentResourceModel{
+		ID:              types.StringUnknown(),
+		PermissionSetID: types.StringValue(permSetID),
+		PolicyARN:       types.StringValue(policyARN),
+		Timeouts:        nullTimeouts("create", "delete"),
+	}
+}
+
+// sharedPermissionSet adds a permission set with one managed policy that no
+// attachment manages, as another team's resource would leave it.
+func sharedPermissionSet(fake *fakePrism) string {
+	fake.permSets["ps-1"] = &PermissionSet{
+		ID:              "ps-1",
+		Name:            "Shared",
+		ManagedPolicies: []string{securityAuditARN},
+		InlinePolicies:  map[string]string{"base": emptyInlinePolicy},
+	}
+	return "ps-1"
+}
+
+// slowPermissionSetWrites delays permission set updates, so that a
+// read-modify-write that isn't serialized with another reads the policies
+// before the other's write lands, and then drops its policy.
+func slowPermissionSetWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/permission-sets/") {
+			time.Sleep(100 * time.Millisecond)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestPermissionSetManagedPolicyAttachment_TwoOnOneSet(t *testing.T) {
+	fake := newFakePrism()
+	permSetID := sharedPermissionSet(fake)
+	client := newTestClient(t, slowPermissionSetWrites(fake))
+	h := newResourceHarness(t, NewPermissionSetManagedPolicyAttachmentResource(), client)
+
+	// Terraform creates independent resources in parallel
+	arns := []string{readOnlyAccessARN, viewOnlyAccessARN}
+	states := make([]struct {
+		state tfsdk.State
+		diags diag.Diagnostics
+	}, len(arns))
+	var wg sync.WaitGroup
+	for i, arn := range arns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states[i].state, states[i].diags = h.create(testManagedPolicyAttachmentModel(permSetID, arn))
+		}()
+	}
+	wg.Wait()
+	for i, s := range states {
+		if s.diags.HasError() {
+			t.Fatalf("create %s: %v", arns[i], s.diags)
+		}
+	}
+
+	want := []string{securityAuditARN, readOnlyAccessARN, viewOnlyAccessARN}
+	if got := fake.permSets[permSetID].ManagedPolicies; !sameElements(got, want) {
+		t.Fatalf("expected managed policies %v, got %v", want, got)
+	}
+	if got, want := h.attr(states[0].state, "id"), "ps-1/"+readOnlyAccessARN; got != want {
+		t.Errorf("expected id %q, got %q", want, got)
+	}
+
+	// Detaching one leaves the other and the unmanaged policy
+	if diags := h.delete(states[0].state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if got, want := fake.permSets[permSetID].ManagedPolicies, []string{securityAuditARN, viewOnlyAccessARN}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected managed policies %v after detaching, got %v", want, got)
+	}
+	refreshed, diags := h.read(states[1].state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if refreshed.Raw.IsNull() {
+		t.Error("expected the remaining attachment to stay in state")
+	}
+
+	// A policy detached outside Terraform leaves state
+	fake.permSets[permSetID].ManagedPolicies = []string{securityAuditARN}
+	refreshed, diags = h.read(states[1].state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !refreshed.Raw.IsNull() {
+		t.Error("expected a detached policy to be removed from state")
+	}
+}
+
+func sameElements(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	count := map[string]int{}
+	for _, s := range got {
+		count[s]++
+	}
+	for _, s := range want {
+		if count[s]--; count[s] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPermissionSetManagedPolicyAttachment_RetriesConflicts(t *testing.T) {
+	fake := newFakePrism()
+	permSetID := sharedPermissionSet(fake)
+	conflicts := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Another writer changes the permission set between the first read
+		// and write, and the backend refuses the stale write
+		if r.Method == http.MethodPut && conflicts == 0 {
+			conflicts++
+			fake.mu.Lock()
+			fake.permSets[permSetID].ManagedPolicies = append(fake.permSets[permSetID].ManagedPolicies, viewOnlyAccessARN)
+			fake.mu.Unlock()
+			writeAPIError(w, http.StatusConflict, "permission set was modified")
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	h := newResourceHarness(t, NewPermissionSetManagedPolicyAttachmentResource(), client)
+
+	if _, diags := h.create(testManagedPolicyAttachmentModel(permSetID, readOnlyAccessARN)); diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	want := []string{securityAuditARN, viewOnlyAccessARN, readOnlyAccessARN}
+	if got := fake.permSets[permSetID].ManagedPolicies; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the retry to keep the other writer's policy, got %v", got)
+	}
+}
+
+func TestPermissionSetManagedPolicyAttachment_ParentWithoutManagedPolicies(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+
+	permSets := newResourceHarness(t, NewPermissionSetResource(), client)
+	parent := testPermissionSetModel(t, "Shared", "shared by several teams")
+	parent.ManagedPolicies = types.ListNull(types.StringType)
+	parent.InlinePolicies = types.MapValueMust(types.StringType, map[string]attr.Value{"base": types.StringValue(emptyInlinePolicy)})
+	parentState, diags := permSets.create(parent)
+	if diags.HasError() {
+		t.Fatalf("create permission set: %v", diags)
+	}
+	permSetID := permSets.attr(parentState, "id")
+
+	attachments := newResourceHarness(t, NewPermissionSetManagedPolicyAttachmentResource(), client)
+	if _, diags := attachments.create(testManagedPolicyAttachmentModel(permSetID, readOnlyAccessARN)); diags.HasError() {
+		t.Fatalf("create attachment: %v", diags)
+	}
+
+	// The permission set doesn't pick up the attached policy as drift
+	parentState, diags = permSets.read(parentState)
+	if diags.HasError() {
+		t.Fatalf("read permission set: %v", diags)
+	}
+	var data PermissionSetResourceModel
+	permSets.get(parentState, &data)
+	if !data.ManagedPolicies.IsNull() {
+		t.Errorf("expected managed_policies to stay null, got %s", data.ManagedPolicies)
+	}
+
+	// and updating it keeps the attached policy
+	data.Description = types.StringValue("updated")
+	if _, diags := permSets.update(parentState, &data); diags.HasError() {
+		t.Fatalf("update permission set: %v", diags)
+	}
+	if got, want := fake.permSets[permSetID].ManagedPolicies, []string{readOnlyAccessARN}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the update to keep managed policies %v, got %v", want, got)
+	}
+	if got := fake.permSets[permSetID].Description; got != "updated" {
+		t.Errorf("expected description to be updated, got %q", got)
+	}
+}
+
+func TestPermissionSetManagedPolicyAttachment_Import(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	permSetID := sharedPermissionSet(fake)
+	h := newResourceHarness(t, NewPermissionSetManagedPolicyAttachmentResource(), client)
+
+	state, diags := h.importState(permSetID + "/" + securityAuditARN)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+	if got := h.attr(state, "policy_arn"); got != securityAuditARN {
+		t.Errorf("expected policy_arn %q, got %q", securityAuditARN, got)
+	}
+
+	if _, diags := h.importState(securityAuditARN); !hasDiagnostic(diags, "Invalid Import ID") {
+		t.Errorf("expected an Invalid Import ID error, got %v", diags)
+	}
+}
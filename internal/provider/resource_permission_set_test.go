@@ -0,0 +1,427 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testPermissionSetModel(t *testing.T, name, description string) *PermissionSetResourceModel {
+	t.Helper()
+
+	managedPolicies, diags := types.ListValueFrom(context.Background(), types.StringType, []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"})
+	if diags.HasError() {
+		t.Fatalf("building managed_policies: %v", diags)
+	}
+	return &PermissionSetResourceModel{
+		ID:              types.StringUnknown(),
+		Name:            types.StringValue(name),
+		Description:     types.StringValue(description),
+		SessionDuration: types.StringValue("PT4H"),
+		ManagedPolicies: managedPolicies,
+		InlinePolicies:  types.MapNull(types.StringType),
+		Timeouts:        nullTimeouts("delete"),
+	}
+}
+
+func TestAccPermissionSetResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	name := accName("readonly")
+
+	// Create
+	state, diags := h.create(testPermissionSetModel(t, name, "Initial"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := h.attr(state, "id")
+	if id == "" {
+		t.Fatal("expected id to be set after create")
+	}
+
+	// Update
+	plan := testPermissionSetModel(t, name, "Updated")
+	plan.ID = types.StringValue(id)
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+
+	// Import
+	imported, diags := h.importState(id)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got := h.attr(imported, "description"); got != "Updated" {
+		t.Errorf("expected imported description %q, got %q", "Updated", got)
+	}
+	if got := h.attr(imported, "session_duration"); got != "PT4H" {
+		t.Errorf("expected imported session_duration PT4H, got %q", got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil {
+		if _, ok := fake.permSets[id]; ok {
+			t.Error("expected permission set to be deleted")
+		}
+	}
+}
+
+func TestAccPermissionSetResource_ChangedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+
+	state, diags := h.create(testPermissionSetModel(t, "readonly", "Initial"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.permSets[h.attr(state, "id")].SessionDuration = "PT1H"
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := h.attr(state, "session_duration"); got != "PT1H" {
+		t.Errorf("expected read to pick up the out-of-band change, got %q", got)
+	}
+
+	// Policies removed outside Terraform are drift too
+	fake.permSets[h.attr(state, "id")].ManagedPolicies = nil
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	var data PermissionSetResourceModel
+	h.get(state, &data)
+	if !data.ManagedPolicies.IsNull() {
+		t.Errorf("expected managed_policies to be unset, got %s", data.ManagedPolicies)
+	}
+}
+
+func TestAccPermissionSetResource_DestroyRemovesAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+
+	state, diags := h.create(testPermissionSetModel(t, "readonly", ""))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := h.attr(state, "id")
+	fake.assignments["asgn-x"] = &PermissionSetAssignment{ID: "asgn-x", PermissionSetID: id, PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if detail := diagnosticDetail(diags, "Automatic Assignment Cleanup"); !strings.Contains(detail, "  - asgn-x (permission set "+id+", USER alice, account 111111111111)\n") {
+		t.Errorf("expected an Automatic Assignment Cleanup warning listing asgn-x, got %v", diags)
+	}
+	if len(fake.assignments) != 0 || len(fake.permSets) != 0 {
+		t.Errorf("expected assignments and permission set to be deleted, got %d and %d", len(fake.assignments), len(fake.permSets))
+	}
+}
+
+func TestPermissionSetDelete_CleansUpAssignmentsConcurrently(t *testing.T) {
+	fake := newFakePrism()
+	var inFlight, maxInFlight, listCalls int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/permission-set-assignments/"):
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				prev := atomic.LoadInt32(&maxInFlight)
+				if n <= prev || atomic.CompareAndSwapInt32(&maxInFlight, prev, n) {
+					break
+				}
+			}
+			// Hold the request so concurrent deletes overlap
+			time.Sleep(20 * time.Millisecond)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-set-assignments"):
+			atomic.AddInt32(&listCalls, 1)
+		}
+		fake.ServeHTTP(w, r)
+	}))
+
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	state, diags := h.create(testPermissionSetModel(t, "readonly", ""))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	permSetID := h.attr(state, "id")
+
+	const assignmentCount = 50
+	for i := 0; i < assignmentCount; i++ {
+		id := fmt.Sprintf("asgn-bulk-%d", i)
+		fake.assignments[id] = &PermissionSetAssignment{ID: id, PermissionSetID: permSetID, PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+	}
+
+	start := time.Now()
+	diags = h.delete(state)
+	elapsed := time.Since(start)
+	if diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got < 2 || got > assignmentCleanupConcurrency {
+		t.Errorf("expected between 2 and %d concurrent deletes, got %d", assignmentCleanupConcurrency, got)
+	}
+	// One list to find the assignments, one to confirm they are gone
+	if got := atomic.LoadInt32(&listCalls); got != 2 {
+		t.Errorf("expected 2 list calls, got %d", got)
+	}
+	if elapsed >= assignmentCleanupInitialBackoff+assignmentCount*20*time.Millisecond/assignmentCleanupConcurrency {
+		t.Errorf("expected the wait to end as soon as the list was clear, took %v", elapsed)
+	}
+	if len(fake.assignments) != 0 || len(fake.permSets) != 0 {
+		t.Errorf("expected assignments and permission set to be deleted, got %d and %d", len(fake.assignments), len(fake.permSets))
+	}
+}
+
+func TestPermissionSetDelete_ProtectsAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	client.CleanupAssignmentsOnDelete = false
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+
+	state, diags := h.create(testPermissionSetModel(t, "readonly", ""))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := h.attr(state, "id")
+	fake.assignments["asgn-x"] = &PermissionSetAssignment{ID: "asgn-x", PermissionSetID: id, PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if !hasDiagnostic(diags, "Active Permission Set Assignments") {
+		t.Fatalf("expected an Active Permission Set Assignments error, got %v", diags)
+	}
+	if !strings.Contains(diags.Errors()[0].Detail(), "asgn-x") {
+		t.Errorf("expected the blocking assignment to be listed, got %s", diags.Errors()[0].Detail())
+	}
+	if _, ok := fake.permSets[id]; !ok {
+		t.Error("expected permission set to be kept")
+	}
+}
+
+func TestPermissionSetResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.permSets["ps-42"] = &PermissionSet{
+		ID:              "ps-42",
+		Name:            "ReadOnly",
+		SessionDuration: "PT2H",
+		ManagedPolicies: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+		InlinePolicies:  map[string]string{"s3": `{"Version":"2012-10-17","Statement":[]}`},
+	}
+
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	state, diags := h.importState("ps-42")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+
+	var data PermissionSetResourceModel
+	h.get(state, &data)
+	if data.Name.ValueString() != "ReadOnly" || data.SessionDuration.ValueString() != "PT2H" {
+		t.Errorf("expected name ReadOnly and session_duration PT2H, got %s and %s", data.Name, data.SessionDuration)
+	}
+	if len(data.ManagedPolicies.Elements()) != 1 || len(data.InlinePolicies.Elements()) != 1 {
+		t.Errorf("expected policies to be imported, got %s and %s", data.ManagedPolicies, data.InlinePolicies)
+	}
+}
+
+func TestPermissionSetResource_StatusFields(t *testing.T) {
+	tests := map[string]struct {
+		payload                                    string
+		wantCreated, wantUpdated, wantProvisioning string
+	}{
+		"reported": {
+			payload:          `{"id": "ps-42", "name": "ReadOnly", "created_at": "2024-03-01T09:30:00Z", "updated_at": "2024-05-12T17:04:05+02:00", "provisioning_status": "PROVISIONED"}`,
+			wantCreated:      "2024-03-01T09:30:00Z",
+			wantUpdated:      "2024-05-12T17:04:05+02:00",
+			wantProvisioning: "PROVISIONED",
+		},
+		"older backend": {
+			payload: `{"id": "ps-42", "name": "ReadOnly"}`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeAPIData(w, json.RawMessage(tt.payload))
+			}))
+
+			check := func(source string, createdAt, updatedAt, provisioningStatus types.String) {
+				for _, c := range []struct {
+					attribute string
+					got       types.String
+					want      string
+				}{
+					{"created_at", createdAt, tt.wantCreated},
+					{"updated_at", updatedAt, tt.wantUpdated},
+					{"provisioning_status", provisioningStatus, tt.wantProvisioning},
+				} {
+					if c.got.IsNull() != (c.want == "") || c.got.ValueString() != c.want {
+						t.Errorf("%s: expected %s %q, got %s", source, c.attribute, c.want, c.got)
+					}
+				}
+			}
+
+			h := newResourceHarness(t, NewPermissionSetResource(), client)
+			state, diags := h.importState("ps-42")
+			if diags.HasError() {
+				t.Fatalf("import: %v", diags)
+			}
+			var resourceData PermissionSetResourceModel
+			h.get(state, &resourceData)
+			check("resource", resourceData.CreatedAt, resourceData.UpdatedAt, resourceData.ProvisioningStatus)
+
+			state, diags = readDataSource(t, NewPermissionSetDataSource(), client, &PermissionSetDataSourceModel{
+				ID:              types.StringValue("ps-42"),
+				ManagedPolicies: types.ListNull(types.StringType),
+				InlinePolicies:  types.MapNull(types.StringType),
+			})
+			if diags.HasError() {
+				t.Fatalf("data source: %v", diags)
+			}
+			var dataSourceData PermissionSetDataSourceModel
+			if diags := state.Get(context.Background(), &dataSourceData); diags.HasError() {
+				t.Fatal(diags)
+			}
+			check("data source", dataSourceData.CreatedAt, dataSourceData.UpdatedAt, dataSourceData.ProvisioningStatus)
+		})
+	}
+}
+
+func TestPermissionSetResource_ValidateConfig(t *testing.T) {
+	policies := func(arns ...string) types.List {
+		values := make([]attr.Value, len(arns))
+		for i, arn := range arns {
+			values[i] = types.StringValue(arn)
+		}
+		return types.ListValueMust(types.StringType, values)
+	}
+	manyPolicies := make([]string, maxManagedPolicies+1)
+	for i := range manyPolicies {
+		manyPolicies[i] = fmt.Sprintf("arn:aws:iam::aws:policy/Policy%d", i)
+	}
+	inline := types.MapValueMust(types.StringType, map[string]attr.Value{"s3": types.StringValue(`{"Version":"2012-10-17","Statement":[]}`)})
+
+	tests := []struct {
+		name            string
+		managedPolicies types.List
+		inlinePolicies  types.Map
+		wantError       string
+	}{
+		{name: "managed only", managedPolicies: policies("arn:aws:iam::aws:policy/ReadOnlyAccess"), inlinePolicies: types.MapNull(types.StringType)},
+		{name: "inline only", managedPolicies: types.ListNull(types.StringType), inlinePolicies: inline},
+		{name: "no policies", managedPolicies: types.ListNull(types.StringType), inlinePolicies: types.MapNull(types.StringType), wantError: "Missing Policies"},
+		{name: "empty policies", managedPolicies: policies(), inlinePolicies: types.MapValueMust(types.StringType, map[string]attr.Value{}), wantError: "Missing Policies"},
+		{name: "unknown managed policies", managedPolicies: types.ListUnknown(types.StringType), inlinePolicies: types.MapNull(types.StringType)},
+		{name: "unknown inline policies", managedPolicies: types.ListNull(types.StringType), inlinePolicies: types.MapUnknown(types.StringType)},
+		{name: "at the limit", managedPolicies: policies(manyPolicies[:maxManagedPolicies]...), inlinePolicies: types.MapNull(types.StringType)},
+		{name: "too many", managedPolicies: policies(manyPolicies...), inlinePolicies: types.MapNull(types.StringType), wantError: "Too Many Managed Policies"},
+		{
+			name:            "duplicate",
+			managedPolicies: policies("arn:aws:iam::aws:policy/ReadOnlyAccess", "arn:aws:iam::aws:policy/Billing", "arn:aws:iam::aws:policy/ReadOnlyAccess"),
+			inlinePolicies:  types.MapNull(types.StringType),
+			wantError:       "Duplicate Managed Policy",
+		},
+		{
+			name:            "unknown element",
+			managedPolicies: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("arn:aws:iam::aws:policy/ReadOnlyAccess"), types.StringUnknown()}),
+			inlinePolicies:  types.MapNull(types.StringType),
+		},
+	}
+
+	h := newResourceHarness(t, NewPermissionSetResource(), nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testPermissionSetModel(t, "readonly", "")
+			config.ID = types.StringNull()
+			config.ManagedPolicies = tt.managedPolicies
+			config.InlinePolicies = tt.inlinePolicies
+
+			diags := h.validateConfig(config)
+			if tt.wantError == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", diags)
+				}
+				return
+			}
+			if diags.ErrorsCount() != 1 || !hasDiagnostic(diags, tt.wantError) {
+				t.Fatalf("expected one %q error, got %v", tt.wantError, diags)
+			}
+		})
+	}
+}
+
+func TestPermissionSetResource_ManagedPolicyARNs(t *testing.T) {
+	tests := []struct {
+		arn   string
+		valid bool
+	}{
+		{arn: "arn:aws:iam::aws:policy/ReadOnlyAccess", valid: true},
+		{arn: "arn:aws:iam::aws:policy/job-function/ViewOnlyAccess", valid: true},
+		{arn: "arn:aws-us-gov:iam::aws:policy/ReadOnlyAccess", valid: true},
+		{arn: "arn:aws-cn:iam::aws:policy/ReadOnlyAccess", valid: true},
+		{arn: "arn:aws:iam::123456789012:policy/team/DeployAccess", valid: true},
+		{arn: "arn:aws:iam:aws:policy/ReadOnlyAccess"},
+		{arn: "arn:aws:iam::12345:policy/DeployAccess"},
+		{arn: "arn:aws:iam::aws:role/ReadOnlyAccess"},
+		{arn: "arn:aws:iam::aws:policy/"},
+		{arn: "arn:aws:s3:::bucket"},
+		{arn: "ReadOnlyAccess"},
+	}
+
+	client := newTestClient(t, newFakePrism())
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			plan := testPermissionSetModel(t, "readonly", "")
+			plan.ManagedPolicies = types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("arn:aws:iam::aws:policy/Billing"),
+				types.StringValue(tt.arn),
+			})
+
+			diags := h.modifyPlan(plan)
+			if tt.valid {
+				if diags.HasError() {
+					t.Fatalf("unexpected diagnostics: %v", diags)
+				}
+				return
+			}
+			if diags.ErrorsCount() != 1 || !hasDiagnostic(diags, "Invalid Managed Policy ARN") {
+				t.Fatalf("expected one invalid ARN error, got %v", diags)
+			}
+			withPath, ok := diags.Errors()[0].(interface{ Path() path.Path })
+			if !ok || !withPath.Path().Equal(path.Root("managed_policies").AtListIndex(1)) {
+				t.Errorf("expected the error at managed_policies[1], got %v", diags)
+			}
+		})
+	}
+
+	// The check can be turned off for unusual partitions
+	client.ValidatePolicyARNs = false
+	plan := testPermissionSetModel(t, "readonly", "")
+	plan.ManagedPolicies = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("arn:aws-iso-f2:iam::aws:policy/ReadOnlyAccess")})
+	if diags := h.modifyPlan(plan); diags.HasError() {
+		t.Errorf("expected no errors with validate_policy_arns = false, got %v", diags)
+	}
+}
@@ -2,15 +2,21 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/convert"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -33,8 +39,18 @@ type UserResourceModel struct {
 	LastName   types.String `tfsdk:"last_name"`
 	Enabled    types.Bool   `tfsdk:"enabled"`
 	Attributes types.Map    `tfsdk:"attributes"`
+	Timeouts   types.Object `tfsdk:"timeouts"`
+
+	ForceRemoveFromGroups types.Bool `tfsdk:"force_remove_from_groups"`
+
+	CreatedAt   types.String `tfsdk:"created_at"`
+	LastLoginAt types.String `tfsdk:"last_login_at"`
 }
 
+// Default timeout for retrying a user delete that conflicts with membership
+// changes still being processed
+const userDeleteTimeout = 2 * time.Minute
+
 func (r *UserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_user"
 }
@@ -76,8 +92,36 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 			"attributes": schema.MapAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Custom attributes for the user",
+				MarkdownDescription: "Custom attributes for the user. Keys may only contain letters, digits, `_`, `.` and `-`, and can't be a key reserved by Prism, such as `username`, `email` or `LDAP_ID`.",
+				Validators: []validator.Map{
+					userAttributeKeysValidator{},
+				},
 			},
+			"force_remove_from_groups": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether deleting the user first removes them from the groups they're still a member of, when the Prism backend refuses to delete group members. Defaults to `true`; when `false`, such a delete fails until the memberships are removed.",
+			},
+			"created_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the user was created, in RFC 3339 format. Null if the Prism backend doesn't report it.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_login_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the user last logged in, in RFC 3339 format, as of the last refresh. Null if the user has never logged in or the Prism backend doesn't report it.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]time.Duration{
+				"delete": userDeleteTimeout,
+			}),
 		},
 	}
 }
@@ -130,11 +174,28 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		Attributes: apiAttributes,
 	}
 
+	// Read and Import find users by username, so two prism_user resources
+	// with the same username would silently share one user. Adopting an
+	// existing user takes an explicit import instead. A failed lookup isn't
+	// fatal: the backend still rejects a duplicate with a conflict.
+	if existing, err := r.client.GetUser(user.Username); err == nil {
+		addUserExistsError(&resp.Diagnostics, existing)
+		return
+	}
+
 	created, err := r.client.CreateUser(user)
 	if err != nil {
+		// Another resource with the same username created it first
+		if isConflictError(err) {
+			if existing, getErr := r.client.GetUser(user.Username); getErr == nil {
+				addUserExistsError(&resp.Diagnostics, existing)
+				return
+			}
+		}
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create user, got error: %s", err))
 		return
 	}
+	r.verifyApplied(user, &resp.Diagnostics)
 
 	data.ID = types.StringValue(created.ID)
 	data.Username = types.StringValue(created.Username)
@@ -153,25 +214,26 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		data.Enabled = types.BoolValue(created.Enabled)
 	}
 
+	// Keep the planned attributes when the API doesn't return them
 	if len(created.Attributes) > 0 {
-		// Convert map[string][]string from API to map[string]string for Terraform
-		tfAttributesMap := make(map[string]string)
-		for k, v := range created.Attributes {
-			if len(v) > 0 {
-				tfAttributesMap[k] = v[0] // Take first value
-			}
-		}
-		attributesMap, diags := types.MapValueFrom(ctx, types.StringType, tfAttributesMap)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.Attributes = attributesMap
+		data.Attributes = convert.StringMapFromMultiValue(created.Attributes)
 	}
+	data.CreatedAt, data.LastLoginAt = userTimestamps(created)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// addUserExistsError reports that a user with the planned username already
+// exists, which usually means that another prism_user resource in the
+// configuration has the same username.
+func addUserExistsError(diags *diag.Diagnostics, existing *User) {
+	diags.AddError("User Already Exists", fmt.Sprintf(
+		"A user with username %q already exists (ID %s). If another prism_user resource has the same username, remove one of them: "+
+			"both would manage the same user and overwrite each other's changes. To manage the existing user with this resource, import it with "+
+			"terraform import <resource address> %s",
+		existing.Username, existing.ID, existing.Username))
+}
+
 func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data UserResourceModel
 
@@ -183,7 +245,7 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	user, err := r.client.GetUser(data.Username.ValueString())
 	if err != nil {
 		// If the resource is not found (404), remove it from state
-		if strings.Contains(err.Error(), "404") {
+		if isNotFoundError(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -191,38 +253,36 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	if user.ID != "" {
+		data.ID = types.StringValue(user.ID)
+	}
 	data.Username = types.StringValue(user.Username)
 	// Only update email if API returned a non-empty value
 	if user.Email != "" {
 		data.Email = types.StringValue(user.Email)
 	}
+	// A name cleared in Prism is drift from the configured one
+	data.FirstName = types.StringNull()
 	if user.FirstName != "" {
 		data.FirstName = types.StringValue(user.FirstName)
 	}
+	data.LastName = types.StringNull()
 	if user.LastName != "" {
 		data.LastName = types.StringValue(user.LastName)
 	}
-	// Only update enabled if it's explicitly true (preserve state value if API returns false/default)
-	if user.Enabled {
+	// Only update enabled if it's explicitly true (preserve state value if API returns false/default).
+	// On import there is no state value to preserve, so take the API value.
+	if user.Enabled || data.Enabled.IsNull() {
 		data.Enabled = types.BoolValue(user.Enabled)
 	}
-
-	if len(user.Attributes) > 0 {
-		// Convert map[string][]string from API to map[string]string for Terraform
-		tfAttributesMap := make(map[string]string)
-		for k, v := range user.Attributes {
-			if len(v) > 0 {
-				tfAttributesMap[k] = v[0] // Take first value
-			}
-		}
-		attributesMap, diags := types.MapValueFrom(ctx, types.StringType, tfAttributesMap)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.Attributes = attributesMap
+	// Not stored by the backend; an imported user gets the default
+	if data.ForceRemoveFromGroups.IsNull() {
+		data.ForceRemoveFromGroups = types.BoolValue(true)
 	}
 
+	data.Attributes = convert.KeepEmptyMap(data.Attributes, convert.StringMapFromMultiValue(user.Attributes))
+	data.CreatedAt, data.LastLoginAt = userTimestamps(user)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -262,6 +322,7 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update user, got error: %s", err))
 		return
 	}
+	r.verifyApplied(user, &resp.Diagnostics)
 
 	data.Username = types.StringValue(updated.Username)
 	// Only update email if API returned a non-empty value
@@ -279,25 +340,55 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		data.Enabled = types.BoolValue(updated.Enabled)
 	}
 
+	// Keep the planned attributes when the API doesn't return them
 	if len(updated.Attributes) > 0 {
-		// Convert map[string][]string from API to map[string]string for Terraform
-		tfAttributesMap := make(map[string]string)
-		for k, v := range updated.Attributes {
-			if len(v) > 0 {
-				tfAttributesMap[k] = v[0] // Take first value
-			}
-		}
-		attributesMap, diags := types.MapValueFrom(ctx, types.StringType, tfAttributesMap)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		data.Attributes = attributesMap
+		data.Attributes = convert.StringMapFromMultiValue(updated.Attributes)
+	}
+	// Keep the timestamps from state when the API doesn't return them
+	if updated.CreatedAt != nil {
+		data.CreatedAt = timestampValue(updated.CreatedAt)
+	}
+	if updated.LastLoginAt != nil {
+		data.LastLoginAt = timestampValue(updated.LastLoginAt)
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// verifyApplied reads the user back and warns about any field whose value
+// differs from what was sent, since Create and Update keep planned values
+// when the API returns empty ones.
+func (r *UserResource) verifyApplied(sent *User, diags *diag.Diagnostics) {
+	owner := fmt.Sprintf("User %q", sent.Username)
+	remote, err := r.client.GetUser(sent.Username)
+	if err != nil {
+		addVerifyError(diags, owner, err)
+		return
+	}
+
+	var mismatches fieldMismatches
+	// Keycloak stores emails in lower case
+	mismatches.compareString("email", strings.ToLower(sent.Email), strings.ToLower(remote.Email))
+	if sent.FirstName != "" {
+		mismatches.compareString("first_name", sent.FirstName, remote.FirstName)
+	}
+	if sent.LastName != "" {
+		mismatches.compareString("last_name", sent.LastName, remote.LastName)
+	}
+	mismatches.compareBool("enabled", sent.Enabled, remote.Enabled)
+
+	keys := make([]string, 0, len(sent.Attributes))
+	for k := range sent.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		mismatches.compareStrings(fmt.Sprintf("attributes[%q]", k), sent.Attributes[k], remote.Attributes[k])
+	}
+
+	mismatches.addWarning(diags, owner)
+}
+
 func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data UserResourceModel
 
@@ -306,14 +397,93 @@ func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	err := r.client.DeleteUser(data.Username.ValueString())
+	// Remove or report the principal's permission set assignments first, since
+	// the backend refuses to delete principals that still have assignments
+	if !cleanupPrincipalAssignments(ctx, r.client, "USER", data.Username.ValueString(), &resp.Diagnostics) {
+		return
+	}
+
+	deleteTimeout, diags := resolveTimeout(data.Timeouts, "delete", userDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	username := data.Username.ValueString()
+	err := r.client.DeleteUser(username)
+	// Some backends refuse to delete a user who is still a group member. A
+	// prism_group_membership that lists the username as a plain string gives
+	// Terraform no dependency to order the two deletes by.
+	if isConflictError(err) && data.ForceRemoveFromGroups.ValueBool() {
+		if !r.removeFromGroups(username, &resp.Diagnostics) {
+			return
+		}
+	}
+	if isConflictError(err) {
+		err = retryOnConflict(ctx, deleteTimeout, fmt.Sprintf("user %q", username), func() error {
+			return r.client.DeleteUser(username)
+		})
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete user, got error: %s", err))
+		detail := fmt.Sprintf("Unable to delete user, got error: %s", err)
+		if isConflictError(err) && !data.ForceRemoveFromGroups.ValueBool() {
+			detail += "\n\nIf the user is still a member of groups, remove them from those groups first, or set force_remove_from_groups = true."
+		}
+		resp.Diagnostics.AddError("Client Error", detail)
 		return
 	}
 }
 
+// removeFromGroups removes username from every group they're a member of,
+// with a warning naming the groups. It reports false after adding an error.
+func (r *UserResource) removeFromGroups(username string, diags *diag.Diagnostics) bool {
+	groups, err := r.client.GetUserGroups(username)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to list the groups of user %q before deleting it, got error: %s", username, err))
+		return false
+	}
+	if len(groups) == 0 {
+		return true
+	}
+
+	for _, group := range groups {
+		if err := r.client.RemoveGroupMembers(group, []string{username}); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to remove user %q from group %q before deleting it, got error: %s", username, group, err))
+			return false
+		}
+	}
+	diags.AddWarning(
+		"User Removed from Groups",
+		fmt.Sprintf("User %q was still a member of %s, so it was removed from them before being deleted. "+
+			"Remove the user from any prism_group_membership that lists it, or that membership will try to add it back.",
+			username, strings.Join(groups, ", ")),
+	)
+	return true
+}
+
 func (r *UserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Import using username since that's what Read() uses to fetch the user
 	resource.ImportStatePassthroughID(ctx, path.Root("username"), req, resp)
 }
+
+// userTimestamps returns the created_at and last_login_at values of user,
+// which are null where the backend doesn't send them.
+func userTimestamps(user *User) (createdAt, lastLoginAt types.String) {
+	return timestampValue(user.CreatedAt), timestampValue(user.LastLoginAt)
+}
+
+// userDependencyDetail describes a failed wait for username, or a user with
+// that email address, to exist. Users take a moment to become visible after
+// they're created, so a wait that timed out most likely raced a prism_user
+// created in the same apply.
+func userDependencyDetail(username string, err error) string {
+	detail := fmt.Sprintf("User dependency not satisfied: %s", err)
+	if errors.Is(err, errDependencyTimeout) {
+		check := "check that the user exists"
+		if isEmailAddress(username) {
+			check = "check that a user has this email address"
+		}
+		detail += fmt.Sprintf("\n\nIf user %q is created in the same apply, it wasn't visible yet; running the apply again should succeed. Otherwise, %s.", username, check)
+	}
+	return detail
+}
@@ -0,0 +1,435 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testUserModel(username, email string) *UserResourceModel {
+	return &UserResourceModel{
+		ID:         types.StringUnknown(),
+		Username:   types.StringValue(username),
+		Email:      types.StringValue(email),
+		FirstName:  types.StringValue("Test"),
+		LastName:   types.StringValue("User"),
+		Enabled:    types.BoolValue(true),
+		Attributes: types.MapNull(types.StringType),
+		Timeouts:   nullTimeouts("delete"),
+
+		ForceRemoveFromGroups: types.BoolValue(true),
+
+		CreatedAt:   types.StringUnknown(),
+		LastLoginAt: types.StringUnknown(),
+	}
+}
+
+func TestAccUserResource_Lifecycle(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	h := newResourceHarness(t, NewUserResource(), client)
+	username := accName("user")
+
+	// Create
+	state, diags := h.create(testUserModel(username, username+"@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	if h.attr(state, "id") == "" {
+		t.Error("expected id to be set after create")
+	}
+
+	// Update
+	plan := testUserModel(username, username+"@example.org")
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	state, diags = h.update(state, plan)
+	if diags.HasError() {
+		t.Fatalf("update: %v", diags)
+	}
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read after update: %v", diags)
+	}
+	if got := h.attr(state, "email"); got != username+"@example.org" {
+		t.Errorf("expected updated email, got %q", got)
+	}
+
+	// Import
+	imported, diags := h.importState(username)
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	if got := h.attr(imported, "email"); got != username+"@example.org" {
+		t.Errorf("expected imported email to match, got %q", got)
+	}
+
+	// Destroy
+	if diags := h.delete(state); diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if fake != nil {
+		if _, ok := fake.users[username]; ok {
+			t.Error("expected user to be deleted")
+		}
+	}
+}
+
+func TestAccUserResource_DeletedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	delete(fake.users, "alice")
+
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if !state.Raw.IsNull() {
+		t.Error("expected the user to be removed from state")
+	}
+}
+
+func TestUserDelete_CleansUpAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+	fake.assignments["asgn-2"] = &PermissionSetAssignment{ID: "asgn-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "alice", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if diags.HasError() {
+		t.Fatalf("delete: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Automatic Assignment Cleanup") {
+		t.Errorf("expected an Automatic Assignment Cleanup warning, got %v", diags)
+	}
+	if _, ok := fake.users["alice"]; ok {
+		t.Error("expected user to be deleted")
+	}
+	if _, ok := fake.assignments["asgn-2"]; !ok {
+		t.Error("expected the group assignment with the same name to be left alone")
+	}
+}
+
+func TestUserDelete_ProtectsAssignments(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	client.CleanupAssignmentsOnDelete = false
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	fake.assignments["asgn-1"] = &PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"}
+
+	diags = h.delete(state)
+	if !hasDiagnostic(diags, "Active Permission Set Assignments") {
+		t.Fatalf("expected an Active Permission Set Assignments error, got %v", diags)
+	}
+	detail := diags.Errors()[0].Detail()
+	for _, want := range []string{"asgn-1", `principal_id = "alice"`, "cleanup_assignments_on_delete"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("expected detail to mention %s, got:\n%s", want, detail)
+		}
+	}
+	if _, ok := fake.users["alice"]; !ok {
+		t.Error("expected user to be kept")
+	}
+	if _, ok := fake.assignments["asgn-1"]; !ok {
+		t.Error("expected assignment to be kept")
+	}
+}
+
+func TestUserResource_ImportCreatedOutsideTerraform(t *testing.T) {
+	client, fake := newAccTestClient(t)
+	requireFake(t, fake)
+	fake.users["alice"] = &User{ID: "user-42", Username: "alice", Email: "alice@example.com", Enabled: false}
+
+	h := newResourceHarness(t, NewUserResource(), client)
+	state, diags := h.importState("alice")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	h.assertImportComplete(state)
+
+	if got := h.attr(state, "id"); got != "user-42" {
+		t.Errorf("expected id user-42, got %q", got)
+	}
+	var data UserResourceModel
+	h.get(state, &data)
+	if data.Enabled.IsNull() || data.Enabled.ValueBool() {
+		t.Errorf("expected imported enabled to be false, got %s", data.Enabled)
+	}
+}
+
+func TestUserResource_WarnsWhenFieldsAreNotApplied(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, dropRequestFields(t, fake, "enabled", "lastName"))
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	detail := diagnosticDetail(diags, "Fields Not Applied")
+	for _, want := range []string{`User "alice"`, "enabled: sent true, got false", `last_name: sent "User", got ""`} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("expected the warning to contain %q, got %q", want, detail)
+		}
+	}
+	if strings.Contains(detail, "first_name") || strings.Contains(detail, "email") {
+		t.Errorf("expected only dropped fields in the warning, got %q", detail)
+	}
+	// The planned values are kept, as before
+	var data UserResourceModel
+	h.get(state, &data)
+	if !data.Enabled.ValueBool() {
+		t.Errorf("expected enabled to stay true in state, got %s", data.Enabled)
+	}
+}
+
+func TestUserResource_NoWarningWhenFieldsAreApplied(t *testing.T) {
+	client := newTestClient(t, newFakePrism())
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	plan := testUserModel("alice", "Alice@Example.com")
+	plan.Attributes = types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("platform")})
+	state, diags := h.create(plan)
+	if diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected create without warnings, got %v", diags)
+	}
+
+	plan.ID = types.StringValue(h.attr(state, "id"))
+	plan.Enabled = types.BoolValue(false)
+	if _, diags := h.update(state, plan); diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("expected update without warnings, got %v", diags)
+	}
+}
+
+func TestUserResource_WarnsWhenVerificationFails(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			writeAPIError(w, http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	_, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("expected the create to succeed, got %v", diags)
+	}
+	if !hasDiagnostic(diags, "Unable to Verify Fields") {
+		t.Errorf("expected a verification warning, got %v", diags)
+	}
+}
+
+func TestUserResource_ReadDetectsClearedName(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, fake)
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state, diags := h.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+
+	fake.users["alice"].LastName = ""
+	state, diags = h.read(state)
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	var got UserResourceModel
+	h.get(state, &got)
+	if !got.LastName.IsNull() {
+		t.Errorf("expected last_name to be null once cleared, got %s", got.LastName)
+	}
+	if got.FirstName.ValueString() != "Test" {
+		t.Errorf("expected first_name Test, got %s", got.FirstName)
+	}
+}
+
+func TestUserResource_DuplicateUsernameInConfig(t *testing.T) {
+	fake := newFakePrism()
+	client := newTestClient(t, fake)
+
+	// Two resources with the same username, as after a copy-paste
+	first := newResourceHarness(t, NewUserResource(), client)
+	state, diags := first.create(testUserModel("alice", "alice@example.com"))
+	if diags.HasError() {
+		t.Fatalf("create: %v", diags)
+	}
+	id := first.attr(state, "id")
+
+	second := newResourceHarness(t, NewUserResource(), client)
+	_, diags = second.create(testUserModel("alice", "alice@example.org"))
+	detail := diagnosticDetail(diags, "User Already Exists")
+	for _, want := range []string{`username "alice"`, "(ID " + id + ")", "terraform import <resource address> alice"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("expected the error to contain %q, got %q", want, detail)
+		}
+	}
+	if got := fake.users["alice"].Email; got != "alice@example.com" {
+		t.Errorf("expected the first resource's user to be left alone, got email %q", got)
+	}
+}
+
+func TestUserResource_DuplicateUsernameCreatedConcurrently(t *testing.T) {
+	fake := newFakePrism()
+	fake.users["alice"] = &User{ID: "user-7", Username: "alice", Email: "alice@example.com", Enabled: true}
+
+	// The other resource creates the user between the lookup and the create
+	lookedUp := false
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && !lookedUp {
+			lookedUp = true
+			writeAPIError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	_, diags := h.create(testUserModel("alice", "alice@example.org"))
+	if detail := diagnosticDetail(diags, "User Already Exists"); !strings.Contains(detail, "(ID user-7)") {
+		t.Errorf("expected an error naming user-7, got %v", diags)
+	}
+}
+
+func TestUserResource_ReadTimestamps(t *testing.T) {
+	user := map[string]interface{}{"id": "user-1", "username": "alice", "email": "alice@example.com", "enabled": true, "createdTimestamp": 1700000000000}
+	client := newTestClient(t, userFixture(user))
+	h := newResourceHarness(t, NewUserResource(), client)
+
+	state := testUserModel("alice", "alice@example.com")
+	state.ID = types.StringValue("user-1")
+	state.LastLoginAt = types.StringValue("2025-10-09T08:53:20Z")
+	read, diags := h.read(h.state(state))
+	if diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	var data UserResourceModel
+	h.get(read, &data)
+	if data.CreatedAt.ValueString() != "2023-11-14T22:13:20Z" {
+		t.Errorf("expected created_at to be read, got %s", data.CreatedAt)
+	}
+	// A last login the backend no longer reports is cleared
+	if !data.LastLoginAt.IsNull() {
+		t.Errorf("expected a null last_login_at, got %s", data.LastLoginAt)
+	}
+}
+
+// memberDeleteFake returns a fake that refuses to delete group members, with
+// alice in the groups developers and oncall, and a user harness on it.
+func memberDeleteFake(t *testing.T, handler func(*fakePrism) http.Handler) (*fakePrism, *resourceHarness) {
+	t.Helper()
+
+	fake := newFakePrism()
+	fake.memberDeleteConflicts = true
+	fake.users["alice"] = &User{ID: "user-alice", Username: "alice", Email: "alice@example.com"}
+	fake.users["bob"] = &User{ID: "user-bob", Username: "bob", Email: "bob@example.com"}
+	for _, name := range []string{"developers", "oncall", "admins"} {
+		fake.groups[name] = &Group{ID: "group-" + name, Name: name}
+	}
+	fake.members["developers"] = []string{"alice", "bob"}
+	fake.members["oncall"] = []string{"alice"}
+	fake.members["admins"] = []string{"bob"}
+
+	var h http.Handler = fake
+	if handler != nil {
+		h = handler(fake)
+	}
+	return fake, newResourceHarness(t, NewUserResource(), newTestClient(t, h))
+}
+
+func TestUserResource_DeleteRemovesFromGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*fakePrism) http.Handler
+	}{
+		{name: "user groups route"},
+		{name: "no user groups route", handler: func(fake *fakePrism) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/users/alice/groups") {
+					writeAPIError(w, http.StatusNotFound, "not found")
+					return
+				}
+				fake.ServeHTTP(w, r)
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, h := memberDeleteFake(t, tt.handler)
+			state := h.state(testUserModel("alice", "alice@example.com"))
+
+			diags := h.delete(state)
+			if diags.HasError() {
+				t.Fatalf("delete: %v", diags)
+			}
+			if _, ok := fake.users["alice"]; ok {
+				t.Error("expected alice to be deleted")
+			}
+			detail := diagnosticDetail(diags, "User Removed from Groups")
+			if !strings.Contains(detail, "developers, oncall") {
+				t.Errorf("expected the warning to name developers and oncall, got %q", detail)
+			}
+			if got := fake.members["developers"]; len(got) != 1 || got[0] != "bob" {
+				t.Errorf("expected bob to stay in developers, got %v", got)
+			}
+			if got := fake.members["admins"]; len(got) != 1 {
+				t.Errorf("expected admins to be left alone, got %v", got)
+			}
+		})
+	}
+}
+
+func TestUserResource_DeleteWithoutForceRemoveFromGroups(t *testing.T) {
+	fake, h := memberDeleteFake(t, nil)
+	model := testUserModel("alice", "alice@example.com")
+	model.ForceRemoveFromGroups = types.BoolValue(false)
+	model.Timeouts = testTimeouts(map[string]string{"delete": "1s"})
+
+	diags := h.delete(h.state(model))
+	detail := diagnosticDetail(diags, "Client Error")
+	if !strings.Contains(detail, "409") || !strings.Contains(detail, "force_remove_from_groups") {
+		t.Errorf("expected a conflict error suggesting force_remove_from_groups, got %q", detail)
+	}
+	if _, ok := fake.users["alice"]; !ok {
+		t.Error("expected alice to be kept")
+	}
+	if got := fake.members["oncall"]; len(got) != 1 {
+		t.Errorf("expected alice to stay in oncall, got %v", got)
+	}
+}
+
+func TestUserResource_ImportDefaultsForceRemoveFromGroups(t *testing.T) {
+	_, h := memberDeleteFake(t, nil)
+
+	state, diags := h.importState("bob")
+	if diags.HasError() {
+		t.Fatalf("import: %v", diags)
+	}
+	var data UserResourceModel
+	h.get(state, &data)
+	if !data.ForceRemoveFromGroups.ValueBool() {
+		t.Errorf("expected force_remove_from_groups true, got %s", data.ForceRemoveFromGroups)
+	}
+}
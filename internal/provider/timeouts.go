@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// timeoutsBlock returns a `timeouts` block with the given operations
+// (e.g. "create", "delete"), mirroring terraform-plugin-framework-timeouts.
+// Each value is a Go duration string such as "30s" or "10m".
+func timeoutsBlock(defaults map[string]time.Duration) schema.Block {
+	attributes := make(map[string]schema.Attribute, len(defaults))
+	for operation, def := range defaults {
+		attributes[operation] = schema.StringAttribute{
+			Optional: true,
+			MarkdownDescription: fmt.Sprintf("Timeout for the %s operation as a duration string (e.g. `30s`, `10m`). Defaults to `%s`.",
+				operation, def),
+			Validators: []validator.String{durationValidator{}},
+		}
+	}
+
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Timeouts for long-running operations",
+		Attributes:          attributes,
+	}
+}
+
+// resolveTimeout returns the configured timeout for operation, or def when
+// the block or attribute is unset.
+func resolveTimeout(timeouts types.Object, operation string, def time.Duration) (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return def, diags
+	}
+
+	value, ok := timeouts.Attributes()[operation].(types.String)
+	if !ok || value.IsNull() || value.IsUnknown() || value.ValueString() == "" {
+		return def, diags
+	}
+
+	d, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("timeouts").AtName(operation),
+			"Invalid Timeout",
+			fmt.Sprintf("Unable to parse %s timeout %q: %s", operation, value.ValueString(), err),
+		)
+		return def, diags
+	}
+
+	return d, diags
+}
+
+// durationValidator checks that a string parses with time.ParseDuration and is positive.
+type durationValidator struct{}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	return "value must be a positive duration string such as \"30s\" or \"10m\""
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	d, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Expected a positive duration string such as \"30s\" or \"10m\", got %q.", req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func testTimeouts(values map[string]string) types.Object {
+	attrTypes := map[string]attr.Type{}
+	attrValues := map[string]attr.Value{}
+	for operation, value := range values {
+		attrTypes[operation] = types.StringType
+		attrValues[operation] = types.StringValue(value)
+	}
+	return types.ObjectValueMust(attrTypes, attrValues)
+}
+
+func TestResolveTimeout(t *testing.T) {
+	def := 5 * time.Minute
+
+	got, diags := resolveTimeout(types.ObjectNull(map[string]attr.Type{"delete": types.StringType}), "delete", def)
+	if diags.HasError() || got != def {
+		t.Errorf("null block: expected default %s, got %s (%v)", def, got, diags)
+	}
+
+	got, diags = resolveTimeout(testTimeouts(map[string]string{"delete": "45s"}), "delete", def)
+	if diags.HasError() || got != 45*time.Second {
+		t.Errorf("configured: expected 45s, got %s (%v)", got, diags)
+	}
+
+	got, diags = resolveTimeout(testTimeouts(map[string]string{"create": "45s"}), "delete", def)
+	if diags.HasError() || got != def {
+		t.Errorf("other operation: expected default %s, got %s (%v)", def, got, diags)
+	}
+
+	_, diags = resolveTimeout(testTimeouts(map[string]string{"delete": "soon"}), "delete", def)
+	if !diags.HasError() {
+		t.Error("expected an error for an unparseable duration")
+	}
+}
+
+func TestPermissionSetDelete_ShortTimeoutAbortsAssignmentWait(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-set-assignments"):
+			writeAPIData(w, map[string]interface{}{
+				"assignments": []PermissionSetAssignment{{ID: "asgn-1", PermissionSetID: "ps-1", AccountID: "111111111111"}},
+				"count":       1,
+			})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-set-assignments/asgn-1"):
+			// The assignment never disappears
+			writeAPIData(w, PermissionSetAssignment{ID: "asgn-1", PermissionSetID: "ps-1"})
+		default:
+			writeAPIData(w, nil)
+		}
+	}))
+
+	start := time.Now()
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	diags := h.delete(h.state(&PermissionSetResourceModel{
+		ID:              types.StringValue("ps-1"),
+		Name:            types.StringValue("readonly"),
+		ManagedPolicies: types.ListNull(types.StringType),
+		InlinePolicies:  types.MapNull(types.StringType),
+		Timeouts:        testTimeouts(map[string]string{"delete": "3s"}),
+	}))
+	elapsed := time.Since(start)
+
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if !hasDiagnostic(diags, "Assignment Deletion Timeout") {
+		t.Errorf("expected an Assignment Deletion Timeout warning, got %v", diags)
+	}
+	if elapsed > 10*time.Second {
+		t.Errorf("expected the wait to stop near the 3s timeout, took %v", elapsed)
+	}
+}
+
+func TestPermissionSetDelete_CancelStopsAssignmentWait(t *testing.T) {
+	var deleted bool
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-set-assignments"):
+			// The assignment never disappears
+			writeAPIData(w, map[string]interface{}{
+				"assignments": []PermissionSetAssignment{{ID: "asgn-1", PermissionSetID: "ps-1", AccountID: "111111111111"}},
+				"count":       1,
+			})
+		case r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/permission-sets/ps-1"):
+			deleted = true
+			writeAPIData(w, nil)
+		default:
+			writeAPIData(w, nil)
+		}
+	}))
+	client.CleanupAssignmentsOnDelete = true
+
+	h := newResourceHarness(t, NewPermissionSetResource(), client)
+	state := h.state(&PermissionSetResourceModel{
+		ID:              types.StringValue("ps-1"),
+		Name:            types.StringValue("readonly"),
+		ManagedPolicies: types.ListNull(types.StringType),
+		InlinePolicies:  types.MapNull(types.StringType),
+		Timeouts:        nullTimeouts("delete"),
+	})
+
+	// Cancel once the wait has backed off past its first poll
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelAfter := 2 * assignmentCleanupInitialBackoff
+	time.AfterFunc(cancelAfter, cancel)
+
+	start := time.Now()
+	resp := resource.DeleteResponse{State: state}
+	h.r.Delete(ctx, resource.DeleteRequest{State: state}, &resp)
+	elapsed := time.Since(start)
+
+	if detail := diagnosticDetail(resp.Diagnostics, "Delete Cancelled"); !strings.Contains(detail, `Permission set "ps-1" was not deleted`) {
+		t.Errorf("expected a Delete Cancelled error, got %v", resp.Diagnostics)
+	}
+	if deleted {
+		t.Error("expected the permission set not to be deleted after cancellation")
+	}
+	if elapsed > cancelAfter+assignmentCleanupInitialBackoff {
+		t.Errorf("expected the wait to stop within one poll interval of the cancel, took %v", elapsed)
+	}
+}
+
+func TestPermissionSetAssignmentCreate_ShortTimeoutAbortsDependencyWait(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"success":false,"error":"not found"}`, http.StatusNotFound)
+	}))
+
+	accountIDs, _ := types.ListValueFrom(t.Context(), types.StringType, []string{"111111111111"})
+	start := time.Now()
+	_, diags := newResourceHarness(t, NewPermissionSetAssignmentResource(), client).create(&PermissionSetAssignmentResourceModel{
+		ID:                types.StringUnknown(),
+		PermissionSetID:   types.StringValue("ps-missing"),
+		PrincipalType:     types.StringValue("USER"),
+		PrincipalID:       types.StringValue("alice"),
+		ResolvedPrincipal: types.StringUnknown(),
+		AccountIDs:        accountIDs,
+		AssignmentIDs:     types.MapUnknown(types.StringType),
+		Timeouts:          testTimeouts(map[string]string{"create": "3s"}),
+	})
+	elapsed := time.Since(start)
+
+	if !hasDiagnostic(diags, "Dependency Error") {
+		t.Fatalf("expected a Dependency Error, got %v", diags)
+	}
+	if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, "timed out after") {
+		t.Errorf("expected a timeout message, got %q", detail)
+	}
+	if elapsed > 10*time.Second {
+		t.Errorf("expected the wait to stop near the 3s timeout, took %v", elapsed)
+	}
+}
+
+func nullTimeouts(operations ...string) types.Object {
+	attrTypes := map[string]attr.Type{}
+	for _, operation := range operations {
+		attrTypes[operation] = types.StringType
+	}
+	return types.ObjectNull(attrTypes)
+}
+
+func TestAWSAccountDelete_ShortTimeoutAbortsDeboardWait(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/permission-set-assignments"):
+			writeAPIData(w, map[string]interface{}{"assignments": []PermissionSetAssignment{}, "count": 0})
+		case r.Method == http.MethodGet:
+			// Deboarding never finishes
+			writeAPIData(w, AWSAccount{ID: "acct-42", AccountID: "123456789012"})
+		default:
+			writeAPIData(w, nil)
+		}
+	}))
+
+	start := time.Now()
+	h := newResourceHarness(t, NewAWSAccountResource(), client)
+	diags := h.delete(h.state(&AWSAccountResourceModel{
+		ID:          types.StringValue("acct-42"),
+		AccountID:   types.StringValue("123456789012"),
+		AccountName: types.StringValue("Production"),
+		Region:      types.StringNull(),
+		RoleArn:     types.StringNull(),
+		OwnerEmails: types.ListNull(types.StringType),
+		Timeouts:    testTimeouts(map[string]string{"create": "10m", "delete": "3s"}),
+	}))
+	elapsed := time.Since(start)
+
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if detail := diagnosticDetail(diags, "Account Deboarding Not Finished"); !strings.Contains(detail, "timed out after") {
+		t.Errorf("expected a timeout warning, got %v", diags)
+	}
+	if elapsed > 10*time.Second {
+		t.Errorf("expected the wait to stop near the 3s timeout, took %v", elapsed)
+	}
+}
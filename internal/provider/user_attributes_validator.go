@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// ReservedUserAttributes are user attribute keys the Prism backend manages
+// itself. It silently drops them from a user's custom attributes, so they're
+// rejected in configuration and left out by the import tool.
+var ReservedUserAttributes = []string{
+	"username",
+	"email",
+	"firstName",
+	"lastName",
+	"emailVerified",
+	"LDAP_ID",
+	"LDAP_ENTRY_DN",
+	"KERBEROS_PRINCIPAL",
+	"createTimestamp",
+	"modifyTimestamp",
+}
+
+// userAttributeKeyPattern matches the keys the backend accepts for custom
+// user attributes.
+var userAttributeKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// CheckUserAttributeKey returns an error explaining why key can't be used as
+// a custom user attribute, or nil if it can. Reserved keys are matched
+// case-insensitively, as the backend does.
+func CheckUserAttributeKey(key string) error {
+	for _, reserved := range ReservedUserAttributes {
+		if strings.EqualFold(key, reserved) {
+			return fmt.Errorf("%q is reserved by Prism and would be dropped; set it with the matching prism_user argument, if there is one", key)
+		}
+	}
+	if !userAttributeKeyPattern.MatchString(key) {
+		return fmt.Errorf("%q may only contain letters, digits, '_', '.' and '-'", key)
+	}
+	return nil
+}
+
+// userAttributeKeysValidator checks the keys of a map of custom user
+// attributes with CheckUserAttributeKey.
+type userAttributeKeysValidator struct{}
+
+var _ validator.Map = userAttributeKeysValidator{}
+
+func (v userAttributeKeysValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("keys may only contain letters, digits, '_', '.' and '-', and can't be one of the reserved keys %s", strings.Join(ReservedUserAttributes, ", "))
+}
+
+func (v userAttributeKeysValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v userAttributeKeysValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	keys := make([]string, 0, len(req.ConfigValue.Elements()))
+	for key := range req.ConfigValue.Elements() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := CheckUserAttributeKey(key); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				req.Path.AtMapKey(key),
+				"Invalid User Attribute Key",
+				fmt.Sprintf("The attribute key %s.", err),
+			)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUserAttributeKeysValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantInvalid []string
+	}{
+		{name: "valid", keys: []string{"department", "cost_center", "team.name", "employee-id", "Region2"}},
+		{name: "reserved", keys: []string{"department", "username", "LDAP_ID"}, wantInvalid: []string{"LDAP_ID", "username"}},
+		{name: "reserved in another case", keys: []string{"Email", "ldap_id"}, wantInvalid: []string{"Email", "ldap_id"}},
+		{name: "invalid characters", keys: []string{"cost center", "team/name", "naïve", ""}, wantInvalid: []string{"", "cost center", "naïve", "team/name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements := map[string]attr.Value{}
+			for _, key := range tt.keys {
+				elements[key] = types.StringValue("value")
+			}
+			req := validator.MapRequest{
+				Path:        path.Root("attributes"),
+				ConfigValue: types.MapValueMust(types.StringType, elements),
+			}
+			var resp validator.MapResponse
+			userAttributeKeysValidator{}.ValidateMap(context.Background(), req, &resp)
+
+			errors := resp.Diagnostics.Errors()
+			if len(errors) != len(tt.wantInvalid) {
+				t.Fatalf("expected %d errors, got %v", len(tt.wantInvalid), resp.Diagnostics)
+			}
+			// Errors are reported in key order
+			for i, key := range tt.wantInvalid {
+				want := path.Root("attributes").AtMapKey(key)
+				withPath, ok := errors[i].(interface{ Path() path.Path })
+				if !ok || !withPath.Path().Equal(want) || errors[i].Summary() != "Invalid User Attribute Key" {
+					t.Errorf("expected an Invalid User Attribute Key error at %s, got %v", want, errors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUserAttributeKeysValidator_NullAndUnknown(t *testing.T) {
+	for name, value := range map[string]types.Map{
+		"null":    types.MapNull(types.StringType),
+		"unknown": types.MapUnknown(types.StringType),
+	} {
+		var resp validator.MapResponse
+		userAttributeKeysValidator{}.ValidateMap(context.Background(), validator.MapRequest{Path: path.Root("attributes"), ConfigValue: value}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Errorf("%s: expected no errors, got %v", name, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCheckUserAttributeKey_ReservedList(t *testing.T) {
+	for _, key := range ReservedUserAttributes {
+		if CheckUserAttributeKey(key) == nil {
+			t.Errorf("expected reserved key %q to be rejected", key)
+		}
+	}
+}
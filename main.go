@@ -24,6 +24,9 @@ func main() {
 	opts := providerserver.ServeOpts{
 		Address: "registry.terraform.io/CloudKeeper-Inc/prism",
 		Debug:   debug,
+		// Protocol 6 is required for nested attributes, such as the typed
+		// identity provider blocks
+		ProtocolVersion: 6,
 	}
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// AccessChecker is implemented by clients that can check their subdomain
+// and token with one cheap request, such as *provider.Client.
+type AccessChecker interface {
+	CheckAccess() error
+}
+
+// AccessError is a failed access check. Problem says what is most likely
+// wrong and which flag fixes it.
+type AccessError struct {
+	Problem string
+	Err     error
+}
+
+func (e *AccessError) Error() string {
+	return fmt.Sprintf("%s\n  (%s)", e.Problem, e.Err)
+}
+
+func (e *AccessError) Unwrap() error {
+	return e.Err
+}
+
+// CheckAccess checks that f.Client can reach the API for subdomain before
+// anything is fetched, retrying rate limiting and server errors like Fetch
+// does. A rejected token, an unknown subdomain and a host that doesn't
+// resolve come back as an *AccessError; other errors as they are. Clients
+// that aren't AccessCheckers aren't checked.
+func (f *Fetcher) CheckAccess(subdomain string) error {
+	checker, ok := f.Client.(AccessChecker)
+	if !ok {
+		return nil
+	}
+
+	out := f.Out
+	if out == nil {
+		out = io.Discard
+	}
+	client := f.retryingClient(func(format string, args ...interface{}) {
+		fmt.Fprintf(out, format, args...)
+	})
+	_, err := retryCall(client, "the access check", func() (struct{}, error) {
+		return struct{}{}, checker.CheckAccess()
+	})
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *provider.APIError
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		return &AccessError{
+			Problem: fmt.Sprintf("the API token was rejected (HTTP %d); check -token or PRISM_API_TOKEN, and that the token hasn't expired or been revoked", apiErr.StatusCode),
+			Err:     err,
+		}
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		return &AccessError{
+			Problem: fmt.Sprintf("Prism has no customer with subdomain %q (HTTP 404); check -subdomain or PRISM_SUBDOMAIN", subdomain),
+			Err:     err,
+		}
+	case errors.As(err, &dnsErr):
+		return &AccessError{
+			Problem: fmt.Sprintf("can't resolve %s; check -base-url or PRISM_BASE_URL", dnsErr.Name),
+			Err:     err,
+		}
+	}
+	return fmt.Errorf("failed to check access: %w", err)
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestFetcher_CheckAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // responses to the access check before the fake API answers
+		wantErr  string
+	}{
+		{name: "ok"},
+		{name: "rate limited", statuses: []int{http.StatusTooManyRequests}},
+		{name: "bad token", statuses: []int{http.StatusUnauthorized}, wantErr: "the API token was rejected (HTTP 401); check -token or PRISM_API_TOKEN"},
+		{name: "forbidden", statuses: []int{http.StatusForbidden}, wantErr: "the API token was rejected (HTTP 403)"},
+		{name: "bad subdomain", statuses: []int{http.StatusNotFound}, wantErr: `Prism has no customer with subdomain "test" (HTTP 404); check -subdomain or PRISM_SUBDOMAIN`},
+		{name: "server error", statuses: []int{500, 500}, wantErr: "failed to check access: GET /api/v1/customers/test/aws-accounts?max=1: API error (500)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyResponses{
+				failures: map[string][]int{"/aws-accounts": tt.statuses},
+				requests: make(map[string]int),
+			}
+			client, _ := newInterceptedFakePrism(t, testInfrastructure(), flaky.intercept)
+			fetcher := &Fetcher{
+				Client: client,
+				Retry:  RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+				sleep:  func(time.Duration) {},
+			}
+
+			err := fetcher.CheckAccess("test")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected access, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			// The underlying error stays available
+			var apiErr *provider.APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.statuses[len(tt.statuses)-1] {
+				t.Errorf("expected the API error to be wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetcher_CheckAccessUnresolvableHost(t *testing.T) {
+	client := provider.NewClient("https://prism.invalid:8090", "test", "token")
+	client.TimingLog = io.Discard
+	fetcher := &Fetcher{Client: client, sleep: func(time.Duration) {}}
+
+	err := fetcher.CheckAccess("test")
+	var accessErr *AccessError
+	if !errors.As(err, &accessErr) || !strings.HasPrefix(accessErr.Problem, "can't resolve prism.invalid; check -base-url") {
+		t.Errorf("expected a DNS failure to point at -base-url, got %v", err)
+	}
+}
+
+func TestFetcher_CheckAccessSkipsOtherClients(t *testing.T) {
+	fetcher := &Fetcher{Client: &stubClient{}}
+	if err := fetcher.CheckAccess("test"); err != nil {
+		t.Errorf("expected clients without CheckAccess not to be checked, got %v", err)
+	}
+}
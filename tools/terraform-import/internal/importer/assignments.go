@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"sort"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// assignmentResource is one generated prism_permission_set_assignment: all
+// assignments of a permission set to a principal, across accounts, grouped
+// the way the provider groups them so that an import plans no changes.
+type assignmentResource struct {
+	provider.PermissionSetAssignmentGroup
+
+	Name              string
+	PermissionSetName string // empty when the permission set wasn't exported
+}
+
+// groupAssignments groups the fetched assignments by permission set and
+// principal, sorted by resource name. Names are derived from the permission
+// set and principal only, so the same tenant always produces the same
+// names; names that still collide get a _2, _3, ... suffix in sort order.
+func groupAssignments(data *InfrastructureData) []assignmentResource {
+	permSetNames := make(map[string]string, len(data.PermissionSets))
+	for _, ps := range data.namingPermissionSets {
+		permSetNames[ps.ID] = ps.Name
+	}
+	for _, ps := range data.PermissionSets {
+		permSetNames[ps.ID] = ps.Name
+	}
+
+	var resources []*assignmentResource
+	for _, group := range provider.GroupPermissionSetAssignments(data.PermissionSetAssignments) {
+		resources = append(resources, &assignmentResource{
+			PermissionSetAssignmentGroup: group,
+			PermissionSetName:            permSetNames[group.PermissionSetID],
+		})
+	}
+
+	for _, resource := range resources {
+		// Without the permission set (e.g. when excluded), name it by ID
+		permSetLabel := resource.PermissionSetName
+		if permSetLabel == "" {
+			permSetLabel = resource.PermissionSetID
+		}
+		resource.Name = toResourceName(permSetLabel+"_"+resource.PrincipalID, "assignment")
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.PermissionSetID != b.PermissionSetID {
+			return a.PermissionSetID < b.PermissionSetID
+		}
+		if a.PrincipalType != b.PrincipalType {
+			return a.PrincipalType < b.PrincipalType
+		}
+		return a.PrincipalID < b.PrincipalID
+	})
+
+	bases := make([]string, len(resources))
+	for i, resource := range resources {
+		bases[i] = resource.Name
+	}
+	allocator := newNameAllocator(bases)
+
+	result := make([]assignmentResource, len(resources))
+	for i, resource := range resources {
+		resource.Name = allocator.allocate(resource.Name)
+		result[i] = *resource
+	}
+	return result
+}
@@ -0,0 +1,385 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// unorderedInfrastructure returns a tenant with several groups, memberships
+// and assignments, listed out of order.
+func unorderedInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		AWSAccounts: []provider.AWSAccount{
+			{ID: "acct-2", AccountID: "222222222222", AccountName: "Staging"},
+			{ID: "acct-1", AccountID: "111111111111", AccountName: "Production"},
+		},
+		PermissionSets: []provider.PermissionSet{
+			{ID: "ps-2", Name: "Admin"},
+			{ID: "ps-1", Name: "ReadOnly"},
+		},
+		Users: []provider.User{
+			{ID: "user-2", Username: "bob", Email: "bob@example.com", Enabled: true},
+			{ID: "user-1", Username: "alice", Email: "alice@example.com", Enabled: true},
+		},
+		Groups: []provider.Group{
+			{ID: "group-3", Name: "Support"},
+			{ID: "group-1", Name: "Engineering"},
+			{ID: "group-2", Name: "Operations"},
+		},
+		GroupMemberships: map[string][]string{
+			"Support":     {"bob"},
+			"Engineering": {"alice", "bob"},
+			"Operations":  {"alice"},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "assign-5", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Support", AccountID: "111111111111"},
+			{ID: "assign-3", PermissionSetID: "ps-2", PrincipalType: "USER", Username: "alice", AccountID: "222222222222"},
+			{ID: "assign-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "111111111111"},
+			{ID: "assign-4", PermissionSetID: "ps-2", PrincipalType: "GROUP", GroupName: "Operations", AccountID: "111111111111"},
+			{ID: "assign-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "222222222222"},
+		},
+	}
+}
+
+// reversed returns a copy of data with every list in reverse order.
+func reversed(data *InfrastructureData) *InfrastructureData {
+	r := *data
+	r.AWSAccounts = reverse(data.AWSAccounts)
+	r.PermissionSets = reverse(data.PermissionSets)
+	r.Users = reverse(data.Users)
+	r.Groups = reverse(data.Groups)
+	r.PermissionSetAssignments = reverse(data.PermissionSetAssignments)
+	return &r
+}
+
+func reverse[T any](s []T) []T {
+	r := make([]T, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
+
+func TestGenerateFiles_Deterministic(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks}
+	first := generateTestFiles(t, config, unorderedInfrastructure())
+	assertGoldenDir(t, first, "deterministic")
+
+	// Map iteration order differs between runs, so generate a few more times
+	for i := 0; i < 5; i++ {
+		dir := generateTestFiles(t, config, unorderedInfrastructure())
+		assertSameFiles(t, first, dir)
+	}
+}
+
+func TestGenerateFiles_IndependentOfAPIOrder(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks}
+	data := unorderedInfrastructure()
+	dir := generateTestFiles(t, config, data)
+
+	// Only the order of the account IDs within an assignment follows the API
+	r := reversed(data)
+	r.PermissionSetAssignments = append(reverse(r.PermissionSetAssignments[:3]), r.PermissionSetAssignments[3:]...)
+	assertSameFiles(t, dir, generateTestFiles(t, config, r))
+}
+
+// assertSameFiles checks that dirs a and b hold byte-identical files.
+func assertSameFiles(t *testing.T, a, b string) {
+	t.Helper()
+
+	filesA, filesB := listFiles(t, a), listFiles(t, b)
+	if !reflect.DeepEqual(filesA, filesB) {
+		t.Fatalf("expected the same files, got %v and %v", filesA, filesB)
+	}
+	for _, name := range filesA {
+		contentA, err := os.ReadFile(filepath.Join(a, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentB, err := os.ReadFile(filepath.Join(b, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contentA) != string(contentB) {
+			t.Errorf("%s differs between runs:\n--- first ---\n%s\n--- second ---\n%s", name, contentA, contentB)
+		}
+	}
+}
+
+func TestGroupAssignments_Names(t *testing.T) {
+	data := &InfrastructureData{
+		PermissionSets: []provider.PermissionSet{{ID: "ps-1", Name: "ReadOnly"}},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			// A user and a group with the same name share a natural name
+			{ID: "a-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "eng", AccountID: "111111111111"},
+			{ID: "a-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "eng", AccountID: "111111111111"},
+			// This one is naturally named readonly_eng_2
+			{ID: "a-3", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "eng_2", AccountID: "111111111111"},
+			// The permission set isn't exported, so it's named by ID
+			{ID: "a-4", PermissionSetID: "ps-9", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+			{ID: "a-5", PermissionSetID: "ps-9", PrincipalType: "USER", Username: "alice", AccountID: "222222222222"},
+		},
+	}
+
+	group := func(permSetID, principalType, principalID string, accountIDs, assignmentIDs []string) provider.PermissionSetAssignmentGroup {
+		return provider.PermissionSetAssignmentGroup{PermissionSetID: permSetID, PrincipalType: principalType, PrincipalID: principalID,
+			AccountIDs: accountIDs, AssignmentIDs: assignmentIDs}
+	}
+	want := []assignmentResource{
+		{Name: "ps_9_alice", PermissionSetAssignmentGroup: group("ps-9", "USER", "alice", []string{"111111111111", "222222222222"}, []string{"a-4", "a-5"})},
+		{Name: "readonly_eng", PermissionSetName: "ReadOnly", PermissionSetAssignmentGroup: group("ps-1", "GROUP", "eng", []string{"111111111111"}, []string{"a-2"})},
+		{Name: "readonly_eng_3", PermissionSetName: "ReadOnly", PermissionSetAssignmentGroup: group("ps-1", "USER", "eng", []string{"111111111111"}, []string{"a-1"})},
+		{Name: "readonly_eng_2", PermissionSetName: "ReadOnly", PermissionSetAssignmentGroup: group("ps-1", "GROUP", "eng_2", []string{"111111111111"}, []string{"a-3"})},
+	}
+
+	// Reordering the API list must not change the names
+	ps9 := data.PermissionSetAssignments[3:]
+	for _, ps1 := range [][]provider.PermissionSetAssignment{
+		data.PermissionSetAssignments[:3],
+		reverse(data.PermissionSetAssignments[:3]),
+	} {
+		data.PermissionSetAssignments = append(append([]provider.PermissionSetAssignment(nil), ps9...), ps1...)
+		if got := groupAssignments(data); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+// TestAssignments_ImportPlansNoChanges generates a configuration, imports
+// its assignments with the provider's resource against a fake API, and
+// checks that the imported state matches the configuration, which is what
+// makes the first plan after importing empty.
+func TestAssignments_ImportPlansNoChanges(t *testing.T) {
+	// The API lists Engineering's accounts, and their IDs, out of order
+	data := reversed(unorderedInfrastructure())
+
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: style, VarAccounts: VarAccountsNone}, data)
+			configured := evaluateResources(t, dir)
+			client, _ := newFakePrism(t, data)
+
+			imported := 0
+			for address, attrs := range configured {
+				if !strings.HasPrefix(address, "prism_permission_set_assignment.") {
+					continue
+				}
+				imported++
+				state := importAssignment(t, client, attrs["id"].AsString())
+
+				for _, name := range []string{"id", "permission_set_id", "principal_type", "principal_id"} {
+					var value types.String
+					if diags := state.GetAttribute(context.Background(), path.Root(name), &value); diags.HasError() {
+						t.Fatalf("%s: reading %s: %v", address, name, diags)
+					}
+					if want := attrs[name].AsString(); value.ValueString() != want {
+						t.Errorf("%s: configured %s = %q, imported %q", address, name, want, value.ValueString())
+					}
+				}
+
+				var accountIDs []string
+				if diags := state.GetAttribute(context.Background(), path.Root("account_ids"), &accountIDs); diags.HasError() {
+					t.Fatalf("%s: reading account_ids: %v", address, diags)
+				}
+				var want []string
+				for _, v := range attrs["account_ids"].AsValueSlice() {
+					want = append(want, v.AsString())
+				}
+				if !reflect.DeepEqual(accountIDs, want) {
+					t.Errorf("%s: configured account_ids = %v, imported %v", address, want, accountIDs)
+				}
+			}
+			if imported != 4 {
+				t.Errorf("expected 4 assignments to import, got %d", imported)
+			}
+		})
+	}
+}
+
+// importAssignment imports id with the provider's
+// prism_permission_set_assignment, running ImportState and then Read as
+// terraform does.
+func importAssignment(t *testing.T, client *provider.Client, id string) tfsdk.State {
+	t.Helper()
+	ctx := context.Background()
+
+	r := provider.NewPermissionSetAssignmentResource()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	var configureResp resource.ConfigureResponse
+	r.(resource.ResourceWithConfigure).Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatalf("configure: %v", configureResp.Diagnostics)
+	}
+
+	schema := schemaResp.Schema
+	importResp := resource.ImportStateResponse{
+		State: tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil)},
+	}
+	r.(resource.ResourceWithImportState).ImportState(ctx, resource.ImportStateRequest{ID: id}, &importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("importing %s: %v", id, importResp.Diagnostics)
+	}
+
+	readResp := resource.ReadResponse{State: importResp.State}
+	r.Read(ctx, resource.ReadRequest{State: importResp.State}, &readResp)
+	if readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Fatalf("reading %s: %v", id, readResp.Diagnostics)
+	}
+	return readResp.State
+}
+
+// evaluateResources evaluates the attributes of every resource instance
+// configured in dir, keyed by address, with references resolved. Each
+// instance's id is its import ID, as it is once imported.
+func evaluateResources(t *testing.T, dir string) map[string]map[string]cty.Value {
+	t.Helper()
+
+	var bodies []*hclsyntax.Body
+	for _, file := range listFiles(t, dir) {
+		if filepath.Ext(file) != ".tf" {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, diags := hclsyntax.ParseConfig(src, file, hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("parsing %s: %v", file, diags)
+		}
+		bodies = append(bodies, f.Body.(*hclsyntax.Body))
+	}
+
+	ids := make(map[string]string)
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			if block.Type != "import" {
+				continue
+			}
+			to, diags := hcl.AbsTraversalForExpr(block.Body.Attributes["to"].Expr)
+			if diags.HasErrors() {
+				t.Fatalf("import address: %v", diags)
+			}
+			id, diags := block.Body.Attributes["id"].Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatalf("import ID: %v", diags)
+			}
+			ids[traversalAddress(to)] = id.AsString()
+		}
+	}
+
+	// References only go from users, groups and assignments to resources
+	// configured with literals, so two rounds resolve all of them
+	resources := make(map[string]map[string]cty.Value)
+	for round := 0; round < 2; round++ {
+		ctx := &hcl.EvalContext{Variables: map[string]cty.Value{}}
+		byType := make(map[string]map[string]cty.Value)
+		for address, attrs := range resources {
+			resourceType, name, _ := strings.Cut(address, ".")
+			if byType[resourceType] == nil {
+				byType[resourceType] = make(map[string]cty.Value)
+			}
+			if key, ok := strings.CutPrefix(name, forEachResourceName+"["); ok {
+				// Instances of a for_each resource are indexed by key
+				name, key = forEachResourceName, strings.Trim(key, `"]`)
+				instances := byType[resourceType][name]
+				all := map[string]cty.Value{}
+				if !instances.IsNull() {
+					all = instances.AsValueMap()
+				}
+				all[key] = cty.ObjectVal(attrs)
+				byType[resourceType][name] = cty.ObjectVal(all)
+				continue
+			}
+			byType[resourceType][name] = cty.ObjectVal(attrs)
+		}
+		for resourceType, instances := range byType {
+			ctx.Variables[resourceType] = cty.ObjectVal(instances)
+		}
+
+		locals := map[string]cty.Value{}
+		for _, body := range bodies {
+			for _, block := range body.Blocks {
+				if block.Type != "locals" {
+					continue
+				}
+				for name, attr := range block.Body.Attributes {
+					if value, diags := attr.Expr.Value(ctx); !diags.HasErrors() {
+						locals[name] = value
+					}
+				}
+			}
+		}
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+
+		for _, body := range bodies {
+			for _, block := range body.Blocks {
+				if block.Type != "resource" {
+					continue
+				}
+				address := block.Labels[0] + "." + block.Labels[1]
+				forEach, ok := block.Body.Attributes["for_each"]
+				if !ok {
+					resources[address] = evaluateAttributes(block.Body, ctx, ids[address])
+					continue
+				}
+				instances, diags := forEach.Expr.Value(ctx)
+				if diags.HasErrors() {
+					continue
+				}
+				for key, each := range instances.AsValueMap() {
+					instanceCtx := ctx.NewChild()
+					instanceCtx.Variables = map[string]cty.Value{"each": cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal(key), "value": each})}
+					instance := fmt.Sprintf("%s[%q]", address, key)
+					resources[instance] = evaluateAttributes(block.Body, instanceCtx, ids[instance])
+				}
+			}
+		}
+	}
+	return resources
+}
+
+// evaluateAttributes evaluates the attributes of body that ctx can resolve,
+// with id set to the import ID.
+func evaluateAttributes(body *hclsyntax.Body, ctx *hcl.EvalContext, id string) map[string]cty.Value {
+	attrs := map[string]cty.Value{"id": cty.StringVal(id)}
+	for name, attr := range body.Attributes {
+		if value, diags := attr.Expr.Value(ctx); !diags.HasErrors() {
+			attrs[name] = value
+		}
+	}
+	return attrs
+}
+
+// traversalAddress formats a resource address such as
+// prism_user.this["alice"].
+func traversalAddress(traversal hcl.Traversal) string {
+	var sb strings.Builder
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			sb.WriteString(step.Name)
+		case hcl.TraverseAttr:
+			sb.WriteString("." + step.Name)
+		case hcl.TraverseIndex:
+			fmt.Fprintf(&sb, "[%q]", step.Key.AsString())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,395 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// diffSpec describes how -diff-state matches and compares one resource type.
+type diffSpec struct {
+	Kind          string
+	KeyAttributes []string // identify the resource; joined with ":" in the report
+	Attributes    []string // compared between state and Prism
+}
+
+// diffSpecs covers every resource type the tool generates. Resources are
+// matched by their natural keys rather than by ID, so a resource created
+// outside Terraform with the same name is reported as a difference, not as
+// two unrelated resources.
+var diffSpecs = map[string]diffSpec{
+	"prism_aws_account": {
+		Kind:          kindAWSAccounts,
+		KeyAttributes: []string{"account_id"},
+		Attributes:    []string{"account_name", "region"},
+	},
+	"prism_permission_set": {
+		Kind:          kindPermissionSets,
+		KeyAttributes: []string{"id"},
+		Attributes:    []string{"name", "description", "session_duration", "managed_policies", "inline_policies"},
+	},
+	"prism_user": {
+		Kind:          kindUsers,
+		KeyAttributes: []string{"username"},
+		Attributes:    []string{"email", "first_name", "last_name", "enabled", "attributes"},
+	},
+	"prism_group": {
+		Kind:          kindGroups,
+		KeyAttributes: []string{"name"},
+		Attributes:    []string{"description", "path"},
+	},
+	"prism_group_membership": {
+		Kind:          kindMemberships,
+		KeyAttributes: []string{"group_name"},
+		Attributes:    []string{"usernames"},
+	},
+	"prism_permission_set_assignment": {
+		Kind:          kindAssignments,
+		KeyAttributes: []string{"permission_set_id", "principal_type", "principal_id"},
+		Attributes:    []string{"account_ids"},
+	},
+	// The config holds secrets the API doesn't return, so it isn't compared
+	"prism_identity_provider": {
+		Kind:          kindIdentityProviders,
+		KeyAttributes: []string{"type", "alias"},
+		Attributes:    []string{"display_name", "enabled"},
+	},
+}
+
+// diffResource is one resource on either side of the diff.
+type diffResource struct {
+	Address    string
+	Attributes map[string]interface{}
+}
+
+// diffResources holds resources by type and then by key.
+type diffResources map[string]map[string]diffResource
+
+func (r diffResources) add(resourceType, key string, resource diffResource) {
+	if r[resourceType] == nil {
+		r[resourceType] = make(map[string]diffResource)
+	}
+	if _, ok := r[resourceType][key]; !ok {
+		r[resourceType][key] = resource
+	}
+}
+
+// DriftReport lists the differences between a state file and Prism.
+type DriftReport struct {
+	StatePath string
+	Missing   []driftEntry  // in Prism, not in state
+	Orphaned  []driftEntry  // in state, not in Prism
+	Changed   []driftChange // in both, with different attributes
+}
+
+type driftEntry struct {
+	Type    string
+	Key     string
+	Address string
+}
+
+type driftChange struct {
+	Address    string
+	Attributes []attributeDiff
+}
+
+type attributeDiff struct {
+	Name  string
+	State string
+	Prism string
+}
+
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Orphaned) > 0 || len(r.Changed) > 0
+}
+
+// write prints the report for people and CI logs.
+func (r *DriftReport) Write(w io.Writer) {
+	if !r.HasDrift() {
+		fmt.Fprintf(w, "✅ No drift: Prism matches the prism_* resources in %s\n", r.StatePath)
+		return
+	}
+
+	fmt.Fprintf(w, "⚠️  Prism has drifted from %s\n", r.StatePath)
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(w, "\nIn Prism but not in state (%d):\n", len(r.Missing))
+		for _, entry := range r.Missing {
+			fmt.Fprintf(w, "  + %s (%s %s)\n", entry.Address, entry.Type, entry.Key)
+		}
+	}
+	if len(r.Orphaned) > 0 {
+		fmt.Fprintf(w, "\nIn state but not in Prism (%d):\n", len(r.Orphaned))
+		for _, entry := range r.Orphaned {
+			fmt.Fprintf(w, "  - %s (%s %s)\n", entry.Address, entry.Type, entry.Key)
+		}
+	}
+	if len(r.Changed) > 0 {
+		fmt.Fprintf(w, "\nAttribute differences (%d):\n", len(r.Changed))
+		for _, change := range r.Changed {
+			fmt.Fprintf(w, "  ~ %s\n", change.Address)
+			for _, attr := range change.Attributes {
+				fmt.Fprintf(w, "      %s: state %s, Prism %s\n", attr.Name, attr.State, attr.Prism)
+			}
+		}
+	}
+}
+
+// DiffState compares the prism_* resources in the state file at statePath
+// with the live data. Only the given kinds are compared, and resources
+// missing from state are reported with the address they would be generated
+// at with the given -style and -name-map (which may be nil).
+func DiffState(statePath string, data *InfrastructureData, kinds ResourceKinds, style string, nameMap *NameMap) (*DriftReport, error) {
+	state, err := readStateResources(statePath)
+	if err != nil {
+		return nil, err
+	}
+	live := liveResources(data, style, nameMap)
+
+	report := &DriftReport{StatePath: statePath}
+	for _, resourceType := range sortedKeys(diffSpecs) {
+		spec := diffSpecs[resourceType]
+		if !kinds[spec.Kind] {
+			continue
+		}
+
+		for _, key := range sortedKeys(live[resourceType]) {
+			if _, ok := state[resourceType][key]; !ok {
+				report.Missing = append(report.Missing, driftEntry{Type: resourceType, Key: key, Address: live[resourceType][key].Address})
+			}
+		}
+
+		for _, key := range sortedKeys(state[resourceType]) {
+			stateResource := state[resourceType][key]
+			liveResource, ok := live[resourceType][key]
+			if !ok {
+				report.Orphaned = append(report.Orphaned, driftEntry{Type: resourceType, Key: key, Address: stateResource.Address})
+				continue
+			}
+
+			change := driftChange{Address: stateResource.Address}
+			for _, name := range spec.Attributes {
+				stateValue := normalizeAttribute(stateResource.Attributes[name])
+				liveValue := normalizeAttribute(liveResource.Attributes[name])
+				if stateValue != liveValue {
+					change.Attributes = append(change.Attributes, attributeDiff{Name: name, State: stateValue, Prism: liveValue})
+				}
+			}
+			if len(change.Attributes) > 0 {
+				report.Changed = append(report.Changed, change)
+			}
+		}
+	}
+	return report, nil
+}
+
+// stateFile is the part of the Terraform state format (version 4) that
+// -diff-state reads.
+type stateFile struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// readStateResources reads the managed resources of the types in diffSpecs
+// from a terraform.tfstate file, across all modules.
+func readStateResources(path string) (diffResources, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var state stateFile
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("%s has state format version %d, only version 4 (Terraform >= 0.12) is supported", path, state.Version)
+	}
+
+	resources := make(diffResources)
+	for _, res := range state.Resources {
+		spec, ok := diffSpecs[res.Type]
+		if !ok || res.Mode != "managed" {
+			continue
+		}
+
+		address := res.Type + "." + res.Name
+		if res.Module != "" {
+			address = res.Module + "." + address
+		}
+		for _, instance := range res.Instances {
+			instanceAddress := address
+			switch key := instance.IndexKey.(type) {
+			case string:
+				instanceAddress += fmt.Sprintf("[%q]", key)
+			case float64:
+				instanceAddress += fmt.Sprintf("[%d]", int(key))
+			}
+
+			resources.add(res.Type, diffKey(spec, instance.Attributes), diffResource{
+				Address:    instanceAddress,
+				Attributes: instance.Attributes,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// liveResources converts the fetched data into the attributes its
+// resources would have in state, addressed by their generated names.
+func liveResources(data *InfrastructureData, style string, nameMap *NameMap) diffResources {
+	data = sortedData(data)
+	names := newResourceNames(data, nameMap)
+	names.style = style
+	resources := make(diffResources)
+
+	addLive := func(resourceType, name string, attributes map[string]interface{}) {
+		resources.add(resourceType, diffKey(diffSpecs[resourceType], attributes), diffResource{
+			Address:    names.address(resourceType, name),
+			Attributes: attributes,
+		})
+	}
+
+	for _, acc := range data.AWSAccounts {
+		addLive("prism_aws_account", names.accounts[acc.AccountID], map[string]interface{}{
+			"account_id":   acc.AccountID,
+			"account_name": acc.AccountName,
+			"region":       acc.Region,
+		})
+	}
+	for _, ps := range data.PermissionSets {
+		addLive("prism_permission_set", names.permissionSets[ps.ID], map[string]interface{}{
+			"id":               ps.ID,
+			"name":             ps.Name,
+			"description":      ps.Description,
+			"session_duration": ps.SessionDuration,
+			"managed_policies": ps.ManagedPolicies,
+			"inline_policies":  ps.InlinePolicies,
+		})
+	}
+	for _, user := range data.Users {
+		attributes, _ := userAttributes(user)
+		addLive("prism_user", names.users[user.Username], map[string]interface{}{
+			"username":   user.Username,
+			"email":      user.Email,
+			"first_name": user.FirstName,
+			"last_name":  user.LastName,
+			"enabled":    user.Enabled,
+			"attributes": attributes,
+		})
+	}
+	for _, group := range data.Groups {
+		addLive("prism_group", names.groups[group.Name], map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+			"path":        group.Path,
+		})
+	}
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		if len(data.GroupMemberships[groupName]) == 0 {
+			continue
+		}
+		addLive("prism_group_membership", names.memberships[groupName], map[string]interface{}{
+			"group_name": groupName,
+			"usernames":  data.GroupMemberships[groupName],
+		})
+	}
+	for _, assignment := range groupAssignments(data) {
+		addLive("prism_permission_set_assignment", assignment.Name, map[string]interface{}{
+			"permission_set_id": assignment.PermissionSetID,
+			"principal_type":    assignment.PrincipalType,
+			"principal_id":      assignment.PrincipalID,
+			"account_ids":       assignment.AccountIDs,
+		})
+	}
+	for _, idp := range data.IdentityProviders {
+		addLive("prism_identity_provider", names.identityProviders[idp.Alias], map[string]interface{}{
+			"type":         idp.Type,
+			"alias":        idp.Alias,
+			"display_name": idp.DisplayName,
+			"enabled":      idp.Enabled,
+		})
+	}
+	return resources
+}
+
+// diffKey joins the key attributes of a resource.
+func diffKey(spec diffSpec, attributes map[string]interface{}) string {
+	parts := make([]string, len(spec.KeyAttributes))
+	for i, name := range spec.KeyAttributes {
+		parts[i] = fmt.Sprint(attributes[name])
+		if attributes[name] == nil {
+			parts[i] = ""
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// normalizeAttribute renders a state or live attribute value as JSON so
+// that equal values compare equal: unset, empty strings and empty
+// collections are all null, lists are compared as sets (every list the tool
+// compares is unordered), and JSON map values such as inline policies
+// ignore whitespace.
+func normalizeAttribute(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		value = items
+	case map[string]interface{}:
+		items := make(map[string]string, len(v))
+		for k, item := range v {
+			items[k] = fmt.Sprint(item)
+		}
+		value = items
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return "null"
+		}
+	case []string:
+		if len(v) == 0 {
+			return "null"
+		}
+		sorted := append([]string(nil), v...)
+		sort.Strings(sorted)
+		value = sorted
+	case map[string]string:
+		if len(v) == 0 {
+			return "null"
+		}
+		compacted := make(map[string]string, len(v))
+		for k, item := range v {
+			var buf bytes.Buffer
+			if json.Compact(&buf, []byte(item)) == nil {
+				item = buf.String()
+			}
+			compacted[k] = item
+		}
+		value = compacted
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(encoded.String(), "\n")
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffState_InSync(t *testing.T) {
+	report, err := DiffState(filepath.Join("testdata", "diff", "in-sync.tfstate"), testInfrastructure(), allResourceKinds(t), StyleFlat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasDrift() {
+		var out bytes.Buffer
+		report.Write(&out)
+		t.Errorf("expected no drift, got:\n%s", out.String())
+	}
+}
+
+func TestDiffState_Drift(t *testing.T) {
+	report, err := DiffState(filepath.Join("testdata", "diff", "drift.tfstate"), testInfrastructure(), allResourceKinds(t), StyleFlat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.HasDrift() {
+		t.Fatal("expected drift")
+	}
+
+	var out bytes.Buffer
+	report.Write(&out)
+	want := `⚠️  Prism has drifted from testdata/diff/drift.tfstate
+
+In Prism but not in state (1):
+  + prism_user.o_brien (prism_user o'brien)
+
+In state but not in Prism (1):
+  - prism_group.legacy (prism_group Legacy)
+
+Attribute differences (3):
+  ~ prism_permission_set.readonly
+      inline_policies: state {"deny-iam":"{\"Version\":\"2012-10-17\",\"Statement\":[]}"}, Prism null
+  ~ prism_permission_set_assignment.readonly_engineering
+      account_ids: state ["111111111111"], Prism ["111111111111","222222222222"]
+  ~ prism_user.alice
+      email: state "alice@old.example.com", Prism "alice@example.com"
+`
+	if got := strings.ReplaceAll(out.String(), `testdata\diff\`, "testdata/diff/"); got != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffState_OnlyComparesSelectedKinds(t *testing.T) {
+	kinds, err := ParseResourceKinds("groups", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := DiffState(filepath.Join("testdata", "diff", "drift.tfstate"), testInfrastructure(), kinds, StyleFlat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Missing) != 0 || len(report.Changed) != 0 || len(report.Orphaned) != 1 {
+		t.Errorf("expected only the orphaned group, got %+v", report)
+	}
+}
+
+func TestDiffState_Errors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		wantErr string
+	}{
+		"not json":    {`{"version":`, "failed to decode"},
+		"old version": {`{"version":3,"modules":[]}`, "only version 4"},
+	}
+
+	for name, tt := range tests {
+		path := filepath.Join(t.TempDir(), "terraform.tfstate")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := DiffState(path, testInfrastructure(), allResourceKinds(t), StyleFlat, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestNormalizeAttribute(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+	}{
+		{"unset string", nil, ""},
+		{"empty list", nil, []interface{}{}},
+		{"empty map", map[string]interface{}{}, map[string]string(nil)},
+		{"list order", []interface{}{"b", "a"}, []string{"a", "b"}},
+		{"policy whitespace", map[string]interface{}{"p": "{\n  \"a\": [1, 2]\n}\n"}, map[string]string{"p": `{"a":[1,2]}`}},
+		{"html", "a&b<c>", "a&b<c>"},
+	}
+	for _, tt := range tests {
+		if a, b := normalizeAttribute(tt.a), normalizeAttribute(tt.b); a != b {
+			t.Errorf("%s: expected %s and %s to be equal", tt.name, a, b)
+		}
+	}
+
+	if got := normalizeAttribute("a&b"); got != `"a&b"` {
+		t.Errorf("expected HTML characters unescaped, got %s", got)
+	}
+	if normalizeAttribute(true) == normalizeAttribute(false) {
+		t.Error("expected true and false to differ")
+	}
+}
+
+func allResourceKinds(t *testing.T) ResourceKinds {
+	t.Helper()
+	kinds, err := ParseResourceKinds("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kinds
+}
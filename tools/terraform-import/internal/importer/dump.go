@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// dumpSchemaVersion is the version of the -export-json format. Bump it when
+// a change to InfrastructureData means older dumps can no longer be read.
+const dumpSchemaVersion = 1
+
+// Dump is the file written by -export-json and read by -from-json.
+type Dump struct {
+	SchemaVersion  int                 `json:"schema_version"`
+	PrismSubdomain string              `json:"prism_subdomain,omitempty"`
+	ExportedAt     time.Time           `json:"exported_at"`
+	Data           *InfrastructureData `json:"data"`
+}
+
+// WriteDump writes data to path so that generation can be rerun later with
+// -from-json without calling the API.
+func WriteDump(path, prismSubdomain string, data *InfrastructureData) error {
+	content, err := json.MarshalIndent(Dump{
+		SchemaVersion:  dumpSchemaVersion,
+		PrismSubdomain: prismSubdomain,
+		ExportedAt:     time.Now().UTC(),
+		Data:           data,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	// The dump lists every user's email address, so keep it private
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadDump reads a file written by WriteDump.
+func LoadDump(path string) (*Dump, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var d Dump
+	if err := json.Unmarshal(content, &d); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	switch {
+	case d.SchemaVersion == 0:
+		return nil, fmt.Errorf("%s is not an -export-json dump: schema_version is missing", path)
+	case d.SchemaVersion > dumpSchemaVersion:
+		return nil, fmt.Errorf("%s has schema version %d, but this tool reads up to version %d; use a newer version of the tool",
+			path, d.SchemaVersion, dumpSchemaVersion)
+	case d.Data == nil:
+		return nil, fmt.Errorf("%s has no data", path)
+	}
+
+	if d.Data.GroupMemberships == nil {
+		d.Data.GroupMemberships = make(map[string][]string)
+	}
+	return &d, nil
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDump_RoundTrip(t *testing.T) {
+	for name, data := range map[string]*InfrastructureData{
+		"test":    testInfrastructure(),
+		"hostile": hostileInfrastructure(),
+	} {
+		path := filepath.Join(t.TempDir(), "dump.json")
+		if err := WriteDump(path, "acme", data); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		d, err := LoadDump(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if d.SchemaVersion != dumpSchemaVersion || d.PrismSubdomain != "acme" || d.ExportedAt.IsZero() {
+			t.Errorf("%s: unexpected dump header %+v", name, d)
+		}
+		if !reflect.DeepEqual(d.Data, data) {
+			t.Errorf("%s: data changed in the round trip:\n got: %+v\nwant: %+v", name, d.Data, data)
+		}
+	}
+}
+
+func TestDump_FileIsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.json")
+	if err := WriteDump(path, "acme", testInfrastructure()); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected mode 0600, got %s", perm)
+	}
+}
+
+func TestGenerateFiles_FromDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.json")
+	if err := WriteDump(path, "acme", testInfrastructure()); err != nil {
+		t.Fatal(err)
+	}
+	d, err := LoadDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Generating from a dump gives the same files as from the API
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, d.Data)
+	assertGoldenDir(t, dir, "import-blocks")
+}
+
+func TestLoadDump_Errors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		wantErr string
+	}{
+		"not json":       {`{"schema_version":`, "failed to decode"},
+		"no version":     {`{"data":{}}`, "not an -export-json dump"},
+		"future version": {`{"schema_version":99,"data":{}}`, "use a newer version of the tool"},
+		"no data":        {`{"schema_version":1}`, "has no data"},
+	}
+
+	for name, tt := range tests {
+		path := filepath.Join(t.TempDir(), "dump.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadDump(path)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tt.wantErr, err)
+		}
+	}
+
+	if _, err := LoadDump(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestFilterData(t *testing.T) {
+	kinds, err := ParseResourceKinds("", "users,memberships")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := FilterData(testInfrastructure(), kinds)
+
+	if len(data.Users) != 0 || len(data.GroupMemberships) != 0 {
+		t.Errorf("expected users and memberships to be dropped, got %+v", data)
+	}
+	if len(data.AWSAccounts) == 0 || len(data.Groups) == 0 || len(data.PermissionSetAssignments) == 0 {
+		t.Errorf("expected the other kinds to be kept, got %+v", data)
+	}
+}
@@ -0,0 +1,334 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// Client is the part of the Prism API client that Fetcher uses.
+type Client interface {
+	ListAWSAccounts() ([]provider.AWSAccount, error)
+	ListPermissionSets() ([]provider.PermissionSet, error)
+	ListUsers() ([]provider.User, error)
+	ListGroups() ([]provider.Group, error)
+	GetGroupMembers(groupName string) ([]string, error)
+	ListPermissionSetAssignments() ([]provider.PermissionSetAssignment, error)
+	ListIdentityProviders() ([]provider.IdentityProvider, error)
+}
+
+// Fetcher fetches the resources of the selected kinds from the Prism API.
+type Fetcher struct {
+	Client      Client
+	Kinds       ResourceKinds
+	Concurrency int // maximum number of requests in flight
+
+	// Retry is how failed requests are retried; the zero value doesn't
+	// retry.
+	Retry RetryPolicy
+
+	// Strict fails the fetch on the first list that can't be fetched.
+	// Otherwise Fetch carries on and returns what it could fetch along with
+	// a *PartialFetchError.
+	Strict bool
+
+	// Out receives progress messages; nil discards them. Warn receives
+	// warnings about data that couldn't be fetched; nil sends them to Out.
+	Out  io.Writer
+	Warn io.Writer
+
+	// sleep waits between retries; nil means time.Sleep
+	sleep func(time.Duration)
+}
+
+// PartialFetchError is returned by Fetch, along with the data it could
+// fetch, when some lists couldn't be fetched. Their kinds are missing from
+// the data.
+type PartialFetchError struct {
+	Failures []ListFailure // in fetch order
+}
+
+// ListFailure is a top-level list that couldn't be fetched.
+type ListFailure struct {
+	Noun  string   // e.g. "permission set assignments"
+	Kinds []string // the resource kinds missing because of it
+	Err   error
+}
+
+func (e *PartialFetchError) Error() string {
+	nouns := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		nouns[i] = failure.Noun
+	}
+	return fmt.Sprintf("failed to fetch %s", strings.Join(nouns, ", "))
+}
+
+// FetchedKinds returns the kinds in kinds that were fetched.
+func (e *PartialFetchError) FetchedKinds(kinds ResourceKinds) ResourceKinds {
+	fetched := make(ResourceKinds, len(kinds))
+	for kind := range kinds {
+		fetched[kind] = true
+	}
+	for _, failure := range e.Failures {
+		for _, kind := range failure.Kinds {
+			delete(fetched, kind)
+		}
+	}
+	return fetched
+}
+
+// memberProgressSteps is how many progress lines are printed while fetching
+// group members, which takes one request per group.
+const memberProgressSteps = 10
+
+// listFetch is one of the independent top-level lists fetched by Fetcher.
+type listFetch struct {
+	noun  string
+	kinds []string // the resource kinds that need the list
+	fetch func() (int, error)
+}
+
+// Fetch fetches the resources of every kind in f.Kinds. The top-level lists
+// are fetched concurrently, then the members of each group. Requests are
+// retried according to f.Retry. Unless f.Strict is set, lists that still
+// fail are reported together at the end and returned as a
+// *PartialFetchError with the rest of the data; if every list fails, Fetch
+// returns the first error.
+func (f *Fetcher) Fetch() (*InfrastructureData, error) {
+	kinds, concurrency := f.Kinds, f.Concurrency
+	out := f.Out
+	if out == nil {
+		out = io.Discard
+	}
+	warn := f.Warn
+	if warn == nil {
+		warn = out
+	}
+
+	// Tasks report progress as they finish
+	var outMu sync.Mutex
+	progress := func(format string, args ...interface{}) {
+		outMu.Lock()
+		defer outMu.Unlock()
+		fmt.Fprintf(out, format, args...)
+	}
+
+	client := f.retryingClient(progress)
+
+	data := &InfrastructureData{
+		GroupMemberships: make(map[string][]string),
+	}
+
+	var lists []listFetch
+
+	if kinds[kindAWSAccounts] {
+		lists = append(lists, listFetch{"AWS accounts", []string{kindAWSAccounts}, func() (int, error) {
+			accounts, err := client.ListAWSAccounts()
+			if err != nil {
+				return 0, err
+			}
+			data.AWSAccounts = accounts
+			return len(accounts), nil
+		}})
+	}
+
+	if kinds[kindPermissionSets] {
+		lists = append(lists, listFetch{"permission sets", []string{kindPermissionSets}, func() (int, error) {
+			permSets, err := client.ListPermissionSets()
+			if err != nil {
+				return 0, err
+			}
+			data.PermissionSets = permSets
+			return len(permSets), nil
+		}})
+	}
+
+	if kinds[kindUsers] {
+		lists = append(lists, listFetch{"users", []string{kindUsers}, func() (int, error) {
+			users, err := client.ListUsers()
+			if err != nil {
+				return 0, err
+			}
+			data.Users = users
+			return len(users), nil
+		}})
+	}
+
+	// Memberships are listed per group, so the groups are needed for them
+	// even when groups themselves aren't exported.
+	var groups []provider.Group
+	if kinds[kindGroups] || kinds[kindMemberships] {
+		var groupKinds []string
+		for _, kind := range []string{kindGroups, kindMemberships} {
+			if kinds[kind] {
+				groupKinds = append(groupKinds, kind)
+			}
+		}
+		lists = append(lists, listFetch{"groups", groupKinds, func() (int, error) {
+			list, err := client.ListGroups()
+			if err != nil {
+				return 0, err
+			}
+			groups = list
+			return len(groups), nil
+		}})
+	}
+
+	if kinds[kindAssignments] {
+		lists = append(lists, listFetch{"permission set assignments", []string{kindAssignments}, func() (int, error) {
+			assignments, err := client.ListPermissionSetAssignments()
+			if err != nil {
+				return 0, err
+			}
+			data.PermissionSetAssignments = assignments
+			return len(assignments), nil
+		}})
+	}
+
+	if kinds[kindIdentityProviders] {
+		lists = append(lists, listFetch{"identity providers", []string{kindIdentityProviders}, func() (int, error) {
+			idps, err := client.ListIdentityProviders()
+			if err != nil {
+				return 0, err
+			}
+			data.IdentityProviders = idps
+			return len(idps), nil
+		}})
+	}
+
+	// The lists are independent, so fetch them concurrently. Each task
+	// writes only its own field of data.
+	tasks := make([]func() error, len(lists))
+	for i, list := range lists {
+		fmt.Fprintf(out, "  → Fetching %s...\n", list.noun)
+		tasks[i] = func() error {
+			start := time.Now()
+			count, err := list.fetch()
+			if err == nil {
+				progress("    Found %d %s (%s)\n", count, list.noun, time.Since(start).Round(time.Millisecond))
+			}
+			return err
+		}
+	}
+	partial := &PartialFetchError{}
+	failed := make(map[string]bool)
+	for i, err := range runConcurrently(concurrency, tasks) {
+		if err == nil {
+			continue
+		}
+		if f.Strict {
+			return nil, fmt.Errorf("failed to fetch %s: %w", lists[i].noun, err)
+		}
+		partial.Failures = append(partial.Failures, ListFailure{Noun: lists[i].noun, Kinds: lists[i].kinds, Err: err})
+		for _, kind := range lists[i].kinds {
+			failed[kind] = true
+		}
+	}
+	if len(partial.Failures) > 0 && len(partial.Failures) == len(lists) {
+		// Nothing was fetched, so there is nothing to carry on with
+		failure := partial.Failures[0]
+		return nil, fmt.Errorf("failed to fetch %s: %w", failure.Noun, failure.Err)
+	}
+
+	if kinds[kindGroups] {
+		data.Groups = groups
+	}
+
+	// Fetch Group Memberships, which needs the list of groups
+	if kinds[kindMemberships] && !failed[kindMemberships] {
+		fmt.Fprintf(out, "  → Fetching group memberships for %d groups...\n", len(groups))
+		done, step := 0, max((len(groups)+memberProgressSteps-1)/memberProgressSteps, 1)
+		members, memberErrors := fetchGroupMembers(client, groups, concurrency, func() {
+			outMu.Lock()
+			defer outMu.Unlock()
+			done++
+			if done%step == 0 || done == len(groups) {
+				fmt.Fprintf(out, "    %d/%d groups\n", done, len(groups))
+			}
+		})
+		for groupName, usernames := range members {
+			data.GroupMemberships[groupName] = usernames
+		}
+		if len(memberErrors) > 0 {
+			fmt.Fprintf(warn, "    Warning: failed to fetch members for %d groups:\n", len(memberErrors))
+			for _, memberError := range memberErrors {
+				fmt.Fprintf(warn, "      - %s\n", memberError)
+			}
+		}
+		withMembers := 0
+		for _, usernames := range data.GroupMemberships {
+			if len(usernames) > 0 {
+				withMembers++
+			}
+		}
+		fmt.Fprintf(out, "    Found memberships for %d groups\n", withMembers)
+	}
+
+	if len(partial.Failures) > 0 {
+		fmt.Fprintf(warn, "    Warning: failed to fetch %d of %d lists; their resources are left out:\n", len(partial.Failures), len(lists))
+		for _, failure := range partial.Failures {
+			fmt.Fprintf(warn, "      - %s: %s\n", failure.Noun, failure.Err)
+		}
+		return data, partial
+	}
+	return data, nil
+}
+
+// fetchGroupMembers fetches the members of each group concurrently, with at
+// most concurrency requests in flight, calling fetched (if not nil) as each
+// group finishes. It returns the members of every group whose members were
+// fetched, and a description of each failure in group order.
+func fetchGroupMembers(client Client, groups []provider.Group, concurrency int, fetched func()) (map[string][]string, []string) {
+	results := make([][]string, len(groups))
+	tasks := make([]func() error, len(groups))
+	for i, group := range groups {
+		tasks[i] = func() error {
+			var err error
+			results[i], err = client.GetGroupMembers(group.Name)
+			if fetched != nil {
+				fetched()
+			}
+			return err
+		}
+	}
+
+	members := make(map[string][]string)
+	var memberErrors []string
+	for i, err := range runConcurrently(concurrency, tasks) {
+		if err != nil {
+			// Collect errors but keep the memberships of the other groups
+			memberErrors = append(memberErrors, fmt.Sprintf("group %s: %s", groups[i].Name, err))
+			continue
+		}
+		// Keep empty groups too, so that they can be told apart from groups
+		// whose members couldn't be fetched
+		members[groups[i].Name] = append([]string{}, results[i]...)
+	}
+	return members, memberErrors
+}
+
+// runConcurrently runs tasks with at most concurrency of them in flight and
+// returns their errors in task order.
+func runConcurrently(concurrency int, tasks []func() error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(tasks))
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			errs[i] = task()
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,298 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// manyGroupsInfrastructure returns n groups with two members each.
+func manyGroupsInfrastructure(n int) *InfrastructureData {
+	data := testInfrastructure()
+	data.Groups = nil
+	data.GroupMemberships = make(map[string][]string)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("group-%02d", i)
+		data.Groups = append(data.Groups, provider.Group{ID: name, Name: name})
+		data.GroupMemberships[name] = []string{"alice", fmt.Sprintf("user-%02d", i)}
+	}
+	return data
+}
+
+func TestFetcher_Concurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures wall-clock time")
+	}
+
+	const requestLatency = 20 * time.Millisecond
+	data := manyGroupsInfrastructure(20)
+
+	// Vary the latency by path so that responses complete out of order
+	latency := func(path string) time.Duration {
+		h := fnv.New32a()
+		h.Write([]byte(path))
+		return requestLatency + time.Duration(h.Sum32()%10)*time.Millisecond
+	}
+
+	fetch := func(concurrency int) (*InfrastructureData, time.Duration) {
+		t.Helper()
+		client, _ := newSlowFakePrism(t, data, latency)
+		kinds, err := ParseResourceKinds("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		got, err := (&Fetcher{Client: client, Kinds: kinds, Concurrency: concurrency}).Fetch()
+		if err != nil {
+			t.Fatalf("fetching with concurrency %d: %s", concurrency, err)
+		}
+		return got, time.Since(start)
+	}
+
+	serial, serialElapsed := fetch(1)
+	parallel, parallelElapsed := fetch(5)
+
+	// 25 requests one at a time take at least 500ms; five at a time need
+	// about a fifth of that
+	if parallelElapsed*2 > serialElapsed {
+		t.Errorf("expected concurrency 5 to be at least twice as fast as 1, took %s vs %s", parallelElapsed, serialElapsed)
+	}
+
+	if !reflect.DeepEqual(parallel, serial) {
+		t.Errorf("expected the same data regardless of concurrency:\nserial:   %+v\nparallel: %+v", serial, parallel)
+	}
+	if !reflect.DeepEqual(parallel.Groups, data.Groups) {
+		t.Errorf("expected groups in API order, got %+v", parallel.Groups)
+	}
+	if !reflect.DeepEqual(parallel.GroupMemberships, data.GroupMemberships) {
+		t.Errorf("expected memberships %v, got %v", data.GroupMemberships, parallel.GroupMemberships)
+	}
+}
+
+func TestFetchGroupMembers_AggregatesErrors(t *testing.T) {
+	data := manyGroupsInfrastructure(6)
+	client, _ := newFakePrism(t, data)
+
+	// Groups the fake doesn't know about fail with 404
+	groups := append([]provider.Group{{Name: "missing-b"}}, data.Groups...)
+	groups = append(groups, provider.Group{Name: "missing-a"})
+
+	members, errs := fetchGroupMembers(client, groups, 3, nil)
+	if !reflect.DeepEqual(members, data.GroupMemberships) {
+		t.Errorf("expected members of the other groups %v, got %v", data.GroupMemberships, members)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	// Errors follow group order, not completion order
+	for i, name := range []string{"missing-b", "missing-a"} {
+		if want := "group " + name + ": "; !strings.HasPrefix(errs[i], want) {
+			t.Errorf("expected error %d to start with %q, got %q", i, want, errs[i])
+		}
+	}
+}
+
+func TestRunConcurrently_BoundsInFlight(t *testing.T) {
+	var (
+		inFlight    = make(chan struct{}, 100)
+		maxInFlight int
+		tasks       []func() error
+	)
+	results := make(chan int, 100)
+	for i := 0; i < 20; i++ {
+		tasks = append(tasks, func() error {
+			inFlight <- struct{}{}
+			results <- len(inFlight)
+			time.Sleep(5 * time.Millisecond)
+			<-inFlight
+			if i%7 == 0 {
+				return fmt.Errorf("task %d", i)
+			}
+			return nil
+		})
+	}
+
+	errs := runConcurrently(3, tasks)
+	close(results)
+	for n := range results {
+		maxInFlight = max(maxInFlight, n)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 tasks in flight, saw %d", maxInFlight)
+	}
+
+	for i, err := range errs {
+		if (i%7 == 0) != (err != nil) {
+			t.Errorf("task %d: unexpected error %v", i, err)
+		}
+	}
+}
+
+// stubClient serves data from memory, failing the lists named in errs.
+type stubClient struct {
+	data *InfrastructureData
+	errs map[string]error
+}
+
+func (c *stubClient) ListAWSAccounts() ([]provider.AWSAccount, error) {
+	return c.data.AWSAccounts, c.errs["accounts"]
+}
+
+func (c *stubClient) ListPermissionSets() ([]provider.PermissionSet, error) {
+	return c.data.PermissionSets, c.errs["permission sets"]
+}
+
+func (c *stubClient) ListUsers() ([]provider.User, error) {
+	return c.data.Users, c.errs["users"]
+}
+
+func (c *stubClient) ListGroups() ([]provider.Group, error) {
+	return c.data.Groups, c.errs["groups"]
+}
+
+func (c *stubClient) GetGroupMembers(groupName string) ([]string, error) {
+	return c.data.GroupMemberships[groupName], c.errs["members of "+groupName]
+}
+
+func (c *stubClient) ListPermissionSetAssignments() ([]provider.PermissionSetAssignment, error) {
+	return c.data.PermissionSetAssignments, c.errs["assignments"]
+}
+
+func (c *stubClient) ListIdentityProviders() ([]provider.IdentityProvider, error) {
+	return c.data.IdentityProviders, c.errs["identity providers"]
+}
+
+func TestFetcher_StubClient(t *testing.T) {
+	data := testInfrastructure()
+	data.IdentityProviders = identityProviderInfrastructure().IdentityProviders
+
+	var out strings.Builder
+	fetcher := &Fetcher{Client: &stubClient{data: data}, Kinds: allResourceKinds(t), Concurrency: 2, Out: &out}
+	got, err := fetcher.Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Errorf("expected %+v, got %+v", data, got)
+	}
+	for _, want := range []string{"Found 2 users", "Found 2 identity providers", "Found memberships for 1 groups"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected progress to contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFetcher_Errors(t *testing.T) {
+	data := testInfrastructure()
+
+	// A failed list fails a strict fetch
+	fetcher := &Fetcher{
+		Client:      &stubClient{data: data, errs: map[string]error{"users": fmt.Errorf("boom")}},
+		Kinds:       allResourceKinds(t),
+		Concurrency: 2,
+		Strict:      true,
+	}
+	if _, err := fetcher.Fetch(); err == nil || err.Error() != "failed to fetch users: boom" {
+		t.Errorf("expected the users error, got %v", err)
+	}
+
+	// A failed member list only loses that group's memberships
+	fetcher.Client = &stubClient{data: data, errs: map[string]error{"members of Engineering": fmt.Errorf("boom")}}
+	got, err := fetcher.Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.GroupMemberships) != 0 || len(got.Groups) != 1 {
+		t.Errorf("expected the group without its memberships, got %+v", got)
+	}
+}
+
+func TestFetcher_PartialFetch(t *testing.T) {
+	data := testInfrastructure()
+	kinds := allResourceKinds(t)
+
+	var warn strings.Builder
+	fetcher := &Fetcher{
+		Client:      &stubClient{data: data, errs: map[string]error{"groups": fmt.Errorf("boom"), "assignments": fmt.Errorf("bang")}},
+		Kinds:       kinds,
+		Concurrency: 2,
+		Warn:        &warn,
+	}
+	got, err := fetcher.Fetch()
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a PartialFetchError, got %v", err)
+	}
+	if err.Error() != "failed to fetch groups, permission set assignments" {
+		t.Errorf("unexpected error %q", err)
+	}
+
+	// The other lists are kept; memberships need the groups, so they're missing too
+	if !reflect.DeepEqual(got.Users, data.Users) || !reflect.DeepEqual(got.AWSAccounts, data.AWSAccounts) {
+		t.Errorf("expected the lists that were fetched, got %+v", got)
+	}
+	if len(got.Groups) != 0 || len(got.GroupMemberships) != 0 || len(got.PermissionSetAssignments) != 0 {
+		t.Errorf("expected no groups, memberships or assignments, got %+v", got)
+	}
+
+	fetched := partial.FetchedKinds(kinds)
+	want := ResourceKinds{kindAWSAccounts: true, kindPermissionSets: true, kindUsers: true, kindIdentityProviders: true}
+	if !reflect.DeepEqual(fetched, want) {
+		t.Errorf("expected fetched kinds %v, got %v", want, fetched)
+	}
+	if len(kinds) != len(AllKinds) {
+		t.Errorf("expected FetchedKinds to leave its argument alone, got %v", kinds)
+	}
+
+	for _, line := range []string{"Warning: failed to fetch 2 of 6 lists", "- groups: boom", "- permission set assignments: bang"} {
+		if !strings.Contains(warn.String(), line) {
+			t.Errorf("expected the summary to contain %q, got:\n%s", line, warn.String())
+		}
+	}
+
+	// With nothing fetched there's nothing to carry on with
+	fetcher.Client = &stubClient{data: data, errs: map[string]error{"users": fmt.Errorf("boom")}}
+	fetcher.Kinds = ResourceKinds{kindUsers: true}
+	if got, err := fetcher.Fetch(); got != nil || err == nil || err.Error() != "failed to fetch users: boom" {
+		t.Errorf("expected only the users error, got %v, %v", got, err)
+	}
+}
+
+func TestFetcher_Progress(t *testing.T) {
+	data := manyGroupsInfrastructure(25)
+
+	var out, warn strings.Builder
+	fetcher := &Fetcher{
+		Client:      &stubClient{data: data, errs: map[string]error{"members of group-03": fmt.Errorf("boom")}},
+		Kinds:       allResourceKinds(t),
+		Concurrency: 4,
+		Out:         &out,
+		Warn:        &warn,
+	}
+	if _, err := fetcher.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"→ Fetching group memberships for 25 groups...", "    3/25 groups\n", "    25/25 groups\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected progress to contain %q:\n%s", want, out.String())
+		}
+	}
+	if lines := strings.Count(out.String(), "/25 groups"); lines > memberProgressSteps+1 {
+		t.Errorf("expected at most %d member progress lines, got %d:\n%s", memberProgressSteps+1, lines, out.String())
+	}
+
+	if strings.Contains(out.String(), "Warning") {
+		t.Errorf("expected warnings to go to Warn, got progress:\n%s", out.String())
+	}
+	if !strings.Contains(warn.String(), "group group-03: boom") {
+		t.Errorf("expected a warning for group-03, got %q", warn.String())
+	}
+}
@@ -0,0 +1,305 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Resource kinds accepted by -include and -exclude
+const (
+	kindAWSAccounts       = "aws_accounts"
+	kindPermissionSets    = "permission_sets"
+	kindUsers             = "users"
+	kindGroups            = "groups"
+	kindMemberships       = "memberships"
+	kindAssignments       = "assignments"
+	kindIdentityProviders = "identity_providers"
+)
+
+// AllKinds lists every resource kind in generation order.
+var AllKinds = []string{
+	kindAWSAccounts,
+	kindPermissionSets,
+	kindUsers,
+	kindGroups,
+	kindMemberships,
+	kindAssignments,
+	kindIdentityProviders,
+}
+
+// ResourceKinds is the set of resource kinds to fetch and generate.
+type ResourceKinds map[string]bool
+
+// ParseResourceKinds turns the -include and -exclude flag values into the
+// set of kinds to export. An empty include list means every kind; exclusions
+// are applied after inclusions.
+func ParseResourceKinds(include, exclude string) (ResourceKinds, error) {
+	included, err := splitKinds("-include", include)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := splitKinds("-exclude", exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make(ResourceKinds)
+	if len(included) == 0 {
+		included = AllKinds
+	}
+	for _, kind := range included {
+		kinds[kind] = true
+	}
+	for _, kind := range excluded {
+		delete(kinds, kind)
+	}
+
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("-include and -exclude leave no resource kinds to export")
+	}
+	return kinds, nil
+}
+
+// splitKinds parses a comma-separated list of resource kinds.
+func splitKinds(flagName, list string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		if !isKnownKind(kind) {
+			return nil, fmt.Errorf("%s: unknown resource kind %q (valid kinds: %s)", flagName, kind, strings.Join(AllKinds, ", "))
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+func isKnownKind(kind string) bool {
+	for _, known := range AllKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterData drops the resources of kinds not in kinds from data, e.g. when
+// generating from a dump that was exported with different filters.
+func FilterData(data *InfrastructureData, kinds ResourceKinds) *InfrastructureData {
+	filtered := &InfrastructureData{
+		GroupMemberships: make(map[string][]string),
+	}
+	if kinds[kindAWSAccounts] {
+		filtered.AWSAccounts = data.AWSAccounts
+	}
+	if kinds[kindPermissionSets] {
+		filtered.PermissionSets = data.PermissionSets
+	}
+	if kinds[kindUsers] {
+		filtered.Users = data.Users
+	}
+	if kinds[kindGroups] {
+		filtered.Groups = data.Groups
+	}
+	if kinds[kindMemberships] {
+		filtered.GroupMemberships = data.GroupMemberships
+	}
+	if kinds[kindAssignments] {
+		filtered.PermissionSetAssignments = data.PermissionSetAssignments
+	}
+	if kinds[kindIdentityProviders] {
+		filtered.IdentityProviders = data.IdentityProviders
+	}
+	return filtered
+}
+
+// NameFilter selects users or groups by name for -user-filter and
+// -group-filter. A nil filter matches every name.
+type NameFilter struct {
+	pattern *regexp.Regexp
+	negate  bool // keep the names that don't match
+}
+
+// ParseNameFilter compiles a -user-filter or -group-filter value: a regular
+// expression, or "!" followed by one to keep the names it doesn't match.
+// An empty value gives a nil filter.
+func ParseNameFilter(flagName, value string) (*NameFilter, error) {
+	if value == "" {
+		return nil, nil
+	}
+	filter := &NameFilter{}
+	if strings.HasPrefix(value, "!") {
+		filter.negate = true
+		value = value[1:]
+	}
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", flagName, err)
+	}
+	filter.pattern = pattern
+	return filter, nil
+}
+
+func (f *NameFilter) matches(name string) bool {
+	if f == nil {
+		return true
+	}
+	return f.pattern.MatchString(name) != f.negate
+}
+
+// NameFilterSummary counts what ApplyNameFilters kept.
+type NameFilterSummary struct {
+	Users, TotalUsers   int
+	Groups, TotalGroups int
+	Assignments         int // assignments left out with their principal
+}
+
+func (s NameFilterSummary) String() string {
+	return fmt.Sprintf("%d of %d users and %d of %d groups matched; %d assignments left out",
+		s.Users, s.TotalUsers, s.Groups, s.TotalGroups, s.Assignments)
+}
+
+// ApplyNameFilters keeps the users matching users and the groups matching
+// groups. Memberships and assignments follow their group or principal.
+// Memberships of kept groups still list every member, since the membership
+// resource owns the whole list; members that aren't generated are written as
+// literal usernames rather than references.
+func ApplyNameFilters(data *InfrastructureData, users, groups *NameFilter) (*InfrastructureData, NameFilterSummary) {
+	summary := NameFilterSummary{TotalUsers: len(data.Users), TotalGroups: len(data.Groups)}
+	result := *data
+
+	result.Users = nil
+	for _, user := range data.Users {
+		if users.matches(user.Username) {
+			result.Users = append(result.Users, user)
+		}
+	}
+	summary.Users = len(result.Users)
+
+	result.Groups = nil
+	for _, group := range data.Groups {
+		if groups.matches(group.Name) {
+			result.Groups = append(result.Groups, group)
+		}
+	}
+	summary.Groups = len(result.Groups)
+
+	// Memberships are fetched even when groups aren't exported, so filter
+	// them by name rather than by the groups kept above
+	result.GroupMemberships = make(map[string][]string, len(data.GroupMemberships))
+	for groupName, usernames := range data.GroupMemberships {
+		if groups.matches(groupName) {
+			result.GroupMemberships[groupName] = usernames
+		}
+	}
+
+	result.PermissionSetAssignments = nil
+	for _, assignment := range data.PermissionSetAssignments {
+		if (assignment.PrincipalType == "USER" && !users.matches(assignment.Username)) ||
+			(assignment.PrincipalType == "GROUP" && !groups.matches(assignment.GroupName)) {
+			summary.Assignments++
+			continue
+		}
+		result.PermissionSetAssignments = append(result.PermissionSetAssignments, assignment)
+	}
+
+	return &result, summary
+}
+
+// SkipOptions selects fetched objects to leave out of the generated files.
+type SkipOptions struct {
+	DisabledUsers bool
+	EmptyGroups   bool
+}
+
+// SkipSummary counts the objects SkipObjects left out.
+type SkipSummary struct {
+	Users       int // disabled users
+	Groups      int // empty groups
+	Memberships int // disabled users' group memberships
+	Assignments int // assignments to skipped users and groups
+}
+
+func (s SkipSummary) String() string {
+	return fmt.Sprintf("%d disabled users, %d empty groups, %d group memberships and %d assignments",
+		s.Users, s.Groups, s.Memberships, s.Assignments)
+}
+
+// SkipObjects leaves the objects selected by opts out of data. Disabled
+// users are also removed from memberships and assignments, so nothing
+// refers to a user that isn't generated. A group is empty when its members
+// were fetched and there are none, counting after disabled users were
+// removed; groups whose members couldn't be fetched are kept.
+func SkipObjects(data *InfrastructureData, opts SkipOptions) (*InfrastructureData, SkipSummary) {
+	var summary SkipSummary
+	result := *data
+
+	disabled := make(map[string]bool)
+	if opts.DisabledUsers {
+		result.Users = nil
+		for _, user := range data.Users {
+			if !user.Enabled {
+				disabled[user.Username] = true
+				summary.Users++
+				continue
+			}
+			result.Users = append(result.Users, user)
+		}
+	}
+
+	result.GroupMemberships = make(map[string][]string, len(data.GroupMemberships))
+	for groupName, usernames := range data.GroupMemberships {
+		kept := []string{}
+		for _, username := range usernames {
+			if disabled[username] {
+				summary.Memberships++
+				continue
+			}
+			kept = append(kept, username)
+		}
+		result.GroupMemberships[groupName] = kept
+	}
+
+	emptyGroups := make(map[string]bool)
+	if opts.EmptyGroups {
+		result.Groups = nil
+		for _, group := range data.Groups {
+			if members, ok := result.GroupMemberships[group.Name]; ok && len(members) == 0 {
+				emptyGroups[group.Name] = true
+				delete(result.GroupMemberships, group.Name)
+				summary.Groups++
+				continue
+			}
+			result.Groups = append(result.Groups, group)
+		}
+	}
+
+	result.PermissionSetAssignments = nil
+	for _, assignment := range data.PermissionSetAssignments {
+		if (assignment.PrincipalType == "USER" && disabled[assignment.Username]) ||
+			(assignment.PrincipalType == "GROUP" && emptyGroups[assignment.GroupName]) {
+			summary.Assignments++
+			continue
+		}
+		result.PermissionSetAssignments = append(result.PermissionSetAssignments, assignment)
+	}
+
+	return &result, summary
+}
+
+// CountResources describes how many resources of each kind data holds, for
+// the -dry-run summary.
+func CountResources(data *InfrastructureData) string {
+	memberships := 0
+	for _, usernames := range data.GroupMemberships {
+		if len(usernames) > 0 {
+			memberships++
+		}
+	}
+	return fmt.Sprintf("%d AWS accounts, %d permission sets, %d users, %d groups, %d group memberships, %d permission set assignments, %d identity providers",
+		len(data.AWSAccounts), len(data.PermissionSets), len(data.Users), len(data.Groups), memberships,
+		len(data.PermissionSetAssignments), len(data.IdentityProviders))
+}
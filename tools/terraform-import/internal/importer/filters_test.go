@@ -0,0 +1,625 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestParseResourceKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		want    []string
+		wantErr string
+	}{
+		{name: "defaults", want: AllKinds},
+		{name: "include", include: "users, groups", want: []string{kindGroups, kindUsers}},
+		{name: "exclude", exclude: "aws_accounts,assignments", want: []string{kindGroups, kindIdentityProviders, kindMemberships, kindPermissionSets, kindUsers}},
+		{name: "include and exclude", include: "users,groups,memberships", exclude: "memberships", want: []string{kindGroups, kindUsers}},
+		{name: "unknown include", include: "users,accounts", wantErr: `-include: unknown resource kind "accounts"`},
+		{name: "unknown exclude", exclude: "Users", wantErr: `-exclude: unknown resource kind "Users"`},
+		{name: "nothing left", include: "users", exclude: "users", wantErr: "no resource kinds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kinds, err := ParseResourceKinds(tt.include, tt.exclude)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got []string
+			for kind := range kinds {
+				got = append(got, kind)
+			}
+			sort.Strings(got)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected kinds %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestFetcher_SkipsExcludedKinds(t *testing.T) {
+	tests := []struct {
+		include string
+		exclude string
+		want    []string
+	}{
+		{
+			want: []string{"/aws-accounts", "/permission-sets", "/users", "/groups", "/groups/Engineering/members", "/permission-set-assignments", "/identity-providers"},
+		},
+		{
+			include: "users,groups",
+			want:    []string{"/users", "/groups"},
+		},
+		{
+			// Memberships are listed per group, so groups are still listed
+			include: "memberships",
+			want:    []string{"/groups", "/groups/Engineering/members"},
+		},
+		{
+			exclude: "aws_accounts,assignments,memberships",
+			want:    []string{"/permission-sets", "/users", "/groups", "/identity-providers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("include="+tt.include+",exclude="+tt.exclude, func(t *testing.T) {
+			client, paths := newFakePrism(t, testInfrastructure())
+			kinds, err := ParseResourceKinds(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := (&Fetcher{Client: client, Kinds: kinds, Concurrency: 5}).Fetch(); err != nil {
+				t.Fatalf("fetching data: %s", err)
+			}
+			// Lists are fetched concurrently, so only the set of requests is fixed
+			got := paths()
+			sort.Strings(got)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected requests %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateFiles_ExcludedKindsUseLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  string
+		exclude  string
+		contains map[string][]string
+		absent   []string
+	}{
+		{
+			name:    "users and groups only",
+			include: "users,groups,memberships",
+			contains: map[string][]string{
+				"groups.tf": {"group_name = prism_group.engineering.name", "prism_user.alice.username,"},
+			},
+			absent: []string{"aws_accounts.tf", "permission_sets.tf", "assignments.tf"},
+		},
+		{
+			name:    "memberships without users",
+			exclude: "users",
+			contains: map[string][]string{
+				"groups.tf":      {"group_name = prism_group.engineering.name", "\"alice\",", "\"o'brien\","},
+				"assignments.tf": {"principal_id      = prism_group.engineering.name"},
+			},
+			absent: []string{"users.tf"},
+		},
+		{
+			name:    "assignments only",
+			include: "assignments",
+			contains: map[string][]string{
+				"assignments.tf": {
+					`resource "prism_permission_set_assignment" "ps_1_engineering"`,
+					`permission_set_id = "ps-1"`,
+					`principal_id      = "Engineering"`,
+					`"111111111111",`,
+				},
+				"imports.tf": {"to = prism_permission_set_assignment.ps_1_engineering"},
+			},
+			absent: []string{"aws_accounts.tf", "permission_sets.tf", "users.tf", "groups.tf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newFakePrism(t, testInfrastructure())
+			kinds, err := ParseResourceKinds(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := (&Fetcher{Client: client, Kinds: kinds, Concurrency: 5}).Fetch()
+			if err != nil {
+				t.Fatalf("fetching data: %s", err)
+			}
+
+			dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, data)
+			for name, wants := range tt.contains {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("expected %s: %s", name, err)
+				}
+				for _, want := range wants {
+					if !strings.Contains(string(content), want) {
+						t.Errorf("expected %s to contain %q:\n%s", name, want, content)
+					}
+				}
+			}
+			for _, name := range tt.absent {
+				if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+					t.Errorf("expected no %s, got %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+// newFakePrism serves data from a fake Prism API and returns a client for
+// it along with a function listing the paths requested so far, relative to
+// the customer prefix.
+func newFakePrism(t *testing.T, data *InfrastructureData) (*provider.Client, func() []string) {
+	t.Helper()
+	return newSlowFakePrism(t, data, nil)
+}
+
+// newSlowFakePrism is newFakePrism with latency(path) added to each request.
+func newSlowFakePrism(t *testing.T, data *InfrastructureData, latency func(path string) time.Duration) (*provider.Client, func() []string) {
+	t.Helper()
+	return newInterceptedFakePrism(t, data, func(w http.ResponseWriter, path string) bool {
+		if latency != nil {
+			time.Sleep(latency(path))
+		}
+		return false
+	})
+}
+
+// newInterceptedFakePrism is newFakePrism with intercept called first for
+// each request; when it returns true, it has written the response itself.
+func newInterceptedFakePrism(t *testing.T, data *InfrastructureData, intercept func(w http.ResponseWriter, path string) bool) (*provider.Client, func() []string) {
+	t.Helper()
+
+	const prefix = "/api/v1/customers/test"
+
+	var mu sync.Mutex
+	var paths []string
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		mu.Lock()
+		paths = append(paths, path)
+		mu.Unlock()
+
+		if intercept != nil && intercept(w, path) {
+			return
+		}
+
+		var body interface{}
+		switch {
+		case path == "/aws-accounts":
+			body = data.AWSAccounts
+		case path == "/permission-sets":
+			body = data.PermissionSets
+		case path == "/users":
+			body = data.Users
+		case path == "/groups":
+			body = data.Groups
+		case path == "/permission-set-assignments":
+			body = map[string]interface{}{"assignments": data.PermissionSetAssignments}
+		case path == "/identity-providers":
+			body = map[string]interface{}{"identityProviders": data.IdentityProviders, "count": len(data.IdentityProviders)}
+		case strings.HasPrefix(path, "/groups/") && strings.HasSuffix(path, "/members"):
+			groupName := strings.TrimSuffix(strings.TrimPrefix(path, "/groups/"), "/members")
+			found := false
+			for _, group := range data.Groups {
+				found = found || group.Name == groupName
+			}
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+			var members []map[string]string
+			for _, username := range data.GroupMemberships[groupName] {
+				members = append(members, map[string]string{"username": username})
+			}
+			body = map[string]interface{}{"group": groupName, "members": members}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": body}); err != nil {
+			t.Errorf("encoding response: %s", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := provider.NewClient(server.URL, "test", "token")
+	client.HTTPClient = server.Client()
+	client.TimingLog = io.Discard
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+// skippableInfrastructure has disabled users and groups that are, or become,
+// empty once they are left out.
+func skippableInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		Users: []provider.User{
+			{ID: "user-1", Username: "alice", Email: "alice@example.com", Enabled: true},
+			{ID: "user-2", Username: "bob", Email: "bob@example.com", Enabled: false},
+			{ID: "user-3", Username: "carol", Email: "carol@example.com", Enabled: false},
+		},
+		Groups: []provider.Group{
+			{ID: "group-1", Name: "Engineering"},
+			{ID: "group-2", Name: "Contractors"},
+			{ID: "group-3", Name: "Empty"},
+			{ID: "group-4", Name: "Unfetched"},
+		},
+		GroupMemberships: map[string][]string{
+			"Engineering": {"alice", "bob"},
+			"Contractors": {"bob", "carol"},
+			"Empty":       {},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "assign-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+			{ID: "assign-2", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "bob", AccountID: "111111111111"},
+			{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "111111111111"},
+			{ID: "assign-4", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Contractors", AccountID: "111111111111"},
+			{ID: "assign-5", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Empty", AccountID: "111111111111"},
+		},
+	}
+}
+
+func TestSkipObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SkipOptions
+		users       []string
+		groups      []string
+		memberships map[string][]string
+		assignments []string
+		summary     SkipSummary
+	}{
+		{
+			name:        "nothing",
+			users:       []string{"alice", "bob", "carol"},
+			groups:      []string{"Engineering", "Contractors", "Empty", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice", "bob"}, "Contractors": {"bob", "carol"}, "Empty": {}},
+			assignments: []string{"assign-1", "assign-2", "assign-3", "assign-4", "assign-5"},
+		},
+		{
+			name:        "disabled users",
+			opts:        SkipOptions{DisabledUsers: true},
+			users:       []string{"alice"},
+			groups:      []string{"Engineering", "Contractors", "Empty", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice"}, "Contractors": {}, "Empty": {}},
+			assignments: []string{"assign-1", "assign-3", "assign-4", "assign-5"},
+			summary:     SkipSummary{Users: 2, Memberships: 3, Assignments: 1},
+		},
+		{
+			name:        "empty groups",
+			opts:        SkipOptions{EmptyGroups: true},
+			users:       []string{"alice", "bob", "carol"},
+			groups:      []string{"Engineering", "Contractors", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice", "bob"}, "Contractors": {"bob", "carol"}},
+			assignments: []string{"assign-1", "assign-2", "assign-3", "assign-4"},
+			summary:     SkipSummary{Groups: 1, Assignments: 1},
+		},
+		{
+			// Contractors only has disabled members, and Unfetched is kept
+			// because its members are unknown
+			name:        "both",
+			opts:        SkipOptions{DisabledUsers: true, EmptyGroups: true},
+			users:       []string{"alice"},
+			groups:      []string{"Engineering", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice"}},
+			assignments: []string{"assign-1", "assign-3"},
+			summary:     SkipSummary{Users: 2, Groups: 2, Memberships: 3, Assignments: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := skippableInfrastructure()
+			got, summary := SkipObjects(data, tt.opts)
+
+			var users, groups, assignments []string
+			for _, user := range got.Users {
+				users = append(users, user.Username)
+			}
+			for _, group := range got.Groups {
+				groups = append(groups, group.Name)
+			}
+			for _, assignment := range got.PermissionSetAssignments {
+				assignments = append(assignments, assignment.ID)
+			}
+
+			if !reflect.DeepEqual(users, tt.users) {
+				t.Errorf("expected users %v, got %v", tt.users, users)
+			}
+			if !reflect.DeepEqual(groups, tt.groups) {
+				t.Errorf("expected groups %v, got %v", tt.groups, groups)
+			}
+			if !reflect.DeepEqual(got.GroupMemberships, tt.memberships) {
+				t.Errorf("expected memberships %v, got %v", tt.memberships, got.GroupMemberships)
+			}
+			if !reflect.DeepEqual(assignments, tt.assignments) {
+				t.Errorf("expected assignments %v, got %v", tt.assignments, assignments)
+			}
+			if summary != tt.summary {
+				t.Errorf("expected summary %+v, got %+v", tt.summary, summary)
+			}
+			if !reflect.DeepEqual(data, skippableInfrastructure()) {
+				t.Errorf("expected the input to be left alone, got %+v", data)
+			}
+		})
+	}
+}
+
+func TestGenerateFiles_SkippedUsersAreNotReferenced(t *testing.T) {
+	userRef := regexp.MustCompile(`prism_user\.(\w+)(?:\["([^"]+)"\])?`)
+
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			data, _ := SkipObjects(skippableInfrastructure(), SkipOptions{DisabledUsers: true, EmptyGroups: true})
+			dir := generateTestFiles(t, Config{ImportFormat: "blocks", Style: style}, data)
+
+			declared := map[string]bool{}
+			if style == StyleForEach {
+				for _, key := range localKeys(t, filepath.Join(dir, "users.tf"), "users") {
+					declared[key] = true
+				}
+			} else {
+				for _, user := range data.Users {
+					declared[toResourceName(user.Username, "user")] = true
+				}
+			}
+
+			for _, file := range []string{"groups.tf", "assignments.tf"} {
+				content, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, username := range []string{"bob", "carol"} {
+					if strings.Contains(string(content), username) {
+						t.Errorf("expected %s not to mention skipped user %s:\n%s", file, username, content)
+					}
+				}
+
+				refs := userRef.FindAllStringSubmatch(string(content), -1)
+				if len(refs) == 0 {
+					t.Errorf("expected %s to refer to prism_user, got:\n%s", file, content)
+				}
+				for _, ref := range refs {
+					name := ref[1]
+					if style == StyleForEach {
+						name = ref[2]
+					}
+					if !declared[name] {
+						t.Errorf("%s refers to undeclared user %s", file, ref[0])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFetchGroupMembers_RecordsEmptyGroups(t *testing.T) {
+	data := skippableInfrastructure()
+	client, _ := newFakePrism(t, data)
+
+	groups := append(data.Groups, provider.Group{Name: "missing"})
+	members, errs := fetchGroupMembers(client, groups, 2, nil)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	// Unfetched has no members in the fake either; missing failed
+	want := map[string][]string{
+		"Engineering": {"alice", "bob"},
+		"Contractors": {"bob", "carol"},
+		"Empty":       {},
+		"Unfetched":   {},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("expected members %v, got %v", want, members)
+	}
+}
+
+func TestParseNameFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		matches []string
+		misses  []string
+		wantErr string
+	}{
+		{name: "empty", value: "", matches: []string{"alice", ""}},
+		{name: "inclusive", value: "^(alice|bob)$", matches: []string{"alice", "bob"}, misses: []string{"carol", "alice2"}},
+		{name: "prefix", value: "^team-a-", matches: []string{"team-a-dev"}, misses: []string{"team-b-dev"}},
+		{name: "exclusive", value: "!^contractor-", matches: []string{"alice", "team-contractor-x"}, misses: []string{"contractor-1"}},
+		{name: "invalid", value: "team-(a", wantErr: "-group-filter: error parsing regexp: missing closing ): `team-(a`"},
+		{name: "invalid exclusive", value: "![a-", wantErr: "-group-filter: error parsing regexp: missing closing ]: `[a-`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseNameFilter("-group-filter", tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.matches {
+				if !filter.matches(name) {
+					t.Errorf("expected %q to match", name)
+				}
+			}
+			for _, name := range tt.misses {
+				if filter.matches(name) {
+					t.Errorf("expected %q not to match", name)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyNameFilters(t *testing.T) {
+	mustFilter := func(value string) *NameFilter {
+		t.Helper()
+		filter, err := ParseNameFilter("-filter", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return filter
+	}
+
+	tests := []struct {
+		name        string
+		users       *NameFilter
+		groups      *NameFilter
+		wantUsers   []string
+		wantGroups  []string
+		memberships map[string][]string
+		assignments []string
+		summary     NameFilterSummary
+	}{
+		{
+			name:        "users",
+			users:       mustFilter("^(alice|carol)$"),
+			wantUsers:   []string{"alice", "carol"},
+			wantGroups:  []string{"Engineering", "Contractors", "Empty", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice", "bob"}, "Contractors": {"bob", "carol"}, "Empty": {}},
+			assignments: []string{"assign-1", "assign-3", "assign-4", "assign-5"},
+			summary:     NameFilterSummary{Users: 2, TotalUsers: 3, Groups: 4, TotalGroups: 4, Assignments: 1},
+		},
+		{
+			name:        "groups",
+			groups:      mustFilter("!^(Contractors|Empty)$"),
+			wantUsers:   []string{"alice", "bob", "carol"},
+			wantGroups:  []string{"Engineering", "Unfetched"},
+			memberships: map[string][]string{"Engineering": {"alice", "bob"}},
+			assignments: []string{"assign-1", "assign-2", "assign-3"},
+			summary:     NameFilterSummary{Users: 3, TotalUsers: 3, Groups: 2, TotalGroups: 4, Assignments: 2},
+		},
+		{
+			name:        "nothing matches",
+			users:       mustFilter("^nobody$"),
+			groups:      mustFilter("^nothing$"),
+			memberships: map[string][]string{},
+			summary:     NameFilterSummary{TotalUsers: 3, TotalGroups: 4, Assignments: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := skippableInfrastructure()
+			got, summary := ApplyNameFilters(data, tt.users, tt.groups)
+
+			var users, groups, assignments []string
+			for _, user := range got.Users {
+				users = append(users, user.Username)
+			}
+			for _, group := range got.Groups {
+				groups = append(groups, group.Name)
+			}
+			for _, assignment := range got.PermissionSetAssignments {
+				assignments = append(assignments, assignment.ID)
+			}
+
+			if !reflect.DeepEqual(users, tt.wantUsers) {
+				t.Errorf("expected users %v, got %v", tt.wantUsers, users)
+			}
+			if !reflect.DeepEqual(groups, tt.wantGroups) {
+				t.Errorf("expected groups %v, got %v", tt.wantGroups, groups)
+			}
+			if !reflect.DeepEqual(got.GroupMemberships, tt.memberships) {
+				t.Errorf("expected memberships %v, got %v", tt.memberships, got.GroupMemberships)
+			}
+			if !reflect.DeepEqual(assignments, tt.assignments) {
+				t.Errorf("expected assignments %v, got %v", tt.assignments, assignments)
+			}
+			if summary != tt.summary {
+				t.Errorf("expected summary %+v, got %+v", tt.summary, summary)
+			}
+			if !reflect.DeepEqual(data, skippableInfrastructure()) {
+				t.Errorf("expected the input to be left alone, got %+v", data)
+			}
+		})
+	}
+}
+
+func TestGenerateFiles_FilteredUsersUseLiterals(t *testing.T) {
+	filter, err := ParseNameFilter("-user-filter", "^alice$")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			data, _ := ApplyNameFilters(skippableInfrastructure(), filter, nil)
+			dir := generateTestFiles(t, Config{ImportFormat: "blocks", Style: style}, data)
+
+			content, err := os.ReadFile(filepath.Join(dir, "groups.tf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{`"bob"`, `"carol"`} {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("expected groups.tf to list filtered user %s literally:\n%s", want, content)
+				}
+			}
+
+			aliceRef := "prism_user.alice.username"
+			if style == StyleForEach {
+				aliceRef = `prism_user.this["alice"].username`
+			}
+			for _, file := range []string{"groups.tf", "assignments.tf"} {
+				content, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if regexp.MustCompile(`prism_user\.(bob|carol)|\["(bob|carol)"\]`).Match(content) {
+					t.Errorf("expected %s not to refer to filtered users:\n%s", file, content)
+				}
+				if !strings.Contains(string(content), aliceRef) {
+					t.Errorf("expected %s to refer to %s:\n%s", file, aliceRef, content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Supported values of the -style flag
+const (
+	StyleFlat    = "flat"
+	StyleForEach = "foreach"
+)
+
+// forEachResourceName names the single for_each resource of each type in
+// forEachTypes.
+const forEachResourceName = "this"
+
+// forEachTypes are the resource types that -style=foreach generates as one
+// for_each resource over a local map. Accounts, permission sets and
+// identity providers are few, so they stay flat.
+var forEachTypes = map[string]bool{
+	"prism_user":                      true,
+	"prism_group":                     true,
+	"prism_group_membership":          true,
+	"prism_group_member":              true,
+	"prism_permission_set_assignment": true,
+}
+
+// forEachResource is a for_each resource and the local map it iterates
+// over. Instances are keyed by their resource name, so the keys match the
+// names the flat style would use. Their values are only rendered when the
+// map is written, one instance at a time, so that a map with tens of
+// thousands of instances isn't held in memory.
+type forEachResource struct {
+	Type       string
+	Local      string
+	Attributes []string
+	Keys       []string
+	Values     []func() map[string]hclwrite.Tokens // in Keys order; attribute -> value
+}
+
+func newForEachResource(resourceType, local string, attributes ...string) *forEachResource {
+	return &forEachResource{
+		Type:       resourceType,
+		Local:      local,
+		Attributes: attributes,
+	}
+}
+
+// add adds an instance whose attributes values returns. Attributes missing
+// from them are null.
+func (r *forEachResource) add(key string, values func() map[string]hclwrite.Tokens) {
+	r.Keys = append(r.Keys, key)
+	r.Values = append(r.Values, values)
+}
+
+// instance renders the i-th instance as an object.
+func (r *forEachResource) instance(i int) hclwrite.Tokens {
+	values := r.Values[i]()
+	for _, attr := range r.Attributes {
+		if values[attr] == nil {
+			values[attr] = hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+		}
+	}
+	return tokensForObject(r.Attributes, values)
+}
+
+// writeForEachResources writes one locals block holding the maps of the
+// resources that have instances, followed by their resource blocks. The
+// maps are written an instance at a time.
+func writeForEachResources(w *hclFileWriter, names *resourceNames, resources ...*forEachResource) {
+	var nonEmpty []*forEachResource
+	for _, r := range resources {
+		if len(r.Keys) > 0 {
+			nonEmpty = append(nonEmpty, r)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return
+	}
+
+	w.writeString("\nlocals {\n")
+	for i, r := range nonEmpty {
+		if i > 0 {
+			w.writeString("\n")
+		}
+		w.writeString("  " + r.Local + " = {\n")
+		for j, key := range r.Keys {
+			// Formatted as the map's only instance, inside the locals block
+			// and the map, which are left out
+			f := hclwrite.NewEmptyFile()
+			locals := f.Body().AppendNewBlock("locals", nil).Body()
+			locals.SetAttributeRaw(r.Local, tokensForObject([]string{key}, map[string]hclwrite.Tokens{key: r.instance(j)}))
+			w.writeLines(f, 2, 2)
+		}
+		w.writeString("  }\n")
+	}
+	w.writeString("}\n")
+
+	for _, r := range nonEmpty {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, r.Type, forEachResourceName)
+			resource.SetAttributeTraversal("for_each", traversal("local", r.Local))
+			resource.AppendNewline()
+			for _, attr := range r.Attributes {
+				resource.SetAttributeTraversal(attr, traversal("each", "value", attr))
+			}
+		})
+	}
+}
+
+// writeForEachFile writes a file of for_each resources under a comment.
+func writeForEachFile(outputDir, name, comment string, names *resourceNames, resources ...*forEachResource) error {
+	w, err := createHCLFile(outputDir, name)
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, comment)
+	})
+	writeForEachResources(w, names, resources...)
+	return w.close()
+}
+
+// optionalString is value, or null when it is empty.
+func optionalString(value string) hclwrite.Tokens {
+	if value == "" {
+		return nil
+	}
+	return hclwrite.TokensForValue(cty.StringVal(value))
+}
+
+func generateUsersFileForEach(outputDir string, users []provider.User, names *resourceNames) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	comment := "Users"
+	r := newForEachResource("prism_user", "users", "username", "email", "first_name", "last_name", "enabled", "attributes")
+	for _, user := range users {
+		attributeValues, skipped := userAttributes(user)
+		if len(skipped) > 0 {
+			comment += fmt.Sprintf("\n\n%s: %s", user.Username, skippedAttributesComment(skipped))
+		}
+		r.add(names.users[user.Username], func() map[string]hclwrite.Tokens {
+			values := map[string]hclwrite.Tokens{
+				"username":   hclwrite.TokensForValue(cty.StringVal(user.Username)),
+				"email":      hclwrite.TokensForValue(cty.StringVal(user.Email)),
+				"first_name": optionalString(user.FirstName),
+				"last_name":  optionalString(user.LastName),
+				"enabled":    hclwrite.TokensForValue(cty.BoolVal(user.Enabled)),
+			}
+
+			attributes := make(map[string]hclwrite.Tokens)
+			for k, v := range attributeValues {
+				attributes[k] = hclwrite.TokensForValue(cty.StringVal(v))
+			}
+			if len(attributes) > 0 {
+				values["attributes"] = tokensForMap(attributes)
+			}
+			return values
+		})
+	}
+
+	return writeForEachFile(outputDir, "users.tf", comment, names, r)
+}
+
+func generateGroupsFileForEach(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	groups := newForEachResource("prism_group", "groups", "name", "description", "path")
+	for _, group := range data.Groups {
+		groups.add(names.groups[group.Name], func() map[string]hclwrite.Tokens {
+			return map[string]hclwrite.Tokens{
+				"name":        hclwrite.TokensForValue(cty.StringVal(group.Name)),
+				"description": optionalString(group.Description),
+				"path":        optionalString(group.Path),
+			}
+		})
+	}
+
+	memberships := newForEachResource("prism_group_membership", "group_memberships", "group_name", "usernames")
+	if names.individualMembers() {
+		memberships = newForEachResource("prism_group_member", "group_members", "group_name", "username")
+	}
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		members := data.GroupMemberships[groupName]
+		if len(members) == 0 {
+			continue
+		}
+
+		if names.individualMembers() {
+			for _, member := range members {
+				memberships.add(names.members[groupMember{Group: groupName, Username: member}], func() map[string]hclwrite.Tokens {
+					return map[string]hclwrite.Tokens{
+						"group_name": names.reference("prism_group", names.groups[groupName], "name", groupName),
+						"username":   names.reference("prism_user", names.users[member], "username", member),
+					}
+				})
+			}
+			continue
+		}
+
+		memberships.add(names.memberships[groupName], func() map[string]hclwrite.Tokens {
+			var usernames []hclwrite.Tokens
+			for _, member := range members {
+				usernames = append(usernames, names.reference("prism_user", names.users[member], "username", member))
+			}
+			return map[string]hclwrite.Tokens{
+				"group_name": names.reference("prism_group", names.groups[groupName], "name", groupName),
+				"usernames":  tokensForMultilineTuple(usernames),
+			}
+		})
+	}
+
+	if len(groups.Keys) == 0 && len(memberships.Keys) == 0 {
+		return nil
+	}
+
+	heading, explanation := membershipsComment(names)
+	if len(memberships.Keys) > 0 {
+		heading += "\n\n" + explanation
+	}
+	return writeForEachFile(outputDir, "groups.tf", "Groups and "+heading, names, groups, memberships)
+}
+
+func generateAssignmentsFileForEach(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	if len(data.PermissionSetAssignments) == 0 {
+		return nil
+	}
+
+	r := newForEachResource("prism_permission_set_assignment", "permission_set_assignments",
+		"permission_set_id", "principal_type", "principal_id", "account_ids")
+	for _, assignment := range groupAssignments(data) {
+		r.add(assignment.Name, func() map[string]hclwrite.Tokens {
+			var accounts []hclwrite.Tokens
+			for _, accountID := range assignment.AccountIDs {
+				accounts = append(accounts, names.accountID(accountID))
+			}
+
+			return map[string]hclwrite.Tokens{
+				"permission_set_id": names.permissionSetID(assignment.PermissionSetID),
+				"principal_type":    hclwrite.TokensForValue(cty.StringVal(assignment.PrincipalType)),
+				"principal_id":      names.principalID(assignment.PrincipalType, assignment.PrincipalID),
+				"account_ids":       tokensForMultilineTuple(accounts),
+			}
+		})
+	}
+
+	w, err := createHCLFile(outputDir, "assignments.tf")
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Permission Set Assignments")
+		appendAssignmentLocals(body, names)
+	})
+	writeForEachResources(w, names, r)
+	return w.close()
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+func TestGenerateFiles_ForEach(t *testing.T) {
+	// Same fixture as the flat import-blocks golden
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: StyleForEach}, testInfrastructure())
+	assertGoldenDir(t, dir, "foreach")
+}
+
+func TestGenerateFiles_ForEachScript(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatScript, Style: StyleForEach}, testInfrastructure())
+
+	content, err := os.ReadFile(filepath.Join(dir, "import.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`terraform import prism_aws_account.production '111111111111'`,
+		`terraform import 'prism_user.this["o_brien"]' 'o'\''brien'`,
+		`terraform import 'prism_group_membership.this["engineering_members"]' 'Engineering'`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected import.sh to contain %q:\n%s", want, content)
+		}
+	}
+}
+
+// The foreach style imports the same resources with the same IDs, at the
+// instance keyed by the flat style's resource name.
+func TestImportSections_StylesMatch(t *testing.T) {
+	data := sortedData(testInfrastructure())
+	flat := newResourceNames(data, nil)
+	forEach := newResourceNames(data, nil)
+	forEach.style = StyleForEach
+
+	flatSections, forEachSections := importSections(data, flat), importSections(data, forEach)
+	if len(flatSections) != len(forEachSections) {
+		t.Fatalf("expected %d sections, got %d", len(flatSections), len(forEachSections))
+	}
+	for i, section := range flatSections {
+		for j, target := range section.Targets {
+			got := forEachSections[i].Targets[j]
+			resourceType, name, _ := strings.Cut(target.Address, ".")
+			want := target.Address
+			if forEachTypes[resourceType] {
+				want = resourceType + `.this["` + name + `"]`
+			}
+			if got.Address != want || got.ID != target.ID {
+				t.Errorf("expected %s with ID %q, got %s with ID %q", want, target.ID, got.Address, got.ID)
+			}
+		}
+	}
+}
+
+func TestGenerateFiles_ForEachLocalKeys(t *testing.T) {
+	data := hostileInfrastructure()
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: StyleForEach}, data)
+	names := newResourceNames(sortedData(data), nil)
+
+	tests := map[string]struct {
+		local string
+		want  map[string]string
+	}{
+		"users.tf":  {"users", names.users},
+		"groups.tf": {"groups", names.groups},
+	}
+	for file, tt := range tests {
+		got := localKeys(t, filepath.Join(dir, file), tt.local)
+		var want []string
+		for _, name := range tt.want {
+			want = append(want, name)
+		}
+		sort.Strings(want)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected local.%s keys %v, got %v", file, tt.local, want, got)
+		}
+	}
+}
+
+// localKeys returns the sorted keys of the named local map in path.
+func localKeys(t *testing.T, path, local string) []string {
+	t.Helper()
+
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		t.Fatalf("parsing %s: %s", path, diags)
+	}
+	content, _, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "locals"}},
+	})
+	if diags.HasErrors() || len(content.Blocks) != 1 {
+		t.Fatalf("expected one locals block in %s: %s", path, diags)
+	}
+	attrs, diags := content.Blocks[0].Body.JustAttributes()
+	if diags.HasErrors() || attrs[local] == nil {
+		t.Fatalf("expected local %s in %s: %s", local, path, diags)
+	}
+
+	pairs, diags := hcl.ExprMap(attrs[local].Expr)
+	if diags.HasErrors() {
+		t.Fatalf("local %s in %s is not a map: %s", local, path, diags)
+	}
+	var keys []string
+	for _, pair := range pairs {
+		keys = append(keys, hcl.ExprAsKeyword(pair.Key))
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,687 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedData returns a copy of data with every resource list sorted by its
+// natural key, so that the generated files don't depend on API order.
+func sortedData(data *InfrastructureData) *InfrastructureData {
+	sorted := *data
+
+	sorted.AWSAccounts = append([]provider.AWSAccount(nil), data.AWSAccounts...)
+	sort.SliceStable(sorted.AWSAccounts, func(i, j int) bool {
+		a, b := sorted.AWSAccounts[i], sorted.AWSAccounts[j]
+		if a.AccountName != b.AccountName {
+			return a.AccountName < b.AccountName
+		}
+		return a.AccountID < b.AccountID
+	})
+
+	sorted.PermissionSets = append([]provider.PermissionSet(nil), data.PermissionSets...)
+	sort.SliceStable(sorted.PermissionSets, func(i, j int) bool {
+		a, b := sorted.PermissionSets[i], sorted.PermissionSets[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	sorted.Users = append([]provider.User(nil), data.Users...)
+	sort.SliceStable(sorted.Users, func(i, j int) bool {
+		return sorted.Users[i].Username < sorted.Users[j].Username
+	})
+
+	sorted.Groups = append([]provider.Group(nil), data.Groups...)
+	sort.SliceStable(sorted.Groups, func(i, j int) bool {
+		return sorted.Groups[i].Name < sorted.Groups[j].Name
+	})
+
+	sorted.IdentityProviders = append([]provider.IdentityProvider(nil), data.IdentityProviders...)
+	sortIdentityProviders(sorted.IdentityProviders)
+
+	return &sorted
+}
+
+// Generator writes the Terraform files for the fetched resources.
+type Generator struct {
+	Config Config
+}
+
+// Generate writes every file into g.Config.OutputDir, which must exist,
+// checking that each parses as it is written. With -output-layout=by-group-path,
+// variables are extracted again for each directory and the given ones are
+// ignored.
+func (g *Generator) Generate(data *InfrastructureData, variables *Variables) error {
+	config := g.Config
+	data = sortedData(data)
+	names := generatorNames(config, data)
+
+	if config.OutputLayout == OutputLayoutByGroupPath {
+		return generateByGroupPath(config, data, names)
+	}
+	if err := generateConfiguration(config, data, variables, names); err != nil {
+		return err
+	}
+	if config.OutputLayout == OutputLayoutSingle {
+		return mergeIntoMainFile(config.OutputDir)
+	}
+	return nil
+}
+
+// generatorNames names the resources in sorted data the way Generator does
+// with config.
+func generatorNames(config Config, data *InfrastructureData) *resourceNames {
+	names := newResourceNames(data, config.NameMap)
+	names.style = config.Style
+	names.membershipStyle = config.MembershipStyle
+	names.providerAlias = config.ProviderAlias
+	if config.Layout == LayoutModule {
+		// Resources are imported through the module from the root module
+		names.module = config.ModuleName
+	}
+	return names
+}
+
+// generateConfiguration writes the files of one configuration for sorted
+// data into config.OutputDir, one file per resource type.
+func generateConfiguration(config Config, data *InfrastructureData, variables *Variables, names *resourceNames) error {
+	outputDir := config.OutputDir
+	names.accountVariables = variables.AccountIDs
+	names.permissionSetLocals = variables.PermissionSets
+	names.userLocals = variables.Users
+	names.groupLocals = variables.Groups
+	forEach := config.Style == StyleForEach
+	module := config.Layout == LayoutModule
+
+	// Generate provider.tf, or versions.tf for a module, which gets its
+	// provider configuration from the caller
+	if module {
+		if err := generateVersionsFile(outputDir, config); err != nil {
+			return err
+		}
+	} else if err := generateProviderFile(outputDir, config); err != nil {
+		return err
+	}
+
+	// Generate variables.tf
+	if err := generateVariablesFile(outputDir, config, variables); err != nil {
+		return err
+	}
+
+	// Generate terraform.tfvars; a module's inputs are set by its caller
+	if !module {
+		if err := generateTFVarsFile(outputDir, config, variables); err != nil {
+			return err
+		}
+	}
+	if config.WriteGitignore {
+		if err := writeGitignore(outputDir); err != nil {
+			return err
+		}
+	}
+
+	// Generate AWS accounts
+	if err := generateAWSAccountsFile(outputDir, data.AWSAccounts, names); err != nil {
+		return err
+	}
+
+	// Generate permission sets
+	if err := generatePermissionSetsFile(outputDir, data.PermissionSets, names); err != nil {
+		return err
+	}
+
+	// Generate users, groups and assignments, as one resource block each
+	// or as for_each resources
+	generateUsers, generateGroups, generateAssignments := generateUsersFile, generateGroupsFile, generateAssignmentsFile
+	if forEach {
+		generateUsers, generateGroups, generateAssignments = generateUsersFileForEach, generateGroupsFileForEach, generateAssignmentsFileForEach
+	}
+
+	if err := generateUsers(outputDir, data.Users, names); err != nil {
+		return err
+	}
+
+	if err := generateGroups(outputDir, data, names); err != nil {
+		return err
+	}
+
+	if err := generateAssignments(outputDir, data, names); err != nil {
+		return err
+	}
+
+	// Generate identity providers
+	if err := generateIdentityProvidersFile(outputDir, data.IdentityProviders, names); err != nil {
+		return err
+	}
+
+	// Generate import blocks or the import scripts
+	if config.ImportFormat == ImportFormatScript {
+		if err := generateImportScript(outputDir, data, names); err != nil {
+			return err
+		}
+		if err := generateImportPowerShell(outputDir, data, names); err != nil {
+			return err
+		}
+	} else if err := generateImportBlocks(outputDir, importBlocksFile(config), data, names); err != nil {
+		return err
+	}
+
+	// Generate the module outputs
+	if module {
+		return generateOutputsFile(outputDir, data, names)
+	}
+	return nil
+}
+
+// appendTerraformBlock appends the terraform block with the Terraform
+// version, provider requirements and, with -backend, a backend skeleton.
+func appendTerraformBlock(body *hclwrite.Body, config Config) {
+	// Import blocks need Terraform 1.5
+	requiredVersion := ">= 1.5"
+	if config.ImportFormat == ImportFormatScript {
+		requiredVersion = ">= 1.0"
+	}
+	if config.TerraformVersion != "" {
+		requiredVersion = config.TerraformVersion
+	}
+
+	terraform := body.AppendNewBlock("terraform", nil).Body()
+	terraform.SetAttributeValue("required_version", cty.StringVal(requiredVersion))
+	terraform.AppendNewline()
+
+	prism := map[string]hclwrite.Tokens{
+		"source": hclwrite.TokensForValue(cty.StringVal("CloudKeeper-Inc/prism")),
+	}
+	if config.ProviderVersion != "" {
+		prism["version"] = hclwrite.TokensForValue(cty.StringVal(config.ProviderVersion))
+	}
+	requiredProviders := terraform.AppendNewBlock("required_providers", nil).Body()
+	requiredProviders.SetAttributeRaw("prism", tokensForMap(prism))
+
+	if config.Backend != "" {
+		terraform.AppendNewline()
+		terraform.AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte(backendComment(config.Backend))},
+		})
+	}
+}
+
+func generateProviderFile(outputDir string, config Config) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	appendTerraformBlock(body, config)
+	body.AppendNewline()
+
+	prism := body.AppendNewBlock("provider", []string{"prism"}).Body()
+	if config.ProviderAlias != "" {
+		prism.SetAttributeValue("alias", cty.StringVal(config.ProviderAlias))
+	}
+	prism.SetAttributeTraversal("prism_subdomain", traversal("var", "prism_subdomain"))
+	prism.SetAttributeTraversal("api_token", traversal("var", "prism_api_token"))
+	prism.SetAttributeTraversal("base_url", traversal("var", "prism_base_url"))
+	if config.Port != provider.DefaultPort {
+		prism.SetAttributeValue("port", cty.NumberIntVal(config.Port))
+	}
+
+	return writeHCLFile(outputDir, "provider.tf", f)
+}
+
+func generateVariablesFile(outputDir string, config Config, variables *Variables) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	appendComment(body, "Provider Configuration Variables")
+	body.AppendNewline()
+	subdomain := body.AppendNewBlock("variable", []string{"prism_subdomain"}).Body()
+	subdomain.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+	subdomain.SetAttributeValue("description", cty.StringVal("Prism subdomain"))
+	subdomain.SetAttributeValue("sensitive", cty.False)
+	body.AppendNewline()
+
+	token := body.AppendNewBlock("variable", []string{"prism_api_token"}).Body()
+	token.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+	token.SetAttributeValue("description", cty.StringVal("Prism API token"))
+	token.SetAttributeValue("sensitive", cty.True)
+	// Null leaves the provider to read PRISM_API_TOKEN
+	token.SetAttributeValue("default", cty.NullVal(cty.String))
+
+	// A module's caller configures the provider, including its base URL
+	if config.Layout != LayoutModule {
+		body.AppendNewline()
+		baseURL := body.AppendNewBlock("variable", []string{"prism_base_url"}).Body()
+		baseURL.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+		baseURL.SetAttributeValue("description", cty.StringVal("Prism base URL, without port"))
+	}
+
+	// Add account ID variables if any
+	if len(variables.AccountIDs) > 0 {
+		body.AppendNewline()
+		appendComment(body, "AWS Account ID Variables")
+
+		// Sort for consistent output
+		var accountIDs []string
+		for accountID := range variables.AccountIDs {
+			accountIDs = append(accountIDs, accountID)
+		}
+		sort.Strings(accountIDs)
+
+		for _, accountID := range accountIDs {
+			body.AppendNewline()
+			account := body.AppendNewBlock("variable", []string{variables.AccountIDs[accountID]}).Body()
+			account.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+			account.SetAttributeValue("description", cty.StringVal(fmt.Sprintf("AWS Account ID (%s)", accountID)))
+			if config.Layout == LayoutModule {
+				// There's no terraform.tfvars to set it in
+				account.SetAttributeValue("default", cty.StringVal(accountID))
+			}
+		}
+	}
+
+	if len(variables.IdentityProviders) > 0 {
+		body.AppendNewline()
+		appendComment(body, "Identity Provider Variables")
+
+		for _, v := range variables.IdentityProviders {
+			body.AppendNewline()
+			variable := body.AppendNewBlock("variable", []string{v.Name}).Body()
+			variable.SetAttributeRaw("type", hclwrite.TokensForIdentifier("string"))
+			variable.SetAttributeValue("description", cty.StringVal(v.Description))
+			variable.SetAttributeValue("sensitive", cty.BoolVal(v.Sensitive))
+		}
+	}
+
+	return writeHCLFile(outputDir, "variables.tf", f)
+}
+
+func generateTFVarsFile(outputDir string, config Config, variables *Variables) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	subdomain := config.PrismSubdomain
+	if subdomain == "" {
+		subdomain = "YOUR_SUBDOMAIN_HERE"
+	}
+
+	appendComment(body, "Provider Configuration")
+	body.SetAttributeValue("prism_subdomain", cty.StringVal(subdomain))
+	body.SetAttributeValue("prism_base_url", cty.StringVal(config.BaseURL))
+
+	// This file is easily committed, so the token is only shown as an example
+	body.AppendNewline()
+	appendComment(body, "The API token is read from the PRISM_API_TOKEN environment variable. Only")
+	appendComment(body, "set it here if this file is kept out of version control (see -write-gitignore).")
+	appendComment(body, `prism_api_token = "YOUR_API_TOKEN_HERE"`)
+
+	if len(variables.AccountIDs) > 0 {
+		body.AppendNewline()
+		appendComment(body, "AWS Account IDs")
+
+		var accountIDs []string
+		for accountID := range variables.AccountIDs {
+			accountIDs = append(accountIDs, accountID)
+		}
+		sort.Strings(accountIDs)
+
+		for _, accountID := range accountIDs {
+			body.SetAttributeValue(variables.AccountIDs[accountID], cty.StringVal(accountID))
+		}
+	}
+
+	if len(variables.IdentityProviders) > 0 {
+		body.AppendNewline()
+		appendComment(body, "Identity Providers")
+
+		for _, v := range variables.IdentityProviders {
+			body.SetAttributeValue(v.Name, cty.StringVal("YOUR_"+strings.ToUpper(v.Name)+"_HERE"))
+		}
+	}
+
+	return writeHCLFile(outputDir, "terraform.tfvars", f)
+}
+
+// writeGitignore adds terraform.tfvars to the .gitignore in outputDir,
+// creating it if needed and keeping any entries it already has.
+func writeGitignore(outputDir string) error {
+	path := filepath.Join(outputDir, ".gitignore")
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "terraform.tfvars" {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	content = append(content, "# Variable values, which may include the API token\nterraform.tfvars\n"...)
+	return os.WriteFile(path, content, 0644)
+}
+
+func generateAWSAccountsFile(outputDir string, accounts []provider.AWSAccount, names *resourceNames) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	w, err := createHCLFile(outputDir, "aws_accounts.tf")
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "AWS Accounts")
+	})
+
+	for _, acc := range accounts {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, "prism_aws_account", names.accounts[acc.AccountID])
+			if variable, ok := names.accountVariables[acc.AccountID]; ok {
+				resource.SetAttributeTraversal("account_id", traversal("var", variable))
+			} else {
+				resource.SetAttributeValue("account_id", cty.StringVal(acc.AccountID))
+			}
+			resource.SetAttributeValue("account_name", cty.StringVal(acc.AccountName))
+			if acc.Region != "" {
+				resource.SetAttributeValue("region", cty.StringVal(acc.Region))
+			}
+		})
+	}
+
+	return w.close()
+}
+
+func generatePermissionSetsFile(outputDir string, permSets []provider.PermissionSet, names *resourceNames) error {
+	if len(permSets) == 0 {
+		return nil
+	}
+
+	w, err := createHCLFile(outputDir, "permission_sets.tf")
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Permission Sets")
+	})
+
+	for _, ps := range permSets {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, "prism_permission_set", names.permissionSets[ps.ID])
+			resource.SetAttributeValue("name", cty.StringVal(ps.Name))
+
+			if ps.Description != "" {
+				resource.SetAttributeValue("description", cty.StringVal(ps.Description))
+			}
+
+			if ps.SessionDuration != "" {
+				resource.SetAttributeValue("session_duration", cty.StringVal(ps.SessionDuration))
+			}
+
+			if len(ps.ManagedPolicies) > 0 {
+				resource.AppendNewline()
+				var policies []hclwrite.Tokens
+				for _, policy := range ps.ManagedPolicies {
+					policies = append(policies, hclwrite.TokensForValue(cty.StringVal(policy)))
+				}
+				resource.SetAttributeRaw("managed_policies", tokensForMultilineTuple(policies))
+			}
+
+			if len(ps.InlinePolicies) > 0 {
+				resource.AppendNewline()
+				policies := make(map[string]hclwrite.Tokens, len(ps.InlinePolicies))
+				for name, policy := range ps.InlinePolicies {
+					// Indent JSON as a heredoc; keep anything else as a plain string.
+					// json.Indent only changes whitespace, so key order, numbers
+					// and escapes stay exactly as Prism returned them.
+					var prettyJSON bytes.Buffer
+					if err := json.Indent(&prettyJSON, []byte(strings.TrimSpace(policy)), "", "  "); err == nil {
+						policies[name] = tokensForHeredoc(prettyJSON.String(), "    ")
+						continue
+					}
+					policies[name] = hclwrite.TokensForValue(cty.StringVal(policy))
+				}
+				resource.SetAttributeRaw("inline_policies", tokensForMap(policies))
+			}
+		})
+	}
+
+	return w.close()
+}
+
+func generateUsersFile(outputDir string, users []provider.User, names *resourceNames) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	w, err := createHCLFile(outputDir, "users.tf")
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Users")
+	})
+
+	for _, user := range users {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, "prism_user", names.users[user.Username])
+			resource.SetAttributeValue("username", cty.StringVal(user.Username))
+			resource.SetAttributeValue("email", cty.StringVal(user.Email))
+
+			if user.FirstName != "" {
+				resource.SetAttributeValue("first_name", cty.StringVal(user.FirstName))
+			}
+
+			if user.LastName != "" {
+				resource.SetAttributeValue("last_name", cty.StringVal(user.LastName))
+			}
+
+			resource.SetAttributeValue("enabled", cty.BoolVal(user.Enabled))
+
+			values, skipped := userAttributes(user)
+			attributes := make(map[string]hclwrite.Tokens)
+			for k, v := range values {
+				attributes[k] = hclwrite.TokensForValue(cty.StringVal(v))
+			}
+			if len(attributes) > 0 || len(skipped) > 0 {
+				resource.AppendNewline()
+			}
+			if len(skipped) > 0 {
+				appendComment(resource, skippedAttributesComment(skipped))
+			}
+			if len(attributes) > 0 {
+				resource.SetAttributeRaw("attributes", tokensForMap(attributes))
+			}
+		})
+	}
+
+	return w.close()
+}
+
+// userAttributes returns the first value of each of user's attributes, as
+// the provider keeps them, and the sorted keys left out because prism_user
+// rejects them, such as the reserved LDAP_ID.
+func userAttributes(user provider.User) (map[string]string, []string) {
+	attributes := make(map[string]string)
+	var skipped []string
+	for k, values := range user.Attributes {
+		if provider.CheckUserAttributeKey(k) != nil {
+			skipped = append(skipped, k)
+			continue
+		}
+		if len(values) > 0 {
+			attributes[k] = values[0]
+		}
+	}
+	sort.Strings(skipped)
+	return attributes, skipped
+}
+
+// skippedAttributesComment explains why the attribute keys in skipped are
+// missing from the generated configuration.
+func skippedAttributesComment(skipped []string) string {
+	return fmt.Sprintf("Attributes reserved by Prism or with invalid keys are left out: %s", strings.Join(skipped, ", "))
+}
+
+func generateGroupsFile(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	if len(data.Groups) == 0 && len(data.GroupMemberships) == 0 {
+		return nil
+	}
+
+	w, err := createHCLFile(outputDir, "groups.tf")
+	if err != nil {
+		return err
+	}
+
+	if len(data.Groups) > 0 {
+		w.append(func(body *hclwrite.Body) {
+			appendComment(body, "Groups")
+		})
+	}
+
+	for _, group := range data.Groups {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, "prism_group", names.groups[group.Name])
+			resource.SetAttributeValue("name", cty.StringVal(group.Name))
+
+			if group.Description != "" {
+				resource.SetAttributeValue("description", cty.StringVal(group.Description))
+			}
+
+			if group.Path != "" {
+				resource.SetAttributeValue("path", cty.StringVal(group.Path))
+			}
+		})
+	}
+
+	// Group memberships
+	if len(data.GroupMemberships) > 0 {
+		w.append(func(body *hclwrite.Body) {
+			if len(data.Groups) > 0 {
+				body.AppendNewline()
+			}
+			heading, explanation := membershipsComment(names)
+			appendComment(body, heading+"\n\n"+explanation)
+		})
+
+		for _, groupName := range sortedKeys(data.GroupMemberships) {
+			members := data.GroupMemberships[groupName]
+			if len(members) == 0 {
+				continue
+			}
+
+			if names.individualMembers() {
+				for _, member := range members {
+					w.append(func(body *hclwrite.Body) {
+						body.AppendNewline()
+						resource := names.appendResource(body, "prism_group_member", names.members[groupMember{Group: groupName, Username: member}])
+						resource.SetAttributeRaw("group_name", names.reference("prism_group", names.groups[groupName], "name", groupName))
+						resource.SetAttributeRaw("username", names.reference("prism_user", names.users[member], "username", member))
+					})
+				}
+				continue
+			}
+
+			w.append(func(body *hclwrite.Body) {
+				body.AppendNewline()
+				resource := names.appendResource(body, "prism_group_membership", names.memberships[groupName])
+				resource.SetAttributeRaw("group_name", names.reference("prism_group", names.groups[groupName], "name", groupName))
+
+				var usernames []hclwrite.Tokens
+				for _, member := range members {
+					usernames = append(usernames, names.reference("prism_user", names.users[member], "username", member))
+				}
+				resource.SetAttributeRaw("usernames", tokensForMultilineTuple(usernames))
+			})
+		}
+	}
+
+	return w.close()
+}
+
+func generateAssignmentsFile(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	if len(data.PermissionSetAssignments) == 0 {
+		return nil
+	}
+
+	w, err := createHCLFile(outputDir, "assignments.tf")
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Permission Set Assignments")
+		appendAssignmentLocals(body, names)
+	})
+
+	// Assignments are grouped by permission set + principal
+	for _, assignment := range groupAssignments(data) {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			resource := names.appendResource(body, "prism_permission_set_assignment", assignment.Name)
+
+			// Refer to the permission set and principal resources when generated
+			resource.SetAttributeRaw("permission_set_id", names.permissionSetID(assignment.PermissionSetID))
+			resource.SetAttributeValue("principal_type", cty.StringVal(assignment.PrincipalType))
+			resource.SetAttributeRaw("principal_id", names.principalID(assignment.PrincipalType, assignment.PrincipalID))
+
+			var accounts []hclwrite.Tokens
+			for _, accountID := range assignment.AccountIDs {
+				accounts = append(accounts, names.accountID(accountID))
+			}
+			resource.SetAttributeRaw("account_ids", tokensForMultilineTuple(accounts))
+		})
+	}
+
+	return w.close()
+}
+
+// appendAssignmentLocals appends the locals block of the permission sets and
+// principals that assignments refer to through locals with -use-locals, if
+// there are any.
+func appendAssignmentLocals(body *hclwrite.Body, names *resourceNames) {
+	values := make(map[string]hclwrite.Tokens)
+	for id, local := range names.permissionSetLocals {
+		values[local] = names.reference("prism_permission_set", names.permissionSets[id], "id", id)
+	}
+	for username, local := range names.userLocals {
+		values[local] = names.reference("prism_user", names.users[username], "username", username)
+	}
+	for groupName, local := range names.groupLocals {
+		values[local] = names.reference("prism_group", names.groups[groupName], "name", groupName)
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	body.AppendNewline()
+	locals := body.AppendNewBlock("locals", nil).Body()
+	for _, local := range sortedKeys(values) {
+		locals.SetAttributeRaw(local, values[local])
+	}
+}
@@ -0,0 +1,441 @@
+package importer
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// testInfrastructure returns a small tenant with one of each resource type.
+// Maps hold a single entry so that generation order is stable.
+func testInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		AWSAccounts: []provider.AWSAccount{
+			{ID: "acct-1", AccountID: "111111111111", AccountName: "Production", Region: "us-east-1"},
+		},
+		PermissionSets: []provider.PermissionSet{
+			{ID: "ps-1", Name: "ReadOnly", Description: "Read-only access", SessionDuration: "PT4H",
+				ManagedPolicies: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"}},
+		},
+		Users: []provider.User{
+			{ID: "user-1", Username: "alice", Email: "alice@example.com", FirstName: "Alice", Enabled: true},
+			{ID: "user-2", Username: "o'brien", Email: "obrien@example.com", Enabled: false},
+		},
+		Groups: []provider.Group{
+			{ID: "group-1", Name: "Engineering", Description: "All engineers"},
+		},
+		GroupMemberships: map[string][]string{
+			"Engineering": {"alice", "o'brien"},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "assign-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "111111111111"},
+			{ID: "assign-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "222222222222"},
+		},
+	}
+}
+
+// generateTestFiles runs a Generator for data into a temporary directory
+// and returns that directory.
+func generateTestFiles(t *testing.T, config Config, data *InfrastructureData) string {
+	t.Helper()
+
+	config.OutputDir = t.TempDir()
+	if config.BaseURL == "" {
+		config.BaseURL = "https://prism.cloudkeeper.com"
+	}
+	if config.Port == 0 {
+		config.Port = provider.DefaultPort
+	}
+	generator := &Generator{Config: config}
+	if err := generator.Generate(data, VariableExtractor{NameMap: config.NameMap, Accounts: config.VarAccounts}.Extract(data)); err != nil {
+		t.Fatalf("generating files: %s", err)
+	}
+	return config.OutputDir
+}
+
+// assertGoldenDir compares every file in dir with testdata/golden. Run the
+// tests with -update to rewrite the golden files.
+func assertGoldenDir(t *testing.T, dir, golden string) {
+	t.Helper()
+
+	golden = filepath.Join("testdata", golden)
+	if *update {
+		if err := os.RemoveAll(golden); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.CopyFS(golden, os.DirFS(dir)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := listFiles(t, dir)
+	want := listFiles(t, golden)
+	if len(got) != len(want) {
+		t.Errorf("expected files %v, got %v", want, got)
+	}
+	for _, name := range want {
+		wantContent, err := os.ReadFile(filepath.Join(golden, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotContent, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("expected %s to be generated: %s", name, err)
+			continue
+		}
+		if string(gotContent) != string(wantContent) {
+			t.Errorf("%s differs from %s:\n--- got ---\n%s\n--- want ---\n%s", name, golden, gotContent, wantContent)
+		}
+	}
+}
+
+// assertGoldenFile compares the file at path with testdata/golden. Run the
+// tests with -update to rewrite the golden file.
+func assertGoldenFile(t *testing.T, path, golden string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden = filepath.Join("testdata", golden)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from %s:\n--- got ---\n%s\n--- want ---\n%s", filepath.Base(path), golden, got, want)
+	}
+}
+
+// listFiles returns the sorted relative paths of all files under dir.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, rel)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("listing %s: %s", dir, err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestGenerateFiles_BaseURLAndPort(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks, BaseURL: "https://prism.internal", Port: 9443}
+	dir := generateTestFiles(t, config, testInfrastructure())
+
+	providerTF, err := os.ReadFile(filepath.Join(dir, "provider.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"base_url        = var.prism_base_url", "port            = 9443"} {
+		if !strings.Contains(string(providerTF), want) {
+			t.Errorf("expected provider.tf to contain %q:\n%s", want, providerTF)
+		}
+	}
+
+	tfvars, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `prism_base_url  = "https://prism.internal"`; !strings.Contains(string(tfvars), want) {
+		t.Errorf("expected terraform.tfvars to contain %q:\n%s", want, tfvars)
+	}
+
+	// The default port is left to the provider
+	dir = generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, testInfrastructure())
+	providerTF, err = os.ReadFile(filepath.Join(dir, "provider.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(providerTF), "port") {
+		t.Errorf("expected no port with the default port:\n%s", providerTF)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, Layout: LayoutRoot, ModuleName: "prism", OutputLayout: OutputLayoutSplit, VarAccounts: VarAccountsReused, MembershipStyle: MembershipStyleAuthoritative}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+
+	tests := map[string]struct {
+		change  func(c *Config)
+		wantErr string
+	}{
+		"import format": {func(c *Config) { c.ImportFormat = "hcl" }, `-import-format must be "blocks" or "script", got "hcl"`},
+		"style":         {func(c *Config) { c.Style = "nested" }, `-style must be "flat" or "foreach", got "nested"`},
+		"layout":        {func(c *Config) { c.Layout = "workspace" }, `-layout must be "root" or "module", got "workspace"`},
+		"module name":   {func(c *Config) { c.ModuleName = "1prism" }, `-module-name must be a valid Terraform name, got "1prism"`},
+		"backend":       {func(c *Config) { c.Backend = "azurerm" }, `-backend must be "s3", "gcs" or "local", got "azurerm"`},
+		"module backend": {func(c *Config) { c.Layout, c.Backend = LayoutModule, BackendS3 },
+			"-backend can't be used with -layout=module; the backend belongs in the root module"},
+		"module gitignore": {func(c *Config) { c.Layout, c.WriteGitignore = LayoutModule, true },
+			"-write-gitignore can't be used with -layout=module, which doesn't write terraform.tfvars"},
+		"var accounts":     {func(c *Config) { c.VarAccounts = "some" }, `-var-accounts must be "all", "reused" or "none", got "some"`},
+		"membership style": {func(c *Config) { c.MembershipStyle = "additive" }, `-membership-style must be "authoritative" or "individual", got "additive"`},
+		"output layout":    {func(c *Config) { c.OutputLayout = "nested" }, `-output-layout must be "split", "single" or "by-group-path", got "nested"`},
+		"module by group path": {func(c *Config) { c.Layout, c.OutputLayout = LayoutModule, OutputLayoutByGroupPath },
+			"-output-layout=by-group-path can't be used with -layout=module; it writes a root configuration per directory"},
+		"provider alias": {func(c *Config) { c.ProviderAlias = "1acme" }, `the provider alias must be a valid Terraform name, got "1acme"`},
+		"module provider alias": {func(c *Config) { c.Layout, c.ProviderAlias = LayoutModule, "acme" },
+			"a provider alias can't be used with -layout=module; the caller passes the module its provider"},
+	}
+	for name, tt := range tests {
+		config := valid
+		tt.change(&config)
+		if err := config.Validate(); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: expected error %q, got %v", name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestGenerateFiles_ProviderAlias(t *testing.T) {
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			config := Config{ImportFormat: ImportFormatBlocks, Style: style, PrismSubdomain: "acme", ProviderAlias: "acme"}
+			dir := generateTestFiles(t, config, testInfrastructure())
+			for _, name := range []string{"provider.tf", "permission_sets.tf", "groups.tf"} {
+				assertGoldenFile(t, filepath.Join(dir, name), filepath.Join("provider-alias", style, name))
+			}
+			assertReferencesResolve(t, dir)
+		})
+	}
+}
+
+// TestGenerator_EveryFileHasGolden makes sure that each file the Generator
+// can write is covered by at least one golden file.
+func TestGenerator_EveryFileHasGolden(t *testing.T) {
+	files := []string{
+		"provider.tf", "versions.tf", "variables.tf", "terraform.tfvars", "outputs.tf",
+		"aws_accounts.tf", "permission_sets.tf", "users.tf", "groups.tf", "assignments.tf", "identity_providers.tf",
+		"imports.tf", RootImportsFile, "import.sh", "import.ps1", "main.tf",
+	}
+
+	covered := map[string]bool{}
+	for _, file := range listFiles(t, "testdata") {
+		covered[filepath.Base(file)] = true
+	}
+	for _, file := range files {
+		if !covered[file] {
+			t.Errorf("no golden file covers %s", file)
+		}
+	}
+}
+
+func TestGenerateFiles_TFVars(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "placeholder", config: Config{ImportFormat: ImportFormatBlocks}},
+		{name: "subdomain", config: Config{ImportFormat: ImportFormatBlocks, PrismSubdomain: "acme"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := generateTestFiles(t, tt.config, testInfrastructure())
+			assertGoldenFile(t, filepath.Join(dir, "terraform.tfvars"), filepath.Join("tfvars", tt.name+".tfvars"))
+
+			content, err := os.ReadFile(filepath.Join(dir, "terraform.tfvars"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.Split(string(content), "\n") {
+				if strings.HasPrefix(line, "prism_api_token") {
+					t.Errorf("expected the API token to be commented out, got %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFiles_WriteGitignore(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks, PrismSubdomain: "acme"}
+	dir := generateTestFiles(t, config, testInfrastructure())
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); !os.IsNotExist(err) {
+		t.Errorf("expected no .gitignore without WriteGitignore, got %v", err)
+	}
+
+	config.WriteGitignore = true
+	dir = generateTestFiles(t, config, testInfrastructure())
+	assertGoldenFile(t, filepath.Join(dir, ".gitignore"), filepath.Join("gitignore", "new.gitignore"))
+}
+
+func TestWriteGitignore_KeepsExistingEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".gitignore")
+	if err := os.WriteFile(path, []byte(".terraform/\n*.tfstate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running twice must not add the entry twice
+	for range 2 {
+		if err := writeGitignore(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertGoldenFile(t, path, filepath.Join("gitignore", "existing.gitignore"))
+}
+
+// largeInfrastructure returns a tenant with 2,000 users who each have 25
+// permission sets in one account: 50,000 assignments.
+func largeInfrastructure() *InfrastructureData {
+	data := &InfrastructureData{GroupMemberships: make(map[string][]string)}
+	for i := range 20 {
+		data.AWSAccounts = append(data.AWSAccounts, provider.AWSAccount{
+			ID: fmt.Sprintf("acct-%d", i), AccountID: fmt.Sprintf("%012d", 100000000000+i), AccountName: fmt.Sprintf("Account %d", i),
+		})
+	}
+	for i := range 25 {
+		data.PermissionSets = append(data.PermissionSets, provider.PermissionSet{ID: fmt.Sprintf("ps-%d", i), Name: fmt.Sprintf("Set%d", i)})
+	}
+	for i := range 2000 {
+		username := fmt.Sprintf("user%04d", i)
+		data.Users = append(data.Users, provider.User{ID: fmt.Sprintf("user-%d", i), Username: username, Email: username + "@example.com", Enabled: true})
+		for j := range 25 {
+			data.PermissionSetAssignments = append(data.PermissionSetAssignments, provider.PermissionSetAssignment{
+				ID: fmt.Sprintf("assign-%d-%d", i, j), PermissionSetID: fmt.Sprintf("ps-%d", j), PrincipalType: "USER",
+				Username: username, AccountID: data.AWSAccounts[(i+j)%20].AccountID,
+			})
+		}
+	}
+	return data
+}
+
+// peakHeap runs f and returns roughly the most heap it had in use at once,
+// over what was in use before.
+func peakHeap(f func()) uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	base := stats.HeapAlloc
+
+	var peak atomic.Uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > base && stats.HeapAlloc-base > peak.Load() {
+				peak.Store(stats.HeapAlloc - base)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	f()
+	close(done)
+	<-sampled
+	return peak.Load()
+}
+
+func TestGenerateFiles_LargeTenant(t *testing.T) {
+	if testing.Short() || raceEnabled {
+		// The race detector makes it take minutes, and generation runs on
+		// one goroutine
+		t.Skip("generates 50,000 assignments")
+	}
+	// Building the files in memory took over 1 GB
+	const ceiling = 128 << 20
+
+	data := largeInfrastructure()
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			var dir string
+			peak := peakHeap(func() {
+				dir = generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: style, OutputLayout: OutputLayoutSplit}, data)
+			})
+			t.Logf("heap peaked at %d MB", peak>>20)
+			if peak > ceiling {
+				t.Errorf("expected generation to stay under %d MB of heap, peaked at %d MB", ceiling>>20, peak>>20)
+			}
+
+			// Formatting the whole file in one go, as files used to be
+			// written, changes nothing
+			content, err := os.ReadFile(filepath.Join(dir, "assignments.tf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatted := hclwrite.Format(content); !bytes.Equal(formatted, content) {
+				t.Error("assignments.tf isn't formatted as a whole")
+			}
+
+			targets := ImportDirectories(Config{Style: style}, data)[0].Targets
+			if len(targets) != 20+25+2000+50000 {
+				t.Errorf("expected every resource to be imported, got %d", len(targets))
+			}
+			imports, err := os.ReadFile(filepath.Join(dir, "imports.tf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := bytes.Count(imports, []byte("import {")); n != len(targets) {
+				t.Errorf("expected %d import blocks, got %d", len(targets), n)
+			}
+		})
+	}
+}
+
+// Reserved attributes, such as the LDAP_ID of a federated user, would fail
+// prism_user validation, so they're left out with a comment.
+func TestGenerateFiles_SkipsReservedUserAttributes(t *testing.T) {
+	data := &InfrastructureData{
+		Users: []provider.User{
+			{ID: "user-1", Username: "alice", Email: "alice@example.com", Enabled: true,
+				Attributes: map[string][]string{"department": {"Platform"}, "LDAP_ID": {"a1b2"}, "LDAP_ENTRY_DN": {"uid=alice"}}},
+		},
+	}
+
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: style}, data)
+		content, err := os.ReadFile(filepath.Join(dir, "users.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "left out: LDAP_ENTRY_DN, LDAP_ID") {
+			t.Errorf("%s: expected a comment listing the skipped attributes:\n%s", style, content)
+		}
+		if strings.Contains(string(content), `"a1b2"`) || strings.Contains(string(content), "uid=alice") {
+			t.Errorf("%s: expected the reserved attributes to be left out:\n%s", style, content)
+		}
+		if !strings.Contains(string(content), `"Platform"`) {
+			t.Errorf("%s: expected the department attribute to be kept:\n%s", style, content)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// appendComment appends text to body as "# " comment lines, with a bare
+// "#" for each empty line.
+func appendComment(body *hclwrite.Body, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		comment := "#"
+		if line != "" {
+			comment += " " + line
+		}
+		body.AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte(comment + "\n")},
+		})
+	}
+}
+
+// traversal builds a reference such as prism_user.alice.username.
+func traversal(root string, attrs ...string) hcl.Traversal {
+	t := hcl.Traversal{hcl.TraverseRoot{Name: root}}
+	for _, attr := range attrs {
+		t = append(t, hcl.TraverseAttr{Name: attr})
+	}
+	return t
+}
+
+// tokensForMultilineTuple renders elems as a list with one element per line.
+func tokensForMultilineTuple(elems []hclwrite.Tokens) hclwrite.Tokens {
+	toks := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for _, elem := range elems {
+		toks = append(toks, elem...)
+		toks = append(toks,
+			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
+			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		)
+	}
+	return append(toks, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+}
+
+// tokensForMap renders values as an object with one attribute per line,
+// sorted by key. Keys that aren't valid identifiers are quoted.
+func tokensForMap(values map[string]hclwrite.Tokens) hclwrite.Tokens {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return tokensForObject(keys, values)
+}
+
+// tokensForObject is tokensForMap with the keys in the given order.
+func tokensForObject(keys []string, values map[string]hclwrite.Tokens) hclwrite.Tokens {
+	toks := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for _, k := range keys {
+		if hclsyntax.ValidIdentifier(k) {
+			toks = append(toks, hclwrite.TokensForIdentifier(k)...)
+		} else {
+			toks = append(toks, hclwrite.TokensForValue(cty.StringVal(k))...)
+		}
+		toks = append(toks, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("=")})
+		toks = append(toks, values[k]...)
+		toks = append(toks, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	return append(toks, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")})
+}
+
+// tokensForHeredoc renders content as an indented <<-EOT heredoc, escaping
+// template sequences such as the ${aws:username} policy variable so that
+// the value is taken literally. indent is the indentation of the attribute
+// holding the heredoc; the content is indented one level deeper.
+func tokensForHeredoc(content, indent string) hclwrite.Tokens {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	delimiter := heredocDelimiter(lines)
+
+	toks := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<-" + delimiter + "\n")},
+	}
+	for _, line := range lines {
+		if line != "" {
+			line = indent + "  " + escapeTemplate(line)
+		}
+		toks = append(toks, &hclwrite.Token{Type: hclsyntax.TokenStringLit, Bytes: []byte(line + "\n")})
+	}
+	return append(toks, &hclwrite.Token{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(indent + delimiter)})
+}
+
+// heredocDelimiter returns EOT, or EOT_1, EOT_2, ... if a line of the
+// content would otherwise end the heredoc early.
+func heredocDelimiter(lines []string) string {
+	delimiter := "EOT"
+	for n := 1; ; n++ {
+		collides := false
+		for _, line := range lines {
+			if strings.TrimSpace(line) == delimiter {
+				collides = true
+				break
+			}
+		}
+		if !collides {
+			return delimiter
+		}
+		delimiter = fmt.Sprintf("EOT_%d", n)
+	}
+}
+
+// escapeTemplate escapes the ${ and %{ sequences that start template
+// interpolations and directives.
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// writeHCLFile formats f like terraform fmt, checks that it parses and
+// writes it to outputDir.
+func writeHCLFile(outputDir, name string, f *hclwrite.File) error {
+	w, err := createHCLFile(outputDir, name)
+	if err != nil {
+		return err
+	}
+	w.write(f)
+	return w.close()
+}
+
+// hclFileWriter writes an HCL file a piece at a time, usually one block.
+// Each piece is formatted like terraform fmt and checked to parse on its
+// own, so that a file with tens of thousands of resources is never held in
+// memory, or lexed, as a whole. Formatting only aligns attributes within a
+// block, so the file is the same as formatting it in one go. The first
+// error is kept and returned by close.
+type hclFileWriter struct {
+	name  string
+	file  *os.File
+	w     *bufio.Writer
+	lines int // lines written so far, to place parse errors in the file
+	err   error
+}
+
+// createHCLFile creates the file name in outputDir.
+func createHCLFile(outputDir, name string) (*hclFileWriter, error) {
+	file, err := os.Create(filepath.Join(outputDir, name))
+	if err != nil {
+		return nil, err
+	}
+	return &hclFileWriter{name: name, file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// append builds a piece of the file with build and writes it.
+func (w *hclFileWriter) append(build func(body *hclwrite.Body)) {
+	f := hclwrite.NewEmptyFile()
+	build(f.Body())
+	w.write(f)
+}
+
+// write formats f, checks that it parses and writes it.
+func (w *hclFileWriter) write(f *hclwrite.File) {
+	w.writeLines(f, 0, 0)
+}
+
+// writeLines is write without the first head and last tail lines of the
+// formatted f, for a piece that only formats right inside the blocks it is
+// nested in, such as one entry of a large map: f holds the entry with the
+// blocks around it, which are checked with it but written separately.
+func (w *hclFileWriter) writeLines(f *hclwrite.File, head, tail int) {
+	if w.err != nil {
+		return
+	}
+	content := hclwrite.Format(f.Bytes())
+	start := hcl.Pos{Line: w.lines + 1 - head, Column: 1}
+	if _, diags := hclsyntax.ParseConfig(content, w.name, start); diags.HasErrors() {
+		w.err = fmt.Errorf("generated invalid HCL: %w", diags)
+		return
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if n := len(lines); n > 0 && len(lines[n-1]) == 0 {
+		// The formatted piece ends with a newline
+		lines = lines[:n-1]
+	}
+	if head+tail > len(lines) {
+		w.err = fmt.Errorf("%s: can't remove %d lines from a piece of %d", w.name, head+tail, len(lines))
+		return
+	}
+	for _, line := range lines[head : len(lines)-tail] {
+		w.writeString(string(line))
+	}
+}
+
+// writeString writes s as it is, for the lines around pieces written with
+// writeLines.
+func (w *hclFileWriter) writeString(s string) {
+	if w.err != nil {
+		return
+	}
+	w.lines += strings.Count(s, "\n")
+	_, w.err = w.w.WriteString(s)
+}
+
+// close flushes and closes the file, returning the first error.
+func (w *hclFileWriter) close() error {
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	if err := w.file.Close(); w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
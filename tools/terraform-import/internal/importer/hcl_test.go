@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// hostilePolicy uses IAM policy variables, which look like HCL templates.
+const hostilePolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:*",` +
+	`"Resource":"arn:aws:s3:::home/${aws:username}/*","Condition":{"StringLike":{"s3:prefix":["%{x}","EOT"]}}}]}`
+
+// hostileInfrastructure returns resources whose values contain quotes,
+// backslashes, template sequences, newlines and non-ASCII characters.
+func hostileInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		PermissionSets: []provider.PermissionSet{
+			{ID: "ps-1", Name: `Dev "Admin"`, Description: "Line one\nLine two with ${var.x} and %{if}",
+				InlinePolicies: map[string]string{
+					"home-dir":  hostilePolicy,
+					"not_json":  `C:\path\${x}`,
+					"ünïcødé 🚀": `{"a":"b"}`,
+				}},
+		},
+		Users: []provider.User{
+			{ID: "user-1", Username: "zoë", Email: `"quoted"@example.com`, LastName: `Back\slash`, Enabled: true,
+				Attributes: map[string][]string{"cost-center": {"${cc}"}, "team": {"Ünïcode"}}},
+		},
+		Groups: []provider.Group{
+			{ID: "group-1", Name: "ops/${env}", Description: `Tab	and "quote"`},
+		},
+		GroupMemberships: map[string][]string{
+			"ops/${env}": {"zoë"},
+		},
+	}
+}
+
+func TestGenerateFiles_HostileNames(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, hostileInfrastructure())
+	assertGoldenDir(t, dir, "hostile")
+
+	// Values must come back exactly as they were fetched
+	attrs := parseAttributes(t, filepath.Join(dir, "users.tf"), "resource")
+	if got := attrs["email"].AsString(); got != `"quoted"@example.com` {
+		t.Errorf("expected email to round-trip, got %q", got)
+	}
+	if got := attrs["attributes"].GetAttr("cost-center").AsString(); got != "${cc}" {
+		t.Errorf("expected attribute to round-trip, got %q", got)
+	}
+
+	attrs = parseAttributes(t, filepath.Join(dir, "groups.tf"), "resource")
+	if got := attrs["name"].AsString(); got != "ops/${env}" {
+		t.Errorf("expected group name to round-trip, got %q", got)
+	}
+}
+
+func TestGenerateFiles_InlinePolicyRoundTrip(t *testing.T) {
+	data := hostileInfrastructure()
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, data)
+
+	attrs := parseAttributes(t, filepath.Join(dir, "permission_sets.tf"), "resource")
+	if got := attrs["description"].AsString(); got != data.PermissionSets[0].Description {
+		t.Errorf("expected description to round-trip, got %q", got)
+	}
+
+	policies := attrs["inline_policies"].AsValueMap()
+	for name, want := range data.PermissionSets[0].InlinePolicies {
+		value, ok := policies[name]
+		if !ok {
+			t.Errorf("expected inline policy %q", name)
+			continue
+		}
+		got := value.AsString()
+
+		var wantJSON, gotJSON interface{}
+		if json.Unmarshal([]byte(want), &wantJSON) != nil {
+			// Not JSON, so it is written as a plain string
+			if got != want {
+				t.Errorf("policy %q: expected %q, got %q", name, want, got)
+			}
+			continue
+		}
+		if err := json.Unmarshal([]byte(got), &gotJSON); err != nil {
+			t.Errorf("policy %q is no longer valid JSON: %s\n%s", name, err, got)
+			continue
+		}
+		if !reflect.DeepEqual(gotJSON, wantJSON) {
+			t.Errorf("policy %q changed:\n got: %s\nwant: %s", name, got, want)
+		}
+	}
+}
+
+func TestHCLFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := createHCLFile(dir, "ok.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Things")
+	})
+	for _, name := range []string{"a", "bb"} {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			block := body.AppendNewBlock("resource", []string{"thing", name}).Body()
+			block.SetAttributeValue("name", cty.StringVal(name))
+			block.SetAttributeValue("enabled", cty.True)
+		})
+	}
+	// A map entry, formatted inside the block and attribute around it
+	w.writeString("\nlocals {\n  things = {\n")
+	entry := hclwrite.NewEmptyFile()
+	entry.Body().AppendNewBlock("locals", nil).Body().SetAttributeRaw("things", tokensForObject([]string{"c"},
+		map[string]hclwrite.Tokens{"c": hclwrite.TokensForValue(cty.StringVal("c"))}))
+	w.writeLines(entry, 2, 2)
+	w.writeString("  }\n}\n")
+	if err := w.close(); err != nil {
+		t.Fatalf("expected valid pieces to be written, got %s", err)
+	}
+
+	// The pieces make the same file as formatting it in one go
+	content, err := os.ReadFile(filepath.Join(dir, "ok.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Things
+
+resource "thing" "a" {
+  name    = "a"
+  enabled = true
+}
+
+resource "thing" "bb" {
+  name    = "bb"
+  enabled = true
+}
+
+locals {
+  things = {
+    c = "c"
+  }
+}
+`
+	if string(content) != want {
+		t.Errorf("unexpected file:\n--- got ---\n%s\n--- want ---\n%s", content, want)
+	}
+	if formatted := hclwrite.Format(content); string(formatted) != string(content) {
+		t.Errorf("expected the file to be formatted already, got:\n%s", formatted)
+	}
+
+	w, err = createHCLFile(dir, "broken.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Broken")
+	})
+	w.append(func(body *hclwrite.Body) {
+		body.AppendNewline()
+		block := body.AppendNewBlock("resource", []string{"thing", "broken"}).Body()
+		block.SetAttributeRaw("name", hclwrite.Tokens{{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)}})
+	})
+	err = w.close()
+	if err == nil || !strings.Contains(err.Error(), "generated invalid HCL") || !strings.Contains(err.Error(), "broken.tf:4") {
+		t.Errorf("expected an invalid HCL error at broken.tf:4, got %v", err)
+	}
+}
+
+// parseAttributes parses path and evaluates the attributes of its first
+// block of the given type. Only literal attributes are returned.
+func parseAttributes(t *testing.T, path, blockType string) map[string]cty.Value {
+	t.Helper()
+
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		t.Fatalf("parsing %s: %s", path, diags)
+	}
+	content, _, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: blockType, LabelNames: []string{"type", "name"}}},
+	})
+	if diags.HasErrors() || len(content.Blocks) == 0 {
+		t.Fatalf("expected a %s block in %s: %s", blockType, path, diags)
+	}
+	attrs, diags := content.Blocks[0].Body.JustAttributes()
+	if diags.HasErrors() {
+		t.Fatalf("reading attributes in %s: %s", path, diags)
+	}
+
+	values := make(map[string]cty.Value)
+	for name, attr := range attrs {
+		if len(attr.Expr.Variables()) > 0 {
+			continue
+		}
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			t.Fatalf("evaluating %s in %s: %s", name, path, diags)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+func TestGenerateFiles_AdversarialPolicies(t *testing.T) {
+	policies := map[string]string{
+		"policy_variables": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":"arn:aws:s3:::b/${aws:username}/*"}]}`,
+		"eot":              `{"a":"EOT","EOT":["EOT","EOT_1"],"b":"\nEOT\n"}`,
+		"html":             `{"Condition":{"StringEquals":{"aws:RequestTag/x":"a&b<c>"}}}`,
+		"numbers":          `{"big":12345678901234567890,"float":1.0,"exp":1e400,"neg":-0.0}`,
+		"escapes":          `{"s":"line\nbreak \"quoted\" \\ back é \t tab  "}`,
+		"templates":        `{"a":"$${x} %{if} %%{y} ${z} $$${w}"}`,
+		"key_order":        `{"z":1,"a":2,"m":{"y":3,"b":4}}`,
+		"duplicate_keys":   `{"a":1,"a":2}`,
+		"pretty_crlf":      "  {\r\n    \"a\": 1\r\n  }\r\n",
+		"empty_object":     `{}`,
+		"empty_array":      `[]`,
+		"not_json":         `{"a": EOT`,
+		"not_json_lines":   "EOT\n${x}\n  EOT",
+	}
+	data := &InfrastructureData{
+		PermissionSets: []provider.PermissionSet{{ID: "ps-1", Name: "Adversarial", InlinePolicies: policies}},
+	}
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, data)
+
+	// terraform fmt would leave every file alone
+	for _, name := range listFiles(t, dir) {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".tf" && string(hclwrite.Format(content)) != string(content) {
+			t.Errorf("%s changes when formatted:\n%s", name, content)
+		}
+	}
+
+	got := parseAttributes(t, filepath.Join(dir, "permission_sets.tf"), "resource")["inline_policies"].AsValueMap()
+	for name, want := range policies {
+		value, ok := got[name]
+		if !ok {
+			t.Errorf("expected inline policy %q", name)
+			continue
+		}
+
+		// Only whitespace may change, so the compacted documents are identical
+		var wantCompact, gotCompact bytes.Buffer
+		if err := json.Compact(&wantCompact, []byte(want)); err != nil {
+			if value.AsString() != want {
+				t.Errorf("policy %q: expected the invalid JSON unchanged, got %q", name, value.AsString())
+			}
+			continue
+		}
+		if err := json.Compact(&gotCompact, []byte(value.AsString())); err != nil {
+			t.Errorf("policy %q is no longer valid JSON: %s\n%s", name, err, value.AsString())
+			continue
+		}
+		if gotCompact.String() != wantCompact.String() {
+			t.Errorf("policy %q changed:\n got: %s\nwant: %s", name, gotCompact.String(), wantCompact.String())
+		}
+	}
+}
+
+func TestTokensForHeredoc_Delimiter(t *testing.T) {
+	tests := []struct {
+		content   string
+		delimiter string
+	}{
+		{"plain\n", "EOT"},
+		{"EOTx\nxEOT\n", "EOT"},
+		{"EOT\n", "EOT_1"},
+		{"a\n  EOT  \nEOT_1\nb\n", "EOT_2"},
+	}
+
+	for _, tt := range tests {
+		f := hclwrite.NewEmptyFile()
+		f.Body().SetAttributeRaw("value", tokensForHeredoc(tt.content, ""))
+		src := hclwrite.Format(f.Bytes())
+
+		if want := "<<-" + tt.delimiter + "\n"; !strings.Contains(string(src), want) {
+			t.Errorf("%q: expected delimiter %s, got:\n%s", tt.content, tt.delimiter, src)
+		}
+
+		file, diags := hclparse.NewParser().ParseHCL(src, "test.tf")
+		if diags.HasErrors() {
+			t.Errorf("%q: generated invalid HCL: %s\n%s", tt.content, diags, src)
+			continue
+		}
+		attrs, _ := file.Body.JustAttributes()
+		value, diags := attrs["value"].Expr.Value(nil)
+		if diags.HasErrors() {
+			t.Errorf("%q: %s", tt.content, diags)
+			continue
+		}
+		if value.AsString() != tt.content {
+			t.Errorf("expected %q, got %q", tt.content, value.AsString())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// configKey is one field of an identity provider's config JSON.
+type configKey struct {
+	Name     string
+	Required bool
+}
+
+// identityProviderConfigKeys lists the config fields the provider sends for
+// each identity provider type (see CreateIdentityProvider).
+var identityProviderConfigKeys = map[string][]configKey{
+	"google": {
+		{Name: "clientId", Required: true},
+		{Name: "clientSecret", Required: true},
+		{Name: "hostedDomain"},
+	},
+	"microsoft": {
+		{Name: "clientId", Required: true},
+		{Name: "clientSecret", Required: true},
+		{Name: "tenantId", Required: true},
+	},
+	"keycloak": {
+		{Name: "clientId", Required: true},
+		{Name: "clientSecret", Required: true},
+		{Name: "authServerUrl", Required: true},
+		{Name: "targetRealm", Required: true},
+	},
+	"custom": {
+		{Name: "clientId", Required: true},
+		{Name: "clientSecret", Required: true},
+		{Name: "authServerUrl", Required: true},
+		{Name: "authorizationUrl", Required: true},
+		{Name: "tokenUrl", Required: true},
+		{Name: "userInfoUrl", Required: true},
+		{Name: "logoutUrl"},
+		{Name: "issuer", Required: true},
+		{Name: "providerName"},
+	},
+}
+
+// configVariable is a variable generated for an identity provider config
+// field whose value can't be exported.
+type configVariable struct {
+	Name        string
+	Description string
+	Sensitive   bool
+}
+
+// isSecretConfigKey reports whether a config field holds a secret. The API
+// masks secrets, so they are always replaced by a variable.
+func isSecretConfigKey(key string) bool {
+	return strings.Contains(strings.ToLower(key), "secret")
+}
+
+// identityProviderConfig returns the config fields of idp in a stable
+// order, with the value of each field that can be exported. Fields without
+// a value need a variable.
+func identityProviderConfig(idp provider.IdentityProvider) (keys []configKey, values map[string]string) {
+	values = make(map[string]string)
+	for key, value := range idp.Config {
+		if s, ok := value.(string); ok && s != "" && !isSecretConfigKey(key) {
+			values[key] = s
+		}
+	}
+
+	keys, ok := identityProviderConfigKeys[idp.Type]
+	if !ok {
+		// Unknown type: keep whatever the API returned
+		for _, key := range sortedKeys(idp.Config) {
+			keys = append(keys, configKey{Name: key, Required: true})
+		}
+	}
+	return keys, values
+}
+
+// identityProviderVariables lists the variables needed by the config of
+// each identity provider: every secret, and every required field the API
+// didn't return.
+func identityProviderVariables(idps []provider.IdentityProvider, names *resourceNames) []configVariable {
+	var variables []configVariable
+	for _, idp := range idps {
+		keys, values := identityProviderConfig(idp)
+		for _, key := range keys {
+			if _, ok := values[key.Name]; ok || !key.Required {
+				continue
+			}
+			variables = append(variables, configVariable{
+				Name:        configVariableName(names.identityProviders[idp.Alias], key.Name),
+				Description: fmt.Sprintf("%s of the %s identity provider", key.Name, idp.Alias),
+				Sensitive:   isSecretConfigKey(key.Name),
+			})
+		}
+	}
+	return variables
+}
+
+// configVariableName names the variable for a config field, e.g.
+// google_client_secret for the clientSecret of prism_identity_provider.google.
+func configVariableName(resourceName, key string) string {
+	var snake strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			snake.WriteByte('_')
+		}
+		snake.WriteRune(unicode.ToLower(r))
+	}
+	return toResourceName(resourceName+"_"+snake.String(), "identity_provider")
+}
+
+func generateIdentityProvidersFile(outputDir string, idps []provider.IdentityProvider, names *resourceNames) error {
+	if len(idps) == 0 {
+		return nil
+	}
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	appendComment(body, "Identity Providers")
+	appendComment(body, "Secrets can't be exported; set them in terraform.tfvars.")
+
+	for _, idp := range idps {
+		name := names.identityProviders[idp.Alias]
+
+		body.AppendNewline()
+		resource := names.appendResource(body, "prism_identity_provider", name)
+		resource.SetAttributeValue("type", cty.StringVal(idp.Type))
+
+		if idp.DisplayName != "" {
+			resource.SetAttributeValue("display_name", cty.StringVal(idp.DisplayName))
+		}
+
+		resource.SetAttributeValue("enabled", cty.BoolVal(idp.Enabled))
+
+		keys, values := identityProviderConfig(idp)
+		config := make(map[string]hclwrite.Tokens)
+		for _, key := range keys {
+			if value, ok := values[key.Name]; ok {
+				config[key.Name] = hclwrite.TokensForValue(cty.StringVal(value))
+			} else if key.Required {
+				config[key.Name] = hclwrite.TokensForTraversal(traversal("var", configVariableName(name, key.Name)))
+			}
+		}
+		resource.AppendNewline()
+		resource.SetAttributeRaw("config", hclwrite.TokensForFunctionCall("jsonencode", tokensForMap(config)))
+	}
+
+	return writeHCLFile(outputDir, "identity_providers.tf", f)
+}
+
+// sortIdentityProviders sorts identity providers by type and alias.
+func sortIdentityProviders(idps []provider.IdentityProvider) {
+	sort.SliceStable(idps, func(i, j int) bool {
+		if idps[i].Type != idps[j].Type {
+			return idps[i].Type < idps[j].Type
+		}
+		return idps[i].Alias < idps[j].Alias
+	})
+}
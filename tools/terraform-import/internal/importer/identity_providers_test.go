@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// identityProviderInfrastructure returns a Google provider whose config the
+// API returns in full, apart from the masked secret, and a Microsoft
+// provider whose tenant ID the API leaves out.
+func identityProviderInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		GroupMemberships: map[string][]string{},
+		IdentityProviders: []provider.IdentityProvider{
+			{Type: "microsoft", Alias: "microsoft", DisplayName: "Sign in with Microsoft", Enabled: false,
+				Config: map[string]interface{}{"clientId": "azure-client-id", "syncMode": "FORCE"}},
+			{Type: "google", Alias: "google", DisplayName: "Sign in with Google", Enabled: true,
+				Config: map[string]interface{}{"clientId": "google-client-id", "clientSecret": "**********", "hostedDomain": "example.com"}},
+		},
+	}
+}
+
+func TestGenerateFiles_IdentityProviders(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, identityProviderInfrastructure())
+	assertGoldenDir(t, dir, "identity-providers")
+
+	attrs := parseAttributes(t, filepath.Join(dir, "identity_providers.tf"), "resource")
+	if got := attrs["type"].AsString(); got != "google" {
+		t.Errorf("expected the google provider first, got %q", got)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "identity_providers.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "**********") {
+		t.Errorf("expected the masked secret to be replaced by a variable:\n%s", content)
+	}
+}
+
+func TestIdentityProviderVariables(t *testing.T) {
+	data := sortedData(identityProviderInfrastructure())
+	got := identityProviderVariables(data.IdentityProviders, newResourceNames(data, nil))
+
+	want := []configVariable{
+		{Name: "google_client_secret", Description: "clientSecret of the google identity provider", Sensitive: true},
+		{Name: "microsoft_client_secret", Description: "clientSecret of the microsoft identity provider", Sensitive: true},
+		{Name: "microsoft_tenant_id", Description: "tenantId of the microsoft identity provider", Sensitive: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected variables %+v, got %+v", want, got)
+	}
+}
+
+func TestImportSections_IdentityProviders(t *testing.T) {
+	data := sortedData(identityProviderInfrastructure())
+
+	got := map[string]string{}
+	for _, section := range importSections(data, newResourceNames(data, nil)) {
+		for _, target := range section.Targets {
+			got[target.Address] = target.ID
+		}
+	}
+
+	want := map[string]string{
+		"prism_identity_provider.google":    "google:google",
+		"prism_identity_provider.microsoft": "microsoft:microsoft",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected import targets %v, got %v", want, got)
+	}
+}
+
+func TestFetcher_IdentityProviders(t *testing.T) {
+	client, _ := newFakePrism(t, identityProviderInfrastructure())
+	kinds, err := ParseResourceKinds(kindIdentityProviders, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := (&Fetcher{Client: client, Kinds: kinds, Concurrency: 5}).Fetch()
+	if err != nil {
+		t.Fatalf("fetching data: %s", err)
+	}
+	if len(data.IdentityProviders) != 2 {
+		t.Fatalf("expected 2 identity providers, got %+v", data.IdentityProviders)
+	}
+
+	data, err = (&Fetcher{Client: client, Kinds: allResourceKinds(t), Concurrency: 5}).Fetch()
+	if err != nil {
+		t.Fatalf("fetching data: %s", err)
+	}
+	kinds, _ = ParseResourceKinds("", kindIdentityProviders)
+	if got := FilterData(data, kinds).IdentityProviders; got != nil {
+		t.Errorf("expected excluded identity providers to be dropped, got %+v", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Package importer generates Terraform configuration for the resources of
+// an existing Prism tenant: Fetcher reads them from the API,
+// VariableExtractor picks the values that become variables, and Generator
+// writes the .tf files and the imports that adopt the resources.
+package importer
+
+import (
+	"fmt"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Config holds the settings that shape the generated files.
+type Config struct {
+	OutputDir    string
+	BaseURL      string
+	Port         int64
+	ImportFormat string
+	Style        string
+	Layout       string
+	ModuleName   string
+
+	// PrismSubdomain is written to terraform.tfvars; empty leaves a
+	// placeholder
+	PrismSubdomain string
+
+	// NameMap sets the resource names of some objects; nil derives them all
+	NameMap *NameMap
+
+	// VarAccounts is the -var-accounts mode and UseLocals the -use-locals
+	// flag, used to extract the variables of each directory with
+	// -output-layout=by-group-path
+	VarAccounts string
+	UseLocals   bool
+
+	// MembershipStyle is the -membership-style; anything but individual
+	// generates one authoritative membership per group
+	MembershipStyle string
+
+	// OutputLayout is the -output-layout; anything but single and
+	// by-group-path writes one file per resource type
+	OutputLayout string
+
+	// Settings for the terraform block; empty leaves the defaults
+	ProviderVersion  string
+	TerraformVersion string
+	Backend          string
+
+	// WriteGitignore adds terraform.tfvars to .gitignore in OutputDir
+	WriteGitignore bool
+
+	// ProviderAlias configures the prism provider under this alias, which
+	// every resource then names; empty uses the default configuration
+	ProviderAlias string
+}
+
+// Validate reports the first setting that Generator can't generate.
+func (c Config) Validate() error {
+	if c.ImportFormat != ImportFormatBlocks && c.ImportFormat != ImportFormatScript {
+		return fmt.Errorf("-import-format must be %q or %q, got %q", ImportFormatBlocks, ImportFormatScript, c.ImportFormat)
+	}
+	if c.Style != StyleFlat && c.Style != StyleForEach {
+		return fmt.Errorf("-style must be %q or %q, got %q", StyleFlat, StyleForEach, c.Style)
+	}
+	if c.MembershipStyle != MembershipStyleAuthoritative && c.MembershipStyle != MembershipStyleIndividual {
+		return fmt.Errorf("-membership-style must be %q or %q, got %q", MembershipStyleAuthoritative, MembershipStyleIndividual, c.MembershipStyle)
+	}
+	if c.Layout != LayoutRoot && c.Layout != LayoutModule {
+		return fmt.Errorf("-layout must be %q or %q, got %q", LayoutRoot, LayoutModule, c.Layout)
+	}
+	if c.VarAccounts != VarAccountsAll && c.VarAccounts != VarAccountsReused && c.VarAccounts != VarAccountsNone {
+		return fmt.Errorf("-var-accounts must be %q, %q or %q, got %q", VarAccountsAll, VarAccountsReused, VarAccountsNone, c.VarAccounts)
+	}
+	if c.OutputLayout != OutputLayoutSplit && c.OutputLayout != OutputLayoutSingle && c.OutputLayout != OutputLayoutByGroupPath {
+		return fmt.Errorf("-output-layout must be %q, %q or %q, got %q", OutputLayoutSplit, OutputLayoutSingle, OutputLayoutByGroupPath, c.OutputLayout)
+	}
+	if c.OutputLayout == OutputLayoutByGroupPath && c.Layout == LayoutModule {
+		return fmt.Errorf("-output-layout=by-group-path can't be used with -layout=module; it writes a root configuration per directory")
+	}
+	if !hclsyntax.ValidIdentifier(c.ModuleName) {
+		return fmt.Errorf("-module-name must be a valid Terraform name, got %q", c.ModuleName)
+	}
+	if c.Backend != "" && backendSkeletons[c.Backend] == nil {
+		return fmt.Errorf("-backend must be %q, %q or %q, got %q", BackendS3, BackendGCS, BackendLocal, c.Backend)
+	}
+	if c.Backend != "" && c.Layout == LayoutModule {
+		return fmt.Errorf("-backend can't be used with -layout=module; the backend belongs in the root module")
+	}
+	if c.WriteGitignore && c.Layout == LayoutModule {
+		return fmt.Errorf("-write-gitignore can't be used with -layout=module, which doesn't write terraform.tfvars")
+	}
+	if c.ProviderAlias != "" && !hclsyntax.ValidIdentifier(c.ProviderAlias) {
+		return fmt.Errorf("the provider alias must be a valid Terraform name, got %q", c.ProviderAlias)
+	}
+	if c.ProviderAlias != "" && c.Layout == LayoutModule {
+		return fmt.Errorf("a provider alias can't be used with -layout=module; the caller passes the module its provider")
+	}
+	return nil
+}
+
+// InfrastructureData holds the resources fetched from a Prism tenant.
+type InfrastructureData struct {
+	AWSAccounts              []provider.AWSAccount              `json:"aws_accounts"`
+	PermissionSets           []provider.PermissionSet           `json:"permission_sets"`
+	Users                    []provider.User                    `json:"users"`
+	Groups                   []provider.Group                   `json:"groups"`
+	GroupMemberships         map[string][]string                `json:"group_memberships"` // group name -> usernames
+	PermissionSetAssignments []provider.PermissionSetAssignment `json:"permission_set_assignments"`
+	IdentityProviders        []provider.IdentityProvider        `json:"identity_providers"`
+
+	// namingPermissionSets are permission sets generated in another
+	// directory with -output-layout=by-group-path, which assignments are
+	// still named after
+	namingPermissionSets []provider.PermissionSet
+}
+
+// Variables maps exported values to the Terraform variables, and with
+// -use-locals the locals, that hold them.
+type Variables struct {
+	AccountIDs map[string]string // account_id -> variable name
+
+	// Locals that assignments refer to permission sets and principals
+	// through, with -use-locals
+	PermissionSets map[string]string // permission set id -> local name
+	Users          map[string]string // username -> local name
+	Groups         map[string]string // group name -> local name
+
+	// Identity provider config fields that can't be exported, in
+	// generation order
+	IdentityProviders []configVariable
+}
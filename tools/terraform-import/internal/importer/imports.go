@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/compositeid"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Supported values of the -import-format flag
+const (
+	ImportFormatBlocks = "blocks"
+	ImportFormatScript = "script"
+)
+
+// ImportTarget is one resource to import: its Terraform address and the ID
+// that the resource's ImportState accepts, along with the IDs of the Prism
+// objects it adopts, for reports.
+type ImportTarget struct {
+	Type     string
+	Address  string
+	ID       string
+	PrismIDs []string
+}
+
+// importSection groups the import targets of one resource type. Title
+// heads the section and Noun is used in progress messages.
+type importSection struct {
+	Title   string
+	Noun    string
+	Targets []ImportTarget
+}
+
+// importSections lists every generated resource with its import ID. Both
+// the import blocks and the import script are generated from this list so
+// that they can't drift apart.
+func importSections(data *InfrastructureData, names *resourceNames) []importSection {
+	var sections []importSection
+
+	// Memberships have no Prism ID of their own; they are reported with
+	// their group's and, for members, their user's
+	userIDs := make(map[string]string, len(data.Users))
+	for _, user := range data.Users {
+		userIDs[user.Username] = user.ID
+	}
+	groupIDs := make(map[string]string, len(data.Groups))
+	for _, group := range data.Groups {
+		groupIDs[group.Name] = group.ID
+	}
+
+	// AWS accounts import by AWS account ID
+	if len(data.AWSAccounts) > 0 {
+		section := importSection{Title: "AWS Accounts", Noun: "AWS accounts"}
+		for _, acc := range data.AWSAccounts {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_aws_account",
+				Address:  names.address("prism_aws_account", names.accounts[acc.AccountID]),
+				ID:       acc.AccountID,
+				PrismIDs: prismIDs(acc.ID),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	// Permission sets import by ID
+	if len(data.PermissionSets) > 0 {
+		section := importSection{Title: "Permission Sets", Noun: "permission sets"}
+		for _, ps := range data.PermissionSets {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_permission_set",
+				Address:  names.address("prism_permission_set", names.permissionSets[ps.ID]),
+				ID:       ps.ID,
+				PrismIDs: prismIDs(ps.ID),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	// Users import by username
+	if len(data.Users) > 0 {
+		section := importSection{Title: "Users", Noun: "users"}
+		for _, user := range data.Users {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_user",
+				Address:  names.address("prism_user", names.users[user.Username]),
+				ID:       user.Username,
+				PrismIDs: prismIDs(user.ID),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	// Groups import by name
+	if len(data.Groups) > 0 {
+		section := importSection{Title: "Groups", Noun: "groups"}
+		for _, group := range data.Groups {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_group",
+				Address:  names.address("prism_group", names.groups[group.Name]),
+				ID:       group.Name,
+				PrismIDs: prismIDs(group.ID),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	// Group memberships import by group name, and group members by
+	// group_name:username with colons in either escaped
+	section := importSection{Title: "Group Memberships", Noun: "group memberships"}
+	if names.individualMembers() {
+		section = importSection{Title: "Group Members", Noun: "group members"}
+	}
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		if len(data.GroupMemberships[groupName]) == 0 {
+			continue
+		}
+		if names.individualMembers() {
+			for _, member := range data.GroupMemberships[groupName] {
+				section.Targets = append(section.Targets, ImportTarget{
+					Type:     "prism_group_member",
+					Address:  names.address("prism_group_member", names.members[groupMember{Group: groupName, Username: member}]),
+					ID:       compositeid.Encode(':', groupName, member),
+					PrismIDs: prismIDs(groupIDs[groupName], userIDs[member]),
+				})
+			}
+			continue
+		}
+		section.Targets = append(section.Targets, ImportTarget{
+			Type:     "prism_group_membership",
+			Address:  names.address("prism_group_membership", names.memberships[groupName]),
+			ID:       groupName,
+			PrismIDs: prismIDs(groupIDs[groupName]),
+		})
+	}
+	if len(section.Targets) > 0 {
+		sections = append(sections, section)
+	}
+
+	// Permission set assignments import by their comma-separated backend IDs
+	if len(data.PermissionSetAssignments) > 0 {
+		section := importSection{Title: "Permission Set Assignments", Noun: "permission set assignments"}
+		for _, assignment := range groupAssignments(data) {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_permission_set_assignment",
+				Address:  names.address("prism_permission_set_assignment", assignment.Name),
+				ID:       assignment.ID(),
+				PrismIDs: prismIDs(assignment.AssignmentIDs...),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	// Identity providers import by type:alias
+	if len(data.IdentityProviders) > 0 {
+		section := importSection{Title: "Identity Providers", Noun: "identity providers"}
+		for _, idp := range data.IdentityProviders {
+			section.Targets = append(section.Targets, ImportTarget{
+				Type:     "prism_identity_provider",
+				Address:  names.address("prism_identity_provider", names.identityProviders[idp.Alias]),
+				ID:       compositeid.Encode(':', idp.Type, idp.Alias),
+				PrismIDs: prismIDs(idp.ID),
+			})
+		}
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+// prismIDs returns the non-empty ids, e.g. leaving out the ID of a group
+// that wasn't fetched.
+func prismIDs(ids ...string) []string {
+	var result []string
+	for _, id := range ids {
+		if id != "" {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// importBlocksFile is the file generateImportBlocks writes to.
+func importBlocksFile(config Config) string {
+	if config.Layout == LayoutModule {
+		return RootImportsFile
+	}
+	return "imports.tf"
+}
+
+// generateImportBlocks writes imports.tf with a Terraform 1.5+ import block
+// for every generated resource, so one plan/apply adopts everything.
+func generateImportBlocks(outputDir, fileName string, data *InfrastructureData, names *resourceNames) error {
+	w, err := createHCLFile(outputDir, fileName)
+	if err != nil {
+		return err
+	}
+	w.append(func(body *hclwrite.Body) {
+		appendComment(body, "Terraform import blocks - generated automatically")
+		appendComment(body, "Requires Terraform >= 1.5. Run `terraform plan` to review the imports")
+		appendComment(body, "and `terraform apply` to bring all resources into state at once.")
+	})
+
+	for _, section := range importSections(data, names) {
+		w.append(func(body *hclwrite.Body) {
+			body.AppendNewline()
+			appendComment(body, section.Title)
+		})
+		for _, target := range section.Targets {
+			w.append(func(body *hclwrite.Body) {
+				body.AppendNewline()
+				block := body.AppendNewBlock("import", nil).Body()
+				block.SetAttributeRaw("to", tokensForAddress(target.Address))
+				block.SetAttributeValue("id", cty.StringVal(target.ID))
+			})
+		}
+	}
+
+	return w.close()
+}
+
+// tokensForAddress renders a resource address such as prism_user.alice.
+func tokensForAddress(address string) hclwrite.Tokens {
+	address, index, indexed := strings.Cut(address, "[")
+	parts := strings.Split(address, ".")
+	t := traversal(parts[0], parts[1:]...)
+	if indexed {
+		// for_each instance, e.g. prism_user.this["alice"]
+		t = append(t, hcl.TraverseIndex{Key: cty.StringVal(strings.Trim(index, `"]`))})
+	}
+	return hclwrite.TokensForTraversal(t)
+}
+
+func generateImportScript(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	var sb strings.Builder
+
+	sb.WriteString("#!/bin/bash\n")
+	sb.WriteString("# Terraform import script - generated automatically\n")
+	sb.WriteString("# This script imports existing resources into Terraform state\n\n")
+	sb.WriteString("set -e\n\n")
+	sb.WriteString("echo \"Starting Terraform import process...\"\n\n")
+
+	for _, section := range importSections(data, names) {
+		sb.WriteString(fmt.Sprintf("# Import %s\n", section.Title))
+		sb.WriteString(fmt.Sprintf("echo \"Importing %s...\"\n", section.Noun))
+		for _, target := range section.Targets {
+			address := target.Address
+			if strings.Contains(address, "[") {
+				address = shellQuote(address)
+			}
+			sb.WriteString(fmt.Sprintf("terraform import %s %s\n", address, shellQuote(target.ID)))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("echo \"✅ Import complete!\"\n")
+	sb.WriteString("echo \"Next steps:\"\n")
+	sb.WriteString("echo \"  1. Run: terraform plan\"\n")
+	sb.WriteString("echo \"  2. Review any differences\"\n")
+	sb.WriteString("echo \"  3. Run: terraform apply (if needed)\"\n")
+
+	return os.WriteFile(filepath.Join(outputDir, "import.sh"), []byte(sb.String()), 0755)
+}
+
+// generateImportPowerShell writes import.ps1, the import script for Windows
+// users without bash. It runs the same commands as import.sh.
+func generateImportPowerShell(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	var sb strings.Builder
+
+	// Windows PowerShell reads scripts without a byte order mark as ANSI,
+	// which would garble non-ASCII names
+	sb.WriteString("\ufeff")
+	sb.WriteString("# Terraform import script for PowerShell - generated automatically\n")
+	sb.WriteString("# This script imports existing resources into Terraform state\n\n")
+	sb.WriteString("$ErrorActionPreference = 'Stop'\n\n")
+	sb.WriteString("# Windows PowerShell and PowerShell before 7.3 drop double quotes from\n")
+	sb.WriteString("# arguments to native commands unless they are escaped\n")
+	sb.WriteString("$EscapeQuotes = $PSVersionTable.PSVersion -lt [version]'7.3' -or $PSNativeCommandArgumentPassing -eq 'Legacy'\n\n")
+	sb.WriteString("function Import-Resource([string]$Address, [string]$Id) {\n")
+	sb.WriteString("    if ($EscapeQuotes) {\n")
+	sb.WriteString("        $Address = $Address -replace '\"', '\\\"'\n")
+	sb.WriteString("        $Id = $Id -replace '\"', '\\\"'\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    & terraform import $Address $Id\n")
+	sb.WriteString("    # Stop on the first failure, like set -e in import.sh\n")
+	sb.WriteString("    if ($LASTEXITCODE -ne 0) {\n")
+	sb.WriteString("        throw \"terraform import $Address failed with exit code $LASTEXITCODE\"\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n\n")
+	sb.WriteString("Write-Host \"Starting Terraform import process...\"\n\n")
+
+	for _, section := range importSections(data, names) {
+		sb.WriteString(fmt.Sprintf("# Import %s\n", section.Title))
+		sb.WriteString(fmt.Sprintf("Write-Host \"Importing %s...\"\n", section.Noun))
+		for _, target := range section.Targets {
+			sb.WriteString(fmt.Sprintf("Import-Resource %s %s\n", powerShellQuote(target.Address), powerShellQuote(target.ID)))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Write-Host \"Import complete!\"\n")
+	sb.WriteString("Write-Host \"Next steps:\"\n")
+	sb.WriteString("Write-Host \"  1. Run: terraform plan\"\n")
+	sb.WriteString("Write-Host \"  2. Review any differences\"\n")
+	sb.WriteString("Write-Host \"  3. Run: terraform apply (if needed)\"\n")
+
+	return os.WriteFile(filepath.Join(outputDir, "import.ps1"), []byte(sb.String()), 0644)
+}
+
+// powerShellQuote quotes s as a single-quoted PowerShell string, in which
+// only ' is special. Unquoted, commas in assignment IDs would make an array.
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// shellQuote quotes s as a single word for bash.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
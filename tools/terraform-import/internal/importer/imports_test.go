@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestGenerateFiles_ImportBlocks(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks}, testInfrastructure())
+	assertGoldenDir(t, dir, "import-blocks")
+
+	if _, err := os.Stat(filepath.Join(dir, "import.sh")); !os.IsNotExist(err) {
+		t.Errorf("expected no import.sh in blocks mode, got %v", err)
+	}
+}
+
+func TestGenerateFiles_ImportScript(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatScript}, testInfrastructure())
+	assertGoldenDir(t, dir, "import-script")
+
+	info, err := os.Stat(filepath.Join(dir, "import.sh"))
+	if err != nil {
+		t.Fatalf("expected import.sh: %s", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected import.sh to be executable, got mode %s", info.Mode())
+	}
+}
+
+func TestImportSections_IDsMatchImportState(t *testing.T) {
+	want := map[string]string{
+		"prism_aws_account.production":                         "111111111111",
+		"prism_permission_set.readonly":                        "ps-1",
+		"prism_user.alice":                                     "alice",
+		"prism_user.o_brien":                                   "o'brien",
+		"prism_group.engineering":                              "Engineering",
+		"prism_group_membership.engineering_members":           "Engineering",
+		"prism_permission_set_assignment.readonly_engineering": "assign-1,assign-2",
+	}
+
+	got := map[string]string{}
+	for _, section := range importSections(testInfrastructure(), newResourceNames(testInfrastructure(), nil)) {
+		for _, target := range section.Targets {
+			got[target.Address] = target.ID
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("expected %d import targets, got %d: %v", len(want), len(got), got)
+	}
+	for address, id := range want {
+		if got[address] != id {
+			t.Errorf("expected %s to import with ID %q, got %q", address, id, got[address])
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"alice":           `'alice'`,
+		"o'brien":         `'o'\''brien'`,
+		"a,b":             `'a,b'`,
+		"platform $(eng)": `'platform $(eng)'`,
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestGenerateFiles_ImportPowerShell(t *testing.T) {
+	// for_each addresses contain double quotes and identity provider IDs
+	// contain colons
+	data := testInfrastructure()
+	data.IdentityProviders = identityProviderInfrastructure().IdentityProviders
+
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			dir := generateTestFiles(t, Config{ImportFormat: ImportFormatScript, Style: style}, data)
+			assertGoldenFile(t, filepath.Join(dir, "import.ps1"), filepath.Join("import-powershell", style+".ps1"))
+
+			// Both scripts run the same commands
+			sh, err := os.ReadFile(filepath.Join(dir, "import.sh"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ps1, err := os.ReadFile(filepath.Join(dir, "import.ps1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			shCommands := regexp.MustCompile(`(?m)^terraform import `).FindAll(sh, -1)
+			ps1Commands := regexp.MustCompile(`(?m)^Import-Resource '`).FindAll(ps1, -1)
+			if len(shCommands) == 0 || len(shCommands) != len(ps1Commands) {
+				t.Errorf("expected the same number of imports, got %d in import.sh and %d in import.ps1", len(shCommands), len(ps1Commands))
+			}
+		})
+	}
+}
+
+func TestPowerShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"alice":                       `'alice'`,
+		"o'brien":                     `'o''brien'`,
+		"a,b":                         `'a,b'`,
+		"google:corp":                 `'google:corp'`,
+		`prism_user.this["alice"]`:    `'prism_user.this["alice"]'`,
+		"platform $(eng) `whoami` @x": "'platform $(eng) `whoami` @x'",
+	}
+	for in, want := range tests {
+		if got := powerShellQuote(in); got != want {
+			t.Errorf("powerShellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestImportSections_EscapesCompositeIDs(t *testing.T) {
+	// LDAP-style usernames hold colons, which would otherwise read as the
+	// separator of group_name:username
+	data := sortedData(&InfrastructureData{
+		Users:             []provider.User{{Username: "CN=jane:doe"}},
+		Groups:            []provider.Group{{Name: "ops:admins"}},
+		GroupMemberships:  map[string][]string{"ops:admins": {"CN=jane:doe"}},
+		IdentityProviders: []provider.IdentityProvider{{Type: "oidc", Alias: "corp:sso"}},
+	})
+	names := newResourceNames(data, nil)
+	names.membershipStyle = MembershipStyleIndividual
+
+	ids := map[string]string{}
+	for _, section := range importSections(data, names) {
+		for _, target := range section.Targets {
+			ids[target.Type] = target.ID
+		}
+	}
+	if got, want := ids["prism_group_member"], "ops%3Aadmins:CN=jane%3Adoe"; got != want {
+		t.Errorf("expected group member ID %q, got %q", want, got)
+	}
+	if got, want := ids["prism_identity_provider"], "oidc:corp%3Asso"; got != want {
+		t.Errorf("expected identity provider ID %q, got %q", want, got)
+	}
+}
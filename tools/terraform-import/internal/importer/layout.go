@@ -0,0 +1,346 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// Supported values of the -output-layout flag
+const (
+	OutputLayoutSplit       = "split"
+	OutputLayoutSingle      = "single"
+	OutputLayoutByGroupPath = "by-group-path"
+)
+
+// CommonDirectory holds the resources that no one team owns with
+// -output-layout=by-group-path: AWS accounts, permission sets, identity
+// providers, groups without a path and users in several teams' groups.
+const CommonDirectory = "common"
+
+// mainFileOrder lists the files that -output-layout=single merges into
+// main.tf, in dependency order.
+var mainFileOrder = []string{
+	"provider.tf", "versions.tf", "variables.tf",
+	"aws_accounts.tf", "permission_sets.tf", "users.tf", "groups.tf", "assignments.tf", "identity_providers.tf",
+	"imports.tf", "outputs.tf",
+}
+
+// mergeIntoMainFile replaces the files in mainFileOrder that were written to
+// outputDir with main.tf holding their contents, one after the other. The
+// files are copied rather than read whole, since a large tenant's files can
+// be large.
+func mergeIntoMainFile(outputDir string) (err error) {
+	merged, err := os.Create(filepath.Join(outputDir, "main.tf"))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := merged.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	empty := true
+	for _, name := range mainFileOrder {
+		path := filepath.Join(outputDir, name)
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !empty {
+			if _, err := merged.WriteString("\n"); err != nil {
+				f.Close()
+				return err
+			}
+		}
+		n, err := io.Copy(merged, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		empty = empty && n == 0
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupPathPartition is the part of the data generated into one directory
+// with -output-layout=by-group-path.
+type groupPathPartition struct {
+	Dir  string // relative to the output directory, with forward slashes
+	Data *InfrastructureData
+}
+
+// GroupPathDirectories returns the directories -output-layout=by-group-path
+// generates for data, CommonDirectory first, for reporting.
+func GroupPathDirectories(data *InfrastructureData) []string {
+	var dirs []string
+	for _, partition := range partitionByGroupPath(sortedData(data)) {
+		dirs = append(dirs, partition.Dir)
+	}
+	return dirs
+}
+
+// partitionByGroupPath splits sorted data into CommonDirectory and one
+// partition per top-level group path, i.e. per path that isn't below
+// another group's path. A team's partition holds its groups, their
+// memberships and assignments, and the users who are only in its groups.
+func partitionByGroupPath(data *InfrastructureData) []groupPathPartition {
+	topLevel := topLevelGroupPaths(data.Groups)
+
+	// Directories are allocated like resource names, so that two paths that
+	// sanitize alike, or a path called common, get distinct directories
+	allocator := newNameAllocator(nil)
+	allocator.reserve(CommonDirectory)
+	dirs := make(map[string]string, len(topLevel)) // top-level path -> directory
+	for _, path := range topLevel {
+		dirs[path] = allocator.allocate(groupPathDirectory(path))
+	}
+
+	groupDirs := make(map[string]string) // group name -> directory
+	for _, group := range data.Groups {
+		groupDirs[group.Name] = CommonDirectory
+		if path := normalizeGroupPath(group.Path); path != "" {
+			for _, top := range topLevel {
+				if strings.HasPrefix(path, top) {
+					groupDirs[group.Name] = dirs[top]
+					break
+				}
+			}
+		}
+	}
+
+	// A user belongs to a team when all of their groups do
+	userTeams := make(map[string]map[string]bool)
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		dir, ok := groupDirs[groupName]
+		if !ok {
+			dir = CommonDirectory
+		}
+		for _, member := range data.GroupMemberships[groupName] {
+			if userTeams[member] == nil {
+				userTeams[member] = make(map[string]bool)
+			}
+			userTeams[member][dir] = true
+		}
+	}
+	userDirs := make(map[string]string) // username -> directory
+	for _, user := range data.Users {
+		userDirs[user.Username] = CommonDirectory
+		if len(userTeams[user.Username]) == 1 {
+			for dir := range userTeams[user.Username] {
+				userDirs[user.Username] = dir
+			}
+		}
+	}
+
+	partitions := map[string]*InfrastructureData{
+		CommonDirectory: {
+			AWSAccounts:       data.AWSAccounts,
+			PermissionSets:    data.PermissionSets,
+			IdentityProviders: data.IdentityProviders,
+		},
+	}
+	partition := func(dir string) *InfrastructureData {
+		if partitions[dir] == nil {
+			partitions[dir] = &InfrastructureData{namingPermissionSets: data.PermissionSets}
+		}
+		if partitions[dir].GroupMemberships == nil {
+			partitions[dir].GroupMemberships = make(map[string][]string)
+		}
+		return partitions[dir]
+	}
+
+	for _, user := range data.Users {
+		p := partition(userDirs[user.Username])
+		p.Users = append(p.Users, user)
+	}
+	for _, group := range data.Groups {
+		p := partition(groupDirs[group.Name])
+		p.Groups = append(p.Groups, group)
+	}
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		dir, ok := groupDirs[groupName]
+		if !ok {
+			dir = CommonDirectory
+		}
+		partition(dir).GroupMemberships[groupName] = data.GroupMemberships[groupName]
+	}
+	for _, assignment := range data.PermissionSetAssignments {
+		// Assignments go with their principal, and to CommonDirectory when
+		// it wasn't fetched
+		var dir string
+		var ok bool
+		if assignment.PrincipalType == "USER" {
+			dir, ok = userDirs[assignment.Username]
+		} else {
+			dir, ok = groupDirs[assignment.GroupName]
+		}
+		if !ok {
+			dir = CommonDirectory
+		}
+		p := partition(dir)
+		p.PermissionSetAssignments = append(p.PermissionSetAssignments, assignment)
+	}
+
+	result := []groupPathPartition{{Dir: CommonDirectory, Data: partitions[CommonDirectory]}}
+	for _, dir := range sortedKeys(partitions) {
+		if dir != CommonDirectory {
+			result = append(result, groupPathPartition{Dir: dir, Data: partitions[dir]})
+		}
+	}
+	return result
+}
+
+// normalizeGroupPath returns path as "/a/b/", or "" for a group at the root.
+func normalizeGroupPath(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path + "/"
+}
+
+// topLevelGroupPaths returns the sorted, normalized paths of groups that
+// aren't below another group's path.
+func topLevelGroupPaths(groups []provider.Group) []string {
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		if path := normalizeGroupPath(group.Path); path != "" {
+			seen[path] = true
+		}
+	}
+	paths := sortedKeys(seen)
+
+	// Sorted, a path comes right before the paths below it
+	var topLevel []string
+	for _, path := range paths {
+		if n := len(topLevel); n > 0 && strings.HasPrefix(path, topLevel[n-1]) {
+			continue
+		}
+		topLevel = append(topLevel, path)
+	}
+	return topLevel
+}
+
+var invalidDirChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// groupPathDirectory converts a normalized group path such as
+// "/teams/engineering/" into a relative directory such as
+// "teams/engineering", keeping each segment safe to use as a file name.
+func groupPathDirectory(path string) string {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		segment = strings.Trim(invalidDirChars.ReplaceAllString(segment, "_"), "_")
+		if segment == "" || strings.Trim(segment, ".") == "" {
+			// Empty, . or .. would leave the output directory
+			segment = "group_path"
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// generateByGroupPath writes one root configuration per partition of data
+// into its own directory under config.OutputDir. Every resource keeps the
+// name it would have in a single configuration; references to resources in
+// other directories, which Terraform can't follow, are written as literals.
+func generateByGroupPath(config Config, data *InfrastructureData, names *resourceNames) error {
+	partitions := partitionByGroupPath(data)
+
+	var dirs []string
+	for _, partition := range partitions {
+		dir := filepath.Join(config.OutputDir, filepath.FromSlash(partition.Dir))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		partitionConfig := config
+		partitionConfig.OutputDir = dir
+		partitionNames := names.restrictTo(partition.Data)
+		variables := extractVariables(partition.Data, partitionNames, config.VarAccounts, config.UseLocals)
+		if err := generateConfiguration(partitionConfig, partition.Data, variables, partitionNames); err != nil {
+			return fmt.Errorf("%s: %w", partition.Dir, err)
+		}
+		dirs = append(dirs, partition.Dir)
+	}
+
+	if config.ImportFormat == ImportFormatScript {
+		if err := generateImportRunnerScript(config.OutputDir, dirs); err != nil {
+			return err
+		}
+		return generateImportRunnerPowerShell(config.OutputDir, dirs)
+	}
+	return nil
+}
+
+// generateImportRunnerScript writes import.sh for
+// -output-layout=by-group-path, which initializes each directory and runs
+// its import.sh there, since each directory has its own state.
+func generateImportRunnerScript(outputDir string, dirs []string) error {
+	var sb strings.Builder
+
+	sb.WriteString("#!/bin/bash\n")
+	sb.WriteString("# Terraform import script - generated automatically\n")
+	sb.WriteString("# Each directory is a separate configuration with its own state, so this\n")
+	sb.WriteString("# runs the import script of each directory in that directory\n\n")
+	sb.WriteString("set -e\n\n")
+	sb.WriteString("cd \"$(dirname \"$0\")\"\n\n")
+
+	for _, dir := range dirs {
+		sb.WriteString(fmt.Sprintf("echo \"Importing into %s...\"\n", dir))
+		sb.WriteString(fmt.Sprintf("(cd %s && terraform init -input=false && bash ./import.sh)\n\n", shellQuote(dir)))
+	}
+
+	sb.WriteString("echo \"✅ Import complete!\"\n")
+	sb.WriteString("echo \"Next steps: run terraform plan in each directory\"\n")
+
+	return os.WriteFile(filepath.Join(outputDir, "import.sh"), []byte(sb.String()), 0755)
+}
+
+// generateImportRunnerPowerShell writes import.ps1, the PowerShell version
+// of generateImportRunnerScript.
+func generateImportRunnerPowerShell(outputDir string, dirs []string) error {
+	var sb strings.Builder
+
+	// Windows PowerShell reads scripts without a byte order mark as ANSI
+	sb.WriteString("\ufeff")
+	sb.WriteString("# Terraform import script for PowerShell - generated automatically\n")
+	sb.WriteString("# Each directory is a separate configuration with its own state, so this\n")
+	sb.WriteString("# runs the import script of each directory in that directory\n\n")
+	sb.WriteString("$ErrorActionPreference = 'Stop'\n\n")
+
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = powerShellQuote(dir)
+	}
+	sb.WriteString(fmt.Sprintf("foreach ($Dir in @(%s)) {\n", strings.Join(quoted, ", ")))
+	sb.WriteString("    Write-Host \"Importing into $Dir...\"\n")
+	sb.WriteString("    Push-Location (Join-Path $PSScriptRoot $Dir)\n")
+	sb.WriteString("    try {\n")
+	sb.WriteString("        & terraform init -input=false\n")
+	sb.WriteString("        if ($LASTEXITCODE -ne 0) {\n")
+	sb.WriteString("            throw \"terraform init failed in $Dir with exit code $LASTEXITCODE\"\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        & ./import.ps1\n")
+	sb.WriteString("    } finally {\n")
+	sb.WriteString("        Pop-Location\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("Write-Host \"Import complete!\"\n")
+	sb.WriteString("Write-Host \"Next steps: run terraform plan in each directory\"\n")
+
+	return os.WriteFile(filepath.Join(outputDir, "import.ps1"), []byte(sb.String()), 0644)
+}
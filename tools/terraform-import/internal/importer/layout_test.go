@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// teamInfrastructure has two teams by group path, one with a nested group,
+// a group without a path and users in one team, in two and in none.
+func teamInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		AWSAccounts: []provider.AWSAccount{
+			{ID: "acct-1", AccountID: "111111111111", AccountName: "Production", Region: "us-east-1"},
+			{ID: "acct-2", AccountID: "222222222222", AccountName: "Staging", Region: "us-east-1"},
+		},
+		PermissionSets: []provider.PermissionSet{
+			{ID: "ps-1", Name: "ReadOnly", SessionDuration: "PT4H"},
+			{ID: "ps-2", Name: "Admin", SessionDuration: "PT1H"},
+		},
+		Users: []provider.User{
+			{ID: "user-1", Username: "alice", Email: "alice@example.com", Enabled: true},
+			{ID: "user-2", Username: "bob", Email: "bob@example.com", Enabled: true},
+			{ID: "user-3", Username: "carol", Email: "carol@example.com", Enabled: true},
+			{ID: "user-4", Username: "dave", Email: "dave@example.com", Enabled: true},
+		},
+		Groups: []provider.Group{
+			{ID: "group-1", Name: "Platform", Path: "/teams/platform/"},
+			{ID: "group-2", Name: "Platform Oncall", Path: "/teams/platform/oncall/"},
+			{ID: "group-3", Name: "Data", Path: "/teams/data/"},
+			{ID: "group-4", Name: "Everyone"},
+		},
+		GroupMemberships: map[string][]string{
+			"Platform":        {"alice", "carol"},
+			"Platform Oncall": {"alice"},
+			"Data":            {"bob", "carol"},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "assign-1", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Platform", AccountID: "111111111111"},
+			{ID: "assign-2", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Platform", AccountID: "222222222222"},
+			{ID: "assign-3", PermissionSetID: "ps-2", PrincipalType: "GROUP", GroupName: "Platform Oncall", AccountID: "111111111111"},
+			{ID: "assign-4", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Data", AccountID: "222222222222"},
+			{ID: "assign-5", PermissionSetID: "ps-2", PrincipalType: "USER", Username: "carol", AccountID: "111111111111"},
+			{ID: "assign-6", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "bob", AccountID: "222222222222"},
+			{ID: "assign-7", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Everyone", AccountID: "111111111111"},
+		},
+	}
+}
+
+func TestGenerateFiles_OutputLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "split", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSplit}},
+		{name: "single", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSingle}},
+		{name: "single-module", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleForEach, Layout: LayoutModule, ModuleName: "prism", OutputLayout: OutputLayoutSingle}},
+		{name: "by-group-path", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutByGroupPath}},
+		{name: "by-group-path-script", config: Config{ImportFormat: ImportFormatScript, Style: StyleForEach, OutputLayout: OutputLayoutByGroupPath}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := generateTestFiles(t, tt.config, teamInfrastructure())
+			assertGoldenDir(t, dir, filepath.Join("output-layout", tt.name))
+		})
+	}
+}
+
+func TestGenerateFiles_ByGroupPathDirectoriesStandAlone(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutByGroupPath}, teamInfrastructure())
+
+	for _, sub := range []string{"common", "teams/data", "teams/platform"} {
+		t.Run(sub, func(t *testing.T) {
+			assertReferencesResolve(t, filepath.Join(dir, sub))
+		})
+	}
+
+	// Groups below a top-level path share its directory
+	groups, err := os.ReadFile(filepath.Join(dir, "teams", "platform", "groups.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(groups), `resource "prism_group" "platform_oncall"`) {
+		t.Errorf("expected Platform Oncall next to Platform, got:\n%s", groups)
+	}
+}
+
+func TestGroupPathDirectories(t *testing.T) {
+	data := teamInfrastructure()
+	data.Groups = append(data.Groups,
+		provider.Group{Name: "Common", Path: "/common/"},
+		provider.Group{Name: "Sneaky", Path: "/../etc/"},
+		provider.Group{Name: "Spaced", Path: "/Site Reliability/"},
+		provider.Group{Name: "Underscored", Path: "/Site_Reliability/"},
+	)
+
+	got := GroupPathDirectories(data)
+	want := []string{"common", "Site_Reliability", "Site_Reliability_2", "common_2", "group_path/etc", "teams/data", "teams/platform"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected directories %v, got %v", want, got)
+	}
+}
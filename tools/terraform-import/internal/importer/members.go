@@ -0,0 +1,33 @@
+package importer
+
+// Supported values of the -membership-style flag
+const (
+	MembershipStyleAuthoritative = "authoritative"
+	MembershipStyleIndividual    = "individual"
+)
+
+// authoritativeMembershipsComment explains the memberships in groups.tf
+// with -membership-style=authoritative.
+const authoritativeMembershipsComment = `Each prism_group_membership is authoritative: it sets the complete list of
+a group's members, so Terraform removes members added any other way, such
+as by SCIM or onboarding automation. To leave those members alone, generate
+with -membership-style=individual instead.`
+
+// individualMembersComment explains the members in groups.tf with
+// -membership-style=individual.
+const individualMembersComment = `Each prism_group_member adds one user to one group and leaves the group's
+other members alone, so members added any other way, such as by SCIM or
+onboarding automation, are kept. Terraform doesn't report them either, and
+removing a user from a group takes removing their prism_group_member. For
+one authoritative list of members per group, generate with
+-membership-style=authoritative instead.`
+
+// membershipsComment returns the heading of the memberships in groups.tf
+// and an explanation of what their style means for members managed
+// elsewhere.
+func membershipsComment(names *resourceNames) (heading, explanation string) {
+	if names.individualMembers() {
+		return "Group Members", individualMembersComment
+	}
+	return "Group Memberships", authoritativeMembershipsComment
+}
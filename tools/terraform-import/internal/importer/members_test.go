@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestGenerateFiles_MembershipStyle(t *testing.T) {
+	for _, membershipStyle := range []string{MembershipStyleAuthoritative, MembershipStyleIndividual} {
+		for _, style := range []string{StyleFlat, StyleForEach} {
+			t.Run(membershipStyle+"/"+style, func(t *testing.T) {
+				config := Config{ImportFormat: ImportFormatBlocks, Style: style, MembershipStyle: membershipStyle}
+				dir := generateTestFiles(t, config, testInfrastructure())
+				for _, name := range []string{"groups.tf", "imports.tf"} {
+					assertGoldenFile(t, filepath.Join(dir, name), filepath.Join("membership-style", membershipStyle, style, name))
+				}
+				assertReferencesResolve(t, dir)
+			})
+		}
+	}
+}
+
+func TestGenerateFiles_IndividualMembersScript(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatScript, Style: StyleFlat, MembershipStyle: MembershipStyleIndividual}
+	dir := generateTestFiles(t, config, testInfrastructure())
+
+	script, err := os.ReadFile(filepath.Join(dir, "import.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "terraform import prism_group_member.engineering_alice 'Engineering:alice'"; !strings.Contains(string(script), want) {
+		t.Errorf("expected import.sh to contain %q:\n%s", want, script)
+	}
+	if strings.Contains(string(script), "prism_group_membership") {
+		t.Errorf("expected no authoritative memberships:\n%s", script)
+	}
+}
+
+func TestResourceNames_MembersDontCollide(t *testing.T) {
+	// a_b + c and a + b_c both sanitize to a_b_c
+	data := sortedData(&InfrastructureData{
+		Users:  []provider.User{{Username: "c"}, {Username: "b_c"}},
+		Groups: []provider.Group{{Name: "a_b"}, {Name: "a"}},
+		GroupMemberships: map[string][]string{
+			"a":   {"b_c"},
+			"a_b": {"c"},
+		},
+	})
+
+	names := newResourceNames(data, nil)
+	first := names.members[groupMember{Group: "a", Username: "b_c"}]
+	second := names.members[groupMember{Group: "a_b", Username: "c"}]
+	if first != "a_b_c" || second != "a_b_c_2" {
+		t.Errorf("expected members a_b_c and a_b_c_2, got %q and %q", first, second)
+	}
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Supported values of the -layout flag
+const (
+	LayoutRoot   = "root"
+	LayoutModule = "module"
+)
+
+// RootImportsFile holds the import blocks in the module layout. Terraform
+// only allows import blocks in the root module, so the file is meant to be
+// copied there and isn't loaded as part of the module.
+const RootImportsFile = "root-imports.tf.example"
+
+// generateVersionsFile writes versions.tf for the module layout: the
+// provider requirements without a provider block, which the calling
+// configuration supplies.
+func generateVersionsFile(outputDir string, config Config) error {
+	f := hclwrite.NewEmptyFile()
+	appendTerraformBlock(f.Body(), config)
+	return writeHCLFile(outputDir, "versions.tf", f)
+}
+
+// generateOutputsFile writes outputs.tf for the module layout, exporting the
+// IDs that other configurations refer to, keyed by resource name.
+func generateOutputsFile(outputDir string, data *InfrastructureData, names *resourceNames) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	appendComment(body, "Module Outputs")
+
+	appendOutput := func(name, description, resourceType, attr string, resourceNames []string) {
+		if len(resourceNames) == 0 {
+			return
+		}
+
+		var value hclwrite.Tokens
+		if names.style == StyleForEach && forEachTypes[resourceType] {
+			value = tokensForForEachOutput(resourceType, attr)
+		} else {
+			values := make(map[string]hclwrite.Tokens, len(resourceNames))
+			for _, resourceName := range resourceNames {
+				values[resourceName] = names.reference(resourceType, resourceName, attr, "")
+			}
+			value = tokensForMap(values)
+		}
+
+		body.AppendNewline()
+		output := body.AppendNewBlock("output", []string{name}).Body()
+		output.SetAttributeValue("description", cty.StringVal(description))
+		output.SetAttributeRaw("value", value)
+	}
+
+	var accounts, permSets, users, groups []string
+	for _, acc := range data.AWSAccounts {
+		accounts = append(accounts, names.accounts[acc.AccountID])
+	}
+	for _, ps := range data.PermissionSets {
+		permSets = append(permSets, names.permissionSets[ps.ID])
+	}
+	for _, user := range data.Users {
+		users = append(users, names.users[user.Username])
+	}
+	for _, group := range data.Groups {
+		groups = append(groups, names.groups[group.Name])
+	}
+
+	appendOutput("account_ids", "AWS account IDs, by resource name", "prism_aws_account", "account_id", accounts)
+	appendOutput("permission_set_ids", "Permission set IDs, by resource name", "prism_permission_set", "id", permSets)
+	appendOutput("user_ids", "User IDs, by resource name", "prism_user", "id", users)
+	appendOutput("group_names", "Group names, by resource name", "prism_group", "name", groups)
+
+	return writeHCLFile(outputDir, "outputs.tf", f)
+}
+
+// tokensForForEachOutput renders { for k, v in <type>.this : k => v.<attr> }.
+func tokensForForEachOutput(resourceType, attr string) hclwrite.Tokens {
+	ident := func(name string) *hclwrite.Token {
+		return &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(name)}
+	}
+
+	toks := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
+		ident("for"), ident("k"),
+		{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
+		ident("v"), ident("in"),
+	}
+	toks = append(toks, hclwrite.TokensForTraversal(traversal(resourceType, forEachResourceName))...)
+	toks = append(toks,
+		&hclwrite.Token{Type: hclsyntax.TokenColon, Bytes: []byte(":")},
+		ident("k"),
+		&hclwrite.Token{Type: hclsyntax.TokenFatArrow, Bytes: []byte("=>")},
+	)
+	toks = append(toks, hclwrite.TokensForTraversal(traversal("v", attr))...)
+	return append(toks, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")})
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+func TestGenerateFiles_ModuleLayout(t *testing.T) {
+	dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Layout: LayoutModule, ModuleName: "prism"}, testInfrastructure())
+	assertGoldenDir(t, dir, "module")
+
+	for _, name := range []string{"provider.tf", "terraform.tfvars", "imports.tf"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected no %s in a module, got %v", name, err)
+		}
+	}
+
+	blocks := moduleBlocks(t, dir)
+	if len(blocks["provider"]) != 0 {
+		t.Errorf("expected no provider block in a module, got %v", blocks["provider"])
+	}
+	if len(blocks["import"]) != 0 {
+		t.Errorf("expected no import block in a module, got %d", len(blocks["import"]))
+	}
+	if want := []string{"account_ids", "group_names", "permission_set_ids", "user_ids"}; !reflect.DeepEqual(blocks["output"], want) {
+		t.Errorf("expected outputs %v, got %v", want, blocks["output"])
+	}
+	if want := []string{"prism_api_token", "prism_subdomain"}; !reflect.DeepEqual(blocks["variable"], want) {
+		t.Errorf("expected variables %v, got %v", want, blocks["variable"])
+	}
+}
+
+func TestGenerateVariablesFile_ModuleAccountDefaults(t *testing.T) {
+	variables := &Variables{AccountIDs: map[string]string{"111111111111": "production_account_id"}}
+
+	for layout, want := range map[string]bool{LayoutRoot: false, LayoutModule: true} {
+		dir := t.TempDir()
+		if err := generateVariablesFile(dir, Config{Layout: layout}, variables); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, "variables.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Modules have no terraform.tfvars, so the value becomes the default
+		if got := strings.Contains(string(content), `default     = "111111111111"`); got != want {
+			t.Errorf("%s: expected default %t, got:\n%s", layout, want, content)
+		}
+		if got := strings.Contains(string(content), "prism_base_url"); got == want {
+			t.Errorf("%s: expected prism_base_url %t, got:\n%s", layout, !want, content)
+		}
+	}
+}
+
+func TestGenerateFiles_ModuleLayoutForEach(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatScript, Layout: LayoutModule, ModuleName: "identity", Style: StyleForEach}
+	dir := generateTestFiles(t, config, testInfrastructure())
+
+	outputs, err := os.ReadFile(filepath.Join(dir, "outputs.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"{ for k, v in prism_user.this : k => v.id }",
+		"{ for k, v in prism_group.this : k => v.name }",
+		"production = prism_aws_account.production.account_id",
+	} {
+		if !strings.Contains(string(outputs), want) {
+			t.Errorf("expected outputs.tf to contain %q:\n%s", want, outputs)
+		}
+	}
+
+	script, err := os.ReadFile(filepath.Join(dir, "import.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"terraform import module.identity.prism_aws_account.production '111111111111'",
+		`terraform import 'module.identity.prism_user.this["alice"]' 'alice'`,
+	} {
+		if !strings.Contains(string(script), want) {
+			t.Errorf("expected import.sh to contain %q:\n%s", want, script)
+		}
+	}
+}
+
+// moduleBlocks parses the .tf files in dir and returns the first label of
+// each top-level block, by block type, sorted.
+func moduleBlocks(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+
+	schema := &hcl.BodySchema{}
+	for _, blockType := range []string{"terraform", "provider", "variable", "output", "resource", "locals", "import"} {
+		var labels []string
+		switch blockType {
+		case "provider", "variable", "output":
+			labels = []string{"name"}
+		case "resource":
+			labels = []string{"type", "name"}
+		}
+		schema.Blocks = append(schema.Blocks, hcl.BlockHeaderSchema{Type: blockType, LabelNames: labels})
+	}
+
+	blocks := make(map[string][]string)
+	parser := hclparse.NewParser()
+	for _, name := range listFiles(t, dir) {
+		if filepath.Ext(name) != ".tf" {
+			continue
+		}
+		file, diags := parser.ParseHCLFile(filepath.Join(dir, name))
+		if diags.HasErrors() {
+			t.Fatalf("parsing %s: %s", name, diags)
+		}
+		content, diags := file.Body.Content(schema)
+		if diags.HasErrors() {
+			t.Fatalf("reading %s: %s", name, diags)
+		}
+		for _, block := range content.Blocks {
+			label := ""
+			if len(block.Labels) > 0 {
+				label = block.Labels[0]
+			}
+			blocks[block.Type] = append(blocks[block.Type], label)
+		}
+	}
+	for _, labels := range blocks {
+		sort.Strings(labels)
+	}
+	return blocks
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+)
+
+// NameMap chooses the resource names of some objects instead of deriving
+// them from the objects' names. Objects that aren't in the map are named as
+// usual, avoiding the names the map hands out.
+type NameMap struct {
+	Users          map[string]string `yaml:"users"`           // username -> name
+	Groups         map[string]string `yaml:"groups"`          // group name -> name
+	PermissionSets map[string]string `yaml:"permission_sets"` // permission set name -> name
+	AWSAccounts    map[string]string `yaml:"aws_accounts"`    // AWS account ID -> name
+}
+
+// LoadNameMap reads a -name-map file. YAML is a superset of JSON, so the
+// file may be either.
+func LoadNameMap(path string) (*NameMap, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	m := &NameMap{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// validate checks that every name is a valid resource name and that no two
+// objects of a type are given the same name.
+func (m *NameMap) validate() error {
+	for _, section := range m.sections() {
+		byName := make(map[string]string)
+		for _, key := range sortedKeys(section.names) {
+			name := section.names[key]
+			if !hclsyntax.ValidIdentifier(name) {
+				return fmt.Errorf("%s: %q is mapped to %q, which isn't a valid resource name", section.key, key, name)
+			}
+			if other, ok := byName[name]; ok {
+				return fmt.Errorf("%s: %q and %q are both mapped to %q", section.key, other, key, name)
+			}
+			byName[name] = key
+		}
+	}
+	return nil
+}
+
+// nameMapSection is one resource type of a NameMap.
+type nameMapSection struct {
+	key   string // the section's key in the file
+	noun  string
+	names map[string]string
+}
+
+func (m *NameMap) sections() []nameMapSection {
+	return []nameMapSection{
+		{key: "users", noun: "user", names: m.Users},
+		{key: "groups", noun: "group", names: m.Groups},
+		{key: "permission_sets", noun: "permission set", names: m.PermissionSets},
+		{key: "aws_accounts", noun: "AWS account", names: m.AWSAccounts},
+	}
+}
+
+// Unused describes the entries of m that match none of the objects in data,
+// which usually means a typo.
+func (m *NameMap) Unused(data *InfrastructureData) []string {
+	present := map[string]map[string]bool{
+		"users":           {},
+		"groups":          {},
+		"permission_sets": {},
+		"aws_accounts":    {},
+	}
+	for _, user := range data.Users {
+		present["users"][user.Username] = true
+	}
+	for _, group := range data.Groups {
+		present["groups"][group.Name] = true
+	}
+	for _, ps := range data.PermissionSets {
+		present["permission_sets"][ps.Name] = true
+	}
+	for _, acc := range data.AWSAccounts {
+		present["aws_accounts"][acc.AccountID] = true
+	}
+
+	var unused []string
+	for _, section := range m.sections() {
+		for _, key := range sortedKeys(section.names) {
+			if !present[section.key][key] {
+				unused = append(unused, fmt.Sprintf("%s %q", section.noun, key))
+			}
+		}
+	}
+	return unused
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// partialNameMap is testdata/name-map/partial.yaml: one of each type, with
+// o'brien left to the sanitizer.
+func partialNameMap() *NameMap {
+	return &NameMap{
+		Users:          map[string]string{"alice": "o_brien"},
+		Groups:         map[string]string{"Engineering": "eng"},
+		PermissionSets: map[string]string{"ReadOnly": "read_only"},
+		AWSAccounts:    map[string]string{"111111111111": "prod"},
+	}
+}
+
+func TestLoadNameMap(t *testing.T) {
+	for _, file := range []string{"partial.yaml", "partial.json"} {
+		t.Run(file, func(t *testing.T) {
+			got, err := LoadNameMap(filepath.Join("testdata", "name-map", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := partialNameMap(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestLoadNameMap_Errors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"duplicate name":   {content: "users:\n  alice: admin\n  bob: admin\n", want: `users: "alice" and "bob" are both mapped to "admin"`},
+		"invalid name":     {content: "groups:\n  Engineering: 1eng\n", want: `groups: "Engineering" is mapped to "1eng", which isn't a valid resource name`},
+		"empty name":       {content: "aws_accounts:\n  \"111111111111\": \"\"\n", want: `aws_accounts: "111111111111" is mapped to "", which isn't a valid resource name`},
+		"unknown section":  {content: "identity_providers:\n  google: g\n", want: "field identity_providers not found"},
+		"duplicate object": {content: "users:\n  alice: a\n  alice: b\n", want: `mapping key "alice" already defined`},
+		"not a map":        {content: "users: [alice]\n", want: "cannot unmarshal"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "names.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadNameMap(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Names may repeat across types, like the resources they name
+	path := filepath.Join(t.TempDir(), "names.yaml")
+	if err := os.WriteFile(path, []byte("users:\n  admin: admin\ngroups:\n  Admins: admin\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadNameMap(path); err != nil {
+		t.Errorf("expected the same name for a user and a group to be fine, got %v", err)
+	}
+}
+
+func TestGenerateFiles_NameMap(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks, NameMap: partialNameMap()}
+	dir := generateTestFiles(t, config, testInfrastructure())
+	assertGoldenDir(t, dir, "name-map/generated")
+	assertReferencesResolve(t, dir)
+}
+
+func TestNameMap_Unused(t *testing.T) {
+	m := partialNameMap()
+	m.Users["bob"] = "bob"
+	m.AWSAccounts["999999999999"] = "gone"
+
+	want := []string{`user "bob"`, `AWS account "999999999999"`}
+	if got := m.Unused(testInfrastructure()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestNameMap_ReachesEveryAddress(t *testing.T) {
+	data := orphanedInfrastructure()
+	m := partialNameMap()
+
+	orphans := FindOrphans(data, allResourceKinds(t), m)
+	if len(orphans) == 0 || orphans[0].Address != "prism_group_membership.contractors_members" {
+		t.Fatalf("unexpected orphans %+v", orphans)
+	}
+	for _, orphan := range orphans {
+		if orphan.Address == "prism_group_membership.engineering_members" {
+			t.Errorf("expected the mapped group's membership to be renamed, got %s", orphan.Address)
+		}
+	}
+
+	live := liveResources(testInfrastructure(), StyleFlat, m)
+	var addresses []string
+	for _, resources := range live {
+		for _, resource := range resources {
+			addresses = append(addresses, resource.Address)
+		}
+	}
+	for _, want := range []string{"prism_user.o_brien", "prism_user.o_brien_2", "prism_group.eng", "prism_group_membership.eng_members", "prism_permission_set.read_only", "prism_aws_account.prod"} {
+		found := false
+		for _, address := range addresses {
+			found = found || address == want
+		}
+		if !found {
+			t.Errorf("expected -diff-state to address %s, got %v", want, addresses)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// toResourceName converts s into a valid Terraform resource name. Names
+// must start with a letter, so kind is prefixed to names starting with a
+// digit ("123-prod" becomes "account_123_prod") and used on its own when
+// nothing of s survives sanitizing.
+func toResourceName(s, kind string) string {
+	s = invalidNameChars.ReplaceAllString(s, "_")
+	s = strings.ToLower(s)
+	s = strings.Trim(s, "_")
+	if s == "" {
+		return kind
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		return kind + "_" + s
+	}
+	return s
+}
+
+// ProviderAlias returns the provider alias of the tenant with subdomain,
+// as a valid Terraform name.
+func ProviderAlias(subdomain string) string {
+	return toResourceName(subdomain, "tenant")
+}
+
+// nameAllocator hands out unique resource names within one resource type.
+type nameAllocator struct {
+	natural map[string]bool
+	used    map[string]bool
+}
+
+// newNameAllocator returns an allocator for the given base names. Knowing
+// all of them up front keeps a suffixed name from taking a name that
+// another resource has naturally.
+func newNameAllocator(bases []string) *nameAllocator {
+	a := &nameAllocator{
+		natural: make(map[string]bool, len(bases)),
+		used:    make(map[string]bool, len(bases)),
+	}
+	for _, base := range bases {
+		a.natural[base] = true
+	}
+	return a
+}
+
+// reserve takes name, so that allocate never hands it out.
+func (a *nameAllocator) reserve(name string) {
+	a.natural[name] = true
+	a.used[name] = true
+}
+
+// allocate returns base if it is still free and otherwise the first free
+// base_2, base_3, ... Calling it in a stable order gives stable names.
+func (a *nameAllocator) allocate(base string) string {
+	name := base
+	for n := 2; a.used[name]; n++ {
+		if candidate := fmt.Sprintf("%s_%d", base, n); !a.natural[candidate] {
+			name = candidate
+		}
+	}
+	a.used[name] = true
+	return name
+}
+
+// resourceNames holds the generated resource name of every exported
+// resource, keyed the way other resources refer to it. The .tf files and
+// the import blocks or script all take their names from here.
+type resourceNames struct {
+	accounts       map[string]string // AWS account ID -> name
+	permissionSets map[string]string // permission set ID -> name
+	users          map[string]string // username -> name
+	groups         map[string]string // group name -> name
+	memberships    map[string]string // group name -> membership name
+	members        map[groupMember]string
+
+	identityProviders map[string]string // identity provider alias -> name
+
+	// accountVariables holds the variables of AWS account IDs; see
+	// accountID
+	accountVariables map[string]string // AWS account ID -> variable name
+
+	// Locals that assignments refer to permission sets and principals
+	// through with -use-locals; see permissionSetID and principalID
+	permissionSetLocals map[string]string // permission set ID -> local name
+	userLocals          map[string]string // username -> local name
+	groupLocals         map[string]string // group name -> local name
+
+	// style is the -style the names are used with; see address
+	style string
+
+	// membershipStyle is the -membership-style, which decides whether
+	// memberships or members are generated
+	membershipStyle string
+
+	// module is the name the root module calls the generated module by with
+	// -layout=module, and empty otherwise
+	module string
+
+	// providerAlias is the alias of the prism provider configuration that
+	// resources use, and empty for the default one
+	providerAlias string
+}
+
+// groupMember is one user's membership of one group, generated as a
+// prism_group_member with -membership-style=individual.
+type groupMember struct {
+	Group    string
+	Username string
+}
+
+// individualMembers reports whether memberships are generated as one
+// prism_group_member per member rather than one prism_group_membership per
+// group.
+func (n *resourceNames) individualMembers() bool {
+	return n.membershipStyle == MembershipStyleIndividual
+}
+
+// appendResource appends the block of the named resource, starting with
+// the provider meta-argument when resources use an aliased provider.
+func (n *resourceNames) appendResource(body *hclwrite.Body, resourceType, name string) *hclwrite.Body {
+	resource := body.AppendNewBlock("resource", []string{resourceType, name}).Body()
+	if n.providerAlias != "" {
+		resource.SetAttributeTraversal("provider", traversal("prism", n.providerAlias))
+		resource.AppendNewline()
+	}
+	return resource
+}
+
+// address returns the address of the named resource, which is an instance
+// of the type's single for_each resource in the foreach style, from the root
+// module.
+func (n *resourceNames) address(resourceType, name string) string {
+	address := resourceType + "." + name
+	if n.style == StyleForEach && forEachTypes[resourceType] {
+		address = fmt.Sprintf("%s.%s[%q]", resourceType, forEachResourceName, name)
+	}
+	if n.module != "" {
+		address = "module." + n.module + "." + address
+	}
+	return address
+}
+
+// reference refers to attr of the named resource when there is one, and is
+// the literal value otherwise (e.g. when the resource's kind was excluded).
+func (n *resourceNames) reference(resourceType, name, attr, value string) hclwrite.Tokens {
+	if name == "" {
+		return hclwrite.TokensForValue(cty.StringVal(value))
+	}
+	if n.style == StyleForEach && forEachTypes[resourceType] {
+		return hclwrite.TokensForTraversal(hcl.Traversal{
+			hcl.TraverseRoot{Name: resourceType},
+			hcl.TraverseAttr{Name: forEachResourceName},
+			hcl.TraverseIndex{Key: cty.StringVal(name)},
+			hcl.TraverseAttr{Name: attr},
+		})
+	}
+	return hclwrite.TokensForTraversal(traversal(resourceType, name, attr))
+}
+
+// accountID refers to the account_id of the AWS account resource with
+// accountID when there is one, and otherwise to the account ID's variable
+// or, without one, is the literal ID.
+func (n *resourceNames) accountID(accountID string) hclwrite.Tokens {
+	if name, ok := n.accounts[accountID]; ok {
+		return n.reference("prism_aws_account", name, "account_id", accountID)
+	}
+	if variable, ok := n.accountVariables[accountID]; ok {
+		return hclwrite.TokensForTraversal(traversal("var", variable))
+	}
+	return hclwrite.TokensForValue(cty.StringVal(accountID))
+}
+
+// permissionSetID refers to the id of the permission set with id through its
+// local when it has one, and otherwise as reference does.
+func (n *resourceNames) permissionSetID(id string) hclwrite.Tokens {
+	if local, ok := n.permissionSetLocals[id]; ok {
+		return hclwrite.TokensForTraversal(traversal("local", local))
+	}
+	return n.reference("prism_permission_set", n.permissionSets[id], "id", id)
+}
+
+// principalID refers to the username or group name of an assignment's
+// principal through its local when it has one, and otherwise as reference
+// does.
+func (n *resourceNames) principalID(principalType, principal string) hclwrite.Tokens {
+	if principalType == "USER" {
+		if local, ok := n.userLocals[principal]; ok {
+			return hclwrite.TokensForTraversal(traversal("local", local))
+		}
+		return n.reference("prism_user", n.users[principal], "username", principal)
+	}
+	if local, ok := n.groupLocals[principal]; ok {
+		return hclwrite.TokensForTraversal(traversal("local", local))
+	}
+	return n.reference("prism_group", n.groups[principal], "name", principal)
+}
+
+// newResourceNames names the resources in data, taking names from nameMap
+// (which may be nil) where it has them. data must already be sorted (see
+// sortedData) for the names to be stable.
+func newResourceNames(data *InfrastructureData, nameMap *NameMap) *resourceNames {
+	names := &resourceNames{
+		accounts:       make(map[string]string),
+		permissionSets: make(map[string]string),
+		users:          make(map[string]string),
+		groups:         make(map[string]string),
+		memberships:    make(map[string]string),
+		members:        make(map[groupMember]string),
+
+		identityProviders: make(map[string]string),
+	}
+
+	var fixed NameMap
+	if nameMap != nil {
+		fixed = *nameMap
+	}
+
+	var keys, bases []string
+	for _, acc := range data.AWSAccounts {
+		keys = append(keys, acc.AccountID)
+		bases = append(bases, toResourceName(acc.AccountName, "account"))
+	}
+	allocateNames(names.accounts, keys, bases, fixed.AWSAccounts)
+
+	// Permission sets are mapped by name but referred to by ID
+	keys, bases = nil, nil
+	permSetNames := make(map[string]string)
+	for _, ps := range data.PermissionSets {
+		keys = append(keys, ps.ID)
+		bases = append(bases, toResourceName(ps.Name, "permission_set"))
+		if name, ok := fixed.PermissionSets[ps.Name]; ok {
+			permSetNames[ps.ID] = name
+		}
+	}
+	allocateNames(names.permissionSets, keys, bases, permSetNames)
+
+	keys, bases = nil, nil
+	for _, user := range data.Users {
+		keys = append(keys, user.Username)
+		bases = append(bases, toResourceName(user.Username, "user"))
+	}
+	allocateNames(names.users, keys, bases, fixed.Users)
+
+	keys, bases = nil, nil
+	for _, group := range data.Groups {
+		keys = append(keys, group.Name)
+		bases = append(bases, toResourceName(group.Name, "group"))
+	}
+	allocateNames(names.groups, keys, bases, fixed.Groups)
+
+	// Memberships follow their group's name when the group is exported
+	keys, bases = nil, nil
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		if len(data.GroupMemberships[groupName]) == 0 {
+			// Not generated
+			continue
+		}
+		base := names.groups[groupName]
+		if base == "" {
+			base = toResourceName(groupName, "group")
+		}
+		keys = append(keys, groupName)
+		bases = append(bases, base+"_members")
+	}
+	allocateNames(names.memberships, keys, bases, nil)
+
+	// Members are named after their group and user, e.g. admins_alice
+	var memberKeys []groupMember
+	bases = nil
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		group := names.groups[groupName]
+		if group == "" {
+			group = toResourceName(groupName, "group")
+		}
+		for _, member := range data.GroupMemberships[groupName] {
+			user := names.users[member]
+			if user == "" {
+				user = toResourceName(member, "user")
+			}
+			memberKeys = append(memberKeys, groupMember{Group: groupName, Username: member})
+			bases = append(bases, group+"_"+user)
+		}
+	}
+	allocateNames(names.members, memberKeys, bases, nil)
+
+	keys, bases = nil, nil
+	for _, idp := range data.IdentityProviders {
+		keys = append(keys, idp.Alias)
+		bases = append(bases, toResourceName(idp.Alias, "identity_provider"))
+	}
+	allocateNames(names.identityProviders, keys, bases, nil)
+
+	return names
+}
+
+// restrictTo returns a copy of n naming only the resources in data, so
+// that references to resources outside data are written as literals.
+func (n *resourceNames) restrictTo(data *InfrastructureData) *resourceNames {
+	restricted := &resourceNames{
+		accounts:       make(map[string]string),
+		permissionSets: make(map[string]string),
+		users:          make(map[string]string),
+		groups:         make(map[string]string),
+		memberships:    make(map[string]string),
+		members:        make(map[groupMember]string),
+
+		identityProviders: make(map[string]string),
+
+		style:           n.style,
+		membershipStyle: n.membershipStyle,
+		module:          n.module,
+		providerAlias:   n.providerAlias,
+	}
+	for _, acc := range data.AWSAccounts {
+		restricted.accounts[acc.AccountID] = n.accounts[acc.AccountID]
+	}
+	for _, ps := range data.PermissionSets {
+		restricted.permissionSets[ps.ID] = n.permissionSets[ps.ID]
+	}
+	for _, user := range data.Users {
+		restricted.users[user.Username] = n.users[user.Username]
+	}
+	for _, group := range data.Groups {
+		restricted.groups[group.Name] = n.groups[group.Name]
+	}
+	for groupName := range data.GroupMemberships {
+		if name, ok := n.memberships[groupName]; ok {
+			restricted.memberships[groupName] = name
+		}
+		for _, member := range data.GroupMemberships[groupName] {
+			key := groupMember{Group: groupName, Username: member}
+			restricted.members[key] = n.members[key]
+		}
+	}
+	for _, idp := range data.IdentityProviders {
+		restricted.identityProviders[idp.Alias] = n.identityProviders[idp.Alias]
+	}
+	return restricted
+}
+
+// allocateNames fills names[keys[i]] with mapped[keys[i]] if set, and
+// otherwise with a unique name based on bases[i].
+func allocateNames[K comparable](names map[K]string, keys []K, bases []string, mapped map[K]string) {
+	allocator := newNameAllocator(bases)
+	for _, key := range keys {
+		if name, ok := mapped[key]; ok {
+			names[key] = name
+			allocator.reserve(name)
+		}
+	}
+	for i, key := range keys {
+		if _, ok := names[key]; ok {
+			// The same resource listed twice keeps one name
+			continue
+		}
+		names[key] = allocator.allocate(bases[i])
+	}
+}
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestToResourceName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Production", "production"},
+		{"John.Smith", "john_smith"},
+		{"  spaced out  ", "spaced_out"},
+		{"123-prod", "account_123_prod"},
+		{"123", "account_123"},
+		{"_9lives", "account_9lives"},
+		{"zoë", "zo"},
+		{"日本語", "account"},
+		{"---", "account"},
+		{"", "account"},
+	}
+
+	for _, tt := range tests {
+		got := toResourceName(tt.in, "account")
+		if got != tt.want {
+			t.Errorf("toResourceName(%q): expected %q, got %q", tt.in, tt.want, got)
+		}
+		if !hclsyntax.ValidIdentifier(got) {
+			t.Errorf("toResourceName(%q) = %q is not a valid identifier", tt.in, got)
+		}
+	}
+}
+
+func TestNameAllocator(t *testing.T) {
+	bases := []string{"john_smith", "john_smith", "john_smith_2", "john_smith", "user"}
+	allocator := newNameAllocator(bases)
+
+	var got []string
+	for _, base := range bases {
+		got = append(got, allocator.allocate(base))
+	}
+
+	// john_smith_2 is taken naturally, so the duplicates skip it
+	want := []string{"john_smith", "john_smith_3", "john_smith_2", "john_smith_4", "user"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// collidingInfrastructure returns resources whose names collide, start with
+// digits, or have nothing left after sanitizing.
+func collidingInfrastructure() *InfrastructureData {
+	return &InfrastructureData{
+		AWSAccounts: []provider.AWSAccount{
+			{ID: "acct-1", AccountID: "111111111111", AccountName: "123-prod"},
+			{ID: "acct-2", AccountID: "222222222222", AccountName: "123 Prod"},
+			{ID: "acct-3", AccountID: "333333333333", AccountName: "本番"},
+		},
+		PermissionSets: []provider.PermissionSet{
+			{ID: "ps-1", Name: "42"},
+		},
+		Users: []provider.User{
+			{ID: "user-1", Username: "john_smith", Email: "js1@example.com", Enabled: true},
+			{ID: "user-2", Username: "John.Smith", Email: "js2@example.com", Enabled: true},
+			{ID: "user-3", Username: "zoë", Email: "zoe1@example.com", Enabled: true},
+			{ID: "user-4", Username: "zoé", Email: "zoe2@example.com", Enabled: true},
+		},
+		Groups: []provider.Group{
+			{ID: "group-1", Name: "Ops"},
+			{ID: "group-2", Name: "ops"},
+			{ID: "group-3", Name: "???"},
+		},
+		GroupMemberships: map[string][]string{
+			"Ops": {"John.Smith"},
+			"ops": {"john_smith"},
+			"???": {"zoé"},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "assign-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "John.Smith", AccountID: "111111111111"},
+			{ID: "assign-2", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "john_smith", AccountID: "222222222222"},
+			{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "john_smith", AccountID: "111111111111"},
+		},
+	}
+}
+
+func TestNewResourceNames(t *testing.T) {
+	names := newResourceNames(sortedData(collidingInfrastructure()), nil)
+
+	tests := []struct {
+		kind string
+		got  map[string]string
+		want map[string]string
+	}{
+		{"accounts", names.accounts, map[string]string{
+			"222222222222": "account_123_prod",
+			"111111111111": "account_123_prod_2",
+			"333333333333": "account",
+		}},
+		{"permission sets", names.permissionSets, map[string]string{"ps-1": "permission_set_42"}},
+		{"users", names.users, map[string]string{
+			"John.Smith": "john_smith",
+			"john_smith": "john_smith_2",
+			"zoé":        "zo",
+			"zoë":        "zo_2",
+		}},
+		{"groups", names.groups, map[string]string{"???": "group", "Ops": "ops", "ops": "ops_2"}},
+		{"memberships", names.memberships, map[string]string{"???": "group_members", "Ops": "ops_members", "ops": "ops_2_members"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.kind, tt.want, tt.got)
+		}
+	}
+}
+
+func TestGenerateFiles_NameCollisions(t *testing.T) {
+	for _, format := range []string{ImportFormatBlocks, ImportFormatScript} {
+		t.Run(format, func(t *testing.T) {
+			dir := generateTestFiles(t, Config{ImportFormat: format}, collidingInfrastructure())
+
+			// Every resource is declared once and imported once
+			declared := matchAll(t, dir, `(?m)^resource "(\w+)" "([^"]*)"`, "*.tf")
+			imported := matchAll(t, dir, `(?m)^  to = (\w+)\.(\w+)$`, "imports.tf")
+			if format == ImportFormatScript {
+				imported = matchAll(t, dir, `(?m)^terraform import (\w+)\.(\w+) `, "import.sh")
+			}
+			if len(declared) != 16 {
+				t.Errorf("expected 16 resources, got %d: %v", len(declared), declared)
+			}
+			if !reflect.DeepEqual(declared, imported) {
+				t.Errorf("declared and imported addresses differ:\ndeclared: %v\nimported: %v", declared, imported)
+			}
+			seen := make(map[string]bool)
+			for _, address := range declared {
+				if seen[address] {
+					t.Errorf("%s is declared twice", address)
+				}
+				seen[address] = true
+			}
+		})
+	}
+}
+
+func TestVariableExtractor_LeadingDigits(t *testing.T) {
+	data := collidingInfrastructure()
+	vars := VariableExtractor{}.Extract(data)
+
+	want := map[string]string{"111111111111": "account_123_prod_2_account_id"}
+	if !reflect.DeepEqual(vars.AccountIDs, want) {
+		t.Errorf("expected variables %v, got %v", want, vars.AccountIDs)
+	}
+}
+
+// matchAll returns the sorted "type.name" addresses matched by pattern in
+// the files of dir matching glob.
+func matchAll(t *testing.T, dir, pattern, glob string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, glob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(pattern)
+
+	var addresses []string
+	for _, file := range files {
+		if filepath.Base(file) == "imports.tf" && glob != "imports.tf" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range re.FindAllStringSubmatch(string(content), -1) {
+			if !hclsyntax.ValidIdentifier(m[2]) {
+				t.Errorf("%s: %q is not a valid resource name", filepath.Base(file), m[2])
+			}
+			addresses = append(addresses, m[1]+"."+m[2])
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"fmt"
+)
+
+// Orphan is a reference in the fetched data to a resource of a fetched kind
+// that isn't there, e.g. an assignment to a deleted user. Generator writes
+// such references as literal values, since there is no resource to refer
+// to; Orphan records where, so that they can be reported.
+type Orphan struct {
+	Address   string // the generated resource holding the reference
+	Attribute string
+	Value     string
+	Reason    string
+}
+
+func (o Orphan) String() string {
+	return fmt.Sprintf("%s: %s %q is written as a literal; %s", o.Address, o.Attribute, o.Value, o.Reason)
+}
+
+// FindOrphans returns the dangling references in data, in the order of the
+// generated files, with addresses named using nameMap (which may be nil).
+// References to kinds that weren't fetched are literals by design and
+// aren't reported.
+func FindOrphans(data *InfrastructureData, kinds ResourceKinds, nameMap *NameMap) []Orphan {
+	data = sortedData(data)
+	names := newResourceNames(data, nameMap)
+
+	var orphans []Orphan
+	check := func(kind string, found map[string]string, address, attribute, value, reason string) {
+		if !kinds[kind] {
+			return
+		}
+		if _, ok := found[value]; !ok {
+			orphans = append(orphans, Orphan{Address: address, Attribute: attribute, Value: value, Reason: reason})
+		}
+	}
+
+	for _, groupName := range sortedKeys(data.GroupMemberships) {
+		members := data.GroupMemberships[groupName]
+		if len(members) == 0 {
+			// Not generated
+			continue
+		}
+		address := "prism_group_membership." + names.memberships[groupName]
+		check(kindGroups, names.groups, address, "group_name", groupName, "no group with this name was fetched")
+		for _, member := range members {
+			check(kindUsers, names.users, address, "usernames", member, "no user with this username was fetched")
+		}
+	}
+
+	for _, assignment := range groupAssignments(data) {
+		address := "prism_permission_set_assignment." + assignment.Name
+		check(kindPermissionSets, names.permissionSets, address, "permission_set_id", assignment.PermissionSetID, "no permission set with this ID was fetched")
+		if assignment.PrincipalType == "USER" {
+			check(kindUsers, names.users, address, "principal_id", assignment.PrincipalID, "no user with this username was fetched")
+		} else {
+			check(kindGroups, names.groups, address, "principal_id", assignment.PrincipalID, "no group with this name was fetched")
+		}
+		for _, accountID := range assignment.AccountIDs {
+			check(kindAWSAccounts, names.accounts, address, "account_ids", accountID, "no AWS account with this ID was fetched")
+		}
+	}
+
+	return orphans
+}
@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// orphanedInfrastructure extends testInfrastructure with references to
+// users, groups, permission sets and accounts that weren't fetched.
+func orphanedInfrastructure() *InfrastructureData {
+	data := testInfrastructure()
+	data.GroupMemberships["Engineering"] = append(data.GroupMemberships["Engineering"], "deleted-user")
+	data.GroupMemberships["Contractors"] = []string{"alice"}
+	data.PermissionSetAssignments = append(data.PermissionSetAssignments,
+		provider.PermissionSetAssignment{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "ghost", AccountID: "111111111111"},
+		provider.PermissionSetAssignment{ID: "assign-4", PermissionSetID: "ps-1", PrincipalType: "GROUP", GroupName: "Former Team", AccountID: "111111111111"},
+		provider.PermissionSetAssignment{ID: "assign-5", PermissionSetID: "ps-deleted", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+	)
+	return data
+}
+
+func TestFindOrphans(t *testing.T) {
+	got := FindOrphans(orphanedInfrastructure(), allResourceKinds(t), nil)
+	want := []Orphan{
+		{Address: "prism_group_membership.contractors_members", Attribute: "group_name", Value: "Contractors", Reason: "no group with this name was fetched"},
+		{Address: "prism_group_membership.engineering_members", Attribute: "usernames", Value: "deleted-user", Reason: "no user with this username was fetched"},
+		{Address: "prism_permission_set_assignment.ps_deleted_alice", Attribute: "permission_set_id", Value: "ps-deleted", Reason: "no permission set with this ID was fetched"},
+		{Address: "prism_permission_set_assignment.readonly_engineering", Attribute: "account_ids", Value: "222222222222", Reason: "no AWS account with this ID was fetched"},
+		{Address: "prism_permission_set_assignment.readonly_former_team", Attribute: "principal_id", Value: "Former Team", Reason: "no group with this name was fetched"},
+		{Address: "prism_permission_set_assignment.readonly_ghost", Attribute: "principal_id", Value: "ghost", Reason: "no user with this username was fetched"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected orphans:\n got: %+v\nwant: %+v", got, want)
+	}
+
+	if got := FindOrphans(testInfrastructure(), allResourceKinds(t), nil); len(got) != 1 || got[0].Value != "222222222222" {
+		t.Errorf("expected only the unlisted account to be reported, got %+v", got)
+	}
+}
+
+func TestFindOrphans_UnfetchedKindsAreNotReported(t *testing.T) {
+	kinds := allResourceKinds(t)
+	delete(kinds, kindUsers)
+	delete(kinds, kindAWSAccounts)
+
+	for _, orphan := range FindOrphans(orphanedInfrastructure(), kinds, nil) {
+		if orphan.Value == "ghost" || orphan.Value == "deleted-user" || orphan.Value == "222222222222" {
+			t.Errorf("expected references to unfetched kinds to be left alone, got %s", orphan)
+		}
+	}
+}
+
+func TestOrphanString(t *testing.T) {
+	orphan := Orphan{Address: "prism_permission_set_assignment.readonly_ghost", Attribute: "principal_id", Value: "ghost", Reason: "no user with this username was fetched"}
+	want := `prism_permission_set_assignment.readonly_ghost: principal_id "ghost" is written as a literal; no user with this username was fetched`
+	if got := orphan.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestGenerateFiles_OrphansAreLiterals(t *testing.T) {
+	for _, style := range []string{StyleFlat, StyleForEach} {
+		t.Run(style, func(t *testing.T) {
+			dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: style}, orphanedInfrastructure())
+			assertGoldenFile(t, filepath.Join(dir, "assignments.tf"), filepath.Join("orphans", style, "assignments.tf"))
+			assertGoldenFile(t, filepath.Join(dir, "groups.tf"), filepath.Join("orphans", style, "groups.tf"))
+			assertReferencesResolve(t, dir)
+		})
+	}
+}
+
+// assertReferencesResolve checks that every reference to a resource or
+// variable in the .tf files in dir is declared there, which is what
+// terraform validate would fail on.
+func assertReferencesResolve(t *testing.T, dir string) {
+	t.Helper()
+
+	declared := map[string]bool{}
+	var references []hcl.Traversal
+	var walk func(body *hclsyntax.Body)
+	walk = func(body *hclsyntax.Body) {
+		for _, attr := range body.Attributes {
+			references = append(references, attr.Expr.Variables()...)
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				declared[block.Labels[0]+"."+block.Labels[1]] = true
+			case block.Type == "variable" && len(block.Labels) == 1:
+				declared["var."+block.Labels[0]] = true
+			case block.Type == "locals":
+				for name := range block.Body.Attributes {
+					declared["local."+name] = true
+				}
+			}
+			walk(block.Body)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".tf" {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, diags := hclsyntax.ParseConfig(src, entry.Name(), hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatalf("parsing %s: %s", entry.Name(), diags)
+		}
+		walk(file.Body.(*hclsyntax.Body))
+	}
+
+	for _, reference := range references {
+		root := reference.RootName()
+		if root != "var" && root != "local" && !strings.HasPrefix(root, "prism_") {
+			continue
+		}
+		attr, ok := reference[1].(hcl.TraverseAttr)
+		if !ok {
+			t.Errorf("unexpected reference %s", hclTraversalString(reference))
+			continue
+		}
+		if !declared[root+"."+attr.Name] {
+			t.Errorf("%s refers to an undeclared resource or variable", hclTraversalString(reference))
+		}
+	}
+}
+
+func hclTraversalString(traversal hcl.Traversal) string {
+	parts := []string{traversal.RootName()}
+	for _, step := range traversal[1:] {
+		if attr, ok := step.(hcl.TraverseAttr); ok {
+			parts = append(parts, attr.Name)
+		}
+	}
+	return strings.Join(parts, ".")
+}
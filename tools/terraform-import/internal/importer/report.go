@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// reportSchemaVersion is the version of the -report-json format. Bump it
+// when a field changes meaning or is removed.
+const reportSchemaVersion = 1
+
+// reportTypes lists the resource types of a report in generation order.
+var reportTypes = []string{
+	"prism_aws_account",
+	"prism_permission_set",
+	"prism_user",
+	"prism_group",
+	"prism_group_membership",
+	"prism_group_member",
+	"prism_permission_set_assignment",
+	"prism_identity_provider",
+}
+
+// Report records what a run generated: every resource with the Prism
+// objects it adopts, what was left out and the warnings printed on the
+// way. It is written by -report-json and summarized at the end of a run.
+type Report struct {
+	SchemaVersion  int              `json:"schema_version"`
+	PrismSubdomain string           `json:"prism_subdomain,omitempty"`
+	GeneratedAt    time.Time        `json:"generated_at"`
+	OutputDir      string           `json:"output_dir"`
+	Counts         map[string]int   `json:"counts"` // resource type -> resources
+	Resources      []ReportResource `json:"resources"`
+	Skipped        ReportSkipped    `json:"skipped"`
+	Warnings       []string         `json:"warnings"`
+}
+
+// ReportResource is one generated resource.
+type ReportResource struct {
+	Type     string   `json:"type"`
+	Address  string   `json:"address"`
+	ImportID string   `json:"import_id"`
+	PrismIDs []string `json:"prism_ids"` // the Prism objects the resource adopts
+
+	// Directory is the configuration holding the resource with
+	// -output-layout=by-group-path, relative to the output directory
+	Directory string `json:"directory,omitempty"`
+}
+
+// ReportSkipped counts the objects left out of the generated files.
+type ReportSkipped struct {
+	NotFetched     []string `json:"not_fetched"`     // kinds excluded, or that failed to fetch
+	FilteredUsers  int      `json:"filtered_users"`  // by -user-filter
+	FilteredGroups int      `json:"filtered_groups"` // by -group-filter
+	DisabledUsers  int      `json:"disabled_users"`
+	EmptyGroups    int      `json:"empty_groups"`
+	Memberships    int      `json:"memberships"` // of disabled users
+	Assignments    int      `json:"assignments"` // of filtered or skipped principals
+}
+
+// String describes the skipped objects, or is "nothing" when there are
+// none.
+func (s ReportSkipped) String() string {
+	var parts []string
+	if len(s.NotFetched) > 0 {
+		parts = append(parts, "kinds not fetched: "+strings.Join(s.NotFetched, ", "))
+	}
+	for _, count := range []struct {
+		n    int
+		noun string
+	}{
+		{s.FilteredUsers, "users filtered out"},
+		{s.FilteredGroups, "groups filtered out"},
+		{s.DisabledUsers, "disabled users"},
+		{s.EmptyGroups, "empty groups"},
+		{s.Memberships, "group memberships"},
+		{s.Assignments, "assignments"},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.noun))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// NewReport lists the resources Generator generates for data with config,
+// in import order. The caller fills in Skipped and Warnings.
+func NewReport(config Config, data *InfrastructureData) *Report {
+	report := &Report{
+		SchemaVersion:  reportSchemaVersion,
+		PrismSubdomain: config.PrismSubdomain,
+		GeneratedAt:    time.Now().UTC(),
+		OutputDir:      config.OutputDir,
+		Counts:         make(map[string]int),
+		Resources:      []ReportResource{},
+		Skipped:        ReportSkipped{NotFetched: []string{}},
+		Warnings:       []string{},
+	}
+	for _, dir := range ImportDirectories(config, data) {
+		directory := ""
+		if config.OutputLayout == OutputLayoutByGroupPath {
+			directory = dir.Dir
+		}
+		for _, target := range dir.Targets {
+			report.Resources = append(report.Resources, ReportResource{
+				Type:      target.Type,
+				Address:   target.Address,
+				ImportID:  target.ID,
+				PrismIDs:  append([]string{}, target.PrismIDs...),
+				Directory: directory,
+			})
+			report.Counts[target.Type]++
+		}
+	}
+	return report
+}
+
+// WriteJSON writes the report to path.
+func (r *Report) WriteJSON(path string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	// Import IDs hold usernames, which are often email addresses
+	if err := os.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteSummary writes a table of the resources generated per type,
+// followed by what was skipped and the number of warnings.
+func (r *Report) WriteSummary(w io.Writer) {
+	const typeWidth = len("prism_permission_set_assignment")
+	fmt.Fprintf(w, "  %-*s  %6s\n", typeWidth, "Resource type", "Count")
+	for _, resourceType := range reportTypes {
+		if n := r.Counts[resourceType]; n > 0 {
+			fmt.Fprintf(w, "  %-*s  %6d\n", typeWidth, resourceType, n)
+		}
+	}
+	fmt.Fprintf(w, "  %-*s  %6d\n", typeWidth, "Total", len(r.Resources))
+	fmt.Fprintf(w, "  Skipped: %s\n", r.Skipped)
+	fmt.Fprintf(w, "  Warnings: %d\n", len(r.Warnings))
+}
+
+// WriteTenantSummary writes a table of the resources and warnings of each
+// tenant's report, with their totals.
+func WriteTenantSummary(w io.Writer, reports []*Report) {
+	tenantWidth := len("Tenant")
+	for _, r := range reports {
+		tenantWidth = max(tenantWidth, len(r.PrismSubdomain))
+	}
+
+	resources, warnings := 0, 0
+	fmt.Fprintf(w, "  %-*s  %9s  %8s\n", tenantWidth, "Tenant", "Resources", "Warnings")
+	for _, r := range reports {
+		fmt.Fprintf(w, "  %-*s  %9d  %8d\n", tenantWidth, r.PrismSubdomain, len(r.Resources), len(r.Warnings))
+		resources += len(r.Resources)
+		warnings += len(r.Warnings)
+	}
+	fmt.Fprintf(w, "  %-*s  %9d  %8d\n", tenantWidth, "Total", resources, warnings)
+}
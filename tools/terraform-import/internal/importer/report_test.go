@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewReport_JSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "split", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSplit}},
+		{name: "by-group-path", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleForEach, OutputLayout: OutputLayoutByGroupPath}},
+		{name: "individual-members", config: Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSplit, MembershipStyle: MembershipStyleIndividual}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.PrismSubdomain = "acme"
+			tt.config.OutputDir = "generated-terraform"
+			report := NewReport(tt.config, teamInfrastructure())
+			report.GeneratedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			report.Skipped = ReportSkipped{NotFetched: []string{kindIdentityProviders}, DisabledUsers: 1, Assignments: 2}
+			report.Warnings = []string{"failed to fetch members for 1 groups:\n- Ops: API error (500)"}
+
+			path := filepath.Join(t.TempDir(), "report.json")
+			if err := report.WriteJSON(path); err != nil {
+				t.Fatal(err)
+			}
+			assertGoldenFile(t, path, filepath.Join("report", tt.name+".json"))
+		})
+	}
+}
+
+func TestNewReport_MapsEveryPrismObject(t *testing.T) {
+	data := teamInfrastructure()
+	report := NewReport(Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSplit}, data)
+
+	addresses := make(map[string][]string)
+	for _, resource := range report.Resources {
+		for _, id := range resource.PrismIDs {
+			addresses[id] = append(addresses[id], resource.Address)
+		}
+	}
+
+	want := map[string][]string{
+		"acct-1":   {"prism_aws_account.production"},
+		"ps-2":     {"prism_permission_set.admin"},
+		"user-4":   {"prism_user.dave"},
+		"group-1":  {"prism_group.platform", "prism_group_membership.platform_members"},
+		"assign-1": {"prism_permission_set_assignment.readonly_platform"},
+		"assign-2": {"prism_permission_set_assignment.readonly_platform"},
+	}
+	for id, wantAddresses := range want {
+		if !reflect.DeepEqual(addresses[id], wantAddresses) {
+			t.Errorf("expected %s to map to %v, got %v", id, wantAddresses, addresses[id])
+		}
+	}
+
+	// Every object that becomes a resource of its own is in the report
+	var ids []string
+	for _, acc := range data.AWSAccounts {
+		ids = append(ids, acc.ID)
+	}
+	for _, ps := range data.PermissionSets {
+		ids = append(ids, ps.ID)
+	}
+	for _, user := range data.Users {
+		ids = append(ids, user.ID)
+	}
+	for _, group := range data.Groups {
+		ids = append(ids, group.ID)
+	}
+	for _, assignment := range data.PermissionSetAssignments {
+		ids = append(ids, assignment.ID)
+	}
+	for _, id := range ids {
+		if len(addresses[id]) == 0 {
+			t.Errorf("expected %s in the report", id)
+		}
+	}
+
+	if report.Counts["prism_permission_set_assignment"] != 6 || len(report.Resources) != 21 {
+		t.Errorf("expected 6 assignments of 21 resources, got %d of %d", report.Counts["prism_permission_set_assignment"], len(report.Resources))
+	}
+}
+
+func TestNewReport_MatchesImports(t *testing.T) {
+	config := Config{ImportFormat: ImportFormatBlocks, Style: StyleForEach, OutputLayout: OutputLayoutByGroupPath}
+	report := NewReport(config, teamInfrastructure())
+
+	var targets []ImportTarget
+	for _, dir := range ImportDirectories(config, teamInfrastructure()) {
+		targets = append(targets, dir.Targets...)
+	}
+	if len(targets) != len(report.Resources) {
+		t.Fatalf("expected %d resources, got %d", len(targets), len(report.Resources))
+	}
+	for i, target := range targets {
+		if resource := report.Resources[i]; resource.Address != target.Address || resource.ImportID != target.ID {
+			t.Errorf("resource %d: expected %s (%s), got %s (%s)", i, target.Address, target.ID, resource.Address, resource.ImportID)
+		}
+	}
+}
+
+func TestReport_WriteSummary(t *testing.T) {
+	report := NewReport(Config{ImportFormat: ImportFormatBlocks, Style: StyleFlat, OutputLayout: OutputLayoutSplit}, teamInfrastructure())
+	report.Skipped = ReportSkipped{NotFetched: []string{kindIdentityProviders}, FilteredUsers: 3, Memberships: 1}
+	report.Warnings = []string{"one", "two"}
+
+	var out bytes.Buffer
+	report.WriteSummary(&out)
+	want := `  Resource type                     Count
+  prism_aws_account                     2
+  prism_permission_set                  2
+  prism_user                            4
+  prism_group                           4
+  prism_group_membership                3
+  prism_permission_set_assignment       6
+  Total                                21
+  Skipped: kinds not fetched: identity_providers; 3 users filtered out; 1 group memberships
+  Warnings: 2
+`
+	if out.String() != want {
+		t.Errorf("unexpected summary:\n--- got ---\n%s\n--- want ---\n%s", out.String(), want)
+	}
+}
+
+func TestWriteTenantSummary(t *testing.T) {
+	acme := NewReport(Config{PrismSubdomain: "acme"}, teamInfrastructure())
+	globex := NewReport(Config{PrismSubdomain: "globex-eu"}, testInfrastructure())
+	globex.Warnings = []string{"one"}
+
+	var out bytes.Buffer
+	WriteTenantSummary(&out, []*Report{acme, globex})
+	want := fmt.Sprintf(`  Tenant     Resources  Warnings
+  acme              21         0
+  globex-eu  %9d         1
+  Total      %9d         1
+`, len(globex.Resources), 21+len(globex.Resources))
+	if out.String() != want {
+		t.Errorf("unexpected summary:\n--- got ---\n%s\n--- want ---\n%s", out.String(), want)
+	}
+}
+
+func TestReport_WriteJSONIsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := NewReport(Config{}, &InfrastructureData{}).WriteJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected mode 0600, got %s", perm)
+	}
+
+	var decoded map[string]any
+	content, _ := os.ReadFile(path)
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	// Empty lists are written as lists, not null, for the tracker
+	for _, key := range []string{"resources", "warnings"} {
+		if _, ok := decoded[key].([]any); !ok {
+			t.Errorf("expected %s to be a list, got %v", key, decoded[key])
+		}
+	}
+}
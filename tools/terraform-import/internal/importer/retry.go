@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// RetryPolicy controls how Fetcher retries requests that were rate limited
+// or failed with a server or network error.
+type RetryPolicy struct {
+	MaxAttempts int           // including the first; below 2 means no retries
+	BaseDelay   time.Duration // doubled after each retry, unless the API sends Retry-After
+	MaxDelay    time.Duration // caps both the backoff and Retry-After; zero means no cap
+}
+
+// DefaultRetryPolicy rides out a burst of 429s without holding up a run
+// against an API that is down for much longer than a minute.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// delay returns how long to wait before retry number n (from 1) after err.
+func (p RetryPolicy) delay(n int, err error) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	var apiErr *provider.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		d = apiErr.RetryAfter
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// isRetryable reports whether err may go away when the request is repeated:
+// rate limiting, a gateway or server error, or a failure to reach the API
+// other than a host that doesn't exist.
+func isRetryable(err error) bool {
+	var apiErr *provider.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// retryingClient retries the calls of a Client according to policy.
+type retryingClient struct {
+	client Client
+	policy RetryPolicy
+
+	// sleep waits between attempts; retried reports each retry
+	sleep   func(time.Duration)
+	retried func(what string, attempt int, delay time.Duration, err error)
+}
+
+// retryingClient wraps f.Client to retry according to f.Retry, reporting
+// each retry through progress.
+func (f *Fetcher) retryingClient(progress func(format string, args ...interface{})) *retryingClient {
+	sleep := f.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	return &retryingClient{
+		client: f.Client,
+		policy: f.Retry,
+		sleep:  sleep,
+		retried: func(what string, attempt int, delay time.Duration, err error) {
+			progress("    Retrying %s in %s (attempt %d of %d failed): %s\n", what, delay, attempt, f.Retry.MaxAttempts, err)
+		},
+	}
+}
+
+// retryCall calls call until it succeeds, fails with an error that isn't
+// retryable, or c.policy.MaxAttempts are used up, and returns the last
+// result.
+func retryCall[T any](c *retryingClient, what string, call func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := call()
+		if err == nil || attempt >= c.policy.MaxAttempts || !isRetryable(err) {
+			return result, err
+		}
+		delay := c.policy.delay(attempt, err)
+		c.retried(what, attempt, delay, err)
+		c.sleep(delay)
+	}
+}
+
+func (c *retryingClient) ListAWSAccounts() ([]provider.AWSAccount, error) {
+	return retryCall(c, "AWS accounts", c.client.ListAWSAccounts)
+}
+
+func (c *retryingClient) ListPermissionSets() ([]provider.PermissionSet, error) {
+	return retryCall(c, "permission sets", c.client.ListPermissionSets)
+}
+
+func (c *retryingClient) ListUsers() ([]provider.User, error) {
+	return retryCall(c, "users", c.client.ListUsers)
+}
+
+func (c *retryingClient) ListGroups() ([]provider.Group, error) {
+	return retryCall(c, "groups", c.client.ListGroups)
+}
+
+func (c *retryingClient) GetGroupMembers(groupName string) ([]string, error) {
+	return retryCall(c, "members of group "+groupName, func() ([]string, error) {
+		return c.client.GetGroupMembers(groupName)
+	})
+}
+
+func (c *retryingClient) ListPermissionSetAssignments() ([]provider.PermissionSetAssignment, error) {
+	return retryCall(c, "permission set assignments", c.client.ListPermissionSetAssignments)
+}
+
+func (c *retryingClient) ListIdentityProviders() ([]provider.IdentityProvider, error) {
+	return retryCall(c, "identity providers", c.client.ListIdentityProviders)
+}
@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	plain := &provider.APIError{StatusCode: http.StatusServiceUnavailable}
+	limited := &provider.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}
+
+	tests := []struct {
+		retry int
+		err   error
+		want  time.Duration
+	}{
+		{retry: 1, err: plain, want: time.Second},
+		{retry: 2, err: plain, want: 2 * time.Second},
+		{retry: 3, err: plain, want: 4 * time.Second},
+		{retry: 4, err: plain, want: 5 * time.Second},
+		{retry: 1, err: limited, want: 3 * time.Second},
+		{retry: 4, err: limited, want: 3 * time.Second},
+		{retry: 1, err: &provider.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}, want: 5 * time.Second},
+		{retry: 1, err: fmt.Errorf("GET /users: %w", limited), want: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.delay(tt.retry, tt.err); got != tt.want {
+			t.Errorf("retry %d after %v: expected %s, got %s", tt.retry, tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"rate limited": {err: &provider.APIError{StatusCode: http.StatusTooManyRequests}, want: true},
+		"unavailable":  {err: &provider.APIError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		"wrapped":      {err: fmt.Errorf("listing: %w", &provider.APIError{StatusCode: http.StatusBadGateway}), want: true},
+		"network":      {err: fmt.Errorf("GET /users: failed to execute request: %w", &url.Error{Op: "Get", URL: "https://prism", Err: errors.New("connection reset")}), want: true},
+		"no such host": {err: &url.Error{Op: "Get", URL: "https://prism.invalid", Err: &net.DNSError{Err: "no such host", Name: "prism.invalid", IsNotFound: true}}, want: false},
+		"not found":    {err: &provider.APIError{StatusCode: http.StatusNotFound}, want: false},
+		"unauthorized": {err: &provider.APIError{StatusCode: http.StatusUnauthorized}, want: false},
+		"bad response": {err: errors.New("failed to unmarshal API response"), want: false},
+	}
+	for name, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: expected %t, got %t", name, tt.want, got)
+		}
+	}
+}
+
+// flakyResponses makes the fake Prism API fail the first requests to some
+// paths, and counts the requests to each path.
+type flakyResponses struct {
+	mu       sync.Mutex
+	failures map[string][]int // path -> statuses to return, in order; 429s ask to wait 3s
+	requests map[string]int
+}
+
+func (f *flakyResponses) intercept(w http.ResponseWriter, path string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.requests[path]
+	f.requests[path]++
+	statuses := f.failures[path]
+	if n >= len(statuses) {
+		return false
+	}
+	if statuses[n] == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "3")
+	}
+	w.WriteHeader(statuses[n])
+	fmt.Fprintf(w, `{"success":false,"error":"status %d"}`, statuses[n])
+	return true
+}
+
+func (f *flakyResponses) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path]
+}
+
+func TestFetcher_RetriesFlakyServer(t *testing.T) {
+	data := testInfrastructure()
+	flaky := &flakyResponses{
+		failures: map[string][]int{
+			"/users":                      {http.StatusTooManyRequests, http.StatusTooManyRequests},
+			"/groups/Engineering/members": {http.StatusServiceUnavailable},
+		},
+		requests: make(map[string]int),
+	}
+	client, _ := newInterceptedFakePrism(t, data, flaky.intercept)
+
+	var mu sync.Mutex
+	var sleeps []time.Duration
+	var out strings.Builder
+	fetcher := &Fetcher{
+		Client:      client,
+		Kinds:       allResourceKinds(t),
+		Concurrency: 1,
+		Retry:       RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond},
+		Out:         &out,
+		sleep: func(d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			sleeps = append(sleeps, d)
+		},
+	}
+	got, err := fetcher.Fetch()
+	if err != nil {
+		t.Fatalf("expected the retries to succeed, got %v", err)
+	}
+	if !reflect.DeepEqual(got.Users, data.Users) || !reflect.DeepEqual(got.GroupMemberships, data.GroupMemberships) {
+		t.Errorf("expected the users and memberships, got %+v", got)
+	}
+
+	if n := flaky.count("/users"); n != 3 {
+		t.Errorf("expected 3 requests for users, got %d", n)
+	}
+	// Retry-After wins over the backoff
+	want := []time.Duration{3 * time.Second, 3 * time.Second, 10 * time.Millisecond}
+	if !reflect.DeepEqual(sleeps, want) {
+		t.Errorf("expected sleeps %v, got %v", want, sleeps)
+	}
+	for _, line := range []string{"Retrying users in 3s (attempt 1 of 3 failed)", "Retrying members of group Engineering in 10ms (attempt 1 of 3 failed)"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected progress to contain %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestFetcher_RetriesGiveUp(t *testing.T) {
+	flaky := &flakyResponses{
+		failures: map[string][]int{
+			"/permission-sets":    {503, 503, 503, 503},
+			"/identity-providers": {http.StatusForbidden},
+		},
+		requests: make(map[string]int),
+	}
+	client, _ := newInterceptedFakePrism(t, testInfrastructure(), flaky.intercept)
+
+	fetcher := &Fetcher{
+		Client:      client,
+		Kinds:       allResourceKinds(t),
+		Concurrency: 2,
+		Retry:       RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+		sleep:       func(time.Duration) {},
+	}
+	_, err := fetcher.Fetch()
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Failures) != 2 {
+		t.Fatalf("expected permission sets and identity providers to fail, got %v", err)
+	}
+	if n := flaky.count("/permission-sets"); n != 3 {
+		t.Errorf("expected 3 attempts for permission sets, got %d", n)
+	}
+	if n := flaky.count("/identity-providers"); n != 1 {
+		t.Errorf("expected a 403 not to be retried, got %d requests", n)
+	}
+	var apiErr *provider.APIError
+	if !errors.As(partial.Failures[0].Err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last 503 for permission sets, got %v", partial.Failures[0].Err)
+	}
+}
@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ImportCheckpointFile records, in a configuration's directory, the
+// addresses TerraformRunner has imported one at a time, so that a run that
+// stopped at a failure picks up where it left off.
+const ImportCheckpointFile = ".prism-import-checkpoint"
+
+// importPlanFile is the plan TerraformRunner applies with import blocks.
+const importPlanFile = "prism-import.tfplan"
+
+// ImportDirectory is a configuration that Generator writes and the
+// resources to import into its state.
+type ImportDirectory struct {
+	Dir     string // relative to the output directory; "." for the only one
+	Targets []ImportTarget
+}
+
+// ImportDirectories returns the configurations Generator writes for data
+// with config, in the order to import them.
+func ImportDirectories(config Config, data *InfrastructureData) []ImportDirectory {
+	data = sortedData(data)
+	names := generatorNames(config, data)
+	if config.OutputLayout != OutputLayoutByGroupPath {
+		return []ImportDirectory{{Dir: ".", Targets: importTargets(data, names)}}
+	}
+
+	var dirs []ImportDirectory
+	for _, partition := range partitionByGroupPath(data) {
+		dirs = append(dirs, ImportDirectory{
+			Dir:     partition.Dir,
+			Targets: importTargets(partition.Data, names.restrictTo(partition.Data)),
+		})
+	}
+	return dirs
+}
+
+// importTargets lists the import targets of every section, in order.
+func importTargets(data *InfrastructureData, names *resourceNames) []ImportTarget {
+	var targets []ImportTarget
+	for _, section := range importSections(data, names) {
+		targets = append(targets, section.Targets...)
+	}
+	return targets
+}
+
+// TerraformRunner runs terraform in a generated configuration to import its
+// resources, streaming terraform's output.
+type TerraformRunner struct {
+	Terraform    string // path to the terraform binary
+	ImportFormat string // how the imports were generated
+	Stdout       io.Writer
+	Stderr       io.Writer
+}
+
+// Workspace returns the Terraform workspace selected in dir.
+func (r *TerraformRunner) Workspace(dir string) (string, error) {
+	cmd := exec.Command(r.Terraform, "workspace", "show")
+	cmd.Dir = dir
+	cmd.Stderr = r.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("terraform workspace show failed in %s: %w", dir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Import runs terraform init in dir and imports targets. Import blocks are
+// applied with plan and apply, which Terraform can simply run again after a
+// failure. With the import script, each target is imported with terraform
+// import and recorded in ImportCheckpointFile, and targets recorded by an
+// earlier run are skipped. Import stops at the first command that fails.
+func (r *TerraformRunner) Import(dir string, targets []ImportTarget) error {
+	if err := r.terraform(dir, "init", "-input=false"); err != nil {
+		return err
+	}
+
+	if r.ImportFormat != ImportFormatScript {
+		defer os.Remove(filepath.Join(dir, importPlanFile))
+		if err := r.terraform(dir, "plan", "-input=false", "-out="+importPlanFile); err != nil {
+			return err
+		}
+		return r.terraform(dir, "apply", "-input=false", importPlanFile)
+	}
+
+	checkpointPath := filepath.Join(dir, ImportCheckpointFile)
+	done, err := readCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := os.OpenFile(checkpointPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer checkpoint.Close()
+
+	for _, target := range targets {
+		if done[target.Address] {
+			continue
+		}
+		if err := r.terraform(dir, "import", "-input=false", target.Address, target.ID); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(checkpoint, target.Address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// terraform runs terraform with args in dir, echoing the command first.
+func (r *TerraformRunner) terraform(dir string, args ...string) error {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if strings.ContainsAny(arg, " \"'[]") {
+			quoted[i] = shellQuote(arg)
+		}
+	}
+	command := "terraform " + strings.Join(quoted, " ")
+	fmt.Fprintf(r.Stdout, "$ %s\n", command)
+
+	cmd := exec.Command(r.Terraform, args...)
+	cmd.Dir = dir
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed in %s: %w", command, dir, err)
+	}
+	return nil
+}
+
+// readCheckpoint returns the addresses recorded in the checkpoint file at
+// path, which may not exist yet.
+func readCheckpoint(path string) (map[string]bool, error) {
+	done := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if address := strings.TrimSpace(scanner.Text()); address != "" {
+			done[address] = true
+		}
+	}
+	return done, scanner.Err()
+}
@@ -0,0 +1,11 @@
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = var.production_account_id
+  account_name = "Production"
+}
+
+resource "prism_aws_account" "staging" {
+  account_id   = var.staging_account_id
+  account_name = "Staging"
+}
@@ -0,0 +1,95 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.production
+  id = "111111111111"
+}
+
+import {
+  to = prism_aws_account.staging
+  id = "222222222222"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.admin
+  id = "ps-2"
+}
+
+import {
+  to = prism_permission_set.readonly
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.alice
+  id = "alice"
+}
+
+import {
+  to = prism_user.bob
+  id = "bob"
+}
+
+# Groups
+
+import {
+  to = prism_group.engineering
+  id = "Engineering"
+}
+
+import {
+  to = prism_group.operations
+  id = "Operations"
+}
+
+import {
+  to = prism_group.support
+  id = "Support"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.engineering_members
+  id = "Engineering"
+}
+
+import {
+  to = prism_group_membership.operations_members
+  id = "Operations"
+}
+
+import {
+  to = prism_group_membership.support_members
+  id = "Support"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.admin_alice
+  id = "assign-3"
+}
+
+import {
+  to = prism_permission_set_assignment.admin_operations
+  id = "assign-4"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_engineering
+  id = "assign-1,assign-2"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_support
+  id = "assign-5"
+}
@@ -0,0 +1,9 @@
+# Permission Sets
+
+resource "prism_permission_set" "admin" {
+  name = "Admin"
+}
+
+resource "prism_permission_set" "readonly" {
+  name = "ReadOnly"
+}
@@ -0,0 +1,13 @@
+# Users
+
+resource "prism_user" "alice" {
+  username = "alice"
+  email    = "alice@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "bob" {
+  username = "bob"
+  email    = "bob@example.com"
+  enabled  = true
+}
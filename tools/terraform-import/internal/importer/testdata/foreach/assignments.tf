@@ -0,0 +1,24 @@
+# Permission Set Assignments
+
+locals {
+  permission_set_assignments = {
+    readonly_engineering = {
+      permission_set_id = prism_permission_set.readonly.id
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["engineering"].name
+      account_ids = [
+        prism_aws_account.production.account_id,
+        "222222222222",
+      ]
+    }
+  }
+}
+
+resource "prism_permission_set_assignment" "this" {
+  for_each = local.permission_set_assignments
+
+  permission_set_id = each.value.permission_set_id
+  principal_type    = each.value.principal_type
+  principal_id      = each.value.principal_id
+  account_ids       = each.value.account_ids
+}
@@ -0,0 +1,7 @@
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = "111111111111"
+  account_name = "Production"
+  region       = "us-east-1"
+}
@@ -0,0 +1,11 @@
+# Permission Sets
+
+resource "prism_permission_set" "readonly" {
+  name             = "ReadOnly"
+  description      = "Read-only access"
+  session_duration = "PT4H"
+
+  managed_policies = [
+    "arn:aws:iam::aws:policy/ReadOnlyAccess",
+  ]
+}
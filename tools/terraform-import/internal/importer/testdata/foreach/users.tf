@@ -0,0 +1,33 @@
+# Users
+
+locals {
+  users = {
+    alice = {
+      username   = "alice"
+      email      = "alice@example.com"
+      first_name = "Alice"
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+    o_brien = {
+      username   = "o'brien"
+      email      = "obrien@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = false
+      attributes = null
+    }
+  }
+}
+
+resource "prism_user" "this" {
+  for_each = local.users
+
+  username   = each.value.username
+  email      = each.value.email
+  first_name = each.value.first_name
+  last_name  = each.value.last_name
+  enabled    = each.value.enabled
+  attributes = each.value.attributes
+}
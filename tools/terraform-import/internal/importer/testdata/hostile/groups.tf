@@ -0,0 +1,20 @@
+# Groups
+
+resource "prism_group" "ops_env" {
+  name        = "ops/$${env}"
+  description = "Tab\tand \"quote\""
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "ops_env_members" {
+  group_name = prism_group.ops_env.name
+  usernames = [
+    prism_user.zo.username,
+  ]
+}
@@ -0,0 +1,31 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.dev_admin
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.zo
+  id = "zoë"
+}
+
+# Groups
+
+import {
+  to = prism_group.ops_env
+  id = "ops/$${env}"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.ops_env_members
+  id = "ops/$${env}"
+}
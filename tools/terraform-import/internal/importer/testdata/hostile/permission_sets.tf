@@ -0,0 +1,35 @@
+# Permission Sets
+
+resource "prism_permission_set" "dev_admin" {
+  name        = "Dev \"Admin\""
+  description = "Line one\nLine two with $${var.x} and %%{if}"
+
+  inline_policies = {
+    home-dir    = <<-EOT
+      {
+        "Version": "2012-10-17",
+        "Statement": [
+          {
+            "Effect": "Allow",
+            "Action": "s3:*",
+            "Resource": "arn:aws:s3:::home/$${aws:username}/*",
+            "Condition": {
+              "StringLike": {
+                "s3:prefix": [
+                  "%%{x}",
+                  "EOT"
+                ]
+              }
+            }
+          }
+        ]
+      }
+    EOT
+    not_json    = "C:\\path\\$${x}"
+    "ünïcødé 🚀" = <<-EOT
+      {
+        "a": "b"
+      }
+    EOT
+  }
+}
@@ -0,0 +1,13 @@
+# Users
+
+resource "prism_user" "zo" {
+  username  = "zoë"
+  email     = "\"quoted\"@example.com"
+  last_name = "Back\\slash"
+  enabled   = true
+
+  attributes = {
+    cost-center = "$${cc}"
+    team        = "Ünïcode"
+  }
+}
@@ -0,0 +1,26 @@
+# Identity Providers
+# Secrets can't be exported; set them in terraform.tfvars.
+
+resource "prism_identity_provider" "google" {
+  type         = "google"
+  display_name = "Sign in with Google"
+  enabled      = true
+
+  config = jsonencode({
+    clientId     = "google-client-id"
+    clientSecret = var.google_client_secret
+    hostedDomain = "example.com"
+  })
+}
+
+resource "prism_identity_provider" "microsoft" {
+  type         = "microsoft"
+  display_name = "Sign in with Microsoft"
+  enabled      = false
+
+  config = jsonencode({
+    clientId     = "azure-client-id"
+    clientSecret = var.microsoft_client_secret
+    tenantId     = var.microsoft_tenant_id
+  })
+}
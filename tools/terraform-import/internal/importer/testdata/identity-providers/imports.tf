@@ -0,0 +1,15 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# Identity Providers
+
+import {
+  to = prism_identity_provider.google
+  id = "google:google"
+}
+
+import {
+  to = prism_identity_provider.microsoft
+  id = "microsoft:microsoft"
+}
@@ -0,0 +1,39 @@
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
+
+variable "prism_base_url" {
+  type        = string
+  description = "Prism base URL, without port"
+}
+
+# Identity Provider Variables
+
+variable "google_client_secret" {
+  type        = string
+  description = "clientSecret of the google identity provider"
+  sensitive   = true
+}
+
+variable "microsoft_client_secret" {
+  type        = string
+  description = "clientSecret of the microsoft identity provider"
+  sensitive   = true
+}
+
+variable "microsoft_tenant_id" {
+  type        = string
+  description = "tenantId of the microsoft identity provider"
+  sensitive   = false
+}
@@ -0,0 +1,11 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "readonly_engineering" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.engineering.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+    "222222222222",
+  ]
+}
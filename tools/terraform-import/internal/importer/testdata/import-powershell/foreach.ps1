@@ -0,0 +1,58 @@
+﻿# Terraform import script for PowerShell - generated automatically
+# This script imports existing resources into Terraform state
+
+$ErrorActionPreference = 'Stop'
+
+# Windows PowerShell and PowerShell before 7.3 drop double quotes from
+# arguments to native commands unless they are escaped
+$EscapeQuotes = $PSVersionTable.PSVersion -lt [version]'7.3' -or $PSNativeCommandArgumentPassing -eq 'Legacy'
+
+function Import-Resource([string]$Address, [string]$Id) {
+    if ($EscapeQuotes) {
+        $Address = $Address -replace '"', '\"'
+        $Id = $Id -replace '"', '\"'
+    }
+    & terraform import $Address $Id
+    # Stop on the first failure, like set -e in import.sh
+    if ($LASTEXITCODE -ne 0) {
+        throw "terraform import $Address failed with exit code $LASTEXITCODE"
+    }
+}
+
+Write-Host "Starting Terraform import process..."
+
+# Import AWS Accounts
+Write-Host "Importing AWS accounts..."
+Import-Resource 'prism_aws_account.production' '111111111111'
+
+# Import Permission Sets
+Write-Host "Importing permission sets..."
+Import-Resource 'prism_permission_set.readonly' 'ps-1'
+
+# Import Users
+Write-Host "Importing users..."
+Import-Resource 'prism_user.this["alice"]' 'alice'
+Import-Resource 'prism_user.this["o_brien"]' 'o''brien'
+
+# Import Groups
+Write-Host "Importing groups..."
+Import-Resource 'prism_group.this["engineering"]' 'Engineering'
+
+# Import Group Memberships
+Write-Host "Importing group memberships..."
+Import-Resource 'prism_group_membership.this["engineering_members"]' 'Engineering'
+
+# Import Permission Set Assignments
+Write-Host "Importing permission set assignments..."
+Import-Resource 'prism_permission_set_assignment.this["readonly_engineering"]' 'assign-1,assign-2'
+
+# Import Identity Providers
+Write-Host "Importing identity providers..."
+Import-Resource 'prism_identity_provider.google' 'google:google'
+Import-Resource 'prism_identity_provider.microsoft' 'microsoft:microsoft'
+
+Write-Host "Import complete!"
+Write-Host "Next steps:"
+Write-Host "  1. Run: terraform plan"
+Write-Host "  2. Review any differences"
+Write-Host "  3. Run: terraform apply (if needed)"
@@ -0,0 +1,38 @@
+#!/bin/bash
+# Terraform import script - generated automatically
+# This script imports existing resources into Terraform state
+
+set -e
+
+echo "Starting Terraform import process..."
+
+# Import AWS Accounts
+echo "Importing AWS accounts..."
+terraform import prism_aws_account.production '111111111111'
+
+# Import Permission Sets
+echo "Importing permission sets..."
+terraform import prism_permission_set.readonly 'ps-1'
+
+# Import Users
+echo "Importing users..."
+terraform import prism_user.alice 'alice'
+terraform import prism_user.o_brien 'o'\''brien'
+
+# Import Groups
+echo "Importing groups..."
+terraform import prism_group.engineering 'Engineering'
+
+# Import Group Memberships
+echo "Importing group memberships..."
+terraform import prism_group_membership.engineering_members 'Engineering'
+
+# Import Permission Set Assignments
+echo "Importing permission set assignments..."
+terraform import prism_permission_set_assignment.readonly_engineering 'assign-1,assign-2'
+
+echo "✅ Import complete!"
+echo "Next steps:"
+echo "  1. Run: terraform plan"
+echo "  2. Review any differences"
+echo "  3. Run: terraform apply (if needed)"
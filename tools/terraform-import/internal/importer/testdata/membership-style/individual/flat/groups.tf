@@ -0,0 +1,25 @@
+# Groups
+
+resource "prism_group" "engineering" {
+  name        = "Engineering"
+  description = "All engineers"
+}
+
+# Group Members
+#
+# Each prism_group_member adds one user to one group and leaves the group's
+# other members alone, so members added any other way, such as by SCIM or
+# onboarding automation, are kept. Terraform doesn't report them either, and
+# removing a user from a group takes removing their prism_group_member. For
+# one authoritative list of members per group, generate with
+# -membership-style=authoritative instead.
+
+resource "prism_group_member" "engineering_alice" {
+  group_name = prism_group.engineering.name
+  username   = prism_user.alice.username
+}
+
+resource "prism_group_member" "engineering_o_brien" {
+  group_name = prism_group.engineering.name
+  username   = prism_user.o_brien.username
+}
@@ -0,0 +1,44 @@
+# Groups and Group Members
+#
+# Each prism_group_member adds one user to one group and leaves the group's
+# other members alone, so members added any other way, such as by SCIM or
+# onboarding automation, are kept. Terraform doesn't report them either, and
+# removing a user from a group takes removing their prism_group_member. For
+# one authoritative list of members per group, generate with
+# -membership-style=authoritative instead.
+
+locals {
+  groups = {
+    engineering = {
+      name        = "Engineering"
+      description = "All engineers"
+      path        = null
+    }
+  }
+
+  group_members = {
+    engineering_alice = {
+      group_name = prism_group.this["engineering"].name
+      username   = prism_user.this["alice"].username
+    }
+    engineering_o_brien = {
+      group_name = prism_group.this["engineering"].name
+      username   = prism_user.this["o_brien"].username
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
+
+resource "prism_group_member" "this" {
+  for_each = local.group_members
+
+  group_name = each.value.group_name
+  username   = each.value.username
+}
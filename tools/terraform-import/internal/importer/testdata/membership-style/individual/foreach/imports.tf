@@ -0,0 +1,55 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.production
+  id = "111111111111"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.readonly
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.this["alice"]
+  id = "alice"
+}
+
+import {
+  to = prism_user.this["o_brien"]
+  id = "o'brien"
+}
+
+# Groups
+
+import {
+  to = prism_group.this["engineering"]
+  id = "Engineering"
+}
+
+# Group Members
+
+import {
+  to = prism_group_member.this["engineering_alice"]
+  id = "Engineering:alice"
+}
+
+import {
+  to = prism_group_member.this["engineering_o_brien"]
+  id = "Engineering:o'brien"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.this["readonly_engineering"]
+  id = "assign-1,assign-2"
+}
@@ -0,0 +1,30 @@
+# Module Outputs
+
+output "account_ids" {
+  description = "AWS account IDs, by resource name"
+  value = {
+    production = prism_aws_account.production.account_id
+  }
+}
+
+output "permission_set_ids" {
+  description = "Permission set IDs, by resource name"
+  value = {
+    readonly = prism_permission_set.readonly.id
+  }
+}
+
+output "user_ids" {
+  description = "User IDs, by resource name"
+  value = {
+    alice   = prism_user.alice.id
+    o_brien = prism_user.o_brien.id
+  }
+}
+
+output "group_names" {
+  description = "Group names, by resource name"
+  value = {
+    engineering = prism_group.engineering.name
+  }
+}
@@ -0,0 +1,14 @@
+# Users
+
+resource "prism_user" "alice" {
+  username   = "alice"
+  email      = "alice@example.com"
+  first_name = "Alice"
+  enabled    = true
+}
+
+resource "prism_user" "o_brien" {
+  username = "o'brien"
+  email    = "obrien@example.com"
+  enabled  = false
+}
@@ -0,0 +1,14 @@
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
@@ -0,0 +1,11 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "readonly_engineering" {
+  permission_set_id = prism_permission_set.read_only.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.eng.name
+  account_ids = [
+    prism_aws_account.prod.account_id,
+    "222222222222",
+  ]
+}
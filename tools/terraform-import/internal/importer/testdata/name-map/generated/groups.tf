@@ -0,0 +1,21 @@
+# Groups
+
+resource "prism_group" "eng" {
+  name        = "Engineering"
+  description = "All engineers"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "eng_members" {
+  group_name = prism_group.eng.name
+  usernames = [
+    prism_user.o_brien.username,
+    prism_user.o_brien_2.username,
+  ]
+}
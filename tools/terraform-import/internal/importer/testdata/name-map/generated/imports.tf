@@ -0,0 +1,50 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.prod
+  id = "111111111111"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.read_only
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.o_brien
+  id = "alice"
+}
+
+import {
+  to = prism_user.o_brien_2
+  id = "o'brien"
+}
+
+# Groups
+
+import {
+  to = prism_group.eng
+  id = "Engineering"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.eng_members
+  id = "Engineering"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.readonly_engineering
+  id = "assign-1,assign-2"
+}
@@ -0,0 +1,11 @@
+# Permission Sets
+
+resource "prism_permission_set" "read_only" {
+  name             = "ReadOnly"
+  description      = "Read-only access"
+  session_duration = "PT4H"
+
+  managed_policies = [
+    "arn:aws:iam::aws:policy/ReadOnlyAccess",
+  ]
+}
@@ -0,0 +1,38 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "ps_deleted_alice" {
+  permission_set_id = "ps-deleted"
+  principal_type    = "USER"
+  principal_id      = prism_user.alice.username
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_engineering" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.engineering.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+    "222222222222",
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_former_team" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = "Former Team"
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_ghost" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "USER"
+  principal_id      = "ghost"
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
@@ -0,0 +1,29 @@
+# Groups
+
+resource "prism_group" "engineering" {
+  name        = "Engineering"
+  description = "All engineers"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "contractors_members" {
+  group_name = "Contractors"
+  usernames = [
+    prism_user.alice.username,
+  ]
+}
+
+resource "prism_group_membership" "engineering_members" {
+  group_name = prism_group.engineering.name
+  usernames = [
+    prism_user.alice.username,
+    prism_user.o_brien.username,
+    "deleted-user",
+  ]
+}
@@ -0,0 +1,48 @@
+# Groups and Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+locals {
+  groups = {
+    engineering = {
+      name        = "Engineering"
+      description = "All engineers"
+      path        = null
+    }
+  }
+
+  group_memberships = {
+    contractors_members = {
+      group_name = "Contractors"
+      usernames = [
+        prism_user.this["alice"].username,
+      ]
+    }
+    engineering_members = {
+      group_name = prism_group.this["engineering"].name
+      usernames = [
+        prism_user.this["alice"].username,
+        prism_user.this["o_brien"].username,
+        "deleted-user",
+      ]
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
+
+resource "prism_group_membership" "this" {
+  for_each = local.group_memberships
+
+  group_name = each.value.group_name
+  usernames  = each.value.usernames
+}
@@ -0,0 +1,19 @@
+# Groups and Group Memberships
+
+locals {
+  groups = {
+    everyone = {
+      name        = "Everyone"
+      description = null
+      path        = null
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
@@ -0,0 +1,37 @@
+#!/bin/bash
+# Terraform import script - generated automatically
+# This script imports existing resources into Terraform state
+
+set -e
+
+echo "Starting Terraform import process..."
+
+# Import AWS Accounts
+echo "Importing AWS accounts..."
+terraform import prism_aws_account.production '111111111111'
+terraform import prism_aws_account.staging '222222222222'
+
+# Import Permission Sets
+echo "Importing permission sets..."
+terraform import prism_permission_set.admin 'ps-2'
+terraform import prism_permission_set.readonly 'ps-1'
+
+# Import Users
+echo "Importing users..."
+terraform import 'prism_user.this["carol"]' 'carol'
+terraform import 'prism_user.this["dave"]' 'dave'
+
+# Import Groups
+echo "Importing groups..."
+terraform import 'prism_group.this["everyone"]' 'Everyone'
+
+# Import Permission Set Assignments
+echo "Importing permission set assignments..."
+terraform import 'prism_permission_set_assignment.this["admin_carol"]' 'assign-5'
+terraform import 'prism_permission_set_assignment.this["readonly_everyone"]' 'assign-7'
+
+echo "✅ Import complete!"
+echo "Next steps:"
+echo "  1. Run: terraform plan"
+echo "  2. Review any differences"
+echo "  3. Run: terraform apply (if needed)"
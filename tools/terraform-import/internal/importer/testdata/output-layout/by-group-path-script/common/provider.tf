@@ -0,0 +1,15 @@
+terraform {
+  required_version = ">= 1.0"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
@@ -0,0 +1,22 @@
+﻿# Terraform import script for PowerShell - generated automatically
+# Each directory is a separate configuration with its own state, so this
+# runs the import script of each directory in that directory
+
+$ErrorActionPreference = 'Stop'
+
+foreach ($Dir in @('common', 'teams/data', 'teams/platform')) {
+    Write-Host "Importing into $Dir..."
+    Push-Location (Join-Path $PSScriptRoot $Dir)
+    try {
+        & terraform init -input=false
+        if ($LASTEXITCODE -ne 0) {
+            throw "terraform init failed in $Dir with exit code $LASTEXITCODE"
+        }
+        & ./import.ps1
+    } finally {
+        Pop-Location
+    }
+}
+
+Write-Host "Import complete!"
+Write-Host "Next steps: run terraform plan in each directory"
@@ -0,0 +1,20 @@
+#!/bin/bash
+# Terraform import script - generated automatically
+# Each directory is a separate configuration with its own state, so this
+# runs the import script of each directory in that directory
+
+set -e
+
+cd "$(dirname "$0")"
+
+echo "Importing into common..."
+(cd 'common' && terraform init -input=false && bash ./import.sh)
+
+echo "Importing into teams/data..."
+(cd 'teams/data' && terraform init -input=false && bash ./import.sh)
+
+echo "Importing into teams/platform..."
+(cd 'teams/platform' && terraform init -input=false && bash ./import.sh)
+
+echo "✅ Import complete!"
+echo "Next steps: run terraform plan in each directory"
@@ -0,0 +1,31 @@
+# Permission Set Assignments
+
+locals {
+  permission_set_assignments = {
+    readonly_bob = {
+      permission_set_id = "ps-1"
+      principal_type    = "USER"
+      principal_id      = prism_user.this["bob"].username
+      account_ids = [
+        "222222222222",
+      ]
+    }
+    readonly_data = {
+      permission_set_id = "ps-1"
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["data"].name
+      account_ids = [
+        "222222222222",
+      ]
+    }
+  }
+}
+
+resource "prism_permission_set_assignment" "this" {
+  for_each = local.permission_set_assignments
+
+  permission_set_id = each.value.permission_set_id
+  principal_type    = each.value.principal_type
+  principal_id      = each.value.principal_id
+  account_ids       = each.value.account_ids
+}
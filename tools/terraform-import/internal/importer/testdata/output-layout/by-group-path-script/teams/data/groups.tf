@@ -0,0 +1,41 @@
+# Groups and Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+locals {
+  groups = {
+    data = {
+      name        = "Data"
+      description = null
+      path        = "/teams/data/"
+    }
+  }
+
+  group_memberships = {
+    data_members = {
+      group_name = prism_group.this["data"].name
+      usernames = [
+        prism_user.this["bob"].username,
+        "carol",
+      ]
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
+
+resource "prism_group_membership" "this" {
+  for_each = local.group_memberships
+
+  group_name = each.value.group_name
+  usernames  = each.value.usernames
+}
@@ -0,0 +1,45 @@
+﻿# Terraform import script for PowerShell - generated automatically
+# This script imports existing resources into Terraform state
+
+$ErrorActionPreference = 'Stop'
+
+# Windows PowerShell and PowerShell before 7.3 drop double quotes from
+# arguments to native commands unless they are escaped
+$EscapeQuotes = $PSVersionTable.PSVersion -lt [version]'7.3' -or $PSNativeCommandArgumentPassing -eq 'Legacy'
+
+function Import-Resource([string]$Address, [string]$Id) {
+    if ($EscapeQuotes) {
+        $Address = $Address -replace '"', '\"'
+        $Id = $Id -replace '"', '\"'
+    }
+    & terraform import $Address $Id
+    # Stop on the first failure, like set -e in import.sh
+    if ($LASTEXITCODE -ne 0) {
+        throw "terraform import $Address failed with exit code $LASTEXITCODE"
+    }
+}
+
+Write-Host "Starting Terraform import process..."
+
+# Import Users
+Write-Host "Importing users..."
+Import-Resource 'prism_user.this["bob"]' 'bob'
+
+# Import Groups
+Write-Host "Importing groups..."
+Import-Resource 'prism_group.this["data"]' 'Data'
+
+# Import Group Memberships
+Write-Host "Importing group memberships..."
+Import-Resource 'prism_group_membership.this["data_members"]' 'Data'
+
+# Import Permission Set Assignments
+Write-Host "Importing permission set assignments..."
+Import-Resource 'prism_permission_set_assignment.this["readonly_bob"]' 'assign-6'
+Import-Resource 'prism_permission_set_assignment.this["readonly_data"]' 'assign-4'
+
+Write-Host "Import complete!"
+Write-Host "Next steps:"
+Write-Host "  1. Run: terraform plan"
+Write-Host "  2. Review any differences"
+Write-Host "  3. Run: terraform apply (if needed)"
@@ -0,0 +1,30 @@
+#!/bin/bash
+# Terraform import script - generated automatically
+# This script imports existing resources into Terraform state
+
+set -e
+
+echo "Starting Terraform import process..."
+
+# Import Users
+echo "Importing users..."
+terraform import 'prism_user.this["bob"]' 'bob'
+
+# Import Groups
+echo "Importing groups..."
+terraform import 'prism_group.this["data"]' 'Data'
+
+# Import Group Memberships
+echo "Importing group memberships..."
+terraform import 'prism_group_membership.this["data_members"]' 'Data'
+
+# Import Permission Set Assignments
+echo "Importing permission set assignments..."
+terraform import 'prism_permission_set_assignment.this["readonly_bob"]' 'assign-6'
+terraform import 'prism_permission_set_assignment.this["readonly_data"]' 'assign-4'
+
+echo "✅ Import complete!"
+echo "Next steps:"
+echo "  1. Run: terraform plan"
+echo "  2. Review any differences"
+echo "  3. Run: terraform apply (if needed)"
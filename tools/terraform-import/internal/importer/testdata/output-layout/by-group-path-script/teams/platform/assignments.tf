@@ -0,0 +1,32 @@
+# Permission Set Assignments
+
+locals {
+  permission_set_assignments = {
+    admin_platform_oncall = {
+      permission_set_id = "ps-2"
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["platform_oncall"].name
+      account_ids = [
+        "111111111111",
+      ]
+    }
+    readonly_platform = {
+      permission_set_id = "ps-1"
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["platform"].name
+      account_ids = [
+        "111111111111",
+        "222222222222",
+      ]
+    }
+  }
+}
+
+resource "prism_permission_set_assignment" "this" {
+  for_each = local.permission_set_assignments
+
+  permission_set_id = each.value.permission_set_id
+  principal_type    = each.value.principal_type
+  principal_id      = each.value.principal_id
+  account_ids       = each.value.account_ids
+}
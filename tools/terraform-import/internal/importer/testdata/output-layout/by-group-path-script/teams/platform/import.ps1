@@ -0,0 +1,47 @@
+﻿# Terraform import script for PowerShell - generated automatically
+# This script imports existing resources into Terraform state
+
+$ErrorActionPreference = 'Stop'
+
+# Windows PowerShell and PowerShell before 7.3 drop double quotes from
+# arguments to native commands unless they are escaped
+$EscapeQuotes = $PSVersionTable.PSVersion -lt [version]'7.3' -or $PSNativeCommandArgumentPassing -eq 'Legacy'
+
+function Import-Resource([string]$Address, [string]$Id) {
+    if ($EscapeQuotes) {
+        $Address = $Address -replace '"', '\"'
+        $Id = $Id -replace '"', '\"'
+    }
+    & terraform import $Address $Id
+    # Stop on the first failure, like set -e in import.sh
+    if ($LASTEXITCODE -ne 0) {
+        throw "terraform import $Address failed with exit code $LASTEXITCODE"
+    }
+}
+
+Write-Host "Starting Terraform import process..."
+
+# Import Users
+Write-Host "Importing users..."
+Import-Resource 'prism_user.this["alice"]' 'alice'
+
+# Import Groups
+Write-Host "Importing groups..."
+Import-Resource 'prism_group.this["platform"]' 'Platform'
+Import-Resource 'prism_group.this["platform_oncall"]' 'Platform Oncall'
+
+# Import Group Memberships
+Write-Host "Importing group memberships..."
+Import-Resource 'prism_group_membership.this["platform_members"]' 'Platform'
+Import-Resource 'prism_group_membership.this["platform_oncall_members"]' 'Platform Oncall'
+
+# Import Permission Set Assignments
+Write-Host "Importing permission set assignments..."
+Import-Resource 'prism_permission_set_assignment.this["admin_platform_oncall"]' 'assign-3'
+Import-Resource 'prism_permission_set_assignment.this["readonly_platform"]' 'assign-1,assign-2'
+
+Write-Host "Import complete!"
+Write-Host "Next steps:"
+Write-Host "  1. Run: terraform plan"
+Write-Host "  2. Review any differences"
+Write-Host "  3. Run: terraform apply (if needed)"
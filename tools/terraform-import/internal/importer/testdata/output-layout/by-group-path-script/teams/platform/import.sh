@@ -0,0 +1,32 @@
+#!/bin/bash
+# Terraform import script - generated automatically
+# This script imports existing resources into Terraform state
+
+set -e
+
+echo "Starting Terraform import process..."
+
+# Import Users
+echo "Importing users..."
+terraform import 'prism_user.this["alice"]' 'alice'
+
+# Import Groups
+echo "Importing groups..."
+terraform import 'prism_group.this["platform"]' 'Platform'
+terraform import 'prism_group.this["platform_oncall"]' 'Platform Oncall'
+
+# Import Group Memberships
+echo "Importing group memberships..."
+terraform import 'prism_group_membership.this["platform_members"]' 'Platform'
+terraform import 'prism_group_membership.this["platform_oncall_members"]' 'Platform Oncall'
+
+# Import Permission Set Assignments
+echo "Importing permission set assignments..."
+terraform import 'prism_permission_set_assignment.this["admin_platform_oncall"]' 'assign-3'
+terraform import 'prism_permission_set_assignment.this["readonly_platform"]' 'assign-1,assign-2'
+
+echo "✅ Import complete!"
+echo "Next steps:"
+echo "  1. Run: terraform plan"
+echo "  2. Review any differences"
+echo "  3. Run: terraform apply (if needed)"
@@ -0,0 +1,25 @@
+# Users
+
+locals {
+  users = {
+    alice = {
+      username   = "alice"
+      email      = "alice@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+  }
+}
+
+resource "prism_user" "this" {
+  for_each = local.users
+
+  username   = each.value.username
+  email      = each.value.email
+  first_name = each.value.first_name
+  last_name  = each.value.last_name
+  enabled    = each.value.enabled
+  attributes = each.value.attributes
+}
@@ -0,0 +1,5 @@
+# Groups
+
+resource "prism_group" "everyone" {
+  name = "Everyone"
+}
@@ -0,0 +1,58 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.production
+  id = "111111111111"
+}
+
+import {
+  to = prism_aws_account.staging
+  id = "222222222222"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.admin
+  id = "ps-2"
+}
+
+import {
+  to = prism_permission_set.readonly
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.carol
+  id = "carol"
+}
+
+import {
+  to = prism_user.dave
+  id = "dave"
+}
+
+# Groups
+
+import {
+  to = prism_group.everyone
+  id = "Everyone"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.admin_carol
+  id = "assign-5"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_everyone
+  id = "assign-7"
+}
@@ -0,0 +1,11 @@
+# Permission Sets
+
+resource "prism_permission_set" "admin" {
+  name             = "Admin"
+  session_duration = "PT1H"
+}
+
+resource "prism_permission_set" "readonly" {
+  name             = "ReadOnly"
+  session_duration = "PT4H"
+}
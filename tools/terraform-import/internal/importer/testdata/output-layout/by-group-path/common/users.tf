@@ -0,0 +1,13 @@
+# Users
+
+resource "prism_user" "carol" {
+  username = "carol"
+  email    = "carol@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "dave" {
+  username = "dave"
+  email    = "dave@example.com"
+  enabled  = true
+}
@@ -0,0 +1,19 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "readonly_bob" {
+  permission_set_id = "ps-1"
+  principal_type    = "USER"
+  principal_id      = prism_user.bob.username
+  account_ids = [
+    "222222222222",
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_data" {
+  permission_set_id = "ps-1"
+  principal_type    = "GROUP"
+  principal_id      = prism_group.data.name
+  account_ids = [
+    "222222222222",
+  ]
+}
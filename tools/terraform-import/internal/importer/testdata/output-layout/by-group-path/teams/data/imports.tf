@@ -0,0 +1,36 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# Users
+
+import {
+  to = prism_user.bob
+  id = "bob"
+}
+
+# Groups
+
+import {
+  to = prism_group.data
+  id = "Data"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.data_members
+  id = "Data"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.readonly_bob
+  id = "assign-6"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_data
+  id = "assign-4"
+}
@@ -0,0 +1,7 @@
+# Users
+
+resource "prism_user" "bob" {
+  username = "bob"
+  email    = "bob@example.com"
+  enabled  = true
+}
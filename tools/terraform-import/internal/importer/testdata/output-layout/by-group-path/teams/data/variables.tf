@@ -0,0 +1,19 @@
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
+
+variable "prism_base_url" {
+  type        = string
+  description = "Prism base URL, without port"
+}
@@ -0,0 +1,20 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "admin_platform_oncall" {
+  permission_set_id = "ps-2"
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform_oncall.name
+  account_ids = [
+    "111111111111",
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_platform" {
+  permission_set_id = "ps-1"
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform.name
+  account_ids = [
+    "111111111111",
+    "222222222222",
+  ]
+}
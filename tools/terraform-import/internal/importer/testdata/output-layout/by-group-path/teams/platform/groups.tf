@@ -0,0 +1,33 @@
+# Groups
+
+resource "prism_group" "platform" {
+  name = "Platform"
+  path = "/teams/platform/"
+}
+
+resource "prism_group" "platform_oncall" {
+  name = "Platform Oncall"
+  path = "/teams/platform/oncall/"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "platform_members" {
+  group_name = prism_group.platform.name
+  usernames = [
+    prism_user.alice.username,
+    "carol",
+  ]
+}
+
+resource "prism_group_membership" "platform_oncall_members" {
+  group_name = prism_group.platform_oncall.name
+  usernames = [
+    prism_user.alice.username,
+  ]
+}
@@ -0,0 +1,46 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# Users
+
+import {
+  to = prism_user.alice
+  id = "alice"
+}
+
+# Groups
+
+import {
+  to = prism_group.platform
+  id = "Platform"
+}
+
+import {
+  to = prism_group.platform_oncall
+  id = "Platform Oncall"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.platform_members
+  id = "Platform"
+}
+
+import {
+  to = prism_group_membership.platform_oncall_members
+  id = "Platform Oncall"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.admin_platform_oncall
+  id = "assign-3"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_platform
+  id = "assign-1,assign-2"
+}
@@ -0,0 +1,7 @@
+# Users
+
+resource "prism_user" "alice" {
+  username = "alice"
+  email    = "alice@example.com"
+  enabled  = true
+}
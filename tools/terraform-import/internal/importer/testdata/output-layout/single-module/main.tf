@@ -0,0 +1,277 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+}
+
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
+
+# AWS Account ID Variables
+
+variable "production_account_id" {
+  type        = string
+  description = "AWS Account ID (111111111111)"
+  default     = "111111111111"
+}
+
+variable "staging_account_id" {
+  type        = string
+  description = "AWS Account ID (222222222222)"
+  default     = "222222222222"
+}
+
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = var.production_account_id
+  account_name = "Production"
+  region       = "us-east-1"
+}
+
+resource "prism_aws_account" "staging" {
+  account_id   = var.staging_account_id
+  account_name = "Staging"
+  region       = "us-east-1"
+}
+
+# Permission Sets
+
+resource "prism_permission_set" "admin" {
+  name             = "Admin"
+  session_duration = "PT1H"
+}
+
+resource "prism_permission_set" "readonly" {
+  name             = "ReadOnly"
+  session_duration = "PT4H"
+}
+
+# Users
+
+locals {
+  users = {
+    alice = {
+      username   = "alice"
+      email      = "alice@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+    bob = {
+      username   = "bob"
+      email      = "bob@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+    carol = {
+      username   = "carol"
+      email      = "carol@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+    dave = {
+      username   = "dave"
+      email      = "dave@example.com"
+      first_name = null
+      last_name  = null
+      enabled    = true
+      attributes = null
+    }
+  }
+}
+
+resource "prism_user" "this" {
+  for_each = local.users
+
+  username   = each.value.username
+  email      = each.value.email
+  first_name = each.value.first_name
+  last_name  = each.value.last_name
+  enabled    = each.value.enabled
+  attributes = each.value.attributes
+}
+
+# Groups and Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+locals {
+  groups = {
+    data = {
+      name        = "Data"
+      description = null
+      path        = "/teams/data/"
+    }
+    everyone = {
+      name        = "Everyone"
+      description = null
+      path        = null
+    }
+    platform = {
+      name        = "Platform"
+      description = null
+      path        = "/teams/platform/"
+    }
+    platform_oncall = {
+      name        = "Platform Oncall"
+      description = null
+      path        = "/teams/platform/oncall/"
+    }
+  }
+
+  group_memberships = {
+    data_members = {
+      group_name = prism_group.this["data"].name
+      usernames = [
+        prism_user.this["bob"].username,
+        prism_user.this["carol"].username,
+      ]
+    }
+    platform_members = {
+      group_name = prism_group.this["platform"].name
+      usernames = [
+        prism_user.this["alice"].username,
+        prism_user.this["carol"].username,
+      ]
+    }
+    platform_oncall_members = {
+      group_name = prism_group.this["platform_oncall"].name
+      usernames = [
+        prism_user.this["alice"].username,
+      ]
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
+
+resource "prism_group_membership" "this" {
+  for_each = local.group_memberships
+
+  group_name = each.value.group_name
+  usernames  = each.value.usernames
+}
+
+# Permission Set Assignments
+
+locals {
+  permission_set_assignments = {
+    admin_carol = {
+      permission_set_id = prism_permission_set.admin.id
+      principal_type    = "USER"
+      principal_id      = prism_user.this["carol"].username
+      account_ids = [
+        prism_aws_account.production.account_id,
+      ]
+    }
+    admin_platform_oncall = {
+      permission_set_id = prism_permission_set.admin.id
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["platform_oncall"].name
+      account_ids = [
+        prism_aws_account.production.account_id,
+      ]
+    }
+    readonly_bob = {
+      permission_set_id = prism_permission_set.readonly.id
+      principal_type    = "USER"
+      principal_id      = prism_user.this["bob"].username
+      account_ids = [
+        prism_aws_account.staging.account_id,
+      ]
+    }
+    readonly_data = {
+      permission_set_id = prism_permission_set.readonly.id
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["data"].name
+      account_ids = [
+        prism_aws_account.staging.account_id,
+      ]
+    }
+    readonly_everyone = {
+      permission_set_id = prism_permission_set.readonly.id
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["everyone"].name
+      account_ids = [
+        prism_aws_account.production.account_id,
+      ]
+    }
+    readonly_platform = {
+      permission_set_id = prism_permission_set.readonly.id
+      principal_type    = "GROUP"
+      principal_id      = prism_group.this["platform"].name
+      account_ids = [
+        prism_aws_account.production.account_id,
+        prism_aws_account.staging.account_id,
+      ]
+    }
+  }
+}
+
+resource "prism_permission_set_assignment" "this" {
+  for_each = local.permission_set_assignments
+
+  permission_set_id = each.value.permission_set_id
+  principal_type    = each.value.principal_type
+  principal_id      = each.value.principal_id
+  account_ids       = each.value.account_ids
+}
+
+# Module Outputs
+
+output "account_ids" {
+  description = "AWS account IDs, by resource name"
+  value = {
+    production = prism_aws_account.production.account_id
+    staging    = prism_aws_account.staging.account_id
+  }
+}
+
+output "permission_set_ids" {
+  description = "Permission set IDs, by resource name"
+  value = {
+    admin    = prism_permission_set.admin.id
+    readonly = prism_permission_set.readonly.id
+  }
+}
+
+output "user_ids" {
+  description = "User IDs, by resource name"
+  value       = { for k, v in prism_user.this : k => v.id }
+}
+
+output "group_names" {
+  description = "Group names, by resource name"
+  value       = { for k, v in prism_group.this : k => v.name }
+}
@@ -0,0 +1,328 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
+
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
+
+variable "prism_base_url" {
+  type        = string
+  description = "Prism base URL, without port"
+}
+
+# AWS Account ID Variables
+
+variable "production_account_id" {
+  type        = string
+  description = "AWS Account ID (111111111111)"
+}
+
+variable "staging_account_id" {
+  type        = string
+  description = "AWS Account ID (222222222222)"
+}
+
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = var.production_account_id
+  account_name = "Production"
+  region       = "us-east-1"
+}
+
+resource "prism_aws_account" "staging" {
+  account_id   = var.staging_account_id
+  account_name = "Staging"
+  region       = "us-east-1"
+}
+
+# Permission Sets
+
+resource "prism_permission_set" "admin" {
+  name             = "Admin"
+  session_duration = "PT1H"
+}
+
+resource "prism_permission_set" "readonly" {
+  name             = "ReadOnly"
+  session_duration = "PT4H"
+}
+
+# Users
+
+resource "prism_user" "alice" {
+  username = "alice"
+  email    = "alice@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "bob" {
+  username = "bob"
+  email    = "bob@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "carol" {
+  username = "carol"
+  email    = "carol@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "dave" {
+  username = "dave"
+  email    = "dave@example.com"
+  enabled  = true
+}
+
+# Groups
+
+resource "prism_group" "data" {
+  name = "Data"
+  path = "/teams/data/"
+}
+
+resource "prism_group" "everyone" {
+  name = "Everyone"
+}
+
+resource "prism_group" "platform" {
+  name = "Platform"
+  path = "/teams/platform/"
+}
+
+resource "prism_group" "platform_oncall" {
+  name = "Platform Oncall"
+  path = "/teams/platform/oncall/"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "data_members" {
+  group_name = prism_group.data.name
+  usernames = [
+    prism_user.bob.username,
+    prism_user.carol.username,
+  ]
+}
+
+resource "prism_group_membership" "platform_members" {
+  group_name = prism_group.platform.name
+  usernames = [
+    prism_user.alice.username,
+    prism_user.carol.username,
+  ]
+}
+
+resource "prism_group_membership" "platform_oncall_members" {
+  group_name = prism_group.platform_oncall.name
+  usernames = [
+    prism_user.alice.username,
+  ]
+}
+
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "admin_carol" {
+  permission_set_id = prism_permission_set.admin.id
+  principal_type    = "USER"
+  principal_id      = prism_user.carol.username
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "admin_platform_oncall" {
+  permission_set_id = prism_permission_set.admin.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform_oncall.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_bob" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "USER"
+  principal_id      = prism_user.bob.username
+  account_ids = [
+    prism_aws_account.staging.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_data" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.data.name
+  account_ids = [
+    prism_aws_account.staging.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_everyone" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.everyone.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_platform" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+    prism_aws_account.staging.account_id,
+  ]
+}
+
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.production
+  id = "111111111111"
+}
+
+import {
+  to = prism_aws_account.staging
+  id = "222222222222"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.admin
+  id = "ps-2"
+}
+
+import {
+  to = prism_permission_set.readonly
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.alice
+  id = "alice"
+}
+
+import {
+  to = prism_user.bob
+  id = "bob"
+}
+
+import {
+  to = prism_user.carol
+  id = "carol"
+}
+
+import {
+  to = prism_user.dave
+  id = "dave"
+}
+
+# Groups
+
+import {
+  to = prism_group.data
+  id = "Data"
+}
+
+import {
+  to = prism_group.everyone
+  id = "Everyone"
+}
+
+import {
+  to = prism_group.platform
+  id = "Platform"
+}
+
+import {
+  to = prism_group.platform_oncall
+  id = "Platform Oncall"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.data_members
+  id = "Data"
+}
+
+import {
+  to = prism_group_membership.platform_members
+  id = "Platform"
+}
+
+import {
+  to = prism_group_membership.platform_oncall_members
+  id = "Platform Oncall"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.admin_carol
+  id = "assign-5"
+}
+
+import {
+  to = prism_permission_set_assignment.admin_platform_oncall
+  id = "assign-3"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_bob
+  id = "assign-6"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_data
+  id = "assign-4"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_everyone
+  id = "assign-7"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_platform
+  id = "assign-1,assign-2"
+}
@@ -0,0 +1,56 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "admin_carol" {
+  permission_set_id = prism_permission_set.admin.id
+  principal_type    = "USER"
+  principal_id      = prism_user.carol.username
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "admin_platform_oncall" {
+  permission_set_id = prism_permission_set.admin.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform_oncall.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_bob" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "USER"
+  principal_id      = prism_user.bob.username
+  account_ids = [
+    prism_aws_account.staging.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_data" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.data.name
+  account_ids = [
+    prism_aws_account.staging.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_everyone" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.everyone.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_platform" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.platform.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+    prism_aws_account.staging.account_id,
+  ]
+}
@@ -0,0 +1,13 @@
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = var.production_account_id
+  account_name = "Production"
+  region       = "us-east-1"
+}
+
+resource "prism_aws_account" "staging" {
+  account_id   = var.staging_account_id
+  account_name = "Staging"
+  region       = "us-east-1"
+}
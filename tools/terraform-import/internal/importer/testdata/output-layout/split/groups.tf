@@ -0,0 +1,50 @@
+# Groups
+
+resource "prism_group" "data" {
+  name = "Data"
+  path = "/teams/data/"
+}
+
+resource "prism_group" "everyone" {
+  name = "Everyone"
+}
+
+resource "prism_group" "platform" {
+  name = "Platform"
+  path = "/teams/platform/"
+}
+
+resource "prism_group" "platform_oncall" {
+  name = "Platform Oncall"
+  path = "/teams/platform/oncall/"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "data_members" {
+  group_name = prism_group.data.name
+  usernames = [
+    prism_user.bob.username,
+    prism_user.carol.username,
+  ]
+}
+
+resource "prism_group_membership" "platform_members" {
+  group_name = prism_group.platform.name
+  usernames = [
+    prism_user.alice.username,
+    prism_user.carol.username,
+  ]
+}
+
+resource "prism_group_membership" "platform_oncall_members" {
+  group_name = prism_group.platform_oncall.name
+  usernames = [
+    prism_user.alice.username,
+  ]
+}
@@ -0,0 +1,120 @@
+# Terraform import blocks - generated automatically
+# Requires Terraform >= 1.5. Run `terraform plan` to review the imports
+# and `terraform apply` to bring all resources into state at once.
+
+# AWS Accounts
+
+import {
+  to = prism_aws_account.production
+  id = "111111111111"
+}
+
+import {
+  to = prism_aws_account.staging
+  id = "222222222222"
+}
+
+# Permission Sets
+
+import {
+  to = prism_permission_set.admin
+  id = "ps-2"
+}
+
+import {
+  to = prism_permission_set.readonly
+  id = "ps-1"
+}
+
+# Users
+
+import {
+  to = prism_user.alice
+  id = "alice"
+}
+
+import {
+  to = prism_user.bob
+  id = "bob"
+}
+
+import {
+  to = prism_user.carol
+  id = "carol"
+}
+
+import {
+  to = prism_user.dave
+  id = "dave"
+}
+
+# Groups
+
+import {
+  to = prism_group.data
+  id = "Data"
+}
+
+import {
+  to = prism_group.everyone
+  id = "Everyone"
+}
+
+import {
+  to = prism_group.platform
+  id = "Platform"
+}
+
+import {
+  to = prism_group.platform_oncall
+  id = "Platform Oncall"
+}
+
+# Group Memberships
+
+import {
+  to = prism_group_membership.data_members
+  id = "Data"
+}
+
+import {
+  to = prism_group_membership.platform_members
+  id = "Platform"
+}
+
+import {
+  to = prism_group_membership.platform_oncall_members
+  id = "Platform Oncall"
+}
+
+# Permission Set Assignments
+
+import {
+  to = prism_permission_set_assignment.admin_carol
+  id = "assign-5"
+}
+
+import {
+  to = prism_permission_set_assignment.admin_platform_oncall
+  id = "assign-3"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_bob
+  id = "assign-6"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_data
+  id = "assign-4"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_everyone
+  id = "assign-7"
+}
+
+import {
+  to = prism_permission_set_assignment.readonly_platform
+  id = "assign-1,assign-2"
+}
@@ -0,0 +1,25 @@
+# Users
+
+resource "prism_user" "alice" {
+  username = "alice"
+  email    = "alice@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "bob" {
+  username = "bob"
+  email    = "bob@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "carol" {
+  username = "carol"
+  email    = "carol@example.com"
+  enabled  = true
+}
+
+resource "prism_user" "dave" {
+  username = "dave"
+  email    = "dave@example.com"
+  enabled  = true
+}
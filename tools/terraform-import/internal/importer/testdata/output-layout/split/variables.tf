@@ -0,0 +1,31 @@
+# Provider Configuration Variables
+
+variable "prism_subdomain" {
+  type        = string
+  description = "Prism subdomain"
+  sensitive   = false
+}
+
+variable "prism_api_token" {
+  type        = string
+  description = "Prism API token"
+  sensitive   = true
+  default     = null
+}
+
+variable "prism_base_url" {
+  type        = string
+  description = "Prism base URL, without port"
+}
+
+# AWS Account ID Variables
+
+variable "production_account_id" {
+  type        = string
+  description = "AWS Account ID (111111111111)"
+}
+
+variable "staging_account_id" {
+  type        = string
+  description = "AWS Account ID (222222222222)"
+}
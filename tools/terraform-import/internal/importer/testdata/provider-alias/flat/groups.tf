@@ -0,0 +1,25 @@
+# Groups
+
+resource "prism_group" "engineering" {
+  provider = prism.acme
+
+  name        = "Engineering"
+  description = "All engineers"
+}
+
+# Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+resource "prism_group_membership" "engineering_members" {
+  provider = prism.acme
+
+  group_name = prism_group.engineering.name
+  usernames = [
+    prism_user.alice.username,
+    prism_user.o_brien.username,
+  ]
+}
@@ -0,0 +1,45 @@
+# Groups and Group Memberships
+#
+# Each prism_group_membership is authoritative: it sets the complete list of
+# a group's members, so Terraform removes members added any other way, such
+# as by SCIM or onboarding automation. To leave those members alone, generate
+# with -membership-style=individual instead.
+
+locals {
+  groups = {
+    engineering = {
+      name        = "Engineering"
+      description = "All engineers"
+      path        = null
+    }
+  }
+
+  group_memberships = {
+    engineering_members = {
+      group_name = prism_group.this["engineering"].name
+      usernames = [
+        prism_user.this["alice"].username,
+        prism_user.this["o_brien"].username,
+      ]
+    }
+  }
+}
+
+resource "prism_group" "this" {
+  provider = prism.acme
+
+  for_each = local.groups
+
+  name        = each.value.name
+  description = each.value.description
+  path        = each.value.path
+}
+
+resource "prism_group_membership" "this" {
+  provider = prism.acme
+
+  for_each = local.group_memberships
+
+  group_name = each.value.group_name
+  usernames  = each.value.usernames
+}
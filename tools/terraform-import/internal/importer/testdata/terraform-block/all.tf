@@ -0,0 +1,23 @@
+terraform {
+  required_version = "~> 1.4"
+
+  required_providers {
+    prism = {
+      source  = "CloudKeeper-Inc/prism"
+      version = "~> 1.2"
+    }
+  }
+
+  # Uncomment to store state in the s3 backend, replacing the placeholders
+  # backend "s3" {
+  #   bucket = "YOUR_STATE_BUCKET"
+  #   key    = "prism/terraform.tfstate"
+  #   region = "us-east-1"
+  # }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
@@ -0,0 +1,21 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+
+  # Uncomment to store state in the gcs backend, replacing the placeholders
+  # backend "gcs" {
+  #   bucket = "YOUR_STATE_BUCKET"
+  #   prefix = "prism"
+  # }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
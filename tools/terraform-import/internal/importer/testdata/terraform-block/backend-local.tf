@@ -0,0 +1,20 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+
+  # Uncomment to store state in the local backend, replacing the placeholders
+  # backend "local" {
+  #   path = "terraform.tfstate"
+  # }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
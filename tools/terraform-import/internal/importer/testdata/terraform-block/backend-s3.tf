@@ -0,0 +1,22 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    prism = {
+      source = "CloudKeeper-Inc/prism"
+    }
+  }
+
+  # Uncomment to store state in the s3 backend, replacing the placeholders
+  # backend "s3" {
+  #   bucket = "YOUR_STATE_BUCKET"
+  #   key    = "prism/terraform.tfstate"
+  #   region = "us-east-1"
+  # }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
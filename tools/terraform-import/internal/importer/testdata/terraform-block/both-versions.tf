@@ -0,0 +1,16 @@
+terraform {
+  required_version = "~> 1.9"
+
+  required_providers {
+    prism = {
+      source  = "CloudKeeper-Inc/prism"
+      version = "~> 1.2.3"
+    }
+  }
+}
+
+provider "prism" {
+  prism_subdomain = var.prism_subdomain
+  api_token       = var.prism_api_token
+  base_url        = var.prism_base_url
+}
@@ -0,0 +1,10 @@
+terraform {
+  required_version = "~> 1.9"
+
+  required_providers {
+    prism = {
+      source  = "CloudKeeper-Inc/prism"
+      version = "~> 1.2"
+    }
+  }
+}
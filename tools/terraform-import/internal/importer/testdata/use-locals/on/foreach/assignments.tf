@@ -0,0 +1,45 @@
+# Permission Set Assignments
+
+locals {
+  engineering_group_name     = prism_group.this["engineering"].name
+  readonly_permission_set_id = prism_permission_set.readonly.id
+}
+
+locals {
+  permission_set_assignments = {
+    admin_engineering = {
+      permission_set_id = prism_permission_set.admin.id
+      principal_type    = "GROUP"
+      principal_id      = local.engineering_group_name
+      account_ids = [
+        prism_aws_account.production.account_id,
+      ]
+    }
+    readonly_alice = {
+      permission_set_id = local.readonly_permission_set_id
+      principal_type    = "USER"
+      principal_id      = prism_user.this["alice"].username
+      account_ids = [
+        prism_aws_account.production.account_id,
+      ]
+    }
+    readonly_engineering = {
+      permission_set_id = local.readonly_permission_set_id
+      principal_type    = "GROUP"
+      principal_id      = local.engineering_group_name
+      account_ids = [
+        prism_aws_account.production.account_id,
+        "222222222222",
+      ]
+    }
+  }
+}
+
+resource "prism_permission_set_assignment" "this" {
+  for_each = local.permission_set_assignments
+
+  permission_set_id = each.value.permission_set_id
+  principal_type    = each.value.principal_type
+  principal_id      = each.value.principal_id
+  account_ids       = each.value.account_ids
+}
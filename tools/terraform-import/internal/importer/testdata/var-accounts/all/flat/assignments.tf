@@ -0,0 +1,20 @@
+# Permission Set Assignments
+
+resource "prism_permission_set_assignment" "readonly_alice" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "USER"
+  principal_id      = prism_user.alice.username
+  account_ids = [
+    prism_aws_account.production.account_id,
+  ]
+}
+
+resource "prism_permission_set_assignment" "readonly_engineering" {
+  permission_set_id = prism_permission_set.readonly.id
+  principal_type    = "GROUP"
+  principal_id      = prism_group.engineering.name
+  account_ids = [
+    prism_aws_account.production.account_id,
+    var.account_222222222222_account_id,
+  ]
+}
@@ -0,0 +1,7 @@
+# AWS Accounts
+
+resource "prism_aws_account" "production" {
+  account_id   = var.production_account_id
+  account_name = "Production"
+  region       = "us-east-1"
+}
@@ -0,0 +1,141 @@
+package importer
+
+// Supported values of the -var-accounts flag
+const (
+	VarAccountsAll    = "all"
+	VarAccountsReused = "reused"
+	VarAccountsNone   = "none"
+)
+
+// VariableExtractor decides which exported values become Terraform
+// variables rather than literals.
+type VariableExtractor struct {
+	// NameMap must be the Generator's, since variables are named after
+	// resources
+	NameMap *NameMap
+
+	// Accounts is the -var-accounts mode; empty means VarAccountsReused
+	Accounts string
+
+	// UseLocals is the -use-locals flag: permission sets and principals in
+	// more than one assignment get a local holding their reference
+	UseLocals bool
+}
+
+// Extract returns the variables for data: AWS account IDs as chosen by
+// e.Accounts, the identity provider config fields that can't be exported,
+// and with e.UseLocals the locals of reused permission sets and principals.
+func (e VariableExtractor) Extract(data *InfrastructureData) *Variables {
+	// Name the variables after the resources so they are valid and unique
+	// too
+	sorted := sortedData(data)
+	return extractVariables(sorted, newResourceNames(sorted, e.NameMap), e.Accounts, e.UseLocals)
+}
+
+// extractVariables is Extract for sorted data named by names.
+func extractVariables(data *InfrastructureData, names *resourceNames, accounts string, useLocals bool) *Variables {
+	vars := &Variables{
+		AccountIDs:     make(map[string]string),
+		PermissionSets: make(map[string]string),
+		Users:          make(map[string]string),
+		Groups:         make(map[string]string),
+	}
+
+	switch accounts {
+	case VarAccountsNone:
+	case VarAccountsAll:
+		// Every generated account, and every account an assignment refers
+		// to without one, named so as not to collide with the others
+		var bases []string
+		for _, acc := range data.AWSAccounts {
+			bases = append(bases, names.accounts[acc.AccountID]+"_account_id")
+		}
+		referenced := make(map[string]bool)
+		for _, assignment := range data.PermissionSetAssignments {
+			if _, ok := names.accounts[assignment.AccountID]; !ok {
+				referenced[assignment.AccountID] = true
+			}
+		}
+		unlisted := sortedKeys(referenced)
+		for _, accountID := range unlisted {
+			bases = append(bases, toResourceName(accountID, "account")+"_account_id")
+		}
+
+		allocator := newNameAllocator(bases)
+		for _, acc := range data.AWSAccounts {
+			if _, ok := vars.AccountIDs[acc.AccountID]; ok {
+				// The same account listed twice keeps one variable
+				continue
+			}
+			vars.AccountIDs[acc.AccountID] = allocator.allocate(names.accounts[acc.AccountID] + "_account_id")
+		}
+		for _, accountID := range unlisted {
+			vars.AccountIDs[accountID] = allocator.allocate(toResourceName(accountID, "account") + "_account_id")
+		}
+	default:
+		// Generated accounts that appear in more than one assignment
+		accountUsage := make(map[string]int)
+		for _, assignment := range data.PermissionSetAssignments {
+			accountUsage[assignment.AccountID]++
+		}
+		for accountID, count := range accountUsage {
+			if count > 1 {
+				if name, ok := names.accounts[accountID]; ok {
+					vars.AccountIDs[accountID] = name + "_account_id"
+				}
+			}
+		}
+	}
+
+	// Secrets can't be exported, so identity provider configs refer to
+	// variables for them
+	vars.IdentityProviders = identityProviderVariables(data.IdentityProviders, names)
+
+	if useLocals {
+		assignmentLocals(vars, data, names)
+	}
+
+	return vars
+}
+
+// assignmentLocals fills the locals of vars with the generated permission
+// sets, users and groups that more than one assignment resource refers to,
+// named after their resources.
+func assignmentLocals(vars *Variables, data *InfrastructureData, names *resourceNames) {
+	permSetUsage := make(map[string]int)
+	userUsage := make(map[string]int)
+	groupUsage := make(map[string]int)
+	for _, assignment := range groupAssignments(data) {
+		permSetUsage[assignment.PermissionSetID]++
+		if assignment.PrincipalType == "USER" {
+			userUsage[assignment.PrincipalID]++
+		} else {
+			groupUsage[assignment.PrincipalID]++
+		}
+	}
+
+	// Keys whose resource is generated, with the base of their local's name
+	type reused struct {
+		locals map[string]string
+		key    string
+		base   string
+	}
+	var entries []reused
+	var bases []string
+	add := func(locals map[string]string, usage map[string]int, resources map[string]string, suffix string) {
+		for _, key := range sortedKeys(usage) {
+			if name, ok := resources[key]; ok && usage[key] > 1 {
+				entries = append(entries, reused{locals, key, name + suffix})
+				bases = append(bases, name+suffix)
+			}
+		}
+	}
+	add(vars.PermissionSets, permSetUsage, names.permissionSets, "_permission_set_id")
+	add(vars.Users, userUsage, names.users, "_username")
+	add(vars.Groups, groupUsage, names.groups, "_group_name")
+
+	allocator := newNameAllocator(bases)
+	for _, entry := range entries {
+		entry.locals[entry.key] = allocator.allocate(entry.base)
+	}
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+func TestVariableExtractor_Extract(t *testing.T) {
+	data := testInfrastructure()
+	data.AWSAccounts = append(data.AWSAccounts, provider.AWSAccount{ID: "acct-2", AccountID: "222222222222", AccountName: "Staging"})
+	data.PermissionSetAssignments = append(data.PermissionSetAssignments,
+		provider.PermissionSetAssignment{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "222222222222"})
+	data.IdentityProviders = identityProviderInfrastructure().IdentityProviders
+
+	vars := VariableExtractor{}.Extract(data)
+
+	// Only accounts used by more than one assignment become variables
+	wantAccounts := map[string]string{"222222222222": "staging_account_id"}
+	if !reflect.DeepEqual(vars.AccountIDs, wantAccounts) {
+		t.Errorf("expected account variables %v, got %v", wantAccounts, vars.AccountIDs)
+	}
+
+	var idpVariables []string
+	for _, v := range vars.IdentityProviders {
+		idpVariables = append(idpVariables, v.Name)
+	}
+	wantIdP := []string{"google_client_secret", "microsoft_client_secret", "microsoft_tenant_id"}
+	if !reflect.DeepEqual(idpVariables, wantIdP) {
+		t.Errorf("expected identity provider variables %v, got %v", wantIdP, idpVariables)
+	}
+}
+
+func TestVariableExtractor_NoVariables(t *testing.T) {
+	vars := VariableExtractor{}.Extract(&InfrastructureData{GroupMemberships: map[string][]string{}})
+	if len(vars.AccountIDs) != 0 || len(vars.IdentityProviders) != 0 {
+		t.Errorf("expected no variables, got %+v", vars)
+	}
+}
+
+func TestGenerateFiles_VarAccounts(t *testing.T) {
+	// 111111111111 is in two assignments and 222222222222 isn't generated
+	data := testInfrastructure()
+	data.PermissionSetAssignments = append(data.PermissionSetAssignments,
+		provider.PermissionSetAssignment{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"})
+
+	for _, mode := range []string{VarAccountsAll, VarAccountsReused, VarAccountsNone} {
+		for _, style := range []string{StyleFlat, StyleForEach} {
+			t.Run(mode+"/"+style, func(t *testing.T) {
+				dir := generateTestFiles(t, Config{ImportFormat: ImportFormatBlocks, Style: style, VarAccounts: mode}, data)
+				for _, name := range []string{"variables.tf", "terraform.tfvars", "aws_accounts.tf", "assignments.tf"} {
+					assertGoldenFile(t, filepath.Join(dir, name), filepath.Join("var-accounts", mode, style, name))
+				}
+				assertReferencesResolve(t, dir)
+			})
+		}
+	}
+}
+
+func TestVariableExtractor_UseLocals(t *testing.T) {
+	data := useLocalsInfrastructure()
+
+	vars := VariableExtractor{UseLocals: true}.Extract(data)
+	if want := map[string]string{"ps-1": "readonly_permission_set_id"}; !reflect.DeepEqual(vars.PermissionSets, want) {
+		t.Errorf("expected permission set locals %v, got %v", want, vars.PermissionSets)
+	}
+	if want := map[string]string{"Engineering": "engineering_group_name"}; !reflect.DeepEqual(vars.Groups, want) {
+		t.Errorf("expected group locals %v, got %v", want, vars.Groups)
+	}
+	if len(vars.Users) != 0 {
+		t.Errorf("expected no user locals for a user in one assignment, got %v", vars.Users)
+	}
+
+	vars = VariableExtractor{}.Extract(data)
+	if len(vars.PermissionSets) != 0 || len(vars.Users) != 0 || len(vars.Groups) != 0 {
+		t.Errorf("expected no locals without UseLocals, got %+v", vars)
+	}
+}
+
+// useLocalsInfrastructure has ReadOnly and Engineering in two assignment
+// resources each, and alice and Admin in one.
+func useLocalsInfrastructure() *InfrastructureData {
+	data := testInfrastructure()
+	data.PermissionSets = append(data.PermissionSets, provider.PermissionSet{ID: "ps-2", Name: "Admin"})
+	data.PermissionSetAssignments = append(data.PermissionSetAssignments,
+		provider.PermissionSetAssignment{ID: "assign-3", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+		provider.PermissionSetAssignment{ID: "assign-4", PermissionSetID: "ps-2", PrincipalType: "GROUP", GroupName: "Engineering", AccountID: "111111111111"})
+	return data
+}
+
+func TestGenerateFiles_UseLocals(t *testing.T) {
+	data := useLocalsInfrastructure()
+
+	for _, useLocals := range []bool{true, false} {
+		mode := "off"
+		if useLocals {
+			mode = "on"
+		}
+		for _, style := range []string{StyleFlat, StyleForEach} {
+			t.Run(mode+"/"+style, func(t *testing.T) {
+				config := Config{ImportFormat: ImportFormatBlocks, Style: style, UseLocals: useLocals}
+				config.OutputDir = t.TempDir()
+				config.BaseURL = "https://prism.cloudkeeper.com"
+				config.Port = provider.DefaultPort
+				variables := VariableExtractor{UseLocals: useLocals}.Extract(data)
+				if err := (&Generator{Config: config}).Generate(data, variables); err != nil {
+					t.Fatalf("generating files: %s", err)
+				}
+				assertGoldenFile(t, filepath.Join(config.OutputDir, "assignments.tf"), filepath.Join("use-locals", mode, style, "assignments.tf"))
+				assertReferencesResolve(t, config.OutputDir)
+			})
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Supported values of the -backend flag
+const (
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+	BackendLocal = "local"
+)
+
+// backendSkeletons holds the settings written, commented out, for each
+// -backend value. Placeholders are upper case.
+var backendSkeletons = map[string][][2]string{
+	BackendS3: {
+		{"bucket", `"YOUR_STATE_BUCKET"`},
+		{"key", `"prism/terraform.tfstate"`},
+		{"region", `"us-east-1"`},
+	},
+	BackendGCS: {
+		{"bucket", `"YOUR_STATE_BUCKET"`},
+		{"prefix", `"prism"`},
+	},
+	BackendLocal: {
+		{"path", `"terraform.tfstate"`},
+	},
+}
+
+// versionPattern matches the versions accepted by -provider-version and
+// -terraform-version: MAJOR.MINOR or MAJOR.MINOR.PATCH.
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(\.\d+)?$`)
+
+// PessimisticConstraint turns a -provider-version or -terraform-version value
+// into a "~>" constraint, which allows newer releases up to the last number
+// given: "1.2" allows 1.x from 1.2 on, "1.2.3" allows 1.2.x from 1.2.3 on.
+func PessimisticConstraint(flagName, version string) (string, error) {
+	if !versionPattern.MatchString(version) {
+		return "", fmt.Errorf("%s must be a version such as 1.2 or 1.2.3, got %q", flagName, version)
+	}
+	return "~> " + version, nil
+}
+
+// CheckTerraformVersion reports an error when version is older than
+// Terraform 1.5, which import blocks need.
+func CheckTerraformVersion(version string) error {
+	m := versionPattern.FindStringSubmatch(version)
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major < 1 || (major == 1 && minor < 5) {
+		return fmt.Errorf("-terraform-version %s is older than 1.5, which import blocks need; use -import-format=script", version)
+	}
+	return nil
+}
+
+// backendComment renders the commented-out backend block for backend.
+func backendComment(backend string) string {
+	settings := backendSkeletons[backend]
+	width := 0
+	for _, setting := range settings {
+		width = max(width, len(setting[0]))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Uncomment to store state in the %s backend, replacing the placeholders\n", backend)
+	fmt.Fprintf(&b, "# backend %q {\n", backend)
+	for _, setting := range settings {
+		fmt.Fprintf(&b, "#   %-*s = %s\n", width, setting[0], setting[1])
+	}
+	b.WriteString("# }\n")
+	return b.String()
+}
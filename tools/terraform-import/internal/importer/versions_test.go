@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateFiles_TerraformBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		file   string
+	}{
+		{name: "default", config: Config{ImportFormat: ImportFormatBlocks}},
+		{name: "default-script", config: Config{ImportFormat: ImportFormatScript}},
+		{name: "provider-version", config: Config{ImportFormat: ImportFormatBlocks, ProviderVersion: "~> 1.2"}},
+		{name: "terraform-version", config: Config{ImportFormat: ImportFormatBlocks, TerraformVersion: "~> 1.9"}},
+		{name: "both-versions", config: Config{ImportFormat: ImportFormatBlocks, ProviderVersion: "~> 1.2.3", TerraformVersion: "~> 1.9"}},
+		{name: "backend-s3", config: Config{ImportFormat: ImportFormatBlocks, Backend: BackendS3}},
+		{name: "backend-gcs", config: Config{ImportFormat: ImportFormatBlocks, Backend: BackendGCS}},
+		{name: "backend-local", config: Config{ImportFormat: ImportFormatBlocks, Backend: BackendLocal}},
+		{name: "all", config: Config{ImportFormat: ImportFormatScript, ProviderVersion: "~> 1.2", TerraformVersion: "~> 1.4", Backend: BackendS3}},
+		{name: "module", config: Config{ImportFormat: ImportFormatBlocks, Layout: LayoutModule, ModuleName: "prism", ProviderVersion: "~> 1.2", TerraformVersion: "~> 1.9"}, file: "versions.tf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			if file == "" {
+				file = "provider.tf"
+			}
+			dir := generateTestFiles(t, tt.config, testInfrastructure())
+			assertGoldenFile(t, filepath.Join(dir, file), filepath.Join("terraform-block", tt.name+".tf"))
+		})
+	}
+}
+
+func TestPessimisticConstraint(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "1.2", want: "~> 1.2"},
+		{version: "1.2.3", want: "~> 1.2.3"},
+		{version: "10.0", want: "~> 10.0"},
+		{version: "1", wantErr: true},
+		{version: "v1.2", wantErr: true},
+		{version: ">= 1.2", wantErr: true},
+		{version: "1.2.3-beta", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := PessimisticConstraint("-provider-version", tt.version)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", tt.version, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %q, got %q (%v)", tt.version, tt.want, got, err)
+		}
+	}
+}
+
+func TestCheckTerraformVersion(t *testing.T) {
+	for version, ok := range map[string]bool{
+		"1.5":   true,
+		"1.5.7": true,
+		"1.10":  true,
+		"2.0":   true,
+		"1.4":   false,
+		"1.4.9": false,
+		"0.15":  false,
+	} {
+		if err := CheckTerraformVersion(version); (err == nil) != ok {
+			t.Errorf("%s: expected ok=%t, got %v", version, ok, err)
+		}
+	}
+}
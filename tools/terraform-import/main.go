@@ -1,788 +1,692 @@
+// Command terraform-import generates Terraform configuration for an existing
+// Prism tenant. The generation itself lives in internal/importer; this file
+// parses flags, wires the pieces together and reports progress.
 package main
 
 import (
-	"encoding/json"
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"regexp"
-	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/tools/terraform-import/internal/importer"
 )
 
-type Config struct {
-	PrismSubdomain string
-	APIToken       string
-	OutputDir      string
-}
-
-type InfrastructureData struct {
-	AWSAccounts              []provider.AWSAccount
-	PermissionSets           []provider.PermissionSet
-	Users                    []provider.User
-	Groups                   []provider.Group
-	GroupMemberships         map[string][]string // group name -> usernames
-	PermissionSetAssignments []provider.PermissionSetAssignment
-}
+// defaultBaseURL is used when neither -base-url nor PRISM_BASE_URL is set
+const defaultBaseURL = "https://prism.cloudkeeper.com"
 
-type Variables struct {
-	AccountIDs     map[string]string // account_id -> variable name
-	PermissionSets map[string]string // permission set id -> variable name
-	Users          map[string]string // username -> variable name
-	Groups         map[string]string // group name -> variable name
+// Config holds the command line settings: the generation settings, plus
+// where the data comes from and what to do with it.
+type Config struct {
+	importer.Config
+
+	APIToken     string
+	Kinds        importer.ResourceKinds
+	Concurrency  int
+	UserFilter   *importer.NameFilter
+	GroupFilter  *importer.NameFilter
+	DryRun       bool
+	SkipDisabled bool
+	SkipEmpty    bool
+	ExportJSON   string
+	ReportJSON   string
+	FromJSON     string
+	DiffState    string
+	Quiet        bool
+	Verbose      bool
+	Strict       bool
+	RunImport    bool
+	Yes          bool
+	Terraform    string   // the terraform binary, with -run-import
+	Tenants      []Tenant // with -subdomains, exported one after another
 }
 
 func main() {
-	config := parseFlags()
-
-	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println("🔍 Connecting to Prism API...")
-	client := provider.NewClient(
-		fmt.Sprintf("https://%s.prism.cloudkeeper.com", config.PrismSubdomain),
-		config.PrismSubdomain,
-		config.APIToken,
-	)
-
-	fmt.Println("📦 Fetching infrastructure data...")
-	data, err := fetchAllData(client)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching data: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println("🔢 Analyzing and extracting variables...")
-	variables := extractVariables(data)
-
-	fmt.Println("📝 Generating Terraform files...")
-	if err := generateFiles(config.OutputDir, data, variables); err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating files: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println("✅ Successfully generated Terraform configuration!")
-	fmt.Printf("\n📁 Output directory: %s\n", config.OutputDir)
-	fmt.Println("\n📋 Generated files:")
-	fmt.Println("  - provider.tf        (provider configuration)")
-	fmt.Println("  - variables.tf       (variable definitions)")
-	fmt.Println("  - terraform.tfvars   (variable values)")
-	fmt.Println("  - aws_accounts.tf    (AWS account resources)")
-	fmt.Println("  - permission_sets.tf (permission set resources)")
-	fmt.Println("  - users.tf           (user resources)")
-	fmt.Println("  - groups.tf          (group and membership resources)")
-	fmt.Println("  - assignments.tf     (permission set assignments)")
-	fmt.Println("  - import.sh          (import commands script)")
-	fmt.Println("\n🚀 Next steps:")
-	fmt.Println("  1. cd", config.OutputDir)
-	fmt.Println("  2. Review the generated files")
-	fmt.Println("  3. Run: chmod +x import.sh")
-	fmt.Println("  4. Run: terraform init")
-	fmt.Println("  5. Run: ./import.sh")
-	fmt.Println("  6. Run: terraform plan")
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
 
-func parseFlags() Config {
-	var config Config
-
-	flag.StringVar(&config.PrismSubdomain, "subdomain", os.Getenv("PRISM_SUBDOMAIN"), "Prism subdomain (or set PRISM_SUBDOMAIN env var)")
-	flag.StringVar(&config.APIToken, "token", os.Getenv("PRISM_API_TOKEN"), "API token (or set PRISM_API_TOKEN env var)")
-	flag.StringVar(&config.OutputDir, "output", "./generated-terraform", "Output directory for generated files")
-	flag.Parse()
-
-	if config.PrismSubdomain == "" {
-		fmt.Fprintf(os.Stderr, "Error: Prism subdomain is required (use -subdomain flag or PRISM_SUBDOMAIN env var)\n")
-		os.Exit(1)
+// run runs the command with args and returns its exit code. Progress goes
+// to stdout and errors to stderr; stdin answers the -run-import prompt.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	config, err := parseFlags(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
 	}
-
-	if config.APIToken == "" {
-		fmt.Fprintf(os.Stderr, "Error: API token is required (use -token flag or PRISM_API_TOKEN env var)\n")
-		os.Exit(1)
-	}
-
-	return config
-}
-
-func fetchAllData(client *provider.Client) (*InfrastructureData, error) {
-	data := &InfrastructureData{
-		GroupMemberships: make(map[string][]string),
-	}
-
-	// Fetch AWS Accounts
-	fmt.Println("  → Fetching AWS accounts...")
-	accounts, err := client.ListAWSAccounts()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch AWS accounts: %w", err)
+	if errors.Is(err, errUsage) {
+		return 2
 	}
-	data.AWSAccounts = accounts
-	fmt.Printf("    Found %d AWS accounts\n", len(accounts))
-
-	// Fetch Permission Sets
-	fmt.Println("  → Fetching permission sets...")
-	permSets, err := client.ListPermissionSets()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch permission sets: %w", err)
-	}
-	data.PermissionSets = permSets
-	fmt.Printf("    Found %d permission sets\n", len(permSets))
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	c := newConsole(stdout, config.Quiet)
+	if len(config.Tenants) > 0 {
+		return runTenants(config, c, stderr)
+	}
+
+	report, data, code := generate(&config, c, stderr, &warningLog{w: stderr})
+	if report == nil {
+		return code
+	}
+	if config.ReportJSON != "" {
+		if err := report.WriteJSON(config.ReportJSON); err != nil {
+			fmt.Fprintf(stderr, "Error writing report: %v\n", err)
+			return 1
+		}
+		c.step("💾", "Wrote report to %s", config.ReportJSON)
+		c.line("")
+	}
+
+	if config.RunImport {
+		return runImport(config, data, c, stdin, stderr)
+	}
+	if config.OutputLayout == importer.OutputLayoutByGroupPath {
+		printGroupPathSummary(c, config, importer.GroupPathDirectories(data))
+		return 0
+	}
+	c.step("📋", "Generated files:")
+	if config.OutputLayout == importer.OutputLayoutSingle {
+		c.line("  - main.tf            (all resources, in dependency order)")
+		if config.Layout != importer.LayoutModule {
+			c.line("  - terraform.tfvars   (variable values)")
+		}
+	} else if config.Layout == importer.LayoutModule {
+		c.line("  - versions.tf        (provider requirements)")
+		c.line("  - variables.tf       (module inputs)")
+		c.line("  - outputs.tf         (module outputs)")
+	} else {
+		c.line("  - provider.tf        (provider configuration)")
+		c.line("  - variables.tf       (variable definitions)")
+		c.line("  - terraform.tfvars   (variable values)")
+	}
+	if config.WriteGitignore {
+		c.line("  - .gitignore         (keeps terraform.tfvars out of git)")
+	}
+	if config.OutputLayout != importer.OutputLayoutSingle {
+		c.line("  - aws_accounts.tf    (AWS account resources)")
+		c.line("  - permission_sets.tf (permission set resources)")
+		c.line("  - users.tf           (user resources)")
+		c.line("  - groups.tf          (group and membership resources)")
+		c.line("  - assignments.tf     (permission set assignments)")
+		c.line("  - identity_providers.tf (identity providers)")
+	}
+	if config.ImportFormat == importer.ImportFormatScript {
+		c.line("  - import.sh          (import commands script)")
+		c.line("  - import.ps1         (import commands script for PowerShell)")
+	} else if config.Layout == importer.LayoutModule {
+		c.line("  - %s (import blocks for the root module)", importer.RootImportsFile)
+	} else if config.OutputLayout != importer.OutputLayoutSingle {
+		c.line("  - imports.tf         (import blocks)")
+	}
+	c.line("")
+	c.step("🚀", "Next steps:")
+	if config.Layout == importer.LayoutModule {
+		c.line("  1. Call the module from your root module: module %q { source = %q }", config.ModuleName, config.OutputDir)
+		if config.ImportFormat == importer.ImportFormatScript {
+			c.line("  2. Run: terraform init")
+			c.line("  3. Run import.sh (or import.ps1 on Windows) from the root module's directory")
+			c.line("  4. Run: terraform plan")
+		} else {
+			c.line("  2. Copy %s into the root module as imports.tf", importer.RootImportsFile)
+			c.line("  3. Run: terraform init")
+			c.line("  4. Run: terraform plan")
+			c.line("  5. Run: terraform apply")
+		}
+		return 0
+	}
+	c.line("  1. cd %s", config.OutputDir)
+	c.line("  2. Review the generated files")
+	c.line("  3. Run: export PRISM_API_TOKEN=<your token>")
+	if config.ImportFormat == importer.ImportFormatScript {
+		c.line("  4. Run: chmod +x import.sh")
+		c.line("  5. Run: terraform init")
+		c.line("  6. Run: ./import.sh (or .\\import.ps1 in PowerShell)")
+		c.line("  7. Run: terraform plan")
+	} else {
+		c.line("  4. Run: terraform init")
+		c.line("  5. Run: terraform plan")
+		c.line("  6. Run: terraform apply")
+	}
+	return 0
+}
 
-	// Fetch Users
-	fmt.Println("  → Fetching users...")
-	users, err := client.ListUsers()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %w", err)
+// generate loads the data of config and generates its configuration,
+// printing a summary of the run. It returns the run's report and the data
+// the files were generated from, or a nil report and the exit code when
+// the run ends there: on an error, after a dry run or with -diff-state.
+func generate(config *Config, c *console, stderr io.Writer, warnings *warningLog) (*importer.Report, *importer.InfrastructureData, int) {
+	data, err := loadData(config, c, stderr, warnings)
+	var accessErr *importer.AccessError
+	if errors.As(err, &accessErr) {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return nil, nil, 1
 	}
-	data.Users = users
-	fmt.Printf("    Found %d users\n", len(users))
-
-	// Fetch Groups
-	fmt.Println("  → Fetching groups...")
-	groups, err := client.ListGroups()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+		fmt.Fprintf(stderr, "Error fetching data: %v\n", err)
+		return nil, nil, 1
 	}
-	data.Groups = groups
-	fmt.Printf("    Found %d groups\n", len(groups))
 
-	// Fetch Group Memberships
-	fmt.Println("  → Fetching group memberships...")
-	for _, group := range groups {
-		members, err := client.GetGroupMembers(group.Name)
-		if err != nil {
-			fmt.Printf("    Warning: failed to fetch members for group %s: %v\n", group.Name, err)
-			continue
-		}
-		if len(members) > 0 {
-			data.GroupMemberships[group.Name] = members
+	// Checked before filtering, which leaves literals on purpose
+	if config.NameMap != nil {
+		if unused := config.NameMap.Unused(data); len(unused) > 0 {
+			fmt.Fprintf(warnings, "Warning: -name-map entries that match nothing: %s\n", strings.Join(unused, ", "))
 		}
 	}
-	fmt.Printf("    Found memberships for %d groups\n", len(data.GroupMemberships))
-
-	// Fetch Permission Set Assignments
-	fmt.Println("  → Fetching permission set assignments...")
-	assignments, err := client.ListPermissionSetAssignments()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch permission set assignments: %w", err)
-	}
-	data.PermissionSetAssignments = assignments
-	fmt.Printf("    Found %d permission set assignments\n", len(assignments))
-
-	return data, nil
-}
-
-func extractVariables(data *InfrastructureData) *Variables {
-	vars := &Variables{
-		AccountIDs:     make(map[string]string),
-		PermissionSets: make(map[string]string),
-		Users:          make(map[string]string),
-		Groups:         make(map[string]string),
-	}
-
-	// Extract AWS account IDs that appear multiple times
-	accountUsage := make(map[string]int)
-	for _, assignment := range data.PermissionSetAssignments {
-		accountUsage[assignment.AccountID]++
-	}
-
-	for accountID, count := range accountUsage {
-		if count > 1 {
-			// Find the account name
-			for _, acc := range data.AWSAccounts {
-				if acc.AccountID == accountID {
-					varName := toVarName(acc.AccountName) + "_account_id"
-					vars.AccountIDs[accountID] = varName
-					break
-				}
-			}
+	if orphans := importer.FindOrphans(data, config.Kinds, config.NameMap); len(orphans) > 0 {
+		fmt.Fprintf(warnings, "Warning: %d references to resources that weren't fetched are written as literal values:\n", len(orphans))
+		for _, orphan := range orphans {
+			fmt.Fprintf(warnings, "  - %s\n", orphan)
 		}
 	}
 
-	return vars
-}
-
-func toVarName(s string) string {
-	// Convert to snake_case and remove special characters
-	reg := regexp.MustCompile(`[^a-zA-Z0-9]+`)
-	s = reg.ReplaceAllString(s, "_")
-	s = strings.ToLower(s)
-	s = strings.Trim(s, "_")
-	return s
-}
-
-func toResourceName(s string) string {
-	// Convert to valid Terraform resource name
-	reg := regexp.MustCompile(`[^a-zA-Z0-9_]+`)
-	s = reg.ReplaceAllString(s, "_")
-	s = strings.ToLower(s)
-	s = strings.Trim(s, "_")
-	return s
-}
-
-func generateFiles(outputDir string, data *InfrastructureData, variables *Variables) error {
-	// Generate provider.tf
-	if err := generateProviderFile(outputDir); err != nil {
-		return err
+	var matched importer.NameFilterSummary
+	if config.UserFilter != nil || config.GroupFilter != nil {
+		data, matched = importer.ApplyNameFilters(data, config.UserFilter, config.GroupFilter)
+		c.step("🔎", "Filters: %s", matched)
 	}
 
-	// Generate variables.tf
-	if err := generateVariablesFile(outputDir, variables); err != nil {
-		return err
+	var skipped importer.SkipSummary
+	if config.SkipDisabled || config.SkipEmpty {
+		data, skipped = importer.SkipObjects(data, importer.SkipOptions{DisabledUsers: config.SkipDisabled, EmptyGroups: config.SkipEmpty})
+		c.step("⏭️ ", "Skipped %s", skipped)
 	}
 
-	// Generate terraform.tfvars
-	if err := generateTFVarsFile(outputDir, variables); err != nil {
-		return err
+	if config.DryRun {
+		// The summary is the result of a dry run, so -quiet doesn't hide it
+		c.result("🧪", "Dry run: would generate %s", importer.CountResources(data))
+		return nil, nil, 0
 	}
 
-	// Generate AWS accounts
-	if err := generateAWSAccountsFile(outputDir, data.AWSAccounts); err != nil {
-		return err
-	}
-
-	// Generate permission sets
-	if err := generatePermissionSetsFile(outputDir, data.PermissionSets); err != nil {
-		return err
-	}
-
-	// Generate users
-	if err := generateUsersFile(outputDir, data.Users); err != nil {
-		return err
+	if config.DiffState != "" {
+		c.step("🔎", "Comparing with %s...", config.DiffState)
+		report, err := importer.DiffState(config.DiffState, data, config.Kinds, config.Style, config.NameMap)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error comparing state: %v\n", err)
+			return nil, nil, 1
+		}
+		// The report is the result, so -quiet doesn't hide it
+		report.Write(c.stdout)
+		if report.HasDrift() {
+			// Distinct from errors, so CI can tell drift from a failed run
+			return nil, nil, 2
+		}
+		return nil, nil, 0
 	}
 
-	// Generate groups
-	if err := generateGroupsFile(outputDir, data.Groups, data.GroupMemberships); err != nil {
-		return err
+	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+		fmt.Fprintf(stderr, "Error creating output directory: %v\n", err)
+		return nil, nil, 1
 	}
 
-	// Generate permission set assignments
-	if err := generateAssignmentsFile(outputDir, data); err != nil {
-		return err
-	}
+	c.step("🔢", "Analyzing and extracting variables...")
+	variables := importer.VariableExtractor{NameMap: config.NameMap, Accounts: config.VarAccounts, UseLocals: config.UseLocals}.Extract(data)
 
-	// Generate import script
-	if err := generateImportScript(outputDir, data); err != nil {
-		return err
+	c.step("📝", "Generating Terraform files...")
+	generator := &importer.Generator{Config: config.Config}
+	if err := generator.Generate(data, variables); err != nil {
+		fmt.Fprintf(stderr, "Error generating files: %v\n", err)
+		return nil, nil, 1
 	}
 
-	return nil
-}
-
-func generateProviderFile(outputDir string) error {
-	content := `terraform {
-  required_version = ">= 1.0"
+	c.step("✅", "Successfully generated Terraform configuration!")
+	c.line("")
+	c.step("📁", "Output directory: %s", config.OutputDir)
+	c.line("")
 
-  required_providers {
-    prism = {
-      source = "CloudKeeper-Inc/prism"
-    }
-  }
+	report := importer.NewReport(config.Config, data)
+	report.Skipped = reportSkipped(config.Kinds, matched, skipped)
+	report.Warnings = warnings.Warnings()
+	c.step("📊", "Summary:")
+	report.WriteSummary(c.out)
+	c.line("")
+	return report, data, 0
 }
 
-provider "prism" {
-  prism_subdomain = var.prism_subdomain
-  api_token       = var.prism_api_token
-}
-`
-	return os.WriteFile(filepath.Join(outputDir, "provider.tf"), []byte(content), 0644)
-}
-
-func generateVariablesFile(outputDir string, variables *Variables) error {
-	var sb strings.Builder
-
-	sb.WriteString("# Provider Configuration Variables\n\n")
-	sb.WriteString("variable \"prism_subdomain\" {\n")
-	sb.WriteString("  type        = string\n")
-	sb.WriteString("  description = \"Prism subdomain\"\n")
-	sb.WriteString("  sensitive   = false\n")
-	sb.WriteString("}\n\n")
-
-	sb.WriteString("variable \"prism_api_token\" {\n")
-	sb.WriteString("  type        = string\n")
-	sb.WriteString("  description = \"Prism API token\"\n")
-	sb.WriteString("  sensitive   = true\n")
-	sb.WriteString("}\n")
-
-	// Add account ID variables if any
-	if len(variables.AccountIDs) > 0 {
-		sb.WriteString("\n# AWS Account ID Variables\n")
-
-		// Sort for consistent output
-		var accountIDs []string
-		for accountID := range variables.AccountIDs {
-			accountIDs = append(accountIDs, accountID)
-		}
-		sort.Strings(accountIDs)
-
-		for _, accountID := range accountIDs {
-			varName := variables.AccountIDs[accountID]
-			sb.WriteString(fmt.Sprintf("\nvariable \"%s\" {\n", varName))
-			sb.WriteString("  type        = string\n")
-			sb.WriteString(fmt.Sprintf("  description = \"AWS Account ID (%s)\"\n", accountID))
-			sb.WriteString("}\n")
+// runImport imports the generated resources by running terraform in each
+// generated configuration, after asking for confirmation unless -yes was
+// given.
+func runImport(config Config, data *importer.InfrastructureData, c *console, stdin io.Reader, stderr io.Writer) int {
+	runner := &importer.TerraformRunner{
+		Terraform:    config.Terraform,
+		ImportFormat: config.ImportFormat,
+		Stdout:       c.out,
+		Stderr:       stderr,
+	}
+
+	// The workspaces are shown so that a wrong one is caught before the
+	// imports land in it; without -yes they are shown even with -quiet
+	w := c.out
+	if !config.Yes {
+		w = c.stdout
+	}
+	dirs := importer.ImportDirectories(config.Config, data)
+	total := 0
+	fmt.Fprintln(w, "Importing with terraform:")
+	for _, dir := range dirs {
+		path := filepath.Join(config.OutputDir, filepath.FromSlash(dir.Dir))
+		workspace, err := runner.Workspace(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
 		}
+		fmt.Fprintf(w, "  - %d resources into workspace %q of %s\n", len(dir.Targets), workspace, path)
+		total += len(dir.Targets)
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "variables.tf"), []byte(sb.String()), 0644)
-}
-
-func generateTFVarsFile(outputDir string, variables *Variables) error {
-	var sb strings.Builder
-
-	sb.WriteString("# Provider Configuration\n")
-	sb.WriteString("prism_subdomain = \"YOUR_SUBDOMAIN_HERE\"\n")
-	sb.WriteString("prism_api_token = \"YOUR_API_TOKEN_HERE\"\n")
-
-	if len(variables.AccountIDs) > 0 {
-		sb.WriteString("\n# AWS Account IDs\n")
-
-		var accountIDs []string
-		for accountID := range variables.AccountIDs {
-			accountIDs = append(accountIDs, accountID)
+	if !config.Yes {
+		fmt.Fprint(w, "Run terraform init and import them? [y/N] ")
+		var answer string
+		if stdin != nil {
+			answer, _ = bufio.NewReader(stdin).ReadString('\n')
 		}
-		sort.Strings(accountIDs)
-
-		for _, accountID := range accountIDs {
-			varName := variables.AccountIDs[accountID]
-			sb.WriteString(fmt.Sprintf("%s = \"%s\"\n", varName, accountID))
+		fmt.Fprintln(w)
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintf(stderr, "Import cancelled; the generated files are in %s. Pass -yes to import without asking.\n", config.OutputDir)
+			return 1
 		}
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "terraform.tfvars"), []byte(sb.String()), 0644)
-}
-
-func generateAWSAccountsFile(outputDir string, accounts []provider.AWSAccount) error {
-	if len(accounts) == 0 {
-		return nil
-	}
-
-	var sb strings.Builder
-	sb.WriteString("# AWS Accounts\n\n")
-
-	for _, acc := range accounts {
-		resourceName := toResourceName(acc.AccountName)
-		sb.WriteString(fmt.Sprintf("resource \"prism_aws_account\" \"%s\" {\n", resourceName))
-		sb.WriteString(fmt.Sprintf("  account_id   = \"%s\"\n", acc.AccountID))
-		sb.WriteString(fmt.Sprintf("  account_name = \"%s\"\n", escapeString(acc.AccountName)))
-		if acc.Region != "" {
-			sb.WriteString(fmt.Sprintf("  region       = \"%s\"\n", acc.Region))
+	for _, dir := range dirs {
+		path := filepath.Join(config.OutputDir, filepath.FromSlash(dir.Dir))
+		c.step("🚚", "Importing %d resources into %s...", len(dir.Targets), path)
+		if err := runner.Import(path, dir.Targets); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			if config.ImportFormat == importer.ImportFormatScript {
+				fmt.Fprintf(stderr, "The resources imported so far are recorded in %s; run the same command again to import the rest.\n", filepath.Join(path, importer.ImportCheckpointFile))
+			} else {
+				fmt.Fprintln(stderr, "Run the same command again to retry; Terraform skips the resources that are already imported.")
+			}
+			return 1
 		}
-		sb.WriteString("}\n\n")
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "aws_accounts.tf"), []byte(sb.String()), 0644)
+	c.line("")
+	c.step("✅", "Imported %d resources", total)
+	c.step("🚀", "Next step: run terraform plan in %s and review any differences", config.OutputDir)
+	return 0
 }
 
-func generatePermissionSetsFile(outputDir string, permSets []provider.PermissionSet) error {
-	if len(permSets) == 0 {
-		return nil
-	}
-
-	var sb strings.Builder
-	sb.WriteString("# Permission Sets\n\n")
-
-	for _, ps := range permSets {
-		resourceName := toResourceName(ps.Name)
-		sb.WriteString(fmt.Sprintf("resource \"prism_permission_set\" \"%s\" {\n", resourceName))
-		sb.WriteString(fmt.Sprintf("  name        = \"%s\"\n", escapeString(ps.Name)))
-
-		if ps.Description != "" {
-			sb.WriteString(fmt.Sprintf("  description = \"%s\"\n", escapeString(ps.Description)))
-		}
+// printGroupPathSummary prints the directories and next steps of
+// -output-layout=by-group-path, where each directory is its own root
+// configuration.
+func printGroupPathSummary(c *console, config Config, dirs []string) {
+	c.step("📋", "Generated one configuration per directory, each with its own state:")
+	for _, dir := range dirs {
+		c.line("  - %s", dir)
+	}
+	c.line("")
+	c.step("🚀", "Next steps:")
+	c.line("  1. cd %s", config.OutputDir)
+	c.line("  2. Review the generated files")
+	c.line("  3. Run: export PRISM_API_TOKEN=<your token>")
+	if config.ImportFormat == importer.ImportFormatScript {
+		c.line("  4. Run: ./import.sh (or .\\import.ps1 in PowerShell), which runs terraform init and the imports in each directory")
+		c.line("  5. Run terraform plan in each directory")
+		return
+	}
+	c.line("  4. In each directory, run: terraform init, terraform plan and terraform apply")
+}
 
-		if ps.SessionDuration != "" {
-			sb.WriteString(fmt.Sprintf("  session_duration = \"%s\"\n", ps.SessionDuration))
-		}
+// errUsage reports a flag the flag package rejected and already printed
+// usage for.
+var errUsage = errors.New("invalid usage")
 
-		if len(ps.ManagedPolicies) > 0 {
-			sb.WriteString("\n  managed_policies = [\n")
-			for _, policy := range ps.ManagedPolicies {
-				sb.WriteString(fmt.Sprintf("    \"%s\",\n", policy))
-			}
-			sb.WriteString("  ]\n")
-		}
+func parseFlags(args []string, stderr io.Writer) (Config, error) {
+	var config Config
 
-		if len(ps.InlinePolicies) > 0 {
-			sb.WriteString("\n  inline_policies = {\n")
-			for name, policy := range ps.InlinePolicies {
-				// Pretty print JSON
-				var policyObj interface{}
-				if err := json.Unmarshal([]byte(policy), &policyObj); err == nil {
-					prettyJSON, err := json.MarshalIndent(policyObj, "    ", "  ")
-					if err == nil {
-						sb.WriteString(fmt.Sprintf("    %s = <<-EOT\n%s\nEOT\n", name, indent(string(prettyJSON), 4)))
-					} else {
-						// Fallback to raw policy if marshal fails
-						sb.WriteString(fmt.Sprintf("    %s = %q\n", name, policy))
-					}
-				} else {
-					sb.WriteString(fmt.Sprintf("    %s = %q\n", name, policy))
-				}
-			}
-			sb.WriteString("  }\n")
+	fs := flag.NewFlagSet("terraform-import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.StringVar(&config.PrismSubdomain, "subdomain", os.Getenv("PRISM_SUBDOMAIN"), "Prism subdomain (or set PRISM_SUBDOMAIN env var)")
+	subdomains := fs.String("subdomains", "", "Comma-separated Prism subdomains to export in one run, each into its own sub-directory of -output")
+	fs.StringVar(&config.APIToken, "token", "", "API token (or set PRISM_API_TOKEN_<SUBDOMAIN> or PRISM_API_TOKEN env var)")
+	fs.StringVar(&config.BaseURL, "base-url", envOrDefault("PRISM_BASE_URL", defaultBaseURL), "Base URL of the Prism API, without port (or set PRISM_BASE_URL env var)")
+	fs.Int64Var(&config.Port, "port", provider.DefaultPort, "Port of the Prism API (or set PRISM_PORT env var)")
+	userFilter := fs.String("user-filter", "", "Only generate users whose username matches this regular expression (prefix with ! to invert)")
+	groupFilter := fs.String("group-filter", "", "Only generate groups whose name matches this regular expression (prefix with ! to invert)")
+	fs.BoolVar(&config.DryRun, "dry-run", false, "Print what would be generated, without writing any files")
+	fs.BoolVar(&config.SkipDisabled, "skip-disabled-users", false, "Leave out disabled users, along with their group memberships and assignments")
+	fs.BoolVar(&config.SkipEmpty, "skip-empty-groups", false, "Leave out groups without members, along with their assignments")
+	fs.StringVar(&config.ExportJSON, "export-json", "", "Also write the fetched data to this JSON file, for use with -from-json")
+	fs.StringVar(&config.ReportJSON, "report-json", "", "Also write the run summary, with the Terraform address of every Prism object, to this JSON file")
+	fs.StringVar(&config.FromJSON, "from-json", "", "Generate from a file written by -export-json instead of calling the API")
+	fs.StringVar(&config.DiffState, "diff-state", "", "Instead of generating files, report drift between Prism and this terraform.tfstate file")
+	fs.StringVar(&config.OutputDir, "output", "./generated-terraform", "Output directory for generated files")
+	fs.StringVar(&config.ImportFormat, "import-format", importer.ImportFormatBlocks, "How to import resources: blocks (imports.tf, Terraform >= 1.5) or script (import.sh and import.ps1)")
+	fs.StringVar(&config.Style, "style", importer.StyleFlat, "How to generate users, groups, memberships and assignments: flat (one resource block each) or foreach (locals maps and one for_each resource per type)")
+	fs.StringVar(&config.MembershipStyle, "membership-style", importer.MembershipStyleAuthoritative, "How to generate group memberships: authoritative (one prism_group_membership per group, removing other members) or individual (one prism_group_member per member, leaving other members alone)")
+	fs.StringVar(&config.Layout, "layout", importer.LayoutRoot, "Generate a root configuration (root) or a module with inputs and outputs (module)")
+	fs.StringVar(&config.OutputLayout, "output-layout", importer.OutputLayoutSplit, "How to lay out the generated files: split (one file per resource type), single (everything in main.tf) or by-group-path (one configuration per top-level group path, plus common)")
+	fs.StringVar(&config.VarAccounts, "var-accounts", importer.VarAccountsReused, "Which AWS account IDs become variables: all (every referenced account), reused (accounts in more than one assignment) or none")
+	nameMap := fs.String("name-map", "", "YAML or JSON file choosing the resource names of users, groups, permission sets and AWS accounts")
+	fs.StringVar(&config.ModuleName, "module-name", "prism", "With -layout=module, the name the root module calls the module by, used in import addresses")
+	providerVersion := fs.String("provider-version", "", "Pin the prism provider to this version, as ~> VERSION (default unpinned)")
+	terraformVersion := fs.String("terraform-version", "", "Require this Terraform version, as ~> VERSION (default >= 1.5, or >= 1.0 with -import-format=script)")
+	fs.BoolVar(&config.UseLocals, "use-locals", false, "Refer to permission sets and principals in more than one assignment through locals in assignments.tf")
+	fs.BoolVar(&config.WriteGitignore, "write-gitignore", false, "Add terraform.tfvars to .gitignore in the output directory")
+	fs.StringVar(&config.Backend, "backend", "", "Add a commented-out backend block to fill in: s3, gcs or local")
+	fs.BoolVar(&config.Quiet, "quiet", false, "Print only errors and results, for CI")
+	fs.BoolVar(&config.Verbose, "verbose", false, "Log each API call with its duration to stderr")
+	fs.BoolVar(&config.Strict, "strict", false, "Stop at the first list that can't be fetched, instead of generating the rest")
+	fs.BoolVar(&config.RunImport, "run-import", false, "After generating, run terraform init and the imports with the terraform binary in PATH")
+	fs.BoolVar(&config.Yes, "yes", false, "With -run-import, don't ask for confirmation")
+	fs.IntVar(&config.Concurrency, "concurrency", 5, "Maximum number of API requests in flight while fetching")
+	include := fs.String("include", "", "Comma-separated resource kinds to export (default all): "+strings.Join(importer.AllKinds, ", "))
+	exclude := fs.String("exclude", "", "Comma-separated resource kinds to skip")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return config, err
+		}
+		return config, errUsage
+	}
+
+	// The flags win over PRISM_PORT and the token variables when both are
+	// given
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if v := os.Getenv("PRISM_PORT"); v != "" && !set["port"] {
+		port, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return config, fmt.Errorf("PRISM_PORT must be a port number, got %q", v)
 		}
-
-		sb.WriteString("}\n\n")
+		config.Port = port
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "permission_sets.tf"), []byte(sb.String()), 0644)
-}
-
-func generateUsersFile(outputDir string, users []provider.User) error {
-	if len(users) == 0 {
-		return nil
+	if config.Quiet && config.Verbose {
+		return config, errors.New("-quiet and -verbose can't be used together")
 	}
 
-	var sb strings.Builder
-	sb.WriteString("# Users\n\n")
-
-	for _, user := range users {
-		resourceName := toResourceName(user.Username)
-		sb.WriteString(fmt.Sprintf("resource \"prism_user\" \"%s\" {\n", resourceName))
-		sb.WriteString(fmt.Sprintf("  username   = \"%s\"\n", escapeString(user.Username)))
-		sb.WriteString(fmt.Sprintf("  email      = \"%s\"\n", escapeString(user.Email)))
-
-		if user.FirstName != "" {
-			sb.WriteString(fmt.Sprintf("  first_name = \"%s\"\n", escapeString(user.FirstName)))
+	if config.RunImport {
+		if config.DryRun || config.DiffState != "" {
+			return config, errors.New("-run-import can't be used with -dry-run or -diff-state, which don't generate files")
 		}
-
-		if user.LastName != "" {
-			sb.WriteString(fmt.Sprintf("  last_name  = \"%s\"\n", escapeString(user.LastName)))
+		if config.Layout == importer.LayoutModule {
+			return config, errors.New("-run-import can't be used with -layout=module; run the imports from the root module that calls it")
 		}
-
-		sb.WriteString(fmt.Sprintf("  enabled    = %t\n", user.Enabled))
-
-		if len(user.Attributes) > 0 {
-			sb.WriteString("\n  attributes = {\n")
-			// Sort keys for consistent output
-			var keys []string
-			for k := range user.Attributes {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-
-			for _, k := range keys {
-				values := user.Attributes[k]
-				if len(values) > 0 {
-					sb.WriteString(fmt.Sprintf("    %s = \"%s\"\n", k, escapeString(values[0])))
-				}
-			}
-			sb.WriteString("  }\n")
+		terraform, err := exec.LookPath("terraform")
+		if err != nil {
+			return config, fmt.Errorf("-run-import needs terraform in PATH: %w", err)
 		}
-
-		sb.WriteString("}\n\n")
+		config.Terraform = terraform
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "users.tf"), []byte(sb.String()), 0644)
-}
-
-func generateGroupsFile(outputDir string, groups []provider.Group, memberships map[string][]string) error {
-	if len(groups) == 0 {
-		return nil
+	if config.FromJSON != "" && config.ExportJSON != "" {
+		return config, errors.New("-from-json and -export-json can't be used together")
 	}
 
-	var sb strings.Builder
-	sb.WriteString("# Groups\n\n")
-
-	for _, group := range groups {
-		resourceName := toResourceName(group.Name)
-		sb.WriteString(fmt.Sprintf("resource \"prism_group\" \"%s\" {\n", resourceName))
-		sb.WriteString(fmt.Sprintf("  name        = \"%s\"\n", escapeString(group.Name)))
+	if config.ReportJSON != "" && (config.DryRun || config.DiffState != "") {
+		return config, errors.New("-report-json can't be used with -dry-run or -diff-state, which don't generate files")
+	}
 
-		if group.Description != "" {
-			sb.WriteString(fmt.Sprintf("  description = \"%s\"\n", escapeString(group.Description)))
+	if *subdomains != "" {
+		if err := checkTenantFlags(config, set["subdomain"]); err != nil {
+			return config, err
 		}
-
-		if group.Path != "" {
-			sb.WriteString(fmt.Sprintf("  path        = \"%s\"\n", escapeString(group.Path)))
+		tenants, err := parseTenants(*subdomains, config.APIToken, set["token"])
+		if err != nil {
+			return config, err
 		}
-
-		sb.WriteString("}\n\n")
+		config.Tenants = tenants
+		config.PrismSubdomain, config.APIToken = "", ""
+	} else {
+		config.APIToken = resolveToken(config.PrismSubdomain, config.APIToken, set["token"])
 	}
 
-	// Group memberships
-	if len(memberships) > 0 {
-		sb.WriteString("# Group Memberships\n\n")
-
-		for groupName, members := range memberships {
-			if len(members) == 0 {
-				continue
-			}
-
-			resourceName := toResourceName(groupName) + "_members"
-			groupResourceName := toResourceName(groupName)
-
-			sb.WriteString(fmt.Sprintf("resource \"prism_group_membership\" \"%s\" {\n", resourceName))
-			sb.WriteString(fmt.Sprintf("  group_name = prism_group.%s.name\n", groupResourceName))
-			sb.WriteString("  usernames  = [\n")
-
-			for _, member := range members {
-				userResourceName := toResourceName(member)
-				sb.WriteString(fmt.Sprintf("    prism_user.%s.username,\n", userResourceName))
-			}
-
-			sb.WriteString("  ]\n")
-			sb.WriteString("}\n\n")
-		}
+	// Credentials are only needed to call the API
+	if config.PrismSubdomain == "" && config.FromJSON == "" && config.Tenants == nil {
+		return config, errors.New("Prism subdomain is required (use -subdomain flag or PRISM_SUBDOMAIN env var)")
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "groups.tf"), []byte(sb.String()), 0644)
-}
+	if config.APIToken == "" && config.FromJSON == "" && config.Tenants == nil {
+		return config, errors.New("API token is required (use -token flag or PRISM_API_TOKEN env var)")
+	}
 
-func generateAssignmentsFile(outputDir string, data *InfrastructureData) error {
-	if len(data.PermissionSetAssignments) == 0 {
-		return nil
+	if config.BaseURL == "" {
+		return config, errors.New("Prism base URL is required (use -base-url flag or PRISM_BASE_URL env var)")
 	}
 
-	// Group assignments by permission set + principal
-	type assignmentKey struct {
-		PermissionSetID string
-		PrincipalType   string
-		PrincipalID     string
+	if config.Port < 1 || config.Port > 65535 {
+		return config, fmt.Errorf("-port must be between 1 and 65535, got %d", config.Port)
 	}
 
-	grouped := make(map[assignmentKey][]string)
+	if config.Concurrency < 1 {
+		return config, fmt.Errorf("-concurrency must be at least 1, got %d", config.Concurrency)
+	}
 
-	for _, assignment := range data.PermissionSetAssignments {
-		principalID := assignment.Username
-		if assignment.PrincipalType == "GROUP" {
-			principalID = assignment.GroupName
-		}
+	if err := config.Config.Validate(); err != nil {
+		return config, err
+	}
 
-		key := assignmentKey{
-			PermissionSetID: assignment.PermissionSetID,
-			PrincipalType:   assignment.PrincipalType,
-			PrincipalID:     principalID,
+	if *providerVersion != "" {
+		constraint, err := importer.PessimisticConstraint("-provider-version", *providerVersion)
+		if err != nil {
+			return config, err
 		}
-
-		grouped[key] = append(grouped[key], assignment.AccountID)
+		config.ProviderVersion = constraint
 	}
 
-	var sb strings.Builder
-	sb.WriteString("# Permission Set Assignments\n\n")
-
-	counter := 0
-	for key, accountIDs := range grouped {
-		counter++
-
-		// Find permission set name
-		permSetName := ""
-		for _, ps := range data.PermissionSets {
-			if ps.ID == key.PermissionSetID {
-				permSetName = ps.Name
-				break
-			}
+	if *terraformVersion != "" {
+		constraint, err := importer.PessimisticConstraint("-terraform-version", *terraformVersion)
+		if err == nil && config.ImportFormat == importer.ImportFormatBlocks {
+			err = importer.CheckTerraformVersion(*terraformVersion)
 		}
-
-		resourceName := fmt.Sprintf("assignment_%d", counter)
-		if permSetName != "" && key.PrincipalID != "" {
-			resourceName = toResourceName(permSetName + "_" + key.PrincipalID)
+		if err != nil {
+			return config, err
 		}
+		config.TerraformVersion = constraint
+	}
 
-		sb.WriteString(fmt.Sprintf("resource \"prism_permission_set_assignment\" \"%s\" {\n", resourceName))
-
-		// Find permission set resource
-		permSetResourceName := toResourceName(permSetName)
-		sb.WriteString(fmt.Sprintf("  permission_set_id = prism_permission_set.%s.id\n", permSetResourceName))
-		sb.WriteString(fmt.Sprintf("  principal_type    = \"%s\"\n", key.PrincipalType))
+	kinds, err := importer.ParseResourceKinds(*include, *exclude)
+	if err != nil {
+		return config, err
+	}
+	config.Kinds = kinds
 
-		if key.PrincipalType == "USER" {
-			userResourceName := toResourceName(key.PrincipalID)
-			sb.WriteString(fmt.Sprintf("  principal_id      = prism_user.%s.username\n", userResourceName))
-		} else {
-			groupResourceName := toResourceName(key.PrincipalID)
-			sb.WriteString(fmt.Sprintf("  principal_id      = prism_group.%s.name\n", groupResourceName))
-		}
+	// Checked here so that a typo fails before any API calls
+	if config.UserFilter, err = importer.ParseNameFilter("-user-filter", *userFilter); err != nil {
+		return config, err
+	}
+	if config.GroupFilter, err = importer.ParseNameFilter("-group-filter", *groupFilter); err != nil {
+		return config, err
+	}
 
-		sb.WriteString("  account_ids       = [\n")
-		for _, accountID := range accountIDs {
-			// Find account resource name
-			accountResourceName := ""
-			for _, acc := range data.AWSAccounts {
-				if acc.AccountID == accountID {
-					accountResourceName = toResourceName(acc.AccountName)
-					break
-				}
-			}
-			if accountResourceName != "" {
-				sb.WriteString(fmt.Sprintf("    prism_aws_account.%s.account_id,\n", accountResourceName))
-			} else {
-				sb.WriteString(fmt.Sprintf("    \"%s\",\n", accountID))
-			}
+	if *nameMap != "" {
+		if config.NameMap, err = importer.LoadNameMap(*nameMap); err != nil {
+			return config, err
 		}
-		sb.WriteString("  ]\n")
-		sb.WriteString("}\n\n")
 	}
 
-	return os.WriteFile(filepath.Join(outputDir, "assignments.tf"), []byte(sb.String()), 0644)
+	return config, nil
 }
 
-func generateImportScript(outputDir string, data *InfrastructureData) error {
-	var sb strings.Builder
-
-	sb.WriteString("#!/bin/bash\n")
-	sb.WriteString("# Terraform import script - generated automatically\n")
-	sb.WriteString("# This script imports existing resources into Terraform state\n\n")
-	sb.WriteString("set -e\n\n")
-	sb.WriteString("echo \"Starting Terraform import process...\"\n\n")
-
-	// Import AWS accounts
-	if len(data.AWSAccounts) > 0 {
-		sb.WriteString("# Import AWS Accounts\n")
-		sb.WriteString("echo \"Importing AWS accounts...\"\n")
-		for _, acc := range data.AWSAccounts {
-			resourceName := toResourceName(acc.AccountName)
-			sb.WriteString(fmt.Sprintf("terraform import prism_aws_account.%s %s\n", resourceName, acc.AccountID))
-		}
-		sb.WriteString("\n")
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
+}
+
+// newClient builds the API client the same way the provider's Configure
+// does, so the tool and the generated configuration reach the same endpoint.
+func newClient(config Config) *provider.Client {
+	return provider.NewClient(provider.APIBaseURL(config.BaseURL, config.Port), config.PrismSubdomain, config.APIToken)
+}
 
-	// Import permission sets
-	if len(data.PermissionSets) > 0 {
-		sb.WriteString("# Import Permission Sets\n")
-		sb.WriteString("echo \"Importing permission sets...\"\n")
-		for _, ps := range data.PermissionSets {
-			resourceName := toResourceName(ps.Name)
-			sb.WriteString(fmt.Sprintf("terraform import prism_permission_set.%s %s\n", resourceName, ps.ID))
+// reportSkipped counts what the run left out: the kinds that weren't
+// fetched, and what the name filters and -skip-disabled/-skip-empty removed.
+func reportSkipped(kinds importer.ResourceKinds, matched importer.NameFilterSummary, skipped importer.SkipSummary) importer.ReportSkipped {
+	report := importer.ReportSkipped{
+		NotFetched:     []string{},
+		FilteredUsers:  matched.TotalUsers - matched.Users,
+		FilteredGroups: matched.TotalGroups - matched.Groups,
+		DisabledUsers:  skipped.Users,
+		EmptyGroups:    skipped.Groups,
+		Memberships:    skipped.Memberships,
+		Assignments:    matched.Assignments + skipped.Assignments,
+	}
+	for _, kind := range importer.AllKinds {
+		if !kinds[kind] {
+			report.NotFetched = append(report.NotFetched, kind)
 		}
-		sb.WriteString("\n")
 	}
+	return report
+}
 
-	// Import users
-	if len(data.Users) > 0 {
-		sb.WriteString("# Import Users\n")
-		sb.WriteString("echo \"Importing users...\"\n")
-		for _, user := range data.Users {
-			resourceName := toResourceName(user.Username)
-			sb.WriteString(fmt.Sprintf("terraform import prism_user.%s %s\n", resourceName, user.ID))
+// loadData fetches the infrastructure from the API, or reads it from the
+// -from-json dump, and writes the -export-json dump if requested. Without
+// -subdomain, a dump's subdomain is used for terraform.tfvars. Kinds that
+// couldn't be fetched are removed from config.Kinds. Fetch warnings go to
+// warn.
+func loadData(config *Config, c *console, stderr, warn io.Writer) (*importer.InfrastructureData, error) {
+	if config.FromJSON != "" {
+		c.step("📂", "Reading %s...", config.FromJSON)
+		d, err := importer.LoadDump(config.FromJSON)
+		if err != nil {
+			return nil, err
+		}
+		c.line("    Exported from %s at %s", d.PrismSubdomain, d.ExportedAt.Format(time.RFC3339))
+		if config.PrismSubdomain == "" {
+			config.PrismSubdomain = d.PrismSubdomain
 		}
-		sb.WriteString("\n")
+		return importer.FilterData(d.Data, config.Kinds), nil
 	}
 
-	// Import groups
-	if len(data.Groups) > 0 {
-		sb.WriteString("# Import Groups\n")
-		sb.WriteString("echo \"Importing groups...\"\n")
-		for _, group := range data.Groups {
-			resourceName := toResourceName(group.Name)
-			sb.WriteString(fmt.Sprintf("terraform import prism_group.%s %s\n", resourceName, group.ID))
-		}
-		sb.WriteString("\n")
+	c.step("🔍", "Connecting to Prism API...")
+	client := newClient(*config)
+	client.TimingLog = io.Discard
+	if config.Verbose {
+		client.TimingLog = stderr
 	}
 
-	// Import group memberships
-	groupsWithMembers := 0
-	for _, members := range data.GroupMemberships {
-		if len(members) > 0 {
-			groupsWithMembers++
-		}
+	fetcher := &importer.Fetcher{
+		Client:      client,
+		Kinds:       config.Kinds,
+		Concurrency: config.Concurrency,
+		Retry:       importer.DefaultRetryPolicy,
+		// A dump doesn't record which lists are missing, so only write
+		// complete ones
+		Strict: config.Strict || config.ExportJSON != "",
+		Out:    c.out,
+		Warn:   warn,
 	}
-	if groupsWithMembers > 0 {
-		sb.WriteString("# Import Group Memberships\n")
-		sb.WriteString("echo \"Importing group memberships...\"\n")
-		for groupName, members := range data.GroupMemberships {
-			if len(members) == 0 {
-				continue
-			}
-			resourceName := toResourceName(groupName) + "_members"
-			sb.WriteString(fmt.Sprintf("terraform import prism_group_membership.%s %s\n", resourceName, groupName))
-		}
-		sb.WriteString("\n")
+	// Fail on bad credentials before spending time on the fetch
+	if err := fetcher.CheckAccess(config.PrismSubdomain); err != nil {
+		return nil, err
 	}
 
-	// Import permission set assignments
-	if len(data.PermissionSetAssignments) > 0 {
-		sb.WriteString("# Import Permission Set Assignments\n")
-		sb.WriteString("echo \"Importing permission set assignments...\"\n")
-
-		// Group assignments by permission set + principal to match Terraform resources
-		type assignmentKey struct {
-			PermissionSetID string
-			PrincipalType   string
-			PrincipalID     string
-		}
+	c.step("📦", "Fetching infrastructure data...")
+	data, err := fetcher.Fetch()
+	var partial *importer.PartialFetchError
+	if errors.As(err, &partial) {
+		// Treat the missing kinds as not fetched, so that references to
+		// them aren't reported as orphans and -diff-state doesn't report
+		// their resources as deleted
+		config.Kinds = partial.FetchedKinds(config.Kinds)
+	} else if err != nil {
+		return nil, err
+	}
 
-		type assignmentGroup struct {
-			AccountIDs    []string
-			AssignmentIDs []string
+	if config.ExportJSON != "" {
+		if err := importer.WriteDump(config.ExportJSON, config.PrismSubdomain, data); err != nil {
+			return nil, err
 		}
+		c.step("💾", "Wrote fetched data to %s", config.ExportJSON)
+	}
+	return data, nil
+}
 
-		grouped := make(map[assignmentKey]*assignmentGroup)
-
-		for _, assignment := range data.PermissionSetAssignments {
-			principalID := assignment.Username
-			if assignment.PrincipalType == "GROUP" {
-				principalID = assignment.GroupName
-			}
+// console prints progress for a run. Emoji are only used when stdout is a
+// terminal, so logs and pipes get plain text; with -quiet only results and
+// errors are printed.
+type console struct {
+	stdout io.Writer
+	out    io.Writer // stdout, or io.Discard with -quiet
+	emoji  bool
+}
 
-			key := assignmentKey{
-				PermissionSetID: assignment.PermissionSetID,
-				PrincipalType:   assignment.PrincipalType,
-				PrincipalID:     principalID,
-			}
+func newConsole(stdout io.Writer, quiet bool) *console {
+	c := &console{stdout: stdout, out: stdout, emoji: isTerminal(stdout)}
+	if quiet {
+		c.out = io.Discard
+	}
+	return c
+}
 
-			if grouped[key] == nil {
-				grouped[key] = &assignmentGroup{}
-			}
-			grouped[key].AccountIDs = append(grouped[key].AccountIDs, assignment.AccountID)
-			grouped[key].AssignmentIDs = append(grouped[key].AssignmentIDs, assignment.ID)
-		}
+// step prints a progress line, prefixed with emoji on a terminal.
+func (c *console) step(emoji, format string, args ...any) {
+	c.print(c.out, emoji, format, args...)
+}
 
-		counter := 0
-		for key, group := range grouped {
-			counter++
-
-			// Find permission set name
-			permSetName := ""
-			for _, ps := range data.PermissionSets {
-				if ps.ID == key.PermissionSetID {
-					permSetName = ps.Name
-					break
-				}
-			}
+// result is like step but is printed even with -quiet.
+func (c *console) result(emoji, format string, args ...any) {
+	c.print(c.stdout, emoji, format, args...)
+}
 
-			resourceName := fmt.Sprintf("assignment_%d", counter)
-			if permSetName != "" && key.PrincipalID != "" {
-				resourceName = toResourceName(permSetName + "_" + key.PrincipalID)
-			}
+// line prints a progress line without emoji.
+func (c *console) line(format string, args ...any) {
+	fmt.Fprintf(c.out, format+"\n", args...)
+}
 
-			// Create composite ID from actual assignment IDs (new format)
-			compositeID := strings.Join(group.AssignmentIDs, ",")
+func (c *console) print(w io.Writer, emoji, format string, args ...any) {
+	if c.emoji {
+		format = emoji + " " + format
+	}
+	fmt.Fprintf(w, format+"\n", args...)
+}
 
-			sb.WriteString(fmt.Sprintf("terraform import prism_permission_set_assignment.%s '%s'\n", resourceName, compositeID))
-		}
-		sb.WriteString("\n")
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
 	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-	sb.WriteString("echo \"✅ Import complete!\"\n")
-	sb.WriteString("echo \"Next steps:\"\n")
-	sb.WriteString("echo \"  1. Run: terraform plan\"\n")
-	sb.WriteString("echo \"  2. Review any differences\"\n")
-	sb.WriteString("echo \"  3. Run: terraform apply (if needed)\"\n")
+// warningLog passes warnings through to w and keeps them for the report.
+// A line starting with "Warning: " begins a warning, and the "- " lines
+// after it list its items.
+type warningLog struct {
+	w io.Writer
 
-	return os.WriteFile(filepath.Join(outputDir, "import.sh"), []byte(sb.String()), 0755)
+	mu       sync.Mutex
+	partial  string // the unterminated end of the last write
+	warnings []string
 }
 
-func escapeString(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	s = strings.ReplaceAll(s, "\r", "\\r")
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	return s
-}
+func (l *warningLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-func indent(s string, spaces int) string {
-	prefix := strings.Repeat(" ", spaces)
-	lines := strings.Split(s, "\n")
-	for i, line := range lines {
-		if line != "" {
-			lines[i] = prefix + line
+	lines := strings.Split(l.partial+string(p), "\n")
+	l.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Warning: "):
+			l.warnings = append(l.warnings, strings.TrimPrefix(line, "Warning: "))
+		case strings.HasPrefix(line, "- ") && len(l.warnings) > 0:
+			l.warnings[len(l.warnings)-1] += "\n" + line
 		}
 	}
-	return strings.Join(lines, "\n")
+	return l.w.Write(p)
+}
+
+// Warnings returns the warnings written so far.
+func (l *warningLog) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.warnings...)
 }
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/tools/terraform-import/internal/importer"
+)
+
+func TestNewClient_BaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		port    int64
+		want    string
+	}{
+		{name: "default", baseURL: defaultBaseURL, port: provider.DefaultPort, want: "https://prism.cloudkeeper.com:8090"},
+		{name: "custom host", baseURL: "https://prism.staging.example.com/", port: provider.DefaultPort, want: "https://prism.staging.example.com:8090"},
+		{name: "explicit port", baseURL: "https://prism.internal", port: 9443, want: "https://prism.internal:9443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newClient(Config{Config: importer.Config{BaseURL: tt.baseURL, Port: tt.port, PrismSubdomain: "acme"}, APIToken: "token"})
+			if client.BaseURL != tt.want {
+				t.Errorf("expected base URL %q, got %q", tt.want, client.BaseURL)
+			}
+			if client.PrismSubdomain != "acme" {
+				t.Errorf("expected subdomain acme, got %q", client.PrismSubdomain)
+			}
+		})
+	}
+}
+
+// writeTestDump writes a small -export-json dump, so run can be exercised
+// without an API.
+func writeTestDump(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prism.json")
+	data := &importer.InfrastructureData{
+		Users:            []provider.User{{ID: "u-1", Username: "alice", Email: "alice@example.com", Enabled: true}},
+		Groups:           []provider.Group{{ID: "g-1", Name: "admins"}},
+		GroupMemberships: map[string][]string{"admins": {"alice"}},
+	}
+	if err := importer.WriteDump(path, "acme", data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_QuietPrintsNothing(t *testing.T) {
+	dump := writeTestDump(t)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-quiet", "-from-json", dump, "-output", outputDir}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no stdout with -quiet, got:\n%s", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected no stderr, got:\n%s", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "users.tf")); err != nil {
+		t.Errorf("expected users.tf to be generated: %v", err)
+	}
+}
+
+func TestRun_QuietStillPrintsResults(t *testing.T) {
+	dump := writeTestDump(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-quiet", "-dry-run", "-from-json", dump}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	want := "Dry run: would generate 0 AWS accounts, 0 permission sets, 1 users, 1 groups, 1 group memberships, 0 permission set assignments, 0 identity providers\n"
+	if stdout.String() != want {
+		t.Errorf("expected only the dry run summary %q, got %q", want, stdout.String())
+	}
+}
+
+func TestRun_NoEmojiWithoutTerminal(t *testing.T) {
+	dump := writeTestDump(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-from-json", dump, "-output", t.TempDir()}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Successfully generated Terraform configuration!") {
+		t.Errorf("expected progress output, got:\n%s", stdout.String())
+	}
+	for _, emoji := range []string{"📂", "📝", "✅", "🚀"} {
+		if strings.Contains(stdout.String(), emoji) {
+			t.Errorf("expected no emoji when stdout isn't a terminal, found %s in:\n%s", emoji, stdout.String())
+		}
+	}
+}
+
+func TestRun_FlagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{name: "quiet and verbose", args: []string{"-from-json", "x.json", "-quiet", "-verbose"}, code: 1, want: "-quiet and -verbose can't be used together"},
+		{name: "unreadable name map", args: []string{"-from-json", "x.json", "-name-map", "no-such-names.yaml"}, code: 1, want: "failed to read no-such-names.yaml"},
+		{name: "unknown flag", args: []string{"-no-such-flag"}, code: 2, want: "flag provided but not defined"},
+		{name: "report with dry run", args: []string{"-from-json", "x.json", "-dry-run", "-report-json", "report.json"}, code: 1, want: "-report-json can't be used with -dry-run or -diff-state"},
+		{name: "subdomain and subdomains", args: []string{"-subdomain", "acme", "-subdomains", "acme,globex"}, code: 1, want: "-subdomain and -subdomains can't be used together"},
+		{name: "subdomains from dump", args: []string{"-subdomains", "acme,globex", "-from-json", "x.json"}, code: 1, want: "-subdomains can't be used with -from-json or -export-json"},
+		{name: "subdomains as module", args: []string{"-subdomains", "acme,globex", "-layout", "module"}, code: 1, want: "-subdomains can't be used with -layout=module"},
+		{name: "help", args: []string{"-h"}, code: 0, want: "-quiet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, nil, &stdout, &stderr); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("expected stderr to contain %q, got:\n%s", tt.want, stderr.String())
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("expected no stdout, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_FromJSONWritesDumpSubdomain(t *testing.T) {
+	dump := writeTestDump(t)
+	outputDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-quiet", "-from-json", dump, "-output", outputDir}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `prism_subdomain = "acme"`) {
+		t.Errorf("expected the dump's subdomain in terraform.tfvars, got:\n%s", content)
+	}
+}
+
+func TestRun_ReportsOrphans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prism.json")
+	data := &importer.InfrastructureData{
+		Users: []provider.User{{ID: "u-1", Username: "alice", Email: "alice@example.com", Enabled: true}},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "a-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "ghost", AccountID: "111111111111"},
+		},
+	}
+	if err := importer.WriteDump(path, "acme", data); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-quiet", "-from-json", path, "-output", t.TempDir()}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{"Warning: 3 references", `principal_id "ghost"`, `permission_set_id "ps-1"`, `account_ids "111111111111"`} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestRun_ReportJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prism.json")
+	data := &importer.InfrastructureData{
+		Users: []provider.User{
+			{ID: "u-1", Username: "alice", Email: "alice@example.com", Enabled: true},
+			{ID: "u-2", Username: "bob", Email: "bob@example.com", Enabled: true},
+		},
+		PermissionSetAssignments: []provider.PermissionSetAssignment{
+			{ID: "a-1", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "alice", AccountID: "111111111111"},
+			{ID: "a-2", PermissionSetID: "ps-1", PrincipalType: "USER", Username: "bob", AccountID: "111111111111"},
+		},
+	}
+	if err := importer.WriteDump(path, "acme", data); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-from-json", path, "-output", t.TempDir(), "-user-filter", "^alice$", "-exclude", "identity_providers", "-report-json", reportPath}
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	for _, want := range []string{"Summary:\n", "  prism_user                            1\n", "  Warnings: 1\n", "Wrote report to " + reportPath} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected stdout to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report importer.Report
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.PrismSubdomain != "acme" || len(report.Resources) != 2 {
+		t.Errorf("expected alice and her assignment for acme, got %+v", report)
+	}
+	if got := report.Resources[0]; got.Address != "prism_user.alice" || !reflect.DeepEqual(got.PrismIDs, []string{"u-1"}) {
+		t.Errorf("expected u-1 to map to prism_user.alice, got %+v", got)
+	}
+	wantSkipped := importer.ReportSkipped{NotFetched: []string{"identity_providers"}, FilteredUsers: 1, Assignments: 1}
+	if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+		t.Errorf("expected skipped %+v, got %+v", wantSkipped, report.Skipped)
+	}
+	// The orphaned permission set and account of both assignments are one
+	// warning with its items
+	if len(report.Warnings) != 1 || !strings.HasPrefix(report.Warnings[0], "4 references to resources that weren't fetched") ||
+		strings.Count(report.Warnings[0], "\n- ") != 4 {
+		t.Errorf("expected the orphans warning with its items, got %q", report.Warnings)
+	}
+}
+
+func TestRun_OutputLayoutByGroupPath(t *testing.T) {
+	dump := writeTestDump(t)
+	outputDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-from-json", dump, "-output", outputDir, "-output-layout", "by-group-path"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, importer.CommonDirectory, "users.tf")); err != nil {
+		t.Errorf("expected users.tf in the common directory: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "  - common\n") {
+		t.Errorf("expected the directories to be listed, got:\n%s", stdout.String())
+	}
+}
+
+func TestRun_UnreachableAPICreatesNoFiles(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "out")
+	exportPath := filepath.Join(t.TempDir(), "prism.json")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-quiet", "-subdomain", "acme", "-token", "token", "-base-url", "https://prism.invalid",
+		"-output", outputDir, "-export-json", exportPath}
+	if code := run(args, nil, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if want := "Error: can't resolve prism.invalid; check -base-url or PRISM_BASE_URL\n"; !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("expected stderr to start with %q, got:\n%s", want, stderr.String())
+	}
+	for _, path := range []string{outputDir, exportPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be created, got %v", path, err)
+		}
+	}
+}
+
+// installStubTerraform puts a terraform script on PATH that logs each
+// command line to the returned file and fails any command that mentions
+// STUB_TERRAFORM_FAIL.
+func installStubTerraform(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub terraform is a shell script")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "terraform.log")
+	script := `#!/bin/sh
+echo "$*" >> "$STUB_TERRAFORM_LOG"
+if [ "$1" = workspace ]; then
+	echo default
+	exit 0
+fi
+if [ -n "$STUB_TERRAFORM_FAIL" ]; then
+	case "$*" in
+	*"$STUB_TERRAFORM_FAIL"*)
+		echo "stub failure" >&2
+		exit 1
+		;;
+	esac
+fi
+echo "stub terraform $1"
+`
+	if err := os.WriteFile(filepath.Join(dir, "terraform"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STUB_TERRAFORM_LOG", log)
+	t.Setenv("STUB_TERRAFORM_FAIL", "")
+	return log
+}
+
+// readTerraformLog returns the commands the stub terraform ran.
+func readTerraformLog(t *testing.T, log string) []string {
+	t.Helper()
+	content, err := os.ReadFile(log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func TestRun_RunImportBlocks(t *testing.T) {
+	log := installStubTerraform(t)
+	dump := writeTestDump(t)
+	outputDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-from-json", dump, "-output", outputDir, "-run-import", "-yes"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	want := []string{
+		"workspace show",
+		"init -input=false",
+		"plan -input=false -out=prism-import.tfplan",
+		"apply -input=false prism-import.tfplan",
+	}
+	if got := readTerraformLog(t, log); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected terraform to run %q, got %q", want, got)
+	}
+	for _, line := range []string{`3 resources into workspace "default"`, "$ terraform init -input=false", "stub terraform apply", "Imported 3 resources"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("expected stdout to contain %q, got:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestRun_RunImportScriptResumes(t *testing.T) {
+	log := installStubTerraform(t)
+	dump := writeTestDump(t)
+	outputDir := t.TempDir()
+	args := []string{"-quiet", "-from-json", dump, "-output", outputDir, "-import-format", "script", "-run-import", "-yes"}
+
+	// The group's import fails, after the user's is recorded
+	t.Setenv("STUB_TERRAFORM_FAIL", "prism_group.admins")
+	var stdout, stderr bytes.Buffer
+	if code := run(args, nil, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	checkpoint := filepath.Join(outputDir, importer.ImportCheckpointFile)
+	if !strings.Contains(stderr.String(), "recorded in "+checkpoint) {
+		t.Errorf("expected the checkpoint to be pointed out, got:\n%s", stderr.String())
+	}
+	if content, err := os.ReadFile(checkpoint); err != nil || string(content) != "prism_user.alice\n" {
+		t.Fatalf("expected the checkpoint to hold the imported user, got %q (%v)", content, err)
+	}
+
+	// Running again imports only what is left
+	if err := os.Remove(log); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("STUB_TERRAFORM_FAIL", "")
+	stdout.Reset()
+	stderr.Reset()
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	want := []string{
+		"workspace show",
+		"init -input=false",
+		"import -input=false prism_group.admins admins",
+		"import -input=false prism_group_membership.admins_members admins",
+	}
+	if got := readTerraformLog(t, log); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected terraform to run %q, got %q", want, got)
+	}
+}
+
+func TestRun_RunImportDeclined(t *testing.T) {
+	log := installStubTerraform(t)
+	dump := writeTestDump(t)
+	outputDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-quiet", "-from-json", dump, "-output", outputDir, "-run-import"}, strings.NewReader("n\n"), &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Run terraform init and import them? [y/N]") {
+		t.Errorf("expected a confirmation prompt even with -quiet, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Import cancelled") {
+		t.Errorf("expected the import to be cancelled, got:\n%s", stderr.String())
+	}
+	if got := readTerraformLog(t, log); !reflect.DeepEqual(got, []string{"workspace show"}) {
+		t.Errorf("expected only the workspace to be looked up, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "users.tf")); err != nil {
+		t.Errorf("expected the generated files to be kept: %v", err)
+	}
+}
+
+func TestRun_RunImportAccepted(t *testing.T) {
+	log := installStubTerraform(t)
+	dump := writeTestDump(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-quiet", "-from-json", dump, "-output", t.TempDir(), "-run-import"}, strings.NewReader("yes\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if got := readTerraformLog(t, log); len(got) != 4 || got[3] != "apply -input=false prism-import.tfplan" {
+		t.Errorf("expected the imports to be applied, got %q", got)
+	}
+}
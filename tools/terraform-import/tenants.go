@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/tools/terraform-import/internal/importer"
+)
+
+// Tenant is a Prism customer exported by -subdomains.
+type Tenant struct {
+	Subdomain string
+	Token     string
+}
+
+var invalidEnvChars = regexp.MustCompile(`[^A-Z0-9]`)
+
+// tokenEnv returns the environment variable holding the API token of the
+// tenant with subdomain: "acme-eu" reads PRISM_API_TOKEN_ACME_EU.
+func tokenEnv(subdomain string) string {
+	return "PRISM_API_TOKEN_" + invalidEnvChars.ReplaceAllString(strings.ToUpper(subdomain), "_")
+}
+
+// resolveToken returns the API token for subdomain: token when it was
+// given with -token, or else the tenant's own tokenEnv variable, falling
+// back to PRISM_API_TOKEN.
+func resolveToken(subdomain, token string, tokenSet bool) string {
+	if tokenSet {
+		return token
+	}
+	if subdomain != "" {
+		if v := os.Getenv(tokenEnv(subdomain)); v != "" {
+			return v
+		}
+	}
+	return os.Getenv("PRISM_API_TOKEN")
+}
+
+// parseTenants parses the comma-separated -subdomains list and resolves
+// the token of each tenant.
+func parseTenants(list, token string, tokenSet bool) ([]Tenant, error) {
+	var tenants []Tenant
+	seen := make(map[string]bool)
+	for _, subdomain := range strings.Split(list, ",") {
+		subdomain = strings.TrimSpace(subdomain)
+		if subdomain == "" {
+			return nil, fmt.Errorf("-subdomains has an empty entry: %q", list)
+		}
+		if seen[strings.ToLower(subdomain)] {
+			return nil, fmt.Errorf("-subdomains lists %s more than once", subdomain)
+		}
+		seen[strings.ToLower(subdomain)] = true
+
+		tenant := Tenant{Subdomain: subdomain, Token: resolveToken(subdomain, token, tokenSet)}
+		if tenant.Token == "" {
+			return nil, fmt.Errorf("API token for %s is required (use -token flag, %s or PRISM_API_TOKEN env var)", subdomain, tokenEnv(subdomain))
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+// checkTenantFlags rejects the flags that don't apply to a run over
+// several tenants.
+func checkTenantFlags(config Config, subdomainSet bool) error {
+	switch {
+	case subdomainSet:
+		return errors.New("-subdomain and -subdomains can't be used together")
+	case config.FromJSON != "" || config.ExportJSON != "":
+		return errors.New("-subdomains can't be used with -from-json or -export-json, which hold a single tenant")
+	case config.DiffState != "":
+		return errors.New("-subdomains can't be used with -diff-state, which compares a single tenant's state")
+	case config.ReportJSON != "":
+		return errors.New("-subdomains can't be used with -report-json; each tenant's summary is printed instead")
+	case config.RunImport:
+		return errors.New("-subdomains can't be used with -run-import; run the imports in each tenant's directory")
+	case config.Layout == importer.LayoutModule:
+		return errors.New("-subdomains can't be used with -layout=module; each tenant needs a root configuration for its provider")
+	}
+	return nil
+}
+
+// runTenants exports each tenant of -subdomains into its own sub-directory
+// of the output directory, where the provider is aliased to the tenant, and
+// ends with a summary across tenants. A tenant that fails doesn't stop the
+// others, but fails the run.
+func runTenants(config Config, c *console, stderr io.Writer) int {
+	var reports []*importer.Report
+	var failed []string
+	for i, tenant := range config.Tenants {
+		if i > 0 {
+			c.line("")
+		}
+		c.step("🏢", "Tenant %s (%d of %d)", tenant.Subdomain, i+1, len(config.Tenants))
+
+		tenantConfig := config
+		tenantConfig.Tenants = nil
+		tenantConfig.PrismSubdomain = tenant.Subdomain
+		tenantConfig.APIToken = tenant.Token
+		tenantConfig.OutputDir = filepath.Join(config.OutputDir, tenant.Subdomain)
+		tenantConfig.ProviderAlias = importer.ProviderAlias(tenant.Subdomain)
+		report, _, code := generate(&tenantConfig, c, stderr, &warningLog{w: stderr})
+		if code != 0 {
+			failed = append(failed, tenant.Subdomain)
+			continue
+		}
+		if report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	if len(reports) > 0 {
+		c.line("")
+		c.step("📊", "Summary of %d tenants:", len(reports))
+		importer.WriteTenantSummary(c.out, reports)
+		c.line("")
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(stderr, "Error: %d of %d tenants failed: %s\n", len(failed), len(config.Tenants), strings.Join(failed, ", "))
+		return 1
+	}
+	if config.DryRun {
+		return 0
+	}
+
+	c.step("🚀", "Next steps, in each tenant's directory under %s:", config.OutputDir)
+	c.line("  1. Review the generated files")
+	c.line("  2. Run: export PRISM_API_TOKEN=<the tenant's token>")
+	switch {
+	case config.ImportFormat == importer.ImportFormatScript && config.OutputLayout == importer.OutputLayoutByGroupPath:
+		c.line("  3. Run: ./import.sh (or .\\import.ps1 in PowerShell), which runs terraform init and the imports in each configuration")
+	case config.ImportFormat == importer.ImportFormatScript:
+		c.line("  3. Run: terraform init, then ./import.sh (or .\\import.ps1 in PowerShell), then terraform plan")
+	case config.OutputLayout == importer.OutputLayoutByGroupPath:
+		c.line("  3. In each of the tenant's configurations, run: terraform init, terraform plan and terraform apply")
+	default:
+		c.line("  3. Run: terraform init, terraform plan and terraform apply")
+	}
+	return 0
+}
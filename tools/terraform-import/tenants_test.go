@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CloudKeeper-Inc/terraform-provider-prism/internal/provider"
+)
+
+// newFakeTenants serves a user and a group for each subdomain in tokens,
+// to requests with that subdomain's token, and returns the -base-url and
+// -port flags that reach it. The clients run builds trust the server
+// through http.DefaultTransport until the test ends.
+func newFakeTenants(t *testing.T, tokens map[string]string) []string {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/api/v1/customers/")
+		subdomain, path, _ := strings.Cut(rest, "/")
+		if !ok || tokens[subdomain] == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-API-Token") != tokens[subdomain] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body any = []any{}
+		switch path {
+		case "users":
+			body = []provider.User{{ID: "u-1", Username: subdomain + "-admin", Email: "admin@" + subdomain + ".example.com", Enabled: true}}
+		case "groups":
+			body = []provider.Group{{ID: "g-1", Name: "Admins"}}
+		case "groups/Admins/members":
+			body = map[string]any{"group": "Admins", "members": []map[string]string{{"username": subdomain + "-admin"}}}
+		case "permission-set-assignments":
+			body = map[string]any{"assignments": []any{}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "data": body})
+	}))
+	t.Cleanup(server.Close)
+
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []string{"-base-url", "https://" + u.Hostname(), "-port", u.Port()}
+}
+
+func TestRun_Subdomains(t *testing.T) {
+	args := newFakeTenants(t, map[string]string{"acme": "acme-token", "globex-eu": "globex-token"})
+	t.Setenv("PRISM_API_TOKEN", "acme-token")
+	t.Setenv("PRISM_API_TOKEN_GLOBEX_EU", "globex-token")
+	outputDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	args = append(args, "-subdomains", "acme,globex-eu", "-output", outputDir)
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+
+	for subdomain, alias := range map[string]string{"acme": "acme", "globex-eu": "globex_eu"} {
+		dir := filepath.Join(outputDir, subdomain)
+		providerTF, err := os.ReadFile(filepath.Join(dir, "provider.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `alias           = "` + alias + `"`; !strings.Contains(string(providerTF), want) {
+			t.Errorf("expected %s/provider.tf to contain %q:\n%s", subdomain, want, providerTF)
+		}
+
+		usersTF, err := os.ReadFile(filepath.Join(dir, "users.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"provider = prism." + alias, `username = "` + subdomain + `-admin"`} {
+			if !strings.Contains(string(usersTF), want) {
+				t.Errorf("expected %s/users.tf to contain %q:\n%s", subdomain, want, usersTF)
+			}
+		}
+	}
+
+	for _, want := range []string{
+		"Tenant acme (1 of 2)",
+		"Tenant globex-eu (2 of 2)",
+		"  Tenant     Resources  Warnings\n  acme               3         0\n  globex-eu          3         0\n  Total              6         0\n",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected stdout to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRun_SubdomainsFailedTenant(t *testing.T) {
+	args := newFakeTenants(t, map[string]string{"acme": "acme-token", "globex": "globex-token"})
+	t.Setenv("PRISM_API_TOKEN", "acme-token")
+	outputDir := t.TempDir()
+
+	// globex falls back to acme's token, which it rejects
+	var stdout, stderr bytes.Buffer
+	args = append(args, "-subdomains", "globex,acme", "-output", outputDir)
+	if code := run(args, nil, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr: %s)", code, stderr.String())
+	}
+	if want := "Error: 1 of 2 tenants failed: globex\n"; !strings.HasSuffix(stderr.String(), want) {
+		t.Errorf("expected stderr to end with %q, got:\n%s", want, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "acme", "users.tf")); err != nil {
+		t.Errorf("expected the other tenant to be generated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "globex")); !os.IsNotExist(err) {
+		t.Errorf("expected no directory for the failed tenant, got %v", err)
+	}
+}
+
+func TestParseTenants_Tokens(t *testing.T) {
+	t.Setenv("PRISM_API_TOKEN", "shared")
+	t.Setenv("PRISM_API_TOKEN_ACME_EU", "acme-eu")
+
+	tenants, err := parseTenants("acme-eu, globex", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Tenant{{Subdomain: "acme-eu", Token: "acme-eu"}, {Subdomain: "globex", Token: "shared"}}
+	if !reflect.DeepEqual(tenants, want) {
+		t.Errorf("expected %v, got %v", want, tenants)
+	}
+
+	// -token wins over the variables
+	tenants, err = parseTenants("acme-eu,globex", "flag", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Tenant{{Subdomain: "acme-eu", Token: "flag"}, {Subdomain: "globex", Token: "flag"}}
+	if !reflect.DeepEqual(tenants, want) {
+		t.Errorf("expected %v, got %v", want, tenants)
+	}
+}
+
+func TestParseTenants_Errors(t *testing.T) {
+	t.Setenv("PRISM_API_TOKEN", "")
+	t.Setenv("PRISM_API_TOKEN_ACME", "acme")
+
+	tests := map[string]string{
+		"acme,":         `-subdomains has an empty entry: "acme,"`,
+		"acme,ACME":     "-subdomains lists ACME more than once",
+		"acme,globex.1": "API token for globex.1 is required (use -token flag, PRISM_API_TOKEN_GLOBEX_1 or PRISM_API_TOKEN env var)",
+	}
+	for list, wantErr := range tests {
+		if _, err := parseTenants(list, "", false); err == nil || err.Error() != wantErr {
+			t.Errorf("%s: expected error %q, got %v", list, wantErr, err)
+		}
+	}
+}
+
+func TestRun_SingleSubdomainToken(t *testing.T) {
+	args := newFakeTenants(t, map[string]string{"acme": "acme-token"})
+	t.Setenv("PRISM_API_TOKEN", "wrong")
+	t.Setenv("PRISM_API_TOKEN_ACME", "acme-token")
+
+	var stdout, stderr bytes.Buffer
+	args = append(args, "-subdomain", "acme", "-dry-run")
+	if code := run(args, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected the subdomain's own token to be used, got exit code %d (stderr: %s)", code, stderr.String())
+	}
+}